@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/auth"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	keyrotationtypes "github.com/replicatedhq/kots/pkg/keyrotation/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/print"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func AdminConsoleRotateEncryptionKeyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate-encryption-key [namespace]",
+		Short: "Rotate the Admin Console encryption key",
+		Long: `Generates a new encryption key for the Admin Console, re-encrypts every stored secret with it, and removes the previous key once every value is verified.
+If the rotation is interrupted, running the command again continues with the same new key.`,
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			output := v.GetString("output")
+			if output != "json" && output != "" {
+				return errors.Errorf("output format %s not supported (allowed formats are: json)", output)
+			}
+
+			log := logger.NewCLILogger(cmd.OutOrStdout())
+			if output != "" {
+				log.Silence()
+			}
+
+			// use namespace-as-arg if provided, else use namespace from -n/--namespace
+			namespace, err := getNamespaceOrDefault(v.GetString("namespace"))
+			if err != nil {
+				return errors.Wrap(err, "failed to get namespace")
+			}
+			if len(args) == 1 {
+				namespace = args[0]
+			} else if len(args) > 1 {
+				fmt.Printf("more than one argument supplied: %+v\n", args)
+				os.Exit(1)
+			}
+
+			if err := validateNamespace(namespace); err != nil {
+				return errors.Wrap(err, "failed to validate namespace")
+			}
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+
+			clientset, err := k8sutil.GetClientset()
+			if err != nil {
+				return errors.Wrap(err, "failed to get clientset")
+			}
+
+			getPodName := func() (string, error) {
+				return k8sutil.FindKotsadm(clientset, namespace)
+			}
+
+			localPort, errChan, err := k8sutil.PortForward(0, 3000, namespace, getPodName, false, stopCh, log)
+			if err != nil {
+				return errors.Wrap(err, "failed to start port forwarding")
+			}
+
+			go func() {
+				select {
+				case err := <-errChan:
+					if err != nil {
+						log.Error(err)
+					}
+				case <-stopCh:
+				}
+			}()
+
+			url := fmt.Sprintf("http://localhost:%d/api/v1/encryption-key/rotate", localPort)
+
+			authSlug, err := auth.GetOrCreateAuthSlug(clientset, namespace)
+			if err != nil {
+				log.Info("Unable to authenticate to the Admin Console running in the %s namespace. Ensure you have read access to secrets in this namespace and try again.", namespace)
+				if v.GetBool("debug") {
+					return errors.Wrap(err, "failed to get kotsadm auth slug")
+				}
+				os.Exit(2) // not returning error here as we don't want to show the entire stack trace to normal users
+			}
+
+			requestPayload := map[string]interface{}{
+				"dryRun": v.GetBool("dry-run"),
+			}
+			requestBody, err := json.Marshal(requestPayload)
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal request json")
+			}
+			newReq, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+			if err != nil {
+				return errors.Wrap(err, "failed to create request")
+			}
+			newReq.Header.Add("Content-Type", "application/json")
+			newReq.Header.Add("Authorization", authSlug)
+
+			log.ActionWithSpinner("Rotating encryption key")
+
+			resp, err := http.DefaultClient.Do(newReq)
+			if err != nil {
+				log.FinishSpinnerWithError()
+				return errors.Wrap(err, "failed to rotate encryption key")
+			}
+			defer resp.Body.Close()
+
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				log.FinishSpinnerWithError()
+				return errors.Wrap(err, "failed to read")
+			}
+
+			type Response struct {
+				Success bool                     `json:"success"`
+				Error   string                   `json:"error"`
+				Report  *keyrotationtypes.Report `json:"report"`
+			}
+			response := Response{}
+			if err = json.Unmarshal(b, &response); err != nil {
+				log.FinishSpinnerWithError()
+				return errors.Wrapf(err, "failed to unmarshal server response: %s", b)
+			}
+
+			if response.Success {
+				log.FinishSpinner()
+			} else {
+				log.FinishSpinnerWithError()
+			}
+
+			// the report shows which values are still pending, even if the rotation did not finish
+			if response.Report != nil {
+				print.EncryptionKeyRotation(response.Report, output)
+			}
+
+			if response.Error != "" {
+				return errors.New(response.Error)
+			}
+
+			if resp.StatusCode != http.StatusOK || response.Report == nil {
+				return errors.Errorf("unexpected response from server %v: %s", resp.StatusCode, b)
+			}
+
+			switch {
+			case response.Report.DryRun:
+				log.ActionWithoutSpinner("Dry run, %d values would be re-encrypted with a new key", response.Report.Pending())
+			case response.Report.Retired:
+				log.ActionWithoutSpinner("Encryption key has been rotated and the previous key was removed")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "report the values that would be re-encrypted without changing anything")
+	cmd.Flags().StringP("output", "o", "", "output format (currently supported: json)")
+
+	return cmd
+}
@@ -16,6 +16,7 @@ import (
 	registrytypes "github.com/replicatedhq/kots/pkg/docker/registry/types"
 	"github.com/replicatedhq/kots/pkg/image"
 	imagetypes "github.com/replicatedhq/kots/pkg/image/types"
+	"github.com/replicatedhq/kots/pkg/imageverification"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
@@ -68,6 +69,11 @@ func AirgapUpdateCmd() *cobra.Command {
 				return errors.Wrap(err, "failed to get registry config")
 			}
 
+			verificationPolicy, err := imageverification.GetPolicy(clientset, namespace, appSlug)
+			if err != nil {
+				return errors.Wrap(err, "failed to get image verification policy")
+			}
+
 			pushOpts := imagetypes.PushImagesOptions{
 				KotsadmTag: v.GetString("kotsadm-tag"),
 				Registry: registrytypes.RegistryOptions{
@@ -76,8 +82,10 @@ func AirgapUpdateCmd() *cobra.Command {
 					Username:  registryConfig.Username,
 					Password:  registryConfig.Password,
 				},
-				ProgressWriter: getProgressWriter(v, log),
-				LogForUI:       v.GetBool("from-api"),
+				ProgressWriter:      getProgressWriter(v, log),
+				LogForUI:            v.GetBool("from-api"),
+				VerificationPolicy:  verificationPolicy,
+				VerificationResults: &imagetypes.ImageVerificationResults{},
 			}
 
 			if _, err := os.Stat(airgapBundle); err == nil {
@@ -89,6 +97,11 @@ func AirgapUpdateCmd() *cobra.Command {
 				return errors.Wrap(err, "failed to stat airgap bundle")
 			}
 
+			// the admin console persists the results with the version that is created from the bundle
+			if err := imageverification.SetPendingResults(clientset, namespace, appSlug, pushOpts.VerificationResults.List()); err != nil {
+				return errors.Wrap(err, "failed to set image verification results")
+			}
+
 			updateFiles, err := getAirgapUpdateFiles(airgapBundle)
 			if err != nil {
 				return errors.Wrap(err, "failed to get airgap update files")
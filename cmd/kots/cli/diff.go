@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/auth"
+	"github.com/replicatedhq/kots/pkg/handlers"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/print"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func DiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "diff [appSlug]",
+		Short:         "Show the changes to the rendered manifests between two app versions",
+		Long:          "",
+		SilenceUsage:  false,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: diffCmd,
+	}
+
+	cmd.Flags().Int64("from", -1, "the sequence of the version to diff from")
+	cmd.Flags().Int64("to", -1, "the sequence of the version to diff to")
+	cmd.Flags().Bool("stat", false, "only show a summary of the changed resources")
+	cmd.Flags().StringP("output", "o", "", "output format (currently supported: json)")
+
+	return cmd
+}
+
+func diffCmd(cmd *cobra.Command, args []string) error {
+	v := viper.GetViper()
+
+	if len(args) == 0 {
+		cmd.Help()
+		os.Exit(1)
+	}
+
+	appSlug := args[0]
+
+	fromSequence := v.GetInt64("from")
+	toSequence := v.GetInt64("to")
+	if fromSequence < 0 || toSequence < 0 {
+		return errors.New("--from and --to are required")
+	}
+
+	output := v.GetString("output")
+	if output != "json" && output != "" {
+		return errors.Errorf("output format %s not supported (allowed formats are: json)", output)
+	}
+
+	log := logger.NewCLILogger(cmd.OutOrStdout())
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get clientset")
+	}
+
+	namespace, err := getNamespaceOrDefault(v.GetString("namespace"))
+	if err != nil {
+		return errors.Wrap(err, "failed to get namespace")
+	}
+
+	getPodName := func() (string, error) {
+		return k8sutil.FindKotsadm(clientset, namespace)
+	}
+
+	localPort, errChan, err := k8sutil.PortForward(0, 3000, namespace, getPodName, false, stopCh, log)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to start port forwarding")
+	}
+
+	go func() {
+		select {
+		case err := <-errChan:
+			if err != nil {
+				log.Error(err)
+			}
+		case <-stopCh:
+		}
+	}()
+
+	authSlug, err := auth.GetOrCreateAuthSlug(clientset, namespace)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		log.Info("Unable to authenticate to the Admin Console running in the %s namespace. Ensure you have read access to secrets in this namespace and try again.", namespace)
+		if v.GetBool("debug") {
+			return errors.Wrap(err, "failed to get kotsadm auth slug")
+		}
+		os.Exit(2) // not returning error here as we don't want to show the entire stack trace to normal users
+	}
+
+	url := fmt.Sprintf("http://localhost:%d/api/v1/app/%s/diff/%d/%d", localPort, url.PathEscape(appSlug), fromSequence, toSequence)
+	versionDiff, err := getAppVersionDiff(url, authSlug)
+	if err != nil {
+		return errors.Wrap(err, "failed to get app version diff")
+	}
+
+	print.VersionDiff(versionDiff.Resources, output, v.GetBool("stat"))
+
+	return nil
+}
+
+func getAppVersionDiff(url string, authSlug string) (*handlers.GetAppVersionDiffResponse, error) {
+	newReq, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	newReq.Header.Add("Content-Type", "application/json")
+	newReq.Header.Add("Authorization", authSlug)
+
+	resp, err := http.DefaultClient.Do(newReq)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read")
+	}
+
+	versionDiff := handlers.GetAppVersionDiffResponse{}
+	if err := json.Unmarshal(b, &versionDiff); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal app version diff")
+	}
+
+	return &versionDiff, nil
+}
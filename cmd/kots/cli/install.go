@@ -25,6 +25,7 @@ import (
 	dockerregistry "github.com/replicatedhq/kots/pkg/docker/registry"
 	"github.com/replicatedhq/kots/pkg/handlers"
 	"github.com/replicatedhq/kots/pkg/identity"
+	"github.com/replicatedhq/kots/pkg/image"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	k8sutiltypes "github.com/replicatedhq/kots/pkg/k8sutil/types"
 	"github.com/replicatedhq/kots/pkg/kotsadm"
@@ -40,6 +41,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/replicatedapp"
 	storetypes "github.com/replicatedhq/kots/pkg/store/types"
 	"github.com/replicatedhq/kots/pkg/tasks"
+	"github.com/replicatedhq/kots/pkg/util"
 	kotsv1beta1 "github.com/replicatedhq/kotskinds/apis/kots/v1beta1"
 	"github.com/replicatedhq/troubleshoot/pkg/preflight"
 	"github.com/spf13/cobra"
@@ -198,6 +200,9 @@ func InstallCmd() *cobra.Command {
 				if err != nil {
 					log.Info("Unable to pull application metadata. This can be ignored, but custom branding will not be available in the Admin Console until a license is installed. This may also cause the Admin Console to run without minimal role-based-access-control (RBAC) privileges, which may be required by the application.")
 					applicationMetadata = &replicatedapp.ApplicationMetadata{}
+				} else if applicationMetadata == nil {
+					// metadata is only available for replicated apps
+					applicationMetadata = &replicatedapp.ApplicationMetadata{}
 				}
 			}
 
@@ -258,6 +263,18 @@ func InstallCmd() *cobra.Command {
 				}
 			}
 
+			imageVerificationPolicy := ""
+			if policyFile := v.GetString("image-verification-policy"); policyFile != "" {
+				policyData, err := os.ReadFile(policyFile)
+				if err != nil {
+					return errors.Wrap(err, "failed to read image verification policy")
+				}
+				if _, err := image.ParseImageVerificationPolicy(policyData); err != nil {
+					return errors.Wrap(err, "failed to parse image verification policy")
+				}
+				imageVerificationPolicy = string(policyData)
+			}
+
 			simultaneousUploads, _ := strconv.Atoi(v.GetString("airgap-upload-parallelism"))
 
 			additionalLabels := map[string]string{}
@@ -312,6 +329,8 @@ func InstallCmd() *cobra.Command {
 
 				RegistryConfig: *registryConfig,
 
+				ImageVerificationPolicy: imageVerificationPolicy,
+
 				IdentityConfig: *identityConfig,
 				IngressConfig:  *ingressConfig,
 			}
@@ -457,7 +476,7 @@ func InstallCmd() *cobra.Command {
 				}
 			}()
 
-			if deployOptions.License != nil {
+			if deployOptions.License != nil || util.IsUnlicensedUpstreamURI(deployOptions.UpstreamURI) {
 				log.ActionWithSpinner("Waiting for installation to complete")
 				status, err := ValidateAutomatedInstall(deployOptions, authSlug, apiEndpoint)
 				if err != nil {
@@ -553,6 +572,7 @@ func InstallCmd() *cobra.Command {
 	cmd.Flags().StringArray("additional-annotations", []string{}, "additional annotations to add to kotsadm pods")
 	cmd.Flags().StringArray("additional-labels", []string{}, "additional labels to add to kotsadm pods")
 	cmd.Flags().String("private-ca-configmap", "", "the name of a configmap containing private CAs to add to the kotsadm deployment")
+	cmd.Flags().String("image-verification-policy", "", "path to a policy with the cosign public keys or containers-policy.json that image signatures are verified against before images are pushed to the private registry")
 
 	registryFlags(cmd.Flags())
 
@@ -597,6 +617,11 @@ func promptForNamespace(upstreamURI string) (string, error) {
 		return "", errors.Wrap(err, "failed to parse uri")
 	}
 
+	defaultNamespace := u.Hostname()
+	if util.IsUnlicensedUpstreamURI(upstreamURI) {
+		defaultNamespace = util.UnlicensedUpstreamName(upstreamURI)
+	}
+
 	templates := &promptui.PromptTemplates{
 		Prompt:  "{{ . | bold }} ",
 		Valid:   "{{ . | green }} ",
@@ -607,7 +632,7 @@ func promptForNamespace(upstreamURI string) (string, error) {
 	prompt := promptui.Prompt{
 		Label:     "Enter the namespace to deploy to:",
 		Templates: templates,
-		Default:   u.Hostname(),
+		Default:   defaultNamespace,
 		Validate:  validateNamespace,
 		AllowEdit: true,
 	}
@@ -893,8 +918,16 @@ func CheckRBAC() error {
 	return nil
 }
 
+// getInstallAppSlug returns the slug of the app that is installed automatically by the admin console
+func getInstallAppSlug(deployOptions kotsadmtypes.DeployOptions) string {
+	if deployOptions.License != nil {
+		return deployOptions.License.Spec.AppSlug
+	}
+	return util.UnlicensedUpstreamName(deployOptions.UpstreamURI)
+}
+
 func ValidateAutomatedInstall(deployOptions kotsadmtypes.DeployOptions, authSlug string, apiEndpoint string) (storetypes.DownstreamVersionStatus, error) {
-	url := fmt.Sprintf("%s/app/%s/automated/status", apiEndpoint, deployOptions.License.Spec.AppSlug)
+	url := fmt.Sprintf("%s/app/%s/automated/status", apiEndpoint, getInstallAppSlug(deployOptions))
 
 	startTime := time.Now()
 
@@ -953,7 +986,7 @@ func getAutomatedInstallStatus(url string, authSlug string) (*tasks.TaskStatus,
 }
 
 func ValidatePreflightStatus(deployOptions kotsadmtypes.DeployOptions, authSlug string, apiEndpoint string) error {
-	url := fmt.Sprintf("%s/app/%s/preflight/result", apiEndpoint, deployOptions.License.Spec.AppSlug)
+	url := fmt.Sprintf("%s/app/%s/preflight/result", apiEndpoint, getInstallAppSlug(deployOptions))
 
 	startTime := time.Now()
 
@@ -12,6 +12,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/pull"
 	registrytypes "github.com/replicatedhq/kots/pkg/registry/types"
+	"github.com/replicatedhq/kots/pkg/util"
 	kotsv1beta1 "github.com/replicatedhq/kotskinds/apis/kots/v1beta1"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -117,9 +118,11 @@ func PullCmd() *cobra.Command {
 			if err != nil {
 				return errors.Wrap(err, "failed to verify and update license")
 			}
-			pullOptions.AppSelectedChannelID, err = kotsutil.FindChannelIDInLicense(preferredChannelSlug, license)
-			if err != nil { // should never happen since we just verified the channel
-				return errors.Wrap(err, "failed to find channel ID in license")
+			if license != nil {
+				pullOptions.AppSelectedChannelID, err = kotsutil.FindChannelIDInLicense(preferredChannelSlug, license)
+				if err != nil { // should never happen since we just verified the channel
+					return errors.Wrap(err, "failed to find channel ID in license")
+				}
 			}
 
 			renderDir, err := pull.Pull(upstream, pullOptions)
@@ -170,6 +173,9 @@ func PullCmd() *cobra.Command {
 }
 
 func getAppSlugForPull(uri string, license *kotsv1beta1.License) string {
+	if util.IsUnlicensedUpstreamURI(uri) {
+		return util.UnlicensedUpstreamName(uri)
+	}
 	appSlug := strings.Split(uri, "/")[0]
 	if license == nil {
 		return appSlug
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/logger"
@@ -46,6 +47,22 @@ func RestoreCmd() *cobra.Command {
 				return errors.New("--exclude-admin-console and --exclude-apps cannot be used together")
 			}
 
+			namespaceMapping := map[string]string{}
+			for _, mapping := range v.GetStringSlice("namespace-mapping") {
+				parts := strings.Split(mapping, "=")
+				if len(parts) != 2 {
+					return errors.Errorf("namespace-mapping flag is not in the correct format.  Must be source=target")
+				}
+				namespaceMapping[parts[0]] = parts[1]
+			}
+			if len(namespaceMapping) > 0 {
+				if v.GetString("app-slug") == "" || v.GetString("clone-app-slug") == "" {
+					return errors.New("--app-slug and --clone-app-slug are required with --namespace-mapping")
+				}
+			} else if v.GetString("clone-app-slug") != "" {
+				return errors.New("--clone-app-slug requires --namespace-mapping")
+			}
+
 			var restoreOutput RestoreOutput
 			options := snapshot.RestoreInstanceBackupOptions{
 				BackupName:          backupName,
@@ -54,6 +71,9 @@ func RestoreCmd() *cobra.Command {
 				WaitForApps:         v.GetBool("wait-for-apps"),
 				VeleroNamespace:     v.GetString("velero-namespace"),
 				Silent:              output != "",
+				AppSlug:             v.GetString("app-slug"),
+				NamespaceMapping:    namespaceMapping,
+				CloneAppSlug:        v.GetString("clone-app-slug"),
 			}
 			err := snapshot.RestoreInstanceBackup(cmd.Context(), options)
 			if err != nil && output == "" {
@@ -83,6 +103,9 @@ func RestoreCmd() *cobra.Command {
 	cmd.Flags().Bool("exclude-apps", false, "exclude restoring the application(s) and only restore the admin console")
 	cmd.Flags().Bool("wait-for-apps", true, "wait for all applications to be restored")
 	cmd.Flags().StringP("output", "o", "", "output format (currently supported: json)")
+	cmd.Flags().String("app-slug", "", "the slug of the application to restore next to the running application, requires --namespace-mapping")
+	cmd.Flags().StringSlice("namespace-mapping", []string{}, "restore only the application into other namespaces, without replacing it or the admin console. Must be source=target, and can be repeated for each namespace to restore")
+	cmd.Flags().String("clone-app-slug", "", "the app slug to label the restored resources with, requires --namespace-mapping")
 
 	cmd.AddCommand(RestoreListCmd())
 
@@ -55,6 +55,11 @@ func splitEndpointAndNamespace(endpoint string) (string, string) {
 }
 
 func extractPreferredChannelSlug(log *logger.CLILogger, upstreamURI string) (string, error) {
+	// helm charts and git repositories do not have channels
+	if util.IsUnlicensedUpstreamURI(upstreamURI) {
+		return "", nil
+	}
+
 	u, err := url.ParseRequestURI(upstreamURI)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to parse uri")
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/cluster/tunnel"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+func AgentCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "agent",
+		Short:         "Connect this cluster to a kotsadm instance that manages it remotely",
+		Long:          `The agent opens a connection to kotsadm and forwards requests from kotsadm to the api server of the cluster it runs in, so that kotsadm doesn't need network access to the cluster.`,
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			kotsadmURL := v.GetString("kotsadm-url")
+			if kotsadmURL == "" {
+				return errors.New("kotsadm-url is required")
+			}
+			deployToken := v.GetString("deploy-token")
+			if deployToken == "" {
+				return errors.New("deploy-token is required")
+			}
+
+			host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+			if host == "" || port == "" {
+				return errors.New("the agent must run in a pod")
+			}
+
+			ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			return tunnel.RunAgent(ctx, tunnel.AgentOptions{
+				KotsadmURL:       kotsadmURL,
+				DeployToken:      deployToken,
+				APIServerAddress: net.JoinHostPort(host, port),
+				Credentials:      serviceAccountCredentials,
+			})
+		},
+	}
+
+	cmd.Flags().String("kotsadm-url", "", "the address of the kotsadm instance to connect to")
+	cmd.Flags().String("deploy-token", "", "the deploy token that was returned when the cluster was registered")
+
+	return cmd
+}
+
+// serviceAccountCredentials returns the token and the ca bundle of the agent's service account.
+// The token file is read every time because the kubelet rotates it.
+func serviceAccountCredentials() (string, []byte, error) {
+	token, err := os.ReadFile(serviceAccountTokenFile)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to read service account token")
+	}
+
+	caData, err := os.ReadFile(serviceAccountCAFile)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to read service account ca")
+	}
+
+	return string(token), caData, nil
+}
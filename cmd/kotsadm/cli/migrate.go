@@ -5,6 +5,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/filestore"
+	"github.com/replicatedhq/kots/pkg/persistence"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -21,6 +22,7 @@ func MigrateCmd() *cobra.Command {
 
 	cmd.AddCommand(MigrateS3ToRqliteCmd())
 	cmd.AddCommand(MigratePVCToRqliteCmd())
+	cmd.AddCommand(MigrateRqliteToPostgresCmd())
 
 	return cmd
 }
@@ -97,3 +99,39 @@ func MigratePVCToRqliteCmd() *cobra.Command {
 
 	return cmd
 }
+
+func MigrateRqliteToPostgresCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "rqlite-to-postgres",
+		Short:         "Migrate the database from rqlite to postgres",
+		Long:          ``,
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Check if required env vars are set
+			if os.Getenv("RQLITE_URI") == "" {
+				return errors.New("RQLITE_URI is not set")
+			}
+			if os.Getenv("DATABASE_URI") == "" {
+				return errors.New("DATABASE_URI is not set")
+			}
+
+			// Check if the table specs exist
+			if _, err := os.Stat(persistence.SchemaDir()); err != nil {
+				return errors.Wrap(err, "failed to stat schema dir")
+			}
+
+			// Migrate from rqlite to postgres
+			if err := persistence.MigrateFromRqliteToPostgres(os.Getenv("RQLITE_URI"), os.Getenv("DATABASE_URI"), persistence.SchemaDir()); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
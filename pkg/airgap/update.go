@@ -16,6 +16,8 @@ import (
 	identity "github.com/replicatedhq/kots/pkg/kotsadmidentity"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/maintenancewindow"
+	maintenancewindowtypes "github.com/replicatedhq/kots/pkg/maintenancewindow/types"
 	"github.com/replicatedhq/kots/pkg/preflight"
 	"github.com/replicatedhq/kots/pkg/pull"
 	"github.com/replicatedhq/kots/pkg/render"
@@ -250,6 +252,22 @@ func UpdateAppFromPath(a *apptypes.App, airgapRoot string, airgapBundlePath stri
 	}
 
 	if deploy {
+		downstreams, err := store.GetStore().ListDownstreamsForApp(a.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to list downstreams for app")
+		}
+		if len(downstreams) == 0 {
+			return errors.Errorf("no downstreams found for app %q", a.Slug)
+		}
+
+		queued, err := maintenancewindow.QueueIfClosed(a.ID, downstreams[0].ClusterID, newSequence, maintenancewindowtypes.DeploySourceCLI)
+		if err != nil {
+			return errors.Wrap(err, "failed to check maintenance windows")
+		}
+		if queued {
+			return nil
+		}
+
 		if err := version.DeployVersion(a.ID, newSequence); err != nil {
 			return errors.Wrap(err, "failed to deploy app version")
 		}
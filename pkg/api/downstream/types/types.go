@@ -7,6 +7,8 @@ import (
 	"github.com/blang/semver"
 	"github.com/replicatedhq/kots/pkg/cursor"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
+	promotiontypes "github.com/replicatedhq/kots/pkg/promotion/types"
 	kotssemver "github.com/replicatedhq/kots/pkg/semver"
 	storetypes "github.com/replicatedhq/kots/pkg/store/types"
 	v1beta1 "github.com/replicatedhq/kotskinds/apis/kots/v1beta1"
@@ -19,6 +21,9 @@ type Downstream struct {
 	CurrentSequence  int64  `json:"currentSequence"`
 	SnapshotSchedule string `json:"snapshotSchedule,omitempty"`
 	SnapshotTTL      string `json:"snapshotTtl,omitempty"`
+	// ConnectionType is how kotsadm reaches the cluster: local, kubeconfig or agent
+	ConnectionType  string `json:"connectionType,omitempty"`
+	TargetNamespace string `json:"targetNamespace,omitempty"`
 }
 
 type DownstreamVersion struct {
@@ -36,6 +41,7 @@ type DownstreamVersion struct {
 	Source             string                             `json:"source"`
 	PreflightSkipped   bool                               `json:"preflightSkipped"`
 	CommitURL          string                             `json:"commitUrl,omitempty"`
+	PullRequestURL     string                             `json:"pullRequestUrl,omitempty"`
 	GitDeployable      bool                               `json:"gitDeployable,omitempty"`
 	UpstreamReleasedAt *time.Time                         `json:"upstreamReleasedAt,omitempty"`
 
@@ -56,6 +62,7 @@ type DownstreamVersion struct {
 	DownloadStatus             DownloadStatus                  `json:"downloadStatus,omitempty"`
 	AppTitle                   string                          `json:"appTitle,omitempty"`
 	AppIconURI                 string                          `json:"appIconUri,omitempty"`
+	Promotions                 []promotiontypes.Promotion      `json:"promotions,omitempty"`
 }
 
 type DownloadStatus struct {
@@ -151,11 +158,14 @@ func SortDownstreamVersionsByCursor(allVersions []*DownstreamVersion) {
 }
 
 type DownstreamOutput struct {
-	DryrunStdout string `json:"dryrunStdout"`
-	DryrunStderr string `json:"dryrunStderr"`
-	ApplyStdout  string `json:"applyStdout"`
-	ApplyStderr  string `json:"applyStderr"`
-	HelmStdout   string `json:"helmStdout"`
-	HelmStderr   string `json:"helmStderr"`
-	RenderError  string `json:"renderError"`
+	DryrunStdout       string                            `json:"dryrunStdout"`
+	DryrunStderr       string                            `json:"dryrunStderr"`
+	ApplyStdout        string                            `json:"applyStdout"`
+	ApplyStderr        string                            `json:"applyStderr"`
+	HelmStdout         string                            `json:"helmStdout"`
+	HelmStderr         string                            `json:"helmStderr"`
+	RenderError        string                            `json:"renderError"`
+	ResourceResults    []operatortypes.ResourceResult    `json:"resourceResults"`
+	HelmReleaseResults []operatortypes.HelmReleaseResult `json:"helmReleaseResults"`
+	RolloutLog         string                            `json:"rolloutLog,omitempty"`
 }
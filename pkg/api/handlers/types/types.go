@@ -7,6 +7,7 @@ import (
 	versiontypes "github.com/replicatedhq/kots/pkg/api/version/types"
 	apptypes "github.com/replicatedhq/kots/pkg/app/types"
 	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
 )
 
 type ListAppsResponse struct {
@@ -14,7 +15,8 @@ type ListAppsResponse struct {
 }
 
 type AppStatusResponse struct {
-	AppStatus *appstatetypes.AppStatus `json:"appstatus"`
+	AppStatus *appstatetypes.AppStatus      `json:"appstatus"`
+	Drift     *operatortypes.AppDriftStatus `json:"drift,omitempty"`
 }
 
 type ResponseApp struct {
@@ -1,14 +1,18 @@
 package apiserver
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/crypto"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/keyrotation"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/util"
 )
 
 type BootstrapParams struct {
@@ -87,5 +91,15 @@ func loadEncryptionKeys() error {
 		}
 	}
 
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get clientset")
+	}
+
+	// a key rotation that was interrupted leaves values encrypted with the previous key
+	if err := keyrotation.LoadPreviousKey(context.TODO(), clientset, util.PodNamespace); err != nil {
+		return errors.Wrap(err, "failed to load previous encryption key")
+	}
+
 	return nil
 }
@@ -13,10 +13,14 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/replicatedhq/kots/pkg/automation"
 	"github.com/replicatedhq/kots/pkg/binaries"
+	"github.com/replicatedhq/kots/pkg/cluster/tunnel"
 	"github.com/replicatedhq/kots/pkg/handlers"
 	identitymigrate "github.com/replicatedhq/kots/pkg/identity/migrate"
 	"github.com/replicatedhq/kots/pkg/informers"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/maintenancewindow"
+	"github.com/replicatedhq/kots/pkg/metrics"
+	"github.com/replicatedhq/kots/pkg/notifications"
 	"github.com/replicatedhq/kots/pkg/operator"
 	operatorclient "github.com/replicatedhq/kots/pkg/operator/client"
 	"github.com/replicatedhq/kots/pkg/persistence"
@@ -27,6 +31,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/snapshotscheduler"
 	"github.com/replicatedhq/kots/pkg/store"
 	"github.com/replicatedhq/kots/pkg/supportbundle"
+	"github.com/replicatedhq/kots/pkg/supportbundlescheduler"
 	"github.com/replicatedhq/kots/pkg/update"
 	"github.com/replicatedhq/kots/pkg/updatechecker"
 	"github.com/replicatedhq/kots/pkg/upgradeservice"
@@ -43,6 +48,12 @@ type APIServerParams struct {
 func Start(params *APIServerParams) {
 	log.Printf("kotsadm version %s\n", params.Version)
 
+	// the rqlite schema is applied by the migrations init containers
+	if err := persistence.UpdateSchema(); err != nil {
+		log.Println("error updating database schema")
+		panic(err)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	if err := store.GetStore().WaitForReady(ctx); err != nil {
 		log.Println("error waiting for ready")
@@ -68,11 +79,6 @@ func Start(params *APIServerParams) {
 		log.Println("Failed to run identity migrations: ", err)
 	}
 
-	if err := binaries.InitKubectl(); err != nil {
-		log.Println("error initializing kubectl binaries package")
-		panic(err)
-	}
-
 	if err := binaries.InitKustomize(); err != nil {
 		log.Println("error initializing kustomize binaries package")
 		panic(err)
@@ -119,6 +125,10 @@ func Start(params *APIServerParams) {
 		log.Println("failed to initialize reporting:", err)
 	}
 
+	notifications.Start(kotsStore)
+	metrics.Init(kotsStore)
+	metrics.StartServer()
+
 	supportbundle.StartServer()
 
 	if err := informers.Start(); err != nil {
@@ -128,10 +138,17 @@ func Start(params *APIServerParams) {
 	if err := updatechecker.Start(); err != nil {
 		log.Println("Failed to start update checker:", err)
 	}
+
+	maintenancewindow.Start()
+
 	if err := snapshotscheduler.Start(); err != nil {
 		log.Println("Failed to start snapshot scheduler:", err)
 	}
 
+	if err := supportbundlescheduler.Start(); err != nil {
+		log.Println("Failed to start support bundle scheduler:", err)
+	}
+
 	if err := session.StartSessionPurgeCronJob(); err != nil {
 		log.Println("Failed to start session purge cron job:", err)
 	}
@@ -174,17 +191,25 @@ func Start(params *APIServerParams) {
 		wsRouter.HandleFunc("/ec-ws", handler.ConnectToECWebsocket)
 	}
 
+	/**********************************************************************
+	* Remote cluster agent routes (authenticated with the cluster's deploy token)
+	**********************************************************************/
+
+	agentRouter := r.NewRoute().Subrouter()
+	agentRouter.Path(tunnel.ConnectPath).HandlerFunc(handler.ConnectClusterAgent)
+	agentRouter.Path(tunnel.TunnelPath + "/{tunnelId}").HandlerFunc(handler.ConnectClusterAgentTunnel)
+
 	/**********************************************************************
 	* KOTS token auth routes
 	**********************************************************************/
 
-	handlers.RegisterTokenAuthRoutes(handler, debugRouter, loggingRouter)
+	handlers.RegisterTokenAuthRoutes(handler, kotsStore, debugRouter, loggingRouter)
 
 	/**********************************************************************
 	* Session auth routes
 	**********************************************************************/
 
-	policyMiddleware := policy.NewMiddleware(kotsStore, rbac.DefaultRoles())
+	policyMiddleware := policy.NewMiddlewareWithRolesGetter(kotsStore, rbac.GetRoles)
 
 	sessionAuthQuietRouter := r.PathPrefix("").Subrouter()
 	sessionAuthQuietRouter.Use(handlers.RequireValidSessionQuietMiddleware(kotsStore))
@@ -3,10 +3,19 @@ package apparchive
 import (
 	"bufio"
 	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/util"
 	"github.com/sergi/go-diff/diffmatchpatch"
+	"gopkg.in/yaml.v3"
 )
 
 type Diff struct {
@@ -140,3 +149,328 @@ func diffAppFiles(archive map[string][]byte, base map[string][]byte) (*Diff, err
 
 	return &diff, nil
 }
+
+type ResourceDiffStatus string
+
+const (
+	ResourceDiffAdded    ResourceDiffStatus = "added"
+	ResourceDiffRemoved  ResourceDiffStatus = "removed"
+	ResourceDiffModified ResourceDiffStatus = "modified"
+)
+
+const (
+	secretRedactionMask        = "--- REDACTED ---"
+	changedSecretRedactionMask = "--- REDACTED (changed) ---"
+)
+
+// ResourceDiff is the diff of a single rendered resource between two versions
+type ResourceDiff struct {
+	APIVersion   string             `json:"apiVersion"`
+	Kind         string             `json:"kind"`
+	Namespace    string             `json:"namespace,omitempty"`
+	Name         string             `json:"name"`
+	Status       ResourceDiffStatus `json:"status"`
+	Diff         string             `json:"diff"`
+	LinesAdded   int                `json:"linesAdded"`
+	LinesRemoved int                `json:"linesRemoved"`
+}
+
+type resourceKey struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+}
+
+func (k resourceKey) String() string {
+	parts := []string{k.APIVersion, k.Kind}
+	if k.Namespace != "" {
+		parts = append(parts, k.Namespace)
+	}
+	parts = append(parts, k.Name)
+	return strings.Join(parts, "/")
+}
+
+// DiffAppVersionResourcesForDownstream renders both archive dirs and returns the resources that were added, removed or modified.
+// Resources are matched by apiVersion, kind, namespace and name, and the data of secrets is redacted.
+func DiffAppVersionResourcesForDownstream(downstreamName string, archive string, diffBasePath string, kustomizeBinPath string) ([]ResourceDiff, error) {
+	archiveResources, err := getRenderedResources(archive, downstreamName, kustomizeBinPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get rendered resources")
+	}
+
+	baseResources, err := getRenderedResources(diffBasePath, downstreamName, kustomizeBinPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get base rendered resources")
+	}
+
+	return diffResources(archiveResources, baseResources)
+}
+
+func getRenderedResources(archive string, downstreamName string, kustomizeBinPath string) (map[resourceKey][]byte, error) {
+	_, appFiles, err := GetRenderedApp(archive, downstreamName, kustomizeBinPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get rendered app")
+	}
+
+	_, v1Beta1ChartFiles, err := GetRenderedV1Beta1ChartsArchive(archive, downstreamName, kustomizeBinPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get rendered charts files")
+	}
+
+	v1Beta2ChartFiles, err := templateV1Beta2Charts(archive)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to template v1beta2 charts")
+	}
+
+	resources := map[resourceKey][]byte{}
+	for _, files := range []map[string][]byte{appFiles, v1Beta1ChartFiles, v1Beta2ChartFiles} {
+		if err := addResources(resources, files); err != nil {
+			return nil, err
+		}
+	}
+
+	return resources, nil
+}
+
+// templateV1Beta2Charts templates the v1beta2 charts in the archive with their values, so that the resources of the
+// charts are diffed instead of the chart archives and the values files. Secrets in the values only show up in the
+// rendered secrets, which are redacted.
+func templateV1Beta2Charts(archive string) (map[string][]byte, error) {
+	kotsKinds, err := kotsutil.LoadKotsKinds(archive)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load kots kinds")
+	}
+	if kotsKinds.V1Beta2HelmCharts == nil {
+		return nil, nil
+	}
+
+	files := map[string][]byte{}
+	for _, helmChart := range kotsKinds.V1Beta2HelmCharts.Items {
+		if !helmChart.Spec.Exclude.IsEmpty() {
+			exclude, err := helmChart.Spec.Exclude.Boolean()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse exclude boolean")
+			}
+
+			if exclude {
+				continue
+			}
+		}
+
+		chartDir := filepath.Join(archive, "helm", helmChart.GetDirName())
+		if _, err := os.Stat(chartDir); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrap(err, "failed to stat chart dir")
+		}
+
+		manifests, err := templateV1Beta2HelmChartWithValues(&helmChart, chartDir, filepath.Join(chartDir, "values.yaml"), logger.Debugf)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to template chart %s", helmChart.GetDirName())
+		}
+		files[filepath.Join(helmChart.GetDirName(), "all.yaml")] = manifests
+	}
+
+	return files, nil
+}
+
+// addResources splits the files into documents and adds them to resources. Documents that are not kubernetes
+// resources are keyed by their file name.
+func addResources(resources map[resourceKey][]byte, files map[string][]byte) error {
+	for filename, content := range files {
+		for i, doc := range util.ConvertToSingleDocs(content) {
+			doc = bytes.TrimPrefix(doc, []byte("---\n"))
+
+			o := util.OverlySimpleGVK{}
+			_ = yaml.Unmarshal(doc, &o)
+
+			key := resourceKey{
+				APIVersion: o.APIVersion,
+				Kind:       o.Kind,
+				Namespace:  o.Metadata.Namespace,
+				Name:       o.Metadata.Name,
+			}
+			if key.Kind == "" || key.Name == "" {
+				key = resourceKey{Name: filename}
+				if i > 0 {
+					key.Name = fmt.Sprintf("%s-%d", filename, i)
+				}
+			}
+
+			if existing, ok := resources[key]; ok {
+				doc = bytes.Join([][]byte{existing, doc}, []byte("\n---\n"))
+			}
+			resources[key] = doc
+		}
+	}
+	return nil
+}
+
+func diffResources(archive map[resourceKey][]byte, base map[resourceKey][]byte) ([]ResourceDiff, error) {
+	keys := map[resourceKey]bool{}
+	for key := range archive {
+		keys[key] = true
+	}
+	for key := range base {
+		keys[key] = true
+	}
+
+	diffs := []ResourceDiff{}
+	for key := range keys {
+		archiveContents, inArchive := archive[key]
+		baseContents, inBase := base[key]
+
+		if key.Kind == "Secret" {
+			var err error
+			if inBase {
+				baseContents, err = redactSecret(baseContents, nil)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to redact base secret %s", key)
+				}
+			}
+			if inArchive {
+				archiveContents, err = redactSecret(archiveContents, base[key])
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to redact secret %s", key)
+				}
+			}
+		}
+
+		resourceDiff := ResourceDiff{
+			APIVersion: key.APIVersion,
+			Kind:       key.Kind,
+			Namespace:  key.Namespace,
+			Name:       key.Name,
+		}
+		switch {
+		case !inBase:
+			resourceDiff.Status = ResourceDiffAdded
+		case !inArchive:
+			resourceDiff.Status = ResourceDiffRemoved
+		case bytes.Equal(archiveContents, baseContents):
+			continue
+		default:
+			resourceDiff.Status = ResourceDiffModified
+		}
+
+		linesAdded, linesRemoved, err := diffContent(string(baseContents), string(archiveContents))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to diff resource %s", key)
+		}
+		if resourceDiff.Status == ResourceDiffModified && linesAdded == 0 && linesRemoved == 0 {
+			continue
+		}
+		resourceDiff.LinesAdded = linesAdded
+		resourceDiff.LinesRemoved = linesRemoved
+
+		unifiedDiff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(string(baseContents)),
+			B:        splitLines(string(archiveContents)),
+			FromFile: "a/" + key.String(),
+			ToFile:   "b/" + key.String(),
+			Context:  3,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create unified diff for %s", key)
+		}
+		resourceDiff.Diff = unifiedDiff
+
+		diffs = append(diffs, resourceDiff)
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		a, b := diffs[i], diffs[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.APIVersion < b.APIVersion
+	})
+
+	return diffs, nil
+}
+
+// splitLines splits content into lines that keep their line endings. Unlike difflib.SplitLines, no empty line is
+// added at the end of content that ends with a newline.
+func splitLines(content string) []string {
+	if content == "" {
+		return []string{}
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// redactSecret replaces the values in the data and stringData of a secret with a mask. If the base secret is passed,
+// values that differ from the base are replaced with a different mask so that the change still shows up in the diff.
+func redactSecret(content []byte, base []byte) ([]byte, error) {
+	baseValues := map[string]string{}
+	if base != nil {
+		baseNode := yaml.Node{}
+		if err := yaml.Unmarshal(base, &baseNode); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal base secret")
+		}
+		walkSecretValues(&baseNode, func(field string, key string, value *yaml.Node) {
+			baseValues[field+"/"+key] = value.Value
+		})
+	}
+
+	node := yaml.Node{}
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal secret")
+	}
+	walkSecretValues(&node, func(field string, key string, value *yaml.Node) {
+		baseValue, ok := baseValues[field+"/"+key]
+		if base != nil && (!ok || baseValue != value.Value) {
+			value.Value = changedSecretRedactionMask
+		} else {
+			value.Value = secretRedactionMask
+		}
+		value.Kind = yaml.ScalarNode
+		value.Tag = "!!str"
+		value.Style = 0
+		value.Content = nil
+	})
+
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, errors.Wrap(err, "failed to marshal secret")
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to close encoder")
+	}
+
+	return b.Bytes(), nil
+}
+
+func walkSecretValues(node *yaml.Node, fn func(field string, key string, value *yaml.Node)) {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		field, values := node.Content[i].Value, node.Content[i+1]
+		if field != "data" && field != "stringData" {
+			continue
+		}
+		if values.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(values.Content); j += 2 {
+			fn(field, values.Content[j].Value, values.Content[j+1])
+		}
+	}
+}
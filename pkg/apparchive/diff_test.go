@@ -1,10 +1,14 @@
 package apparchive
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"gopkg.in/go-playground/assert.v1"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
 )
 
 func Test_diffContent(t *testing.T) {
@@ -160,3 +164,195 @@ three lines`),
 		})
 	}
 }
+
+func Test_diffResources(t *testing.T) {
+	base := map[string][]byte{
+		"deployment.yaml": []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`),
+		"configmaps.yaml": []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: removed
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unchanged
+data:
+  key: value
+`),
+		"secret.yaml": []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+data:
+  password: b2xk
+  username: YWRtaW4=
+`),
+	}
+	archive := map[string][]byte{
+		"web-deployment.yaml": []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+`),
+		"unchanged-configmap.yaml": []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unchanged
+data:
+  key: value
+`),
+		"added-service.yaml": []byte(`apiVersion: v1
+kind: Service
+metadata:
+  name: added
+  namespace: other
+`),
+		"secret.yaml": []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+data:
+  password: bmV3
+  username: YWRtaW4=
+`),
+	}
+
+	req := require.New(t)
+
+	archiveResources := map[resourceKey][]byte{}
+	req.NoError(addResources(archiveResources, archive))
+	baseResources := map[resourceKey][]byte{}
+	req.NoError(addResources(baseResources, base))
+
+	diffs, err := diffResources(archiveResources, baseResources)
+	req.NoError(err)
+	req.Len(diffs, 4)
+
+	req.Equal("removed", diffs[0].Name)
+	req.Equal(ResourceDiffRemoved, diffs[0].Status)
+
+	req.Equal("web", diffs[1].Name)
+	req.Equal(ResourceDiffModified, diffs[1].Status)
+	req.Equal(1, diffs[1].LinesAdded)
+	req.Equal(1, diffs[1].LinesRemoved)
+	req.Contains(diffs[1].Diff, "--- a/apps/v1/Deployment/web\n+++ b/apps/v1/Deployment/web\n")
+	req.Contains(diffs[1].Diff, "-  replicas: 1\n+  replicas: 2\n")
+
+	req.Equal("credentials", diffs[2].Name)
+	req.Equal(ResourceDiffModified, diffs[2].Status)
+	req.Contains(diffs[2].Diff, "-  password: '--- REDACTED ---'\n+  password: '--- REDACTED (changed) ---'\n")
+	req.NotContains(diffs[2].Diff, "bmV3")
+	req.NotContains(diffs[2].Diff, "b2xk")
+	req.NotContains(diffs[2].Diff, "YWRtaW4=")
+
+	req.Equal("added", diffs[3].Name)
+	req.Equal("other", diffs[3].Namespace)
+	req.Equal(ResourceDiffAdded, diffs[3].Status)
+}
+
+func Test_templateV1Beta2ChartsDiff(t *testing.T) {
+	helmChart := `apiVersion: kots.io/v1beta2
+kind: HelmChart
+metadata:
+  name: my-chart
+spec:
+  chart:
+    name: my-chart
+    chartVersion: 1.0.0
+  namespace: my-app
+`
+	templates := []*chart.File{
+		{
+			Name: "templates/secret.yaml",
+			Data: []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: {{ .Release.Namespace }}
+stringData:
+  password: {{ .Values.password }}
+`),
+		},
+		{
+			Name: "templates/configmap.yaml",
+			Data: []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: {{ .Release.Namespace }}
+data:
+  replicas: "{{ .Values.replicas }}"
+`),
+		},
+	}
+
+	writeArchive := func(values string) string {
+		archive := t.TempDir()
+
+		kotsKindsDir := filepath.Join(archive, "kotsKinds")
+		require.NoError(t, os.MkdirAll(kotsKindsDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(kotsKindsDir, "my-chart.yaml"), []byte(helmChart), 0644))
+
+		chartDir := filepath.Join(archive, "helm", "my-chart")
+		require.NoError(t, os.MkdirAll(chartDir, 0755))
+		_, err := chartutil.Save(&chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion: chart.APIVersionV2,
+				Name:       "my-chart",
+				Version:    "1.0.0",
+			},
+			Templates: templates,
+		}, chartDir)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(chartDir, "values.yaml"), []byte(values), 0644))
+
+		return archive
+	}
+
+	base := writeArchive("password: oldpassword\nreplicas: 1\n")
+	archive := writeArchive("password: newpassword\nreplicas: 2\n")
+
+	req := require.New(t)
+
+	baseFiles, err := templateV1Beta2Charts(base)
+	req.NoError(err)
+	archiveFiles, err := templateV1Beta2Charts(archive)
+	req.NoError(err)
+	req.Len(archiveFiles, 1)
+	req.Contains(archiveFiles, filepath.Join("my-chart", "all.yaml"))
+
+	baseResources := map[resourceKey][]byte{}
+	req.NoError(addResources(baseResources, baseFiles))
+	archiveResources := map[resourceKey][]byte{}
+	req.NoError(addResources(archiveResources, archiveFiles))
+
+	diffs, err := diffResources(archiveResources, baseResources)
+	req.NoError(err)
+	req.Len(diffs, 2)
+
+	req.Equal("ConfigMap", diffs[0].Kind)
+	req.Equal("my-app", diffs[0].Namespace)
+	req.Equal("settings", diffs[0].Name)
+	req.Equal(ResourceDiffModified, diffs[0].Status)
+	req.Contains(diffs[0].Diff, "-  replicas: \"1\"\n+  replicas: \"2\"\n")
+
+	req.Equal("Secret", diffs[1].Kind)
+	req.Equal("my-app", diffs[1].Namespace)
+	req.Equal("db", diffs[1].Name)
+	req.Equal(ResourceDiffModified, diffs[1].Status)
+	req.Contains(diffs[1].Diff, "+  password: '--- REDACTED (changed) ---'\n")
+
+	for _, diff := range diffs {
+		req.NotContains(diff.Diff, "oldpassword")
+		req.NotContains(diff.Diff, "newpassword")
+	}
+}
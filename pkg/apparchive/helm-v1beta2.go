@@ -234,6 +234,25 @@ func WriteRenderedV1Beta2HelmCharts(opts WriteRenderedV1Beta2HelmChartsOptions)
 }
 
 func templateV1Beta2HelmChartWithValuesToDir(helmChart *kotsv1beta2.HelmChart, chartDir, valuesPath, outputDir string, log func(string, ...interface{})) error {
+	manifests, err := templateV1Beta2HelmChartWithValues(helmChart, chartDir, valuesPath, log)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(outputDir, 0744); err != nil {
+		return errors.Wrap(err, "failed to create rendered path")
+	}
+
+	err = os.WriteFile(filepath.Join(outputDir, "all.yaml"), manifests, 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to write all.yaml")
+	}
+
+	return nil
+}
+
+// templateV1Beta2HelmChartWithValues templates the chart archive in the chart dir with the values file and returns the manifests, including hooks
+func templateV1Beta2HelmChartWithValues(helmChart *kotsv1beta2.HelmChart, chartDir, valuesPath string, log func(string, ...interface{})) ([]byte, error) {
 	cfg := &action.Configuration{
 		Log: log,
 	}
@@ -253,23 +272,23 @@ func templateV1Beta2HelmChartWithValuesToDir(helmChart *kotsv1beta2.HelmChart, c
 
 	chartRequested, err := loader.Load(chartPath)
 	if err != nil {
-		return errors.Wrap(err, "failed to load chart")
+		return nil, errors.Wrap(err, "failed to load chart")
 	}
 
 	if req := chartRequested.Metadata.Dependencies; req != nil {
 		if err := action.CheckDependencies(chartRequested, req); err != nil {
-			return errors.Wrap(err, "failed dependency check")
+			return nil, errors.Wrap(err, "failed dependency check")
 		}
 	}
 
 	values, err := chartutil.ReadValuesFile(valuesPath)
 	if err != nil {
-		return errors.Wrap(err, "failed to read values file")
+		return nil, errors.Wrap(err, "failed to read values file")
 	}
 
 	rel, err := client.Run(chartRequested, values)
 	if err != nil {
-		return errors.Wrap(err, "failed to run helm install")
+		return nil, errors.Wrap(err, "failed to run helm install")
 	}
 
 	var manifests bytes.Buffer
@@ -277,21 +296,8 @@ func templateV1Beta2HelmChartWithValuesToDir(helmChart *kotsv1beta2.HelmChart, c
 	for _, m := range rel.Hooks {
 		fmt.Fprintf(&manifests, "---\n# Source: %s\n%s\n", m.Path, m.Manifest)
 	}
-	// add hooks
-	for _, m := range rel.Hooks {
-		fmt.Fprintf(&manifests, "---\n# Source: %s\n%s\n", m.Path, m.Manifest)
-	}
 
-	if err := os.MkdirAll(outputDir, 0744); err != nil {
-		return errors.Wrap(err, "failed to create rendered path")
-	}
-
-	err = os.WriteFile(filepath.Join(outputDir, "all.yaml"), manifests.Bytes(), 0644)
-	if err != nil {
-		return errors.Wrap(err, "failed to write all.yaml")
-	}
-
-	return nil
+	return manifests.Bytes(), nil
 }
 
 func findV1Beta2HelmChartImages(opts WriteV1Beta2HelmChartsOptions, helmChart *kotsv1beta2.HelmChart, chartDir string) ([]string, error) {
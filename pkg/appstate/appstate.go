@@ -9,12 +9,14 @@ import (
 	"github.com/replicatedhq/kots/pkg/appstate/types"
 	corev1 "k8s.io/api/core/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 )
 
 type Monitor struct {
 	clientset       kubernetes.Interface
+	dynamicClient   dynamic.Interface
 	targetNamespace string
 	appInformersCh  chan appInformer
 	appStatusCh     chan types.AppStatus
@@ -33,13 +35,16 @@ type appInformer struct {
 	informers []types.StatusInformer
 }
 
-func NewMonitor(clientset kubernetes.Interface, targetNamespace string) *Monitor {
+// NewMonitor returns a monitor for the apps in the target namespace.
+// The dynamic client is used for kinds that have no controller of their own, such as custom resources, and can be nil to not monitor those.
+func NewMonitor(clientset kubernetes.Interface, dynamicClient dynamic.Interface, targetNamespace string) *Monitor {
 	if targetNamespace == "" {
 		targetNamespace = corev1.NamespaceDefault
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 	m := &Monitor{
 		clientset:       clientset,
+		dynamicClient:   dynamicClient,
 		targetNamespace: targetNamespace,
 		appInformersCh:  make(chan appInformer),
 		appStatusCh:     make(chan types.AppStatus),
@@ -92,7 +97,7 @@ func (m *Monitor) run(ctx context.Context) {
 				if appMonitor != nil {
 					appMonitor.Shutdown()
 				}
-				appMonitor = NewAppMonitor(m.clientset, m.targetNamespace, appInformer.appID, appInformer.sequence)
+				appMonitor = NewAppMonitor(m.clientset, m.dynamicClient, m.targetNamespace, appInformer.appID, appInformer.sequence)
 				go func() {
 					for appStatus := range appMonitor.AppStatusChan() {
 						m.appStatusCh <- appStatus
@@ -107,6 +112,7 @@ func (m *Monitor) run(ctx context.Context) {
 
 type AppMonitor struct {
 	clientset       kubernetes.Interface
+	dynamicClient   dynamic.Interface
 	targetNamespace string
 	appID           string
 	informersCh     chan []types.StatusInformer
@@ -115,11 +121,12 @@ type AppMonitor struct {
 	sequence        int64
 }
 
-func NewAppMonitor(clientset kubernetes.Interface, targetNamespace, appID string, sequence int64) *AppMonitor {
+func NewAppMonitor(clientset kubernetes.Interface, dynamicClient dynamic.Interface, targetNamespace, appID string, sequence int64) *AppMonitor {
 	ctx, cancel := context.WithCancel(context.Background())
 	m := &AppMonitor{
 		appID:           appID,
 		clientset:       clientset,
+		dynamicClient:   dynamicClient,
 		targetNamespace: targetNamespace,
 		informersCh:     make(chan []types.StatusInformer),
 		appStatusCh:     make(chan types.AppStatus),
@@ -213,9 +220,11 @@ func (m *AppMonitor) runInformers(ctx context.Context, informers []types.StatusI
 	}
 
 	kindImpls := map[string]runControllerFunc{
+		CronJobResourceKind:               runCronJobController,
 		DaemonSetResourceKind:             runDaemonSetController,
 		DeploymentResourceKind:            runDeploymentController,
 		IngressResourceKind:               runIngressController,
+		JobResourceKind:                   runJobController,
 		PersistentVolumeClaimResourceKind: runPersistentVolumeClaimController,
 		ServiceResourceKind:               runServiceController,
 		StatefulSetResourceKind:           runStatefulSetController,
@@ -224,6 +233,8 @@ func (m *AppMonitor) runInformers(ctx context.Context, informers []types.StatusI
 		for kind, informers := range kinds {
 			if impl, ok := kindImpls[kind]; ok {
 				goRun(impl, namespace, informers)
+			} else if m.dynamicClient != nil {
+				goRun(runCustomResourceControllerFunc(m.clientset.Discovery(), m.dynamicClient, kind), namespace, informers)
 			} else {
 				log.Printf("Informer requested for unsupported resource kind %v", kind)
 			}
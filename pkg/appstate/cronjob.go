@@ -0,0 +1,161 @@
+package appstate
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/replicatedhq/kots/pkg/appstate/types"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	CronJobResourceKind = "cronjob"
+	CronJobOwnerKind    = "CronJob"
+)
+
+type cronJobEventHandler struct {
+	informers       []types.StatusInformer
+	resourceStateCh chan<- types.ResourceState
+	clientset       kubernetes.Interface
+	targetNamespace string
+}
+
+func init() {
+	registerResourceKindNames(CronJobResourceKind, "cronjobs", "cj")
+}
+
+func runCronJobController(
+	ctx context.Context, clientset kubernetes.Interface, targetNamespace string,
+	informers []types.StatusInformer, resourceStateCh chan<- types.ResourceState,
+) {
+	listwatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return clientset.BatchV1().CronJobs(targetNamespace).List(context.TODO(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return clientset.BatchV1().CronJobs(targetNamespace).Watch(context.TODO(), options)
+		},
+	}
+	informer := cache.NewSharedInformer(
+		listwatch,
+		&batchv1.CronJob{},
+		time.Minute,
+	)
+
+	eventHandler := &cronJobEventHandler{
+		informers:       informers,
+		resourceStateCh: resourceStateCh,
+		clientset:       clientset,
+		targetNamespace: targetNamespace,
+	}
+
+	runInformer(ctx, informer, eventHandler)
+	return
+}
+
+func (h *cronJobEventHandler) ObjectCreated(obj interface{}) {
+	r := h.cast(obj)
+	if _, ok := h.getInformer(r); !ok {
+		return
+	}
+	h.resourceStateCh <- makeCronJobResourceState(r, CalculateCronJobState(h.clientset, h.targetNamespace, r))
+}
+
+func (h *cronJobEventHandler) ObjectUpdated(obj interface{}) {
+	r := h.cast(obj)
+	if _, ok := h.getInformer(r); !ok {
+		return
+	}
+	h.resourceStateCh <- makeCronJobResourceState(r, CalculateCronJobState(h.clientset, h.targetNamespace, r))
+}
+
+func (h *cronJobEventHandler) ObjectDeleted(obj interface{}) {
+	r := h.cast(obj)
+	if _, ok := h.getInformer(r); !ok {
+		return
+	}
+	h.resourceStateCh <- makeCronJobResourceState(r, types.StateMissing)
+}
+
+func (h *cronJobEventHandler) cast(obj interface{}) *batchv1.CronJob {
+	r, _ := obj.(*batchv1.CronJob)
+	return r
+}
+
+func (h *cronJobEventHandler) getInformer(r *batchv1.CronJob) (types.StatusInformer, bool) {
+	if r != nil {
+		for _, informer := range h.informers {
+			if r.Namespace == informer.Namespace && r.Name == informer.Name {
+				return informer, true
+			}
+		}
+	}
+	return types.StatusInformer{}, false
+}
+
+func makeCronJobResourceState(r *batchv1.CronJob, state types.State) types.ResourceState {
+	return types.ResourceState{
+		Kind:      CronJobResourceKind,
+		Name:      r.Name,
+		Namespace: r.Namespace,
+		State:     state,
+	}
+}
+
+// CalculateCronJobState returns the state of the last job of the cron job that finished.
+// A failed run only degrades the cron job, since the next run may succeed.
+func CalculateCronJobState(clientset kubernetes.Interface, targetNamespace string, r *batchv1.CronJob) types.State {
+	if r == nil {
+		return types.StateMissing
+	}
+
+	jobs, err := clientset.BatchV1().Jobs(targetNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		log.Printf("failed to get cronjob job list: %s", err)
+		return types.StateUnavailable
+	}
+
+	return calculateCronJobStateFromJobs(r, jobs.Items)
+}
+
+func calculateCronJobStateFromJobs(r *batchv1.CronJob, jobs []batchv1.Job) types.State {
+	owned := []batchv1.Job{}
+	for _, job := range jobs {
+		for _, owner := range job.ObjectMeta.OwnerReferences {
+			if owner.Kind == CronJobOwnerKind && owner.Name == r.ObjectMeta.Name {
+				owned = append(owned, job)
+				break
+			}
+		}
+	}
+
+	sort.Slice(owned, func(i, j int) bool {
+		return owned[j].CreationTimestamp.Before(&owned[i].CreationTimestamp)
+	})
+
+	isRunning := false
+	for _, job := range owned {
+		switch CalculateJobState(&job) {
+		case types.StateReady:
+			return types.StateReady
+		case types.StateUnavailable:
+			return types.StateDegraded
+		default:
+			isRunning = true
+		}
+	}
+
+	// the first run of the cron job has not finished yet
+	if isRunning {
+		return types.StateUpdating
+	}
+
+	return types.StateReady
+}
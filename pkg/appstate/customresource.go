@@ -0,0 +1,319 @@
+package appstate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/appstate/types"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	// StatusJSONPathAnnotation is a JSONPath expression, e.g. {.status.phase}, that is used instead of the status conditions to get the state of a custom resource
+	StatusJSONPathAnnotation = "kots.io/status-jsonpath"
+	// StatusMappingAnnotation maps the values of the JSONPath expression to states, e.g. Running=ready,Pending=updating,Failed=unavailable.
+	// Without a mapping, values that are the name of a state, or true and false, are used.
+	StatusMappingAnnotation = "kots.io/status-mapping"
+)
+
+// custom resource kinds are resolved through discovery, and retried in case the CRD is created after the informers
+var customResourceResolveInterval = 10 * time.Second
+
+type customResourceEventHandler struct {
+	kind            string
+	informers       []types.StatusInformer
+	resourceStateCh chan<- types.ResourceState
+	isNamespaced    bool
+}
+
+// runCustomResourceControllerFunc returns a controller for a kind that has no controller of its own.
+// The kind is a resource, optionally with its version and group, e.g. "certificates.v1.cert-manager.io" or "certificate.cert-manager.io".
+func runCustomResourceControllerFunc(discoveryClient discovery.DiscoveryInterface, dynamicClient dynamic.Interface, kind string) runControllerFunc {
+	return func(
+		ctx context.Context, clientset kubernetes.Interface, targetNamespace string,
+		informers []types.StatusInformer, resourceStateCh chan<- types.ResourceState,
+	) {
+		var mapping *meta.RESTMapping
+		for {
+			m, err := resolveCustomResourceKind(discoveryClient, kind)
+			if err == nil {
+				mapping = m
+				break
+			}
+			log.Printf("Failed to resolve resource kind %v for informers: %v", kind, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(customResourceResolveInterval):
+			}
+		}
+
+		isNamespaced := mapping.Scope.Name() == meta.RESTScopeNameNamespace
+
+		var ri dynamic.ResourceInterface
+		if isNamespaced {
+			ri = dynamicClient.Resource(mapping.Resource).Namespace(targetNamespace)
+		} else {
+			ri = dynamicClient.Resource(mapping.Resource)
+		}
+
+		listwatch := &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return ri.List(context.TODO(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return ri.Watch(context.TODO(), options)
+			},
+		}
+		informer := cache.NewSharedInformer(
+			listwatch,
+			&unstructured.Unstructured{},
+			time.Minute,
+		)
+
+		eventHandler := &customResourceEventHandler{
+			kind:            kind,
+			informers:       informers,
+			resourceStateCh: resourceStateCh,
+			isNamespaced:    isNamespaced,
+		}
+
+		runInformer(ctx, informer, eventHandler)
+	}
+}
+
+func resolveCustomResourceKind(discoveryClient discovery.DiscoveryInterface, kind string) (*meta.RESTMapping, error) {
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+
+	gvr, gr := schema.ParseResourceArg(kind)
+	candidates := []schema.GroupVersionResource{gr.WithVersion("")}
+	if gvr != nil {
+		candidates = append([]schema.GroupVersionResource{*gvr}, candidates...)
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		gvk, err := mapper.KindFor(candidate)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get rest mapping")
+		}
+		return mapping, nil
+	}
+
+	return nil, errors.Wrap(lastErr, "failed to find kind")
+}
+
+func (h *customResourceEventHandler) ObjectCreated(obj interface{}) {
+	r := h.cast(obj)
+	informer, ok := h.getInformer(r)
+	if !ok {
+		return
+	}
+	h.resourceStateCh <- h.makeResourceState(informer, CalculateCustomResourceState(r))
+}
+
+func (h *customResourceEventHandler) ObjectUpdated(obj interface{}) {
+	r := h.cast(obj)
+	informer, ok := h.getInformer(r)
+	if !ok {
+		return
+	}
+	h.resourceStateCh <- h.makeResourceState(informer, CalculateCustomResourceState(r))
+}
+
+func (h *customResourceEventHandler) ObjectDeleted(obj interface{}) {
+	r := h.cast(obj)
+	informer, ok := h.getInformer(r)
+	if !ok {
+		return
+	}
+	h.resourceStateCh <- h.makeResourceState(informer, types.StateMissing)
+}
+
+func (h *customResourceEventHandler) cast(obj interface{}) *unstructured.Unstructured {
+	r, _ := obj.(*unstructured.Unstructured)
+	return r
+}
+
+func (h *customResourceEventHandler) getInformer(r *unstructured.Unstructured) (types.StatusInformer, bool) {
+	if r != nil {
+		for _, informer := range h.informers {
+			// informers of cluster scoped resources have the target namespace
+			if h.isNamespaced && r.GetNamespace() != informer.Namespace {
+				continue
+			}
+			if r.GetName() == informer.Name {
+				return informer, true
+			}
+		}
+	}
+	return types.StatusInformer{}, false
+}
+
+func (h *customResourceEventHandler) makeResourceState(informer types.StatusInformer, state types.State) types.ResourceState {
+	return types.ResourceState{
+		Kind:      h.kind,
+		Name:      informer.Name,
+		Namespace: informer.Namespace,
+		State:     state,
+	}
+}
+
+// CalculateCustomResourceState returns the state of a resource from the JSONPath expression in its annotations if it has one,
+// and from its Ready or Available status condition otherwise.
+func CalculateCustomResourceState(r *unstructured.Unstructured) types.State {
+	if r == nil {
+		return types.StateMissing
+	}
+
+	if path := r.GetAnnotations()[StatusJSONPathAnnotation]; path != "" {
+		state, err := calculateCustomResourceStateFromJSONPath(r, path, r.GetAnnotations()[StatusMappingAnnotation])
+		if err != nil {
+			log.Printf("failed to get state of %s %s from jsonpath: %v", r.GetKind(), r.GetName(), err)
+			return types.StateUnavailable
+		}
+		return state
+	}
+
+	return calculateCustomResourceStateFromConditions(r)
+}
+
+func calculateCustomResourceStateFromJSONPath(r *unstructured.Unstructured, path string, mapping string) (types.State, error) {
+	parser := jsonpath.New("status").AllowMissingKeys(true)
+	if err := parser.Parse(path); err != nil {
+		return "", errors.Wrapf(err, "failed to parse jsonpath %s", path)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := parser.Execute(buf, r.Object); err != nil {
+		return "", errors.Wrap(err, "failed to execute jsonpath")
+	}
+	value := strings.TrimSpace(buf.String())
+
+	// the controller has not reported a status yet
+	if value == "" {
+		return types.StateUpdating, nil
+	}
+
+	if mapping != "" {
+		states, err := parseStatusMapping(mapping)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to parse status mapping")
+		}
+		if state, ok := states[value]; ok {
+			return state, nil
+		}
+		return types.StateUnavailable, nil
+	}
+
+	switch strings.ToLower(value) {
+	case "true":
+		return types.StateReady, nil
+	case "false":
+		return types.StateUnavailable, nil
+	}
+	if state, ok := parseState(value); ok {
+		return state, nil
+	}
+	return types.StateUnavailable, nil
+}
+
+func parseStatusMapping(mapping string) (map[string]types.State, error) {
+	states := map[string]types.State{}
+	for _, pair := range strings.Split(mapping, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q is not in the format value=state", pair)
+		}
+		state, ok := parseState(parts[1])
+		if !ok {
+			return nil, fmt.Errorf("%q is not a state", parts[1])
+		}
+		states[strings.TrimSpace(parts[0])] = state
+	}
+	return states, nil
+}
+
+func parseState(s string) (types.State, bool) {
+	for _, state := range []types.State{types.StateReady, types.StateUpdating, types.StateDegraded, types.StateUnavailable} {
+		if strings.EqualFold(strings.TrimSpace(s), string(state)) {
+			return state, true
+		}
+	}
+	return "", false
+}
+
+func calculateCustomResourceStateFromConditions(r *unstructured.Unstructured) types.State {
+	generation := r.GetGeneration()
+	if observedGeneration, found, _ := unstructured.NestedInt64(r.Object, "status", "observedGeneration"); found && observedGeneration < generation {
+		return types.StateUpdating
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(r.Object, "status", "conditions")
+	conditionStatuses := map[string]string{}
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		status, _, _ := unstructured.NestedString(condition, "status")
+		// conditions that were set for a previous generation are out of date
+		if observedGeneration, found, _ := unstructured.NestedInt64(condition, "observedGeneration"); found && observedGeneration < generation {
+			status = "Unknown"
+		}
+		conditionStatuses[conditionType] = status
+	}
+
+	if conditionStatuses["Degraded"] == "True" {
+		return types.StateDegraded
+	}
+
+	readyStatus, ok := conditionStatuses["Ready"]
+	if !ok {
+		readyStatus, ok = conditionStatuses["Available"]
+	}
+	if !ok {
+		// the resource has no conditions to tell its state from, it's ready once it exists
+		return types.StateReady
+	}
+
+	switch readyStatus {
+	case "True":
+		return types.StateReady
+	case "False":
+		if conditionStatuses["Progressing"] == "True" || conditionStatuses["Reconciling"] == "True" {
+			return types.StateUpdating
+		}
+		return types.StateUnavailable
+	default:
+		return types.StateUpdating
+	}
+}
@@ -0,0 +1,229 @@
+package appstate
+
+import (
+	"testing"
+
+	"github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCalculateCustomResourceState(t *testing.T) {
+	resource := func(annotations map[string]interface{}, status map[string]interface{}) *unstructured.Unstructured {
+		metadata := map[string]interface{}{
+			"name":       "my-database",
+			"namespace":  "default",
+			"generation": int64(2),
+		}
+		if annotations != nil {
+			metadata["annotations"] = annotations
+		}
+		obj := map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Database",
+			"metadata":   metadata,
+		}
+		if status != nil {
+			obj["status"] = status
+		}
+		return &unstructured.Unstructured{Object: obj}
+	}
+
+	condition := func(conditionType string, status string) interface{} {
+		return map[string]interface{}{
+			"type":   conditionType,
+			"status": status,
+		}
+	}
+
+	tests := []struct {
+		name string
+		r    *unstructured.Unstructured
+		want types.State
+	}{
+		{
+			name: "missing",
+			r:    nil,
+			want: types.StateMissing,
+		},
+		{
+			name: "no status",
+			r:    resource(nil, nil),
+			want: types.StateReady,
+		},
+		{
+			name: "ready condition true",
+			r: resource(nil, map[string]interface{}{
+				"conditions": []interface{}{condition("Ready", "True")},
+			}),
+			want: types.StateReady,
+		},
+		{
+			name: "available condition false",
+			r: resource(nil, map[string]interface{}{
+				"conditions": []interface{}{condition("Available", "False")},
+			}),
+			want: types.StateUnavailable,
+		},
+		{
+			name: "ready condition false while progressing",
+			r: resource(nil, map[string]interface{}{
+				"conditions": []interface{}{condition("Ready", "False"), condition("Progressing", "True")},
+			}),
+			want: types.StateUpdating,
+		},
+		{
+			name: "ready condition unknown",
+			r: resource(nil, map[string]interface{}{
+				"conditions": []interface{}{condition("Ready", "Unknown")},
+			}),
+			want: types.StateUpdating,
+		},
+		{
+			name: "degraded condition",
+			r: resource(nil, map[string]interface{}{
+				"conditions": []interface{}{condition("Available", "True"), condition("Degraded", "True")},
+			}),
+			want: types.StateDegraded,
+		},
+		{
+			name: "status of a previous generation",
+			r: resource(nil, map[string]interface{}{
+				"observedGeneration": int64(1),
+				"conditions":         []interface{}{condition("Ready", "True")},
+			}),
+			want: types.StateUpdating,
+		},
+		{
+			name: "ready condition of a previous generation",
+			r: resource(nil, map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "True", "observedGeneration": int64(1)},
+				},
+			}),
+			want: types.StateUpdating,
+		},
+		{
+			name: "jsonpath with mapping",
+			r: resource(map[string]interface{}{
+				StatusJSONPathAnnotation: "{.status.phase}",
+				StatusMappingAnnotation:  "Running=ready, Pending=updating, Failed=unavailable",
+			}, map[string]interface{}{
+				"phase": "Pending",
+			}),
+			want: types.StateUpdating,
+		},
+		{
+			name: "jsonpath with unmapped value",
+			r: resource(map[string]interface{}{
+				StatusJSONPathAnnotation: "{.status.phase}",
+				StatusMappingAnnotation:  "Running=ready",
+			}, map[string]interface{}{
+				"phase": "Unknown",
+			}),
+			want: types.StateUnavailable,
+		},
+		{
+			name: "jsonpath without mapping",
+			r: resource(map[string]interface{}{
+				StatusJSONPathAnnotation: "{.status.health}",
+			}, map[string]interface{}{
+				"health": "Degraded",
+			}),
+			want: types.StateDegraded,
+		},
+		{
+			name: "jsonpath with boolean",
+			r: resource(map[string]interface{}{
+				StatusJSONPathAnnotation: "{.status.healthy}",
+			}, map[string]interface{}{
+				"healthy": true,
+			}),
+			want: types.StateReady,
+		},
+		{
+			name: "jsonpath field not set yet",
+			r: resource(map[string]interface{}{
+				StatusJSONPathAnnotation: "{.status.phase}",
+			}, nil),
+			want: types.StateUpdating,
+		},
+		{
+			name: "invalid mapping",
+			r: resource(map[string]interface{}{
+				StatusJSONPathAnnotation: "{.status.phase}",
+				StatusMappingAnnotation:  "Running=healthy",
+			}, map[string]interface{}{
+				"phase": "Running",
+			}),
+			want: types.StateUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CalculateCustomResourceState(tt.r))
+		})
+	}
+}
+
+func Test_resolveCustomResourceKind(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	fakeDiscovery := clientset.Discovery().(*discoveryfake.FakeDiscovery)
+	fakeDiscovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "databases", SingularName: "database", Kind: "Database", Namespaced: true},
+				{Name: "backends", SingularName: "backend", Kind: "Backend", Namespaced: false},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		kind           string
+		wantResource   schema.GroupVersionResource
+		wantNamespaced bool
+		wantErr        bool
+	}{
+		{
+			name:           "resource with version and group",
+			kind:           "databases.v1.example.com",
+			wantResource:   schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "databases"},
+			wantNamespaced: true,
+		},
+		{
+			name:           "singular resource with group",
+			kind:           "database.example.com",
+			wantResource:   schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "databases"},
+			wantNamespaced: true,
+		},
+		{
+			name:         "cluster scoped resource",
+			kind:         "backends",
+			wantResource: schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "backends"},
+		},
+		{
+			name:    "unknown resource",
+			kind:    "widgets.example.com",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapping, err := resolveCustomResourceKind(fakeDiscovery, tt.kind)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResource, mapping.Resource)
+			assert.Equal(t, tt.wantNamespaced, mapping.Scope.Name() == "namespace")
+		})
+	}
+}
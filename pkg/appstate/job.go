@@ -0,0 +1,142 @@
+package appstate
+
+import (
+	"context"
+	"time"
+
+	"github.com/replicatedhq/kots/pkg/appstate/types"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	JobResourceKind = "job"
+)
+
+type jobEventHandler struct {
+	informers       []types.StatusInformer
+	resourceStateCh chan<- types.ResourceState
+}
+
+func init() {
+	registerResourceKindNames(JobResourceKind, "jobs")
+}
+
+func runJobController(
+	ctx context.Context, clientset kubernetes.Interface, targetNamespace string,
+	informers []types.StatusInformer, resourceStateCh chan<- types.ResourceState,
+) {
+	listwatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return clientset.BatchV1().Jobs(targetNamespace).List(context.TODO(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return clientset.BatchV1().Jobs(targetNamespace).Watch(context.TODO(), options)
+		},
+	}
+	informer := cache.NewSharedInformer(
+		listwatch,
+		&batchv1.Job{},
+		time.Minute,
+	)
+
+	eventHandler := &jobEventHandler{
+		informers:       informers,
+		resourceStateCh: resourceStateCh,
+	}
+
+	runInformer(ctx, informer, eventHandler)
+	return
+}
+
+func (h *jobEventHandler) ObjectCreated(obj interface{}) {
+	r := h.cast(obj)
+	if _, ok := h.getInformer(r); !ok {
+		return
+	}
+	h.resourceStateCh <- makeJobResourceState(r, CalculateJobState(r))
+}
+
+func (h *jobEventHandler) ObjectUpdated(obj interface{}) {
+	r := h.cast(obj)
+	if _, ok := h.getInformer(r); !ok {
+		return
+	}
+	h.resourceStateCh <- makeJobResourceState(r, CalculateJobState(r))
+}
+
+func (h *jobEventHandler) ObjectDeleted(obj interface{}) {
+	r := h.cast(obj)
+	if _, ok := h.getInformer(r); !ok {
+		return
+	}
+	h.resourceStateCh <- makeJobResourceState(r, types.StateMissing)
+}
+
+func (h *jobEventHandler) cast(obj interface{}) *batchv1.Job {
+	r, _ := obj.(*batchv1.Job)
+	return r
+}
+
+func (h *jobEventHandler) getInformer(r *batchv1.Job) (types.StatusInformer, bool) {
+	if r != nil {
+		for _, informer := range h.informers {
+			if r.Namespace == informer.Namespace && r.Name == informer.Name {
+				return informer, true
+			}
+		}
+	}
+	return types.StatusInformer{}, false
+}
+
+func makeJobResourceState(r *batchv1.Job, state types.State) types.ResourceState {
+	return types.ResourceState{
+		Kind:      JobResourceKind,
+		Name:      r.Name,
+		Namespace: r.Namespace,
+		State:     state,
+	}
+}
+
+// CalculateJobState returns ready once the job completed, and unavailable if it failed.
+// A job that is running is updating, or degraded if some of its pods already failed.
+func CalculateJobState(r *batchv1.Job) types.State {
+	if r == nil {
+		return types.StateMissing
+	}
+
+	if jobHasCondition(r, batchv1.JobFailed) {
+		return types.StateUnavailable
+	}
+	if jobHasCondition(r, batchv1.JobComplete) {
+		return types.StateReady
+	}
+
+	var completions int32 = 1
+	if r.Spec.Completions != nil {
+		completions = *r.Spec.Completions
+	}
+	if r.Status.Succeeded >= completions {
+		return types.StateReady
+	}
+
+	if r.Status.Failed > 0 {
+		return types.StateDegraded
+	}
+
+	return types.StateUpdating
+}
+
+func jobHasCondition(r *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, c := range r.Status.Conditions {
+		if c.Type == conditionType && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
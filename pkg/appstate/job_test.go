@@ -0,0 +1,156 @@
+package appstate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestCalculateJobState(t *testing.T) {
+	tests := []struct {
+		name string
+		job  *batchv1.Job
+		want types.State
+	}{
+		{
+			name: "missing",
+			job:  nil,
+			want: types.StateMissing,
+		},
+		{
+			name: "running",
+			job: &batchv1.Job{
+				Status: batchv1.JobStatus{Active: 1},
+			},
+			want: types.StateUpdating,
+		},
+		{
+			name: "running with failed pods",
+			job: &batchv1.Job{
+				Status: batchv1.JobStatus{Active: 1, Failed: 1},
+			},
+			want: types.StateDegraded,
+		},
+		{
+			name: "complete",
+			job: &batchv1.Job{
+				Status: batchv1.JobStatus{
+					Succeeded: 1,
+					Conditions: []batchv1.JobCondition{
+						{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+					},
+				},
+			},
+			want: types.StateReady,
+		},
+		{
+			name: "some completions succeeded",
+			job: &batchv1.Job{
+				Spec:   batchv1.JobSpec{Completions: pointer.Int32(3)},
+				Status: batchv1.JobStatus{Active: 1, Succeeded: 2},
+			},
+			want: types.StateUpdating,
+		},
+		{
+			name: "failed",
+			job: &batchv1.Job{
+				Status: batchv1.JobStatus{
+					Failed: 6,
+					Conditions: []batchv1.JobCondition{
+						{Type: batchv1.JobFailed, Status: corev1.ConditionTrue},
+					},
+				},
+			},
+			want: types.StateUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CalculateJobState(tt.job))
+		})
+	}
+}
+
+func Test_calculateCronJobStateFromJobs(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "cleanup", Namespace: "default"},
+	}
+
+	job := func(name string, age time.Duration, owner string, status batchv1.JobStatus) batchv1.Job {
+		return batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: CronJobOwnerKind, Name: owner},
+				},
+			},
+			Status: status,
+		}
+	}
+	running := batchv1.JobStatus{Active: 1}
+	complete := batchv1.JobStatus{
+		Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+	}
+	failed := batchv1.JobStatus{
+		Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}},
+	}
+
+	tests := []struct {
+		name string
+		jobs []batchv1.Job
+		want types.State
+	}{
+		{
+			name: "never scheduled",
+			jobs: []batchv1.Job{},
+			want: types.StateReady,
+		},
+		{
+			name: "first run in progress",
+			jobs: []batchv1.Job{
+				job("cleanup-3", time.Minute, "cleanup", running),
+			},
+			want: types.StateUpdating,
+		},
+		{
+			name: "last run succeeded",
+			jobs: []batchv1.Job{
+				job("cleanup-1", 2*time.Hour, "cleanup", failed),
+				job("cleanup-3", time.Minute, "cleanup", running),
+				job("cleanup-2", time.Hour, "cleanup", complete),
+			},
+			want: types.StateReady,
+		},
+		{
+			name: "last run failed",
+			jobs: []batchv1.Job{
+				job("cleanup-1", 2*time.Hour, "cleanup", complete),
+				job("cleanup-2", time.Hour, "cleanup", failed),
+			},
+			want: types.StateDegraded,
+		},
+		{
+			name: "jobs of other cron jobs are ignored",
+			jobs: []batchv1.Job{
+				job("cleanup-1", 2*time.Hour, "cleanup", complete),
+				job("backup-1", time.Hour, "backup", failed),
+			},
+			want: types.StateReady,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, calculateCronJobStateFromJobs(cronJob, tt.jobs))
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package appstate
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/replicatedhq/kots/pkg/appstate/types"
+)
 
 var (
 	resourceKindNames [][]string
@@ -20,3 +24,14 @@ func getResourceKindCommonName(a string) string {
 	}
 	return a
 }
+
+// NormalizeStatusInformers converts resource kinds to their common name (e.g. "deploy" to "deployment")
+// and defaults empty namespaces to the target namespace, the same way the monitor does.
+func NormalizeStatusInformers(informers []types.StatusInformer, targetNamespace string) []types.StatusInformer {
+	return normalizeStatusInformers(informers, targetNamespace)
+}
+
+// GetResourceKindCommonName returns the common name of a resource kind, e.g. "deployment" for "Deployment" or "deploy".
+func GetResourceKindCommonName(kind string) string {
+	return getResourceKindCommonName(kind)
+}
@@ -0,0 +1,177 @@
+package types
+
+import (
+	"time"
+)
+
+// StatusTransition is a change of the state of an app, or of one of its resources if the kind is set.
+// The cluster id is empty for the cluster kotsadm runs in.
+type StatusTransition struct {
+	ID            string    `json:"id"`
+	AppID         string    `json:"appId"`
+	ClusterID     string    `json:"clusterId,omitempty"`
+	Sequence      int64     `json:"sequence"`
+	Kind          string    `json:"kind,omitempty"`
+	Namespace     string    `json:"namespace,omitempty"`
+	Name          string    `json:"name,omitempty"`
+	PreviousState State     `json:"previousState,omitempty"`
+	State         State     `json:"state"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// IsResource returns true if the transition is of a resource rather than of the app
+func (t StatusTransition) IsResource() bool {
+	return t.Kind != ""
+}
+
+type ListStatusTransitionsOptions struct {
+	AppID     string
+	ClusterID string
+	// From and To limit the transitions to the ones in [From, To), zero values are not limited
+	From time.Time
+	To   time.Time
+	// IncludeResources includes the transitions of the resources of the app
+	IncludeResources bool
+}
+
+// GetStatusTransitions returns the transitions from the previous status of an app to the next one.
+// The app transitions when its state or its sequence changes, and a resource transitions when its state changes or when it's first seen.
+func GetStatusTransitions(previous AppStatus, next AppStatus) []StatusTransition {
+	transitions := []StatusTransition{}
+
+	previousState := GetState(previous.ResourceStates)
+	nextState := GetState(next.ResourceStates)
+	if previous.UpdatedAt.IsZero() || previousState != nextState || previous.Sequence != next.Sequence {
+		t := StatusTransition{
+			AppID:     next.AppID,
+			Sequence:  next.Sequence,
+			State:     nextState,
+			CreatedAt: next.UpdatedAt,
+		}
+		if !previous.UpdatedAt.IsZero() {
+			t.PreviousState = previousState
+		}
+		transitions = append(transitions, t)
+	}
+
+	previousResourceStates := map[string]State{}
+	for _, r := range previous.ResourceStates {
+		previousResourceStates[resourceKey(r)] = r.State
+	}
+	for _, r := range next.ResourceStates {
+		previousState, ok := previousResourceStates[resourceKey(r)]
+		if ok && previousState == r.State {
+			continue
+		}
+		transitions = append(transitions, StatusTransition{
+			AppID:         next.AppID,
+			Sequence:      next.Sequence,
+			Kind:          r.Kind,
+			Namespace:     r.Namespace,
+			Name:          r.Name,
+			PreviousState: previousState,
+			State:         r.State,
+			CreatedAt:     next.UpdatedAt,
+		})
+	}
+
+	return transitions
+}
+
+func resourceKey(r ResourceState) string {
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+type UptimeSummary struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Availability is the percentage of the observed time the app was ready, it's nil if the state of the app is not known for any of the time
+	Availability *float64 `json:"availability"`
+	// ObservedSeconds is the time the state of the app is known for, before the first transition it's not
+	ObservedSeconds float64 `json:"observedSeconds"`
+	// StateSeconds is the time spent in each state
+	StateSeconds map[State]float64 `json:"stateSeconds"`
+	// Versions breaks the summary down by the sequence that was deployed, in the order the sequences were first deployed
+	Versions []VersionUptime `json:"versions"`
+}
+
+type VersionUptime struct {
+	Sequence        int64             `json:"sequence"`
+	Availability    *float64          `json:"availability"`
+	ObservedSeconds float64           `json:"observedSeconds"`
+	StateSeconds    map[State]float64 `json:"stateSeconds"`
+	// Degradations is the number of times the app went from ready to another state while the sequence was deployed
+	Degradations int `json:"degradations"`
+}
+
+// SummarizeUptime returns the time an app spent in each state between from and to, overall and per sequence.
+// The initial transition is the last app transition before from, if any, and the transitions must be the app transitions in [from, to) sorted by time.
+func SummarizeUptime(initial *StatusTransition, transitions []StatusTransition, from time.Time, to time.Time) UptimeSummary {
+	summary := UptimeSummary{
+		From:         from,
+		To:           to,
+		StateSeconds: map[State]float64{},
+		Versions:     []VersionUptime{},
+	}
+
+	versionIndexes := map[int64]int{}
+	version := func(sequence int64) *VersionUptime {
+		i, ok := versionIndexes[sequence]
+		if !ok {
+			i = len(summary.Versions)
+			versionIndexes[sequence] = i
+			summary.Versions = append(summary.Versions, VersionUptime{
+				Sequence:     sequence,
+				StateSeconds: map[State]float64{},
+			})
+		}
+		return &summary.Versions[i]
+	}
+	addInterval := func(t StatusTransition, start time.Time, end time.Time) {
+		if !end.After(start) {
+			return
+		}
+		seconds := end.Sub(start).Seconds()
+		summary.StateSeconds[t.State] += seconds
+		summary.ObservedSeconds += seconds
+		v := version(t.Sequence)
+		v.StateSeconds[t.State] += seconds
+		v.ObservedSeconds += seconds
+	}
+
+	current := initial
+	start := from
+	for i := range transitions {
+		t := transitions[i]
+		if t.IsResource() || t.CreatedAt.Before(from) || !t.CreatedAt.Before(to) {
+			continue
+		}
+		if current != nil {
+			addInterval(*current, start, t.CreatedAt)
+			if current.State == StateReady && t.State != StateReady {
+				version(t.Sequence).Degradations++
+			}
+		}
+		current = &t
+		start = t.CreatedAt
+	}
+	if current != nil {
+		addInterval(*current, start, to)
+	}
+
+	summary.Availability = availability(summary.StateSeconds[StateReady], summary.ObservedSeconds)
+	for i := range summary.Versions {
+		v := &summary.Versions[i]
+		v.Availability = availability(v.StateSeconds[StateReady], v.ObservedSeconds)
+	}
+
+	return summary
+}
+
+func availability(readySeconds float64, observedSeconds float64) *float64 {
+	if observedSeconds == 0 {
+		return nil
+	}
+	a := readySeconds / observedSeconds * 100
+	return &a
+}
@@ -0,0 +1,169 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStatusTransitions(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Minute)
+
+	web := func(state State) ResourceState {
+		return ResourceState{Kind: "deployment", Namespace: "default", Name: "web", State: state}
+	}
+	db := func(state State) ResourceState {
+		return ResourceState{Kind: "statefulset", Namespace: "default", Name: "db", State: state}
+	}
+
+	tests := []struct {
+		name     string
+		previous AppStatus
+		next     AppStatus
+		want     []StatusTransition
+	}{
+		{
+			name:     "first status",
+			previous: AppStatus{AppID: "app", ResourceStates: ResourceStates{}},
+			next:     AppStatus{AppID: "app", ResourceStates: ResourceStates{web(StateUpdating)}, UpdatedAt: now, Sequence: 1},
+			want: []StatusTransition{
+				{AppID: "app", Sequence: 1, State: StateUpdating, CreatedAt: now},
+				{AppID: "app", Sequence: 1, Kind: "deployment", Namespace: "default", Name: "web", State: StateUpdating, CreatedAt: now},
+			},
+		},
+		{
+			name:     "no change",
+			previous: AppStatus{AppID: "app", ResourceStates: ResourceStates{web(StateReady), db(StateReady)}, UpdatedAt: earlier, Sequence: 1},
+			next:     AppStatus{AppID: "app", ResourceStates: ResourceStates{db(StateReady), web(StateReady)}, UpdatedAt: now, Sequence: 1},
+			want:     []StatusTransition{},
+		},
+		{
+			name:     "resource change that doesn't change the app state",
+			previous: AppStatus{AppID: "app", ResourceStates: ResourceStates{web(StateDegraded), db(StateReady)}, UpdatedAt: earlier, Sequence: 1},
+			next:     AppStatus{AppID: "app", ResourceStates: ResourceStates{web(StateDegraded), db(StateUpdating)}, UpdatedAt: now, Sequence: 1},
+			want: []StatusTransition{
+				{AppID: "app", Sequence: 1, Kind: "statefulset", Namespace: "default", Name: "db", PreviousState: StateReady, State: StateUpdating, CreatedAt: now},
+			},
+		},
+		{
+			name:     "app becomes degraded",
+			previous: AppStatus{AppID: "app", ResourceStates: ResourceStates{web(StateReady), db(StateReady)}, UpdatedAt: earlier, Sequence: 1},
+			next:     AppStatus{AppID: "app", ResourceStates: ResourceStates{web(StateDegraded), db(StateReady)}, UpdatedAt: now, Sequence: 1},
+			want: []StatusTransition{
+				{AppID: "app", Sequence: 1, PreviousState: StateReady, State: StateDegraded, CreatedAt: now},
+				{AppID: "app", Sequence: 1, Kind: "deployment", Namespace: "default", Name: "web", PreviousState: StateReady, State: StateDegraded, CreatedAt: now},
+			},
+		},
+		{
+			name:     "new sequence in the same state",
+			previous: AppStatus{AppID: "app", ResourceStates: ResourceStates{web(StateReady)}, UpdatedAt: earlier, Sequence: 1},
+			next:     AppStatus{AppID: "app", ResourceStates: ResourceStates{web(StateReady)}, UpdatedAt: now, Sequence: 2},
+			want: []StatusTransition{
+				{AppID: "app", Sequence: 2, PreviousState: StateReady, State: StateReady, CreatedAt: now},
+			},
+		},
+		{
+			name:     "new resource",
+			previous: AppStatus{AppID: "app", ResourceStates: ResourceStates{web(StateReady)}, UpdatedAt: earlier, Sequence: 1},
+			next:     AppStatus{AppID: "app", ResourceStates: ResourceStates{web(StateReady), db(StateReady)}, UpdatedAt: now, Sequence: 2},
+			want: []StatusTransition{
+				{AppID: "app", Sequence: 2, PreviousState: StateReady, State: StateReady, CreatedAt: now},
+				{AppID: "app", Sequence: 2, Kind: "statefulset", Namespace: "default", Name: "db", State: StateReady, CreatedAt: now},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetStatusTransitions(tt.previous, tt.next)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSummarizeUptime(t *testing.T) {
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(10 * time.Hour)
+	at := func(hours int) time.Time {
+		return from.Add(time.Duration(hours) * time.Hour)
+	}
+	percent := func(f float64) *float64 {
+		return &f
+	}
+
+	tests := []struct {
+		name        string
+		initial     *StatusTransition
+		transitions []StatusTransition
+		want        UptimeSummary
+	}{
+		{
+			name: "no transitions",
+			want: UptimeSummary{
+				From:         from,
+				To:           to,
+				StateSeconds: map[State]float64{},
+				Versions:     []VersionUptime{},
+			},
+		},
+		{
+			name:    "ready the whole time",
+			initial: &StatusTransition{Sequence: 1, State: StateReady, CreatedAt: at(-5)},
+			want: UptimeSummary{
+				From:            from,
+				To:              to,
+				Availability:    percent(100),
+				ObservedSeconds: 36000,
+				StateSeconds:    map[State]float64{StateReady: 36000},
+				Versions: []VersionUptime{
+					{Sequence: 1, Availability: percent(100), ObservedSeconds: 36000, StateSeconds: map[State]float64{StateReady: 36000}},
+				},
+			},
+		},
+		{
+			name: "unknown until the first transition",
+			transitions: []StatusTransition{
+				{Sequence: 1, State: StateUpdating, CreatedAt: at(2)},
+				{Sequence: 1, State: StateReady, CreatedAt: at(4)},
+				{Sequence: 1, Kind: "deployment", Name: "web", State: StateReady, CreatedAt: at(4)},
+			},
+			want: UptimeSummary{
+				From:            from,
+				To:              to,
+				Availability:    percent(75),
+				ObservedSeconds: 8 * 3600,
+				StateSeconds:    map[State]float64{StateUpdating: 2 * 3600, StateReady: 6 * 3600},
+				Versions: []VersionUptime{
+					{Sequence: 1, Availability: percent(75), ObservedSeconds: 8 * 3600, StateSeconds: map[State]float64{StateUpdating: 2 * 3600, StateReady: 6 * 3600}},
+				},
+			},
+		},
+		{
+			name:    "degradation after a deploy",
+			initial: &StatusTransition{Sequence: 1, State: StateReady, CreatedAt: at(-5)},
+			transitions: []StatusTransition{
+				{Sequence: 2, State: StateReady, CreatedAt: at(5)},
+				{Sequence: 2, State: StateDegraded, CreatedAt: at(6)},
+				{Sequence: 2, State: StateReady, CreatedAt: at(8)},
+			},
+			want: UptimeSummary{
+				From:            from,
+				To:              to,
+				Availability:    percent(80),
+				ObservedSeconds: 36000,
+				StateSeconds:    map[State]float64{StateReady: 8 * 3600, StateDegraded: 2 * 3600},
+				Versions: []VersionUptime{
+					{Sequence: 1, Availability: percent(100), ObservedSeconds: 5 * 3600, StateSeconds: map[State]float64{StateReady: 5 * 3600}},
+					{Sequence: 2, Availability: percent(60), ObservedSeconds: 5 * 3600, StateSeconds: map[State]float64{StateReady: 3 * 3600, StateDegraded: 2 * 3600}, Degradations: 1},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SummarizeUptime(tt.initial, tt.transitions, from, to)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -42,6 +42,8 @@ type AppStatus struct {
 	UpdatedAt      time.Time      `json:"updatedAt" hash:"ignore"`
 	State          State          `json:"state"`
 	Sequence       int64          `json:"sequence"`
+	// ReadyAt is when the app last became ready, it's nil while the app is not ready
+	ReadyAt *time.Time `json:"readyAt,omitempty" hash:"ignore"`
 }
 
 type ResourceStates []ResourceState
@@ -17,14 +17,17 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/util/jsonpath"
 )
 
 var (
 	WaitForResourceFns = map[string]func(clientset kubernetes.Interface, namespace, name string) error{
+		CronJobResourceKind:               WaitForCronJobToBeReady,
 		DaemonSetResourceKind:             WaitForDaemonSetToBeReady,
 		DeploymentResourceKind:            WaitForDeploymentToBeReady,
 		IngressResourceKind:               WaitForIngressToBeReady,
+		JobResourceKind:                   WaitForJobToBeReady,
 		PersistentVolumeClaimResourceKind: WaitForPersistentVolumeClaimToBeReady,
 		ServiceResourceKind:               WaitForServiceToBeReady,
 		StatefulSetResourceKind:           WaitForStatefulSetToBeReady,
@@ -33,8 +36,8 @@ var (
 	WaitForResourceInterval = time.Second * 2
 )
 
-func WaitForResourceToBeReady(namespace, name string, gvk *schema.GroupVersionKind) error {
-	clientset, err := k8sutil.GetClientset()
+func WaitForResourceToBeReady(config *rest.Config, namespace, name string, gvk *schema.GroupVersionKind) error {
+	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return errors.Wrap(err, "failed to get clientset")
 	}
@@ -48,7 +51,7 @@ func WaitForResourceToBeReady(namespace, name string, gvk *schema.GroupVersionKi
 		return fn(clientset, namespace, name)
 	}
 
-	dr, err := k8sutil.GetDynamicResourceInterface(gvk, namespace)
+	dr, err := k8sutil.GetDynamicResourceInterfaceForConfig(config, gvk, namespace)
 	if err != nil {
 		return errors.Wrap(err, "failed to get dynamic resource interface")
 	}
@@ -110,6 +113,46 @@ func WaitForIngressToBeReady(clientset kubernetes.Interface, namespace, name str
 	}
 }
 
+func WaitForJobToBeReady(clientset kubernetes.Interface, namespace, name string) error {
+	for {
+		r, err := clientset.BatchV1().Jobs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil && !kuberneteserrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to get existing job")
+		}
+
+		if err == nil {
+			state := CalculateJobState(r)
+			if state == types.StateReady {
+				return nil
+			}
+			// a failed job is not retried
+			if state == types.StateUnavailable {
+				return errors.Errorf("job %s failed", name)
+			}
+		}
+
+		time.Sleep(WaitForResourceInterval)
+	}
+}
+
+func WaitForCronJobToBeReady(clientset kubernetes.Interface, namespace, name string) error {
+	for {
+		r, err := clientset.BatchV1().CronJobs(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil && !kuberneteserrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to get existing cronjob")
+		}
+
+		if err == nil {
+			state := CalculateCronJobState(clientset, namespace, r)
+			if state == types.StateReady {
+				return nil
+			}
+		}
+
+		time.Sleep(WaitForResourceInterval)
+	}
+}
+
 func WaitForPersistentVolumeClaimToBeReady(clientset kubernetes.Interface, namespace, name string) error {
 	for {
 		r, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), name, metav1.GetOptions{})
@@ -179,8 +222,8 @@ func WaitForGenericResourceToBeReady(dr dynamic.ResourceInterface, name string)
 	}
 }
 
-func WaitForProperty(namespace, name string, gvk *schema.GroupVersionKind, path, desiredValue string) error {
-	dr, err := k8sutil.GetDynamicResourceInterface(gvk, namespace)
+func WaitForProperty(config *rest.Config, namespace, name string, gvk *schema.GroupVersionKind, path, desiredValue string) error {
+	dr, err := k8sutil.GetDynamicResourceInterfaceForConfig(config, gvk, namespace)
 	if err != nil {
 		return errors.Wrap(err, "failed to get dynamic resource interface")
 	}
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"net/http"
+	"time"
+)
+
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeDenied  Outcome = "denied"
+	OutcomeFailure Outcome = "failure"
+)
+
+// Caller is how the caller of an audited request authenticated
+type Caller string
+
+const (
+	CallerSession Caller = "session"
+	CallerToken   Caller = "token"
+)
+
+// AuditLogEntry records a single mutating request made to the admin console api.
+type AuditLogEntry struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Caller    Caller    `json:"caller"`
+	SessionID string    `json:"sessionId"`
+	Roles     []string  `json:"roles"`
+	AppSlug   string    `json:"appSlug,omitempty"`
+	Sequence  *int64    `json:"sequence,omitempty"`
+	Action    string    `json:"action"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	Outcome   Outcome   `json:"outcome"`
+}
+
+type ListAuditLogOptions struct {
+	AppSlug     string
+	Action      string
+	CurrentPage int
+	PageSize    int
+}
+
+type AuditLog struct {
+	Entries    []AuditLogEntry `json:"entries"`
+	TotalCount int             `json:"totalCount"`
+}
+
+func OutcomeFromStatus(status int) Outcome {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return OutcomeDenied
+	case status >= http.StatusBadRequest:
+		return OutcomeFailure
+	default:
+		return OutcomeSuccess
+	}
+}
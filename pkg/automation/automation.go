@@ -121,6 +121,13 @@ func AutomateInstall(opts AutomateInstallOptions) error {
 		cleanup(&licenseSecret)
 	}
 
+	// apps without a license are only installed online, never from an airgap bundle
+	if opts.AppSlug == "" {
+		if err := automateUpstreamInstalls(clientset); err != nil {
+			return errors.Wrap(err, "failed to automate upstream installs")
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,150 @@
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	kotsadmtypes "github.com/replicatedhq/kots/pkg/kotsadm/types"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/online"
+	onlinetypes "github.com/replicatedhq/kots/pkg/online/types"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/tasks"
+	"github.com/replicatedhq/kots/pkg/util"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// automateUpstreamInstalls installs the apps that were left by the kots install command without a license,
+// such as helm charts and git repositories
+func automateUpstreamInstalls(clientset kubernetes.Interface) error {
+	selectorLabels := map[string]string{
+		"kots.io/automation": "upstream",
+	}
+	upstreamConfigMaps, err := clientset.CoreV1().ConfigMaps(util.PodNamespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(selectorLabels).String(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list upstream config maps")
+	}
+
+	for _, upstreamConfigMap := range upstreamConfigMaps.Items {
+		if err := installUpstreamConfigMap(upstreamConfigMap); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to install upstream %s", upstreamConfigMap.Name))
+		}
+
+		if err := kotsutil.RemoveAppVersionLabelFromInstallationParams(kotsadmtypes.KotsadmConfigMap); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to delete app version label from config"))
+		}
+
+		err = clientset.CoreV1().ConfigMaps(upstreamConfigMap.Namespace).Delete(context.TODO(), upstreamConfigMap.Name, metav1.DeleteOptions{})
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to delete upstream config map %s", upstreamConfigMap.Name))
+			// this is going to create a new app on each start now!
+		}
+	}
+
+	return nil
+}
+
+func installUpstreamConfigMap(upstreamConfigMap corev1.ConfigMap) (finalError error) {
+	upstreamURI := upstreamConfigMap.Data["upstreamUri"]
+	if !util.IsUnlicensedUpstreamURI(upstreamURI) {
+		return fmt.Errorf("upstream config map %q does not contain an unlicensed upstream", upstreamConfigMap.Name)
+	}
+	if util.HasUpstreamCredentials(upstreamURI) {
+		return fmt.Errorf("upstream config map %q contains credentials, they must be referenced from a secret", upstreamConfigMap.Name)
+	}
+	appSlug := upstreamConfigMap.Labels["kots.io/app"]
+	if appSlug == "" {
+		appSlug = util.UnlicensedUpstreamName(upstreamURI)
+	}
+
+	logger.Debug("automated upstream install found",
+		zap.String("appSlug", appSlug),
+		zap.String("upstreamURI", upstreamURI))
+
+	taskMessage, err := json.Marshal(AutomateInstallTaskMessage{Message: "Installing app..."})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal task message")
+	}
+	taskID := fmt.Sprintf("automated-install-slug-%s", appSlug)
+	if err := tasks.SetTaskStatus(taskID, string(taskMessage), AutomatedInstallRunning); err != nil {
+		logger.Error(errors.Wrap(err, "failed to set task status"))
+	}
+
+	finishedCh := make(chan struct{})
+	defer close(finishedCh)
+	go func() {
+		for {
+			select {
+			case <-time.After(time.Second * 2):
+				if err := tasks.UpdateTaskStatusTimestamp(taskID); err != nil {
+					logger.Error(errors.Wrapf(err, "failed to update task %s", taskID))
+				}
+			case <-finishedCh:
+				return
+			}
+		}
+	}()
+
+	var appID string
+	defer func() {
+		message := AutomateInstallTaskMessage{}
+		status := AutomatedInstallSuccess
+		if finalError == nil {
+			versionStatus, err := store.GetStore().GetDownstreamVersionStatus(appID, 0)
+			if err != nil {
+				logger.Error(errors.Wrap(err, "failed to get downstream version status"))
+			}
+			message.Message = "Install complete"
+			message.VersionStatus = versionStatus
+		} else {
+			message.Error = finalError.Error()
+			status = AutomatedInstallFailed
+		}
+		taskMessage, err := json.Marshal(message)
+		if err != nil {
+			logger.Error(errors.Wrap(err, "failed to marshal task message"))
+		}
+		if err := tasks.SetTaskStatus(taskID, string(taskMessage), status); err != nil {
+			logger.Error(errors.Wrap(err, "failed to set install task status"))
+		}
+	}()
+
+	instParams, err := kotsutil.GetInstallationParams(kotsadmtypes.KotsadmConfigMap)
+	if err != nil {
+		return errors.Wrap(err, "failed to get existing kotsadm config map")
+	}
+
+	a, err := store.GetStore().CreateApp(appSlug, "", upstreamURI, "", false, instParams.SkipImagePush, instParams.RegistryIsReadOnly)
+	if err != nil {
+		return errors.Wrap(err, "failed to create app record")
+	}
+	appID = a.ID
+
+	createAppOpts := online.CreateOnlineAppOpts{
+		PendingApp: &onlinetypes.PendingApp{
+			ID:           a.ID,
+			Slug:         a.Slug,
+			Name:         a.Name,
+			VersionLabel: instParams.AppVersionLabel,
+		},
+		UpstreamURI:            upstreamURI,
+		IsAutomated:            true,
+		SkipPreflights:         instParams.SkipPreflights,
+		SkipCompatibilityCheck: instParams.SkipCompatibilityCheck,
+	}
+	if _, err := online.CreateAppFromOnline(createAppOpts); err != nil {
+		return errors.Wrap(err, "failed to create online app")
+	}
+
+	return nil
+}
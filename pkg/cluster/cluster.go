@@ -0,0 +1,348 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	downstreamtypes "github.com/replicatedhq/kots/pkg/api/downstream/types"
+	"github.com/replicatedhq/kots/pkg/cluster/tunnel"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	kotsadmtypes "github.com/replicatedhq/kots/pkg/kotsadm/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/rand"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// ConnectionTypeLocal is the cluster kotsadm runs in
+	ConnectionTypeLocal = "local"
+	// ConnectionTypeKubeconfig is a remote cluster that kotsadm connects to with a kubeconfig
+	ConnectionTypeKubeconfig = "kubeconfig"
+	// ConnectionTypeAgent is a remote cluster that runs an agent which connects back to kotsadm
+	ConnectionTypeAgent = "agent"
+
+	kubeconfigSecretKey = "kubeconfig"
+	defaultNamespace    = "default"
+)
+
+type RegisterOptions struct {
+	Title           string
+	Kubeconfig      string
+	TargetNamespace string
+	Agent           bool
+}
+
+type RegisterResult struct {
+	Cluster *downstreamtypes.Downstream
+	// DeployToken authenticates the agent of the cluster, it's only set for agent clusters
+	DeployToken string
+}
+
+// IsRemote returns true if the cluster is not the one kotsadm runs in
+func IsRemote(d *downstreamtypes.Downstream) bool {
+	return d != nil && d.ConnectionType != "" && d.ConnectionType != ConnectionTypeLocal
+}
+
+// Register adds a remote cluster and makes all installed apps available to deploy to it
+func Register(opts RegisterOptions) (_ *RegisterResult, finalErr error) {
+	if opts.Title == "" {
+		return nil, errors.New("title is required")
+	}
+
+	connectionType := ConnectionTypeAgent
+	if !opts.Agent {
+		if opts.Kubeconfig == "" {
+			return nil, errors.New("either a kubeconfig or an agent is required")
+		}
+		connectionType = ConnectionTypeKubeconfig
+
+		if err := validateKubeconfig([]byte(opts.Kubeconfig)); err != nil {
+			return nil, errors.Wrap(err, "failed to validate kubeconfig")
+		}
+	}
+
+	targetNamespace := opts.TargetNamespace
+	if targetNamespace == "" {
+		targetNamespace = defaultNamespace
+		if connectionType == ConnectionTypeKubeconfig {
+			if clientConfig, err := clientcmd.NewClientConfigFromBytes([]byte(opts.Kubeconfig)); err == nil {
+				if ns, _, err := clientConfig.Namespace(); err == nil && ns != "" {
+					targetNamespace = ns
+				}
+			}
+		}
+	}
+
+	deployToken := rand.StringWithCharset(32, rand.LOWER_CASE)
+
+	clusterID, err := store.GetStore().CreateNewCluster("", true, opts.Title, deployToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cluster")
+	}
+
+	defer func() {
+		if finalErr == nil {
+			return
+		}
+		// don't leave behind a cluster that can't be connected to
+		if err := deleteKubeconfig(clusterID); err != nil {
+			logger.Errorf("failed to delete kubeconfig of cluster %s: %v", clusterID, err)
+		}
+		if err := store.GetStore().RemoveCluster(clusterID); err != nil {
+			logger.Errorf("failed to remove cluster %s: %v", clusterID, err)
+		}
+	}()
+
+	if err := store.GetStore().SetClusterConnection(clusterID, connectionType, targetNamespace); err != nil {
+		return nil, errors.Wrap(err, "failed to set cluster connection")
+	}
+
+	if connectionType == ConnectionTypeKubeconfig {
+		if err := saveKubeconfig(clusterID, []byte(opts.Kubeconfig)); err != nil {
+			return nil, errors.Wrap(err, "failed to save kubeconfig")
+		}
+	}
+
+	if err := store.GetStore().AddAppsToDownstream(clusterID); err != nil {
+		return nil, errors.Wrap(err, "failed to add apps to cluster")
+	}
+
+	cluster, err := store.GetStore().GetCluster(clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster")
+	}
+
+	result := &RegisterResult{
+		Cluster: cluster,
+	}
+	if connectionType == ConnectionTypeAgent {
+		result.DeployToken = deployToken
+	}
+
+	return result, nil
+}
+
+// Remove removes a remote cluster from kotsadm. Apps that were deployed to the cluster are left running.
+func Remove(clusterID string) error {
+	cluster, err := store.GetStore().GetCluster(clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster")
+	}
+	if cluster == nil {
+		return nil
+	}
+	if !IsRemote(cluster) {
+		return errors.New("the cluster kotsadm runs in can't be removed")
+	}
+
+	if err := store.GetStore().RemoveCluster(clusterID); err != nil {
+		return errors.Wrap(err, "failed to remove cluster")
+	}
+
+	if err := deleteKubeconfig(clusterID); err != nil {
+		return errors.Wrap(err, "failed to delete kubeconfig")
+	}
+
+	return nil
+}
+
+// GetRESTConfig returns the config to connect to a cluster with
+func GetRESTConfig(clusterID string) (*rest.Config, error) {
+	cluster, err := store.GetStore().GetCluster(clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster")
+	}
+	if cluster == nil {
+		return nil, errors.Errorf("cluster %s not found", clusterID)
+	}
+
+	return GetRESTConfigForCluster(cluster)
+}
+
+// GetRESTConfigForCluster is the same as GetRESTConfig for a cluster that was already loaded
+func GetRESTConfigForCluster(cluster *downstreamtypes.Downstream) (*rest.Config, error) {
+	var config *rest.Config
+	var err error
+
+	switch cluster.ConnectionType {
+	case "", ConnectionTypeLocal:
+		return k8sutil.GetClusterConfig()
+	case ConnectionTypeKubeconfig:
+		config, err = getKubeconfigRESTConfig(cluster.ClusterID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get kubeconfig rest config")
+		}
+	case ConnectionTypeAgent:
+		config, err = tunnel.GetServer().RESTConfig(cluster.ClusterID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get agent rest config")
+		}
+	default:
+		return nil, errors.Errorf("unknown connection type %q", cluster.ConnectionType)
+	}
+
+	config.QPS = k8sutil.DEFAULT_K8S_CLIENT_QPS
+	config.Burst = k8sutil.DEFAULT_K8S_CLIENT_BURST
+
+	return config, nil
+}
+
+// IsReachable returns true if kotsadm has what it needs to connect to the cluster.
+// It does not check that the api server responds.
+func IsReachable(cluster *downstreamtypes.Downstream) bool {
+	switch cluster.ConnectionType {
+	case ConnectionTypeAgent:
+		return tunnel.GetServer().IsConnected(cluster.ClusterID)
+	default:
+		return true
+	}
+}
+
+func validateKubeconfig(kubeconfig []byte) error {
+	config, err := restConfigFromKubeconfig(kubeconfig)
+	if err != nil {
+		return err
+	}
+	config.Timeout = 10 * time.Second
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	if _, err := clientset.Discovery().ServerVersion(); err != nil {
+		return errors.Wrap(err, "failed to connect to cluster")
+	}
+
+	return nil
+}
+
+func kubeconfigSecretName(clusterID string) string {
+	return fmt.Sprintf("kotsadm-cluster-%s", clusterID)
+}
+
+func saveKubeconfig(clusterID string, kubeconfig []byte) error {
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get clientset")
+	}
+
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kubeconfigSecretName(clusterID),
+			Namespace: util.PodNamespace,
+			Labels:    kotsadmtypes.GetKotsadmLabels(),
+		},
+		Data: map[string][]byte{
+			kubeconfigSecretKey: kubeconfig,
+		},
+	}
+
+	_, err = clientset.CoreV1().Secrets(util.PodNamespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to create secret")
+	}
+
+	return nil
+}
+
+func getKubeconfigRESTConfig(clusterID string) (*rest.Config, error) {
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get clientset")
+	}
+
+	secret, err := clientset.CoreV1().Secrets(util.PodNamespace).Get(context.TODO(), kubeconfigSecretName(clusterID), metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get secret")
+	}
+
+	config, err := restConfigFromKubeconfig(secret.Data[kubeconfigSecretKey])
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// restConfigFromKubeconfig parses a user supplied kubeconfig. Kubeconfigs that would run commands or read
+// files in the kotsadm pod when connecting are rejected, only inline credentials and certificates are allowed.
+func restConfigFromKubeconfig(kubeconfig []byte) (*rest.Config, error) {
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse kubeconfig")
+	}
+
+	if err := checkKubeconfigIsSelfContained(config); err != nil {
+		return nil, errors.Wrap(err, "invalid kubeconfig")
+	}
+
+	restConfig, err := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create rest config")
+	}
+
+	return restConfig, nil
+}
+
+func checkKubeconfigIsSelfContained(config *clientcmdapi.Config) error {
+	for name, cluster := range config.Clusters {
+		if cluster == nil {
+			continue
+		}
+		if cluster.CertificateAuthority != "" {
+			return errors.Errorf("cluster %q: certificate-authority files are not supported, use certificate-authority-data", name)
+		}
+	}
+
+	for name, authInfo := range config.AuthInfos {
+		if authInfo == nil {
+			continue
+		}
+		if authInfo.Exec != nil {
+			return errors.Errorf("user %q: exec credential plugins are not supported", name)
+		}
+		if authInfo.AuthProvider != nil {
+			return errors.Errorf("user %q: auth providers are not supported", name)
+		}
+		if authInfo.TokenFile != "" {
+			return errors.Errorf("user %q: token files are not supported, use token", name)
+		}
+		if authInfo.ClientCertificate != "" {
+			return errors.Errorf("user %q: client-certificate files are not supported, use client-certificate-data", name)
+		}
+		if authInfo.ClientKey != "" {
+			return errors.Errorf("user %q: client-key files are not supported, use client-key-data", name)
+		}
+	}
+
+	return nil
+}
+
+func deleteKubeconfig(clusterID string) error {
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get clientset")
+	}
+
+	err = clientset.CoreV1().Secrets(util.PodNamespace).Delete(context.TODO(), kubeconfigSecretName(clusterID), metav1.DeleteOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete secret")
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_restConfigFromKubeconfig(t *testing.T) {
+	const header = `apiVersion: v1
+kind: Config
+current-context: remote
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: remote
+`
+	tests := []struct {
+		name       string
+		kubeconfig string
+		wantErr    string
+	}{
+		{
+			name: "inline credentials",
+			kubeconfig: header + `clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com:6443
+    certificate-authority-data: ` + testCertData + `
+users:
+- name: remote
+  user:
+    token: abcdef
+`,
+		},
+		{
+			name: "exec plugin",
+			kubeconfig: header + `clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com:6443
+users:
+- name: remote
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: /bin/sh
+      args: ["-c", "id"]
+`,
+			wantErr: "exec credential plugins are not supported",
+		},
+		{
+			name: "auth provider",
+			kubeconfig: header + `clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com:6443
+users:
+- name: remote
+  user:
+    auth-provider:
+      name: gcp
+      config:
+        cmd-path: /bin/sh
+`,
+			wantErr: "auth providers are not supported",
+		},
+		{
+			name: "token file",
+			kubeconfig: header + `clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com:6443
+users:
+- name: remote
+  user:
+    tokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token
+`,
+			wantErr: "token files are not supported",
+		},
+		{
+			name: "client certificate file",
+			kubeconfig: header + `clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com:6443
+users:
+- name: remote
+  user:
+    client-certificate: /etc/kubernetes/pki/client.crt
+    client-key-data: ` + testCertData + `
+`,
+			wantErr: "client-certificate files are not supported",
+		},
+		{
+			name: "client key file",
+			kubeconfig: header + `clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com:6443
+users:
+- name: remote
+  user:
+    client-certificate-data: ` + testCertData + `
+    client-key: /etc/kubernetes/pki/client.key
+`,
+			wantErr: "client-key files are not supported",
+		},
+		{
+			name: "certificate authority file on an unused cluster",
+			kubeconfig: header + `clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com:6443
+- name: other
+  cluster:
+    server: https://other.example.com:6443
+    certificate-authority: /var/run/secrets/kubernetes.io/serviceaccount/ca.crt
+users:
+- name: remote
+  user:
+    token: abcdef
+`,
+			wantErr: "certificate-authority files are not supported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := restConfigFromKubeconfig([]byte(tt.kubeconfig))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "https://remote.example.com:6443", config.Host)
+			assert.Equal(t, "abcdef", config.BearerToken)
+		})
+	}
+}
+
+// base64 of "test", the contents are not parsed when the kubeconfig is loaded
+const testCertData = "dGVzdA=="
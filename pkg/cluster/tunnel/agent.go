@@ -0,0 +1,187 @@
+package tunnel
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/logger"
+)
+
+const (
+	ConnectPath = "/api/v1/agent/connect"
+	TunnelPath  = "/api/v1/agent/tunnel"
+
+	reconnectInterval = 5 * time.Second
+	helloInterval     = 10 * time.Minute
+)
+
+type AgentOptions struct {
+	// KotsadmURL is the address the agent connects to kotsadm at
+	KotsadmURL string
+	// DeployToken authenticates the agent as the cluster it was registered as
+	DeployToken string
+	// APIServerAddress is the host:port tunnels are forwarded to
+	APIServerAddress string
+	// Credentials returns the token and the ca bundle that kotsadm uses for the api server.
+	// It's called again periodically so that rotated tokens are picked up.
+	Credentials func() (token string, caData []byte, err error)
+}
+
+// RunAgent connects the agent to kotsadm and serves tunnels to the api server until the context is cancelled.
+// It reconnects whenever the connection is lost.
+func RunAgent(ctx context.Context, opts AgentOptions) error {
+	for {
+		err := connectAgent(ctx, opts)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			logger.Errorf("agent connection to kotsadm failed: %v", err)
+		} else {
+			logger.Infof("agent connection to kotsadm closed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectInterval):
+		}
+	}
+}
+
+func connectAgent(ctx context.Context, opts AgentOptions) error {
+	connectURL, err := agentURL(opts.KotsadmURL, ConnectPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to get connect url")
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, connectURL, agentHeader(opts.DeployToken))
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to kotsadm")
+	}
+	defer conn.Close()
+
+	logger.Infof("agent connected to kotsadm at %s", opts.KotsadmURL)
+
+	var writeMtx sync.Mutex
+	sendHello := func() error {
+		token, caData, err := opts.Credentials()
+		if err != nil {
+			return errors.Wrap(err, "failed to get credentials")
+		}
+		writeMtx.Lock()
+		defer writeMtx.Unlock()
+		return conn.WriteJSON(message{Type: messageTypeHello, Token: token, CAData: caData})
+	}
+
+	if err := sendHello(); err != nil {
+		return errors.Wrap(err, "failed to send hello")
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-time.After(helloInterval):
+				if err := sendHello(); err != nil {
+					logger.Errorf("failed to refresh agent credentials: %v", err)
+				}
+			}
+		}
+	}()
+
+	for {
+		var msg message
+		if err := conn.ReadJSON(&msg); err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+			return errors.Wrap(err, "failed to read message")
+		}
+
+		switch msg.Type {
+		case messageTypeDial:
+			go func(id string) {
+				if err := serveTunnel(ctx, opts, id); err != nil {
+					logger.Errorf("failed to serve tunnel %s: %v", id, err)
+				}
+			}(msg.ID)
+		default:
+			logger.Debugf("ignoring message of type %q from kotsadm", msg.Type)
+		}
+	}
+}
+
+// serveTunnel opens the data connection of a tunnel and forwards it to the api server
+func serveTunnel(ctx context.Context, opts AgentOptions, id string) error {
+	tunnelURL, err := agentURL(opts.KotsadmURL, TunnelPath+"/"+url.PathEscape(id))
+	if err != nil {
+		return errors.Wrap(err, "failed to get tunnel url")
+	}
+
+	upstream, err := (&net.Dialer{}).DialContext(ctx, "tcp", opts.APIServerAddress)
+	if err != nil {
+		return errors.Wrap(err, "failed to dial api server")
+	}
+	defer upstream.Close()
+
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, tunnelURL, agentHeader(opts.DeployToken))
+	if err != nil {
+		return errors.Wrap(err, "failed to open tunnel")
+	}
+	tunnel := newWSConn(ws)
+	defer tunnel.Close()
+
+	errCh := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(upstream, tunnel)
+		errCh <- err
+	}()
+	go func() {
+		_, err := io.Copy(tunnel, upstream)
+		errCh <- err
+	}()
+
+	// either side closing ends the tunnel
+	return <-errCh
+}
+
+// agentURL returns the websocket url for a path on kotsadm
+func agentURL(kotsadmURL string, path string) (string, error) {
+	u, err := url.Parse(strings.TrimSuffix(kotsadmURL, "/"))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse url")
+	}
+
+	switch u.Scheme {
+	case "http", "ws":
+		u.Scheme = "ws"
+	case "https", "wss":
+		u.Scheme = "wss"
+	default:
+		return "", errors.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	u.Path = u.Path + path
+
+	return u.String(), nil
+}
+
+func agentHeader(deployToken string) http.Header {
+	header := http.Header{}
+	header.Set("Authorization", deployToken)
+	return header
+}
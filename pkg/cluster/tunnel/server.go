@@ -0,0 +1,301 @@
+package tunnel
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/rand"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// APIServerHost is the host the api server of a cluster with an agent is reached at through the tunnel.
+	// It's one of the names in the api server certificate, so tls is verified end to end.
+	APIServerHost = "kubernetes.default.svc"
+
+	dialTimeout  = 30 * time.Second
+	pingInterval = 15 * time.Second
+)
+
+type messageType string
+
+const (
+	// messageTypeHello is sent by the agent when it connects and whenever its credentials change
+	messageTypeHello messageType = "hello"
+	// messageTypeDial is sent by kotsadm to ask the agent to open a new tunnel
+	messageTypeDial messageType = "dial"
+)
+
+type message struct {
+	Type   messageType `json:"type"`
+	ID     string      `json:"id,omitempty"`
+	Token  string      `json:"token,omitempty"`
+	CAData []byte      `json:"caData,omitempty"`
+}
+
+// Server keeps track of the agents that are connected to kotsadm and opens tunnels through them
+type Server struct {
+	upgrader websocket.Upgrader
+
+	mtx    sync.Mutex
+	agents map[string]*agentSession // key is cluster id
+}
+
+type agentSession struct {
+	conn     *websocket.Conn
+	writeMtx sync.Mutex
+
+	mtx     sync.Mutex
+	token   string
+	caData  []byte
+	pending map[string]chan net.Conn // key is tunnel id
+}
+
+var server = NewServer()
+
+// GetServer returns the server that the agents of all clusters connect to
+func GetServer() *Server {
+	return server
+}
+
+func NewServer() *Server {
+	return &Server{
+		agents: map[string]*agentSession{},
+	}
+}
+
+// Connect upgrades the request to the control connection of the agent of a cluster and serves it until it's closed.
+// A new connection from the same cluster replaces the previous one.
+func (s *Server) Connect(w http.ResponseWriter, r *http.Request, clusterID string) error {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to upgrade to websocket")
+	}
+	defer conn.Close()
+
+	session := &agentSession{
+		conn:    conn,
+		pending: map[string]chan net.Conn{},
+	}
+
+	s.mtx.Lock()
+	if existing, ok := s.agents[clusterID]; ok {
+		existing.conn.Close()
+	}
+	s.agents[clusterID] = session
+	s.mtx.Unlock()
+
+	logger.Infof("agent connected for cluster %s", clusterID)
+
+	defer func() {
+		s.mtx.Lock()
+		if s.agents[clusterID] == session {
+			delete(s.agents, clusterID)
+		}
+		s.mtx.Unlock()
+		logger.Infof("agent disconnected for cluster %s", clusterID)
+	}()
+
+	done := make(chan struct{})
+	defer close(done)
+	go session.ping(done)
+
+	for {
+		var msg message
+		if err := conn.ReadJSON(&msg); err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+			return errors.Wrap(err, "failed to read message")
+		}
+
+		switch msg.Type {
+		case messageTypeHello:
+			session.mtx.Lock()
+			session.token = msg.Token
+			session.caData = msg.CAData
+			session.mtx.Unlock()
+		default:
+			logger.Debugf("ignoring message of type %q from agent of cluster %s", msg.Type, clusterID)
+		}
+	}
+}
+
+// Accept upgrades the request to the data connection of a tunnel that was requested by Dial
+func (s *Server) Accept(w http.ResponseWriter, r *http.Request, clusterID string, tunnelID string) error {
+	session := s.getSession(clusterID)
+	if session == nil {
+		return errors.Errorf("agent for cluster %s is not connected", clusterID)
+	}
+
+	session.mtx.Lock()
+	ch, ok := session.pending[tunnelID]
+	delete(session.pending, tunnelID)
+	session.mtx.Unlock()
+	if !ok {
+		return errors.Errorf("tunnel %s was not requested", tunnelID)
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to upgrade to websocket")
+	}
+
+	// the connection is closed by whoever dialed it
+	ch <- newWSConn(conn)
+
+	return nil
+}
+
+// IsConnected returns true if the agent of the cluster is connected and has sent its credentials
+func (s *Server) IsConnected(clusterID string) bool {
+	session := s.getSession(clusterID)
+	if session == nil {
+		return false
+	}
+
+	session.mtx.Lock()
+	defer session.mtx.Unlock()
+
+	return session.token != ""
+}
+
+// Dial opens a connection to the api server of the cluster through its agent
+func (s *Server) Dial(ctx context.Context, clusterID string) (net.Conn, error) {
+	session := s.getSession(clusterID)
+	if session == nil {
+		return nil, errors.Errorf("agent for cluster %s is not connected", clusterID)
+	}
+
+	tunnelID := rand.StringWithCharset(32, rand.LOWER_CASE)
+	ch := make(chan net.Conn, 1)
+
+	session.mtx.Lock()
+	session.pending[tunnelID] = ch
+	session.mtx.Unlock()
+
+	defer func() {
+		session.mtx.Lock()
+		delete(session.pending, tunnelID)
+		session.mtx.Unlock()
+	}()
+
+	if err := session.send(message{Type: messageTypeDial, ID: tunnelID}); err != nil {
+		return nil, errors.Wrap(err, "failed to send dial message")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	select {
+	case conn := <-ch:
+		return conn, nil
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "failed to wait for tunnel")
+	}
+}
+
+// RESTConfig returns a config for the api server of the cluster that connects through its agent
+func (s *Server) RESTConfig(clusterID string) (*rest.Config, error) {
+	session := s.getSession(clusterID)
+	if session == nil {
+		return nil, errors.Errorf("agent for cluster %s is not connected", clusterID)
+	}
+
+	session.mtx.Lock()
+	caData := session.caData
+	session.mtx.Unlock()
+
+	config := &rest.Config{
+		Host: "https://" + APIServerHost,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAData: caData,
+		},
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return s.Dial(ctx, clusterID)
+		},
+		// the agent refreshes its token, so the latest one is used for every request
+		WrapTransport: func(rt http.RoundTripper) http.RoundTripper {
+			return &agentTokenRoundTripper{
+				server:    s,
+				clusterID: clusterID,
+				rt:        rt,
+			}
+		},
+	}
+
+	return config, nil
+}
+
+func (s *Server) getSession(clusterID string) *agentSession {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.agents[clusterID]
+}
+
+func (s *Server) token(clusterID string) string {
+	session := s.getSession(clusterID)
+	if session == nil {
+		return ""
+	}
+
+	session.mtx.Lock()
+	defer session.mtx.Unlock()
+
+	return session.token
+}
+
+func (a *agentSession) send(msg message) error {
+	a.writeMtx.Lock()
+	defer a.writeMtx.Unlock()
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal message")
+	}
+
+	return a.conn.WriteMessage(websocket.TextMessage, b)
+}
+
+func (a *agentSession) ping(done chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case <-time.After(pingInterval):
+		}
+
+		a.writeMtx.Lock()
+		err := a.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second))
+		a.writeMtx.Unlock()
+		if err != nil {
+			logger.Debugf("failed to ping agent: %v", err)
+		}
+	}
+}
+
+type agentTokenRoundTripper struct {
+	server    *Server
+	clusterID string
+	rt        http.RoundTripper
+}
+
+func (t *agentTokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	token := t.server.token(t.clusterID)
+	if token == "" {
+		return nil, errors.Errorf("agent for cluster %s is not connected", t.clusterID)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return t.rt.RoundTrip(req)
+}
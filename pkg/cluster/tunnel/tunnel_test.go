@@ -0,0 +1,133 @@
+package tunnel
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_agentURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		kotsadmURL string
+		path       string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "http",
+			kotsadmURL: "http://kotsadm.default.svc:3000",
+			path:       ConnectPath,
+			want:       "ws://kotsadm.default.svc:3000/api/v1/agent/connect",
+		},
+		{
+			name:       "https with a path prefix",
+			kotsadmURL: "https://admin.example.com/kotsadm/",
+			path:       TunnelPath + "/abc",
+			want:       "wss://admin.example.com/kotsadm/api/v1/agent/tunnel/abc",
+		},
+		{
+			name:       "unsupported scheme",
+			kotsadmURL: "ftp://admin.example.com",
+			path:       ConnectPath,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := agentURL(tt.kotsadmURL, tt.path)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_tunnel(t *testing.T) {
+	// stands in for the api server
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer upstream.Close()
+	go func() {
+		for {
+			conn, err := upstream.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	s := NewServer()
+	kotsadm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "deploy-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == ConnectPath:
+			s.Connect(w, r, "cluster-id")
+		case strings.HasPrefix(r.URL.Path, TunnelPath+"/"):
+			if err := s.Accept(w, r, "cluster-id", strings.TrimPrefix(r.URL.Path, TunnelPath+"/")); err != nil {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer kotsadm.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go RunAgent(ctx, AgentOptions{
+		KotsadmURL:       kotsadm.URL,
+		DeployToken:      "deploy-token",
+		APIServerAddress: upstream.Addr().String(),
+		Credentials: func() (string, []byte, error) {
+			return "sa-token", []byte("ca"), nil
+		},
+	})
+
+	require.Eventually(t, func() bool {
+		return s.IsConnected("cluster-id")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	config, err := s.RESTConfig("cluster-id")
+	require.NoError(t, err)
+	assert.Equal(t, "https://kubernetes.default.svc", config.Host)
+	assert.Equal(t, []byte("ca"), config.TLSClientConfig.CAData)
+
+	// tunnels are independent of each other
+	for _, payload := range []string{"first tunnel", "second tunnel"} {
+		conn, err := config.Dial(ctx, "tcp", "kubernetes.default.svc:443")
+		require.NoError(t, err)
+
+		_, err = conn.Write([]byte(payload))
+		require.NoError(t, err)
+
+		got := make([]byte, len(payload))
+		_, err = io.ReadFull(conn, got)
+		require.NoError(t, err)
+		assert.Equal(t, payload, string(got))
+
+		require.NoError(t, conn.Close())
+	}
+
+	_, err = s.Dial(ctx, "other-cluster-id")
+	require.Error(t, err)
+}
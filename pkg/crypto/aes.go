@@ -23,6 +23,12 @@ type aesCipher struct {
 
 const keyLength = 24 // 192 bit
 
+const (
+	EncryptionSecretName           = "kotsadm-encryption"
+	EncryptionKeySecretKey         = "encryptionKey"
+	PreviousEncryptionKeySecretKey = "previousEncryptionKey"
+)
+
 var decryptionCiphers []*aesCipher // used to decrypt data
 var encryptionCipher *aesCipher    // used to encrypt data
 
@@ -42,12 +48,12 @@ func init() {
 
 // InitFromSecret reads the encryption key from kubernetes and adds it to the list of decryptionCiphers, and sets this key to be used for encryption.
 func InitFromSecret(clientset kubernetes.Interface, namespace string) error {
-	sec, err := clientset.CoreV1().Secrets(namespace).Get(context.Background(), "kotsadm-encryption", metav1.GetOptions{})
+	sec, err := clientset.CoreV1().Secrets(namespace).Get(context.Background(), EncryptionSecretName, metav1.GetOptions{})
 	if err != nil {
 		return errors.Wrap(err, "get kotsadm-encryption secret")
 	}
 
-	secData, ok := sec.Data["encryptionKey"]
+	secData, ok := sec.Data[EncryptionKeySecretKey]
 	if !ok {
 		return fmt.Errorf("kotsadm-encryption secret in %s does not have member encryptionKey", namespace)
 	}
@@ -60,6 +66,15 @@ func InitFromSecret(clientset kubernetes.Interface, namespace string) error {
 	addCipher(secCipher)
 	encryptionCipher = secCipher
 
+	// values may still be encrypted with the previous key while the key is being rotated
+	if previousData, ok := sec.Data[PreviousEncryptionKeySecretKey]; ok {
+		previousCipher, err := aesCipherFromString(string(previousData))
+		if err != nil {
+			return errors.Wrap(err, "parse previous kotsadm-encryption key")
+		}
+		addCipher(previousCipher)
+	}
+
 	return nil
 }
 
@@ -81,7 +96,7 @@ func InitFromString(data string) error {
 func addCipher(aesCipher *aesCipher) {
 	foundMatch := false
 	for _, existingCipher := range decryptionCiphers {
-		if bytes.Equal(existingCipher.key, aesCipher.key) && bytes.Equal(existingCipher.nonce, aesCipher.nonce) {
+		if existingCipher.equals(aesCipher) {
 			foundMatch = true
 		}
 	}
@@ -97,35 +112,100 @@ func NewAESCipher() error {
 		return nil
 	}
 
+	newCipher, err := newRandomAESCipher()
+	if err != nil {
+		return err
+	}
+
+	addCipher(newCipher)
+	encryptionCipher = newCipher
+	return nil
+}
+
+// GenerateKey returns a new random key in the format of the kotsadm-encryption secret, without registering it
+func GenerateKey() (string, error) {
+	newCipher, err := newRandomAESCipher()
+	if err != nil {
+		return "", err
+	}
+	return newCipher.String(), nil
+}
+
+// SetEncryptionKey adds the key to the list of decryptionCiphers, and sets this key to be used for encryption.
+func SetEncryptionKey(data string) error {
+	newCipher, err := aesCipherFromString(data)
+	if err != nil {
+		return err
+	}
+	addCipher(newCipher)
+	encryptionCipher = newCipher
+	return nil
+}
+
+// RemoveDecryptionKey removes the key from the list of decryptionCiphers. The key that's used for encryption can't be removed.
+func RemoveDecryptionKey(data string) error {
+	oldCipher, err := aesCipherFromString(data)
+	if err != nil {
+		return err
+	}
+	if encryptionCipher != nil && encryptionCipher.equals(oldCipher) {
+		return errors.New("cannot remove the encryption key")
+	}
+
+	remaining := []*aesCipher{}
+	for _, existingCipher := range decryptionCiphers {
+		if !existingCipher.equals(oldCipher) {
+			remaining = append(remaining, existingCipher)
+		}
+	}
+	decryptionCiphers = remaining
+	return nil
+}
+
+// EncryptWithKey encrypts the data with the provided key rather than the registered encryption key
+func EncryptWithKey(data string, in []byte) ([]byte, error) {
+	keyCipher, err := aesCipherFromString(data)
+	if err != nil {
+		return nil, err
+	}
+	return keyCipher.cipher.Seal(nil, keyCipher.nonce, in, nil), nil
+}
+
+// DecryptWithKey decrypts the data with the provided key only
+func DecryptWithKey(data string, in []byte) ([]byte, error) {
+	keyCipher, err := aesCipherFromString(data)
+	if err != nil {
+		return nil, err
+	}
+	return keyCipher.decrypt(in)
+}
+
+func newRandomAESCipher() (*aesCipher, error) {
 	key := make([]byte, keyLength)
 	if _, err := rand.Read(key); err != nil {
-		return errors.Wrap(err, "failed to read key")
+		return nil, errors.Wrap(err, "failed to read key")
 	}
 
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		return errors.Wrap(err, "failed to create new cipher")
+		return nil, errors.Wrap(err, "failed to create new cipher")
 	}
 
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return errors.Wrap(err, "failed to wrap cipher gcm")
+		return nil, errors.Wrap(err, "failed to wrap cipher gcm")
 	}
 
 	nonce := make([]byte, gcm.NonceSize())
 	if _, err := rand.Read(nonce); err != nil {
-		return errors.Wrap(err, "failed to read nonce")
+		return nil, errors.Wrap(err, "failed to read nonce")
 	}
 
-	newCipher := &aesCipher{
+	return &aesCipher{
 		key:    key,
 		cipher: gcm,
 		nonce:  nonce,
-	}
-
-	addCipher(newCipher)
-	encryptionCipher = newCipher
-	return nil
+	}, nil
 }
 
 func aesCipherFromString(data string) (newCipher *aesCipher, initErr error) {
@@ -179,7 +259,15 @@ func ToString() string {
 	if encryptionCipher == nil {
 		return ""
 	}
-	return base64.StdEncoding.EncodeToString(append(encryptionCipher.key, encryptionCipher.nonce...))
+	return encryptionCipher.String()
+}
+
+func (c *aesCipher) String() string {
+	return base64.StdEncoding.EncodeToString(append(append([]byte{}, c.key...), c.nonce...))
+}
+
+func (c *aesCipher) equals(other *aesCipher) bool {
+	return bytes.Equal(c.key, other.key) && bytes.Equal(c.nonce, other.nonce)
 }
 
 func (c *aesCipher) decrypt(in []byte) (result []byte, err error) {
@@ -160,3 +160,86 @@ func Test_InitFromSecret(t *testing.T) {
 	req.NoError(err)
 	req.Equal(testString, string(decryptedData))
 }
+
+func Test_RotateKey(t *testing.T) {
+	req := require.New(t)
+
+	encryptionCipher = nil
+	decryptionCiphers = nil
+
+	req.NoError(NewAESCipher())
+	oldKey := ToString()
+	oldEncrypted := Encrypt([]byte("rotate me"))
+
+	newKey, err := GenerateKey()
+	req.NoError(err)
+	req.NotEqual(oldKey, newKey)
+
+	// generating a key does not change the encryption key
+	req.Equal(oldKey, ToString())
+
+	// the new key is used for encryption, and the old key can still decrypt
+	req.NoError(SetEncryptionKey(newKey))
+	req.Equal(newKey, ToString())
+	newEncrypted := Encrypt([]byte("rotate me"))
+	req.NotEqual(oldEncrypted, newEncrypted)
+
+	reencrypted, err := EncryptWithKey(newKey, []byte("rotate me"))
+	req.NoError(err)
+	req.Equal(newEncrypted, reencrypted)
+
+	decrypted, err := Decrypt(oldEncrypted)
+	req.NoError(err)
+	req.Equal("rotate me", string(decrypted))
+
+	_, err = DecryptWithKey(newKey, oldEncrypted)
+	req.Error(err)
+	decrypted, err = DecryptWithKey(newKey, newEncrypted)
+	req.NoError(err)
+	req.Equal("rotate me", string(decrypted))
+
+	// the encryption key can't be removed, the old key can
+	req.Error(RemoveDecryptionKey(newKey))
+	req.NoError(RemoveDecryptionKey(oldKey))
+	_, err = Decrypt(oldEncrypted)
+	req.Error(err)
+	decrypted, err = Decrypt(newEncrypted)
+	req.NoError(err)
+	req.Equal("rotate me", string(decrypted))
+}
+
+func Test_InitFromSecretWithPreviousKey(t *testing.T) {
+	req := require.New(t)
+
+	encryptionCipher = nil
+	decryptionCiphers = nil
+
+	req.NoError(NewAESCipher())
+	previousKey := ToString()
+	previousEncrypted := Encrypt([]byte("encrypted before the rotation"))
+
+	currentKey, err := GenerateKey()
+	req.NoError(err)
+
+	encryptionCipher = nil
+	decryptionCiphers = nil
+
+	clientset := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kotsadm-encryption",
+				Namespace: "testns",
+			},
+			Data: map[string][]byte{
+				"encryptionKey":         []byte(currentKey),
+				"previousEncryptionKey": []byte(previousKey),
+			},
+		})
+
+	req.NoError(InitFromSecret(clientset, "testns"))
+	req.Equal(currentKey, ToString())
+
+	decrypted, err := Decrypt(previousEncrypted)
+	req.NoError(err)
+	req.Equal("encrypted before the rotation", string(decrypted))
+}
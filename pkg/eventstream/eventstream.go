@@ -0,0 +1,140 @@
+package eventstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/eventstream/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/segmentio/ksuid"
+)
+
+// subscriptionBufferSize is the number of events a subscriber can fall behind by before events are dropped for it
+const subscriptionBufferSize = 100
+
+var defaultBroker = NewBroker()
+
+// Publish sends an event to the subscribers of the default broker. It never blocks, so that packages can publish events unconditionally.
+func Publish(event types.Event) {
+	defaultBroker.Publish(event)
+}
+
+// Subscribe subscribes to the events of the default broker that match the filter
+func Subscribe(filter Filter) *Subscription {
+	return defaultBroker.Subscribe(filter)
+}
+
+// Filter selects the events a subscription receives
+type Filter struct {
+	// AppID limits the events of apps to the ones of this app. Events that are not of an app, such as the status of tasks, always match.
+	AppID string
+	// Types limits the events to these types, an empty list means all types
+	Types []types.EventType
+}
+
+func (f Filter) Matches(event types.Event) bool {
+	if f.AppID != "" && event.AppID != "" && event.AppID != f.AppID {
+		return false
+	}
+	return f.MatchesType(event.Type)
+}
+
+func (f Filter) MatchesType(eventType types.EventType) bool {
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Broker fans events out to the subscriptions that match them
+type Broker struct {
+	mtx           sync.RWMutex
+	subscriptions map[*Subscription]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscriptions: map[*Subscription]struct{}{},
+	}
+}
+
+// Publish sends an event to every matching subscription. Events are dropped for subscriptions that are too far behind so that publishers are never blocked.
+func (b *Broker) Publish(event types.Event) {
+	if event.ID == "" {
+		event.ID = ksuid.New().String()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+
+	for s := range b.subscriptions {
+		if !s.filter.Matches(event) {
+			continue
+		}
+		select {
+		case s.events <- event:
+		default:
+			logger.Errorf("event stream subscription is full, dropping %s event %s", event.Type, event.ID)
+		}
+	}
+}
+
+func (b *Broker) Subscribe(filter Filter) *Subscription {
+	s := &Subscription{
+		broker: b,
+		filter: filter,
+		events: make(chan types.Event, subscriptionBufferSize),
+	}
+
+	b.mtx.Lock()
+	b.subscriptions[s] = struct{}{}
+	b.mtx.Unlock()
+
+	return s
+}
+
+type Subscription struct {
+	broker    *Broker
+	filter    Filter
+	events    chan types.Event
+	closeOnce sync.Once
+}
+
+func (s *Subscription) Events() <-chan types.Event {
+	return s.events
+}
+
+// Close unsubscribes from the broker and closes the events channel
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		s.broker.mtx.Lock()
+		delete(s.broker.subscriptions, s)
+		s.broker.mtx.Unlock()
+
+		close(s.events)
+	})
+}
+
+// WriteEvent writes an event in the Server-Sent Events format
+func WriteEvent(w io.Writer, event types.Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal event")
+	}
+	if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, b); err != nil {
+		return errors.Wrap(err, "failed to write event")
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package eventstream
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kots/pkg/eventstream/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_Matches(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		event  types.Event
+		want   bool
+	}{
+		{
+			name:   "empty filter",
+			filter: Filter{},
+			event:  types.Event{Type: types.EventAppStatus, AppID: "app-1"},
+			want:   true,
+		},
+		{
+			name:   "same app",
+			filter: Filter{AppID: "app-1"},
+			event:  types.Event{Type: types.EventAppStatus, AppID: "app-1"},
+			want:   true,
+		},
+		{
+			name:   "other app",
+			filter: Filter{AppID: "app-1"},
+			event:  types.Event{Type: types.EventAppStatus, AppID: "app-2"},
+			want:   false,
+		},
+		{
+			name:   "event that is not of an app",
+			filter: Filter{AppID: "app-1"},
+			event:  types.Event{Type: types.EventTaskStatus},
+			want:   true,
+		},
+		{
+			name:   "matching type",
+			filter: Filter{AppID: "app-1", Types: []types.EventType{types.EventDeployResult, types.EventAppStatus}},
+			event:  types.Event{Type: types.EventAppStatus, AppID: "app-1"},
+			want:   true,
+		},
+		{
+			name:   "other type",
+			filter: Filter{AppID: "app-1", Types: []types.EventType{types.EventDeployResult}},
+			event:  types.Event{Type: types.EventTaskStatus},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(tt.event))
+		})
+	}
+}
+
+func TestBroker(t *testing.T) {
+	b := NewBroker()
+
+	app1 := b.Subscribe(Filter{AppID: "app-1"})
+	defer app1.Close()
+	app2 := b.Subscribe(Filter{AppID: "app-2"})
+	defer app2.Close()
+
+	b.Publish(types.Event{Type: types.EventAppStatus, AppID: "app-1"})
+	b.Publish(types.Event{Type: types.EventTaskStatus, Data: types.TaskStatus{ID: "update-download", Status: "running"}})
+
+	event := <-app1.Events()
+	assert.Equal(t, types.EventAppStatus, event.Type)
+	assert.NotEmpty(t, event.ID)
+	assert.False(t, event.CreatedAt.IsZero())
+
+	event = <-app1.Events()
+	assert.Equal(t, types.EventTaskStatus, event.Type)
+
+	event = <-app2.Events()
+	assert.Equal(t, types.EventTaskStatus, event.Type)
+
+	select {
+	case event := <-app2.Events():
+		t.Fatalf("unexpected event %s", event.Type)
+	default:
+	}
+
+	// a subscription that is behind doesn't block publishers
+	for i := 0; i < subscriptionBufferSize+10; i++ {
+		b.Publish(types.Event{Type: types.EventAppStatus, AppID: "app-1"})
+	}
+	assert.Len(t, app1.Events(), subscriptionBufferSize)
+
+	// events are not published to closed subscriptions
+	app2.Close()
+	app2.Close()
+	b.Publish(types.Event{Type: types.EventTaskStatus})
+	_, ok := <-app2.Events()
+	assert.False(t, ok)
+}
+
+func TestWriteEvent(t *testing.T) {
+	sequence := int64(3)
+	event := types.Event{
+		ID:        "event-1",
+		Type:      types.EventDeployResult,
+		CreatedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		AppID:     "app-1",
+		Sequence:  &sequence,
+		Data:      types.DeployResult{Deployed: true},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, WriteEvent(buf, event))
+
+	want := "id: event-1\nevent: deploy.result\ndata: " +
+		`{"id":"event-1","type":"deploy.result","createdAt":"2024-06-01T12:00:00Z","appId":"app-1","sequence":3,"data":{"deployed":true}}` +
+		"\n\n"
+	assert.Equal(t, want, buf.String())
+}
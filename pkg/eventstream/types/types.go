@@ -0,0 +1,58 @@
+package types
+
+import (
+	"time"
+)
+
+type EventType string
+
+const (
+	EventAppStatus         EventType = "app.status"
+	EventTaskStatus        EventType = "task.status"
+	EventPreflightProgress EventType = "preflight.progress"
+	EventPreflightResults  EventType = "preflight.results"
+	EventDeployResult      EventType = "deploy.result"
+)
+
+var AllEventTypes = []EventType{
+	EventAppStatus,
+	EventTaskStatus,
+	EventPreflightProgress,
+	EventPreflightResults,
+	EventDeployResult,
+}
+
+func IsValidEventType(eventType EventType) bool {
+	for _, t := range AllEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is pushed to the clients of the event stream as it happens
+type Event struct {
+	ID        string    `json:"id"`
+	Type      EventType `json:"type"`
+	CreatedAt time.Time `json:"createdAt"`
+	// AppID is empty for events that are not of a single app, such as the status of tasks
+	AppID string `json:"appId,omitempty"`
+	// ClusterID is empty for the cluster kotsadm runs in
+	ClusterID string      `json:"clusterId,omitempty"`
+	Sequence  *int64      `json:"sequence,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// TaskStatus is the data of a task status event. An empty status means the task was cleared.
+type TaskStatus struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// DeployResult is the data of a deploy result event
+type DeployResult struct {
+	Deployed bool   `json:"deployed"`
+	Error    string `json:"error,omitempty"`
+}
@@ -180,7 +180,7 @@ func MigrateFromPVCToRqlite(ctx context.Context) error {
 	return nil
 }
 
-func isAlreadyMigrated(rqliteDB *gorqlite.Connection, migrationKey string) (bool, error) {
+func isAlreadyMigrated(rqliteDB persistence.DB, migrationKey string) (bool, error) {
 	rows, err := rqliteDB.QueryOneParameterized(gorqlite.ParameterizedStatement{
 		Query:     `SELECT value FROM kotsadm_params WHERE key = ?`,
 		Arguments: []interface{}{migrationKey},
@@ -15,6 +15,8 @@ import (
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	go_git_ssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
@@ -34,6 +36,13 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+const (
+	// GitOpsActionCommit commits and pushes each version directly to the configured branch
+	GitOpsActionCommit = "commit"
+	// GitOpsActionPullRequest pushes each version to its own branch and opens a pull request against the configured branch
+	GitOpsActionPullRequest = "pull-request"
+)
+
 type GitOpsConfig struct {
 	Provider    string `json:"provider"`
 	RepoURI     string `json:"repoUri"`
@@ -46,6 +55,7 @@ type GitOpsConfig struct {
 	Action      string `json:"action"`
 	PublicKey   string `json:"publicKey"`
 	PrivateKey  string `json:"-"`
+	APIToken    string `json:"-"`
 	IsConnected bool   `json:"isConnected"`
 }
 
@@ -58,6 +68,12 @@ type GlobalGitOpsConfig struct {
 	URI      string `json:"uri"`
 }
 
+// GitOpsCommit is the result of pushing a version to a gitops repo
+type GitOpsCommit struct {
+	CommitURL      string
+	PullRequestURL string
+}
+
 type KeyPair struct {
 	PrivateKeyPEM string
 	PublicKeySSH  string
@@ -80,22 +96,9 @@ func (g *GitOpsConfig) CommitURL(hash string) string {
 }
 
 func (g *GitOpsConfig) CloneURL() (string, error) {
-	// copied this logic from node js api
-	uriParts := strings.Split(g.RepoURI, "/")
-
-	if len(uriParts) < 5 {
-		return "", errors.Errorf("unexpected url format: %s", g.RepoURI)
-	}
-
-	owner := uriParts[3]
-	repo := uriParts[4]
-
-	if g.Provider == "bitbucket_server" {
-		if len(uriParts) < 7 {
-			return "", errors.Errorf("unexpected bitbucket server url format: %s", g.RepoURI)
-		}
-		owner = uriParts[4]
-		repo = uriParts[6]
+	owner, repo, err := g.ownerAndRepo()
+	if err != nil {
+		return "", err
 	}
 
 	switch g.Provider {
@@ -109,11 +112,38 @@ func (g *GitOpsConfig) CloneURL() (string, error) {
 		return fmt.Sprintf("git@%s:%s/%s/%s.git", g.Hostname, g.SSHPort, owner, repo), nil
 	case "github_enterprise", "gitlab_enterprise":
 		return fmt.Sprintf("git@%s:%s/%s.git", g.Hostname, owner, repo), nil
+	case "gitea":
+		if g.SSHPort != "" {
+			return fmt.Sprintf("ssh://git@%s:%s/%s/%s.git", g.Hostname, g.SSHPort, owner, repo), nil
+		}
+		return fmt.Sprintf("git@%s:%s/%s.git", g.Hostname, owner, repo), nil
 	}
 
 	return "", errors.Errorf("unsupported provider type: %s", g.Provider)
 }
 
+func (g *GitOpsConfig) ownerAndRepo() (string, string, error) {
+	// copied this logic from node js api
+	uriParts := strings.Split(g.RepoURI, "/")
+
+	if len(uriParts) < 5 {
+		return "", "", errors.Errorf("unexpected url format: %s", g.RepoURI)
+	}
+
+	owner := uriParts[3]
+	repo := uriParts[4]
+
+	if g.Provider == "bitbucket_server" {
+		if len(uriParts) < 7 {
+			return "", "", errors.Errorf("unexpected bitbucket server url format: %s", g.RepoURI)
+		}
+		owner = uriParts[4]
+		repo = uriParts[6]
+	}
+
+	return owner, repo, nil
+}
+
 // GetDownstreamGitOps will return the gitops config for a downstream,
 // This implementation copies how it works in typescript.
 func GetDownstreamGitOps(appID string, clusterID string) (*GitOpsConfig, error) {
@@ -179,10 +209,16 @@ func GetDownstreamGitOpsConfig(clientset kubernetes.Interface, appID string, clu
 					return nil, errors.Wrap(err, "failed to decrypt")
 				}
 
+				apiToken, err := apiTokenFromSecretData(idx, secret.Data)
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to get api token")
+				}
+
 				gitOpsConfig := GitOpsConfig{
 					Provider:   provider,
 					PublicKey:  publicKey,
 					PrivateKey: string(decryptedPrivateKey),
+					APIToken:   apiToken,
 					RepoURI:    repoURI,
 					Hostname:   hostname,
 					HTTPPort:   httpPort,
@@ -327,6 +363,12 @@ func UpdateDownstreamGitOps(appID, clusterID, uri, branch, path, format, action
 }
 
 func updateDownstreamGitOps(clientset kubernetes.Interface, appID, clusterID, uri, branch, path, format, action string) error {
+	switch action {
+	case "", GitOpsActionCommit, GitOpsActionPullRequest:
+	default:
+		return errors.Errorf("unsupported gitops action %q", action)
+	}
+
 	configMap, err := clientset.CoreV1().ConfigMaps(util.PodNamespace).Get(context.TODO(), "kotsadm-gitops", metav1.GetOptions{})
 	if err != nil && !kuberneteserrors.IsNotFound(err) {
 		return errors.Wrap(err, "failed to get configmap")
@@ -485,17 +527,19 @@ func TestGitOpsConnection(gitOpsConfig *GitOpsConfig) (string, error) {
 	return ref.Name().Short(), nil
 }
 
-func CreateGitOps(provider string, repoURI string, hostname string, httpPort string, sshPort string) error {
+// CreateGitOps stores the provider configuration for a repo. The api token is only used to open pull requests
+// and is left unchanged when empty.
+func CreateGitOps(provider string, repoURI string, hostname string, httpPort string, sshPort string, apiToken string) error {
 	clientset, err := k8sutil.GetClientset()
 	if err != nil {
 		return errors.Wrap(err, "failed to get k8s client set")
 	}
 
-	err = createGitOps(clientset, provider, repoURI, hostname, httpPort, sshPort)
+	err = createGitOps(clientset, provider, repoURI, hostname, httpPort, sshPort, apiToken)
 	return errors.Wrap(err, "failed to create gitops")
 }
 
-func createGitOps(clientset kubernetes.Interface, provider string, repoURI string, hostname string, httpPort string, sshPort string) error {
+func createGitOps(clientset kubernetes.Interface, provider string, repoURI string, hostname string, httpPort string, sshPort string, apiToken string) error {
 	secret, err := clientset.CoreV1().Secrets(util.PodNamespace).Get(context.TODO(), "kotsadm-gitops", metav1.GetOptions{})
 	if err != nil && !kuberneteserrors.IsNotFound(err) {
 		return errors.Wrap(err, "failed to get secret")
@@ -587,6 +631,11 @@ func createGitOps(clientset kubernetes.Interface, provider string, repoURI strin
 		secretData[sshPortKey] = []byte(sshPort)
 	}
 
+	if apiToken != "" {
+		encryptedAPIToken := crypto.Encrypt([]byte(apiToken))
+		secretData[fmt.Sprintf("provider.%d.apiToken", repoIdx)] = []byte(base64.StdEncoding.EncodeToString(encryptedAPIToken))
+	}
+
 	if secretExists {
 		secret.Data = secretData
 		_, err = clientset.CoreV1().Secrets(util.PodNamespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
@@ -702,6 +751,25 @@ func gitOpsConfigFromSecretData(idx int64, secretData map[string][]byte) (string
 	return provider, publicKey, privateKey, repoURI, hostname, httpPort, sshPort
 }
 
+func apiTokenFromSecretData(idx int64, secretData map[string][]byte) (string, error) {
+	encodedAPIToken, ok := secretData[fmt.Sprintf("provider.%d.apiToken", idx)]
+	if !ok || len(encodedAPIToken) == 0 {
+		return "", nil
+	}
+
+	decodedAPIToken, err := base64.StdEncoding.DecodeString(string(encodedAPIToken))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode")
+	}
+
+	decryptedAPIToken, err := crypto.Decrypt(decodedAPIToken)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decrypt")
+	}
+
+	return string(decryptedAPIToken), nil
+}
+
 func getAuth(privateKey string) (transport.AuthMethod, error) {
 	var auth transport.AuthMethod
 	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
@@ -713,42 +781,44 @@ func getAuth(privateKey string) (transport.AuthMethod, error) {
 	return auth, nil
 }
 
-func CreateGitOpsDownstreamCommit(a *apptypes.App, clusterID string, newSequence int, filesInDir string, downstreamName string) (string, error) {
+func CreateGitOpsDownstreamCommit(a *apptypes.App, clusterID string, newSequence int, filesInDir string, downstreamName string) (*GitOpsCommit, error) {
 	downstreamGitOps, err := GetDownstreamGitOps(a.ID, clusterID)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get downstream gitops")
+		return nil, errors.Wrap(err, "failed to get downstream gitops")
 	}
 	if downstreamGitOps == nil || !downstreamGitOps.IsConnected {
-		return "", nil
+		return &GitOpsCommit{}, nil
 	}
-	createdCommitURL, err := CreateGitOpsCommit(downstreamGitOps, a.Slug, a.Name, int(newSequence), filesInDir, downstreamName)
+	createdCommit, err := CreateGitOpsCommit(downstreamGitOps, a.Slug, a.Name, int(newSequence), filesInDir, downstreamName)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to create gitops commit")
+		return nil, errors.Wrap(err, "failed to create gitops commit")
 	}
-	return createdCommitURL, nil
+	return createdCommit, nil
 }
 
-func CreateGitOpsCommit(gitOpsConfig *GitOpsConfig, appSlug string, appName string, newSequence int, archiveDir string, downstreamName string) (string, error) {
+// CreateGitOpsCommit commits the rendered app to the gitops repo. Depending on the configured action, the commit is
+// either pushed to the configured branch, or to a per-sequence branch with a pull request opened against the configured branch.
+func CreateGitOpsCommit(gitOpsConfig *GitOpsConfig, appSlug string, appName string, newSequence int, archiveDir string, downstreamName string) (*GitOpsCommit, error) {
 	out, _, err := apparchive.GetRenderedApp(archiveDir, downstreamName, binaries.GetKustomizeBinPath())
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get rendered app")
+		return nil, errors.Wrap(err, "failed to get rendered app")
 	}
 
 	// using the deploy key, create the commit in a new branch
 	auth, err := getAuth(gitOpsConfig.PrivateKey)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get auth")
+		return nil, errors.Wrap(err, "failed to get auth")
 	}
 
 	workDir, err := ioutil.TempDir("", "kotsadm")
 	if err != nil {
-		return "", errors.Wrap(err, "failed to create temp dir")
+		return nil, errors.Wrap(err, "failed to create temp dir")
 	}
 	defer os.RemoveAll(workDir)
 
 	cloneURL, err := gitOpsConfig.CloneURL()
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get clone url")
+		return nil, errors.Wrap(err, "failed to get clone url")
 	}
 
 	cloneOptions := &git.CloneOptions{
@@ -759,7 +829,20 @@ func CreateGitOpsCommit(gitOpsConfig *GitOpsConfig, appSlug string, appName stri
 	}
 	cloned, workTree, err := CloneAndCheckout(workDir, cloneOptions, gitOpsConfig.Branch)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	isPullRequest := gitOpsConfig.Action == GitOpsActionPullRequest
+	pushBranch := gitOpsConfig.Branch
+	if isPullRequest {
+		pushBranch = PullRequestBranchName(appSlug, newSequence)
+		err := workTree.Checkout(&git.CheckoutOptions{
+			Branch: plumbing.NewBranchReferenceName(pushBranch),
+			Create: true,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create branch %s", pushBranch)
+		}
 	}
 
 	dirPath := filepath.Join(workDir, gitOpsConfig.Path)
@@ -768,7 +851,7 @@ func CreateGitOpsCommit(gitOpsConfig *GitOpsConfig, appSlug string, appName stri
 		// create subdirectory if not exist
 		err := os.MkdirAll(dirPath, 0755)
 		if err != nil {
-			return "", errors.Wrap(err, "failed to mkdir")
+			return nil, errors.Wrap(err, "failed to mkdir")
 		}
 	} // ignore error here and let the stat of the file below handle any errors
 
@@ -777,23 +860,23 @@ func CreateGitOpsCommit(gitOpsConfig *GitOpsConfig, appSlug string, appName stri
 	if err == nil { // if the file has not changed, end now
 		currentRevision, err := os.ReadFile(filePath)
 		if err != nil {
-			return "", errors.Wrap(err, "failed to read current app yaml")
+			return nil, errors.Wrap(err, "failed to read current app yaml")
 		}
 		if string(currentRevision) == string(out) {
-			return "", nil
+			return &GitOpsCommit{}, nil
 		}
 	} else if !os.IsNotExist(err) {
-		return "", errors.Wrap(err, "failed to stat current app yaml")
+		return nil, errors.Wrap(err, "failed to stat current app yaml")
 	}
 
 	err = ioutil.WriteFile(filePath, out, 0644)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to write updated app yaml")
+		return nil, errors.Wrap(err, "failed to write updated app yaml")
 	}
 
 	_, err = workTree.Add(strings.TrimPrefix(filepath.Join(gitOpsConfig.Path, fmt.Sprintf("%s.yaml", appSlug)), "/"))
 	if err != nil {
-		return "", errors.Wrap(err, "failed to add to worktree")
+		return nil, errors.Wrap(err, "failed to add to worktree")
 	}
 
 	// commit it
@@ -805,18 +888,42 @@ func CreateGitOpsCommit(gitOpsConfig *GitOpsConfig, appSlug string, appName stri
 		},
 	})
 	if err != nil {
-		return "", errors.Wrap(err, "failed to commit")
+		return nil, errors.Wrap(err, "failed to commit")
 	}
 
-	err = cloned.Push(&git.PushOptions{
+	pushOptions := &git.PushOptions{
 		RemoteName: cloneOptions.RemoteName,
 		Auth:       auth,
-	})
+	}
+	if isPullRequest {
+		// the per-sequence branch is owned by kots, so overwrite it if this sequence was pushed before
+		pushOptions.RefSpecs = []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/heads/%s", pushBranch, pushBranch)),
+		}
+	}
+	err = cloned.Push(pushOptions)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to push")
+		return nil, errors.Wrap(err, "failed to push")
+	}
+
+	createdCommit := &GitOpsCommit{
+		CommitURL: gitOpsConfig.CommitURL(updatedHash.String()),
+	}
+
+	if isPullRequest {
+		pullRequestURL, err := CreatePullRequest(gitOpsConfig, PullRequest{
+			Title: fmt.Sprintf("Update %s to version %d", appName, newSequence),
+			Body:  fmt.Sprintf("This pull request was opened by the KOTS Admin Console to update %s to version %d.", appName, newSequence),
+			Head:  pushBranch,
+			Base:  gitOpsConfig.Branch,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create pull request")
+		}
+		createdCommit.PullRequestURL = pullRequestURL
 	}
 
-	return gitOpsConfig.CommitURL(updatedHash.String()), nil
+	return createdCommit, nil
 }
 
 func generatePrivateKey_ed25519() (*KeyPair, error) {
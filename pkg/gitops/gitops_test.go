@@ -24,6 +24,7 @@ func Test_createGitOps(t *testing.T) {
 		hostname    string
 		httpPort    string
 		sshPort     string
+		apiToken    string
 		configIndex int64
 		action      string
 		branch      string
@@ -73,12 +74,27 @@ func Test_createGitOps(t *testing.T) {
 			path:        "/test/path/2",
 			wantKeyType: "ssh-ed25519",
 		},
+		{
+			name:        "gitea provider with pull requests",
+			provider:    "gitea",
+			repoURI:     "https://1.2.3.7:3000/test_org/test_repo",
+			hostname:    "1.2.3.7",
+			httpPort:    "3000",
+			sshPort:     "2222",
+			apiToken:    "test-token",
+			configIndex: 2,
+			action:      "pull-request",
+			branch:      "main",
+			format:      "single",
+			path:        "/test/path/3",
+			wantKeyType: "ssh-ed25519",
+		},
 	}
 
 	clientset := fake.NewSimpleClientset()
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			err := createGitOps(clientset, test.provider, test.repoURI, test.hostname, test.httpPort, test.sshPort)
+			err := createGitOps(clientset, test.provider, test.repoURI, test.hostname, test.httpPort, test.sshPort, test.apiToken)
 			assert.NoError(t, err)
 
 			err = updateDownstreamGitOps(clientset, test.appID, test.clusterID, test.repoURI, test.branch, test.path, test.format, test.action)
@@ -96,6 +112,7 @@ func Test_createGitOps(t *testing.T) {
 			assert.Equal(t, test.branch, config.Branch)
 			assert.Equal(t, test.format, config.Format)
 			assert.Equal(t, test.path, config.Path)
+			assert.Equal(t, test.apiToken, config.APIToken)
 
 			publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(config.PublicKey))
 			assert.NoError(t, err)
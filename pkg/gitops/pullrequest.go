@@ -0,0 +1,364 @@
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type PullRequest struct {
+	Title string
+	Body  string
+	// Head is the branch containing the changes
+	Head string
+	// Base is the branch the changes should be merged into
+	Base string
+}
+
+type pullRequestClient struct {
+	httpClient *http.Client
+	provider   string
+	apiURL     string
+	token      string
+	owner      string
+	repo       string
+}
+
+// PullRequestBranchName returns the branch that a version is pushed to when the gitops action is pull-request.
+func PullRequestBranchName(appSlug string, sequence int) string {
+	return fmt.Sprintf("kots/%s/%d", appSlug, sequence)
+}
+
+// APIURL returns the base url of the provider's REST API.
+func (g *GitOpsConfig) APIURL() (string, error) {
+	switch g.Provider {
+	case "github":
+		return "https://api.github.com", nil
+	case "gitlab":
+		return "https://gitlab.com/api/v4", nil
+	case "bitbucket":
+		return "https://api.bitbucket.org/2.0", nil
+	}
+
+	u, err := url.Parse(g.RepoURI)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse repo uri")
+	}
+	baseURL := fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+
+	switch g.Provider {
+	case "github_enterprise":
+		return baseURL + "/api/v3", nil
+	case "gitlab_enterprise":
+		return baseURL + "/api/v4", nil
+	case "bitbucket_server":
+		return baseURL + "/rest/api/1.0", nil
+	case "gitea":
+		return baseURL + "/api/v1", nil
+	}
+
+	return "", errors.Errorf("unsupported provider type: %s", g.Provider)
+}
+
+// CreatePullRequest opens a pull request (or merge request) in the gitops repo and returns its url.
+// If an open pull request already exists for the head branch, its url is returned instead.
+func CreatePullRequest(gitOpsConfig *GitOpsConfig, pr PullRequest) (string, error) {
+	if gitOpsConfig.APIToken == "" {
+		return "", errors.New("an api token is required to open pull requests")
+	}
+
+	apiURL, err := gitOpsConfig.APIURL()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get api url")
+	}
+
+	owner, repo, err := gitOpsConfig.ownerAndRepo()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get owner and repo")
+	}
+
+	client := &pullRequestClient{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		provider:   gitOpsConfig.Provider,
+		apiURL:     apiURL,
+		token:      gitOpsConfig.APIToken,
+		owner:      owner,
+		repo:       repo,
+	}
+
+	return client.createOrGetPullRequest(context.TODO(), pr)
+}
+
+func (c *pullRequestClient) createOrGetPullRequest(ctx context.Context, pr PullRequest) (string, error) {
+	existingURL, err := c.findPullRequest(ctx, pr)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to find existing pull request")
+	}
+	if existingURL != "" {
+		return existingURL, nil
+	}
+
+	prURL, err := c.createPullRequest(ctx, pr)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create pull request")
+	}
+
+	return prURL, nil
+}
+
+func (c *pullRequestClient) findPullRequest(ctx context.Context, pr PullRequest) (string, error) {
+	switch c.provider {
+	case "github", "github_enterprise":
+		query := url.Values{}
+		query.Set("state", "open")
+		query.Set("head", fmt.Sprintf("%s:%s", c.owner, pr.Head))
+		query.Set("base", pr.Base)
+		pulls := []struct {
+			HTMLURL string `json:"html_url"`
+		}{}
+		if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s/pulls?%s", c.owner, c.repo, query.Encode()), nil, &pulls); err != nil {
+			return "", err
+		}
+		if len(pulls) > 0 {
+			return pulls[0].HTMLURL, nil
+		}
+
+	case "gitlab", "gitlab_enterprise":
+		query := url.Values{}
+		query.Set("state", "opened")
+		query.Set("source_branch", pr.Head)
+		query.Set("target_branch", pr.Base)
+		mergeRequests := []struct {
+			WebURL string `json:"web_url"`
+		}{}
+		if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/projects/%s/merge_requests?%s", c.gitlabProjectID(), query.Encode()), nil, &mergeRequests); err != nil {
+			return "", err
+		}
+		if len(mergeRequests) > 0 {
+			return mergeRequests[0].WebURL, nil
+		}
+
+	case "bitbucket":
+		query := url.Values{}
+		query.Set("q", fmt.Sprintf(`state="OPEN" AND source.branch.name="%s" AND destination.branch.name="%s"`, pr.Head, pr.Base))
+		pullRequests := struct {
+			Values []bitbucketPullRequest `json:"values"`
+		}{}
+		if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/repositories/%s/%s/pullrequests?%s", c.owner, c.repo, query.Encode()), nil, &pullRequests); err != nil {
+			return "", err
+		}
+		if len(pullRequests.Values) > 0 {
+			return pullRequests.Values[0].Links.HTML.Href, nil
+		}
+
+	case "bitbucket_server":
+		query := url.Values{}
+		query.Set("state", "OPEN")
+		query.Set("direction", "OUTGOING")
+		query.Set("at", "refs/heads/"+pr.Head)
+		pullRequests := struct {
+			Values []bitbucketServerPullRequest `json:"values"`
+		}{}
+		if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/projects/%s/repos/%s/pull-requests?%s", c.owner, c.repo, query.Encode()), nil, &pullRequests); err != nil {
+			return "", err
+		}
+		for _, pullRequest := range pullRequests.Values {
+			if pullRequest.ToRef.ID == "refs/heads/"+pr.Base {
+				return pullRequest.url(), nil
+			}
+		}
+
+	case "gitea":
+		pulls := []struct {
+			HTMLURL string `json:"html_url"`
+			Head    struct {
+				Ref string `json:"ref"`
+			} `json:"head"`
+			Base struct {
+				Ref string `json:"ref"`
+			} `json:"base"`
+		}{}
+		if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s/pulls?state=open", c.owner, c.repo), nil, &pulls); err != nil {
+			return "", err
+		}
+		for _, pull := range pulls {
+			if pull.Head.Ref == pr.Head && pull.Base.Ref == pr.Base {
+				return pull.HTMLURL, nil
+			}
+		}
+
+	default:
+		return "", errors.Errorf("unsupported provider type: %s", c.provider)
+	}
+
+	return "", nil
+}
+
+func (c *pullRequestClient) createPullRequest(ctx context.Context, pr PullRequest) (string, error) {
+	switch c.provider {
+	case "github", "github_enterprise", "gitea":
+		body := map[string]interface{}{
+			"title": pr.Title,
+			"body":  pr.Body,
+			"head":  pr.Head,
+			"base":  pr.Base,
+		}
+		pull := struct {
+			HTMLURL string `json:"html_url"`
+		}{}
+		if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/%s/pulls", c.owner, c.repo), body, &pull); err != nil {
+			return "", err
+		}
+		return pull.HTMLURL, nil
+
+	case "gitlab", "gitlab_enterprise":
+		body := map[string]interface{}{
+			"title":                pr.Title,
+			"description":          pr.Body,
+			"source_branch":        pr.Head,
+			"target_branch":        pr.Base,
+			"remove_source_branch": true,
+		}
+		mergeRequest := struct {
+			WebURL string `json:"web_url"`
+		}{}
+		if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/merge_requests", c.gitlabProjectID()), body, &mergeRequest); err != nil {
+			return "", err
+		}
+		return mergeRequest.WebURL, nil
+
+	case "bitbucket":
+		body := map[string]interface{}{
+			"title":       pr.Title,
+			"description": pr.Body,
+			"source": map[string]interface{}{
+				"branch": map[string]string{"name": pr.Head},
+			},
+			"destination": map[string]interface{}{
+				"branch": map[string]string{"name": pr.Base},
+			},
+			"close_source_branch": true,
+		}
+		pullRequest := bitbucketPullRequest{}
+		if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/repositories/%s/%s/pullrequests", c.owner, c.repo), body, &pullRequest); err != nil {
+			return "", err
+		}
+		return pullRequest.Links.HTML.Href, nil
+
+	case "bitbucket_server":
+		body := map[string]interface{}{
+			"title":       pr.Title,
+			"description": pr.Body,
+			"fromRef":     map[string]string{"id": "refs/heads/" + pr.Head},
+			"toRef":       map[string]string{"id": "refs/heads/" + pr.Base},
+		}
+		pullRequest := bitbucketServerPullRequest{}
+		if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/repos/%s/pull-requests", c.owner, c.repo), body, &pullRequest); err != nil {
+			return "", err
+		}
+		return pullRequest.url(), nil
+	}
+
+	return "", errors.Errorf("unsupported provider type: %s", c.provider)
+}
+
+type bitbucketPullRequest struct {
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+}
+
+type bitbucketServerPullRequest struct {
+	ToRef struct {
+		ID string `json:"id"`
+	} `json:"toRef"`
+	Links struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+func (pr bitbucketServerPullRequest) url() string {
+	if len(pr.Links.Self) == 0 {
+		return ""
+	}
+	return pr.Links.Self[0].Href
+}
+
+func (c *pullRequestClient) gitlabProjectID() string {
+	return url.PathEscape(fmt.Sprintf("%s/%s", c.owner, c.repo))
+}
+
+func (c *pullRequestClient) setAuth(req *http.Request) {
+	switch c.provider {
+	case "gitlab", "gitlab_enterprise":
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	case "gitea":
+		req.Header.Set("Authorization", fmt.Sprintf("token %s", c.token))
+	case "bitbucket":
+		// bitbucket app passwords are provided as "username:app-password", access tokens as-is
+		if username, password, ok := strings.Cut(c.token, ":"); ok {
+			req.SetBasicAuth(username, password)
+		} else {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+		}
+	default:
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	}
+}
+
+func (c *pullRequestClient) do(ctx context.Context, method string, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal request body")
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL+path, reqBody)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.setAuth(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to execute %s request", method)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response body")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("unexpected status code %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return errors.Wrap(err, "failed to unmarshal response body")
+	}
+
+	return nil
+}
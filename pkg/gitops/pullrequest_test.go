@@ -0,0 +1,225 @@
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pullRequestClient_createOrGetPullRequest(t *testing.T) {
+	pr := PullRequest{
+		Title: "Update My App to version 3",
+		Body:  "body",
+		Head:  "kots/my-app/3",
+		Base:  "main",
+	}
+
+	tests := []struct {
+		name         string
+		provider     string
+		existing     bool
+		wantAuth     func(t *testing.T, r *http.Request)
+		handleFind   func(t *testing.T, w http.ResponseWriter, r *http.Request)
+		handleCreate func(t *testing.T, w http.ResponseWriter, r *http.Request)
+		want         string
+	}{
+		{
+			name:     "github creates a pull request",
+			provider: "github",
+			wantAuth: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+			},
+			handleFind: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/repos/my-org/my-repo/pulls", r.URL.Path)
+				assert.Equal(t, "my-org:kots/my-app/3", r.URL.Query().Get("head"))
+				w.Write([]byte(`[]`))
+			},
+			handleCreate: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/repos/my-org/my-repo/pulls", r.URL.Path)
+				body := map[string]string{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, "kots/my-app/3", body["head"])
+				assert.Equal(t, "main", body["base"])
+				assert.Equal(t, "Update My App to version 3", body["title"])
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"html_url": "https://github.com/my-org/my-repo/pull/1"}`))
+			},
+			want: "https://github.com/my-org/my-repo/pull/1",
+		},
+		{
+			name:     "github returns an existing pull request",
+			provider: "github",
+			existing: true,
+			handleFind: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`[{"html_url": "https://github.com/my-org/my-repo/pull/2"}]`))
+			},
+			want: "https://github.com/my-org/my-repo/pull/2",
+		},
+		{
+			name:     "gitlab creates a merge request",
+			provider: "gitlab",
+			wantAuth: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "test-token", r.Header.Get("PRIVATE-TOKEN"))
+			},
+			handleFind: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/projects/my-org%2Fmy-repo/merge_requests", r.URL.EscapedPath())
+				assert.Equal(t, "kots/my-app/3", r.URL.Query().Get("source_branch"))
+				w.Write([]byte(`[]`))
+			},
+			handleCreate: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/projects/my-org%2Fmy-repo/merge_requests", r.URL.EscapedPath())
+				body := map[string]interface{}{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, "kots/my-app/3", body["source_branch"])
+				assert.Equal(t, "main", body["target_branch"])
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"web_url": "https://gitlab.com/my-org/my-repo/-/merge_requests/1"}`))
+			},
+			want: "https://gitlab.com/my-org/my-repo/-/merge_requests/1",
+		},
+		{
+			name:     "bitbucket creates a pull request",
+			provider: "bitbucket",
+			wantAuth: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+			},
+			handleFind: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/repositories/my-org/my-repo/pullrequests", r.URL.Path)
+				w.Write([]byte(`{"values": []}`))
+			},
+			handleCreate: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				body := struct {
+					Source struct {
+						Branch struct {
+							Name string `json:"name"`
+						} `json:"branch"`
+					} `json:"source"`
+				}{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, "kots/my-app/3", body.Source.Branch.Name)
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"links": {"html": {"href": "https://bitbucket.org/my-org/my-repo/pull-requests/1"}}}`))
+			},
+			want: "https://bitbucket.org/my-org/my-repo/pull-requests/1",
+		},
+		{
+			name:     "bitbucket server creates a pull request",
+			provider: "bitbucket_server",
+			handleFind: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/projects/my-org/repos/my-repo/pull-requests", r.URL.Path)
+				assert.Equal(t, "refs/heads/kots/my-app/3", r.URL.Query().Get("at"))
+				// an open pull request from the same branch into a different base does not match
+				w.Write([]byte(`{"values": [{"toRef": {"id": "refs/heads/develop"}, "links": {"self": [{"href": "https://bitbucket.example.com/pull-requests/1"}]}}]}`))
+			},
+			handleCreate: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"toRef": {"id": "refs/heads/main"}, "links": {"self": [{"href": "https://bitbucket.example.com/pull-requests/2"}]}}`))
+			},
+			want: "https://bitbucket.example.com/pull-requests/2",
+		},
+		{
+			name:     "gitea returns an existing pull request",
+			provider: "gitea",
+			existing: true,
+			wantAuth: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "token test-token", r.Header.Get("Authorization"))
+			},
+			handleFind: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/repos/my-org/my-repo/pulls", r.URL.Path)
+				w.Write([]byte(`[
+					{"html_url": "https://gitea.example.com/my-org/my-repo/pulls/1", "head": {"ref": "kots/my-app/2"}, "base": {"ref": "main"}},
+					{"html_url": "https://gitea.example.com/my-org/my-repo/pulls/2", "head": {"ref": "kots/my-app/3"}, "base": {"ref": "main"}}
+				]`))
+			},
+			want: "https://gitea.example.com/my-org/my-repo/pulls/2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.wantAuth != nil {
+					tt.wantAuth(t, r)
+				}
+				switch r.Method {
+				case http.MethodGet:
+					tt.handleFind(t, w, r)
+				case http.MethodPost:
+					created = true
+					tt.handleCreate(t, w, r)
+				default:
+					t.Errorf("unexpected method %s", r.Method)
+				}
+			}))
+			defer server.Close()
+
+			client := &pullRequestClient{
+				httpClient: server.Client(),
+				provider:   tt.provider,
+				apiURL:     server.URL,
+				token:      "test-token",
+				owner:      "my-org",
+				repo:       "my-repo",
+			}
+
+			got, err := client.createOrGetPullRequest(context.Background(), pr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, !tt.existing, created)
+		})
+	}
+}
+
+func Test_pullRequestClient_createOrGetPullRequest_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message": "Validation Failed"}`))
+	}))
+	defer server.Close()
+
+	client := &pullRequestClient{
+		httpClient: server.Client(),
+		provider:   "github",
+		apiURL:     server.URL,
+		token:      "test-token",
+		owner:      "my-org",
+		repo:       "my-repo",
+	}
+
+	_, err := client.createOrGetPullRequest(context.Background(), PullRequest{Head: "kots/my-app/3", Base: "main"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status code 422")
+}
+
+func TestGitOpsConfig_APIURL(t *testing.T) {
+	tests := []struct {
+		provider string
+		repoURI  string
+		want     string
+	}{
+		{provider: "github", repoURI: "https://github.com/my-org/my-repo", want: "https://api.github.com"},
+		{provider: "github_enterprise", repoURI: "https://github.example.com/my-org/my-repo", want: "https://github.example.com/api/v3"},
+		{provider: "gitlab", repoURI: "https://gitlab.com/my-org/my-repo", want: "https://gitlab.com/api/v4"},
+		{provider: "gitlab_enterprise", repoURI: "https://gitlab.example.com/my-org/my-repo", want: "https://gitlab.example.com/api/v4"},
+		{provider: "bitbucket", repoURI: "https://bitbucket.org/my-org/my-repo", want: "https://api.bitbucket.org/2.0"},
+		{provider: "bitbucket_server", repoURI: "https://bitbucket.example.com:7990/projects/PROJ/repos/my-repo", want: "https://bitbucket.example.com:7990/rest/api/1.0"},
+		{provider: "gitea", repoURI: "http://gitea.example.com:3000/my-org/my-repo", want: "http://gitea.example.com:3000/api/v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			g := &GitOpsConfig{Provider: tt.provider, RepoURI: tt.repoURI}
+			got, err := g.APIURL()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/metrics"
 	"github.com/replicatedhq/kots/pkg/store"
 	"github.com/replicatedhq/kots/pkg/tasks"
 	"github.com/replicatedhq/kots/pkg/update"
@@ -236,7 +237,9 @@ func (h *Handler) UploadAirgapBundleChunk(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if _, err := io.Copy(airgapBundle, airgapBundleChunk); err != nil {
+	written, err := io.Copy(airgapBundle, airgapBundleChunk)
+	metrics.AddAirgapUploadBytes(written)
+	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -620,7 +623,8 @@ func (h *Handler) UploadAirgapUpdate(w http.ResponseWriter, r *http.Request) {
 		}
 		defer os.RemoveAll(tmpFile.Name())
 
-		_, err = io.Copy(tmpFile, part)
+		written, err := io.Copy(tmpFile, part)
+		metrics.AddAirgapUploadBytes(written)
 		if err != nil {
 			logger.Error(errors.Wrap(err, "failed to copy part data"))
 			w.WriteHeader(http.StatusInternalServerError)
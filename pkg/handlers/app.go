@@ -18,6 +18,8 @@ import (
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/maintenancewindow"
+	maintenancewindowtypes "github.com/replicatedhq/kots/pkg/maintenancewindow/types"
 	"github.com/replicatedhq/kots/pkg/operator"
 	"github.com/replicatedhq/kots/pkg/rbac"
 	"github.com/replicatedhq/kots/pkg/render"
@@ -51,10 +53,10 @@ func (h *Handler) GetPendingApp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	defaultRoles := rbac.DefaultRoles() // TODO (ethan): this should be set in the handler
+	roles := rbac.GetRoles(r.Context())
 
 	if sess.HasRBAC { // handle pre-rbac sessions
-		allow, err := rbac.CheckAccess(r.Context(), defaultRoles, "read", fmt.Sprintf("app.%s", papp.Slug), sess.Roles)
+		allow, err := rbac.CheckAccess(r.Context(), roles, "read", fmt.Sprintf("app.%s", papp.Slug), sess.Roles)
 		if err != nil {
 			logger.Error(errors.Wrapf(err, "failed to check access for pending app %s", papp.Slug))
 			w.WriteHeader(http.StatusInternalServerError)
@@ -101,11 +103,11 @@ func (h *Handler) ListApps(w http.ResponseWriter, r *http.Request) {
 	}
 
 	responseApps := []types.ResponseApp{}
-	defaultRoles := rbac.DefaultRoles() // TODO (ethan): this should be set in the handler
+	roles := rbac.GetRoles(r.Context())
 
 	for _, a := range apps {
 		if sess.HasRBAC { // handle pre-rbac sessions
-			allow, err := rbac.CheckAccess(r.Context(), defaultRoles, "read", fmt.Sprintf("app.%s", a.Slug), sess.Roles)
+			allow, err := rbac.CheckAccess(r.Context(), roles, "read", fmt.Sprintf("app.%s", a.Slug), sess.Roles)
 			if err != nil {
 				logger.Error(errors.Wrapf(err, "failed to check access for app %s", a.Slug))
 				w.WriteHeader(http.StatusInternalServerError)
@@ -147,8 +149,16 @@ func (h *Handler) GetAppStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	driftStatus, err := store.GetStore().GetAppDriftStatus(a.ID)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	appStatusResponse := types.AppStatusResponse{
 		AppStatus: appStatus,
+		Drift:     driftStatus,
 	}
 	JSON(w, http.StatusOK, appStatusResponse)
 }
@@ -337,6 +347,7 @@ func responseAppFromApp(ctx context.Context, a *apptypes.App, kcb kubeclient.Kub
 
 type GetAppVersionHistoryResponse struct {
 	downstreamtypes.DownstreamVersionHistory `json:",inline"`
+	QueuedDeploy                             *maintenancewindowtypes.QueuedDeploy `json:"queuedDeploy,omitempty"`
 }
 
 func (h *Handler) GetAppVersionHistory(w http.ResponseWriter, r *http.Request) {
@@ -398,8 +409,17 @@ func (h *Handler) GetAppVersionHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	queuedDeploy, err := maintenancewindow.GetQueuedDeploy(foundApp.ID, clusterID)
+	if err != nil {
+		err = errors.Wrap(err, "failed to get queued deploy")
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	response := GetAppVersionHistoryResponse{
 		DownstreamVersionHistory: *history,
+		QueuedDeploy:             queuedDeploy,
 	}
 
 	JSON(w, http.StatusOK, response)
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/cluster"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+)
+
+// defaultStatusHistoryRange is the time range of the status history and the uptime when the request doesn't set one
+const defaultStatusHistoryRange = 30 * 24 * time.Hour
+
+type GetAppStatusHistoryResponse struct {
+	From        time.Time                        `json:"from"`
+	To          time.Time                        `json:"to"`
+	Transitions []appstatetypes.StatusTransition `json:"transitions"`
+}
+
+// GetAppStatusHistory returns the transitions of the state of an app, and of its resources if the resources query param is true.
+// The from and to query params are RFC3339 times, and the clusterId query param selects a cluster other than the one kotsadm runs in.
+func (h *Handler) GetAppStatusHistory(w http.ResponseWriter, r *http.Request) {
+	req, status, err := parseAppStatusHistoryRequest(r)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to parse app status history request"))
+		w.WriteHeader(status)
+		return
+	}
+
+	transitions, err := store.GetStore().ListAppStatusTransitions(appstatetypes.ListStatusTransitionsOptions{
+		AppID:            req.appID,
+		ClusterID:        req.clusterID,
+		From:             req.from,
+		To:               req.to,
+		IncludeResources: r.URL.Query().Get("resources") == "true",
+	})
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list app status transitions"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	JSON(w, http.StatusOK, GetAppStatusHistoryResponse{
+		From:        req.from,
+		To:          req.to,
+		Transitions: transitions,
+	})
+}
+
+// GetAppUptime returns the availability of an app over a time range, overall and per deployed sequence.
+// It takes the same query params as GetAppStatusHistory.
+func (h *Handler) GetAppUptime(w http.ResponseWriter, r *http.Request) {
+	req, status, err := parseAppStatusHistoryRequest(r)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to parse app uptime request"))
+		w.WriteHeader(status)
+		return
+	}
+
+	initial, err := store.GetStore().GetLastAppStatusTransitionBefore(req.appID, req.clusterID, req.from)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get last app status transition"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	transitions, err := store.GetStore().ListAppStatusTransitions(appstatetypes.ListStatusTransitionsOptions{
+		AppID:     req.appID,
+		ClusterID: req.clusterID,
+		From:      req.from,
+		To:        req.to,
+	})
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list app status transitions"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	JSON(w, http.StatusOK, appstatetypes.SummarizeUptime(initial, transitions, req.from, req.to))
+}
+
+type appStatusHistoryRequest struct {
+	appID string
+	// clusterID is the cluster id the status history is recorded under
+	clusterID string
+	from      time.Time
+	to        time.Time
+}
+
+// parseAppStatusHistoryRequest returns the app, the cluster and the time range of a request.
+// The status is the http status to respond with if there is an error.
+func parseAppStatusHistoryRequest(r *http.Request) (*appStatusHistoryRequest, int, error) {
+	a, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			return nil, http.StatusNotFound, errors.Wrap(err, "failed to find app")
+		}
+		return nil, http.StatusInternalServerError, errors.Wrap(err, "failed to get app from slug")
+	}
+
+	// the history of the cluster kotsadm runs in is recorded without a cluster id
+	clusterID := ""
+	if id := r.URL.Query().Get("clusterId"); id != "" {
+		c, err := store.GetStore().GetCluster(id)
+		if err != nil {
+			return nil, http.StatusInternalServerError, errors.Wrap(err, "failed to get cluster")
+		}
+		if c == nil {
+			return nil, http.StatusNotFound, errors.Errorf("cluster %s not found", id)
+		}
+		if cluster.IsRemote(c) {
+			clusterID = id
+		}
+	}
+
+	now := time.Now()
+	to := now
+	if s := r.URL.Query().Get("to"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, http.StatusBadRequest, errors.Wrap(err, "failed to parse to")
+		}
+		to = t
+	}
+	// the time after now is not known yet
+	if to.After(now) {
+		to = now
+	}
+
+	from := to.Add(-defaultStatusHistoryRange)
+	if s := r.URL.Query().Get("from"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, http.StatusBadRequest, errors.Wrap(err, "failed to parse from")
+		}
+		from = t
+	}
+
+	if !from.Before(to) {
+		return nil, http.StatusBadRequest, errors.New("from must be before to")
+	}
+
+	return &appStatusHistoryRequest{
+		appID:     a.ID,
+		clusterID: clusterID,
+		from:      from,
+		to:        to,
+	}, http.StatusOK, nil
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+	audittypes "github.com/replicatedhq/kots/pkg/audit/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+)
+
+type ListAuditLogResponse struct {
+	audittypes.AuditLog `json:",inline"`
+}
+
+func (h *Handler) ListAuditLog(w http.ResponseWriter, r *http.Request) {
+	opts := audittypes.ListAuditLogOptions{
+		AppSlug:     r.URL.Query().Get("appSlug"),
+		Action:      r.URL.Query().Get("action"),
+		PageSize:    20,
+		CurrentPage: 0,
+	}
+
+	if val := r.URL.Query().Get("pageSize"); val != "" {
+		ps, err := strconv.Atoi(val)
+		if err != nil {
+			err = errors.Wrap(err, "failed to parse page size")
+			logger.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		opts.PageSize = ps
+	}
+	if val := r.URL.Query().Get("currentPage"); val != "" {
+		cp, err := strconv.Atoi(val)
+		if err != nil {
+			err = errors.Wrap(err, "failed to parse current page")
+			logger.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		opts.CurrentPage = cp
+	}
+
+	auditLog, err := store.GetStore().ListAuditLogEntries(opts)
+	if err != nil {
+		err = errors.Wrap(err, "failed to list audit log entries")
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	JSON(w, http.StatusOK, ListAuditLogResponse{AuditLog: *auditLog})
+}
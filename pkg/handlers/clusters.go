@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	downstreamtypes "github.com/replicatedhq/kots/pkg/api/downstream/types"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/cluster"
+	"github.com/replicatedhq/kots/pkg/cluster/tunnel"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/operator"
+	"github.com/replicatedhq/kots/pkg/store"
+	storetypes "github.com/replicatedhq/kots/pkg/store/types"
+)
+
+type ClusterResponse struct {
+	downstreamtypes.Downstream
+	// Connected is false for agent clusters whose agent is not connected
+	Connected bool `json:"connected"`
+}
+
+type ListClustersResponse struct {
+	Clusters []ClusterResponse `json:"clusters"`
+}
+
+type RegisterClusterRequest struct {
+	Title           string `json:"title"`
+	Kubeconfig      string `json:"kubeconfig"`
+	TargetNamespace string `json:"targetNamespace"`
+	// Agent registers a cluster that runs an agent instead of providing a kubeconfig
+	Agent bool `json:"agent"`
+}
+
+type RegisterClusterResponse struct {
+	Success bool             `json:"success"`
+	Error   string           `json:"error,omitempty"`
+	Cluster *ClusterResponse `json:"cluster,omitempty"`
+	// DeployToken is what the agent authenticates with, it's only returned when the cluster is registered
+	DeployToken string `json:"deployToken,omitempty"`
+}
+
+type GetAppClusterStatusResponse struct {
+	AppStatus *appstatetypes.AppStatus `json:"appstatus"`
+}
+
+func (h *Handler) ListClusters(w http.ResponseWriter, r *http.Request) {
+	clusters, err := store.GetStore().ListClusters()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list clusters"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response := ListClustersResponse{
+		Clusters: []ClusterResponse{},
+	}
+	for _, c := range clusters {
+		response.Clusters = append(response.Clusters, ClusterResponse{
+			Downstream: *c,
+			Connected:  cluster.IsReachable(c),
+		})
+	}
+
+	JSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) RegisterCluster(w http.ResponseWriter, r *http.Request) {
+	response := RegisterClusterResponse{
+		Success: false,
+	}
+
+	request := RegisterClusterRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.Error = "failed to decode request body"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	if request.Title == "" || (request.Kubeconfig == "" && !request.Agent) {
+		response.Error = "a title and either a kubeconfig or an agent are required"
+		logger.Error(errors.New(response.Error))
+		JSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := cluster.Register(cluster.RegisterOptions{
+		Title:           request.Title,
+		Kubeconfig:      request.Kubeconfig,
+		TargetNamespace: request.TargetNamespace,
+		Agent:           request.Agent,
+	})
+	if err != nil {
+		response.Error = errors.Cause(err).Error()
+		logger.Error(errors.Wrap(err, "failed to register cluster"))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Success = true
+	response.Cluster = &ClusterResponse{
+		Downstream: *result.Cluster,
+		Connected:  cluster.IsReachable(result.Cluster),
+	}
+	response.DeployToken = result.DeployToken
+
+	JSON(w, http.StatusCreated, response)
+}
+
+func (h *Handler) RemoveCluster(w http.ResponseWriter, r *http.Request) {
+	clusterID := mux.Vars(r)["clusterId"]
+
+	c, err := store.GetStore().GetCluster(clusterID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get cluster"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if c == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if !cluster.IsRemote(c) {
+		logger.Error(errors.Errorf("not removing cluster %s because kotsadm runs in it", clusterID))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := cluster.Remove(clusterID); err != nil {
+		logger.Error(errors.Wrap(err, "failed to remove cluster"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeployAppVersionToCluster deploys a version of an app to a single cluster, independently of its other downstreams
+func (h *Handler) DeployAppVersionToCluster(w http.ResponseWriter, r *http.Request) {
+	response := DeployAppVersionResponse{
+		Success: false,
+	}
+
+	appSlug := mux.Vars(r)["appSlug"]
+	clusterID := mux.Vars(r)["clusterId"]
+
+	sequence, err := strconv.ParseInt(mux.Vars(r)["sequence"], 10, 64)
+	if err != nil {
+		response.Error = "failed to parse sequence number"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	a, err := store.GetStore().GetAppFromSlug(appSlug)
+	if err != nil {
+		response.Error = fmt.Sprintf("failed to get app for slug %s", appSlug)
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	c, err := store.GetStore().GetCluster(clusterID)
+	if err != nil {
+		response.Error = "failed to get cluster"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+	if c == nil {
+		response.Error = fmt.Sprintf("cluster %s not found", clusterID)
+		JSON(w, http.StatusNotFound, response)
+		return
+	}
+
+	status, err := store.GetStore().GetStatusForVersion(a.ID, clusterID, sequence)
+	if err != nil {
+		response.Error = fmt.Sprintf("failed to get status for version %d", sequence)
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+	if status == storetypes.VersionPendingDownload || status == storetypes.VersionPendingConfig {
+		response.Error = fmt.Sprintf("not deploying version %d because it's %s", sequence, status)
+		logger.Error(errors.New(response.Error))
+		JSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	if !cluster.IsReachable(c) {
+		response.Error = fmt.Sprintf("cluster %s is not connected", c.Name)
+		logger.Error(errors.New(response.Error))
+		JSON(w, http.StatusServiceUnavailable, response)
+		return
+	}
+
+	if err := operator.DeployToCluster(a.ID, clusterID, sequence); err != nil {
+		response.Error = fmt.Sprintf("failed to deploy version %d to cluster %s", sequence, c.Name)
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Success = true
+	JSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) GetAppClusterStatus(w http.ResponseWriter, r *http.Request) {
+	a, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	clusterID := mux.Vars(r)["clusterId"]
+	c, err := store.GetStore().GetCluster(clusterID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get cluster"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if c == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var appStatus *appstatetypes.AppStatus
+	if cluster.IsRemote(c) {
+		appStatus, err = store.GetStore().GetDownstreamAppStatus(a.ID, clusterID)
+	} else {
+		appStatus, err = store.GetStore().GetAppStatus(a.ID)
+	}
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get app status"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	JSON(w, http.StatusOK, GetAppClusterStatusResponse{
+		AppStatus: appStatus,
+	})
+}
+
+// ConnectClusterAgent serves the control connection of the agent of a remote cluster. The agent authenticates
+// with the deploy token of its cluster.
+func (h *Handler) ConnectClusterAgent(w http.ResponseWriter, r *http.Request) {
+	c, err := requireValidAgentToken(w, r)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to authenticate agent"))
+		return
+	}
+
+	if err := tunnel.GetServer().Connect(w, r, c.ClusterID); err != nil {
+		logger.Error(errors.Wrapf(err, "agent connection for cluster %s failed", c.Name))
+		return
+	}
+}
+
+// ConnectClusterAgentTunnel serves a tunnel that was requested from the agent of a remote cluster
+func (h *Handler) ConnectClusterAgentTunnel(w http.ResponseWriter, r *http.Request) {
+	c, err := requireValidAgentToken(w, r)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to authenticate agent"))
+		return
+	}
+
+	if err := tunnel.GetServer().Accept(w, r, c.ClusterID, mux.Vars(r)["tunnelId"]); err != nil {
+		logger.Error(errors.Wrapf(err, "failed to accept tunnel for cluster %s", c.Name))
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+}
+
+func requireValidAgentToken(w http.ResponseWriter, r *http.Request) (*downstreamtypes.Downstream, error) {
+	token := r.Header.Get("Authorization")
+	if token == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil, errors.New("authorization header empty")
+	}
+
+	clusterID, err := store.GetStore().GetClusterIDFromDeployToken(token)
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil, errors.New("invalid deploy token")
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, errors.Wrap(err, "failed to get cluster id from deploy token")
+	}
+
+	c, err := store.GetStore().GetCluster(clusterID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, errors.Wrap(err, "failed to get cluster")
+	}
+	if c == nil || c.ConnectionType != cluster.ConnectionTypeAgent {
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil, errors.New("deploy token is not for an agent cluster")
+	}
+
+	return c, nil
+}
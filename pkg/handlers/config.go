@@ -663,7 +663,12 @@ func updateAppConfig(updateApp *apptypes.App, sequence int64, configGroups []kot
 	// so we don't need the complex logic in kots, we can just write
 	if kotsKinds.ConfigValues != nil {
 		values := kotsKinds.ConfigValues.Spec.Values
-		kotsKinds.ConfigValues.Spec.Values = kotsadmconfig.UpdateAppConfigValues(values, configGroups)
+		updatedValues, err := kotsadmconfig.UpdateAppConfigValues(values, configGroups)
+		if err != nil {
+			updateAppConfigResponse.Error = "failed to update config values"
+			return updateAppConfigResponse, err
+		}
+		kotsKinds.ConfigValues.Spec.Values = updatedValues
 
 		configValuesSpec, err := kotsKinds.Marshal("kots.io", "v1beta1", "ConfigValues")
 		if err != nil {
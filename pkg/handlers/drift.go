@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/logger"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
+	"github.com/replicatedhq/kots/pkg/store"
+)
+
+type GetAppDriftResponse struct {
+	AutoRemediate bool `json:"autoRemediate"`
+	// Drift is nil until the deployed version was checked for drift
+	Drift     *operatortypes.AppDriftStatus `json:"drift"`
+	IsDrifted bool                          `json:"isDrifted"`
+}
+
+type UpdateAppDriftSettingsRequest struct {
+	AutoRemediate bool `json:"autoRemediate"`
+}
+
+func (h *Handler) GetAppDrift(w http.ResponseWriter, r *http.Request) {
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	driftStatus, err := store.GetStore().GetAppDriftStatus(foundApp.ID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get drift status"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	autoRemediate, err := store.GetStore().GetAppDriftAutoRemediate(foundApp.ID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get drift auto remediate setting"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	JSON(w, http.StatusOK, GetAppDriftResponse{
+		AutoRemediate: autoRemediate,
+		Drift:         driftStatus,
+		IsDrifted:     driftStatus != nil && driftStatus.IsDrifted(),
+	})
+}
+
+// UpdateAppDriftSettings enables or disables re-applying drifted resources when drift is detected
+func (h *Handler) UpdateAppDriftSettings(w http.ResponseWriter, r *http.Request) {
+	request := UpdateAppDriftSettingsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error(errors.Wrap(err, "failed to decode request body"))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err := store.GetStore().SetAppDriftAutoRemediate(foundApp.ID, request.AutoRemediate); err != nil {
+		logger.Error(errors.Wrap(err, "failed to set drift auto remediate setting"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/keyrotation"
+	keyrotationtypes "github.com/replicatedhq/kots/pkg/keyrotation/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+)
+
+type RotateEncryptionKeyRequest struct {
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+type RotateEncryptionKeyResponse struct {
+	Success bool                     `json:"success"`
+	Error   string                   `json:"error,omitempty"`
+	Report  *keyrotationtypes.Report `json:"report,omitempty"`
+}
+
+func (h *Handler) RotateEncryptionKey(w http.ResponseWriter, r *http.Request) {
+	response := RotateEncryptionKeyResponse{}
+
+	rotateEncryptionKeyRequest := RotateEncryptionKeyRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&rotateEncryptionKeyRequest); err != nil {
+		response.Error = "failed to decode request"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	report, err := keyrotation.Rotate(r.Context(), keyrotation.RotateOptions{
+		DryRun: rotateEncryptionKeyRequest.DryRun,
+	})
+	response.Report = report
+	if errors.Is(err, keyrotation.ErrRotationInProgress) {
+		response.Error = err.Error()
+		JSON(w, http.StatusConflict, response)
+		return
+	}
+	if errors.Is(err, keyrotation.ErrRotationIncomplete) {
+		response.Error = err.Error()
+		logger.Error(err)
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+	if err != nil {
+		response.Error = "failed to rotate encryption key"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Success = true
+	JSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/eventstream"
+	eventstreamtypes "github.com/replicatedhq/kots/pkg/eventstream/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/segmentio/ksuid"
+)
+
+// eventStreamHeartbeatInterval keeps idle connections from being closed by proxies
+const eventStreamHeartbeatInterval = 15 * time.Second
+
+// StreamAppEvents pushes the events of an app, and the status of tasks, as Server-Sent Events until the client disconnects.
+// The types query param is a comma separated list of the event types to stream, all types are streamed by default.
+// The current status of the app is sent as the first event.
+func (h *Handler) StreamAppEvents(w http.ResponseWriter, r *http.Request) {
+	a, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	filter := eventstream.Filter{
+		AppID: a.ID,
+	}
+	if types := r.URL.Query().Get("types"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			eventType := eventstreamtypes.EventType(strings.TrimSpace(t))
+			if !eventstreamtypes.IsValidEventType(eventType) {
+				logger.Errorf("invalid event type %q", eventType)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			filter.Types = append(filter.Types, eventType)
+		}
+	}
+
+	// subscribe before getting the current status so that no change is missed in between
+	subscription := eventstream.Subscribe(filter)
+	defer subscription.Close()
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if filter.MatchesType(eventstreamtypes.EventAppStatus) {
+		appStatus, err := store.GetStore().GetAppStatus(a.ID)
+		if err != nil {
+			logger.Error(errors.Wrap(err, "failed to get app status"))
+			return
+		}
+		sequence := appStatus.Sequence
+		if err := eventstream.WriteEvent(w, eventstreamtypes.Event{
+			ID:        ksuid.New().String(),
+			Type:      eventstreamtypes.EventAppStatus,
+			CreatedAt: time.Now().UTC(),
+			AppID:     a.ID,
+			Sequence:  &sequence,
+			Data:      appStatus,
+		}); err != nil {
+			logger.Error(errors.Wrap(err, "failed to write initial app status"))
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		logger.Error(errors.Wrap(err, "failed to flush event stream"))
+		return
+	}
+
+	heartbeat := time.NewTicker(eventStreamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+			if err := eventstream.WriteEvent(w, event); err != nil {
+				logger.Debugf("failed to write %s event %s: %v", event.Type, event.ID, err)
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := w.Write([]byte(": heartbeat\n\n")); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -36,6 +36,8 @@ type CreateGitOpsInput struct {
 	Hostname string `json:"hostname"`
 	HTTPPort string `json:"httpPort"`
 	SSHPort  string `json:"sshPort"`
+	// APIToken is used to open pull requests when the gitops action is "pull-request"
+	APIToken string `json:"apiToken"`
 }
 
 func (h *Handler) UpdateAppGitOps(w http.ResponseWriter, r *http.Request) {
@@ -311,7 +313,7 @@ func (h *Handler) CreateGitOps(w http.ResponseWriter, r *http.Request) {
 	}
 
 	gitOpsInput := createGitOpsRequest.GitOpsInput
-	if err := gitops.CreateGitOps(gitOpsInput.Provider, gitOpsInput.URI, gitOpsInput.Hostname, gitOpsInput.HTTPPort, gitOpsInput.SSHPort); err != nil {
+	if err := gitops.CreateGitOps(gitOpsInput.Provider, gitOpsInput.URI, gitOpsInput.Hostname, gitOpsInput.HTTPPort, gitOpsInput.SSHPort, gitOpsInput.APIToken); err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
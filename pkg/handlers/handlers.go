@@ -39,7 +39,7 @@ func init() {
 }
 
 func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOTSHandler, middleware *policy.Middleware) {
-	r.Use(LoggingMiddleware, RequireValidSessionMiddleware(kotsStore))
+	r.Use(LoggingMiddleware, RequireValidSessionMiddleware(kotsStore), AuditMiddleware(kotsStore))
 
 	// Installation
 	r.Name("UploadNewLicense").Path("/api/v1/license").Methods("POST").
@@ -76,6 +76,10 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 		HandlerFunc(middleware.EnforceAccess(policy.AppSupportbundleWrite, handler.DeleteSupportBundle))
 	r.Name("GetPodDetailsFromSupportBundle").Path("/api/v1/troubleshoot/app/{appSlug}/supportbundle/{bundleId}/pod").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppSupportbundleRead, handler.GetPodDetailsFromSupportBundle))
+	r.Name("GetSupportBundlePolicy").Path("/api/v1/troubleshoot/app/{appSlug}/supportbundle-policy").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppSupportbundleRead, handler.GetSupportBundlePolicy))
+	r.Name("SetSupportBundlePolicy").Path("/api/v1/troubleshoot/app/{appSlug}/supportbundle-policy").Methods("PUT").
+		HandlerFunc(middleware.EnforceAccess(policy.AppSupportbundleWrite, handler.SetSupportBundlePolicy))
 
 	// redactor routes
 	r.Name("UpdateRedact").Path("/api/v1/redact/set").Methods("PUT").
@@ -114,6 +118,16 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 		HandlerFunc(middleware.EnforceAccess(policy.AppRead, handler.GetApp))
 	r.Name("GetAppStatus").Path("/api/v1/app/{appSlug}/status").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppStatusRead, handler.GetAppStatus))
+	r.Name("GetAppStatusHistory").Path("/api/v1/app/{appSlug}/status/history").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppStatusRead, handler.GetAppStatusHistory))
+	r.Name("GetAppUptime").Path("/api/v1/app/{appSlug}/status/uptime").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppStatusRead, handler.GetAppUptime))
+	r.Name("StreamAppEvents").Path("/api/v1/app/{appSlug}/events").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppStatusRead, handler.StreamAppEvents))
+	r.Name("GetAppDrift").Path("/api/v1/app/{appSlug}/drift").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppStatusRead, handler.GetAppDrift))
+	r.Name("UpdateAppDriftSettings").Path("/api/v1/app/{appSlug}/drift/settings").Methods("PUT").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.UpdateAppDriftSettings))
 	r.Name("GetAppVersionHistory").Path("/api/v1/app/{appSlug}/versions").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamRead, handler.GetAppVersionHistory))
 	r.Name("GetLatestDeployableVersion").Path("/api/v1/app/{appSlug}/next-app-version").Methods("GET").
@@ -175,10 +189,34 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamFiletreeRead, handler.GetAppRenderedContents))
 	r.Name("GetAppContents").Path("/api/v1/app/{appSlug}/sequence/{sequence}/contents").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamFiletreeRead, handler.GetAppContents))
+	r.Name("GetAppVersionDiff").Path("/api/v1/app/{appSlug}/diff/{from}/{to}").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamFiletreeRead, handler.GetAppVersionDiff))
 	r.Name("GetAppDashboard").Path("/api/v1/app/{appSlug}/cluster/{clusterId}/dashboard").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppRead, handler.GetAppDashboard))
 	r.Name("GetDownstreamOutput").Path("/api/v1/app/{appSlug}/cluster/{clusterId}/sequence/{sequence}/downstreamoutput").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamLogsRead, handler.GetDownstreamOutput))
+	r.Name("DeployAppVersionToCluster").Path("/api/v1/app/{appSlug}/cluster/{clusterId}/sequence/{sequence}/deploy").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.DeployAppVersionToCluster))
+	r.Name("GetAppClusterStatus").Path("/api/v1/app/{appSlug}/cluster/{clusterId}/status").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppStatusRead, handler.GetAppClusterStatus))
+	r.Name("ListPromotionStages").Path("/api/v1/app/{appSlug}/promotion/stages").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamRead, handler.ListPromotionStages))
+	r.Name("SetPromotionStage").Path("/api/v1/app/{appSlug}/cluster/{clusterId}/promotion/stage").Methods("PUT").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.SetPromotionStage))
+	r.Name("DeletePromotionStage").Path("/api/v1/app/{appSlug}/cluster/{clusterId}/promotion/stage").Methods("DELETE").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.DeletePromotionStage))
+	r.Name("GetPromotionGates").Path("/api/v1/app/{appSlug}/cluster/{clusterId}/promotion/gates").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamRead, handler.GetPromotionGates))
+	r.Name("PromoteAppVersion").Path("/api/v1/app/{appSlug}/cluster/{clusterId}/promote").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.PromoteAppVersion))
+
+	// Remote clusters
+	r.Name("ListClusters").Path("/api/v1/clusters").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.ClusterRead, handler.ListClusters))
+	r.Name("RegisterCluster").Path("/api/v1/clusters").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.ClusterWrite, handler.RegisterCluster))
+	r.Name("RemoveCluster").Path("/api/v1/cluster/{clusterId}").Methods("DELETE").
+		HandlerFunc(middleware.EnforceAccess(policy.ClusterWrite, handler.RemoveCluster))
 
 	r.Name("GetKotsadmRegistry").Path("/api/v1/registry").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.RegistryRead, handler.GetKotsadmRegistry))
@@ -189,6 +227,9 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 	r.Name("DockerHubSecretUpdated").Path("/api/v1/docker/secret-updated").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppCreate, handler.DockerHubSecretUpdated))
 
+	r.Name("RotateEncryptionKey").Path("/api/v1/encryption-key/rotate").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.EncryptionKeyWrite, handler.RotateEncryptionKey))
+
 	r.Name("UpdateAppRegistry").Path("/api/v1/app/{appSlug}/registry").Methods("PUT").
 		HandlerFunc(middleware.EnforceAccess(policy.AppRegistryWrite, handler.UpdateAppRegistry))
 	r.Name("GetAppRegistry").Path("/api/v1/app/{appSlug}/registry").Methods("GET").
@@ -197,6 +238,10 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 		HandlerFunc(middleware.EnforceAccess(policy.AppRegistryRead, handler.GetImageRewriteStatus))
 	r.Name("ValidateAppRegistry").Path("/api/v1/app/{appSlug}/registry/validate").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppRegistryWrite, handler.ValidateAppRegistry))
+	r.Name("GetAppImageVerificationPolicy").Path("/api/v1/app/{appSlug}/image-verification-policy").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppRegistryRead, handler.GetAppImageVerificationPolicy))
+	r.Name("UpdateAppImageVerificationPolicy").Path("/api/v1/app/{appSlug}/image-verification-policy").Methods("PUT").
+		HandlerFunc(middleware.EnforceAccess(policy.AppRegistryWrite, handler.UpdateAppImageVerificationPolicy))
 
 	r.Name("UpdateAppConfig").Path("/api/v1/app/{appSlug}/config").Methods("PUT").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamConfigWrite, handler.UpdateAppConfig))
@@ -222,6 +267,12 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.SetAutomaticUpdatesConfig))
 	r.Name("GetAutomaticUpdatesConfig").Path("/api/v1/app/{appSlug}/automaticupdates").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.GetAutomaticUpdatesConfig))
+	r.Name("GetMaintenanceWindows").Path("/api/v1/app/{appSlug}/maintenance-windows").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamRead, handler.GetMaintenanceWindows))
+	r.Name("SetMaintenanceWindows").Path("/api/v1/app/{appSlug}/maintenance-windows").Methods("PUT").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.SetMaintenanceWindows))
+	r.Name("CancelQueuedDeploy").Path("/api/v1/app/{appSlug}/deploy-queue").Methods("DELETE").
+		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.CancelQueuedDeploy))
 	r.Name("RemoveApp").Path("/api/v1/app/{appSlug}/remove").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppUpdate, handler.RemoveApp))
 
@@ -256,6 +307,10 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsWrite, handler.SaveInstanceSnapshotSchedule))
 	r.Name("SaveInstanceSnapshotRetention").Path("/api/v1/snapshot/retention").Methods("PUT").
 		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsWrite, handler.SaveInstanceSnapshotRetention))
+	r.Name("GetInstanceSnapshotVerificationPolicy").Path("/api/v1/snapshot/verification-policy").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetInstanceSnapshotVerificationPolicy))
+	r.Name("SetInstanceSnapshotVerificationPolicy").Path("/api/v1/snapshot/verification-policy").Methods("PUT").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsWrite, handler.SetInstanceSnapshotVerificationPolicy))
 	r.Name("GetGlobalSnapshotSettings").Path("/api/v1/snapshots/settings").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetGlobalSnapshotSettings))
 	r.Name("UpdateGlobalSnapshotSettings").Path("/api/v1/snapshots/settings").Methods("PUT").
@@ -266,6 +321,8 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 		HandlerFunc(middleware.EnforceAccess(policy.BackupRead, handler.GetBackup))
 	r.Name("DeleteBackup").Path("/api/v1/snapshot/{snapshotName}/delete").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.BackupWrite, handler.DeleteBackup))
+	r.Name("VerifyInstanceBackup").Path("/api/v1/snapshot/{snapshotName}/verify").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.BackupWrite, handler.VerifyInstanceBackup))
 	r.Name("RestoreApps").Path("/api/v1/snapshot/{snapshotName}/restore-apps").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.RestoreWrite, handler.RestoreApps))
 	r.Name("GetRestoreAppsStatus").Path("/api/v1/snapshot/{snapshotName}/apps-restore-status").Methods("POST").
@@ -331,6 +388,24 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.Store, handler KOT
 	r.Name("ChangePassword").Path("/api/v1/password/change").Methods("PUT").
 		HandlerFunc(middleware.EnforceAccess(policy.PasswordChange, handler.ChangePassword))
 
+	// Audit log
+	r.Name("ListAuditLog").Path("/api/v1/audit").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AuditRead, handler.ListAuditLog))
+
+	// Notifications
+	r.Name("ListWebhookTargets").Path("/api/v1/notifications/webhooks").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.NotificationsRead, handler.ListWebhookTargets))
+	r.Name("CreateWebhookTarget").Path("/api/v1/notifications/webhooks").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.NotificationsWrite, handler.CreateWebhookTarget))
+	r.Name("UpdateWebhookTarget").Path("/api/v1/notifications/webhook/{webhookId}").Methods("PUT").
+		HandlerFunc(middleware.EnforceAccess(policy.NotificationsWrite, handler.UpdateWebhookTarget))
+	r.Name("DeleteWebhookTarget").Path("/api/v1/notifications/webhook/{webhookId}").Methods("DELETE").
+		HandlerFunc(middleware.EnforceAccess(policy.NotificationsWrite, handler.DeleteWebhookTarget))
+	r.Name("ListWebhookDeliveries").Path("/api/v1/notifications/webhook/{webhookId}/deliveries").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.NotificationsRead, handler.ListWebhookDeliveries))
+	r.Name("TestWebhookTarget").Path("/api/v1/notifications/webhook/{webhookId}/test").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.NotificationsWrite, handler.TestWebhookTarget))
+
 	// Debug info
 	r.Name("GetDebugInfo").Path("/api/v1/debug").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.ClusterRead, handler.GetDebugInfo))
@@ -360,12 +435,17 @@ func JSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.Write(response)
 }
 
-func RegisterTokenAuthRoutes(handler *Handler, debugRouter *mux.Router, loggingRouter *mux.Router) {
+func RegisterTokenAuthRoutes(handler *Handler, kotsStore store.Store, debugRouter *mux.Router, loggingRouter *mux.Router) {
+	audit := TokenAuthAuditMiddleware(kotsStore)
+
 	debugRouter.Path("/api/v1/kots/ports").Methods("GET").HandlerFunc(handler.GetApplicationPorts)
-	loggingRouter.Path("/api/v1/upload").Methods("PUT").HandlerFunc(handler.UploadExistingApp)
+	loggingRouter.Name("UploadExistingApp").Path("/api/v1/upload").Methods("PUT").
+		Handler(audit(http.HandlerFunc(handler.UploadExistingApp)))
 	loggingRouter.Path("/api/v1/download").Methods("GET").HandlerFunc(handler.DownloadApp)
-	loggingRouter.Path("/api/v1/airgap/install").Methods("POST").HandlerFunc(handler.UploadInitialAirgapApp)
-	loggingRouter.Path("/api/v1/branding/install").Methods("POST").HandlerFunc(handler.UploadInitialBranding)
+	loggingRouter.Name("UploadInitialAirgapApp").Path("/api/v1/airgap/install").Methods("POST").
+		Handler(audit(http.HandlerFunc(handler.UploadInitialAirgapApp)))
+	loggingRouter.Name("UploadInitialBranding").Path("/api/v1/branding/install").Methods("POST").
+		Handler(audit(http.HandlerFunc(handler.UploadInitialBranding)))
 }
 
 func RegisterUnauthenticatedRoutes(handler *Handler, kotsStore store.Store, debugRouter *mux.Router, loggingRouter *mux.Router) {
@@ -610,12 +610,11 @@ func (h *Handler) GetIdentityServiceConfig(w http.ResponseWriter, r *http.Reques
 	}
 
 	roles := []kotsv1beta1.IdentityRole{}
-	defaultRoles := rbac.DefaultRoles()
-	for _, defaultRole := range defaultRoles {
+	for _, rbacRole := range rbac.GetRoles(r.Context()) {
 		role := kotsv1beta1.IdentityRole{
-			ID:          defaultRole.ID,
-			Name:        defaultRole.Name,
-			Description: defaultRole.Description,
+			ID:          rbacRole.ID,
+			Name:        rbacRole.Name,
+			Description: rbacRole.Description,
 		}
 		roles = append(roles, role)
 	}
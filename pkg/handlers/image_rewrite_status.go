@@ -3,13 +3,18 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	imagetypes "github.com/replicatedhq/kots/pkg/image/types"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
 	"github.com/replicatedhq/kots/pkg/tasks"
 )
 
 type GetImageRewriteStatusResponse struct {
-	Status         string `json:"status"`
-	CurrentMessage string `json:"currentMessage"`
+	Status             string                               `json:"status"`
+	CurrentMessage     string                               `json:"currentMessage"`
+	ImageVerifications []imagetypes.ImageVerificationResult `json:"imageVerifications,omitempty"`
 }
 
 func (h *Handler) GetImageRewriteStatus(w http.ResponseWriter, r *http.Request) {
@@ -25,5 +30,30 @@ func (h *Handler) GetImageRewriteStatus(w http.ResponseWriter, r *http.Request)
 		CurrentMessage: message,
 	}
 
+	// the images are rewritten for a new version, return the verification results of the latest version
+	if appSlug := mux.Vars(r)["appSlug"]; appSlug != "" {
+		foundApp, err := store.GetStore().GetAppFromSlug(appSlug)
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to get app %s", appSlug))
+			w.WriteHeader(500)
+			return
+		}
+
+		latestSequence, err := store.GetStore().GetLatestAppSequence(foundApp.ID, true)
+		if err != nil {
+			logger.Error(errors.Wrap(err, "failed to get latest app sequence"))
+			w.WriteHeader(500)
+			return
+		}
+
+		imageVerifications, err := store.GetStore().GetImageVerificationResults(foundApp.ID, latestSequence)
+		if err != nil {
+			logger.Error(errors.Wrap(err, "failed to get image verification results"))
+			w.WriteHeader(500)
+			return
+		}
+		getImageRewriteStatusResponse.ImageVerifications = imageVerifications
+	}
+
 	JSON(w, 200, getImageRewriteStatusResponse)
 }
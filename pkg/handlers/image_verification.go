@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/imageverification"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/util"
+)
+
+type GetAppImageVerificationPolicyResponse struct {
+	Success       bool   `json:"success"`
+	Error         string `json:"error,omitempty"`
+	Policy        string `json:"policy"`
+	DefaultPolicy string `json:"defaultPolicy"`
+}
+
+type UpdateAppImageVerificationPolicyRequest struct {
+	Policy string `json:"policy"`
+}
+
+type UpdateAppImageVerificationPolicyResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (h *Handler) GetAppImageVerificationPolicy(w http.ResponseWriter, r *http.Request) {
+	getAppImageVerificationPolicyResponse := GetAppImageVerificationPolicyResponse{
+		Success: false,
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		logger.Error(err)
+		getAppImageVerificationPolicyResponse.Error = err.Error()
+		JSON(w, http.StatusInternalServerError, getAppImageVerificationPolicyResponse)
+		return
+	}
+
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get clientset"))
+		getAppImageVerificationPolicyResponse.Error = err.Error()
+		JSON(w, http.StatusInternalServerError, getAppImageVerificationPolicyResponse)
+		return
+	}
+
+	policy, defaultPolicy, err := imageverification.GetPolicies(clientset, util.PodNamespace, foundApp.Slug)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get image verification policies"))
+		getAppImageVerificationPolicyResponse.Error = err.Error()
+		JSON(w, http.StatusInternalServerError, getAppImageVerificationPolicyResponse)
+		return
+	}
+
+	getAppImageVerificationPolicyResponse.Success = true
+	getAppImageVerificationPolicyResponse.Policy = policy
+	getAppImageVerificationPolicyResponse.DefaultPolicy = defaultPolicy
+
+	JSON(w, http.StatusOK, getAppImageVerificationPolicyResponse)
+}
+
+func (h *Handler) UpdateAppImageVerificationPolicy(w http.ResponseWriter, r *http.Request) {
+	updateAppImageVerificationPolicyResponse := UpdateAppImageVerificationPolicyResponse{
+		Success: false,
+	}
+
+	updateAppImageVerificationPolicyRequest := UpdateAppImageVerificationPolicyRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&updateAppImageVerificationPolicyRequest); err != nil {
+		logger.Error(errors.Wrap(err, "failed to decode UpdateAppImageVerificationPolicy request body"))
+		updateAppImageVerificationPolicyResponse.Error = err.Error()
+		JSON(w, http.StatusBadRequest, updateAppImageVerificationPolicyResponse)
+		return
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		logger.Error(err)
+		updateAppImageVerificationPolicyResponse.Error = err.Error()
+		JSON(w, http.StatusInternalServerError, updateAppImageVerificationPolicyResponse)
+		return
+	}
+
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get clientset"))
+		updateAppImageVerificationPolicyResponse.Error = err.Error()
+		JSON(w, http.StatusInternalServerError, updateAppImageVerificationPolicyResponse)
+		return
+	}
+
+	if err := imageverification.SetAppPolicy(clientset, util.PodNamespace, foundApp.Slug, updateAppImageVerificationPolicyRequest.Policy); err != nil {
+		logger.Error(errors.Wrap(err, "failed to set image verification policy"))
+		updateAppImageVerificationPolicyResponse.Error = err.Error()
+		JSON(w, http.StatusBadRequest, updateAppImageVerificationPolicyResponse)
+		return
+	}
+
+	updateAppImageVerificationPolicyResponse.Success = true
+
+	JSON(w, http.StatusOK, updateAppImageVerificationPolicyResponse)
+}
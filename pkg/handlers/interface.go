@@ -23,6 +23,8 @@ type KOTSHandler interface {
 	ShareSupportBundle(w http.ResponseWriter, r *http.Request)
 	DeleteSupportBundle(w http.ResponseWriter, r *http.Request)
 	GetPodDetailsFromSupportBundle(w http.ResponseWriter, r *http.Request)
+	GetSupportBundlePolicy(w http.ResponseWriter, r *http.Request)
+	SetSupportBundlePolicy(w http.ResponseWriter, r *http.Request)
 
 	// redactor routes
 	UpdateRedact(w http.ResponseWriter, r *http.Request)
@@ -45,12 +47,31 @@ type KOTSHandler interface {
 	ListApps(w http.ResponseWriter, r *http.Request)
 	GetApp(w http.ResponseWriter, r *http.Request)
 	GetAppStatus(w http.ResponseWriter, r *http.Request)
+	GetAppStatusHistory(w http.ResponseWriter, r *http.Request)
+	GetAppUptime(w http.ResponseWriter, r *http.Request)
+	StreamAppEvents(w http.ResponseWriter, r *http.Request)
+	GetAppDrift(w http.ResponseWriter, r *http.Request)
+	UpdateAppDriftSettings(w http.ResponseWriter, r *http.Request)
 	GetAppVersionHistory(w http.ResponseWriter, r *http.Request)
 	GetLatestDeployableVersion(w http.ResponseWriter, r *http.Request)
 	GetUpdateDownloadStatus(w http.ResponseWriter, r *http.Request) // NOTE: appSlug is unused
 	GetPendingApp(w http.ResponseWriter, r *http.Request)
 	GetAvailableUpdates(w http.ResponseWriter, r *http.Request)
 
+	// Remote clusters
+	ListClusters(w http.ResponseWriter, r *http.Request)
+	RegisterCluster(w http.ResponseWriter, r *http.Request)
+	RemoveCluster(w http.ResponseWriter, r *http.Request)
+	DeployAppVersionToCluster(w http.ResponseWriter, r *http.Request)
+	GetAppClusterStatus(w http.ResponseWriter, r *http.Request)
+
+	// Promotion
+	ListPromotionStages(w http.ResponseWriter, r *http.Request)
+	SetPromotionStage(w http.ResponseWriter, r *http.Request)
+	DeletePromotionStage(w http.ResponseWriter, r *http.Request)
+	GetPromotionGates(w http.ResponseWriter, r *http.Request)
+	PromoteAppVersion(w http.ResponseWriter, r *http.Request)
+
 	// Airgap
 	AirgapBundleProgress(w http.ResponseWriter, r *http.Request)
 	AirgapBundleExists(w http.ResponseWriter, r *http.Request)
@@ -79,6 +100,7 @@ type KOTSHandler interface {
 	RedeployAppVersion(w http.ResponseWriter, r *http.Request)
 	GetAppRenderedContents(w http.ResponseWriter, r *http.Request)
 	GetAppContents(w http.ResponseWriter, r *http.Request)
+	GetAppVersionDiff(w http.ResponseWriter, r *http.Request)
 	GetAppDashboard(w http.ResponseWriter, r *http.Request)
 	GetDownstreamOutput(w http.ResponseWriter, r *http.Request)
 
@@ -88,8 +110,12 @@ type KOTSHandler interface {
 	UpdateAppRegistry(w http.ResponseWriter, r *http.Request)
 	GetAppRegistry(w http.ResponseWriter, r *http.Request)
 	ValidateAppRegistry(w http.ResponseWriter, r *http.Request)
+	GetAppImageVerificationPolicy(w http.ResponseWriter, r *http.Request)
+	UpdateAppImageVerificationPolicy(w http.ResponseWriter, r *http.Request)
 	GarbageCollectImages(w http.ResponseWriter, r *http.Request)
 
+	RotateEncryptionKey(w http.ResponseWriter, r *http.Request)
+
 	UpdateAppConfig(w http.ResponseWriter, r *http.Request)
 	CurrentAppConfig(w http.ResponseWriter, r *http.Request)
 	LiveAppConfig(w http.ResponseWriter, r *http.Request)
@@ -103,6 +129,9 @@ type KOTSHandler interface {
 	AppUpdateCheck(w http.ResponseWriter, r *http.Request)
 	SetAutomaticUpdatesConfig(w http.ResponseWriter, r *http.Request)
 	GetAutomaticUpdatesConfig(w http.ResponseWriter, r *http.Request)
+	GetMaintenanceWindows(w http.ResponseWriter, r *http.Request)
+	SetMaintenanceWindows(w http.ResponseWriter, r *http.Request)
+	CancelQueuedDeploy(w http.ResponseWriter, r *http.Request)
 	RemoveApp(w http.ResponseWriter, r *http.Request)
 
 	// App snapshot routes
@@ -122,11 +151,14 @@ type KOTSHandler interface {
 	GetInstanceSnapshotConfig(w http.ResponseWriter, r *http.Request)
 	SaveInstanceSnapshotSchedule(w http.ResponseWriter, r *http.Request)
 	SaveInstanceSnapshotRetention(w http.ResponseWriter, r *http.Request)
+	GetInstanceSnapshotVerificationPolicy(w http.ResponseWriter, r *http.Request)
+	SetInstanceSnapshotVerificationPolicy(w http.ResponseWriter, r *http.Request)
 	GetGlobalSnapshotSettings(w http.ResponseWriter, r *http.Request)
 	UpdateGlobalSnapshotSettings(w http.ResponseWriter, r *http.Request)
 	GetFileSystemSnapshotProviderInstructions(w http.ResponseWriter, r *http.Request)
 	GetBackup(w http.ResponseWriter, r *http.Request)
 	DeleteBackup(w http.ResponseWriter, r *http.Request)
+	VerifyInstanceBackup(w http.ResponseWriter, r *http.Request)
 	RestoreApps(w http.ResponseWriter, r *http.Request)
 	GetRestoreAppsStatus(w http.ResponseWriter, r *http.Request)
 	DownloadSnapshotLogs(w http.ResponseWriter, r *http.Request)
@@ -164,6 +196,17 @@ type KOTSHandler interface {
 	// Password change
 	ChangePassword(w http.ResponseWriter, r *http.Request)
 
+	// Audit log
+	ListAuditLog(w http.ResponseWriter, r *http.Request)
+
+	// Notifications
+	ListWebhookTargets(w http.ResponseWriter, r *http.Request)
+	CreateWebhookTarget(w http.ResponseWriter, r *http.Request)
+	UpdateWebhookTarget(w http.ResponseWriter, r *http.Request)
+	DeleteWebhookTarget(w http.ResponseWriter, r *http.Request)
+	ListWebhookDeliveries(w http.ResponseWriter, r *http.Request)
+	TestWebhookTarget(w http.ResponseWriter, r *http.Request)
+
 	// Debug info
 	GetDebugInfo(w http.ResponseWriter, r *http.Request)
 
@@ -173,4 +216,8 @@ type KOTSHandler interface {
 
 	// EC Websocket
 	ConnectToECWebsocket(w http.ResponseWriter, r *http.Request)
+
+	// Remote cluster agents
+	ConnectClusterAgent(w http.ResponseWriter, r *http.Request)
+	ConnectClusterAgentTunnel(w http.ResponseWriter, r *http.Request)
 }
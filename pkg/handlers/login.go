@@ -17,6 +17,7 @@ import (
 	ingress "github.com/replicatedhq/kots/pkg/ingress"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/rbac"
 	"github.com/replicatedhq/kots/pkg/session"
 	"github.com/replicatedhq/kots/pkg/store"
 	"github.com/replicatedhq/kots/pkg/user"
@@ -345,6 +346,7 @@ func (h *Handler) OIDCLoginCallback(w http.ResponseWriter, r *http.Request) {
 		groups = identityConfig.Spec.Groups
 	}
 	roles := session.GetSessionRolesFromRBAC(claims.Groups, groups)
+	roles = append(roles, rbac.GetUserRoleIDs(r.Context(), claims.Email, claims.Verified)...)
 
 	if len(roles) == 0 {
 		redirectURL = getRedirectOnErrorURL(redirectURL, "user must be a part of at least 1 group with roles")
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/handlers/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/maintenancewindow"
+	maintenancewindowtypes "github.com/replicatedhq/kots/pkg/maintenancewindow/types"
+	"github.com/replicatedhq/kots/pkg/store"
+)
+
+type GetMaintenanceWindowsResponse struct {
+	Windows []maintenancewindowtypes.MaintenanceWindow `json:"windows"`
+	IsOpen  bool                                       `json:"isOpen"`
+}
+
+type SetMaintenanceWindowsRequest struct {
+	Windows []maintenancewindowtypes.MaintenanceWindow `json:"windows"`
+}
+
+func (h *Handler) GetMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	windows, err := store.GetStore().GetAppMaintenanceWindows(foundApp.ID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get maintenance windows"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	isOpen, err := maintenancewindow.IsOpen(windows, time.Now())
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to check maintenance windows"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if windows == nil {
+		windows = []maintenancewindowtypes.MaintenanceWindow{}
+	}
+
+	JSON(w, http.StatusOK, GetMaintenanceWindowsResponse{
+		Windows: windows,
+		IsOpen:  isOpen,
+	})
+}
+
+// SetMaintenanceWindows replaces the maintenance windows of an app. An empty list removes all restrictions.
+func (h *Handler) SetMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	request := SetMaintenanceWindowsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error(errors.Wrap(err, "failed to decode request body"))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := maintenancewindow.Validate(request.Windows); err != nil {
+		JSON(w, http.StatusBadRequest, types.NewErrorResponse(err))
+		return
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err := store.GetStore().SetAppMaintenanceWindows(foundApp.ID, request.Windows); err != nil {
+		logger.Error(errors.Wrap(err, "failed to set maintenance windows"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CancelQueuedDeploy removes the deploy that is waiting for the next maintenance window
+func (h *Handler) CancelQueuedDeploy(w http.ResponseWriter, r *http.Request) {
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	downstreams, err := store.GetStore().ListDownstreamsForApp(foundApp.ID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list downstreams for app"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	} else if len(downstreams) == 0 {
+		logger.Error(errors.New("no downstreams for app"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err := store.GetStore().DeleteQueuedDeploy(foundApp.ID, downstreams[0].ClusterID); err != nil {
+		logger.Error(errors.Wrap(err, "failed to delete queued deploy"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
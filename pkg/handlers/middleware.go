@@ -3,10 +3,12 @@ package handlers
 import (
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
+	audittypes "github.com/replicatedhq/kots/pkg/audit/types"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/session"
 	"github.com/replicatedhq/kots/pkg/store"
@@ -26,6 +28,11 @@ func (lrw *loggingResponseWriter) WriteHeader(code int) {
 	lrw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap lets http.ResponseController flush streaming responses through the wrapper
+func (lrw *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return lrw.ResponseWriter
+}
+
 func CorsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if handleOptionsRequest(w, r) {
@@ -108,3 +115,67 @@ func RequireValidSessionQuietMiddleware(kotsStore store.Store) mux.MiddlewareFun
 		})
 	}
 }
+
+// AuditMiddleware records every mutating request in the audit log once the handler has returned.
+// It must run after RequireValidSessionMiddleware so that the session is available.
+func AuditMiddleware(kotsStore store.Store) mux.MiddlewareFunc {
+	return auditMiddleware(kotsStore, audittypes.CallerSession)
+}
+
+// TokenAuthAuditMiddleware audits requests to routes that are authenticated with the kots token instead of a session
+func TokenAuthAuditMiddleware(kotsStore store.Store) mux.MiddlewareFunc {
+	return auditMiddleware(kotsStore, audittypes.CallerToken)
+}
+
+func auditMiddleware(kotsStore store.Store, caller audittypes.Caller) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isAuditedMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			lrw := NewLoggingResponseWriter(w)
+			next.ServeHTTP(lrw, r)
+
+			entry := audittypes.AuditLogEntry{
+				CreatedAt: time.Now(),
+				Caller:    caller,
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Status:    lrw.StatusCode,
+				Outcome:   audittypes.OutcomeFromStatus(lrw.StatusCode),
+			}
+
+			if route := mux.CurrentRoute(r); route != nil {
+				entry.Action = route.GetName()
+			}
+			if entry.Action == "" {
+				entry.Action = r.Method + " " + r.URL.Path
+			}
+
+			if sess := session.ContextGetSession(r); sess != nil {
+				entry.SessionID = sess.ID
+				entry.Roles = sess.Roles
+			}
+
+			vars := mux.Vars(r)
+			entry.AppSlug = vars["appSlug"]
+			if sequence, err := strconv.ParseInt(vars["sequence"], 10, 64); err == nil {
+				entry.Sequence = &sequence
+			}
+
+			if err := kotsStore.CreateAuditLogEntry(entry); err != nil {
+				logger.Error(errors.Wrapf(err, "failed to create audit log entry for request %q", r.RequestURI))
+			}
+		})
+	}
+}
+
+func isAuditedMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	audittypes "github.com/replicatedhq/kots/pkg/audit/types"
+	"github.com/replicatedhq/kots/pkg/session"
+	"github.com/replicatedhq/kots/pkg/session/types"
+	mock_store "github.com/replicatedhq/kots/pkg/store/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AuditMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		status     int
+		wantAudit  bool
+		wantResult audittypes.Outcome
+	}{
+		{
+			name:      "reads are not audited",
+			method:    http.MethodGet,
+			status:    http.StatusOK,
+			wantAudit: false,
+		},
+		{
+			name:       "successful write",
+			method:     http.MethodPost,
+			status:     http.StatusOK,
+			wantAudit:  true,
+			wantResult: audittypes.OutcomeSuccess,
+		},
+		{
+			name:       "denied write",
+			method:     http.MethodPut,
+			status:     http.StatusForbidden,
+			wantAudit:  true,
+			wantResult: audittypes.OutcomeDenied,
+		},
+		{
+			name:       "failed write",
+			method:     http.MethodDelete,
+			status:     http.StatusInternalServerError,
+			wantAudit:  true,
+			wantResult: audittypes.OutcomeFailure,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockStore := mock_store.NewMockStore(ctrl)
+
+			var got *audittypes.AuditLogEntry
+			if tt.wantAudit {
+				mockStore.EXPECT().CreateAuditLogEntry(gomock.Any()).DoAndReturn(func(entry audittypes.AuditLogEntry) error {
+					got = &entry
+					return nil
+				})
+			}
+
+			sess := &types.Session{ID: "session-id", Roles: []string{"cluster-admin"}}
+
+			r := mux.NewRouter()
+			r.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					next.ServeHTTP(w, session.ContextSetSession(r, sess))
+				})
+			}, AuditMiddleware(mockStore))
+			r.Name("DeployAppVersion").Path("/api/v1/app/{appSlug}/sequence/{sequence}/deploy").
+				HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(tt.status)
+				})
+
+			req := httptest.NewRequest(tt.method, "/api/v1/app/my-app/sequence/3/deploy", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if !tt.wantAudit {
+				assert.Nil(t, got)
+				return
+			}
+
+			require.NotNil(t, got)
+			require.NotNil(t, got.Sequence)
+			assert.Equal(t, int64(3), *got.Sequence)
+			assert.Equal(t, "DeployAppVersion", got.Action)
+			assert.Equal(t, "my-app", got.AppSlug)
+			assert.Equal(t, audittypes.CallerSession, got.Caller)
+			assert.Equal(t, "session-id", got.SessionID)
+			assert.Equal(t, []string{"cluster-admin"}, got.Roles)
+			assert.Equal(t, tt.method, got.Method)
+			assert.Equal(t, tt.status, got.Status)
+			assert.Equal(t, tt.wantResult, got.Outcome)
+		})
+	}
+}
+
+func Test_TokenAuthAuditMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mock_store.NewMockStore(ctrl)
+
+	var got *audittypes.AuditLogEntry
+	mockStore.EXPECT().CreateAuditLogEntry(gomock.Any()).DoAndReturn(func(entry audittypes.AuditLogEntry) error {
+		got = &entry
+		return nil
+	})
+
+	r := mux.NewRouter()
+	r.Name("UploadExistingApp").Path("/api/v1/upload").Methods("PUT").
+		Handler(TokenAuthAuditMiddleware(mockStore)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})))
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/upload", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.NotNil(t, got)
+	assert.Equal(t, audittypes.CallerToken, got.Caller)
+	assert.Equal(t, "UploadExistingApp", got.Action)
+	assert.Empty(t, got.SessionID)
+	assert.Equal(t, audittypes.OutcomeSuccess, got.Outcome)
+}
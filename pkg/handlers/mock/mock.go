@@ -82,6 +82,18 @@ func (mr *MockKOTSHandlerMockRecorder) CanInstallAppVersion(w, r interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CanInstallAppVersion", reflect.TypeOf((*MockKOTSHandler)(nil).CanInstallAppVersion), w, r)
 }
 
+// CancelQueuedDeploy mocks base method.
+func (m *MockKOTSHandler) CancelQueuedDeploy(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CancelQueuedDeploy", w, r)
+}
+
+// CancelQueuedDeploy indicates an expected call of CancelQueuedDeploy.
+func (mr *MockKOTSHandlerMockRecorder) CancelQueuedDeploy(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelQueuedDeploy", reflect.TypeOf((*MockKOTSHandler)(nil).CancelQueuedDeploy), w, r)
+}
+
 // CancelRestore mocks base method.
 func (m *MockKOTSHandler) CancelRestore(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -178,6 +190,30 @@ func (mr *MockKOTSHandlerMockRecorder) ConfirmEmbeddedClusterManagement(w, r int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmbeddedClusterManagement", reflect.TypeOf((*MockKOTSHandler)(nil).ConfirmEmbeddedClusterManagement), w, r)
 }
 
+// ConnectClusterAgent mocks base method.
+func (m *MockKOTSHandler) ConnectClusterAgent(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ConnectClusterAgent", w, r)
+}
+
+// ConnectClusterAgent indicates an expected call of ConnectClusterAgent.
+func (mr *MockKOTSHandlerMockRecorder) ConnectClusterAgent(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectClusterAgent", reflect.TypeOf((*MockKOTSHandler)(nil).ConnectClusterAgent), w, r)
+}
+
+// ConnectClusterAgentTunnel mocks base method.
+func (m *MockKOTSHandler) ConnectClusterAgentTunnel(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ConnectClusterAgentTunnel", w, r)
+}
+
+// ConnectClusterAgentTunnel indicates an expected call of ConnectClusterAgentTunnel.
+func (mr *MockKOTSHandlerMockRecorder) ConnectClusterAgentTunnel(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectClusterAgentTunnel", reflect.TypeOf((*MockKOTSHandler)(nil).ConnectClusterAgentTunnel), w, r)
+}
+
 // ConnectToECWebsocket mocks base method.
 func (m *MockKOTSHandler) ConnectToECWebsocket(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -250,6 +286,18 @@ func (mr *MockKOTSHandlerMockRecorder) CreateInstanceBackup(w, r interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInstanceBackup", reflect.TypeOf((*MockKOTSHandler)(nil).CreateInstanceBackup), w, r)
 }
 
+// CreateWebhookTarget mocks base method.
+func (m *MockKOTSHandler) CreateWebhookTarget(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CreateWebhookTarget", w, r)
+}
+
+// CreateWebhookTarget indicates an expected call of CreateWebhookTarget.
+func (mr *MockKOTSHandlerMockRecorder) CreateWebhookTarget(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookTarget", reflect.TypeOf((*MockKOTSHandler)(nil).CreateWebhookTarget), w, r)
+}
+
 // CurrentAppConfig mocks base method.
 func (m *MockKOTSHandler) CurrentAppConfig(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -298,6 +346,18 @@ func (mr *MockKOTSHandlerMockRecorder) DeleteKurlNode(w, r interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKurlNode", reflect.TypeOf((*MockKOTSHandler)(nil).DeleteKurlNode), w, r)
 }
 
+// DeletePromotionStage mocks base method.
+func (m *MockKOTSHandler) DeletePromotionStage(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeletePromotionStage", w, r)
+}
+
+// DeletePromotionStage indicates an expected call of DeletePromotionStage.
+func (mr *MockKOTSHandlerMockRecorder) DeletePromotionStage(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePromotionStage", reflect.TypeOf((*MockKOTSHandler)(nil).DeletePromotionStage), w, r)
+}
+
 // DeleteRedact mocks base method.
 func (m *MockKOTSHandler) DeleteRedact(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -322,6 +382,18 @@ func (mr *MockKOTSHandlerMockRecorder) DeleteSupportBundle(w, r interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSupportBundle", reflect.TypeOf((*MockKOTSHandler)(nil).DeleteSupportBundle), w, r)
 }
 
+// DeleteWebhookTarget mocks base method.
+func (m *MockKOTSHandler) DeleteWebhookTarget(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteWebhookTarget", w, r)
+}
+
+// DeleteWebhookTarget indicates an expected call of DeleteWebhookTarget.
+func (mr *MockKOTSHandlerMockRecorder) DeleteWebhookTarget(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookTarget", reflect.TypeOf((*MockKOTSHandler)(nil).DeleteWebhookTarget), w, r)
+}
+
 // DeployAppVersion mocks base method.
 func (m *MockKOTSHandler) DeployAppVersion(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -334,6 +406,18 @@ func (mr *MockKOTSHandlerMockRecorder) DeployAppVersion(w, r interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployAppVersion", reflect.TypeOf((*MockKOTSHandler)(nil).DeployAppVersion), w, r)
 }
 
+// DeployAppVersionToCluster mocks base method.
+func (m *MockKOTSHandler) DeployAppVersionToCluster(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeployAppVersionToCluster", w, r)
+}
+
+// DeployAppVersionToCluster indicates an expected call of DeployAppVersionToCluster.
+func (mr *MockKOTSHandlerMockRecorder) DeployAppVersionToCluster(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployAppVersionToCluster", reflect.TypeOf((*MockKOTSHandler)(nil).DeployAppVersionToCluster), w, r)
+}
+
 // DisableAppGitOps mocks base method.
 func (m *MockKOTSHandler) DisableAppGitOps(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -562,6 +646,18 @@ func (mr *MockKOTSHandlerMockRecorder) GetApp(w, r interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApp", reflect.TypeOf((*MockKOTSHandler)(nil).GetApp), w, r)
 }
 
+// GetAppClusterStatus mocks base method.
+func (m *MockKOTSHandler) GetAppClusterStatus(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetAppClusterStatus", w, r)
+}
+
+// GetAppClusterStatus indicates an expected call of GetAppClusterStatus.
+func (mr *MockKOTSHandlerMockRecorder) GetAppClusterStatus(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppClusterStatus", reflect.TypeOf((*MockKOTSHandler)(nil).GetAppClusterStatus), w, r)
+}
+
 // GetAppContents mocks base method.
 func (m *MockKOTSHandler) GetAppContents(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -586,6 +682,18 @@ func (mr *MockKOTSHandlerMockRecorder) GetAppDashboard(w, r interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppDashboard", reflect.TypeOf((*MockKOTSHandler)(nil).GetAppDashboard), w, r)
 }
 
+// GetAppDrift mocks base method.
+func (m *MockKOTSHandler) GetAppDrift(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetAppDrift", w, r)
+}
+
+// GetAppDrift indicates an expected call of GetAppDrift.
+func (mr *MockKOTSHandlerMockRecorder) GetAppDrift(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppDrift", reflect.TypeOf((*MockKOTSHandler)(nil).GetAppDrift), w, r)
+}
+
 // GetAppIdentityServiceConfig mocks base method.
 func (m *MockKOTSHandler) GetAppIdentityServiceConfig(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -598,6 +706,18 @@ func (mr *MockKOTSHandlerMockRecorder) GetAppIdentityServiceConfig(w, r interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppIdentityServiceConfig", reflect.TypeOf((*MockKOTSHandler)(nil).GetAppIdentityServiceConfig), w, r)
 }
 
+// GetAppImageVerificationPolicy mocks base method.
+func (m *MockKOTSHandler) GetAppImageVerificationPolicy(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetAppImageVerificationPolicy", w, r)
+}
+
+// GetAppImageVerificationPolicy indicates an expected call of GetAppImageVerificationPolicy.
+func (mr *MockKOTSHandlerMockRecorder) GetAppImageVerificationPolicy(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppImageVerificationPolicy", reflect.TypeOf((*MockKOTSHandler)(nil).GetAppImageVerificationPolicy), w, r)
+}
+
 // GetAppRegistry mocks base method.
 func (m *MockKOTSHandler) GetAppRegistry(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -634,6 +754,42 @@ func (mr *MockKOTSHandlerMockRecorder) GetAppStatus(w, r interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppStatus", reflect.TypeOf((*MockKOTSHandler)(nil).GetAppStatus), w, r)
 }
 
+// GetAppStatusHistory mocks base method.
+func (m *MockKOTSHandler) GetAppStatusHistory(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetAppStatusHistory", w, r)
+}
+
+// GetAppStatusHistory indicates an expected call of GetAppStatusHistory.
+func (mr *MockKOTSHandlerMockRecorder) GetAppStatusHistory(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppStatusHistory", reflect.TypeOf((*MockKOTSHandler)(nil).GetAppStatusHistory), w, r)
+}
+
+// GetAppUptime mocks base method.
+func (m *MockKOTSHandler) GetAppUptime(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetAppUptime", w, r)
+}
+
+// GetAppUptime indicates an expected call of GetAppUptime.
+func (mr *MockKOTSHandlerMockRecorder) GetAppUptime(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppUptime", reflect.TypeOf((*MockKOTSHandler)(nil).GetAppUptime), w, r)
+}
+
+// GetAppVersionDiff mocks base method.
+func (m *MockKOTSHandler) GetAppVersionDiff(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetAppVersionDiff", w, r)
+}
+
+// GetAppVersionDiff indicates an expected call of GetAppVersionDiff.
+func (mr *MockKOTSHandlerMockRecorder) GetAppVersionDiff(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppVersionDiff", reflect.TypeOf((*MockKOTSHandler)(nil).GetAppVersionDiff), w, r)
+}
+
 // GetAppVersionDownloadStatus mocks base method.
 func (m *MockKOTSHandler) GetAppVersionDownloadStatus(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -838,6 +994,18 @@ func (mr *MockKOTSHandlerMockRecorder) GetInstanceSnapshotConfig(w, r interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstanceSnapshotConfig", reflect.TypeOf((*MockKOTSHandler)(nil).GetInstanceSnapshotConfig), w, r)
 }
 
+// GetInstanceSnapshotVerificationPolicy mocks base method.
+func (m *MockKOTSHandler) GetInstanceSnapshotVerificationPolicy(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetInstanceSnapshotVerificationPolicy", w, r)
+}
+
+// GetInstanceSnapshotVerificationPolicy indicates an expected call of GetInstanceSnapshotVerificationPolicy.
+func (mr *MockKOTSHandlerMockRecorder) GetInstanceSnapshotVerificationPolicy(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstanceSnapshotVerificationPolicy", reflect.TypeOf((*MockKOTSHandler)(nil).GetInstanceSnapshotVerificationPolicy), w, r)
+}
+
 // GetKotsadmRegistry mocks base method.
 func (m *MockKOTSHandler) GetKotsadmRegistry(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -898,6 +1066,18 @@ func (mr *MockKOTSHandlerMockRecorder) GetLicense(w, r interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLicense", reflect.TypeOf((*MockKOTSHandler)(nil).GetLicense), w, r)
 }
 
+// GetMaintenanceWindows mocks base method.
+func (m *MockKOTSHandler) GetMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetMaintenanceWindows", w, r)
+}
+
+// GetMaintenanceWindows indicates an expected call of GetMaintenanceWindows.
+func (mr *MockKOTSHandlerMockRecorder) GetMaintenanceWindows(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaintenanceWindows", reflect.TypeOf((*MockKOTSHandler)(nil).GetMaintenanceWindows), w, r)
+}
+
 // GetOnlineInstallStatus mocks base method.
 func (m *MockKOTSHandler) GetOnlineInstallStatus(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -958,6 +1138,18 @@ func (mr *MockKOTSHandlerMockRecorder) GetPreflightResult(w, r interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreflightResult", reflect.TypeOf((*MockKOTSHandler)(nil).GetPreflightResult), w, r)
 }
 
+// GetPromotionGates mocks base method.
+func (m *MockKOTSHandler) GetPromotionGates(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetPromotionGates", w, r)
+}
+
+// GetPromotionGates indicates an expected call of GetPromotionGates.
+func (mr *MockKOTSHandlerMockRecorder) GetPromotionGates(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromotionGates", reflect.TypeOf((*MockKOTSHandler)(nil).GetPromotionGates), w, r)
+}
+
 // GetRedact mocks base method.
 func (m *MockKOTSHandler) GetRedact(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1066,6 +1258,18 @@ func (mr *MockKOTSHandlerMockRecorder) GetSupportBundleFiles(w, r interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSupportBundleFiles", reflect.TypeOf((*MockKOTSHandler)(nil).GetSupportBundleFiles), w, r)
 }
 
+// GetSupportBundlePolicy mocks base method.
+func (m *MockKOTSHandler) GetSupportBundlePolicy(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetSupportBundlePolicy", w, r)
+}
+
+// GetSupportBundlePolicy indicates an expected call of GetSupportBundlePolicy.
+func (mr *MockKOTSHandlerMockRecorder) GetSupportBundlePolicy(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSupportBundlePolicy", reflect.TypeOf((*MockKOTSHandler)(nil).GetSupportBundlePolicy), w, r)
+}
+
 // GetSupportBundleRedactions mocks base method.
 func (m *MockKOTSHandler) GetSupportBundleRedactions(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1150,6 +1354,18 @@ func (mr *MockKOTSHandlerMockRecorder) ListApps(w, r interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListApps", reflect.TypeOf((*MockKOTSHandler)(nil).ListApps), w, r)
 }
 
+// ListAuditLog mocks base method.
+func (m *MockKOTSHandler) ListAuditLog(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListAuditLog", w, r)
+}
+
+// ListAuditLog indicates an expected call of ListAuditLog.
+func (mr *MockKOTSHandlerMockRecorder) ListAuditLog(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditLog", reflect.TypeOf((*MockKOTSHandler)(nil).ListAuditLog), w, r)
+}
+
 // ListBackups mocks base method.
 func (m *MockKOTSHandler) ListBackups(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1162,6 +1378,18 @@ func (mr *MockKOTSHandlerMockRecorder) ListBackups(w, r interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBackups", reflect.TypeOf((*MockKOTSHandler)(nil).ListBackups), w, r)
 }
 
+// ListClusters mocks base method.
+func (m *MockKOTSHandler) ListClusters(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListClusters", w, r)
+}
+
+// ListClusters indicates an expected call of ListClusters.
+func (mr *MockKOTSHandlerMockRecorder) ListClusters(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusters", reflect.TypeOf((*MockKOTSHandler)(nil).ListClusters), w, r)
+}
+
 // ListInstanceBackups mocks base method.
 func (m *MockKOTSHandler) ListInstanceBackups(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1174,6 +1402,18 @@ func (mr *MockKOTSHandlerMockRecorder) ListInstanceBackups(w, r interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInstanceBackups", reflect.TypeOf((*MockKOTSHandler)(nil).ListInstanceBackups), w, r)
 }
 
+// ListPromotionStages mocks base method.
+func (m *MockKOTSHandler) ListPromotionStages(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListPromotionStages", w, r)
+}
+
+// ListPromotionStages indicates an expected call of ListPromotionStages.
+func (mr *MockKOTSHandlerMockRecorder) ListPromotionStages(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromotionStages", reflect.TypeOf((*MockKOTSHandler)(nil).ListPromotionStages), w, r)
+}
+
 // ListRedactors mocks base method.
 func (m *MockKOTSHandler) ListRedactors(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1198,6 +1438,30 @@ func (mr *MockKOTSHandlerMockRecorder) ListSupportBundles(w, r interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSupportBundles", reflect.TypeOf((*MockKOTSHandler)(nil).ListSupportBundles), w, r)
 }
 
+// ListWebhookDeliveries mocks base method.
+func (m *MockKOTSHandler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListWebhookDeliveries", w, r)
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockKOTSHandlerMockRecorder) ListWebhookDeliveries(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockKOTSHandler)(nil).ListWebhookDeliveries), w, r)
+}
+
+// ListWebhookTargets mocks base method.
+func (m *MockKOTSHandler) ListWebhookTargets(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListWebhookTargets", w, r)
+}
+
+// ListWebhookTargets indicates an expected call of ListWebhookTargets.
+func (mr *MockKOTSHandlerMockRecorder) ListWebhookTargets(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookTargets", reflect.TypeOf((*MockKOTSHandler)(nil).ListWebhookTargets), w, r)
+}
+
 // LiveAppConfig mocks base method.
 func (m *MockKOTSHandler) LiveAppConfig(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1234,6 +1498,18 @@ func (mr *MockKOTSHandlerMockRecorder) PreflightsReports(w, r interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreflightsReports", reflect.TypeOf((*MockKOTSHandler)(nil).PreflightsReports), w, r)
 }
 
+// PromoteAppVersion mocks base method.
+func (m *MockKOTSHandler) PromoteAppVersion(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PromoteAppVersion", w, r)
+}
+
+// PromoteAppVersion indicates an expected call of PromoteAppVersion.
+func (mr *MockKOTSHandlerMockRecorder) PromoteAppVersion(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoteAppVersion", reflect.TypeOf((*MockKOTSHandler)(nil).PromoteAppVersion), w, r)
+}
+
 // RedeployAppVersion mocks base method.
 func (m *MockKOTSHandler) RedeployAppVersion(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1246,6 +1522,18 @@ func (mr *MockKOTSHandlerMockRecorder) RedeployAppVersion(w, r interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeployAppVersion", reflect.TypeOf((*MockKOTSHandler)(nil).RedeployAppVersion), w, r)
 }
 
+// RegisterCluster mocks base method.
+func (m *MockKOTSHandler) RegisterCluster(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RegisterCluster", w, r)
+}
+
+// RegisterCluster indicates an expected call of RegisterCluster.
+func (mr *MockKOTSHandlerMockRecorder) RegisterCluster(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterCluster", reflect.TypeOf((*MockKOTSHandler)(nil).RegisterCluster), w, r)
+}
+
 // RemoveApp mocks base method.
 func (m *MockKOTSHandler) RemoveApp(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1258,6 +1546,18 @@ func (mr *MockKOTSHandlerMockRecorder) RemoveApp(w, r interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveApp", reflect.TypeOf((*MockKOTSHandler)(nil).RemoveApp), w, r)
 }
 
+// RemoveCluster mocks base method.
+func (m *MockKOTSHandler) RemoveCluster(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RemoveCluster", w, r)
+}
+
+// RemoveCluster indicates an expected call of RemoveCluster.
+func (mr *MockKOTSHandlerMockRecorder) RemoveCluster(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCluster", reflect.TypeOf((*MockKOTSHandler)(nil).RemoveCluster), w, r)
+}
+
 // ResetAirgapInstallStatus mocks base method.
 func (m *MockKOTSHandler) ResetAirgapInstallStatus(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1306,6 +1606,18 @@ func (mr *MockKOTSHandlerMockRecorder) ResumeInstallOnline(w, r interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeInstallOnline", reflect.TypeOf((*MockKOTSHandler)(nil).ResumeInstallOnline), w, r)
 }
 
+// RotateEncryptionKey mocks base method.
+func (m *MockKOTSHandler) RotateEncryptionKey(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RotateEncryptionKey", w, r)
+}
+
+// RotateEncryptionKey indicates an expected call of RotateEncryptionKey.
+func (mr *MockKOTSHandlerMockRecorder) RotateEncryptionKey(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateEncryptionKey", reflect.TypeOf((*MockKOTSHandler)(nil).RotateEncryptionKey), w, r)
+}
+
 // SaveInstanceSnapshotRetention mocks base method.
 func (m *MockKOTSHandler) SaveInstanceSnapshotRetention(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1378,6 +1690,30 @@ func (mr *MockKOTSHandlerMockRecorder) SetAutomaticUpdatesConfig(w, r interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAutomaticUpdatesConfig", reflect.TypeOf((*MockKOTSHandler)(nil).SetAutomaticUpdatesConfig), w, r)
 }
 
+// SetInstanceSnapshotVerificationPolicy mocks base method.
+func (m *MockKOTSHandler) SetInstanceSnapshotVerificationPolicy(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetInstanceSnapshotVerificationPolicy", w, r)
+}
+
+// SetInstanceSnapshotVerificationPolicy indicates an expected call of SetInstanceSnapshotVerificationPolicy.
+func (mr *MockKOTSHandlerMockRecorder) SetInstanceSnapshotVerificationPolicy(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotVerificationPolicy", reflect.TypeOf((*MockKOTSHandler)(nil).SetInstanceSnapshotVerificationPolicy), w, r)
+}
+
+// SetMaintenanceWindows mocks base method.
+func (m *MockKOTSHandler) SetMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetMaintenanceWindows", w, r)
+}
+
+// SetMaintenanceWindows indicates an expected call of SetMaintenanceWindows.
+func (mr *MockKOTSHandlerMockRecorder) SetMaintenanceWindows(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaintenanceWindows", reflect.TypeOf((*MockKOTSHandler)(nil).SetMaintenanceWindows), w, r)
+}
+
 // SetPrometheusAddress mocks base method.
 func (m *MockKOTSHandler) SetPrometheusAddress(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1390,6 +1726,18 @@ func (mr *MockKOTSHandlerMockRecorder) SetPrometheusAddress(w, r interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPrometheusAddress", reflect.TypeOf((*MockKOTSHandler)(nil).SetPrometheusAddress), w, r)
 }
 
+// SetPromotionStage mocks base method.
+func (m *MockKOTSHandler) SetPromotionStage(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPromotionStage", w, r)
+}
+
+// SetPromotionStage indicates an expected call of SetPromotionStage.
+func (mr *MockKOTSHandlerMockRecorder) SetPromotionStage(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPromotionStage", reflect.TypeOf((*MockKOTSHandler)(nil).SetPromotionStage), w, r)
+}
+
 // SetRedactEnabled mocks base method.
 func (m *MockKOTSHandler) SetRedactEnabled(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1414,6 +1762,18 @@ func (mr *MockKOTSHandlerMockRecorder) SetRedactMetadataAndYaml(w, r interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRedactMetadataAndYaml", reflect.TypeOf((*MockKOTSHandler)(nil).SetRedactMetadataAndYaml), w, r)
 }
 
+// SetSupportBundlePolicy mocks base method.
+func (m *MockKOTSHandler) SetSupportBundlePolicy(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetSupportBundlePolicy", w, r)
+}
+
+// SetSupportBundlePolicy indicates an expected call of SetSupportBundlePolicy.
+func (mr *MockKOTSHandlerMockRecorder) SetSupportBundlePolicy(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSupportBundlePolicy", reflect.TypeOf((*MockKOTSHandler)(nil).SetSupportBundlePolicy), w, r)
+}
+
 // ShareSupportBundle mocks base method.
 func (m *MockKOTSHandler) ShareSupportBundle(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1450,6 +1810,18 @@ func (mr *MockKOTSHandlerMockRecorder) StartUpgradeService(w, r interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartUpgradeService", reflect.TypeOf((*MockKOTSHandler)(nil).StartUpgradeService), w, r)
 }
 
+// StreamAppEvents mocks base method.
+func (m *MockKOTSHandler) StreamAppEvents(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StreamAppEvents", w, r)
+}
+
+// StreamAppEvents indicates an expected call of StreamAppEvents.
+func (mr *MockKOTSHandlerMockRecorder) StreamAppEvents(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamAppEvents", reflect.TypeOf((*MockKOTSHandler)(nil).StreamAppEvents), w, r)
+}
+
 // SyncLicense mocks base method.
 func (m *MockKOTSHandler) SyncLicense(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1462,6 +1834,18 @@ func (mr *MockKOTSHandlerMockRecorder) SyncLicense(w, r interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncLicense", reflect.TypeOf((*MockKOTSHandler)(nil).SyncLicense), w, r)
 }
 
+// TestWebhookTarget mocks base method.
+func (m *MockKOTSHandler) TestWebhookTarget(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "TestWebhookTarget", w, r)
+}
+
+// TestWebhookTarget indicates an expected call of TestWebhookTarget.
+func (mr *MockKOTSHandlerMockRecorder) TestWebhookTarget(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TestWebhookTarget", reflect.TypeOf((*MockKOTSHandler)(nil).TestWebhookTarget), w, r)
+}
+
 // UpdateAdminConsole mocks base method.
 func (m *MockKOTSHandler) UpdateAdminConsole(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1486,6 +1870,18 @@ func (mr *MockKOTSHandlerMockRecorder) UpdateAppConfig(w, r interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppConfig", reflect.TypeOf((*MockKOTSHandler)(nil).UpdateAppConfig), w, r)
 }
 
+// UpdateAppDriftSettings mocks base method.
+func (m *MockKOTSHandler) UpdateAppDriftSettings(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateAppDriftSettings", w, r)
+}
+
+// UpdateAppDriftSettings indicates an expected call of UpdateAppDriftSettings.
+func (mr *MockKOTSHandlerMockRecorder) UpdateAppDriftSettings(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppDriftSettings", reflect.TypeOf((*MockKOTSHandler)(nil).UpdateAppDriftSettings), w, r)
+}
+
 // UpdateAppFromAirgap mocks base method.
 func (m *MockKOTSHandler) UpdateAppFromAirgap(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1510,6 +1906,18 @@ func (mr *MockKOTSHandlerMockRecorder) UpdateAppGitOps(w, r interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppGitOps", reflect.TypeOf((*MockKOTSHandler)(nil).UpdateAppGitOps), w, r)
 }
 
+// UpdateAppImageVerificationPolicy mocks base method.
+func (m *MockKOTSHandler) UpdateAppImageVerificationPolicy(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateAppImageVerificationPolicy", w, r)
+}
+
+// UpdateAppImageVerificationPolicy indicates an expected call of UpdateAppImageVerificationPolicy.
+func (mr *MockKOTSHandlerMockRecorder) UpdateAppImageVerificationPolicy(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppImageVerificationPolicy", reflect.TypeOf((*MockKOTSHandler)(nil).UpdateAppImageVerificationPolicy), w, r)
+}
+
 // UpdateAppRegistry mocks base method.
 func (m *MockKOTSHandler) UpdateAppRegistry(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1546,6 +1954,18 @@ func (mr *MockKOTSHandlerMockRecorder) UpdateRedact(w, r interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRedact", reflect.TypeOf((*MockKOTSHandler)(nil).UpdateRedact), w, r)
 }
 
+// UpdateWebhookTarget mocks base method.
+func (m *MockKOTSHandler) UpdateWebhookTarget(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateWebhookTarget", w, r)
+}
+
+// UpdateWebhookTarget indicates an expected call of UpdateWebhookTarget.
+func (mr *MockKOTSHandlerMockRecorder) UpdateWebhookTarget(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookTarget", reflect.TypeOf((*MockKOTSHandler)(nil).UpdateWebhookTarget), w, r)
+}
+
 // UploadAirgapBundleChunk mocks base method.
 func (m *MockKOTSHandler) UploadAirgapBundleChunk(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -1593,3 +2013,15 @@ func (mr *MockKOTSHandlerMockRecorder) ValidateAppRegistry(w, r interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateAppRegistry", reflect.TypeOf((*MockKOTSHandler)(nil).ValidateAppRegistry), w, r)
 }
+
+// VerifyInstanceBackup mocks base method.
+func (m *MockKOTSHandler) VerifyInstanceBackup(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "VerifyInstanceBackup", w, r)
+}
+
+// VerifyInstanceBackup indicates an expected call of VerifyInstanceBackup.
+func (mr *MockKOTSHandlerMockRecorder) VerifyInstanceBackup(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyInstanceBackup", reflect.TypeOf((*MockKOTSHandler)(nil).VerifyInstanceBackup), w, r)
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/handlers/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/notifications"
+	notificationstypes "github.com/replicatedhq/kots/pkg/notifications/types"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/segmentio/ksuid"
+)
+
+type WebhookTargetResponse struct {
+	notificationstypes.WebhookTarget `json:",inline"`
+	HasSecret                        bool `json:"hasSecret"`
+}
+
+type ListWebhookTargetsResponse struct {
+	Webhooks []WebhookTargetResponse `json:"webhooks"`
+}
+
+type CreateWebhookTargetRequest struct {
+	Name    string                         `json:"name"`
+	URL     string                         `json:"url"`
+	Secret  string                         `json:"secret"`
+	Enabled bool                           `json:"enabled"`
+	Events  []notificationstypes.EventType `json:"events"`
+}
+
+// UpdateWebhookTargetRequest leaves the secret unchanged when it's empty
+type UpdateWebhookTargetRequest = CreateWebhookTargetRequest
+
+type ListWebhookDeliveriesResponse struct {
+	notificationstypes.WebhookDeliveries `json:",inline"`
+}
+
+type TestWebhookTargetResponse struct {
+	Delivery notificationstypes.WebhookDelivery `json:"delivery"`
+}
+
+func (h *Handler) ListWebhookTargets(w http.ResponseWriter, r *http.Request) {
+	targets, err := store.GetStore().ListWebhookTargets()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list webhook targets"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response := ListWebhookTargetsResponse{
+		Webhooks: []WebhookTargetResponse{},
+	}
+	for _, target := range targets {
+		response.Webhooks = append(response.Webhooks, webhookTargetResponse(target))
+	}
+
+	JSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) CreateWebhookTarget(w http.ResponseWriter, r *http.Request) {
+	request := CreateWebhookTargetRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error(errors.Wrap(err, "failed to decode request body"))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := validateWebhookTargetRequest(request); err != nil {
+		JSON(w, http.StatusBadRequest, types.NewErrorResponse(err))
+		return
+	}
+
+	target, err := store.GetStore().CreateWebhookTarget(notificationstypes.WebhookTarget{
+		Name:    request.Name,
+		URL:     request.URL,
+		Secret:  request.Secret,
+		Enabled: request.Enabled,
+		Events:  request.Events,
+	})
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to create webhook target"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	JSON(w, http.StatusCreated, webhookTargetResponse(*target))
+}
+
+func (h *Handler) UpdateWebhookTarget(w http.ResponseWriter, r *http.Request) {
+	webhookID := mux.Vars(r)["webhookId"]
+
+	request := UpdateWebhookTargetRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error(errors.Wrap(err, "failed to decode request body"))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := validateWebhookTargetRequest(request); err != nil {
+		JSON(w, http.StatusBadRequest, types.NewErrorResponse(err))
+		return
+	}
+
+	err := store.GetStore().UpdateWebhookTarget(notificationstypes.WebhookTarget{
+		ID:      webhookID,
+		Name:    request.Name,
+		URL:     request.URL,
+		Secret:  request.Secret,
+		Enabled: request.Enabled,
+		Events:  request.Events,
+	})
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to update webhook target"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	target, err := store.GetStore().GetWebhookTarget(webhookID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get webhook target"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	JSON(w, http.StatusOK, webhookTargetResponse(*target))
+}
+
+func (h *Handler) DeleteWebhookTarget(w http.ResponseWriter, r *http.Request) {
+	webhookID := mux.Vars(r)["webhookId"]
+
+	if err := store.GetStore().DeleteWebhookTarget(webhookID); err != nil {
+		logger.Error(errors.Wrap(err, "failed to delete webhook target"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID := mux.Vars(r)["webhookId"]
+
+	pageSize := 20
+	currentPage := 0
+
+	if val := r.URL.Query().Get("pageSize"); val != "" {
+		ps, err := strconv.Atoi(val)
+		if err != nil {
+			err = errors.Wrap(err, "failed to parse page size")
+			logger.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		pageSize = ps
+	}
+	if val := r.URL.Query().Get("currentPage"); val != "" {
+		cp, err := strconv.Atoi(val)
+		if err != nil {
+			err = errors.Wrap(err, "failed to parse current page")
+			logger.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		currentPage = cp
+	}
+
+	deliveries, err := store.GetStore().ListWebhookDeliveries(webhookID, currentPage, pageSize)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list webhook deliveries"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	JSON(w, http.StatusOK, ListWebhookDeliveriesResponse{WebhookDeliveries: *deliveries})
+}
+
+// TestWebhookTarget synchronously sends a single webhook.test event to a target without retries.
+func (h *Handler) TestWebhookTarget(w http.ResponseWriter, r *http.Request) {
+	webhookID := mux.Vars(r)["webhookId"]
+
+	target, err := store.GetStore().GetWebhookTarget(webhookID)
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to get webhook target"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// send the test event even if the target is disabled
+	target.Enabled = true
+
+	sender := notifications.NewWebhookSender()
+	sender.MaxAttempts = 1
+
+	event := notificationstypes.Event{
+		ID:   ksuid.New().String(),
+		Type: notificationstypes.EventWebhookTest,
+	}
+	delivery := sender.Send(*target, event, store.GetStore().CreateWebhookDelivery)
+
+	JSON(w, http.StatusOK, TestWebhookTargetResponse{Delivery: delivery})
+}
+
+func validateWebhookTargetRequest(request CreateWebhookTargetRequest) error {
+	if request.Name == "" {
+		return errors.New("name is required")
+	}
+
+	u, err := url.Parse(request.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("invalid url %q", request.URL)
+	}
+
+	for _, eventType := range request.Events {
+		if !notificationstypes.IsValidEventType(eventType) {
+			return errors.Errorf("unknown event type %q", eventType)
+		}
+	}
+
+	return nil
+}
+
+func webhookTargetResponse(target notificationstypes.WebhookTarget) WebhookTargetResponse {
+	if target.Events == nil {
+		target.Events = []notificationstypes.EventType{}
+	}
+	return WebhookTargetResponse{
+		WebhookTarget: target,
+		HasSecret:     target.Secret != "",
+	}
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/cluster"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/promotion"
+	promotiontypes "github.com/replicatedhq/kots/pkg/promotion/types"
+	"github.com/replicatedhq/kots/pkg/store"
+)
+
+type ListPromotionStagesResponse struct {
+	Stages []promotiontypes.Stage `json:"stages"`
+}
+
+type SetPromotionStageRequest struct {
+	SourceClusterID   string `json:"sourceClusterId"`
+	MinSoakSeconds    int64  `json:"minSoakSeconds"`
+	RequirePreflights bool   `json:"requirePreflights"`
+	RequireNoDrift    bool   `json:"requireNoDrift"`
+}
+
+type GetPromotionGatesResponse struct {
+	Sequence int64                       `json:"sequence"`
+	Gates    []promotiontypes.GateResult `json:"gates"`
+	Passed   bool                        `json:"passed"`
+}
+
+type PromoteAppVersionRequest struct {
+	// Sequence defaults to the sequence that's deployed to the source downstream
+	Sequence *int64 `json:"sequence,omitempty"`
+}
+
+type PromoteAppVersionResponse struct {
+	Success   bool                        `json:"success"`
+	Error     string                      `json:"error,omitempty"`
+	Gates     []promotiontypes.GateResult `json:"gates,omitempty"`
+	Promotion *promotiontypes.Promotion   `json:"promotion,omitempty"`
+}
+
+func (h *Handler) ListPromotionStages(w http.ResponseWriter, r *http.Request) {
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	stages, err := store.GetStore().ListPromotionStages(foundApp.ID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list promotion stages"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	JSON(w, http.StatusOK, ListPromotionStagesResponse{
+		Stages: stages,
+	})
+}
+
+// SetPromotionStage creates or updates the stage that promotes versions into a downstream
+func (h *Handler) SetPromotionStage(w http.ResponseWriter, r *http.Request) {
+	request := SetPromotionStageRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error(errors.Wrap(err, "failed to decode request body"))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	stage := promotiontypes.Stage{
+		AppID:             foundApp.ID,
+		SourceClusterID:   request.SourceClusterID,
+		TargetClusterID:   mux.Vars(r)["clusterId"],
+		MinSoakSeconds:    request.MinSoakSeconds,
+		RequirePreflights: request.RequirePreflights,
+		RequireNoDrift:    request.RequireNoDrift,
+	}
+
+	sourceIsRemote := false
+	for _, clusterID := range []string{stage.SourceClusterID, stage.TargetClusterID} {
+		c, err := store.GetStore().GetCluster(clusterID)
+		if err != nil {
+			logger.Error(errors.Wrap(err, "failed to get cluster"))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if c == nil {
+			logger.Error(errors.Errorf("cluster %s not found", clusterID))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if clusterID == stage.SourceClusterID {
+			sourceIsRemote = cluster.IsRemote(c)
+		}
+	}
+
+	stages, err := store.GetStore().ListPromotionStages(foundApp.ID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list promotion stages"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err := promotion.ValidateStage(stage, stages, sourceIsRemote); err != nil {
+		logger.Error(errors.Wrap(err, "invalid promotion stage"))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := store.GetStore().SetPromotionStage(stage); err != nil {
+		logger.Error(errors.Wrap(err, "failed to set promotion stage"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	JSON(w, http.StatusOK, stage)
+}
+
+func (h *Handler) DeletePromotionStage(w http.ResponseWriter, r *http.Request) {
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err := store.GetStore().DeletePromotionStage(foundApp.ID, mux.Vars(r)["clusterId"]); err != nil {
+		logger.Error(errors.Wrap(err, "failed to delete promotion stage"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPromotionGates evaluates the gates for promoting a sequence into a downstream without promoting it.
+// The sequence defaults to the sequence that's deployed to the source downstream.
+func (h *Handler) GetPromotionGates(w http.ResponseWriter, r *http.Request) {
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	stage, err := store.GetStore().GetPromotionStage(foundApp.ID, mux.Vars(r)["clusterId"])
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get promotion stage"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if stage == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var sequence int64
+	if s := r.URL.Query().Get("sequence"); s != "" {
+		sequence, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			logger.Error(errors.Wrap(err, "failed to parse sequence"))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	} else {
+		sequence, err = store.GetStore().GetCurrentDownstreamSequence(foundApp.ID, stage.SourceClusterID)
+		if err != nil {
+			logger.Error(errors.Wrap(err, "failed to get current sequence of source downstream"))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	gates, err := promotion.CheckGates(*stage, sequence)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to check promotion gates"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	JSON(w, http.StatusOK, GetPromotionGatesResponse{
+		Sequence: sequence,
+		Gates:    gates,
+		Passed:   promotiontypes.AllPassed(gates),
+	})
+}
+
+// PromoteAppVersion deploys a version that passed the gates of the stage into the stage's target downstream
+func (h *Handler) PromoteAppVersion(w http.ResponseWriter, r *http.Request) {
+	response := PromoteAppVersionResponse{
+		Success: false,
+	}
+
+	request := PromoteAppVersionRequest{}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			response.Error = "failed to decode request body"
+			logger.Error(errors.Wrap(err, response.Error))
+			JSON(w, http.StatusBadRequest, response)
+			return
+		}
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		response.Error = "failed to get app from slug"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	result, err := promotion.Promote(foundApp.ID, mux.Vars(r)["clusterId"], request.Sequence)
+	if errors.Is(err, promotion.ErrGatesFailed) {
+		response.Error = err.Error()
+		response.Gates = result.Gates
+		JSON(w, http.StatusConflict, response)
+		return
+	}
+	if err != nil {
+		response.Error = errors.Cause(err).Error()
+		logger.Error(errors.Wrap(err, "failed to promote app version"))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Success = true
+	response.Gates = result.Gates
+	response.Promotion = result.Promotion
+
+	JSON(w, http.StatusOK, response)
+}
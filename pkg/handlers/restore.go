@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -19,9 +20,15 @@ import (
 	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
+type CreateApplicationRestoreRequest struct {
+	// Clone restores the app into other namespaces next to the app, instead of replacing the app
+	Clone *snapshottypes.CloneRestoreOptions `json:"clone,omitempty"`
+}
+
 type CreateApplicationRestoreResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error,omitempty"`
+	Success     bool   `json:"success"`
+	RestoreName string `json:"restoreName,omitempty"`
+	Error       string `json:"error,omitempty"`
 }
 
 type GetRestoreStatusResponse struct {
@@ -39,6 +46,14 @@ func (h *Handler) CreateApplicationRestore(w http.ResponseWriter, r *http.Reques
 	snapshotName := mux.Vars(r)["snapshotName"]
 	kotsadmNamespace := util.PodNamespace
 
+	// the request body is optional
+	createRestoreRequest := CreateApplicationRestoreRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&createRestoreRequest); err != nil && err != io.EOF {
+		logger.Error(err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
 	backup, err := snapshot.GetBackup(r.Context(), kotsadmNamespace, snapshotName)
 	if err != nil {
 		logger.Error(err)
@@ -47,6 +62,11 @@ func (h *Handler) CreateApplicationRestore(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if createRestoreRequest.Clone != nil {
+		createApplicationCloneRestore(w, r, backup, appSlug, *createRestoreRequest.Clone)
+		return
+	}
+
 	appID := backup.Annotations["kots.io/app-id"]
 	sequence, err := strconv.ParseInt(backup.Annotations["kots.io/app-sequence"], 10, 64)
 	if err != nil {
@@ -116,6 +136,85 @@ func (h *Handler) CreateApplicationRestore(w http.ResponseWriter, r *http.Reques
 	JSON(w, http.StatusOK, createRestoreResponse)
 }
 
+// createApplicationCloneRestore restores an app from a backup into other namespaces without undeploying the app
+func createApplicationCloneRestore(w http.ResponseWriter, r *http.Request, backup *velerov1.Backup, appSlug string, opts snapshottypes.CloneRestoreOptions) {
+	createRestoreResponse := CreateApplicationRestoreResponse{
+		Success: false,
+	}
+	kotsadmNamespace := util.PodNamespace
+
+	kotsApp, err := store.GetStore().GetAppFromSlug(appSlug)
+	if err != nil {
+		logger.Error(err)
+		createRestoreResponse.Error = "failed to get app"
+		JSON(w, http.StatusInternalServerError, createRestoreResponse)
+		return
+	}
+
+	includesApp, err := backupIncludesApp(backup, kotsApp)
+	if err != nil {
+		logger.Error(err)
+		createRestoreResponse.Error = "failed to get apps in backup"
+		JSON(w, http.StatusInternalServerError, createRestoreResponse)
+		return
+	}
+	if !includesApp {
+		err := errors.Errorf("snapshot %s does not include app %s", backup.Name, appSlug)
+		logger.Error(err)
+		createRestoreResponse.Error = err.Error()
+		JSON(w, http.StatusBadRequest, createRestoreResponse)
+		return
+	}
+
+	if err := snapshot.ValidateCloneRestoreOptions(backup, appSlug, kotsadmNamespace, opts); err != nil {
+		logger.Error(err)
+		createRestoreResponse.Error = err.Error()
+		JSON(w, http.StatusBadRequest, createRestoreResponse)
+		return
+	}
+
+	// the resources of the clone must not be mistaken for the resources of another app
+	if _, err := store.GetStore().GetAppFromSlug(opts.AppSlug); err == nil {
+		err := errors.Errorf("app %s already exists", opts.AppSlug)
+		logger.Error(err)
+		createRestoreResponse.Error = err.Error()
+		JSON(w, http.StatusConflict, createRestoreResponse)
+		return
+	} else if !store.GetStore().IsNotFound(err) {
+		logger.Error(err)
+		createRestoreResponse.Error = "failed to check app slug"
+		JSON(w, http.StatusInternalServerError, createRestoreResponse)
+		return
+	}
+
+	restoreName, err := snapshot.CreateApplicationCloneRestore(r.Context(), kotsadmNamespace, backup.Name, appSlug, opts)
+	if err != nil {
+		logger.Error(err)
+		createRestoreResponse.Error = "failed to create clone restore"
+		JSON(w, http.StatusInternalServerError, createRestoreResponse)
+		return
+	}
+
+	createRestoreResponse.Success = true
+	createRestoreResponse.RestoreName = restoreName
+
+	JSON(w, http.StatusOK, createRestoreResponse)
+}
+
+// backupIncludesApp returns true if the app was backed up, either in an instance backup or in a backup of the app
+func backupIncludesApp(backup *velerov1.Backup, a *apptypes.App) (bool, error) {
+	if backup.Annotations["kots.io/instance"] != "true" {
+		return backup.Annotations["kots.io/app-id"] == a.ID, nil
+	}
+
+	appsSequences := map[string]int64{}
+	if err := json.Unmarshal([]byte(backup.Annotations["kots.io/apps-sequences"]), &appsSequences); err != nil {
+		return false, errors.Wrap(err, "failed to unmarshal apps sequences")
+	}
+	_, ok := appsSequences[a.Slug]
+	return ok, nil
+}
+
 type RestoreAppsRequest struct {
 	RestoreAll bool     `json:"restoreAll"`
 	AppSlugs   []string `json:"appSlugs"`
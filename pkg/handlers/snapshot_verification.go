@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	downstreamtypes "github.com/replicatedhq/kots/pkg/api/downstream/types"
+	"github.com/replicatedhq/kots/pkg/cluster"
+	"github.com/replicatedhq/kots/pkg/handlers/types"
+	snapshot "github.com/replicatedhq/kots/pkg/kotsadmsnapshot"
+	snapshottypes "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/snapshotscheduler"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/util"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+type GetInstanceSnapshotVerificationPolicyResponse struct {
+	Policy snapshottypes.BackupVerificationPolicy `json:"policy"`
+}
+
+type SetInstanceSnapshotVerificationPolicyRequest struct {
+	Policy snapshottypes.BackupVerificationPolicy `json:"policy"`
+}
+
+type VerifyInstanceBackupRequest struct {
+	RunAnalyzers bool   `json:"runAnalyzers"`
+	Timeout      string `json:"timeout"`
+}
+
+type VerifyInstanceBackupResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (h *Handler) GetInstanceSnapshotVerificationPolicy(w http.ResponseWriter, r *http.Request) {
+	c, err := getLocalCluster()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get local cluster"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	policy, err := store.GetStore().GetInstanceSnapshotVerificationPolicy(c.ClusterID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get snapshot verification policy"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	JSON(w, http.StatusOK, GetInstanceSnapshotVerificationPolicyResponse{
+		Policy: *policy,
+	})
+}
+
+// SetInstanceSnapshotVerificationPolicy replaces the policy that instance snapshots are verified on a schedule with
+func (h *Handler) SetInstanceSnapshotVerificationPolicy(w http.ResponseWriter, r *http.Request) {
+	request := SetInstanceSnapshotVerificationPolicyRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error(errors.Wrap(err, "failed to decode request body"))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := snapshotscheduler.ValidateVerificationPolicy(request.Policy); err != nil {
+		JSON(w, http.StatusBadRequest, types.NewErrorResponse(err))
+		return
+	}
+
+	c, err := getLocalCluster()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get local cluster"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err := store.GetStore().SetInstanceSnapshotVerificationPolicy(c.ClusterID, request.Policy); err != nil {
+		logger.Error(errors.Wrap(err, "failed to set snapshot verification policy"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyInstanceBackup starts restoring the apps of an instance backup into scratch namespaces.
+// The result is recorded on the backup and returned with the backup by ListInstanceBackups.
+// The request body is optional, the analyzers and the timeout default to the ones of the verification policy.
+func (h *Handler) VerifyInstanceBackup(w http.ResponseWriter, r *http.Request) {
+	verifyResponse := VerifyInstanceBackupResponse{
+		Success: false,
+	}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	c, err := getLocalCluster()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get local cluster"))
+		verifyResponse.Error = "failed to get cluster"
+		JSON(w, http.StatusInternalServerError, verifyResponse)
+		return
+	}
+
+	policy, err := store.GetStore().GetInstanceSnapshotVerificationPolicy(c.ClusterID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get snapshot verification policy"))
+		verifyResponse.Error = "failed to get snapshot verification policy"
+		JSON(w, http.StatusInternalServerError, verifyResponse)
+		return
+	}
+
+	request := VerifyInstanceBackupRequest{
+		RunAnalyzers: policy.RunAnalyzers,
+		Timeout:      policy.Timeout,
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		logger.Error(errors.Wrap(err, "failed to decode request body"))
+		verifyResponse.Error = "failed to decode request body"
+		JSON(w, http.StatusBadRequest, verifyResponse)
+		return
+	}
+
+	verifyPolicy := snapshottypes.BackupVerificationPolicy{
+		RunAnalyzers: request.RunAnalyzers,
+		Timeout:      request.Timeout,
+	}
+	if err := snapshotscheduler.ValidateVerificationPolicy(verifyPolicy); err != nil {
+		verifyResponse.Error = err.Error()
+		JSON(w, http.StatusBadRequest, verifyResponse)
+		return
+	}
+
+	if snapshot.IsBackupVerificationInProgress() {
+		verifyResponse.Error = snapshot.ErrBackupVerificationInProgress.Error()
+		JSON(w, http.StatusConflict, verifyResponse)
+		return
+	}
+
+	backupName := mux.Vars(r)["snapshotName"]
+	backup, err := snapshot.GetBackup(r.Context(), util.PodNamespace, backupName)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get backup"))
+		verifyResponse.Error = "failed to get backup"
+		JSON(w, http.StatusInternalServerError, verifyResponse)
+		return
+	}
+	if backup.Annotations["kots.io/instance"] != "true" || backup.Status.Phase != velerov1.BackupPhaseCompleted {
+		verifyResponse.Error = "only completed instance backups can be verified"
+		JSON(w, http.StatusBadRequest, verifyResponse)
+		return
+	}
+
+	go func() {
+		// the verification takes longer than the request
+		verification, err := snapshotscheduler.VerifyInstanceBackup(context.Background(), backupName, verifyPolicy)
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to verify backup %s", backupName))
+			return
+		}
+		logger.Infof("Verification of instance backup %s %s", backupName, verification.Status)
+	}()
+
+	verifyResponse.Success = true
+
+	JSON(w, http.StatusAccepted, verifyResponse)
+}
+
+// getLocalCluster returns the cluster kotsadm runs in, which instance snapshots are taken of
+func getLocalCluster() (*downstreamtypes.Downstream, error) {
+	clusters, err := store.GetStore().ListClusters()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list clusters")
+	}
+	for _, c := range clusters {
+		if !cluster.IsRemote(c) {
+			return c, nil
+		}
+	}
+	return nil, errors.New("no clusters found")
+}
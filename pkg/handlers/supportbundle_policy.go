@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/handlers/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+	supportbundletypes "github.com/replicatedhq/kots/pkg/supportbundle/types"
+	"github.com/replicatedhq/kots/pkg/supportbundlescheduler"
+)
+
+type GetSupportBundlePolicyResponse struct {
+	Policy supportbundletypes.SupportBundlePolicy `json:"policy"`
+}
+
+type SetSupportBundlePolicyRequest struct {
+	Policy supportbundletypes.SupportBundlePolicy `json:"policy"`
+}
+
+func (h *Handler) GetSupportBundlePolicy(w http.ResponseWriter, r *http.Request) {
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	policy, err := store.GetStore().GetAppSupportBundlePolicy(foundApp.ID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get support bundle policy"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if policy.CollectOnStates == nil {
+		policy.CollectOnStates = []appstatetypes.State{}
+	}
+
+	JSON(w, http.StatusOK, GetSupportBundlePolicyResponse{
+		Policy: *policy,
+	})
+}
+
+// SetSupportBundlePolicy replaces the support bundle policy of an app and reschedules its collection
+func (h *Handler) SetSupportBundlePolicy(w http.ResponseWriter, r *http.Request) {
+	request := SetSupportBundlePolicyRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logger.Error(errors.Wrap(err, "failed to decode request body"))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := supportbundlescheduler.Validate(request.Policy); err != nil {
+		JSON(w, http.StatusBadRequest, types.NewErrorResponse(err))
+		return
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		if store.GetStore().IsNotFound(err) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err := store.GetStore().SetAppSupportBundlePolicy(foundApp.ID, request.Policy); err != nil {
+		logger.Error(errors.Wrap(err, "failed to set support bundle policy"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err := supportbundlescheduler.Configure(foundApp, request.Policy); err != nil {
+		logger.Error(errors.Wrap(err, "failed to configure support bundle schedule"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -446,7 +446,7 @@ func (h *Handler) CollectSupportBundle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bundleID, err := supportbundle.Collect(a, mux.Vars(r)["clusterId"])
+	bundleID, err := supportbundle.Collect(a, mux.Vars(r)["clusterId"], types.TriggerManual)
 	if err != nil {
 		logger.Error(err)
 		w.WriteHeader(http.StatusInternalServerError)
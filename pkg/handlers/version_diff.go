@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/apparchive"
+	"github.com/replicatedhq/kots/pkg/binaries"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+	storetypes "github.com/replicatedhq/kots/pkg/store/types"
+)
+
+type GetAppVersionDiffResponse struct {
+	FromSequence int64                     `json:"fromSequence"`
+	ToSequence   int64                     `json:"toSequence"`
+	Resources    []apparchive.ResourceDiff `json:"resources"`
+}
+
+func (h *Handler) GetAppVersionDiff(w http.ResponseWriter, r *http.Request) {
+	appSlug := mux.Vars(r)["appSlug"]
+
+	fromSequence, err := strconv.ParseInt(mux.Vars(r)["from"], 10, 64)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to parse from sequence"))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	toSequence, err := strconv.ParseInt(mux.Vars(r)["to"], 10, 64)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to parse to sequence"))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	a, err := store.GetStore().GetAppFromSlug(appSlug)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get app from slug"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	for _, sequence := range []int64{fromSequence, toSequence} {
+		status, err := store.GetStore().GetDownstreamVersionStatus(a.ID, sequence)
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to get downstream version %d status", sequence))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if status == storetypes.VersionPendingDownload {
+			logger.Error(errors.Errorf("not diffing version %d because it's %s", sequence, status))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	fromArchivePath, err := ioutil.TempDir("", "kotsadm")
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to create temp dir"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(fromArchivePath)
+
+	if err := store.GetStore().GetAppVersionArchive(a.ID, fromSequence, fromArchivePath); err != nil {
+		logger.Error(errors.Wrapf(err, "failed to get app version archive %d", fromSequence))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	toArchivePath, err := ioutil.TempDir("", "kotsadm")
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to create temp dir"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(toArchivePath)
+
+	if err := store.GetStore().GetAppVersionArchive(a.ID, toSequence, toArchivePath); err != nil {
+		logger.Error(errors.Wrapf(err, "failed to get app version archive %d", toSequence))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	downstreams, err := store.GetStore().ListDownstreamsForApp(a.ID)
+	if err != nil {
+		logger.Error(errors.Wrapf(err, "failed to list downstreams for app %q", a.Slug))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if len(downstreams) == 0 {
+		logger.Error(errors.Errorf("no downstreams found for app %q", a.Slug))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	d := downstreams[0]
+
+	resources, err := apparchive.DiffAppVersionResourcesForDownstream(d.Name, toArchivePath, fromArchivePath, binaries.GetKustomizeBinPath())
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to diff app versions"))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	JSON(w, http.StatusOK, GetAppVersionDiffResponse{
+		FromSequence: fromSequence,
+		ToSequence:   toSequence,
+		Resources:    resources,
+	})
+}
@@ -124,9 +124,11 @@ func CopyAirgapImages(opts imagetypes.ProcessImageOptions, log *logger.CLILogger
 			Username:  opts.RegistrySettings.Username,
 			Password:  opts.RegistrySettings.Password,
 		},
-		Log:            log,
-		ProgressWriter: opts.ReportWriter,
-		LogForUI:       true,
+		Log:                 log,
+		ProgressWriter:      opts.ReportWriter,
+		LogForUI:            true,
+		VerificationPolicy:  opts.VerificationPolicy,
+		VerificationResults: opts.VerificationResults,
 	}
 
 	err := TagAndPushImagesFromBundle(opts.AirgapBundle, pushOpts)
@@ -384,12 +386,15 @@ func PushImagesFromTempRegistry(airgapRootDir string, imageList []string, option
 					Username: options.Registry.Username,
 					Password: options.Registry.Password,
 				},
-				CopyAll:           copyAll,
-				SrcDisableV1Ping:  true,
-				SrcSkipTLSVerify:  true,
-				DestDisableV1Ping: true,
-				DestSkipTLSVerify: true,
-				ReportWriter:      reportWriter,
+				CopyAll:             copyAll,
+				SrcDisableV1Ping:    true,
+				SrcSkipTLSVerify:    true,
+				DestDisableV1Ping:   true,
+				DestSkipTLSVerify:   true,
+				ReportWriter:        reportWriter,
+				VerificationPolicy:  options.VerificationPolicy,
+				OriginalImage:       imageID,
+				VerificationResults: options.VerificationResults,
 			},
 		}
 		imageCounter++
@@ -484,10 +489,13 @@ func PushImagesFromDockerArchivePath(airgapRootDir string, options imagetypes.Pu
 					Username: options.Registry.Username,
 					Password: options.Registry.Password,
 				},
-				CopyAll:           false, // docker-archive format does not support multi-arch images
-				DestSkipTLSVerify: true,
-				DestDisableV1Ping: true,
-				ReportWriter:      reportWriter,
+				CopyAll:             false, // docker-archive format does not support multi-arch images
+				DestSkipTLSVerify:   true,
+				DestDisableV1Ping:   true,
+				ReportWriter:        reportWriter,
+				VerificationPolicy:  options.VerificationPolicy,
+				OriginalImage:       rewrittenImage.Name,
+				VerificationResults: options.VerificationResults,
 			},
 		}
 		if err := pushImage(pushImageOpts); err != nil {
@@ -610,10 +618,13 @@ func PushImagesFromDockerArchiveBundle(airgapBundle string, options imagetypes.P
 					Username: options.Registry.Username,
 					Password: options.Registry.Password,
 				},
-				CopyAll:           false, // docker-archive format does not support multi-arch images
-				DestSkipTLSVerify: true,
-				DestDisableV1Ping: true,
-				ReportWriter:      reportWriter,
+				CopyAll:             false, // docker-archive format does not support multi-arch images
+				DestSkipTLSVerify:   true,
+				DestDisableV1Ping:   true,
+				ReportWriter:        reportWriter,
+				VerificationPolicy:  options.VerificationPolicy,
+				OriginalImage:       rewrittenImage.Name,
+				VerificationResults: options.VerificationResults,
 			},
 		}
 		if err := pushImage(pushImageOpts); err != nil {
@@ -641,6 +652,8 @@ func pushImage(opts imagetypes.PushImageOptions) error {
 		copyError = CopyImage(opts.CopyImageOptions)
 		if copyError == nil {
 			break // image copy succeeded, exit the retry loop
+		} else if IsImageVerificationError(copyError) {
+			break // retrying won't change the signatures
 		} else {
 			opts.Log.ChildActionWithoutSpinner("encountered error (#%d) copying image, waiting 10s before trying again: %s", i+1, copyError.Error())
 			time.Sleep(time.Second * 10)
@@ -175,7 +175,7 @@ func CopyOnlineImages(opts imagetypes.ProcessImageOptions, images []string, kots
 		if _, copied := copiedImages[img]; copied {
 			continue
 		}
-		if err := copyOnlineImage(sourceRegistry, destRegistry, img, opts.AppSlug, opts.ReportWriter, log, installationImages, dockerHubRegistry); err != nil {
+		if err := copyOnlineImage(sourceRegistry, destRegistry, img, opts.AppSlug, opts.ReportWriter, log, installationImages, dockerHubRegistry, opts.VerificationPolicy, opts.VerificationResults); err != nil {
 			return errors.Wrapf(err, "failed to copy online image %s", img)
 		}
 		copiedImages[img] = true
@@ -184,7 +184,7 @@ func CopyOnlineImages(opts imagetypes.ProcessImageOptions, images []string, kots
 	return nil
 }
 
-func copyOnlineImage(srcRegistry, destRegistry dockerregistrytypes.RegistryOptions, image string, appSlug string, reportWriter io.Writer, log *logger.CLILogger, installationImages map[string]types.InstallationImageInfo, dockerHubRegistry dockerregistrytypes.RegistryOptions) error {
+func copyOnlineImage(srcRegistry, destRegistry dockerregistrytypes.RegistryOptions, image string, appSlug string, reportWriter io.Writer, log *logger.CLILogger, installationImages map[string]types.InstallationImageInfo, dockerHubRegistry dockerregistrytypes.RegistryOptions, verificationPolicy *imagetypes.ImageVerificationPolicy, verificationResults *imagetypes.ImageVerificationResults) error {
 	// TODO: This reaches out to internet in airgap installs. It shouldn't.
 	sourceImage := image
 	srcAuth := imagetypes.RegistryAuth{}
@@ -236,12 +236,15 @@ func copyOnlineImage(srcRegistry, destRegistry dockerregistrytypes.RegistryOptio
 			Username: destRegistry.Username,
 			Password: destRegistry.Password,
 		},
-		CopyAll:           copyAll,
-		SrcDisableV1Ping:  true,
-		SrcSkipTLSVerify:  os.Getenv("KOTSADM_INSECURE_SRCREGISTRY") == "true",
-		DestDisableV1Ping: true,
-		DestSkipTLSVerify: true,
-		ReportWriter:      reportWriter,
+		CopyAll:             copyAll,
+		SrcDisableV1Ping:    true,
+		SrcSkipTLSVerify:    os.Getenv("KOTSADM_INSECURE_SRCREGISTRY") == "true",
+		DestDisableV1Ping:   true,
+		DestSkipTLSVerify:   true,
+		ReportWriter:        reportWriter,
+		VerificationPolicy:  verificationPolicy,
+		OriginalImage:       image,
+		VerificationResults: verificationResults,
 	}
 	if err := CopyImage(copyImageOpts); err != nil {
 		return errors.Wrapf(err, "failed to copy %s to %s", sourceImage, destImage)
@@ -298,7 +301,21 @@ func CopyImage(opts types.CopyImageOptions) error {
 		imageListSelection = copy.CopyAllImages
 	}
 
-	_, err := CopyImageWithGC(context.Background(), opts.DestRef, opts.SrcRef, &copy.Options{
+	policyContext, verifySignatures, err := getCopyPolicyContext(opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to get policy")
+	}
+	defer policyContext.Destroy()
+
+	if verifySignatures {
+		registriesDir, err := getSigstoreRegistriesDir()
+		if err != nil {
+			return errors.Wrap(err, "failed to get registries config")
+		}
+		srcCtx.RegistriesDirPath = registriesDir
+	}
+
+	_, err = copyImageWithGC(context.Background(), policyContext, opts.DestRef, opts.SrcRef, &copy.Options{
 		RemoveSignatures:      true,
 		SignBy:                "",
 		ReportWriter:          opts.ReportWriter,
@@ -307,6 +324,15 @@ func CopyImage(opts types.CopyImageOptions) error {
 		ForceManifestMIMEType: "",
 		ImageListSelection:    imageListSelection,
 	})
+
+	var policyErr signature.PolicyRequirementError
+	isPolicyErr := errors.As(err, &policyErr)
+	if verifySignatures && (err == nil || isPolicyErr) {
+		opts.VerificationResults.Add(newImageVerificationResult(copyImageName(opts), err))
+	}
+	if isPolicyErr {
+		return &ImageVerificationError{Image: copyImageName(opts), Err: err}
+	}
 	if err != nil {
 		return errors.Wrap(err, "failed to copy image")
 	}
@@ -314,6 +340,27 @@ func CopyImage(opts types.CopyImageOptions) error {
 	return nil
 }
 
+// copyImageName returns the name of the image that is copied, as it is referenced by the application
+func copyImageName(opts types.CopyImageOptions) string {
+	if opts.OriginalImage != "" {
+		return opts.OriginalImage
+	}
+	if opts.SrcRef.DockerReference() != nil {
+		return opts.SrcRef.DockerReference().String()
+	}
+	return ""
+}
+
+// getCopyPolicyContext returns the policy to copy an image with, and whether its signatures are verified
+func getCopyPolicyContext(opts types.CopyImageOptions) (*signature.PolicyContext, bool, error) {
+	if opts.VerificationPolicy == nil {
+		policyContext, err := getPolicyContext()
+		return policyContext, false, err
+	}
+
+	return getVerificationPolicyContext(opts.VerificationPolicy, copyImageName(opts))
+}
+
 // if dockerHubRegistry is provided, its credentials will be used for DockerHub images to increase the rate limit.
 func IsPrivateImage(image string, dockerHubRegistry dockerregistrytypes.RegistryOptions) (bool, error) {
 	var lastErr error
@@ -422,7 +469,12 @@ func CopyImageWithGC(ctx context.Context, destRef, srcRef containerstypes.ImageR
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get policy")
 	}
+	defer policyContext.Destroy()
+
+	return copyImageWithGC(ctx, policyContext, destRef, srcRef, options)
+}
 
+func copyImageWithGC(ctx context.Context, policyContext *signature.PolicyContext, destRef, srcRef containerstypes.ImageReference, options *copy.Options) ([]byte, error) {
 	manifest, err := copy.Image(ctx, policyContext, destRef, srcRef, options)
 
 	// copying an image increases allocated memory, which can push the pod to cross the memory limit when copying multiple images in a row.
@@ -3,6 +3,8 @@ package types
 import (
 	"io"
 	"net/http"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/containers/image/v5/types"
@@ -22,6 +24,10 @@ type ProcessImageOptions struct {
 	AirgapBundle     string
 	CreateAppDir     bool
 	ReportWriter     io.Writer
+	// VerificationPolicy, when set, requires the signatures of images to be verified before they are copied
+	VerificationPolicy *ImageVerificationPolicy
+	// VerificationResults collects the results of verifying the images that are copied
+	VerificationResults *ImageVerificationResults
 }
 
 type RegistryAuth struct {
@@ -44,6 +50,13 @@ type CopyImageOptions struct {
 	DestDisableV1Ping bool
 	DestSkipTLSVerify bool
 	ReportWriter      io.Writer
+	// VerificationPolicy, when set, requires the signatures of the source image to be verified before it is copied
+	VerificationPolicy *ImageVerificationPolicy
+	// OriginalImage is the image as it is referenced by the application. It is used to match the verification policy
+	// and the identity in the signatures, since the source can be a proxy or a temporary registry.
+	OriginalImage string
+	// VerificationResults collects the result of verifying the source image
+	VerificationResults *ImageVerificationResults
 }
 
 type CopyAirgapImagesResult struct {
@@ -56,6 +69,9 @@ type PushImagesOptions struct {
 	Log            *logger.CLILogger
 	ProgressWriter io.Writer
 	LogForUI       bool
+	// VerificationPolicy, when set, requires the signatures of images to be verified before they are pushed
+	VerificationPolicy  *ImageVerificationPolicy
+	VerificationResults *ImageVerificationResults
 }
 
 type PushImageOptions struct {
@@ -105,3 +121,68 @@ type OCIArtifactFile struct {
 	Path      string
 	MediaType string
 }
+
+// ImageVerificationPolicy is the policy that images must satisfy before they are copied to the registry
+type ImageVerificationPolicy struct {
+	// Registries requires the images in a registry, repository or of an image to be signed by one of the cosign public keys
+	Registries []RegistryVerificationPolicy `json:"registries,omitempty"`
+	// ContainersPolicy is a containers-policy.json document. Registries are added to its docker transport scopes.
+	ContainersPolicy string `json:"containersPolicy,omitempty"`
+	// RequireSignatures rejects the images that do not match a registry when there is no containers policy
+	RequireSignatures bool `json:"requireSignatures,omitempty"`
+}
+
+type RegistryVerificationPolicy struct {
+	// Registry is a registry host, repository, or image, e.g. registry.example.com/app
+	Registry string `json:"registry"`
+	// CosignPublicKeys are the PEM encoded public keys that are trusted to sign the images
+	CosignPublicKeys []string `json:"cosignPublicKeys"`
+}
+
+// ImageVerificationResult is the result of verifying the signatures of an image
+type ImageVerificationResult struct {
+	Image      string    `json:"image"`
+	Verified   bool      `json:"verified"`
+	Message    string    `json:"message,omitempty"`
+	VerifiedAt time.Time `json:"verifiedAt"`
+}
+
+// ImageVerificationResults collects the latest verification result of each image that is copied. It is safe for concurrent use.
+type ImageVerificationResults struct {
+	mtx     sync.Mutex
+	results map[string]ImageVerificationResult
+}
+
+// Add records the result of an image, replacing the previous result of the image
+func (r *ImageVerificationResults) Add(result ImageVerificationResult) {
+	if r == nil {
+		return
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.results == nil {
+		r.results = map[string]ImageVerificationResult{}
+	}
+	r.results[result.Image] = result
+}
+
+// List returns the results sorted by image
+func (r *ImageVerificationResults) List() []ImageVerificationResult {
+	results := []ImageVerificationResult{}
+	if r == nil {
+		return results
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for _, result := range r.results {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Image < results[j].Image
+	})
+	return results
+}
@@ -0,0 +1,300 @@
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containers/image/v5/signature"
+	"github.com/distribution/reference"
+	"github.com/pkg/errors"
+	imagetypes "github.com/replicatedhq/kots/pkg/image/types"
+	"sigs.k8s.io/yaml"
+)
+
+// registries.d configuration that looks up cosign signatures, which are stored as attachments in the registry
+const sigstoreRegistriesConfig = `default-docker:
+  use-sigstore-attachments: true
+`
+
+var (
+	sigstoreRegistriesDir     string
+	sigstoreRegistriesDirErr  error
+	sigstoreRegistriesDirOnce sync.Once
+)
+
+// ImageVerificationError is returned when an image does not satisfy the verification policy
+type ImageVerificationError struct {
+	Image string
+	Err   error
+}
+
+func (e *ImageVerificationError) Error() string {
+	return fmt.Sprintf("image %s failed signature verification: %v", e.Image, e.Err)
+}
+
+func (e *ImageVerificationError) Unwrap() error {
+	return e.Err
+}
+
+func IsImageVerificationError(err error) bool {
+	var verificationErr *ImageVerificationError
+	return errors.As(err, &verificationErr)
+}
+
+// policyRequirement is a requirement in a containers-policy.json document
+type policyRequirement map[string]interface{}
+
+// containersPolicy is a containers-policy.json document. Only the docker transport is used since images are
+// always matched by the name they are referenced with in the application.
+type containersPolicy struct {
+	Default    []policyRequirement                       `json:"default"`
+	Transports map[string]map[string][]policyRequirement `json:"transports,omitempty"`
+}
+
+// ParseImageVerificationPolicy parses a verification policy in yaml or json and checks that it can be evaluated
+func ParseImageVerificationPolicy(data []byte) (*imagetypes.ImageVerificationPolicy, error) {
+	policy := &imagetypes.ImageVerificationPolicy{}
+	if err := yaml.Unmarshal(data, policy); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal policy")
+	}
+
+	for _, r := range policy.Registries {
+		if r.Registry == "" {
+			return nil, errors.New("registry is required")
+		}
+		if len(r.CosignPublicKeys) == 0 {
+			return nil, errors.Errorf("registry %s does not have any cosign public keys", r.Registry)
+		}
+	}
+
+	merged, err := mergeContainersPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	// build the whole policy so that invalid keys and requirements are reported now instead of when copying images
+	b, err := json.Marshal(merged)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal containers policy")
+	}
+	policyContext, err := newPolicyContext(b)
+	if err != nil {
+		return nil, err
+	}
+	policyContext.Destroy()
+
+	return policy, nil
+}
+
+// mergeContainersPolicy returns the containers policy with a docker transport scope for each of the registries
+func mergeContainersPolicy(policy *imagetypes.ImageVerificationPolicy) (*containersPolicy, error) {
+	merged := &containersPolicy{}
+	if policy.ContainersPolicy != "" {
+		if err := yaml.Unmarshal([]byte(policy.ContainersPolicy), merged); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal containers policy")
+		}
+		if len(merged.Default) == 0 {
+			return nil, errors.New("containers policy does not have a default requirement")
+		}
+	} else if policy.RequireSignatures {
+		merged.Default = []policyRequirement{{"type": "reject"}}
+	} else {
+		merged.Default = []policyRequirement{{"type": "insecureAcceptAnything"}}
+	}
+
+	if merged.Transports == nil {
+		merged.Transports = map[string]map[string][]policyRequirement{}
+	}
+	if merged.Transports["docker"] == nil {
+		merged.Transports["docker"] = map[string][]policyRequirement{}
+	}
+
+	for _, r := range policy.Registries {
+		keyDatas := [][]byte{}
+		for _, key := range r.CosignPublicKeys {
+			keyDatas = append(keyDatas, []byte(strings.TrimSpace(key)))
+		}
+		merged.Transports["docker"][r.Registry] = []policyRequirement{
+			{
+				"type":     "sigstoreSigned",
+				"keyDatas": keyDatas,
+			},
+		}
+	}
+
+	return merged, nil
+}
+
+// dockerPolicyScopes returns the docker transport scopes that can match an image, from the most to the least specific
+func dockerPolicyScopes(named reference.Named) []string {
+	scopes := []string{}
+	if canonical, ok := named.(reference.Canonical); ok {
+		scopes = append(scopes, fmt.Sprintf("%s@%s", named.Name(), canonical.Digest()))
+	} else if tagged, ok := named.(reference.Tagged); ok {
+		scopes = append(scopes, fmt.Sprintf("%s:%s", named.Name(), tagged.Tag()))
+	}
+
+	name := named.Name()
+	for {
+		scopes = append(scopes, name)
+		i := strings.LastIndex(name, "/")
+		if i == -1 {
+			break
+		}
+		name = name[:i]
+	}
+
+	hostParts := strings.Split(reference.Domain(named), ".")
+	for i := 1; i < len(hostParts); i++ {
+		scopes = append(scopes, "*."+strings.Join(hostParts[i:], "."))
+	}
+
+	return scopes
+}
+
+// imagePolicyRequirements returns the requirements for an image. Signed identities are resolved against the
+// image as it is referenced in the application, since the image is copied from a proxy or a temporary registry.
+// Images that cannot be named, such as images in docker archives, only match the default requirements.
+func imagePolicyRequirements(policy *containersPolicy, image string) ([]policyRequirement, error) {
+	if image == "" {
+		return policy.Default, nil
+	}
+
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse image %s", image)
+	}
+
+	requirements := policy.Default
+	for _, scope := range dockerPolicyScopes(named) {
+		if scopeRequirements, ok := policy.Transports["docker"][scope]; ok {
+			requirements = scopeRequirements
+			break
+		}
+	}
+
+	resolved := []policyRequirement{}
+	for _, requirement := range requirements {
+		r := policyRequirement{}
+		for k, v := range requirement {
+			r[k] = v
+		}
+		if r["type"] == "signedBy" || r["type"] == "sigstoreSigned" {
+			identity, _ := r["signedIdentity"].(map[string]interface{})
+			r["signedIdentity"] = resolveSignedIdentity(identity, named)
+		}
+		resolved = append(resolved, r)
+	}
+
+	return resolved, nil
+}
+
+// resolveSignedIdentity replaces identities that are relative to the image that is being copied with the image
+// from the application. Identities that are already absolute are returned as is.
+func resolveSignedIdentity(identity map[string]interface{}, named reference.Named) map[string]interface{} {
+	identityType, _ := identity["type"].(string)
+
+	exactReference := map[string]interface{}{
+		"type":            "exactReference",
+		"dockerReference": reference.TagNameOnly(named).String(),
+	}
+	exactRepository := map[string]interface{}{
+		"type":             "exactRepository",
+		"dockerRepository": named.Name(),
+	}
+
+	switch identityType {
+	case "", "matchRepoDigestOrExact":
+		if _, ok := named.(reference.Canonical); ok {
+			return exactRepository
+		}
+		return exactReference
+	case "matchExact":
+		return exactReference
+	case "matchRepository":
+		return exactRepository
+	default:
+		return identity
+	}
+}
+
+// requiresSignature returns false if the requirements accept images without checking signatures
+func requiresSignature(requirements []policyRequirement) bool {
+	for _, r := range requirements {
+		if r["type"] != "insecureAcceptAnything" {
+			return true
+		}
+	}
+	return false
+}
+
+func newPolicyContext(policyJSON []byte) (*signature.PolicyContext, error) {
+	policy, err := signature.NewPolicyFromBytes(policyJSON)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read policy")
+	}
+	policyContext, err := signature.NewPolicyContext(policy)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create policy")
+	}
+	return policyContext, nil
+}
+
+// getVerificationPolicyContext returns the policy to copy an image with, and whether the image signatures are verified
+func getVerificationPolicyContext(policy *imagetypes.ImageVerificationPolicy, image string) (*signature.PolicyContext, bool, error) {
+	merged, err := mergeContainersPolicy(policy)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to merge containers policy")
+	}
+
+	requirements, err := imagePolicyRequirements(merged, image)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to get policy requirements")
+	}
+
+	b, err := json.Marshal(containersPolicy{Default: requirements})
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to marshal image policy")
+	}
+
+	policyContext, err := newPolicyContext(b)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return policyContext, requiresSignature(requirements), nil
+}
+
+// getSigstoreRegistriesDir returns the registries.d directory that enables cosign signature lookups
+func getSigstoreRegistriesDir() (string, error) {
+	sigstoreRegistriesDirOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "kots-registries.d")
+		if err != nil {
+			sigstoreRegistriesDirErr = errors.Wrap(err, "failed to create temp dir")
+			return
+		}
+		if err := os.WriteFile(filepath.Join(dir, "default.yaml"), []byte(sigstoreRegistriesConfig), 0644); err != nil {
+			sigstoreRegistriesDirErr = errors.Wrap(err, "failed to write registries config")
+			return
+		}
+		sigstoreRegistriesDir = dir
+	})
+	return sigstoreRegistriesDir, sigstoreRegistriesDirErr
+}
+
+func newImageVerificationResult(image string, err error) imagetypes.ImageVerificationResult {
+	result := imagetypes.ImageVerificationResult{
+		Image:      image,
+		Verified:   err == nil,
+		VerifiedAt: time.Now(),
+	}
+	if err != nil {
+		result.Message = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,252 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/distribution/reference"
+	imagetypes "github.com/replicatedhq/kots/pkg/image/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_dockerPolicyScopes(t *testing.T) {
+	tests := []struct {
+		name  string
+		image string
+		want  []string
+	}{
+		{
+			name:  "docker hub",
+			image: "nginx",
+			want: []string{
+				"docker.io/library/nginx:latest",
+				"docker.io/library/nginx",
+				"docker.io/library",
+				"docker.io",
+				"*.io",
+			},
+		},
+		{
+			name:  "tagged",
+			image: "registry.example.com/org/app:1.0.0",
+			want: []string{
+				"registry.example.com/org/app:1.0.0",
+				"registry.example.com/org/app",
+				"registry.example.com/org",
+				"registry.example.com",
+				"*.example.com",
+				"*.com",
+			},
+		},
+		{
+			name:  "digest",
+			image: "quay.io/org/app@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			want: []string{
+				"quay.io/org/app@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+				"quay.io/org/app",
+				"quay.io/org",
+				"quay.io",
+				"*.io",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			named, err := reference.ParseNormalizedNamed(tt.image)
+			require.NoError(t, err)
+			named = reference.TagNameOnly(named)
+
+			assert.Equal(t, tt.want, dockerPolicyScopes(named))
+		})
+	}
+}
+
+func Test_mergeContainersPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *imagetypes.ImageVerificationPolicy
+		want    *containersPolicy
+		wantErr bool
+	}{
+		{
+			name:   "accept unsigned images by default",
+			policy: &imagetypes.ImageVerificationPolicy{},
+			want: &containersPolicy{
+				Default: []policyRequirement{{"type": "insecureAcceptAnything"}},
+				Transports: map[string]map[string][]policyRequirement{
+					"docker": {},
+				},
+			},
+		},
+		{
+			name: "registry keys",
+			policy: &imagetypes.ImageVerificationPolicy{
+				RequireSignatures: true,
+				Registries: []imagetypes.RegistryVerificationPolicy{
+					{
+						Registry:         "registry.example.com/org",
+						CosignPublicKeys: []string{"\nkey\n"},
+					},
+				},
+			},
+			want: &containersPolicy{
+				Default: []policyRequirement{{"type": "reject"}},
+				Transports: map[string]map[string][]policyRequirement{
+					"docker": {
+						"registry.example.com/org": {
+							{"type": "sigstoreSigned", "keyDatas": [][]byte{[]byte("key")}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "registry keys take precedence over the containers policy",
+			policy: &imagetypes.ImageVerificationPolicy{
+				ContainersPolicy: `{"default":[{"type":"insecureAcceptAnything"}],"transports":{"docker":{"quay.io":[{"type":"reject"}],"registry.example.com":[{"type":"reject"}]}}}`,
+				Registries: []imagetypes.RegistryVerificationPolicy{
+					{
+						Registry:         "registry.example.com",
+						CosignPublicKeys: []string{"key"},
+					},
+				},
+			},
+			want: &containersPolicy{
+				Default: []policyRequirement{{"type": "insecureAcceptAnything"}},
+				Transports: map[string]map[string][]policyRequirement{
+					"docker": {
+						"quay.io": {
+							{"type": "reject"},
+						},
+						"registry.example.com": {
+							{"type": "sigstoreSigned", "keyDatas": [][]byte{[]byte("key")}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "containers policy without default",
+			policy: &imagetypes.ImageVerificationPolicy{
+				ContainersPolicy: `{"transports":{}}`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeContainersPolicy(tt.policy)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_imagePolicyRequirements(t *testing.T) {
+	policy := &containersPolicy{
+		Default: []policyRequirement{{"type": "reject"}},
+		Transports: map[string]map[string][]policyRequirement{
+			"docker": {
+				"registry.example.com": {
+					{"type": "sigstoreSigned", "keyPath": "/keys/example.pub"},
+				},
+				"registry.example.com/org/pinned": {
+					{
+						"type":    "sigstoreSigned",
+						"keyPath": "/keys/example.pub",
+						"signedIdentity": map[string]interface{}{
+							"type":             "exactRepository",
+							"dockerRepository": "registry.example.com/org/signed",
+						},
+					},
+				},
+				"*.internal": {
+					{"type": "insecureAcceptAnything"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		image            string
+		want             []policyRequirement
+		requireSignature bool
+	}{
+		{
+			name:             "no image",
+			image:            "",
+			want:             []policyRequirement{{"type": "reject"}},
+			requireSignature: true,
+		},
+		{
+			name:             "default",
+			image:            "quay.io/org/app:1.0.0",
+			want:             []policyRequirement{{"type": "reject"}},
+			requireSignature: true,
+		},
+		{
+			name:  "identity resolved to the tag",
+			image: "registry.example.com/org/app:1.0.0",
+			want: []policyRequirement{
+				{
+					"type":    "sigstoreSigned",
+					"keyPath": "/keys/example.pub",
+					"signedIdentity": map[string]interface{}{
+						"type":            "exactReference",
+						"dockerReference": "registry.example.com/org/app:1.0.0",
+					},
+				},
+			},
+			requireSignature: true,
+		},
+		{
+			name:  "identity resolved to the repository for digests",
+			image: "registry.example.com/org/app@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			want: []policyRequirement{
+				{
+					"type":    "sigstoreSigned",
+					"keyPath": "/keys/example.pub",
+					"signedIdentity": map[string]interface{}{
+						"type":             "exactRepository",
+						"dockerRepository": "registry.example.com/org/app",
+					},
+				},
+			},
+			requireSignature: true,
+		},
+		{
+			name:  "absolute identity",
+			image: "registry.example.com/org/pinned:1.0.0",
+			want: []policyRequirement{
+				{
+					"type":    "sigstoreSigned",
+					"keyPath": "/keys/example.pub",
+					"signedIdentity": map[string]interface{}{
+						"type":             "exactRepository",
+						"dockerRepository": "registry.example.com/org/signed",
+					},
+				},
+			},
+			requireSignature: true,
+		},
+		{
+			name:             "host wildcard",
+			image:            "registry.corp.internal/app:1.0.0",
+			want:             []policyRequirement{{"type": "insecureAcceptAnything"}},
+			requireSignature: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := imagePolicyRequirements(policy, tt.image)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.requireSignature, requiresSignature(got))
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package imageverification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/image"
+	imagetypes "github.com/replicatedhq/kots/pkg/image/types"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	kotsadmtypes "github.com/replicatedhq/kots/pkg/kotsadm/types"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	ConfigMapName = "kotsadm-image-verification-policy"
+	// DefaultPolicyKey holds the policy that was set at install time. It applies to apps that don't have their own policy.
+	DefaultPolicyKey = "default-policy.yaml"
+	// PendingResultsConfigMapName holds the results of the images that the cli pushed from an airgap bundle,
+	// until the admin console creates the version of the bundle
+	PendingResultsConfigMapName = "kotsadm-image-verification-results"
+)
+
+func appPolicyKey(appSlug string) string {
+	return fmt.Sprintf("app-%s.yaml", appSlug)
+}
+
+func appPendingResultsKey(appSlug string) string {
+	return fmt.Sprintf("app-%s.json", appSlug)
+}
+
+// GetPolicies returns the policy of the app and the default policy. Either can be empty.
+func GetPolicies(clientset kubernetes.Interface, namespace string, appSlug string) (string, string, error) {
+	cm, err := getConfigMap(clientset, namespace)
+	if err != nil {
+		return "", "", err
+	}
+	if cm == nil {
+		return "", "", nil
+	}
+	return cm.Data[appPolicyKey(appSlug)], cm.Data[DefaultPolicyKey], nil
+}
+
+// GetPolicy returns the verification policy that images of the app are copied with, or nil if images are not verified
+func GetPolicy(clientset kubernetes.Interface, namespace string, appSlug string) (*imagetypes.ImageVerificationPolicy, error) {
+	appPolicy, defaultPolicy, err := GetPolicies(clientset, namespace, appSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := appPolicy
+	if policy == "" {
+		policy = defaultPolicy
+	}
+	if policy == "" {
+		return nil, nil
+	}
+
+	parsed, err := image.ParseImageVerificationPolicy([]byte(policy))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse policy")
+	}
+	return parsed, nil
+}
+
+// GetPolicyForApp returns the verification policy of the app when running in the admin console.
+// Images are not verified when the cli copies them, unless a policy is passed explicitly.
+func GetPolicyForApp(appSlug string) (*imagetypes.ImageVerificationPolicy, error) {
+	if util.PodNamespace == "" {
+		return nil, nil
+	}
+
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get clientset")
+	}
+
+	return GetPolicy(clientset, util.PodNamespace, appSlug)
+}
+
+// SetAppPolicy sets the policy of an app. An empty policy removes it, and the default policy applies instead.
+func SetAppPolicy(clientset kubernetes.Interface, namespace string, appSlug string, policy string) error {
+	return setPolicy(clientset, namespace, appPolicyKey(appSlug), policy)
+}
+
+// SetDefaultPolicy sets the policy for apps that don't have their own policy
+func SetDefaultPolicy(clientset kubernetes.Interface, namespace string, policy string) error {
+	return setPolicy(clientset, namespace, DefaultPolicyKey, policy)
+}
+
+func setPolicy(clientset kubernetes.Interface, namespace string, key string, policy string) error {
+	if policy != "" {
+		if _, err := image.ParseImageVerificationPolicy([]byte(policy)); err != nil {
+			return errors.Wrap(err, "failed to parse policy")
+		}
+	}
+
+	cm, err := getConfigMap(clientset, namespace)
+	if err != nil {
+		return err
+	}
+
+	if cm == nil {
+		if policy == "" {
+			return nil
+		}
+		cm = &corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ConfigMapName,
+				Namespace: namespace,
+				Labels:    kotsadmtypes.GetKotsadmLabels(),
+			},
+			Data: map[string]string{
+				key: policy,
+			},
+		}
+		if _, err := clientset.CoreV1().ConfigMaps(namespace).Create(context.TODO(), cm, metav1.CreateOptions{}); err != nil {
+			return errors.Wrap(err, "failed to create config map")
+		}
+		return nil
+	}
+
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	if policy == "" {
+		delete(cm.Data, key)
+	} else {
+		cm.Data[key] = policy
+	}
+	if _, err := clientset.CoreV1().ConfigMaps(namespace).Update(context.TODO(), cm, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to update config map")
+	}
+	return nil
+}
+
+func getConfigMap(clientset kubernetes.Interface, namespace string) (*corev1.ConfigMap, error) {
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if kuberneteserrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to get config map")
+	}
+	return cm, nil
+}
+
+// SaveResults persists the verification results of the images that were copied for the app version.
+// When includePending is set, the results of the images that the cli pushed from the airgap bundle are included.
+// Results are only persisted when running in the admin console.
+func SaveResults(appID string, appSlug string, sequence int64, results *imagetypes.ImageVerificationResults, includePending bool) error {
+	if util.PodNamespace == "" || appID == "" {
+		return nil
+	}
+
+	if includePending {
+		clientset, err := k8sutil.GetClientset()
+		if err != nil {
+			return errors.Wrap(err, "failed to get clientset")
+		}
+
+		pendingResults, err := takePendingResults(clientset, util.PodNamespace, appSlug)
+		if err != nil {
+			return errors.Wrap(err, "failed to get pending results")
+		}
+
+		// results of images that were copied again by the admin console replace the results from the cli
+		merged := &imagetypes.ImageVerificationResults{}
+		for _, result := range pendingResults {
+			merged.Add(result)
+		}
+		for _, result := range results.List() {
+			merged.Add(result)
+		}
+		results = merged
+	}
+
+	list := results.List()
+	if len(list) == 0 {
+		return nil
+	}
+
+	if err := store.GetStore().SetImageVerificationResults(appID, sequence, list); err != nil {
+		return errors.Wrap(err, "failed to set image verification results")
+	}
+
+	return nil
+}
+
+// SetPendingResults stores the results of the images that the cli pushed from an airgap bundle, so that the admin console
+// can persist them with the version of the bundle
+func SetPendingResults(clientset kubernetes.Interface, namespace string, appSlug string, results []imagetypes.ImageVerificationResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(results)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal results")
+	}
+
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), PendingResultsConfigMapName, metav1.GetOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to get config map")
+	}
+
+	if kuberneteserrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      PendingResultsConfigMapName,
+				Namespace: namespace,
+				Labels:    kotsadmtypes.GetKotsadmLabels(),
+			},
+			Data: map[string]string{
+				appPendingResultsKey(appSlug): string(data),
+			},
+		}
+		if _, err := clientset.CoreV1().ConfigMaps(namespace).Create(context.TODO(), cm, metav1.CreateOptions{}); err != nil {
+			return errors.Wrap(err, "failed to create config map")
+		}
+		return nil
+	}
+
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[appPendingResultsKey(appSlug)] = string(data)
+	if _, err := clientset.CoreV1().ConfigMaps(namespace).Update(context.TODO(), cm, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to update config map")
+	}
+	return nil
+}
+
+// takePendingResults returns the results of the images that the cli pushed for the app, and removes them from the config map
+func takePendingResults(clientset kubernetes.Interface, namespace string, appSlug string) ([]imagetypes.ImageVerificationResult, error) {
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), PendingResultsConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if kuberneteserrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to get config map")
+	}
+
+	data, ok := cm.Data[appPendingResultsKey(appSlug)]
+	if !ok {
+		return nil, nil
+	}
+
+	results := []imagetypes.ImageVerificationResult{}
+	if err := json.Unmarshal([]byte(data), &results); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal results")
+	}
+
+	delete(cm.Data, appPendingResultsKey(appSlug))
+	if _, err := clientset.CoreV1().ConfigMaps(namespace).Update(context.TODO(), cm, metav1.UpdateOptions{}); err != nil {
+		return nil, errors.Wrap(err, "failed to update config map")
+	}
+
+	return results, nil
+}
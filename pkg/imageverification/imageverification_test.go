@@ -0,0 +1,42 @@
+package imageverification
+
+import (
+	"testing"
+	"time"
+
+	imagetypes "github.com/replicatedhq/kots/pkg/image/types"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_pendingResults(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	verifiedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	results := []imagetypes.ImageVerificationResult{
+		{Image: "registry.example.com/app:1.0.0", Verified: true, VerifiedAt: verifiedAt},
+	}
+	otherResults := []imagetypes.ImageVerificationResult{
+		{Image: "registry.example.com/other:1.0.0", Verified: false, Message: "no signature", VerifiedAt: verifiedAt},
+	}
+
+	require.NoError(t, SetPendingResults(clientset, "default", "app", results))
+	require.NoError(t, SetPendingResults(clientset, "default", "other", otherResults))
+
+	got, err := takePendingResults(clientset, "default", "app")
+	require.NoError(t, err)
+	require.Equal(t, results, got)
+
+	// results are only taken once
+	got, err = takePendingResults(clientset, "default", "app")
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	got, err = takePendingResults(clientset, "default", "other")
+	require.NoError(t, err)
+	require.Equal(t, otherResults, got)
+
+	got, err = takePendingResults(clientset, "other-namespace", "app")
+	require.NoError(t, err)
+	require.Empty(t, got)
+}
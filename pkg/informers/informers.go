@@ -3,11 +3,15 @@ package informers
 import (
 	"context"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/metrics"
+	"github.com/replicatedhq/kots/pkg/notifications"
+	notificationstypes "github.com/replicatedhq/kots/pkg/notifications/types"
 	kotssnapshot "github.com/replicatedhq/kots/pkg/snapshot"
 	"github.com/replicatedhq/kots/pkg/supportbundle"
 	"github.com/replicatedhq/kots/pkg/util"
@@ -19,6 +23,11 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// notifiedBackups maps backup names to the last phase a notification was sent for,
+// so that repeated modify events for a finished backup don't send duplicate notifications
+var notifiedBackups = map[string]velerov1.BackupPhase{}
+var notifiedBackupsMtx sync.Mutex
+
 // Start will start the kots informers
 // These are not the application level informers, but they are the general purpose KOTS
 // informers. For example, we want to watch Velero Backup
@@ -69,6 +78,8 @@ func Start() error {
 					logger.Errorf("failed to cast obj to backup")
 				}
 
+				notifyBackupPhase(backup)
+
 				if backup.Status.Phase == velerov1.BackupPhaseFailed || backup.Status.Phase == velerov1.BackupPhasePartiallyFailed {
 					if backup.Annotations == nil {
 						backup.Annotations = map[string]string{}
@@ -116,3 +127,46 @@ func Start() error {
 
 	return nil
 }
+
+func notifyBackupPhase(backup *velerov1.Backup) {
+	if backup == nil {
+		return
+	}
+
+	var eventType notificationstypes.EventType
+	switch backup.Status.Phase {
+	case velerov1.BackupPhaseCompleted:
+		eventType = notificationstypes.EventSnapshotCompleted
+	case velerov1.BackupPhaseFailed, velerov1.BackupPhasePartiallyFailed:
+		eventType = notificationstypes.EventSnapshotFailed
+	default:
+		return
+	}
+
+	notifiedBackupsMtx.Lock()
+	if notifiedBackups[backup.Name] == backup.Status.Phase {
+		notifiedBackupsMtx.Unlock()
+		return
+	}
+	notifiedBackups[backup.Name] = backup.Status.Phase
+	notifiedBackupsMtx.Unlock()
+
+	if eventType == notificationstypes.EventSnapshotCompleted {
+		metrics.IncSnapshot(metrics.SnapshotOutcomeCompleted)
+	} else {
+		metrics.IncSnapshot(metrics.SnapshotOutcomeFailed)
+	}
+
+	event := notificationstypes.Event{
+		Type: eventType,
+		Data: map[string]interface{}{
+			"backup": backup.Name,
+			"phase":  backup.Status.Phase,
+		},
+	}
+	if backup.Annotations != nil {
+		// instance backups are not tied to a single app
+		event.AppID = backup.Annotations["kots.io/app-id"]
+	}
+	notifications.Notify(event)
+}
@@ -125,6 +125,11 @@ func GetDynamicResourceInterface(gvk *schema.GroupVersionKind, namespace string)
 		return nil, errors.Wrap(err, "failed to get cluster config")
 	}
 
+	return GetDynamicResourceInterfaceForConfig(config, gvk, namespace)
+}
+
+// GetDynamicResourceInterfaceForConfig is the same as GetDynamicResourceInterface, but for the cluster of the given config
+func GetDynamicResourceInterfaceForConfig(config *rest.Config, gvk *schema.GroupVersionKind, namespace string) (dynamic.ResourceInterface, error) {
 	disc, err := discovery.NewDiscoveryClientForConfig(config)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create discovery client")
@@ -0,0 +1,57 @@
+package k8sutil
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	memory "k8s.io/client-go/discovery/cached"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// restClientGetter is a genericclioptions.RESTClientGetter for an existing rest config,
+// for clients such as helm that don't accept a rest config directly
+type restClientGetter struct {
+	config    *rest.Config
+	namespace string
+}
+
+var _ genericclioptions.RESTClientGetter = &restClientGetter{}
+
+func NewRESTClientGetter(config *rest.Config, namespace string) genericclioptions.RESTClientGetter {
+	return &restClientGetter{
+		config:    config,
+		namespace: namespace,
+	}
+}
+
+func (g *restClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return rest.CopyConfig(g.config), nil
+}
+
+func (g *restClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	disc, err := discovery.NewDiscoveryClientForConfig(g.config)
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewMemCacheClient(disc), nil
+}
+
+func (g *restClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	disc, err := g.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	return restmapper.NewDeferredDiscoveryRESTMapper(disc), nil
+}
+
+// ToRawKubeConfigLoader is only used for the namespace, the config itself always comes from ToRESTConfig
+func (g *restClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return clientcmd.NewDefaultClientConfig(*clientcmdapi.NewConfig(), &clientcmd.ConfigOverrides{
+		Context: clientcmdapi.Context{
+			Namespace: g.namespace,
+		},
+	})
+}
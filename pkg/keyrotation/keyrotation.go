@@ -0,0 +1,182 @@
+package keyrotation
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/crypto"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/keyrotation/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/util"
+	kotskindscrypto "github.com/replicatedhq/kotskinds/pkg/crypto"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	ErrRotationInProgress = errors.New("an encryption key rotation is already running")
+	// ErrRotationIncomplete means that some values are not encrypted with the new key yet, so the previous key was kept.
+	// Running the rotation again continues with the same new key.
+	ErrRotationIncomplete = errors.New("some values could not be encrypted with the new key, the previous key was kept")
+
+	rotateMtx sync.Mutex
+)
+
+type RotateOptions struct {
+	// DryRun reports the values that would be re-encrypted without changing anything
+	DryRun bool
+}
+
+// Rotate encrypts every stored secret with a new key, and retires the previous key once every value is verified to decrypt with the new key.
+// The new key is saved to the kotsadm-encryption secret before anything is re-encrypted, so a rotation that's interrupted continues with the same key.
+func Rotate(ctx context.Context, opts RotateOptions) (*types.Report, error) {
+	if !rotateMtx.TryLock() {
+		return nil, ErrRotationInProgress
+	}
+	defer rotateMtx.Unlock()
+
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get clientset")
+	}
+
+	secret, err := clientset.CoreV1().Secrets(util.PodNamespace).Get(ctx, crypto.EncryptionSecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get encryption secret")
+	}
+
+	currentKey := string(secret.Data[crypto.EncryptionKeySecretKey])
+	if currentKey == "" {
+		return nil, errors.Errorf("%s secret does not have an encryption key", crypto.EncryptionSecretName)
+	}
+	previousKey := string(secret.Data[crypto.PreviousEncryptionKeySecretKey])
+
+	report := &types.Report{
+		DryRun:  opts.DryRun,
+		Resumed: previousKey != "",
+	}
+
+	newKey := currentKey
+	if previousKey == "" {
+		newKey, err = crypto.GenerateKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate key")
+		}
+		previousKey = currentKey
+
+		if !opts.DryRun {
+			secret.Data[crypto.EncryptionKeySecretKey] = []byte(newKey)
+			secret.Data[crypto.PreviousEncryptionKeySecretKey] = []byte(previousKey)
+			if _, err := clientset.CoreV1().Secrets(util.PodNamespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+				return nil, errors.Wrap(err, "failed to save new key")
+			}
+			logger.Info("started encryption key rotation")
+		}
+	}
+
+	if !opts.DryRun {
+		// new values are encrypted with the new key while the rotation runs
+		if err := useKeys(clientset, newKey, previousKey); err != nil {
+			return nil, errors.Wrap(err, "failed to use new key")
+		}
+	}
+
+	r := &rotator{newKey: newKey, apply: !opts.DryRun}
+	report.Locations, err = r.run(ctx, clientset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to re-encrypt values")
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+	if len(report.Errors()) > 0 {
+		return report, ErrRotationIncomplete
+	}
+
+	// values may have been written with the previous key by a process that did not know about the new key yet
+	verifier := &rotator{newKey: newKey}
+	verifiedLocations, err := verifier.run(ctx, clientset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to verify values")
+	}
+	verification := types.Report{Locations: verifiedLocations}
+	if verification.Pending() > 0 || len(verification.Errors()) > 0 {
+		return report, ErrRotationIncomplete
+	}
+
+	if err := retireKey(ctx, clientset, previousKey); err != nil {
+		return nil, errors.Wrap(err, "failed to retire previous key")
+	}
+	report.Retired = true
+	logger.Info("finished encryption key rotation")
+
+	return report, nil
+}
+
+// LoadPreviousKey adds the previous key of a rotation that did not finish to the decryption keys,
+// so that values that were not re-encrypted yet can still be decrypted
+func LoadPreviousKey(ctx context.Context, clientset kubernetes.Interface, namespace string) error {
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, crypto.EncryptionSecretName, metav1.GetOptions{})
+	if err != nil {
+		if kuberneteserrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to get encryption secret")
+	}
+
+	previousKey := string(secret.Data[crypto.PreviousEncryptionKeySecretKey])
+	if previousKey == "" {
+		return nil
+	}
+
+	if err := crypto.InitFromString(previousKey); err != nil {
+		return errors.Wrap(err, "failed to load previous key")
+	}
+	if err := kotskindscrypto.InitFromString(previousKey); err != nil {
+		return errors.Wrap(err, "failed to load previous key for kots kinds")
+	}
+
+	return nil
+}
+
+func useKeys(clientset kubernetes.Interface, newKey string, previousKey string) error {
+	if err := crypto.InitFromString(previousKey); err != nil {
+		return errors.Wrap(err, "failed to load previous key")
+	}
+	if err := crypto.SetEncryptionKey(newKey); err != nil {
+		return errors.Wrap(err, "failed to set encryption key")
+	}
+
+	// identity configs are encrypted by kotskinds, which reads the key from the same secret
+	if err := kotskindscrypto.InitFromString(previousKey); err != nil {
+		return errors.Wrap(err, "failed to load previous key for kots kinds")
+	}
+	if err := kotskindscrypto.InitFromSecret(clientset, util.PodNamespace); err != nil {
+		return errors.Wrap(err, "failed to set encryption key for kots kinds")
+	}
+
+	return nil
+}
+
+func retireKey(ctx context.Context, clientset kubernetes.Interface, previousKey string) error {
+	secret, err := clientset.CoreV1().Secrets(util.PodNamespace).Get(ctx, crypto.EncryptionSecretName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get encryption secret")
+	}
+
+	delete(secret.Data, crypto.PreviousEncryptionKeySecretKey)
+	if _, err := clientset.CoreV1().Secrets(util.PodNamespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to update encryption secret")
+	}
+
+	// kotskinds can't remove keys, the previous key is dropped from it when kotsadm restarts
+	if err := crypto.RemoveDecryptionKey(previousKey); err != nil {
+		return errors.Wrap(err, "failed to remove previous key")
+	}
+
+	return nil
+}
@@ -0,0 +1,385 @@
+package keyrotation
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	"github.com/replicatedhq/kots/pkg/crypto"
+	"github.com/replicatedhq/kots/pkg/keyrotation/types"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
+	"github.com/replicatedhq/kots/pkg/secretprovider"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/util"
+	kotsv1beta1 "github.com/replicatedhq/kotskinds/apis/kots/v1beta1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	serializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+const gitOpsSecretName = "kotsadm-gitops"
+
+type rotator struct {
+	newKey string
+	// apply writes the re-encrypted values, otherwise they are only counted
+	apply bool
+}
+
+func (r *rotator) run(ctx context.Context, clientset kubernetes.Interface) ([]types.Location, error) {
+	locations := []types.Location{}
+
+	apps, err := store.GetStore().ListInstalledApps()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list apps")
+	}
+
+	for _, a := range apps {
+		versions, err := store.GetStore().FindDownstreamVersions(a.ID, true)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list versions of %s", a.Slug)
+		}
+
+		rotated := map[int64]bool{}
+		for _, v := range versions.AllVersions {
+			if rotated[v.ParentSequence] {
+				continue
+			}
+			rotated[v.ParentSequence] = true
+			locations = append(locations, r.rotateAppVersion(a, v.ParentSequence))
+		}
+	}
+
+	databaseLocations, err := r.rotateDatabase()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to re-encrypt database values")
+	}
+	locations = append(locations, databaseLocations...)
+
+	gitOpsLocation, err := r.rotateGitOps(ctx, clientset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to re-encrypt gitops credentials")
+	}
+	if gitOpsLocation != nil {
+		locations = append(locations, *gitOpsLocation)
+	}
+
+	return locations, nil
+}
+
+func (r *rotator) rotateAppVersion(a *apptypes.App, sequence int64) types.Location {
+	location := types.Location{
+		Kind:     types.LocationAppVersion,
+		AppSlug:  a.Slug,
+		Sequence: &sequence,
+	}
+
+	archiveDir, err := os.MkdirTemp("", "kotsadm")
+	if err != nil {
+		location.Error = errors.Wrap(err, "failed to create temp dir").Error()
+		return location
+	}
+	defer os.RemoveAll(archiveDir)
+
+	if err := store.GetStore().GetAppVersionArchive(a.ID, sequence, archiveDir); err != nil {
+		location.Error = errors.Wrap(err, "failed to get app version archive").Error()
+		return location
+	}
+
+	location.Encrypted, location.Reencrypted, err = r.rotateArchive(archiveDir)
+	if err != nil {
+		location.Error = err.Error()
+		return location
+	}
+
+	if r.apply && location.Reencrypted > 0 {
+		if err := store.GetStore().UpdateAppVersionArchive(a.ID, sequence, archiveDir); err != nil {
+			location.Error = errors.Wrap(err, "failed to update app version archive").Error()
+			return location
+		}
+	}
+
+	return location
+}
+
+// rotateArchive re-encrypts the config values and identity configs of an archive, and removes the legacy encryption key
+// from its installation. Both the upstream and the rendered kots kinds are rotated.
+func (r *rotator) rotateArchive(archiveDir string) (int, int, error) {
+	encrypted, reencrypted := 0, 0
+
+	for _, dir := range []string{filepath.Join(archiveDir, "upstream", "userdata"), filepath.Join(archiveDir, "kotsKinds")} {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return errors.Wrap(err, "failed to read file")
+			}
+
+			var updated []byte
+			var fileEncrypted, fileReencrypted int
+			switch {
+			case kotsutil.IsApiVersionKind(content, "kots.io/v1beta1", "ConfigValues"):
+				updated, fileEncrypted, fileReencrypted, err = r.rotateConfigValues(path)
+			case kotsutil.IsApiVersionKind(content, "kots.io/v1beta1", "IdentityConfig"):
+				updated, fileEncrypted, fileReencrypted, err = r.rotateIdentityConfig(content)
+			case kotsutil.IsApiVersionKind(content, "kots.io/v1beta1", "Installation"):
+				updated, fileEncrypted, fileReencrypted, err = r.rotateInstallation(content)
+			default:
+				return nil
+			}
+			if err != nil {
+				return errors.Wrapf(err, "failed to re-encrypt %s", strings.TrimPrefix(path, archiveDir+string(filepath.Separator)))
+			}
+
+			encrypted += fileEncrypted
+			reencrypted += fileReencrypted
+
+			if r.apply && fileReencrypted > 0 {
+				if err := os.WriteFile(path, updated, info.Mode()); err != nil {
+					return errors.Wrap(err, "failed to write file")
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return encrypted, reencrypted, err
+		}
+	}
+
+	return encrypted, reencrypted, nil
+}
+
+func (r *rotator) rotateConfigValues(path string) ([]byte, int, int, error) {
+	configValues, err := kotsutil.LoadConfigValuesFromFile(path)
+	if err != nil {
+		return nil, 0, 0, errors.Wrap(err, "failed to load config values")
+	}
+
+	encrypted, reencrypted := 0, 0
+	for name, value := range configValues.Spec.Values {
+		if value.Value == "" || secretprovider.IsReference(value.Value) {
+			continue
+		}
+
+		// config values don't record which values are encrypted, values that can't be decrypted are not secrets
+		updatedValue, changed, err := r.reencrypt(value.Value)
+		if err != nil {
+			continue
+		}
+
+		encrypted++
+		if changed {
+			reencrypted++
+			value.Value = updatedValue
+			configValues.Spec.Values[name] = value
+		}
+	}
+
+	updated, err := encodeKotsKind(configValues)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return updated, encrypted, reencrypted, nil
+}
+
+func (r *rotator) rotateIdentityConfig(content []byte) ([]byte, int, int, error) {
+	identityConfig, err := kotsutil.LoadIdentityConfigFromContents(content)
+	if err != nil {
+		return nil, 0, 0, errors.Wrap(err, "failed to load identity config")
+	}
+
+	encrypted, reencrypted := 0, 0
+	for _, value := range []*string{clientSecretValue(identityConfig), &identityConfig.Spec.DexConnectors.ValueEncrypted} {
+		if value == nil || *value == "" {
+			continue
+		}
+
+		updatedValue, changed, err := r.reencrypt(*value)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+
+		encrypted++
+		if changed {
+			reencrypted++
+			*value = updatedValue
+		}
+	}
+
+	updated, err := kotsutil.EncodeIdentityConfig(*identityConfig)
+	if err != nil {
+		return nil, 0, 0, errors.Wrap(err, "failed to encode identity config")
+	}
+
+	return updated, encrypted, reencrypted, nil
+}
+
+func clientSecretValue(identityConfig *kotsv1beta1.IdentityConfig) *string {
+	if identityConfig.Spec.ClientSecret == nil {
+		return nil
+	}
+	return &identityConfig.Spec.ClientSecret.ValueEncrypted
+}
+
+// rotateInstallation removes the encryption key that older versions of kots stored in the installation.
+// The key is counted as a value to re-encrypt, so that the rotation isn't verified while it's still stored.
+func (r *rotator) rotateInstallation(content []byte) ([]byte, int, int, error) {
+	installation, err := kotsutil.LoadInstallationFromContents(content)
+	if err != nil {
+		return nil, 0, 0, errors.Wrap(err, "failed to load installation")
+	}
+
+	if installation.Spec.EncryptionKey == "" {
+		return nil, 0, 0, nil
+	}
+	installation.Spec.EncryptionKey = ""
+
+	updated, err := encodeKotsKind(installation)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return updated, 1, 1, nil
+}
+
+func (r *rotator) rotateDatabase() ([]types.Location, error) {
+	values, err := store.GetStore().ListEncryptedValues()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list encrypted values")
+	}
+
+	locations := []types.Location{}
+	locationIndex := map[string]int{}
+	for _, value := range values {
+		name := fmt.Sprintf("%s.%s", value.Table, value.Column)
+		idx, ok := locationIndex[name]
+		if !ok {
+			locations = append(locations, types.Location{
+				Kind: types.LocationDatabase,
+				Name: name,
+			})
+			idx = len(locations) - 1
+			locationIndex[name] = idx
+		}
+		location := &locations[idx]
+
+		updatedValue, changed, err := r.reencrypt(value.Value)
+		if err != nil {
+			if location.Error == "" {
+				location.Error = errors.Wrapf(err, "failed to re-encrypt %s", value.ID).Error()
+			}
+			continue
+		}
+
+		location.Encrypted++
+		if !changed {
+			continue
+		}
+		location.Reencrypted++
+
+		if r.apply {
+			value.Value = updatedValue
+			if err := store.GetStore().UpdateEncryptedValue(value); err != nil && location.Error == "" {
+				location.Error = errors.Wrapf(err, "failed to update %s", value.ID).Error()
+			}
+		}
+	}
+
+	return locations, nil
+}
+
+func (r *rotator) rotateGitOps(ctx context.Context, clientset kubernetes.Interface) (*types.Location, error) {
+	secret, err := clientset.CoreV1().Secrets(util.PodNamespace).Get(ctx, gitOpsSecretName, metav1.GetOptions{})
+	if err != nil {
+		if kuberneteserrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to get gitops secret")
+	}
+
+	location := &types.Location{
+		Kind: types.LocationGitOps,
+		Name: gitOpsSecretName,
+	}
+	for key, value := range secret.Data {
+		if !strings.HasSuffix(key, ".privateKey") && !strings.HasSuffix(key, ".apiToken") {
+			continue
+		}
+		if len(value) == 0 {
+			continue
+		}
+
+		updatedValue, changed, err := r.reencrypt(string(value))
+		if err != nil {
+			location.Error = errors.Wrapf(err, "failed to re-encrypt %s", key).Error()
+			return location, nil
+		}
+
+		location.Encrypted++
+		if changed {
+			location.Reencrypted++
+			secret.Data[key] = []byte(updatedValue)
+		}
+	}
+
+	if r.apply && location.Reencrypted > 0 {
+		if _, err := clientset.CoreV1().Secrets(util.PodNamespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+			location.Error = errors.Wrap(err, "failed to update gitops secret").Error()
+		}
+	}
+
+	return location, nil
+}
+
+// reencrypt returns the value encrypted with the new key, and whether that's different from the value.
+// Encryption is deterministic for a key, so values that are already encrypted with the new key are unchanged.
+func (r *rotator) reencrypt(value string) (string, bool, error) {
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to decode")
+	}
+
+	decrypted, err := crypto.Decrypt(decoded)
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to decrypt")
+	}
+
+	encrypted, err := crypto.EncryptWithKey(r.newKey, decrypted)
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to encrypt")
+	}
+
+	reencrypted := base64.StdEncoding.EncodeToString(encrypted)
+	return reencrypted, reencrypted != value, nil
+}
+
+func encodeKotsKind(obj runtime.Object) ([]byte, error) {
+	s := serializer.NewYAMLSerializer(serializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme)
+
+	var b bytes.Buffer
+	if err := s.Encode(obj, &b); err != nil {
+		return nil, errors.Wrap(err, "failed to encode")
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,143 @@
+package keyrotation
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/replicatedhq/kots/pkg/crypto"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_rotateArchive(t *testing.T) {
+	req := require.New(t)
+
+	oldKey, err := crypto.GenerateKey()
+	req.NoError(err)
+	req.NoError(crypto.SetEncryptionKey(oldKey))
+	newKey, err := crypto.GenerateKey()
+	req.NoError(err)
+
+	encryptedPassword := base64.StdEncoding.EncodeToString(crypto.Encrypt([]byte("s3cret")))
+	encryptedClientSecret := base64.StdEncoding.EncodeToString(crypto.Encrypt([]byte("client-s3cret")))
+
+	archiveDir := t.TempDir()
+	userdataDir := filepath.Join(archiveDir, "upstream", "userdata")
+	req.NoError(os.MkdirAll(userdataDir, 0755))
+
+	req.NoError(os.WriteFile(filepath.Join(userdataDir, "config.yaml"), []byte(fmt.Sprintf(`apiVersion: kots.io/v1beta1
+kind: ConfigValues
+metadata:
+  name: my-app
+spec:
+  values:
+    db_password:
+      value: %s
+    db_host:
+      value: postgres
+    api_token:
+      value: vault://secret/my-app#token
+`, encryptedPassword)), 0644))
+
+	req.NoError(os.WriteFile(filepath.Join(userdataDir, "identityconfig.yaml"), []byte(fmt.Sprintf(`apiVersion: kots.io/v1beta1
+kind: IdentityConfig
+metadata:
+  name: identity
+spec:
+  enabled: true
+  clientID: my-app
+  clientSecret:
+    valueEncrypted: %s
+`, encryptedClientSecret)), 0644))
+
+	req.NoError(os.WriteFile(filepath.Join(userdataDir, "installation.yaml"), []byte(fmt.Sprintf(`apiVersion: kots.io/v1beta1
+kind: Installation
+metadata:
+  name: my-app
+spec:
+  versionLabel: 1.0.0
+  encryptionKey: %s
+`, oldKey)), 0644))
+
+	configValuesBefore, err := os.ReadFile(filepath.Join(userdataDir, "config.yaml"))
+	req.NoError(err)
+
+	// a dry run counts the values without changing them
+	dryRun := &rotator{newKey: newKey}
+	encrypted, reencrypted, err := dryRun.rotateArchive(archiveDir)
+	req.NoError(err)
+	req.Equal(3, encrypted)
+	req.Equal(3, reencrypted)
+
+	configValuesAfter, err := os.ReadFile(filepath.Join(userdataDir, "config.yaml"))
+	req.NoError(err)
+	req.Equal(string(configValuesBefore), string(configValuesAfter))
+
+	r := &rotator{newKey: newKey, apply: true}
+	encrypted, reencrypted, err = r.rotateArchive(archiveDir)
+	req.NoError(err)
+	req.Equal(3, encrypted)
+	req.Equal(3, reencrypted)
+
+	configValues, err := kotsutil.LoadConfigValuesFromFile(filepath.Join(userdataDir, "config.yaml"))
+	req.NoError(err)
+	req.Equal("postgres", configValues.Spec.Values["db_host"].Value)
+	req.Equal("vault://secret/my-app#token", configValues.Spec.Values["api_token"].Value)
+	requireEncryptedWithKey(t, newKey, configValues.Spec.Values["db_password"].Value, "s3cret")
+
+	identityConfigContent, err := os.ReadFile(filepath.Join(userdataDir, "identityconfig.yaml"))
+	req.NoError(err)
+	identityConfig, err := kotsutil.LoadIdentityConfigFromContents(identityConfigContent)
+	req.NoError(err)
+	requireEncryptedWithKey(t, newKey, identityConfig.Spec.ClientSecret.ValueEncrypted, "client-s3cret")
+
+	installation, err := kotsutil.LoadInstallationFromPath(filepath.Join(userdataDir, "installation.yaml"))
+	req.NoError(err)
+	req.Empty(installation.Spec.EncryptionKey)
+	req.Equal("1.0.0", installation.Spec.VersionLabel)
+
+	// running again finds nothing left to re-encrypt
+	encrypted, reencrypted, err = r.rotateArchive(archiveDir)
+	req.NoError(err)
+	req.Equal(2, encrypted)
+	req.Equal(0, reencrypted)
+}
+
+func Test_rotateArchiveUndecryptableIdentityConfig(t *testing.T) {
+	req := require.New(t)
+
+	newKey, err := crypto.GenerateKey()
+	req.NoError(err)
+	unknownKey, err := crypto.GenerateKey()
+	req.NoError(err)
+
+	encrypted, err := crypto.EncryptWithKey(unknownKey, []byte("client-s3cret"))
+	req.NoError(err)
+
+	archiveDir := t.TempDir()
+	userdataDir := filepath.Join(archiveDir, "upstream", "userdata")
+	req.NoError(os.MkdirAll(userdataDir, 0755))
+	req.NoError(os.WriteFile(filepath.Join(userdataDir, "identityconfig.yaml"), []byte(fmt.Sprintf(`apiVersion: kots.io/v1beta1
+kind: IdentityConfig
+metadata:
+  name: identity
+spec:
+  clientSecret:
+    valueEncrypted: %s
+`, base64.StdEncoding.EncodeToString(encrypted))), 0644))
+
+	r := &rotator{newKey: newKey, apply: true}
+	_, _, err = r.rotateArchive(archiveDir)
+	req.Error(err)
+}
+
+func requireEncryptedWithKey(t *testing.T, key string, value string, expected string) {
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	require.NoError(t, err)
+	decrypted, err := crypto.DecryptWithKey(key, decoded)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(decrypted))
+}
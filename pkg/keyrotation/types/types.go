@@ -0,0 +1,61 @@
+package types
+
+type LocationKind string
+
+const (
+	// LocationAppVersion is the config values, identity config and installation of an app version archive
+	LocationAppVersion LocationKind = "app-version"
+	// LocationDatabase is a column of the kotsadm database, e.g. registry passwords and webhook secrets
+	LocationDatabase LocationKind = "database"
+	// LocationGitOps is the credentials in the kotsadm-gitops secret
+	LocationGitOps LocationKind = "gitops"
+)
+
+// EncryptedValue is a value in the kotsadm database that's encrypted with the kotsadm encryption key
+type EncryptedValue struct {
+	Table  string
+	Column string
+	ID     string
+	Value  string
+}
+
+type Report struct {
+	DryRun bool `json:"dryRun"`
+	// Resumed is true if a rotation that was interrupted was continued
+	Resumed bool `json:"resumed"`
+	// Retired is true if every value was verified and the previous key was removed
+	Retired   bool       `json:"retired"`
+	Locations []Location `json:"locations"`
+}
+
+type Location struct {
+	Kind     LocationKind `json:"kind"`
+	AppSlug  string       `json:"appSlug,omitempty"`
+	Sequence *int64       `json:"sequence,omitempty"`
+	Name     string       `json:"name,omitempty"`
+	// Encrypted is the number of encrypted values that were found
+	Encrypted int `json:"encrypted"`
+	// Reencrypted is the number of values that were, or in a dry run would be, encrypted with the new key
+	Reencrypted int    `json:"reencrypted"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Pending returns the number of values that are not encrypted with the new key yet
+func (r Report) Pending() int {
+	pending := 0
+	for _, l := range r.Locations {
+		pending += l.Reencrypted
+	}
+	return pending
+}
+
+// Errors returns the locations that could not be rotated
+func (r Report) Errors() []Location {
+	errs := []Location{}
+	for _, l := range r.Locations {
+		if l.Error != "" {
+			errs = append(errs, l)
+		}
+	}
+	return errs
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/identity"
 	"github.com/replicatedhq/kots/pkg/image"
 	imagetypes "github.com/replicatedhq/kots/pkg/image/types"
+	"github.com/replicatedhq/kots/pkg/imageverification"
 	"github.com/replicatedhq/kots/pkg/ingress"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/kotsadm/types"
@@ -155,6 +156,16 @@ func Upgrade(clientset *kubernetes.Clientset, upgradeOptions types.UpgradeOption
 }
 
 func Deploy(deployOptions types.DeployOptions, log *logger.CLILogger) error {
+	var verificationPolicy *imagetypes.ImageVerificationPolicy
+	if deployOptions.ImageVerificationPolicy != "" {
+		p, err := image.ParseImageVerificationPolicy([]byte(deployOptions.ImageVerificationPolicy))
+		if err != nil {
+			return errors.Wrap(err, "failed to parse image verification policy")
+		}
+		verificationPolicy = p
+	}
+	verificationResults := &imagetypes.ImageVerificationResults{}
+
 	if deployOptions.AirgapBundle != "" && deployOptions.RegistryConfig.OverrideRegistry != "" {
 		pushOptions := imagetypes.PushImagesOptions{
 			Registry: registrytypes.RegistryOptions{
@@ -163,7 +174,9 @@ func Deploy(deployOptions types.DeployOptions, log *logger.CLILogger) error {
 				Username:  deployOptions.RegistryConfig.Username,
 				Password:  deployOptions.RegistryConfig.Password,
 			},
-			ProgressWriter: deployOptions.ProgressWriter,
+			ProgressWriter:      deployOptions.ProgressWriter,
+			VerificationPolicy:  verificationPolicy,
+			VerificationResults: verificationResults,
 		}
 
 		if !deployOptions.DisableImagePush {
@@ -218,6 +231,13 @@ func Deploy(deployOptions types.DeployOptions, log *logger.CLILogger) error {
 		deployOptions.LimitRange = limitRange
 	}
 
+	if deployOptions.AppImagesPushed && deployOptions.License != nil {
+		// the admin console persists the results with the version that is installed from the bundle
+		if err := imageverification.SetPendingResults(clientset, deployOptions.Namespace, deployOptions.License.Spec.AppSlug, verificationResults.List()); err != nil {
+			return errors.Wrap(err, "failed to set image verification results")
+		}
+	}
+
 	if deployOptions.AppImagesPushed {
 		airgapMetadata, err := archives.GetFileContentFromTGZArchive("airgap.yaml", deployOptions.AirgapBundle)
 		if err != nil {
@@ -493,6 +513,24 @@ func ensureKotsadm(deployOptions types.DeployOptions, clientset *kubernetes.Clie
 		if updated {
 			restartKotsadmAPI = true
 		}
+	} else if util.IsUnlicensedUpstreamURI(deployOptions.UpstreamURI) {
+		// helm charts and git repositories don't have a license,
+		// so the upstream is written to a config map for kotsadm to install instead
+		updated, err := ensureUpstreamConfigMap(&deployOptions, clientset)
+		if err != nil {
+			return errors.Wrap(err, "failed to ensure upstream config map")
+		}
+
+		if updated {
+			restartKotsadmAPI = true
+		}
+	}
+
+	if deployOptions.ImageVerificationPolicy != "" {
+		// kotsadm verifies the images of apps that don't have their own policy against the default policy
+		if err := imageverification.SetDefaultPolicy(clientset, deployOptions.Namespace, deployOptions.ImageVerificationPolicy); err != nil {
+			return errors.Wrap(err, "failed to set default image verification policy")
+		}
 	}
 
 	if deployOptions.ConfigValues != nil {
@@ -0,0 +1,53 @@
+package kotsadm
+
+import (
+	"github.com/replicatedhq/kots/pkg/kotsadm/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UpstreamConfigMap is used to install an app that does not have a license, such as a chart from an oci registry
+func UpstreamConfigMap(namespace string, appSlug string, upstreamURI string) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kotsadm-default-upstream",
+			Namespace: namespace,
+			Labels: types.GetKotsadmLabels(map[string]string{
+				"kots.io/automation": "upstream",
+				"kots.io/app":        appSlug,
+			}),
+		},
+		Data: map[string]string{
+			"upstreamUri": upstreamURI,
+		},
+	}
+
+	return configMap
+}
+
+// UpstreamCredentialsSecret stores the credentials of a git or chart upstream, so that they are not persisted as part of the upstream uri
+func UpstreamCredentialsSecret(namespace string, name string, appSlug string, username string, password string) *corev1.Secret {
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: types.GetKotsadmLabels(map[string]string{
+				"kots.io/app": appSlug,
+			}),
+		},
+		Data: map[string][]byte{
+			"username": []byte(username),
+			"password": []byte(password),
+		},
+	}
+
+	return secret
+}
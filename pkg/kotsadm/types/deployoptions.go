@@ -60,6 +60,8 @@ type DeployOptions struct {
 	AdditionalAnnotations  map[string]string
 	AdditionalLabels       map[string]string
 	PrivateCAsConfigmap    string
+	// ImageVerificationPolicy is the default policy that image signatures are verified against
+	ImageVerificationPolicy string
 
 	IdentityConfig kotsv1beta1.IdentityConfig
 	IngressConfig  kotsv1beta1.IngressConfig
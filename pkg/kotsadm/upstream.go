@@ -0,0 +1,92 @@
+package kotsadm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	kotsadmobjects "github.com/replicatedhq/kots/pkg/kotsadm/objects"
+	"github.com/replicatedhq/kots/pkg/kotsadm/types"
+	"github.com/replicatedhq/kots/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func ensureUpstreamConfigMap(deployOptions *types.DeployOptions, clientset *kubernetes.Clientset) (bool, error) {
+	existingConfigMap, err := getUpstreamConfigMap(deployOptions.Namespace, clientset)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to check for existing upstream config map")
+	}
+
+	if existingConfigMap != nil {
+		return false, nil
+	}
+
+	appSlug := util.UnlicensedUpstreamName(deployOptions.UpstreamURI)
+
+	// credentials for git remotes, registries and chart repositories are moved to a secret so that the upstream uri can be persisted
+	upstreamURI, err := ensureUpstreamCredentialsSecret(deployOptions.Namespace, appSlug, deployOptions.UpstreamURI, clientset)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to ensure upstream credentials secret")
+	}
+
+	_, err = clientset.CoreV1().ConfigMaps(deployOptions.Namespace).Create(context.TODO(), kotsadmobjects.UpstreamConfigMap(deployOptions.Namespace, appSlug, upstreamURI), metav1.CreateOptions{})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create upstream config map")
+	}
+
+	return true, nil
+}
+
+// ensureUpstreamCredentialsSecret writes the credentials included in a git or chart upstream uri to a secret,
+// and returns the upstream uri with the credentials replaced by a reference to the secret
+func ensureUpstreamCredentialsSecret(namespace string, appSlug string, upstreamURI string, clientset *kubernetes.Clientset) (string, error) {
+	secretName := fmt.Sprintf("kotsadm-%s-upstream-credentials", appSlug)
+
+	strippedURI, credentials, err := util.ReplaceUpstreamCredentials(upstreamURI, secretName)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to replace upstream credentials")
+	}
+	if credentials == nil {
+		return upstreamURI, nil
+	}
+
+	password, _ := credentials.Password()
+	secret := kotsadmobjects.UpstreamCredentialsSecret(namespace, secretName, appSlug, credentials.Username(), password)
+
+	existingSecret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		return "", errors.Wrap(err, "failed to get existing upstream credentials secret")
+	}
+
+	if kuberneteserrors.IsNotFound(err) {
+		_, err = clientset.CoreV1().Secrets(namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+		if err != nil {
+			return "", errors.Wrap(err, "failed to create upstream credentials secret")
+		}
+		return strippedURI, nil
+	}
+
+	existingSecret.Data = secret.Data
+	_, err = clientset.CoreV1().Secrets(namespace).Update(context.TODO(), existingSecret, metav1.UpdateOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to update upstream credentials secret")
+	}
+
+	return strippedURI, nil
+}
+
+func getUpstreamConfigMap(namespace string, clientset *kubernetes.Clientset) (*corev1.ConfigMap, error) {
+	upstreamConfigMap, err := clientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), "kotsadm-default-upstream", metav1.GetOptions{})
+	if err != nil {
+		if kuberneteserrors.IsNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "failed to get upstream config map from cluster")
+	}
+
+	return upstreamConfigMap, nil
+}
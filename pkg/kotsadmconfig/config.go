@@ -2,18 +2,17 @@ package kotsadmconfig
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"os"
 	"strconv"
 
 	"github.com/pkg/errors"
 	kotsconfig "github.com/replicatedhq/kots/pkg/config"
-	"github.com/replicatedhq/kots/pkg/crypto"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
 	registrytypes "github.com/replicatedhq/kots/pkg/registry/types"
+	"github.com/replicatedhq/kots/pkg/secretprovider"
 	"github.com/replicatedhq/kots/pkg/template"
 	"github.com/replicatedhq/kots/pkg/util"
 	kotsv1beta1 "github.com/replicatedhq/kotskinds/apis/kots/v1beta1"
@@ -119,7 +118,7 @@ func GetMissingRequiredConfig(configGroups []kotsv1beta1.ConfigGroup) ([]string,
 	return requiredItems, requiredItemsTitles
 }
 
-func UpdateAppConfigValues(values map[string]kotsv1beta1.ConfigValue, configGroups []kotsv1beta1.ConfigGroup) map[string]kotsv1beta1.ConfigValue {
+func UpdateAppConfigValues(values map[string]kotsv1beta1.ConfigValue, configGroups []kotsv1beta1.ConfigGroup) (map[string]kotsv1beta1.ConfigValue, error) {
 	for _, group := range configGroups {
 		for _, item := range group.Items {
 			if item.Type == "file" {
@@ -139,7 +138,12 @@ func UpdateAppConfigValues(values map[string]kotsv1beta1.ConfigValue, configGrou
 					// if the decryption succeeds, don't encrypt again
 					_, err := util.DecryptConfigValue(updatedValue)
 					if err != nil {
-						updatedValue = base64.StdEncoding.EncodeToString(crypto.Encrypt([]byte(updatedValue)))
+						// secret references are stored as they are
+						encrypted, err := secretprovider.EncryptConfigValue(context.TODO(), updatedValue)
+						if err != nil {
+							return nil, errors.Wrapf(err, "failed to encrypt value for %s", item.Name)
+						}
+						updatedValue = encrypted
 					}
 				}
 
@@ -164,7 +168,7 @@ func UpdateAppConfigValues(values map[string]kotsv1beta1.ConfigValue, configGrou
 			}
 		}
 	}
-	return values
+	return values, nil
 }
 
 // this is where config values that are passed to the install command are read from
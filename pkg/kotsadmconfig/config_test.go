@@ -74,7 +74,8 @@ func Test_updateAppConfigValues(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			req := require.New(t)
-			updatedValues := UpdateAppConfigValues(test.values, test.configGroups)
+			updatedValues, err := UpdateAppConfigValues(test.values, test.configGroups)
+			req.NoError(err)
 
 			req.Equal(test.want, updatedValues)
 		})
@@ -704,6 +704,8 @@ func ListInstanceBackups(ctx context.Context, kotsadmNamespace string) ([]*types
 			}
 		}
 
+		backup.Verification = GetBackupVerification(&veleroBackup)
+
 		backups = append(backups, &backup)
 	}
 
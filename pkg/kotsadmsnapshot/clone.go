@@ -0,0 +1,314 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	kotsadmtypes "github.com/replicatedhq/kots/pkg/kotsadm/types"
+	"github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	kotssnapshot "github.com/replicatedhq/kots/pkg/snapshot"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	velerolabel "github.com/vmware-tanzu/velero/pkg/label"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// CloneOfAppSlugAnnotation is set on clone restores to the slug of the app that was restored
+	CloneOfAppSlugAnnotation = "kots.io/clone-of-app-slug"
+	// CloneAppSlugAnnotation is set on clone restores to the slug the restored resources are labeled with
+	CloneAppSlugAnnotation = "kots.io/clone-app-slug"
+	// CloneRelabeledAnnotation is set on clone restores once the restored resources are labeled with the slug of the clone
+	CloneRelabeledAnnotation = "kots.io/clone-relabeled"
+)
+
+// GetCloneRestoreName returns the name of the restore that clones an app into the app slug of the clone
+func GetCloneRestoreName(snapshotName string, cloneAppSlug string) string {
+	return fmt.Sprintf("%s.%s", snapshotName, cloneAppSlug)
+}
+
+// ValidateCloneRestoreOptions returns an error if restoring an app from the backup with the options could overwrite the app, or anything else that was in the backup.
+// Namespaces that are not mapped are not restored, and the namespaces they are mapped to must not be in the backup.
+func ValidateCloneRestoreOptions(backup *velerov1.Backup, appSlug string, kotsadmNamespace string, opts types.CloneRestoreOptions) error {
+	if len(opts.NamespaceMapping) == 0 {
+		return errors.New("at least one namespace mapping is required")
+	}
+
+	if opts.AppSlug == "" {
+		return errors.New("an app slug for the clone is required")
+	}
+	if opts.AppSlug == appSlug {
+		return errors.New("the app slug of the clone must be different from the app slug of the app")
+	}
+	if errs := validation.IsDNS1123Label(opts.AppSlug); len(errs) > 0 {
+		return errors.Errorf("invalid app slug %q: %v", opts.AppSlug, errs)
+	}
+
+	backupNamespaces := map[string]bool{}
+	allNamespaces := false
+	for _, ns := range backup.Spec.IncludedNamespaces {
+		if ns == "*" {
+			allNamespaces = true
+		}
+		backupNamespaces[ns] = true
+	}
+
+	for source, target := range opts.NamespaceMapping {
+		if !allNamespaces && !backupNamespaces[source] {
+			return errors.Errorf("namespace %q is not in backup %s", source, backup.Name)
+		}
+		if errs := validation.IsDNS1123Label(target); len(errs) > 0 {
+			return errors.Errorf("invalid namespace %q: %v", target, errs)
+		}
+		if target == kotsadmNamespace {
+			return errors.Errorf("cannot restore into the admin console namespace %s", target)
+		}
+		if backupNamespaces[target] {
+			return errors.Errorf("cannot restore into namespace %q because it is in the backup", target)
+		}
+		if _, ok := opts.NamespaceMapping[target]; ok {
+			return errors.Errorf("cannot restore into namespace %q because it is restored into another namespace", target)
+		}
+	}
+
+	return nil
+}
+
+// buildCloneRestore returns a restore of the resources of an app into the mapped namespaces.
+// Cluster scoped resources are shared with the app, so they are not restored, and neither is the admin console.
+func buildCloneRestore(backup *velerov1.Backup, appSlug string, opts types.CloneRestoreOptions) *velerov1.Restore {
+	includedNamespaces := []string{}
+	for source := range opts.NamespaceMapping {
+		includedNamespaces = append(includedNamespaces, source)
+	}
+	sort.Strings(includedNamespaces)
+
+	labelSelector := &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{
+				Key:      kotsadmtypes.KotsadmKey,
+				Operator: metav1.LabelSelectorOpDoesNotExist,
+			},
+		},
+	}
+	if backup.Annotations["kots.io/instance"] == "true" {
+		// instance backups have the resources of every app
+		labelSelector.MatchLabels = map[string]string{
+			"kots.io/app-slug": appSlug,
+		}
+	}
+
+	return &velerov1.Restore{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: backup.Namespace,
+			Name:      GetCloneRestoreName(backup.Name, opts.AppSlug),
+			Annotations: map[string]string{
+				CloneOfAppSlugAnnotation: appSlug,
+				CloneAppSlugAnnotation:   opts.AppSlug,
+			},
+		},
+		Spec: velerov1.RestoreSpec{
+			BackupName:              backup.Name,
+			IncludedNamespaces:      includedNamespaces,
+			NamespaceMapping:        opts.NamespaceMapping,
+			LabelSelector:           labelSelector,
+			RestorePVs:              pointer.Bool(true),
+			IncludeClusterResources: pointer.Bool(false),
+		},
+	}
+}
+
+// CreateApplicationCloneRestore restores an app from a snapshot into other namespaces, without undeploying the app.
+// The restored resources are labeled with the app slug of the clone once the restore completes. The clone is not
+// an app in the admin console, which deploys every app into the same namespace, so it is not updated or backed up.
+func CreateApplicationCloneRestore(ctx context.Context, kotsadmNamespace string, snapshotName string, appSlug string, opts types.CloneRestoreOptions) (string, error) {
+	logger.Debug("creating clone restore",
+		zap.String("snapshotName", snapshotName),
+		zap.String("appSlug", appSlug),
+		zap.String("cloneAppSlug", opts.AppSlug))
+
+	cfg, err := k8sutil.GetClusterConfig()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	bsl, err := kotssnapshot.FindBackupStoreLocation(ctx, clientset, veleroClient, kotsadmNamespace)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get velero namespace")
+	}
+	if bsl == nil {
+		return "", errors.New("no backup store location found")
+	}
+
+	backup, err := veleroClient.Backups(bsl.Namespace).Get(ctx, snapshotName, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to find backup")
+	}
+
+	if err := ValidateCloneRestoreOptions(backup, appSlug, kotsadmNamespace, opts); err != nil {
+		return "", errors.Wrap(err, "invalid clone restore options")
+	}
+
+	restore := buildCloneRestore(backup, appSlug, opts)
+
+	if err := DeleteRestore(ctx, kotsadmNamespace, restore.Name); err != nil {
+		return "", errors.Wrap(err, "failed to delete previous restore")
+	}
+
+	if _, err := veleroClient.Restores(bsl.Namespace).Create(ctx, restore, metav1.CreateOptions{}); err != nil {
+		return "", errors.Wrap(err, "failed to create restore")
+	}
+
+	return restore.Name, nil
+}
+
+// RelabelCompletedCloneRestores labels the resources of the clone restores that completed with the app slug of their clone
+func RelabelCompletedCloneRestores(ctx context.Context, kotsadmNamespace string) error {
+	cfg, err := k8sutil.GetClusterConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	bsl, err := kotssnapshot.FindBackupStoreLocation(ctx, clientset, veleroClient, kotsadmNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to get velero namespace")
+	}
+	if bsl == nil {
+		return nil
+	}
+
+	restores, err := veleroClient.Restores(bsl.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list restores")
+	}
+
+	for _, restore := range restores.Items {
+		cloneAppSlug := restore.Annotations[CloneAppSlugAnnotation]
+		if cloneAppSlug == "" || restore.Annotations[CloneRelabeledAnnotation] == "true" {
+			continue
+		}
+		// resources of partially failed restores are relabeled too, so that they are not managed as the resources of the original app
+		if restore.Status.Phase != velerov1.RestorePhaseCompleted && restore.Status.Phase != velerov1.RestorePhasePartiallyFailed {
+			continue
+		}
+
+		if err := relabelRestoredResources(ctx, cfg, &restore, cloneAppSlug); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to relabel resources of restore %s", restore.Name))
+			continue
+		}
+
+		patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:"true"}}}`, CloneRelabeledAnnotation)
+		if _, err := veleroClient.Restores(bsl.Namespace).Patch(ctx, restore.Name, k8stypes.MergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to annotate restore %s", restore.Name)
+		}
+
+		logger.Infof("labeled the resources of restore %s with app slug %s", restore.Name, cloneAppSlug)
+	}
+
+	return nil
+}
+
+// relabelRestoredResources sets the app slug label and annotation of every resource a restore created in the namespaces it restored into,
+// so that the resources are not matched by the label selectors of the original app or mistaken for its resources
+func relabelRestoredResources(ctx context.Context, cfg *rest.Config, restore *velerov1.Restore, appSlug string) error {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create discovery client")
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create dynamic client")
+	}
+
+	// discovery fails for some groups when their api services are unavailable, the resources of the others are still relabeled
+	resourceLists, err := discoveryClient.ServerPreferredNamespacedResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return errors.Wrap(err, "failed to discover resources")
+	}
+
+	namespaces := []string{}
+	for _, target := range restore.Spec.NamespaceMapping {
+		namespaces = append(namespaces, target)
+	}
+
+	selector := fmt.Sprintf("velero.io/restore-name=%s", velerolabel.GetValidName(restore.Name))
+	patch := fmt.Sprintf(`{"metadata":{"labels":{"kots.io/app-slug":%q},"annotations":{"kots.io/app-slug":%q}}}`, appSlug, appSlug)
+
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse group version %s", resourceList.GroupVersion)
+		}
+
+		for _, resource := range resourceList.APIResources {
+			if !hasVerbs(resource.Verbs, "list", "patch") {
+				continue
+			}
+			gvr := gv.WithResource(resource.Name)
+
+			for _, ns := range namespaces {
+				items, err := dynamicClient.Resource(gvr).Namespace(ns).List(ctx, metav1.ListOptions{LabelSelector: selector})
+				if err != nil {
+					return errors.Wrapf(err, "failed to list %s in namespace %s", gvr.String(), ns)
+				}
+				for _, item := range items.Items {
+					if _, err := dynamicClient.Resource(gvr).Namespace(ns).Patch(ctx, item.GetName(), k8stypes.MergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+						return errors.Wrapf(err, "failed to label %s %s in namespace %s", gvr.String(), item.GetName(), ns)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func hasVerbs(verbs metav1.Verbs, required ...string) bool {
+	for _, r := range required {
+		found := false
+		for _, v := range verbs {
+			if v == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,213 @@
+package snapshot
+
+import (
+	"testing"
+
+	kotsadmtypes "github.com/replicatedhq/kots/pkg/kotsadm/types"
+	"github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	"github.com/stretchr/testify/assert"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestValidateCloneRestoreOptions(t *testing.T) {
+	backup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "instance-abcd",
+		},
+		Spec: velerov1.BackupSpec{
+			IncludedNamespaces: []string{"kotsadm", "app", "app-db"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		backup  *velerov1.Backup
+		opts    types.CloneRestoreOptions
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			backup: backup,
+			opts: types.CloneRestoreOptions{
+				NamespaceMapping: map[string]string{"app": "staging", "app-db": "staging-db"},
+				AppSlug:          "my-app-staging",
+			},
+		},
+		{
+			name:   "no namespace mapping",
+			backup: backup,
+			opts: types.CloneRestoreOptions{
+				AppSlug: "my-app-staging",
+			},
+			wantErr: "at least one namespace mapping is required",
+		},
+		{
+			name:   "no app slug",
+			backup: backup,
+			opts: types.CloneRestoreOptions{
+				NamespaceMapping: map[string]string{"app": "staging"},
+			},
+			wantErr: "an app slug for the clone is required",
+		},
+		{
+			name:   "same app slug",
+			backup: backup,
+			opts: types.CloneRestoreOptions{
+				NamespaceMapping: map[string]string{"app": "staging"},
+				AppSlug:          "my-app",
+			},
+			wantErr: "the app slug of the clone must be different from the app slug of the app",
+		},
+		{
+			name:   "namespace not in backup",
+			backup: backup,
+			opts: types.CloneRestoreOptions{
+				NamespaceMapping: map[string]string{"other": "staging"},
+				AppSlug:          "my-app-staging",
+			},
+			wantErr: `namespace "other" is not in backup instance-abcd`,
+		},
+		{
+			name: "any namespace in a backup of all namespaces",
+			backup: &velerov1.Backup{
+				Spec: velerov1.BackupSpec{
+					IncludedNamespaces: []string{"*"},
+				},
+			},
+			opts: types.CloneRestoreOptions{
+				NamespaceMapping: map[string]string{"other": "staging"},
+				AppSlug:          "my-app-staging",
+			},
+		},
+		{
+			name:   "into the admin console namespace",
+			backup: backup,
+			opts: types.CloneRestoreOptions{
+				NamespaceMapping: map[string]string{"app": "kotsadm"},
+				AppSlug:          "my-app-staging",
+			},
+			wantErr: "cannot restore into the admin console namespace kotsadm",
+		},
+		{
+			name:   "into a namespace in the backup",
+			backup: backup,
+			opts: types.CloneRestoreOptions{
+				NamespaceMapping: map[string]string{"app": "app-db"},
+				AppSlug:          "my-app-staging",
+			},
+			wantErr: `cannot restore into namespace "app-db" because it is in the backup`,
+		},
+		{
+			name:   "invalid namespace",
+			backup: backup,
+			opts: types.CloneRestoreOptions{
+				NamespaceMapping: map[string]string{"app": "Staging"},
+				AppSlug:          "my-app-staging",
+			},
+			wantErr: `invalid namespace "Staging"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCloneRestoreOptions(tt.backup, "my-app", "kotsadm", tt.opts)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_buildCloneRestore(t *testing.T) {
+	opts := types.CloneRestoreOptions{
+		NamespaceMapping: map[string]string{"app-db": "staging-db", "app": "staging"},
+		AppSlug:          "my-app-staging",
+	}
+
+	notKotsadm := []metav1.LabelSelectorRequirement{
+		{
+			Key:      kotsadmtypes.KotsadmKey,
+			Operator: metav1.LabelSelectorOpDoesNotExist,
+		},
+	}
+
+	tests := []struct {
+		name   string
+		backup *velerov1.Backup
+		want   *velerov1.Restore
+	}{
+		{
+			name: "instance backup",
+			backup: &velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "velero",
+					Name:      "instance-abcd",
+					Annotations: map[string]string{
+						"kots.io/instance": "true",
+					},
+				},
+			},
+			want: &velerov1.Restore{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "velero",
+					Name:      "instance-abcd.my-app-staging",
+					Annotations: map[string]string{
+						CloneOfAppSlugAnnotation: "my-app",
+						CloneAppSlugAnnotation:   "my-app-staging",
+					},
+				},
+				Spec: velerov1.RestoreSpec{
+					BackupName:         "instance-abcd",
+					IncludedNamespaces: []string{"app", "app-db"},
+					NamespaceMapping:   opts.NamespaceMapping,
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"kots.io/app-slug": "my-app",
+						},
+						MatchExpressions: notKotsadm,
+					},
+					RestorePVs:              pointer.Bool(true),
+					IncludeClusterResources: pointer.Bool(false),
+				},
+			},
+		},
+		{
+			name: "app backup",
+			backup: &velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "velero",
+					Name:      "my-app-abcd",
+				},
+			},
+			want: &velerov1.Restore{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "velero",
+					Name:      "my-app-abcd.my-app-staging",
+					Annotations: map[string]string{
+						CloneOfAppSlugAnnotation: "my-app",
+						CloneAppSlugAnnotation:   "my-app-staging",
+					},
+				},
+				Spec: velerov1.RestoreSpec{
+					BackupName:         "my-app-abcd",
+					IncludedNamespaces: []string{"app", "app-db"},
+					NamespaceMapping:   opts.NamespaceMapping,
+					LabelSelector: &metav1.LabelSelector{
+						MatchExpressions: notKotsadm,
+					},
+					RestorePVs:              pointer.Bool(true),
+					IncludeClusterResources: pointer.Bool(false),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildCloneRestore(tt.backup, "my-app", opts)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
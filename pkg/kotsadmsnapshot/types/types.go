@@ -28,6 +28,8 @@ type Backup struct {
 	VolumeSizeHuman    string     `json:"volumeSizeHuman"`
 	SupportBundleID    string     `json:"supportBundleId,omitempty"`
 	IncludedApps       []App      `json:"includedApps,omitempty"`
+	// Verification is the result of the last test restore of the backup, it's nil if the backup was never verified
+	Verification *BackupVerification `json:"verification,omitempty"`
 }
 
 type BackupDetail struct {
@@ -135,3 +137,39 @@ type ScheduledInstanceSnapshot struct {
 	// name of Backup CR will be set once scheduled
 	BackupName string `json:"backupName,omitempty"`
 }
+
+// CloneRestoreOptions restore an app from a snapshot next to the app instead of replacing it
+type CloneRestoreOptions struct {
+	// NamespaceMapping maps the namespaces the snapshot was taken from to the namespaces to restore into.
+	// Only the mapped namespaces are restored.
+	NamespaceMapping map[string]string `json:"namespaceMapping"`
+	// AppSlug is the app slug the restored resources are labeled with, so that they are not managed as the resources of the original app
+	AppSlug string `json:"appSlug"`
+}
+
+type BackupVerificationStatus string
+
+const (
+	BackupVerificationRunning BackupVerificationStatus = "running"
+	BackupVerificationPassed  BackupVerificationStatus = "passed"
+	BackupVerificationFailed  BackupVerificationStatus = "failed"
+)
+
+// BackupVerification is the result of restoring the apps of a backup into scratch namespaces
+type BackupVerification struct {
+	Status BackupVerificationStatus `json:"status"`
+	// Message explains why the verification failed
+	Message    string     `json:"message,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// BackupVerificationPolicy configures the scheduled verification of instance snapshots
+type BackupVerificationPolicy struct {
+	// Schedule is a cron spec to verify the latest unverified backup on, empty disables scheduled verification
+	Schedule string `json:"schedule"`
+	// RunAnalyzers runs the preflight analyzers of the apps once they are ready in the scratch namespaces
+	RunAnalyzers bool `json:"runAnalyzers"`
+	// Timeout is how long the apps have to restore and become ready, e.g. 30m. Empty uses the default
+	Timeout string `json:"timeout"`
+}
@@ -0,0 +1,610 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	"github.com/replicatedhq/kots/pkg/appstate"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/render"
+	kotssnapshot "github.com/replicatedhq/kots/pkg/snapshot"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/util"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	BackupVerificationStatusAnnotation     = "kots.io/verification-status"
+	BackupVerificationMessageAnnotation    = "kots.io/verification-message"
+	BackupVerificationStartedAtAnnotation  = "kots.io/verification-started-at"
+	BackupVerificationFinishedAtAnnotation = "kots.io/verification-finished-at"
+
+	// BackupVerificationLabel is set on scratch namespaces to the name of the backup that is restored into them
+	BackupVerificationLabel = "kots.io/backup-verification"
+
+	DefaultBackupVerificationTimeout = 30 * time.Minute
+)
+
+var ErrBackupVerificationInProgress = errors.New("a backup verification is already in progress")
+
+// verifyMtx makes sure that only one backup is restored into scratch namespaces at a time
+var verifyMtx sync.Mutex
+
+// AnalyzeFunc runs the analyzers of an app and returns an error if any of them fail
+type AnalyzeFunc func(a *apptypes.App, kotsKinds *kotsutil.KotsKinds) error
+
+type VerifyBackupOptions struct {
+	// Timeout is how long each app has to restore and become ready
+	Timeout time.Duration
+	// Analyze runs once the app is ready, nil skips the analyzers
+	Analyze AnalyzeFunc
+}
+
+// IsBackupVerificationInProgress returns true while a backup is restored into scratch namespaces
+func IsBackupVerificationInProgress() bool {
+	if !verifyMtx.TryLock() {
+		return true
+	}
+	verifyMtx.Unlock()
+	return false
+}
+
+// VerifyInstanceBackup restores every app in an instance backup into scratch namespaces, waits for its status informers to be ready, and optionally runs its analyzers.
+// The result is recorded on the backup and the scratch namespaces are deleted.
+// An error is returned only if the verification could not run, a failed verification is returned in the result.
+func VerifyInstanceBackup(ctx context.Context, kotsadmNamespace string, backupName string, opts VerifyBackupOptions) (*types.BackupVerification, error) {
+	if !verifyMtx.TryLock() {
+		return nil, ErrBackupVerificationInProgress
+	}
+	defer verifyMtx.Unlock()
+
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultBackupVerificationTimeout
+	}
+
+	cfg, err := k8sutil.GetClusterConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	bsl, err := kotssnapshot.FindBackupStoreLocation(ctx, clientset, veleroClient, kotsadmNamespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get velero namespace")
+	}
+	if bsl == nil {
+		return nil, errors.New("no backup store location found")
+	}
+
+	backup, err := veleroClient.Backups(bsl.Namespace).Get(ctx, backupName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backup")
+	}
+	if backup.Annotations["kots.io/instance"] != "true" {
+		return nil, errors.Errorf("backup %s is not an instance backup", backupName)
+	}
+	if backup.Status.Phase != velerov1.BackupPhaseCompleted {
+		return nil, errors.Errorf("backup %s is %s, only completed backups can be verified", backupName, backup.Status.Phase)
+	}
+
+	appsSequences := map[string]int64{}
+	if err := json.Unmarshal([]byte(backup.Annotations["kots.io/apps-sequences"]), &appsSequences); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal apps sequences")
+	}
+	appSlugs := []string{}
+	for appSlug := range appsSequences {
+		appSlugs = append(appSlugs, appSlug)
+	}
+	sort.Strings(appSlugs)
+
+	startedAt := time.Now()
+	verification := &types.BackupVerification{
+		Status:    types.BackupVerificationRunning,
+		StartedAt: &startedAt,
+	}
+	if err := setBackupVerification(ctx, veleroClient, backup, verification); err != nil {
+		return nil, errors.Wrap(err, "failed to record verification start")
+	}
+
+	failures := []string{}
+	for _, appSlug := range appSlugs {
+		logger.Info("verifying backup for app",
+			zap.String("backup", backupName),
+			zap.String("appSlug", appSlug))
+
+		if err := verifyAppInBackup(ctx, cfg, clientset, veleroClient, backup, kotsadmNamespace, appSlug, appsSequences[appSlug], opts); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to verify app %s in backup %s", appSlug, backupName))
+			failures = append(failures, fmt.Sprintf("%s: %s", appSlug, err.Error()))
+		}
+	}
+
+	finishedAt := time.Now()
+	verification.FinishedAt = &finishedAt
+	if len(failures) == 0 {
+		verification.Status = types.BackupVerificationPassed
+	} else {
+		verification.Status = types.BackupVerificationFailed
+		verification.Message = strings.Join(failures, "; ")
+	}
+	// the verification can take longer than the request that started it
+	if err := setBackupVerification(context.Background(), veleroClient, backup, verification); err != nil {
+		return nil, errors.Wrap(err, "failed to record verification result")
+	}
+
+	return verification, nil
+}
+
+// verifyAppInBackup restores an app from the backup into scratch namespaces and waits for it to be ready
+func verifyAppInBackup(ctx context.Context, cfg *rest.Config, clientset kubernetes.Interface, veleroClient veleroclientv1.VeleroV1Interface, backup *velerov1.Backup, kotsadmNamespace string, appSlug string, sequence int64, opts VerifyBackupOptions) error {
+	a, err := store.GetStore().GetAppFromSlug(appSlug)
+	if err != nil {
+		return errors.Wrap(err, "failed to get app from slug")
+	}
+
+	archiveDir, err := os.MkdirTemp("", "kotsadm")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temp dir")
+	}
+	defer os.RemoveAll(archiveDir)
+
+	if err := store.GetStore().GetAppVersionArchive(a.ID, sequence, archiveDir); err != nil {
+		return errors.Wrap(err, "failed to get app version archive")
+	}
+
+	kotsKinds, err := kotsutil.LoadKotsKinds(archiveDir)
+	if err != nil {
+		return errors.Wrap(err, "failed to load kotskinds")
+	}
+
+	id := strings.ToLower(rand.String(6))
+	appNamespace := util.AppNamespace()
+	appNamespaces := append([]string{appNamespace}, kotsKinds.KotsApplication.Spec.AdditionalNamespaces...)
+	namespaceMapping := getVerificationNamespaceMapping(backup.Spec.IncludedNamespaces, appNamespaces, id)
+
+	cloneOpts := types.CloneRestoreOptions{
+		NamespaceMapping: namespaceMapping,
+		AppSlug:          fmt.Sprintf("%s-verify-%s", appSlug, id),
+	}
+	if err := ValidateCloneRestoreOptions(backup, appSlug, kotsadmNamespace, cloneOpts); err != nil {
+		return errors.Wrap(err, "invalid verification restore")
+	}
+
+	informers, err := getVerificationStatusInformers(a, sequence, kotsKinds, appNamespace, namespaceMapping)
+	if err != nil {
+		return errors.Wrap(err, "failed to get status informers")
+	}
+
+	restore := buildCloneRestore(backup, appSlug, cloneOpts)
+	// the resources are relabeled here rather than by the operator, so that they are labeled before the app is checked
+	restore.Annotations[CloneRelabeledAnnotation] = "true"
+
+	defer func() {
+		// the scratch namespaces are deleted even if the verification timed out
+		if err := cleanupVerification(context.Background(), clientset, veleroClient, restore, namespaceMapping); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to clean up verification of app %s", appSlug))
+		}
+	}()
+
+	for _, ns := range namespaceMapping {
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: ns,
+				Labels: map[string]string{
+					BackupVerificationLabel: backup.Name,
+				},
+			},
+		}
+		if _, err := clientset.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to create namespace %s", ns)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	if _, err := veleroClient.Restores(restore.Namespace).Create(ctx, restore, metav1.CreateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to create restore")
+	}
+
+	if err := waitForVerificationRestore(ctx, veleroClient, restore.Namespace, restore.Name); err != nil {
+		return errors.Wrap(err, "failed to restore")
+	}
+
+	if err := relabelRestoredResources(ctx, cfg, restore, cloneOpts.AppSlug); err != nil {
+		return errors.Wrap(err, "failed to relabel restored resources")
+	}
+
+	if len(informers) > 0 {
+		dynamicClient, err := dynamic.NewForConfig(cfg)
+		if err != nil {
+			return errors.Wrap(err, "failed to create dynamic client")
+		}
+		if err := waitForStatusInformersReady(ctx, clientset, dynamicClient, a.ID, sequence, informers); err != nil {
+			return errors.Wrap(err, "app did not become ready")
+		}
+	}
+
+	if opts.Analyze != nil && kotsKinds.HasPreflights() {
+		if err := mapPreflightNamespaces(kotsKinds.Preflight, namespaceMapping); err != nil {
+			return errors.Wrap(err, "failed to map preflight namespaces")
+		}
+		if err := opts.Analyze(a, kotsKinds); err != nil {
+			return errors.Wrap(err, "analyzers failed")
+		}
+	}
+
+	return nil
+}
+
+// getVerificationNamespaceMapping maps the namespaces of an app that are in a backup to scratch namespaces.
+// A backup of all namespaces includes every namespace of the app.
+func getVerificationNamespaceMapping(backupNamespaces []string, appNamespaces []string, id string) map[string]string {
+	includedNamespaces := map[string]bool{}
+	for _, ns := range backupNamespaces {
+		includedNamespaces[ns] = true
+	}
+
+	namespaces := []string{}
+	seen := map[string]bool{}
+	for _, ns := range appNamespaces {
+		// additional namespaces can be a wildcard for all namespaces, which can't be restored next to the app
+		if ns == "" || ns == "*" || seen[ns] {
+			continue
+		}
+		if !includedNamespaces["*"] && !includedNamespaces[ns] {
+			continue
+		}
+		seen[ns] = true
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	mapping := map[string]string{}
+	for i, ns := range namespaces {
+		mapping[ns] = fmt.Sprintf("kots-verify-%s-%d", id, i)
+	}
+	return mapping
+}
+
+// getVerificationStatusInformers returns the status informers of the app at the backed up sequence, in the scratch namespaces
+func getVerificationStatusInformers(a *apptypes.App, sequence int64, kotsKinds *kotsutil.KotsKinds, appNamespace string, namespaceMapping map[string]string) ([]appstatetypes.StatusInformer, error) {
+	if len(kotsKinds.KotsApplication.Spec.StatusInformers) == 0 {
+		return nil, nil
+	}
+
+	registrySettings, err := store.GetStore().GetRegistryDetailsForApp(a.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get registry settings for app")
+	}
+
+	builder, err := render.NewBuilder(kotsKinds, registrySettings, a.Slug, sequence, a.IsAirgap, util.PodNamespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get template builder")
+	}
+
+	informerStrings := []appstatetypes.StatusInformerString{}
+	for _, informer := range kotsKinds.KotsApplication.Spec.StatusInformers {
+		renderedInformer, err := builder.String(informer)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to render status informer %s", informer)
+		}
+		if renderedInformer == "" {
+			continue
+		}
+		informerStrings = append(informerStrings, appstatetypes.StatusInformerString(renderedInformer))
+	}
+
+	return mapStatusInformers(informerStrings, appNamespace, namespaceMapping)
+}
+
+// mapStatusInformers moves status informers into the namespaces their namespace is restored into.
+// Informers without a namespace are in the app namespace.
+func mapStatusInformers(informerStrings []appstatetypes.StatusInformerString, appNamespace string, namespaceMapping map[string]string) ([]appstatetypes.StatusInformer, error) {
+	informers := []appstatetypes.StatusInformer{}
+	for _, s := range informerStrings {
+		informer, err := s.Parse()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse status informer %s", s)
+		}
+		if informer.Namespace == "" {
+			informer.Namespace = appNamespace
+		}
+		target, ok := namespaceMapping[informer.Namespace]
+		if !ok {
+			return nil, errors.Errorf("status informer %s is in namespace %s, which is not restored", s, informer.Namespace)
+		}
+		informer.Namespace = target
+		informers = append(informers, informer)
+	}
+	return informers, nil
+}
+
+// mapPreflightNamespaces moves the collectors and analyzers of a preflight spec into the namespaces their namespace is
+// restored into, so that the analyzers check the restored app rather than the app the backup was taken of.
+// Namespaces that are not restored, and collectors and analyzers without a namespace, are not changed.
+func mapPreflightNamespaces(preflight *troubleshootv1beta2.Preflight, namespaceMapping map[string]string) error {
+	b, err := json.Marshal(preflight.Spec)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal preflight spec")
+	}
+
+	spec := map[string]interface{}{}
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return errors.Wrap(err, "failed to unmarshal preflight spec")
+	}
+	mapSpecNamespaces(spec["collectors"], namespaceMapping)
+	mapSpecNamespaces(spec["analyzers"], namespaceMapping)
+
+	b, err = json.Marshal(spec)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal mapped preflight spec")
+	}
+
+	mappedSpec := troubleshootv1beta2.PreflightSpec{}
+	if err := json.Unmarshal(b, &mappedSpec); err != nil {
+		return errors.Wrap(err, "failed to unmarshal mapped preflight spec")
+	}
+	preflight.Spec = mappedSpec
+
+	return nil
+}
+
+// mapSpecNamespaces replaces the values of the namespace and namespaces fields found anywhere in a decoded spec
+func mapSpecNamespaces(v interface{}, namespaceMapping map[string]string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			switch key {
+			case "namespace":
+				if namespace, ok := value.(string); ok {
+					if target, ok := namespaceMapping[namespace]; ok {
+						v[key] = target
+					}
+					continue
+				}
+			case "namespaces":
+				if namespaces, ok := value.([]interface{}); ok {
+					for i, namespace := range namespaces {
+						if target, ok := namespaceMapping[fmt.Sprint(namespace)]; ok {
+							namespaces[i] = target
+						}
+					}
+					continue
+				}
+			}
+			mapSpecNamespaces(value, namespaceMapping)
+		}
+	case []interface{}:
+		for _, value := range v {
+			mapSpecNamespaces(value, namespaceMapping)
+		}
+	}
+}
+
+func waitForVerificationRestore(ctx context.Context, veleroClient veleroclientv1.VeleroV1Interface, veleroNamespace string, restoreName string) error {
+	for {
+		restore, err := veleroClient.Restores(veleroNamespace).Get(ctx, restoreName, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to get restore")
+		}
+
+		switch restore.Status.Phase {
+		case velerov1.RestorePhaseCompleted:
+			return nil
+		case velerov1.RestorePhaseFailed, velerov1.RestorePhasePartiallyFailed, velerov1.RestorePhaseFailedValidation:
+			return errors.Errorf("restore %s with %d errors and %d warnings", restore.Status.Phase, restore.Status.Errors, restore.Status.Warnings)
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "restore is %s", restore.Status.Phase)
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// waitForStatusInformersReady runs the status informers of an app until all of them are ready
+func waitForStatusInformersReady(ctx context.Context, clientset kubernetes.Interface, dynamicClient dynamic.Interface, appID string, sequence int64, informers []appstatetypes.StatusInformer) error {
+	// the informers have namespaces, so the target namespace of the monitor is not used
+	monitor := appstate.NewAppMonitor(clientset, dynamicClient, informers[0].Namespace, appID, sequence)
+	defer func() {
+		monitor.Shutdown()
+		// unblock the informers if they are sending a status that is no longer read
+		go func() {
+			for range monitor.AppStatusChan() {
+			}
+		}()
+	}()
+
+	monitor.Apply(informers)
+
+	state := appstatetypes.StateMissing
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "app is %s", state)
+		case status, ok := <-monitor.AppStatusChan():
+			if !ok {
+				return errors.New("app monitor stopped")
+			}
+			state = appstatetypes.GetState(status.ResourceStates)
+			if state == appstatetypes.StateReady {
+				return nil
+			}
+		}
+	}
+}
+
+// cleanupVerification deletes the restore and the scratch namespaces of a verification
+func cleanupVerification(ctx context.Context, clientset kubernetes.Interface, veleroClient veleroclientv1.VeleroV1Interface, restore *velerov1.Restore, namespaceMapping map[string]string) error {
+	err := veleroClient.Restores(restore.Namespace).Delete(ctx, restore.Name, metav1.DeleteOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete restore %s", restore.Name)
+	}
+
+	for _, ns := range namespaceMapping {
+		err := clientset.CoreV1().Namespaces().Delete(ctx, ns, metav1.DeleteOptions{})
+		if err != nil && !kuberneteserrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete namespace %s", ns)
+		}
+	}
+
+	return nil
+}
+
+// CleanupInterruptedBackupVerifications deletes the scratch namespaces left behind by verifications that did not finish, such as when kotsadm restarted,
+// and records those verifications as failed. It does nothing while a verification is running.
+func CleanupInterruptedBackupVerifications(ctx context.Context, kotsadmNamespace string) error {
+	if !verifyMtx.TryLock() {
+		return nil
+	}
+	defer verifyMtx.Unlock()
+
+	cfg, err := k8sutil.GetClusterConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: BackupVerificationLabel})
+	if err != nil {
+		return errors.Wrap(err, "failed to list verification namespaces")
+	}
+	for _, ns := range namespaces.Items {
+		if ns.DeletionTimestamp != nil {
+			continue
+		}
+		err := clientset.CoreV1().Namespaces().Delete(ctx, ns.Name, metav1.DeleteOptions{})
+		if err != nil && !kuberneteserrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete namespace %s", ns.Name)
+		}
+		logger.Infof("deleted namespace %s of interrupted verification of backup %s", ns.Name, ns.Labels[BackupVerificationLabel])
+	}
+
+	bsl, err := kotssnapshot.FindBackupStoreLocation(ctx, clientset, veleroClient, kotsadmNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to get velero namespace")
+	}
+	if bsl == nil {
+		return nil
+	}
+
+	backups, err := veleroClient.Backups(bsl.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list backups")
+	}
+	for i := range backups.Items {
+		backup := &backups.Items[i]
+		verification := GetBackupVerification(backup)
+		if verification == nil || verification.Status != types.BackupVerificationRunning {
+			continue
+		}
+		finishedAt := time.Now()
+		verification.Status = types.BackupVerificationFailed
+		verification.Message = "verification was interrupted"
+		verification.FinishedAt = &finishedAt
+		if err := setBackupVerification(ctx, veleroClient, backup, verification); err != nil {
+			return errors.Wrapf(err, "failed to record interrupted verification of backup %s", backup.Name)
+		}
+	}
+
+	return nil
+}
+
+// GetBackupVerification returns the result of the last verification of a backup, or nil if it was never verified
+func GetBackupVerification(backup *velerov1.Backup) *types.BackupVerification {
+	status := backup.Annotations[BackupVerificationStatusAnnotation]
+	if status == "" {
+		return nil
+	}
+
+	verification := &types.BackupVerification{
+		Status:  types.BackupVerificationStatus(status),
+		Message: backup.Annotations[BackupVerificationMessageAnnotation],
+	}
+	if t, err := time.Parse(time.RFC3339, backup.Annotations[BackupVerificationStartedAtAnnotation]); err == nil {
+		verification.StartedAt = &t
+	}
+	if t, err := time.Parse(time.RFC3339, backup.Annotations[BackupVerificationFinishedAtAnnotation]); err == nil {
+		verification.FinishedAt = &t
+	}
+	return verification
+}
+
+// getBackupVerificationAnnotations returns the annotations that record a verification on a backup, a nil value removes the annotation
+func getBackupVerificationAnnotations(verification *types.BackupVerification) map[string]interface{} {
+	annotations := map[string]interface{}{
+		BackupVerificationStatusAnnotation:     string(verification.Status),
+		BackupVerificationMessageAnnotation:    nil,
+		BackupVerificationStartedAtAnnotation:  nil,
+		BackupVerificationFinishedAtAnnotation: nil,
+	}
+	if verification.Message != "" {
+		annotations[BackupVerificationMessageAnnotation] = verification.Message
+	}
+	if verification.StartedAt != nil {
+		annotations[BackupVerificationStartedAtAnnotation] = verification.StartedAt.UTC().Format(time.RFC3339)
+	}
+	if verification.FinishedAt != nil {
+		annotations[BackupVerificationFinishedAtAnnotation] = verification.FinishedAt.UTC().Format(time.RFC3339)
+	}
+	return annotations
+}
+
+func setBackupVerification(ctx context.Context, veleroClient veleroclientv1.VeleroV1Interface, backup *velerov1.Backup, verification *types.BackupVerification) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": getBackupVerificationAnnotations(verification),
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal patch")
+	}
+
+	if _, err := veleroClient.Backups(backup.Namespace).Patch(ctx, backup.Name, k8stypes.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return errors.Wrap(err, "failed to patch backup")
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_getVerificationNamespaceMapping(t *testing.T) {
+	tests := []struct {
+		name             string
+		backupNamespaces []string
+		appNamespaces    []string
+		want             map[string]string
+	}{
+		{
+			name:             "app namespace",
+			backupNamespaces: []string{"kotsadm"},
+			appNamespaces:    []string{"kotsadm"},
+			want: map[string]string{
+				"kotsadm": "kots-verify-abcdef-0",
+			},
+		},
+		{
+			name:             "additional namespaces that are not in the backup",
+			backupNamespaces: []string{"kotsadm", "db"},
+			appNamespaces:    []string{"kotsadm", "web", "db", "db"},
+			want: map[string]string{
+				"db":      "kots-verify-abcdef-0",
+				"kotsadm": "kots-verify-abcdef-1",
+			},
+		},
+		{
+			name:             "backup of all namespaces",
+			backupNamespaces: []string{"*"},
+			appNamespaces:    []string{"kotsadm", "*", "web"},
+			want: map[string]string{
+				"kotsadm": "kots-verify-abcdef-0",
+				"web":     "kots-verify-abcdef-1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getVerificationNamespaceMapping(tt.backupNamespaces, tt.appNamespaces, "abcdef")
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_mapStatusInformers(t *testing.T) {
+	namespaceMapping := map[string]string{
+		"kotsadm": "kots-verify-abcdef-0",
+		"db":      "kots-verify-abcdef-1",
+	}
+
+	tests := []struct {
+		name            string
+		informerStrings []appstatetypes.StatusInformerString
+		want            []appstatetypes.StatusInformer
+		wantErr         bool
+	}{
+		{
+			name:            "informers with and without a namespace",
+			informerStrings: []appstatetypes.StatusInformerString{"deployment/web", "db/statefulset/postgres"},
+			want: []appstatetypes.StatusInformer{
+				{Kind: "deployment", Name: "web", Namespace: "kots-verify-abcdef-0"},
+				{Kind: "statefulset", Name: "postgres", Namespace: "kots-verify-abcdef-1"},
+			},
+		},
+		{
+			name:            "informer in a namespace that is not restored",
+			informerStrings: []appstatetypes.StatusInformerString{"other/deployment/web"},
+			wantErr:         true,
+		},
+		{
+			name:            "invalid informer",
+			informerStrings: []appstatetypes.StatusInformerString{"web"},
+			wantErr:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mapStatusInformers(tt.informerStrings, "kotsadm", namespaceMapping)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_mapPreflightNamespaces(t *testing.T) {
+	namespaceMapping := map[string]string{
+		"kotsadm": "kots-verify-abcdef-0",
+		"db":      "kots-verify-abcdef-1",
+	}
+
+	preflight := &troubleshootv1beta2.Preflight{
+		Spec: troubleshootv1beta2.PreflightSpec{
+			Collectors: []*troubleshootv1beta2.Collect{
+				{
+					ClusterResources: &troubleshootv1beta2.ClusterResources{
+						Namespaces: []string{"kotsadm", "db", "kube-system"},
+					},
+				},
+				{
+					Logs: &troubleshootv1beta2.Logs{
+						Name:      "db/logs",
+						Namespace: "db",
+						Selector:  []string{"app=postgres"},
+					},
+				},
+			},
+			Analyzers: []*troubleshootv1beta2.Analyze{
+				{
+					DeploymentStatus: &troubleshootv1beta2.DeploymentStatus{
+						Name:      "web",
+						Namespace: "kotsadm",
+					},
+				},
+				{
+					ClusterPodStatuses: &troubleshootv1beta2.ClusterPodStatuses{
+						Namespaces: []string{"db"},
+					},
+				},
+				{
+					DeploymentStatus: &troubleshootv1beta2.DeploymentStatus{
+						Name:      "coredns",
+						Namespace: "kube-system",
+					},
+				},
+			},
+		},
+	}
+
+	err := mapPreflightNamespaces(preflight, namespaceMapping)
+	require.NoError(t, err)
+
+	collectors := preflight.Spec.Collectors
+	require.Len(t, collectors, 2)
+	assert.Equal(t, []string{"kots-verify-abcdef-0", "kots-verify-abcdef-1", "kube-system"}, collectors[0].ClusterResources.Namespaces)
+	assert.Equal(t, "kots-verify-abcdef-1", collectors[1].Logs.Namespace)
+	assert.Equal(t, "db/logs", collectors[1].Logs.Name)
+	assert.Equal(t, []string{"app=postgres"}, collectors[1].Logs.Selector)
+
+	analyzers := preflight.Spec.Analyzers
+	require.Len(t, analyzers, 3)
+	assert.Equal(t, "kots-verify-abcdef-0", analyzers[0].DeploymentStatus.Namespace)
+	assert.Equal(t, "web", analyzers[0].DeploymentStatus.Name)
+	assert.Equal(t, []string{"kots-verify-abcdef-1"}, analyzers[1].ClusterPodStatuses.Namespaces)
+	assert.Equal(t, "kube-system", analyzers[2].DeploymentStatus.Namespace)
+}
+
+func TestGetBackupVerification(t *testing.T) {
+	startedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	finishedAt := startedAt.Add(10 * time.Minute)
+
+	tests := []struct {
+		name         string
+		verification *types.BackupVerification
+	}{
+		{
+			name: "running",
+			verification: &types.BackupVerification{
+				Status:    types.BackupVerificationRunning,
+				StartedAt: &startedAt,
+			},
+		},
+		{
+			name: "failed",
+			verification: &types.BackupVerification{
+				Status:     types.BackupVerificationFailed,
+				Message:    "my-app: app did not become ready",
+				StartedAt:  &startedAt,
+				FinishedAt: &finishedAt,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a merge patch removes the annotations with nil values
+			annotations := map[string]string{}
+			for k, v := range getBackupVerificationAnnotations(tt.verification) {
+				if v != nil {
+					annotations[k] = v.(string)
+				}
+			}
+			backup := &velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: annotations,
+				},
+			}
+			assert.Equal(t, tt.verification, GetBackupVerification(backup))
+		})
+	}
+
+	assert.Nil(t, GetBackupVerification(&velerov1.Backup{}))
+}
@@ -179,10 +179,16 @@ func DownloadUpdate(appID string, update types.Update, skipPreflights bool, skip
 		appSequence = *update.AppSequence
 	}
 
-	latestLicense, err := store.GetStore().GetLatestLicenseForApp(a.ID)
-	if err != nil {
-		finalError = errors.Wrap(err, "failed to get latest license")
-		return
+	// helm charts and git repositories don't have a license
+	isUnlicensedUpstream := util.IsUnlicensedUpstreamURI(a.UpstreamURI)
+
+	var latestLicense *kotsv1beta1.License
+	if !isUnlicensedUpstream {
+		latestLicense, err = store.GetStore().GetLatestLicenseForApp(a.ID)
+		if err != nil {
+			finalError = errors.Wrap(err, "failed to get latest license")
+			return
+		}
 	}
 
 	identityConfigFile := filepath.Join(archiveDir, "upstream", "userdata", "identityconfig.yaml")
@@ -249,7 +255,12 @@ func DownloadUpdate(appID string, update types.Update, skipPreflights bool, skip
 		pullOptions.NoProxyEnvValue = os.Getenv("no_proxy")
 	}
 
-	_, err = pull.Pull(fmt.Sprintf("replicated://%s", beforeKotsKinds.License.Spec.AppSlug), pullOptions)
+	upstreamURI := a.UpstreamURI
+	if !isUnlicensedUpstream {
+		upstreamURI = fmt.Sprintf("replicated://%s", beforeKotsKinds.License.Spec.AppSlug)
+	}
+
+	_, err = pull.Pull(upstreamURI, pullOptions)
 	if err != nil {
 		if errors.Cause(err) != pull.ErrConfigNeeded {
 			finalError = errors.Wrap(err, "failed to pull")
@@ -26,6 +26,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/kurl"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/secretprovider"
 	"github.com/replicatedhq/kots/pkg/util"
 	kotsv1beta1 "github.com/replicatedhq/kotskinds/apis/kots/v1beta1"
 	kotsv1beta2 "github.com/replicatedhq/kotskinds/apis/kots/v1beta2"
@@ -178,10 +179,12 @@ func (k *KotsKinds) EncryptConfigValues() error {
 				return errors.Errorf("Cannot encrypt item %q because item type was %q (not password)", name, configItemType)
 			}
 
-			encrypted := crypto.Encrypt([]byte(configValue.ValuePlaintext))
-			encoded := base64.StdEncoding.EncodeToString(encrypted)
+			encrypted, err := secretprovider.EncryptConfigValue(context.TODO(), configValue.ValuePlaintext)
+			if err != nil {
+				return errors.Wrapf(err, "failed to encrypt item %q", name)
+			}
 
-			configValue.Value = encoded
+			configValue.Value = encrypted
 			configValue.ValuePlaintext = ""
 
 			updated[name] = configValue
@@ -0,0 +1,225 @@
+package maintenancewindow
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/maintenancewindow/types"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/version"
+	cron "github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// Validate returns an error if any of the windows can't be parsed
+func Validate(windows []types.MaintenanceWindow) error {
+	for i, w := range windows {
+		if _, _, err := parseWindow(w); err != nil {
+			return errors.Wrapf(err, "invalid maintenance window %d", i)
+		}
+	}
+	return nil
+}
+
+// IsOpen returns true if t is inside any of the windows. Apps without windows are always open.
+func IsOpen(windows []types.MaintenanceWindow, t time.Time) (bool, error) {
+	if len(windows) == 0 {
+		return true, nil
+	}
+
+	for _, w := range windows {
+		schedule, duration, err := parseWindow(w)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to parse maintenance window")
+		}
+
+		// the first window that opens after t-duration is still open at t if it opened before t
+		start := schedule.Next(t.Add(-duration))
+		if !start.After(t) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// NextOpen returns the time the next window opens after t, or t if a window is currently open
+func NextOpen(windows []types.MaintenanceWindow, t time.Time) (*time.Time, error) {
+	isOpen, err := IsOpen(windows, t)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check if window is open")
+	}
+	if isOpen {
+		return &t, nil
+	}
+
+	var next *time.Time
+	for _, w := range windows {
+		schedule, _, err := parseWindow(w)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse maintenance window")
+		}
+		start := schedule.Next(t)
+		if start.IsZero() {
+			continue
+		}
+		if next == nil || start.Before(*next) {
+			next = &start
+		}
+	}
+
+	return next, nil
+}
+
+func parseWindow(w types.MaintenanceWindow) (cron.Schedule, time.Duration, error) {
+	duration, err := time.ParseDuration(w.Duration)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to parse duration")
+	}
+	if duration <= 0 {
+		return nil, 0, errors.New("duration must be positive")
+	}
+
+	spec := w.Schedule
+	if w.Timezone != "" {
+		if _, err := time.LoadLocation(w.Timezone); err != nil {
+			return nil, 0, errors.Wrap(err, "failed to load timezone")
+		}
+		spec = "CRON_TZ=" + w.Timezone + " " + spec
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to parse schedule")
+	}
+
+	return schedule, duration, nil
+}
+
+// QueueIfClosed queues the deploy if the app's maintenance windows are closed and returns true if it was queued
+func QueueIfClosed(appID string, clusterID string, sequence int64, source string) (bool, error) {
+	windows, err := store.GetStore().GetAppMaintenanceWindows(appID)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get maintenance windows")
+	}
+
+	isOpen, err := IsOpen(windows, time.Now())
+	if err != nil {
+		return false, errors.Wrap(err, "failed to check maintenance windows")
+	}
+	if isOpen {
+		return false, nil
+	}
+
+	queuedDeploy := types.QueuedDeploy{
+		AppID:     appID,
+		ClusterID: clusterID,
+		Sequence:  sequence,
+		Source:    source,
+		QueuedAt:  time.Now(),
+	}
+	if err := store.GetStore().SetQueuedDeploy(queuedDeploy); err != nil {
+		return false, errors.Wrap(err, "failed to queue deploy")
+	}
+
+	logger.Info("deploy queued until the next maintenance window",
+		zap.String("appID", appID),
+		zap.Int64("sequence", sequence),
+		zap.String("source", source))
+
+	return true, nil
+}
+
+// GetQueuedDeploy returns the queued deploy for an app and when it will run, or nil if no deploy is queued
+func GetQueuedDeploy(appID string, clusterID string) (*types.QueuedDeploy, error) {
+	queuedDeploy, err := store.GetStore().GetQueuedDeploy(appID, clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get queued deploy")
+	}
+	if queuedDeploy == nil {
+		return nil, nil
+	}
+
+	windows, err := store.GetStore().GetAppMaintenanceWindows(appID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get maintenance windows")
+	}
+
+	queuedDeploy.NextWindowAt, err = NextOpen(windows, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get next maintenance window")
+	}
+
+	return queuedDeploy, nil
+}
+
+func Start() {
+	logger.Debug("starting deploy queue")
+
+	go func() {
+		for {
+			processQueue()
+			time.Sleep(time.Minute)
+		}
+	}()
+}
+
+func processQueue() {
+	queuedDeploys, err := store.GetStore().ListQueuedDeploys()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list queued deploys"))
+		return
+	}
+
+	for _, queuedDeploy := range queuedDeploys {
+		if err := processQueuedDeploy(queuedDeploy); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to process queued deploy of sequence %d for app %s", queuedDeploy.Sequence, queuedDeploy.AppID))
+		}
+	}
+}
+
+func processQueuedDeploy(queuedDeploy types.QueuedDeploy) error {
+	windows, err := store.GetStore().GetAppMaintenanceWindows(queuedDeploy.AppID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get maintenance windows")
+	}
+
+	isOpen, err := IsOpen(windows, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "failed to check maintenance windows")
+	}
+	if !isOpen {
+		return nil
+	}
+
+	// remove it from the queue first so that a deploy that fails validation is not retried forever
+	if err := store.GetStore().DeleteQueuedDeploy(queuedDeploy.AppID, queuedDeploy.ClusterID); err != nil {
+		return errors.Wrap(err, "failed to delete queued deploy")
+	}
+
+	currentSequence, err := store.GetStore().GetCurrentDownstreamSequence(queuedDeploy.AppID, queuedDeploy.ClusterID)
+	if store.GetStore().IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to get current downstream sequence")
+	}
+	if queuedDeploy.Sequence <= currentSequence {
+		// a newer version was deployed since, deploying the queued version would roll the app back
+		logger.Info("skipping queued deploy of a version that is not newer than the deployed version",
+			zap.String("appID", queuedDeploy.AppID),
+			zap.Int64("sequence", queuedDeploy.Sequence),
+			zap.Int64("currentSequence", currentSequence))
+		return nil
+	}
+
+	logger.Info("maintenance window is open, deploying queued version",
+		zap.String("appID", queuedDeploy.AppID),
+		zap.Int64("sequence", queuedDeploy.Sequence))
+
+	if err := version.DeployVersion(queuedDeploy.AppID, queuedDeploy.Sequence); err != nil {
+		return errors.Wrap(err, "failed to deploy version")
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package maintenancewindow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kots/pkg/maintenancewindow/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		windows []types.MaintenanceWindow
+		wantErr bool
+	}{
+		{
+			name:    "no windows",
+			windows: nil,
+		},
+		{
+			name: "valid",
+			windows: []types.MaintenanceWindow{
+				{Schedule: "0 2 * * 6", Duration: "4h", Timezone: "America/New_York"},
+				{Schedule: "30 22 * * 1-5", Duration: "90m"},
+			},
+		},
+		{
+			name:    "invalid schedule",
+			windows: []types.MaintenanceWindow{{Schedule: "every saturday", Duration: "4h"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid duration",
+			windows: []types.MaintenanceWindow{{Schedule: "0 2 * * 6", Duration: "four hours"}},
+			wantErr: true,
+		},
+		{
+			name:    "zero duration",
+			windows: []types.MaintenanceWindow{{Schedule: "0 2 * * 6", Duration: "0s"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid timezone",
+			windows: []types.MaintenanceWindow{{Schedule: "0 2 * * 6", Duration: "4h", Timezone: "Mars/Olympus_Mons"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.windows)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestIsOpen(t *testing.T) {
+	// saturdays from 02:00 to 06:00 in new york
+	windows := []types.MaintenanceWindow{
+		{Schedule: "0 2 * * 6", Duration: "4h", Timezone: "America/New_York"},
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		windows []types.MaintenanceWindow
+		t       time.Time
+		want    bool
+	}{
+		{
+			name:    "no windows is always open",
+			windows: nil,
+			t:       time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC),
+			want:    true,
+		},
+		{
+			name:    "before window",
+			windows: windows,
+			t:       time.Date(2024, 3, 16, 1, 59, 0, 0, newYork),
+			want:    false,
+		},
+		{
+			name:    "start of window",
+			windows: windows,
+			t:       time.Date(2024, 3, 16, 2, 0, 0, 0, newYork),
+			want:    true,
+		},
+		{
+			name:    "inside window in another timezone",
+			windows: windows,
+			t:       time.Date(2024, 3, 16, 8, 0, 0, 0, time.UTC),
+			want:    true,
+		},
+		{
+			name:    "end of window",
+			windows: windows,
+			t:       time.Date(2024, 3, 16, 6, 0, 0, 0, newYork),
+			want:    false,
+		},
+		{
+			name:    "weekday",
+			windows: windows,
+			t:       time.Date(2024, 3, 13, 3, 0, 0, 0, newYork),
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsOpen(tt.windows, tt.t)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNextOpen(t *testing.T) {
+	windows := []types.MaintenanceWindow{
+		{Schedule: "0 2 * * 6", Duration: "4h"},
+		{Schedule: "0 22 * * 3", Duration: "1h"},
+	}
+
+	// open now
+	now := time.Date(2024, 3, 16, 3, 0, 0, 0, time.UTC)
+	got, err := NextOpen(windows, now)
+	require.NoError(t, err)
+	assert.Equal(t, now, *got)
+
+	// the earliest of the next windows
+	now = time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC)
+	got, err = NextOpen(windows, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 13, 22, 0, 0, 0, time.UTC), got.UTC())
+
+	now = time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)
+	got, err = NextOpen(windows, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 16, 2, 0, 0, 0, time.UTC), got.UTC())
+}
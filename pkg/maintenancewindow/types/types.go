@@ -0,0 +1,28 @@
+package types
+
+import "time"
+
+const (
+	DeploySourceAutoDeploy = "auto-deploy"
+	DeploySourceCLI        = "cli"
+	DeploySourceAPI        = "api"
+)
+
+// MaintenanceWindow is a recurring time range in which automatic and CLI deploys are allowed.
+// Schedule is a standard cron expression for when the window opens, evaluated in Timezone.
+type MaintenanceWindow struct {
+	Schedule string `json:"schedule"`
+	Duration string `json:"duration"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// QueuedDeploy is a deploy that was requested outside of a maintenance window.
+// There is at most one queued deploy per app and downstream, later requests replace earlier ones.
+type QueuedDeploy struct {
+	AppID        string     `json:"appId"`
+	ClusterID    string     `json:"clusterId"`
+	Sequence     int64      `json:"sequence"`
+	Source       string     `json:"source"`
+	QueuedAt     time.Time  `json:"queuedAt"`
+	NextWindowAt *time.Time `json:"nextWindowAt,omitempty"`
+}
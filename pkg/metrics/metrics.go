@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+)
+
+const namespace = "kotsadm"
+
+// ServerPort is the port the metrics are served on. It's separate from the admin console api port so that
+// the metrics are only reachable from inside the cluster and never through the admin console proxy.
+const ServerPort = 3040
+
+const (
+	UpdateCheckOutcomeUpdatesAvailable = "updates_available"
+	UpdateCheckOutcomeNoUpdates        = "no_updates"
+	UpdateCheckOutcomeError            = "error"
+
+	DeployStatusDeployed = "deployed"
+	DeployStatusFailed   = "failed"
+
+	SnapshotOutcomeCompleted = "completed"
+	SnapshotOutcomeFailed    = "failed"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	updateCheckDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "update_check_duration_seconds",
+		Help:      "Duration of update checks by app and outcome.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"app_id", "outcome"})
+
+	deployDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "deploy_duration_seconds",
+		Help:      "Duration of app deployments by app and status.",
+		Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200},
+	}, []string{"app_id", "status"})
+
+	appState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "app_state",
+		Help:      "Current state of an app. The series for the current state is 1.",
+	}, []string{"app_id", "state"})
+
+	resourceState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "app_resource_state",
+		Help:      "Current state of a resource monitored by an app status informer. The series for the current state is 1.",
+	}, []string{"app_id", "kind", "namespace", "name", "state"})
+
+	airgapUploadBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "airgap_upload_bytes_total",
+		Help:      "Total number of airgap bundle bytes uploaded.",
+	})
+
+	snapshots = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "snapshots_total",
+		Help:      "Number of finished snapshots by outcome.",
+	}, []string{"outcome"})
+)
+
+// SessionCounter is the subset of the kots store used to report session metrics
+type SessionCounter interface {
+	CountActiveSessions() (int64, error)
+}
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		updateCheckDuration,
+		deployDuration,
+		appState,
+		resourceState,
+		airgapUploadBytes,
+		snapshots,
+	)
+}
+
+// Init registers the metrics that are read from the store when scraped
+func Init(sessionCounter SessionCounter) {
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_sessions",
+		Help:      "Number of active admin console sessions.",
+	}, func() float64 {
+		count, err := sessionCounter.CountActiveSessions()
+		if err != nil {
+			logger.Errorf("failed to count active sessions: %v", err)
+			return 0
+		}
+		return float64(count)
+	}))
+}
+
+// Handler returns the http handler that serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// StartServer serves the metrics on ServerPort
+func StartServer() {
+	go func() {
+		r := mux.NewRouter()
+		r.Path("/metrics").Methods("GET").Handler(Handler())
+
+		srv := &http.Server{
+			Handler: r,
+			Addr:    fmt.Sprintf(":%d", ServerPort),
+		}
+
+		logger.Debugf("Starting metrics server on port %d...\n", ServerPort)
+
+		err := srv.ListenAndServe()
+		logger.Error(errors.Wrap(err, "failed to run metrics server"))
+	}()
+}
+
+func ObserveUpdateCheck(appID string, outcome string, duration time.Duration) {
+	updateCheckDuration.WithLabelValues(appID, outcome).Observe(duration.Seconds())
+}
+
+func ObserveDeploy(appID string, status string, duration time.Duration) {
+	deployDuration.WithLabelValues(appID, status).Observe(duration.Seconds())
+}
+
+// SetAppStatus replaces the app and resource state series of an app with its current status
+func SetAppStatus(appID string, resourceStates appstatetypes.ResourceStates) {
+	appState.DeletePartialMatch(prometheus.Labels{"app_id": appID})
+	appState.WithLabelValues(appID, string(appstatetypes.GetState(resourceStates))).Set(1)
+
+	resourceState.DeletePartialMatch(prometheus.Labels{"app_id": appID})
+	for _, rs := range resourceStates {
+		resourceState.WithLabelValues(appID, rs.Kind, rs.Namespace, rs.Name, string(rs.State)).Set(1)
+	}
+}
+
+func AddAirgapUploadBytes(n int64) {
+	airgapUploadBytes.Add(float64(n))
+}
+
+func IncSnapshot(outcome string) {
+	snapshots.WithLabelValues(outcome).Inc()
+}
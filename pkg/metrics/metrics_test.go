@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAppStatus(t *testing.T) {
+	SetAppStatus("app-1", appstatetypes.ResourceStates{
+		{Kind: "deployment", Namespace: "default", Name: "web", State: appstatetypes.StateReady},
+		{Kind: "service", Namespace: "default", Name: "web", State: appstatetypes.StateUnavailable},
+	})
+	SetAppStatus("app-2", appstatetypes.ResourceStates{
+		{Kind: "deployment", Namespace: "default", Name: "api", State: appstatetypes.StateReady},
+	})
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(appState.WithLabelValues("app-1", string(appstatetypes.StateUnavailable))))
+	assert.Equal(t, 3, testutil.CollectAndCount(resourceState))
+
+	// the previous state series of an app are replaced, other apps are unchanged
+	SetAppStatus("app-1", appstatetypes.ResourceStates{
+		{Kind: "deployment", Namespace: "default", Name: "web", State: appstatetypes.StateReady},
+	})
+
+	assert.Equal(t, 2, testutil.CollectAndCount(appState))
+	assert.Equal(t, 1.0, testutil.ToFloat64(appState.WithLabelValues("app-1", string(appstatetypes.StateReady))))
+	assert.Equal(t, 2, testutil.CollectAndCount(resourceState))
+}
+
+func TestObserveUpdateCheck(t *testing.T) {
+	ObserveUpdateCheck("app-1", UpdateCheckOutcomeNoUpdates, time.Second)
+	ObserveUpdateCheck("app-1", UpdateCheckOutcomeError, time.Second)
+	ObserveUpdateCheck("app-1", UpdateCheckOutcomeError, time.Second)
+
+	assert.Equal(t, 2, testutil.CollectAndCount(updateCheckDuration))
+}
@@ -0,0 +1,199 @@
+package notifications
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/notifications/types"
+	"github.com/segmentio/ksuid"
+)
+
+// Store is the subset of the kots store used to send notifications
+type Store interface {
+	GetApp(appID string) (*apptypes.App, error)
+	ListWebhookTargets() ([]types.WebhookTarget, error)
+	CreateWebhookDelivery(delivery types.WebhookDelivery) error
+}
+
+var (
+	dispatcherMtx sync.Mutex
+	dispatcher    *Dispatcher
+)
+
+// Start starts the global dispatcher. Until it's started, Notify is a no-op so that packages can
+// emit events unconditionally, including from tests and the CLI.
+func Start(store Store) {
+	dispatcherMtx.Lock()
+	defer dispatcherMtx.Unlock()
+
+	if dispatcher != nil {
+		return
+	}
+
+	dispatcher = NewDispatcher(store, NewWebhookSender())
+	go dispatcher.Run()
+}
+
+// Notify queues an event to be sent to all matching webhook targets.
+func Notify(event types.Event) {
+	dispatcherMtx.Lock()
+	d := dispatcher
+	dispatcherMtx.Unlock()
+
+	if d == nil {
+		return
+	}
+
+	d.Enqueue(event)
+}
+
+// targetQueueSize is the number of events that can be queued for a single webhook target
+const targetQueueSize = 100
+
+type Dispatcher struct {
+	store  Store
+	sender *WebhookSender
+	events chan types.Event
+
+	workersMtx sync.Mutex
+	workers    map[string]*targetWorker
+	workersWg  sync.WaitGroup
+
+	dropped atomic.Uint64
+}
+
+// targetWorker sends the events queued for a single webhook target, so that a target that is slow or down
+// only delays its own deliveries
+type targetWorker struct {
+	queue chan targetEvent
+}
+
+type targetEvent struct {
+	target types.WebhookTarget
+	event  types.Event
+}
+
+func NewDispatcher(store Store, sender *WebhookSender) *Dispatcher {
+	return &Dispatcher{
+		store:   store,
+		sender:  sender,
+		events:  make(chan types.Event, 100),
+		workers: map[string]*targetWorker{},
+	}
+}
+
+// Enqueue adds an event to the queue. Events are dropped if the queue is full so that callers are never blocked.
+func (d *Dispatcher) Enqueue(event types.Event) {
+	if event.ID == "" {
+		event.ID = ksuid.New().String()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+
+	select {
+	case d.events <- event:
+	default:
+		d.dropped.Add(1)
+		logger.Errorf("notifications queue is full, dropping %s event %s (%d events dropped)", event.Type, event.ID, d.dropped.Load())
+	}
+}
+
+// Dropped returns the number of events and deliveries that were dropped because a queue was full
+func (d *Dispatcher) Dropped() uint64 {
+	return d.dropped.Load()
+}
+
+func (d *Dispatcher) Run() {
+	for event := range d.events {
+		if err := d.Dispatch(event); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to dispatch %s event %s", event.Type, event.ID))
+		}
+	}
+}
+
+// Dispatch queues an event for all matching webhook targets. Each target has its own worker and queue, retries
+// happen on the worker, and a delivery is dropped if the queue of its target is full.
+func (d *Dispatcher) Dispatch(event types.Event) error {
+	if event.AppSlug == "" && event.AppID != "" {
+		a, err := d.store.GetApp(event.AppID)
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to get app %s for notification", event.AppID))
+		} else {
+			event.AppSlug = a.Slug
+		}
+	}
+
+	targets, err := d.store.ListWebhookTargets()
+	if err != nil {
+		return errors.Wrap(err, "failed to list webhook targets")
+	}
+
+	d.workersMtx.Lock()
+	defer d.workersMtx.Unlock()
+
+	// stop the workers of targets that were deleted
+	listed := map[string]bool{}
+	for _, target := range targets {
+		listed[target.ID] = true
+	}
+	for targetID, worker := range d.workers {
+		if !listed[targetID] {
+			close(worker.queue)
+			delete(d.workers, targetID)
+		}
+	}
+
+	for _, target := range targets {
+		if !target.Matches(event.Type) {
+			continue
+		}
+
+		worker, ok := d.workers[target.ID]
+		if !ok {
+			worker = d.startWorker()
+			d.workers[target.ID] = worker
+		}
+
+		select {
+		case worker.queue <- targetEvent{target: target, event: event}:
+		default:
+			d.dropped.Add(1)
+			logger.Errorf("notifications queue of webhook target %s is full, dropping %s event %s (%d events dropped)", target.ID, event.Type, event.ID, d.dropped.Load())
+		}
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) startWorker() *targetWorker {
+	worker := &targetWorker{
+		queue: make(chan targetEvent, targetQueueSize),
+	}
+
+	d.workersWg.Add(1)
+	go func() {
+		defer d.workersWg.Done()
+		for e := range worker.queue {
+			d.sender.Send(e.target, e.event, d.store.CreateWebhookDelivery)
+		}
+	}()
+
+	return worker
+}
+
+// Close stops the workers once they have sent the events that are already queued for their targets
+func (d *Dispatcher) Close() {
+	d.workersMtx.Lock()
+	for targetID, worker := range d.workers {
+		close(worker.queue)
+		delete(d.workers, targetID)
+	}
+	d.workersMtx.Unlock()
+
+	d.workersWg.Wait()
+}
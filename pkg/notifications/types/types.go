@@ -0,0 +1,95 @@
+package types
+
+import (
+	"time"
+)
+
+type EventType string
+
+const (
+	EventVersionAvailable  EventType = "version.available"
+	EventDeploySucceeded   EventType = "deploy.succeeded"
+	EventDeployFailed      EventType = "deploy.failed"
+	EventAppStatusChanged  EventType = "app.status.changed"
+	EventSnapshotCompleted EventType = "snapshot.completed"
+	EventSnapshotFailed    EventType = "snapshot.failed"
+	EventPreflightComplete EventType = "preflight.completed"
+	EventWebhookTest       EventType = "webhook.test"
+)
+
+var AllEventTypes = []EventType{
+	EventVersionAvailable,
+	EventDeploySucceeded,
+	EventDeployFailed,
+	EventAppStatusChanged,
+	EventSnapshotCompleted,
+	EventSnapshotFailed,
+	EventPreflightComplete,
+}
+
+func IsValidEventType(eventType EventType) bool {
+	for _, t := range AllEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is the JSON payload sent to webhook targets
+type Event struct {
+	ID        string                 `json:"id"`
+	Type      EventType              `json:"type"`
+	CreatedAt time.Time              `json:"createdAt"`
+	AppID     string                 `json:"appId,omitempty"`
+	AppSlug   string                 `json:"appSlug,omitempty"`
+	Sequence  *int64                 `json:"sequence,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+type WebhookTarget struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Secret  string `json:"-"`
+	Enabled bool   `json:"enabled"`
+	// Events is the list of event types sent to this target. An empty list means all events.
+	Events    []EventType `json:"events"`
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+}
+
+// Matches returns true if the event should be sent to this target
+func (t WebhookTarget) Matches(eventType EventType) bool {
+	if !t.Enabled {
+		return false
+	}
+	if eventType == EventWebhookTest || len(t.Events) == 0 {
+		return true
+	}
+	for _, e := range t.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery records a single attempt to deliver an event to a webhook target
+type WebhookDelivery struct {
+	ID         string    `json:"id"`
+	TargetID   string    `json:"targetId"`
+	EventID    string    `json:"eventId"`
+	EventType  EventType `json:"eventType"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Success    bool      `json:"success"`
+	Payload    string    `json:"payload"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type WebhookDeliveries struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	TotalCount int               `json:"totalCount"`
+}
@@ -0,0 +1,135 @@
+package notifications
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/notifications/types"
+)
+
+const (
+	SignatureHeader = "X-Kots-Signature-256"
+	EventHeader     = "X-Kots-Event"
+	DeliveryHeader  = "X-Kots-Delivery"
+)
+
+type WebhookSender struct {
+	HTTPClient     *http.Client
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func NewWebhookSender() *WebhookSender {
+	return &WebhookSender{
+		HTTPClient:     &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts:    5,
+		InitialBackoff: 2 * time.Second,
+		MaxBackoff:     time.Minute,
+	}
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of the payload, prefixed with "sha256=".
+// Receivers should compute the same signature with the shared secret and compare it to the X-Kots-Signature-256 header.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send delivers an event to a webhook target, retrying with exponential backoff on failure.
+// Every attempt is passed to recordDelivery. The last attempt is returned.
+func (s *WebhookSender) Send(target types.WebhookTarget, event types.Event, recordDelivery func(types.WebhookDelivery) error) types.WebhookDelivery {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		// this should never happen, but record it so it's visible in the delivery history
+		delivery := types.WebhookDelivery{
+			TargetID:  target.ID,
+			EventID:   event.ID,
+			EventType: event.Type,
+			Attempt:   1,
+			Error:     errors.Wrap(err, "failed to marshal event").Error(),
+			CreatedAt: time.Now(),
+		}
+		s.record(recordDelivery, delivery)
+		return delivery
+	}
+
+	backoff := s.InitialBackoff
+	var delivery types.WebhookDelivery
+	for attempt := 1; attempt <= s.MaxAttempts; attempt++ {
+		delivery = s.sendOnce(target, event, payload, attempt)
+		s.record(recordDelivery, delivery)
+
+		if delivery.Success || attempt == s.MaxAttempts {
+			break
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > s.MaxBackoff {
+			backoff = s.MaxBackoff
+		}
+	}
+
+	return delivery
+}
+
+func (s *WebhookSender) sendOnce(target types.WebhookTarget, event types.Event, payload []byte, attempt int) types.WebhookDelivery {
+	delivery := types.WebhookDelivery{
+		TargetID:  target.ID,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Attempt:   attempt,
+		Payload:   string(payload),
+		CreatedAt: time.Now(),
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target.URL, bytes.NewReader(payload))
+	if err != nil {
+		delivery.Error = errors.Wrap(err, "failed to create request").Error()
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "KOTS-Webhook")
+	req.Header.Set(EventHeader, string(event.Type))
+	req.Header.Set(DeliveryHeader, event.ID)
+	if target.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(target.Secret, payload))
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		delivery.Error = errors.Wrap(err, "failed to execute request").Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1024*1024))
+
+	delivery.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		delivery.Error = fmt.Sprintf("unexpected status code %d", resp.StatusCode)
+		return delivery
+	}
+
+	delivery.Success = true
+	return delivery
+}
+
+func (s *WebhookSender) record(recordDelivery func(types.WebhookDelivery) error, delivery types.WebhookDelivery) {
+	if recordDelivery == nil {
+		return
+	}
+	if err := recordDelivery(delivery); err != nil {
+		logger.Error(errors.Wrapf(err, "failed to record delivery of event %s to webhook %s", delivery.EventID, delivery.TargetID))
+	}
+}
@@ -0,0 +1,209 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	"github.com/replicatedhq/kots/pkg/notifications/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSender() *WebhookSender {
+	return &WebhookSender{
+		HTTPClient:     http.DefaultClient,
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"id":"1"}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=6146142a2ce0159e84c0767881e4ec80bc397da62526e7d19f70795eb79460c0", Sign("secret", []byte(`{"id":"1"}`)))
+	assert.NotEqual(t, Sign("secret", []byte(`{"id":"1"}`)), Sign("other", []byte(`{"id":"1"}`)))
+}
+
+func TestWebhookSender_Send(t *testing.T) {
+	event := types.Event{
+		ID:      "event-id",
+		Type:    types.EventDeployFailed,
+		AppSlug: "my-app",
+		Data:    map[string]interface{}{"error": "boom"},
+	}
+
+	t.Run("signs the payload", func(t *testing.T) {
+		var gotBody []byte
+		var gotHeaders http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotHeaders = r.Header
+			gotBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		deliveries := []types.WebhookDelivery{}
+		target := types.WebhookTarget{ID: "target-id", URL: server.URL, Secret: "secret", Enabled: true}
+		delivery := testSender().Send(target, event, func(d types.WebhookDelivery) error {
+			deliveries = append(deliveries, d)
+			return nil
+		})
+
+		assert.True(t, delivery.Success)
+		assert.Equal(t, http.StatusNoContent, delivery.StatusCode)
+		require.Len(t, deliveries, 1)
+
+		assert.Equal(t, Sign("secret", gotBody), gotHeaders.Get(SignatureHeader))
+		assert.Equal(t, "deploy.failed", gotHeaders.Get(EventHeader))
+		assert.Equal(t, "event-id", gotHeaders.Get(DeliveryHeader))
+
+		gotEvent := types.Event{}
+		require.NoError(t, json.Unmarshal(gotBody, &gotEvent))
+		assert.Equal(t, "my-app", gotEvent.AppSlug)
+		assert.Equal(t, "boom", gotEvent.Data["error"])
+	})
+
+	t.Run("retries until success", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		deliveries := []types.WebhookDelivery{}
+		target := types.WebhookTarget{ID: "target-id", URL: server.URL, Enabled: true}
+		delivery := testSender().Send(target, event, func(d types.WebhookDelivery) error {
+			deliveries = append(deliveries, d)
+			return nil
+		})
+
+		assert.True(t, delivery.Success)
+		assert.Equal(t, 3, delivery.Attempt)
+		require.Len(t, deliveries, 3)
+		assert.False(t, deliveries[0].Success)
+		assert.Equal(t, http.StatusServiceUnavailable, deliveries[0].StatusCode)
+		assert.Equal(t, "unexpected status code 503", deliveries[0].Error)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		target := types.WebhookTarget{ID: "target-id", URL: server.URL, Enabled: true}
+		delivery := testSender().Send(target, event, nil)
+
+		assert.False(t, delivery.Success)
+		assert.Equal(t, 3, calls)
+	})
+}
+
+type fakeStore struct {
+	mtx        sync.Mutex
+	targets    []types.WebhookTarget
+	deliveries []types.WebhookDelivery
+}
+
+func (s *fakeStore) GetApp(appID string) (*apptypes.App, error) {
+	return &apptypes.App{ID: appID, Slug: "my-app"}, nil
+}
+
+func (s *fakeStore) ListWebhookTargets() ([]types.WebhookTarget, error) {
+	return s.targets, nil
+}
+
+func (s *fakeStore) CreateWebhookDelivery(delivery types.WebhookDelivery) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	received := map[string][]types.Event{}
+	var mtx sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := types.Event{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		mtx.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], event)
+		mtx.Unlock()
+	}))
+	defer server.Close()
+
+	store := &fakeStore{
+		targets: []types.WebhookTarget{
+			{ID: "all", URL: server.URL + "/all", Enabled: true},
+			{ID: "deploys", URL: server.URL + "/deploys", Enabled: true, Events: []types.EventType{types.EventDeploySucceeded, types.EventDeployFailed}},
+			{ID: "disabled", URL: server.URL + "/disabled", Enabled: false},
+		},
+	}
+	d := NewDispatcher(store, testSender())
+
+	require.NoError(t, d.Dispatch(types.Event{ID: "1", Type: types.EventVersionAvailable, AppID: "app-id"}))
+	require.NoError(t, d.Dispatch(types.Event{ID: "2", Type: types.EventDeployFailed, AppID: "app-id"}))
+	d.Close()
+
+	require.Len(t, received["/all"], 2)
+	assert.Equal(t, "my-app", received["/all"][0].AppSlug)
+	require.Len(t, received["/deploys"], 1)
+	assert.Equal(t, types.EventDeployFailed, received["/deploys"][0].Type)
+	assert.Empty(t, received["/disabled"])
+	assert.Len(t, store.deliveries, 3)
+}
+
+func TestDispatcher_Dispatch_slowTarget(t *testing.T) {
+	release := make(chan struct{})
+	var fastCount atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release
+			return
+		}
+		fastCount.Add(1)
+	}))
+	defer server.Close()
+
+	store := &fakeStore{
+		targets: []types.WebhookTarget{
+			{ID: "slow", URL: server.URL + "/slow", Enabled: true},
+			{ID: "fast", URL: server.URL + "/fast", Enabled: true},
+		},
+	}
+	d := NewDispatcher(store, testSender())
+
+	// the slow target blocks on its first delivery, fills its queue and then drops deliveries,
+	// while the fast target still receives every event
+	events := targetQueueSize + 5
+	for i := 0; i < events; i++ {
+		require.NoError(t, d.Dispatch(types.Event{ID: fmt.Sprint(i), Type: types.EventDeployFailed}))
+		require.Eventually(t, func() bool {
+			return fastCount.Load() == int32(i+1)
+		}, 5*time.Second, time.Millisecond)
+	}
+	assert.NotZero(t, d.Dropped())
+
+	close(release)
+	d.Close()
+}
+
+func TestNotify_notStarted(t *testing.T) {
+	// must not block or panic when the dispatcher has not been started
+	Notify(types.Event{Type: types.EventDeploySucceeded})
+}
@@ -93,14 +93,18 @@ func CreateAppFromOnline(opts CreateOnlineAppOpts) (_ *kotsutil.KotsKinds, final
 		pipeReader.CloseWithError(scanner.Err())
 	}()
 
-	// put the license in a temp file
-	licenseFile, err := ioutil.TempFile("", "kotsadm")
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create tmp file for license")
-	}
-	defer os.RemoveAll(licenseFile.Name())
-	if err := ioutil.WriteFile(licenseFile.Name(), []byte(opts.PendingApp.LicenseData), 0644); err != nil {
-		return nil, errors.Wrap(err, "failed to write license tmp file")
+	// put the license in a temp file. helm charts and git repositories are installed without a license.
+	licenseFile := ""
+	if opts.PendingApp.LicenseData != "" {
+		tmpFile, err := ioutil.TempFile("", "kotsadm")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create tmp file for license")
+		}
+		defer os.RemoveAll(tmpFile.Name())
+		if err := ioutil.WriteFile(tmpFile.Name(), []byte(opts.PendingApp.LicenseData), 0644); err != nil {
+			return nil, errors.Wrap(err, "failed to write license tmp file")
+		}
+		licenseFile = tmpFile.Name()
 	}
 
 	// pull to a tmp dir
@@ -142,7 +146,7 @@ func CreateAppFromOnline(opts CreateOnlineAppOpts) (_ *kotsutil.KotsKinds, final
 	// for the application, and then delete it.
 	pullOptions := pull.PullOptions{
 		Downstreams:            []string{"this-cluster"},
-		LicenseFile:            licenseFile.Name(),
+		LicenseFile:            licenseFile,
 		Namespace:              appNamespace,
 		ExcludeKotsKinds:       true,
 		RootDir:                tmpRoot,
@@ -0,0 +1,7 @@
+package applier
+
+type KubectlInterface interface {
+	Apply(targetNamespace string, slug string, yamlDoc []byte, dryRun bool, annotateSlug bool) ([]byte, []byte, error)
+	Remove(targetNamespace string, yamlDoc []byte, wait bool) ([]byte, []byte, error)
+	ApplyCreateOrPatch(targetNamespace string, slug string, yamlDoc []byte, dryRun bool, annotateSlug bool) ([]byte, []byte, error)
+}
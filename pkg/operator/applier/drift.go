@@ -0,0 +1,193 @@
+package applier
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// metadata fields that are set by the api server and change without anyone editing the object
+var ignoredDriftMetadataFields = []string{
+	"managedFields",
+	"resourceVersion",
+	"generation",
+	"creationTimestamp",
+	"uid",
+	"selfLink",
+}
+
+// DetectDrift compares every object in the yaml document with its live object and returns the objects that drifted.
+// The desired state is the result of a server-side dry-run apply as the kots field manager, so defaulted fields and
+// fields that are owned by other managers are not reported. The reported fields are exactly the ones the next
+// deploy would revert.
+func (c *ServerSideApplier) DetectDrift(targetNamespace string, slug string, yamlDoc []byte, annotateSlug bool) ([]operatortypes.DriftedResource, error) {
+	objs, err := decodeObjects(yamlDoc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode objects")
+	}
+
+	drifted := []operatortypes.DriftedResource{}
+	for _, obj := range objs {
+		if annotateSlug {
+			if err := annotateAppSlug(obj, slug); err != nil {
+				return nil, errors.Wrapf(err, "failed to annotate %s %s", obj.GetKind(), obj.GetName())
+			}
+		}
+
+		result, err := c.detectObjectDrift(context.TODO(), obj, targetNamespace)
+		if err != nil {
+			result.Reason = operatortypes.DriftReasonError
+			result.Error = err.Error()
+		}
+		if result.Reason != "" {
+			drifted = append(drifted, result)
+		}
+	}
+
+	return drifted, nil
+}
+
+// detectObjectDrift returns a result without a reason if the object did not drift
+func (c *ServerSideApplier) detectObjectDrift(ctx context.Context, obj *unstructured.Unstructured, targetNamespace string) (operatortypes.DriftedResource, error) {
+	result := newDriftedResource(obj)
+
+	dr, err := c.resourceInterface(obj, targetNamespace)
+	if err != nil {
+		return result, err
+	}
+	result.Namespace = obj.GetNamespace()
+
+	live, err := dr.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		result.Reason = operatortypes.DriftReasonMissing
+		return result, nil
+	} else if err != nil {
+		return result, errors.Wrap(err, "failed to get live object")
+	}
+
+	desired, err := dr.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+		FieldManager: FieldManager,
+		Force:        true,
+		DryRun:       []string{metav1.DryRunAll},
+	})
+	if err != nil {
+		return result, errors.Wrap(err, "failed to dry run apply")
+	}
+
+	fields := driftedFields(live.Object, desired.Object)
+	if len(fields) == 0 {
+		return result, nil
+	}
+
+	result.Reason = operatortypes.DriftReasonModified
+	result.Fields = fields
+	result.Managers = driftedFieldManagers(live, fields)
+
+	return result, nil
+}
+
+// driftedFields returns the paths of the fields that differ between the live and the desired object.
+// Lists are compared as a whole and reported by the path of the list.
+func driftedFields(live map[string]interface{}, desired map[string]interface{}) []string {
+	live = normalizeForDrift(live)
+	desired = normalizeForDrift(desired)
+
+	fields := []string{}
+	diffFields(live, desired, nil, &fields)
+	sort.Strings(fields)
+
+	return fields
+}
+
+func normalizeForDrift(obj map[string]interface{}) map[string]interface{} {
+	obj = (&unstructured.Unstructured{Object: obj}).DeepCopy().Object
+	delete(obj, "status")
+	for _, field := range ignoredDriftMetadataFields {
+		unstructured.RemoveNestedField(obj, "metadata", field)
+	}
+	return obj
+}
+
+func diffFields(a interface{}, b interface{}, path []string, fields *[]string) {
+	aMap, aIsMap := a.(map[string]interface{})
+	bMap, bIsMap := b.(map[string]interface{})
+	if !aIsMap || !bIsMap {
+		if !reflect.DeepEqual(a, b) {
+			*fields = append(*fields, strings.Join(path, "."))
+		}
+		return
+	}
+
+	keys := map[string]bool{}
+	for k := range aMap {
+		keys[k] = true
+	}
+	for k := range bMap {
+		keys[k] = true
+	}
+
+	for k := range keys {
+		diffFields(aMap[k], bMap[k], append(append([]string{}, path...), k), fields)
+	}
+}
+
+// driftedFieldManagers returns the field managers other than kots that own any of the fields in the live object
+func driftedFieldManagers(live *unstructured.Unstructured, fields []string) []string {
+	managers := map[string]bool{}
+	for _, entry := range live.GetManagedFields() {
+		if entry.Manager == FieldManager || entry.FieldsV1 == nil {
+			continue
+		}
+
+		owned := map[string]interface{}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &owned); err != nil {
+			continue
+		}
+
+		for _, field := range fields {
+			if ownsField(owned, strings.Split(field, ".")) {
+				managers[entry.Manager] = true
+				break
+			}
+		}
+	}
+
+	result := []string{}
+	for manager := range managers {
+		result = append(result, manager)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// ownsField returns true if the fieldsV1 set contains the path or any field below it
+func ownsField(owned map[string]interface{}, path []string) bool {
+	if len(path) == 0 {
+		return true
+	}
+	next, ok := owned["f:"+path[0]].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	return ownsField(next, path[1:])
+}
+
+func newDriftedResource(obj *unstructured.Unstructured) operatortypes.DriftedResource {
+	gvk := obj.GroupVersionKind()
+	return operatortypes.DriftedResource{
+		Group:     gvk.Group,
+		Version:   gvk.Version,
+		Kind:      gvk.Kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}
+}
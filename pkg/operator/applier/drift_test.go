@@ -0,0 +1,114 @@
+package applier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_driftedFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		live    map[string]interface{}
+		desired map[string]interface{}
+		want    []string
+	}{
+		{
+			name: "no drift",
+			live: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "app", "resourceVersion": "2", "generation": int64(2)},
+				"spec":     map[string]interface{}{"replicas": int64(1)},
+				"status":   map[string]interface{}{"readyReplicas": int64(1)},
+			},
+			desired: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "app", "resourceVersion": "3", "generation": int64(3)},
+				"spec":     map[string]interface{}{"replicas": int64(1)},
+				"status":   map[string]interface{}{"readyReplicas": int64(0)},
+			},
+			want: []string{},
+		},
+		{
+			name: "modified, added and removed fields",
+			live: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":   "app",
+					"labels": map[string]interface{}{"app": "app", "edited": "true"},
+				},
+				"spec": map[string]interface{}{"replicas": int64(3)},
+			},
+			desired: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":   "app",
+					"labels": map[string]interface{}{"app": "app"},
+				},
+				"spec": map[string]interface{}{"replicas": int64(1), "paused": true},
+			},
+			want: []string{"metadata.labels.edited", "spec.paused", "spec.replicas"},
+		},
+		{
+			name: "lists are compared as a whole",
+			live: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"ports": []interface{}{map[string]interface{}{"port": int64(8080)}},
+				},
+			},
+			desired: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"ports": []interface{}{map[string]interface{}{"port": int64(80)}},
+				},
+			},
+			want: []string{"spec.ports"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, driftedFields(tt.live, tt.desired))
+		})
+	}
+}
+
+func Test_driftedFieldManagers(t *testing.T) {
+	live := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	live.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{
+			Manager:  FieldManager,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{},"f:template":{}}}`)},
+		},
+		{
+			Manager:  "kubectl-edit",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+		},
+		{
+			Manager:  "kube-controller-manager",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:readyReplicas":{}}}`)},
+		},
+		{
+			Manager:  "kubectl-label",
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:edited":{}}}}`)},
+		},
+	})
+
+	tests := []struct {
+		name   string
+		fields []string
+		want   []string
+	}{
+		{
+			name:   "other managers own the fields",
+			fields: []string{"metadata.labels.edited", "spec.replicas"},
+			want:   []string{"kubectl-edit", "kubectl-label"},
+		},
+		{
+			name:   "fields that nobody else owns",
+			fields: []string{"spec.template"},
+			want:   []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, driftedFieldManagers(live, tt.fields))
+		})
+	}
+}
@@ -0,0 +1,451 @@
+package applier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/logger"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery"
+	memory "k8s.io/client-go/discovery/cached"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/util/csaupgrade"
+)
+
+const (
+	// FieldManager is the field manager used for all server-side apply requests made by kots
+	FieldManager = "kots"
+
+	AppSlugAnnotation = "kots.io/app-slug"
+
+	deletionPollInterval = 2 * time.Second
+	deletionTimeout      = 10 * time.Minute
+)
+
+var (
+	// client-side field managers that kots used when it shelled out to kubectl.
+	// ownership of their fields is migrated to the kots field manager on first apply
+	// so that fields removed from the manifests are also removed from the live objects.
+	clientSideFieldManagers = sets.New("kubectl-client-side-apply", "kubectl-create", "kubectl-patch")
+
+	// these match the kustomize commonAnnotations field specs that were used to annotate the app slug
+	slugAnnotationTemplatePaths = map[schema.GroupKind][][]string{
+		{Group: "", Kind: "ReplicationController"}: {{"spec", "template", "metadata", "annotations"}},
+		{Group: "apps", Kind: "Deployment"}:        {{"spec", "template", "metadata", "annotations"}},
+		{Group: "apps", Kind: "ReplicaSet"}:        {{"spec", "template", "metadata", "annotations"}},
+		{Group: "apps", Kind: "DaemonSet"}:         {{"spec", "template", "metadata", "annotations"}},
+		{Group: "apps", Kind: "StatefulSet"}:       {{"spec", "template", "metadata", "annotations"}},
+		{Group: "batch", Kind: "Job"}:              {{"spec", "template", "metadata", "annotations"}},
+		{Group: "batch", Kind: "CronJob"}: {
+			{"spec", "jobTemplate", "metadata", "annotations"},
+			{"spec", "jobTemplate", "spec", "template", "metadata", "annotations"},
+		},
+	}
+)
+
+// ServerSideApplier implements KubectlInterface natively using the dynamic client and server-side apply
+type ServerSideApplier struct {
+	dynamicClient dynamic.Interface
+	restMapper    meta.RESTMapper
+}
+
+var _ KubectlInterface = (*ServerSideApplier)(nil)
+
+func NewServerSideApplier(config *rest.Config) (*ServerSideApplier, error) {
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create dynamic client")
+	}
+
+	disc, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create discovery client")
+	}
+
+	return &ServerSideApplier{
+		dynamicClient: dynamicClient,
+		restMapper:    restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(disc)),
+	}, nil
+}
+
+func NewServerSideApplierWithClients(dynamicClient dynamic.Interface, restMapper meta.RESTMapper) *ServerSideApplier {
+	return &ServerSideApplier{
+		dynamicClient: dynamicClient,
+		restMapper:    restMapper,
+	}
+}
+
+func (c *ServerSideApplier) Apply(targetNamespace string, slug string, yamlDoc []byte, dryRun bool, annotateSlug bool) ([]byte, []byte, error) {
+	results, err := c.ApplyResources(targetNamespace, slug, yamlDoc, dryRun, annotateSlug)
+	stdout, stderr := FormatResults(results)
+	if err != nil {
+		return stdout, append(stderr, []byte(err.Error())...), err
+	}
+	return stdout, stderr, nil
+}
+
+// ApplyCreateOrPatch exists for compatibility with the kubectl based applier. Server-side apply
+// does not store the last applied configuration in an annotation, so the document never has to
+// be split up or fall back to create and patch.
+func (c *ServerSideApplier) ApplyCreateOrPatch(targetNamespace string, slug string, yamlDoc []byte, dryRun bool, annotateSlug bool) ([]byte, []byte, error) {
+	return c.Apply(targetNamespace, slug, yamlDoc, dryRun, annotateSlug)
+}
+
+func (c *ServerSideApplier) Remove(targetNamespace string, yamlDoc []byte, wait bool) ([]byte, []byte, error) {
+	results, err := c.RemoveResources(targetNamespace, yamlDoc, wait)
+	stdout, stderr := FormatResults(results)
+	if err != nil {
+		return stdout, append(stderr, []byte(err.Error())...), err
+	}
+	return stdout, stderr, nil
+}
+
+// ApplyResources server-side applies every object in the yaml document and returns a result per object.
+// It stops at the first object that fails to apply. Conflicts with other field managers are forced
+// (matching the previous kubectl apply behavior) but are reported in the result.
+// Dry runs are server-side applies with all stages dry run, so they are validated and admitted by the api server without being persisted.
+func (c *ServerSideApplier) ApplyResources(targetNamespace string, slug string, yamlDoc []byte, dryRun bool, annotateSlug bool) ([]operatortypes.ResourceResult, error) {
+	objs, err := decodeObjects(yamlDoc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode objects")
+	}
+
+	results := []operatortypes.ResourceResult{}
+	for _, obj := range objs {
+		if annotateSlug {
+			if err := annotateAppSlug(obj, slug); err != nil {
+				return results, errors.Wrapf(err, "failed to annotate %s %s", obj.GetKind(), obj.GetName())
+			}
+		}
+
+		result, err := c.applyObject(context.TODO(), obj, targetNamespace, dryRun)
+		results = append(results, result)
+		if err != nil {
+			return results, errors.Wrapf(err, "failed to apply %s", result.String())
+		}
+	}
+
+	return results, nil
+}
+
+// RemoveResources deletes every object in the yaml document. Objects that do not exist are not treated as errors.
+func (c *ServerSideApplier) RemoveResources(targetNamespace string, yamlDoc []byte, wait bool) ([]operatortypes.ResourceResult, error) {
+	objs, err := decodeObjects(yamlDoc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode objects")
+	}
+
+	results := []operatortypes.ResourceResult{}
+	var finalErr error
+	for _, obj := range objs {
+		result, err := c.removeObject(context.TODO(), obj, targetNamespace, wait)
+		results = append(results, result)
+		if err != nil {
+			// keep going, like kubectl delete does
+			finalErr = errors.Wrapf(err, "failed to delete %s", result.String())
+		}
+	}
+
+	return results, finalErr
+}
+
+func (c *ServerSideApplier) applyObject(ctx context.Context, obj *unstructured.Unstructured, targetNamespace string, dryRun bool) (operatortypes.ResourceResult, error) {
+	result := newResourceResult(obj)
+	result.DryRun = dryRun
+
+	dr, err := c.resourceInterface(obj, targetNamespace)
+	if err != nil {
+		result.Operation = operatortypes.ResourceOperationFailed
+		result.Error = err.Error()
+		return result, err
+	}
+	result.Namespace = obj.GetNamespace()
+
+	existing, err := dr.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		result.Operation = operatortypes.ResourceOperationFailed
+		result.Error = err.Error()
+		return result, errors.Wrap(err, "failed to get existing object")
+	}
+	if kuberneteserrors.IsNotFound(err) {
+		existing = nil
+	}
+
+	if existing != nil && !dryRun {
+		if err := c.upgradeClientSideManagedFields(ctx, dr, existing); err != nil {
+			// not fatal, the object will still be applied but fields removed from the manifest may be left behind
+			logger.Infof("failed to migrate client-side managed fields for %s: %v", result.String(), err)
+		}
+	}
+
+	applyOptions := metav1.ApplyOptions{FieldManager: FieldManager}
+	if dryRun {
+		applyOptions.DryRun = []string{metav1.DryRunAll}
+	}
+
+	applied, err := dr.Apply(ctx, obj.GetName(), obj, applyOptions)
+	if kuberneteserrors.IsConflict(err) {
+		result.Conflicts = conflictsFromError(err)
+		logger.Infof("%s has conflicts with other field managers, forcing apply", result.String())
+		applyOptions.Force = true
+		applied, err = dr.Apply(ctx, obj.GetName(), obj, applyOptions)
+	}
+	if err != nil {
+		result.Operation = operatortypes.ResourceOperationFailed
+		result.Error = err.Error()
+		return result, err
+	}
+
+	switch {
+	case existing == nil:
+		result.Operation = operatortypes.ResourceOperationCreated
+	case dryRun && isUnchangedByDryRun(existing, applied):
+		result.Operation = operatortypes.ResourceOperationUnchanged
+	case !dryRun && applied.GetResourceVersion() == existing.GetResourceVersion():
+		result.Operation = operatortypes.ResourceOperationUnchanged
+	default:
+		result.Operation = operatortypes.ResourceOperationConfigured
+	}
+
+	return result, nil
+}
+
+// isUnchangedByDryRun compares the live object to the result of a dry run apply. Dry runs are never persisted,
+// so the resource version can't be used to tell if the apply would have changed anything.
+func isUnchangedByDryRun(existing *unstructured.Unstructured, applied *unstructured.Unstructured) bool {
+	existing, applied = existing.DeepCopy(), applied.DeepCopy()
+	for _, obj := range []*unstructured.Unstructured{existing, applied} {
+		obj.SetManagedFields(nil)
+		obj.SetResourceVersion("")
+		obj.SetGeneration(0)
+	}
+	return equality.Semantic.DeepEqual(existing.Object, applied.Object)
+}
+
+func (c *ServerSideApplier) removeObject(ctx context.Context, obj *unstructured.Unstructured, targetNamespace string, waitForDeletion bool) (operatortypes.ResourceResult, error) {
+	result := newResourceResult(obj)
+
+	dr, err := c.resourceInterface(obj, targetNamespace)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// the kind no longer exists in the cluster, so neither does the object
+			result.Operation = operatortypes.ResourceOperationNotFound
+			return result, nil
+		}
+		result.Operation = operatortypes.ResourceOperationFailed
+		result.Error = err.Error()
+		return result, err
+	}
+	result.Namespace = obj.GetNamespace()
+
+	propagationPolicy := metav1.DeletePropagationBackground
+	err = dr.Delete(ctx, obj.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
+	if kuberneteserrors.IsNotFound(err) {
+		result.Operation = operatortypes.ResourceOperationNotFound
+		return result, nil
+	} else if err != nil {
+		result.Operation = operatortypes.ResourceOperationFailed
+		result.Error = err.Error()
+		return result, err
+	}
+
+	if waitForDeletion {
+		err := wait.PollUntilContextTimeout(ctx, deletionPollInterval, deletionTimeout, true, func(ctx context.Context) (bool, error) {
+			_, err := dr.Get(ctx, obj.GetName(), metav1.GetOptions{})
+			if kuberneteserrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		})
+		if err != nil {
+			result.Operation = operatortypes.ResourceOperationFailed
+			result.Error = errors.Wrap(err, "failed to wait for deletion").Error()
+			return result, errors.Wrap(err, "failed to wait for deletion")
+		}
+	}
+
+	result.Operation = operatortypes.ResourceOperationDeleted
+	return result, nil
+}
+
+// resourceInterface returns the dynamic resource interface for the object and sets the namespace on namespaced objects that don't have one.
+func (c *ServerSideApplier) resourceInterface(obj *unstructured.Unstructured, targetNamespace string) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+
+	mapping, err := c.restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		// the kind may have been added by a crd that was applied after the mapper was populated
+		if resettable, ok := c.restMapper.(meta.ResettableRESTMapper); ok {
+			resettable.Reset()
+			mapping, err = c.restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get rest mapping for %s", gvk.String())
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return c.dynamicClient.Resource(mapping.Resource), nil
+	}
+
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(targetNamespace)
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(metav1.NamespaceDefault)
+	}
+
+	return c.dynamicClient.Resource(mapping.Resource).Namespace(obj.GetNamespace()), nil
+}
+
+func (c *ServerSideApplier) upgradeClientSideManagedFields(ctx context.Context, dr dynamic.ResourceInterface, existing *unstructured.Unstructured) error {
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(existing, clientSideFieldManagers, FieldManager)
+	if err != nil {
+		return errors.Wrap(err, "failed to create managed fields patch")
+	}
+	if patch == nil {
+		return nil
+	}
+
+	if _, err := dr.Patch(ctx, existing.GetName(), types.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+		return errors.Wrap(err, "failed to patch managed fields")
+	}
+
+	return nil
+}
+
+// FormatResults renders results as kubectl would print them, successful results on stdout and failures on stderr
+func FormatResults(results []operatortypes.ResourceResult) ([]byte, []byte) {
+	var stdout, stderr bytes.Buffer
+	for _, result := range results {
+		if result.IsError() {
+			fmt.Fprintf(&stderr, "%s: %s\n", result.String(), result.Error)
+			continue
+		}
+		fmt.Fprintln(&stdout, result.String())
+		for _, conflict := range result.Conflicts {
+			fmt.Fprintf(&stderr, "Warning: %s: forced ownership of %s: %s\n", result.String(), conflict.Field, conflict.Message)
+		}
+	}
+	return stdout.Bytes(), stderr.Bytes()
+}
+
+func newResourceResult(obj *unstructured.Unstructured) operatortypes.ResourceResult {
+	gvk := obj.GroupVersionKind()
+	return operatortypes.ResourceResult{
+		Group:     gvk.Group,
+		Version:   gvk.Version,
+		Kind:      gvk.Kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}
+}
+
+func conflictsFromError(err error) []operatortypes.ResourceConflict {
+	status, ok := err.(kuberneteserrors.APIStatus)
+	if !ok && !errors.As(err, &status) {
+		return nil
+	}
+
+	details := status.Status().Details
+	if details == nil {
+		return nil
+	}
+
+	conflicts := []operatortypes.ResourceConflict{}
+	for _, cause := range details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		conflicts = append(conflicts, operatortypes.ResourceConflict{
+			Field:   cause.Field,
+			Message: cause.Message,
+		})
+	}
+	return conflicts
+}
+
+func annotateAppSlug(obj *unstructured.Unstructured, slug string) error {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AppSlugAnnotation] = slug
+	obj.SetAnnotations(annotations)
+
+	paths := slugAnnotationTemplatePaths[obj.GroupVersionKind().GroupKind()]
+	for _, path := range paths {
+		templateAnnotations, _, err := unstructured.NestedStringMap(obj.Object, path...)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get %s", strings.Join(path, "."))
+		}
+		if templateAnnotations == nil {
+			templateAnnotations = map[string]string{}
+		}
+		templateAnnotations[AppSlugAnnotation] = slug
+		if err := unstructured.SetNestedStringMap(obj.Object, templateAnnotations, path...); err != nil {
+			return errors.Wrapf(err, "failed to set %s", strings.Join(path, "."))
+		}
+	}
+
+	return nil
+}
+
+// decodeObjects decodes a (multi-doc) yaml document into objects, expanding any lists
+func decodeObjects(yamlDoc []byte) ([]*unstructured.Unstructured, error) {
+	objs := []*unstructured.Unstructured{}
+
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(yamlDoc), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.Wrap(err, "failed to decode document")
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		if obj.IsList() {
+			err := obj.EachListItem(func(item runtime.Object) error {
+				u, ok := item.(*unstructured.Unstructured)
+				if !ok {
+					return errors.Errorf("unexpected list item type %T", item)
+				}
+				objs = append(objs, u)
+				return nil
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to expand list")
+			}
+			continue
+		}
+
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, errors.New("object is missing kind or name")
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
@@ -0,0 +1,217 @@
+package applier
+
+import (
+	"testing"
+	"time"
+
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func Test_decodeObjects(t *testing.T) {
+	tests := []struct {
+		name    string
+		yamlDoc string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "multi doc",
+			yamlDoc: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: one
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: two
+`,
+			want: []string{"ConfigMap/one", "Secret/two"},
+		},
+		{
+			name: "list",
+			yamlDoc: `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: one
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: two
+`,
+			want: []string{"ConfigMap/one", "ConfigMap/two"},
+		},
+		{
+			name: "empty docs are skipped",
+			yamlDoc: `---
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: one
+`,
+			want: []string{"ConfigMap/one"},
+		},
+		{
+			name: "missing name",
+			yamlDoc: `apiVersion: v1
+kind: ConfigMap
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs, err := decodeObjects([]byte(tt.yamlDoc))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			got := []string{}
+			for _, obj := range objs {
+				got = append(got, obj.GetKind()+"/"+obj.GetName())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_annotateAppSlug(t *testing.T) {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "CronJob",
+			"metadata": map[string]interface{}{
+				"name": "my-cronjob",
+				"annotations": map[string]interface{}{
+					"existing": "value",
+				},
+			},
+			"spec": map[string]interface{}{
+				"schedule": "* * * * *",
+			},
+		},
+	}
+
+	err := annotateAppSlug(obj, "my-app")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"existing": "value", AppSlugAnnotation: "my-app"}, obj.GetAnnotations())
+
+	jobAnnotations, _, err := unstructured.NestedStringMap(obj.Object, "spec", "jobTemplate", "metadata", "annotations")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{AppSlugAnnotation: "my-app"}, jobAnnotations)
+
+	podAnnotations, _, err := unstructured.NestedStringMap(obj.Object, "spec", "jobTemplate", "spec", "template", "metadata", "annotations")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{AppSlugAnnotation: "my-app"}, podAnnotations)
+}
+
+func Test_conflictsFromError(t *testing.T) {
+	err := kuberneteserrors.NewApplyConflict([]metav1.StatusCause{
+		{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Field:   ".spec.replicas",
+			Message: `conflict with "kubectl-edit" using apps/v1`,
+		},
+		{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Field:   ".spec.template",
+			Message: "ignored",
+		},
+	}, "Apply failed with 1 conflict")
+
+	assert.Equal(t, []operatortypes.ResourceConflict{
+		{
+			Field:   ".spec.replicas",
+			Message: `conflict with "kubectl-edit" using apps/v1`,
+		},
+	}, conflictsFromError(err))
+}
+
+func TestFormatResults(t *testing.T) {
+	results := []operatortypes.ResourceResult{
+		{Group: "apps", Version: "v1", Kind: "Deployment", Name: "web", Operation: operatortypes.ResourceOperationConfigured, Conflicts: []operatortypes.ResourceConflict{{Field: ".spec.replicas", Message: "conflict"}}},
+		{Version: "v1", Kind: "ConfigMap", Name: "config", Operation: operatortypes.ResourceOperationCreated, DryRun: true},
+		{Version: "v1", Kind: "Service", Name: "web", Operation: operatortypes.ResourceOperationFailed, Error: "invalid port"},
+	}
+
+	stdout, stderr := FormatResults(results)
+	assert.Equal(t, "deployment.apps/web configured\nconfigmap/config created (dry run)\n", string(stdout))
+	assert.Equal(t, "Warning: deployment.apps/web configured: forced ownership of .spec.replicas: conflict\nservice/web failed: invalid port\n", string(stderr))
+}
+
+func TestServerSideApplier_RemoveResources(t *testing.T) {
+	configMapGVK := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(configMapGVK, meta.RESTScopeNamespace)
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(configMapGVK)
+	existing.SetNamespace("my-namespace")
+	existing.SetName("exists")
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), existing)
+	c := NewServerSideApplierWithClients(dynamicClient, mapper)
+
+	results, err := c.RemoveResources("my-namespace", []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: exists
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: missing
+`), false)
+	require.NoError(t, err)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, operatortypes.ResourceOperationDeleted, results[0].Operation)
+	assert.Equal(t, "my-namespace", results[0].Namespace)
+	assert.Equal(t, operatortypes.ResourceOperationNotFound, results[1].Operation)
+}
+
+func Test_isUnchangedByDryRun(t *testing.T) {
+	newConfigMap := func(resourceVersion string, managedAt time.Time, data map[string]interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata": map[string]interface{}{
+					"name":      "config",
+					"namespace": "my-namespace",
+				},
+				"data": data,
+			},
+		}
+		obj.SetResourceVersion(resourceVersion)
+		obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: FieldManager, Operation: metav1.ManagedFieldsOperationApply, Time: &metav1.Time{Time: managedAt}}})
+		return obj
+	}
+
+	now := time.Now()
+	existing := newConfigMap("1", now.Add(-time.Hour), map[string]interface{}{"key": "value"})
+
+	assert.True(t, isUnchangedByDryRun(existing, newConfigMap("2", now, map[string]interface{}{"key": "value"})))
+	assert.False(t, isUnchangedByDryRun(existing, newConfigMap("1", now.Add(-time.Hour), map[string]interface{}{"key": "changed"})))
+
+	// the objects passed in are not modified
+	assert.Equal(t, "1", existing.GetResourceVersion())
+	assert.Len(t, existing.GetManagedFields(), 1)
+}
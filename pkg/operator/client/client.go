@@ -20,9 +20,13 @@ import (
 	apptypes "github.com/replicatedhq/kots/pkg/app/types"
 	"github.com/replicatedhq/kots/pkg/appstate"
 	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
-	"github.com/replicatedhq/kots/pkg/binaries"
+	"github.com/replicatedhq/kots/pkg/eventstream"
+	eventstreamtypes "github.com/replicatedhq/kots/pkg/eventstream/types"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/metrics"
+	"github.com/replicatedhq/kots/pkg/notifications"
+	notificationstypes "github.com/replicatedhq/kots/pkg/notifications/types"
 	"github.com/replicatedhq/kots/pkg/operator/applier"
 	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
 	"github.com/replicatedhq/kots/pkg/registry"
@@ -30,19 +34,25 @@ import (
 	"github.com/replicatedhq/kots/pkg/store"
 	"github.com/replicatedhq/kots/pkg/supportbundle"
 	supportbundletypes "github.com/replicatedhq/kots/pkg/supportbundle/types"
+	"github.com/replicatedhq/kots/pkg/supportbundlescheduler"
 	"github.com/replicatedhq/kots/pkg/util"
 	"github.com/replicatedhq/kotskinds/pkg/helmchart"
 	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 type DeployResults struct {
-	IsError      bool   `json:"isError"`
-	DryrunStdout []byte `json:"dryrunStdout"`
-	DryrunStderr []byte `json:"dryrunStderr"`
-	ApplyStdout  []byte `json:"applyStdout"`
-	ApplyStderr  []byte `json:"applyStderr"`
-	HelmStdout   []byte `json:"helmStdout"`
-	HelmStderr   []byte `json:"helmStderr"`
+	IsError            bool                              `json:"isError"`
+	DryrunStdout       []byte                            `json:"dryrunStdout"`
+	DryrunStderr       []byte                            `json:"dryrunStderr"`
+	ApplyStdout        []byte                            `json:"applyStdout"`
+	ApplyStderr        []byte                            `json:"applyStderr"`
+	HelmStdout         []byte                            `json:"helmStdout"`
+	HelmStderr         []byte                            `json:"helmStderr"`
+	ResourceResults    []operatortypes.ResourceResult    `json:"resourceResults"`
+	HelmReleaseResults []operatortypes.HelmReleaseResult `json:"helmReleaseResults"`
 }
 
 // DesiredState is what we receive from the kotsadm api server
@@ -53,6 +63,10 @@ type DesiredState struct {
 
 type Client struct {
 	TargetNamespace string
+	// ClusterID is only set for remote clusters, the client deploys to the cluster kotsadm runs in otherwise
+	ClusterID string
+	// RESTConfig is the config for remote clusters
+	RESTConfig *rest.Config
 
 	watchedNamespaces []string
 	imagePullSecrets  []string
@@ -72,12 +86,17 @@ func (c *Client) Init() error {
 		}
 	}
 
-	clientset, err := k8sutil.GetClientset()
+	clientset, err := c.getClientset()
 	if err != nil {
 		return errors.Wrap(err, "failed to get k8s clientset")
 	}
 
-	c.appStateMonitor = appstate.NewMonitor(clientset, c.TargetNamespace)
+	dynamicClient, err := c.getDynamicClient()
+	if err != nil {
+		return errors.Wrap(err, "failed to get dynamic client")
+	}
+
+	c.appStateMonitor = appstate.NewMonitor(clientset, dynamicClient, c.TargetNamespace)
 	go c.runAppStateMonitor()
 
 	return nil
@@ -117,6 +136,10 @@ func (c *Client) runAppStateMonitor() error {
 			}
 			if err := c.setAppStatus(appStatus); err != nil {
 				log.Printf("error updating app status: %v", err)
+				return
+			}
+			if lastHash != nextHash {
+				c.publishAppStatus(appStatus)
 			}
 		})
 	}
@@ -205,6 +228,24 @@ func (c *Client) DeployApp(deployArgs operatortypes.DeployAppArgs) (deployed boo
 	return
 }
 
+// DeployCanary applies the manifests of the first step of a progressive rollout.
+// Unlike DeployApp, it doesn't delete removed resources, deploy helm charts or record deploy results.
+func (c *Client) DeployCanary(deployArgs operatortypes.DeployAppArgs) error {
+	log.Println("received a canary deploy request for", deployArgs.AppSlug)
+
+	result, err := c.ensureResourcesPresent(deployArgs)
+	if err != nil {
+		return errors.Wrap(err, "failed to deploy manifests")
+	}
+
+	if result.dryRunResult.hasErr || result.applyResult.hasErr {
+		stderr := append(result.dryRunResult.multiStderr, result.applyResult.multiStderr...)
+		return errors.Errorf("failed to apply manifests: %s", bytes.Join(stderr, []byte("\n")))
+	}
+
+	return nil
+}
+
 func (c *Client) UndeployApp(undeployArgs operatortypes.UndeployAppArgs) (finalError error) {
 	log.Println("received an undeploy request for", undeployArgs.AppSlug)
 
@@ -498,18 +539,21 @@ func (c *Client) setDeployResults(args operatortypes.DeployAppArgs, dryRunResult
 		results.IsError = results.IsError || dryRunResult.hasErr
 		results.DryrunStdout = bytes.Join(dryRunResult.multiStdout, []byte("\n"))
 		results.DryrunStderr = bytes.Join(dryRunResult.multiStderr, []byte("\n"))
+		results.ResourceResults = append(results.ResourceResults, dryRunResult.resources...)
 	}
 
 	if applyResult != nil {
 		results.IsError = results.IsError || applyResult.hasErr
 		results.ApplyStdout = bytes.Join(applyResult.multiStdout, []byte("\n"))
 		results.ApplyStderr = bytes.Join(applyResult.multiStderr, []byte("\n"))
+		results.ResourceResults = append(results.ResourceResults, applyResult.resources...)
 	}
 
 	if helmResult != nil {
 		results.IsError = results.IsError || helmResult.hasErr
 		results.HelmStdout = bytes.Join(helmResult.multiStdout, []byte("\n"))
 		results.HelmStderr = bytes.Join(helmResult.multiStderr, []byte("\n"))
+		results.HelmReleaseResults = append(results.HelmReleaseResults, helmResult.helmReleases...)
 	}
 
 	app, err := store.GetStore().GetApp(args.AppID)
@@ -535,13 +579,15 @@ func (c *Client) setDeployResults(args operatortypes.DeployAppArgs, dryRunResult
 	}
 
 	downstreamOutput := downstreamtypes.DownstreamOutput{
-		DryrunStdout: base64.StdEncoding.EncodeToString(results.DryrunStdout),
-		DryrunStderr: base64.StdEncoding.EncodeToString(results.DryrunStderr),
-		ApplyStdout:  base64.StdEncoding.EncodeToString(results.ApplyStdout),
-		ApplyStderr:  base64.StdEncoding.EncodeToString(results.ApplyStderr),
-		HelmStdout:   base64.StdEncoding.EncodeToString(results.HelmStdout),
-		HelmStderr:   base64.StdEncoding.EncodeToString(results.HelmStderr),
-		RenderError:  "",
+		DryrunStdout:       base64.StdEncoding.EncodeToString(results.DryrunStdout),
+		DryrunStderr:       base64.StdEncoding.EncodeToString(results.DryrunStderr),
+		ApplyStdout:        base64.StdEncoding.EncodeToString(results.ApplyStdout),
+		ApplyStderr:        base64.StdEncoding.EncodeToString(results.ApplyStderr),
+		HelmStdout:         base64.StdEncoding.EncodeToString(results.HelmStdout),
+		HelmStderr:         base64.StdEncoding.EncodeToString(results.HelmStderr),
+		RenderError:        "",
+		ResourceResults:    results.ResourceResults,
+		HelmReleaseResults: results.HelmReleaseResults,
 	}
 	err = store.GetStore().UpdateDownstreamDeployStatus(args.AppID, args.ClusterID, args.Sequence, results.IsError, downstreamOutput)
 	if err != nil {
@@ -610,7 +656,25 @@ func (c *Client) ApplyAppInformers(args operatortypes.AppInformersArgs) {
 	}
 }
 
+// publishAppStatus pushes the status of an app to the clients of the event stream
+func (c *Client) publishAppStatus(appStatus appstatetypes.AppStatus) {
+	appStatus.State = appstatetypes.GetState(appStatus.ResourceStates)
+	sequence := appStatus.Sequence
+	eventstream.Publish(eventstreamtypes.Event{
+		Type:      eventstreamtypes.EventAppStatus,
+		AppID:     appStatus.AppID,
+		ClusterID: c.ClusterID,
+		Sequence:  &sequence,
+		Data:      appStatus,
+	})
+}
+
 func (c *Client) setAppStatus(newAppStatus appstatetypes.AppStatus) error {
+	if c.ClusterID != "" {
+		// the status of the app is that of the cluster kotsadm runs in, remote clusters have their own
+		return c.setDownstreamAppStatus(newAppStatus)
+	}
+
 	currentAppStatus, err := store.GetStore().GetAppStatus(newAppStatus.AppID)
 	if err != nil {
 		return errors.Wrap(err, "failed to get current app status")
@@ -621,6 +685,8 @@ func (c *Client) setAppStatus(newAppStatus appstatetypes.AppStatus) error {
 		return errors.Wrap(err, "failed to set app status")
 	}
 
+	metrics.SetAppStatus(newAppStatus.AppID, newAppStatus.ResourceStates)
+
 	newAppState := appstatetypes.GetState(newAppStatus.ResourceStates)
 	if currentAppStatus != nil && newAppState != currentAppStatus.State {
 		go func() {
@@ -629,19 +695,94 @@ func (c *Client) setAppStatus(newAppStatus appstatetypes.AppStatus) error {
 				logger.Debugf("failed to submit app info: %v", err)
 			}
 		}()
+
+		previousAppState := currentAppStatus.State
+		go func() {
+			if err := supportbundlescheduler.HandleAppStateChange(newAppStatus.AppID, "", previousAppState, newAppState); err != nil {
+				logger.Error(errors.Wrap(err, "failed to handle app state change for support bundles"))
+			}
+		}()
+
+		sequence := newAppStatus.Sequence
+		notifications.Notify(notificationstypes.Event{
+			Type:     notificationstypes.EventAppStatusChanged,
+			AppID:    newAppStatus.AppID,
+			Sequence: &sequence,
+			Data: map[string]interface{}{
+				"previousState": currentAppStatus.State,
+				"state":         newAppState,
+			},
+		})
 	}
 
 	return nil
 }
 
-func (c *Client) getApplier() (applier.KubectlInterface, error) {
-	kubectl := binaries.GetKubectlBinPath()
-	kustomize := binaries.GetKustomizeBinPath()
+func (c *Client) setDownstreamAppStatus(newAppStatus appstatetypes.AppStatus) error {
+	currentAppStatus, err := store.GetStore().GetDownstreamAppStatus(newAppStatus.AppID, c.ClusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get current downstream app status")
+	}
 
-	config, err := k8sutil.GetClusterConfig()
+	err = store.GetStore().SetDownstreamAppStatus(newAppStatus.AppID, c.ClusterID, newAppStatus.ResourceStates, newAppStatus.UpdatedAt, newAppStatus.Sequence)
+	if err != nil {
+		return errors.Wrap(err, "failed to set downstream app status")
+	}
+
+	newAppState := appstatetypes.GetState(newAppStatus.ResourceStates)
+	if currentAppStatus != nil && newAppState != currentAppStatus.State {
+		clusterID := c.ClusterID
+		previousAppState := currentAppStatus.State
+		go func() {
+			if err := supportbundlescheduler.HandleAppStateChange(newAppStatus.AppID, clusterID, previousAppState, newAppState); err != nil {
+				logger.Error(errors.Wrap(err, "failed to handle app state change for support bundles"))
+			}
+		}()
+	}
+
+	return nil
+}
+
+func (c *Client) getApplier() (*applier.ServerSideApplier, error) {
+	config, err := c.getClusterConfig()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get cluster config")
 	}
 
-	return applier.NewKubectl(kubectl, kustomize, config), nil
+	return applier.NewServerSideApplier(config)
+}
+
+func (c *Client) getClusterConfig() (*rest.Config, error) {
+	if c.RESTConfig != nil {
+		return rest.CopyConfig(c.RESTConfig), nil
+	}
+	return k8sutil.GetClusterConfig()
+}
+
+func (c *Client) getClientset() (*kubernetes.Clientset, error) {
+	config, err := c.getClusterConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create kubernetes clientset")
+	}
+
+	return clientset, nil
+}
+
+func (c *Client) getDynamicClient() (dynamic.Interface, error) {
+	config, err := c.getClusterConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create dynamic client")
+	}
+
+	return dynamicClient, nil
 }
@@ -8,8 +8,10 @@ type ClientInterface interface {
 	Init() error
 	Shutdown()
 	DeployApp(deployArgs operatortypes.DeployAppArgs) (deployed bool, finalError error)
+	DeployCanary(deployArgs operatortypes.DeployAppArgs) error
 	UndeployApp(undeployArgs operatortypes.UndeployAppArgs) error
 	ApplyAppInformers(args operatortypes.AppInformersArgs)
 	ApplyNamespacesInformer(namespaces []string, imagePullSecrets []string)
 	ApplyHooksInformer(namespaces []string)
+	DetectDrift(args operatortypes.DetectDriftArgs) ([]operatortypes.DriftedResource, error)
 }
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
-	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/operator/applier"
 	"github.com/replicatedhq/kots/pkg/operator/types"
@@ -175,12 +174,12 @@ func shouldWaitForResourceDeletion(kind string, waitFlag bool) bool {
 }
 
 func (c *Client) clearNamespaces(appSlug string, namespacesToClear []string, isRestore bool, restoreLabelSelector *metav1.LabelSelector) error {
-	dyn, err := k8sutil.GetDynamicClient()
+	dyn, err := c.getDynamicClient()
 	if err != nil {
 		return errors.Wrap(err, "failed to get dynamic client")
 	}
 
-	config, err := k8sutil.GetClusterConfig()
+	config, err := c.getClusterConfig()
 	if err != nil {
 		return errors.Wrap(err, "failed to get cluster config")
 	}
@@ -316,7 +315,7 @@ func (c *Client) clearNamespaces(appSlug string, namespacesToClear []string, isR
 }
 
 func (c *Client) deletePVCs(appLabelSelector *metav1.LabelSelector, appslug string) error {
-	clientset, err := k8sutil.GetClientset()
+	clientset, err := c.getClientset()
 	if err != nil {
 		return errors.Wrap(err, "failed to get k8s clientset")
 	}
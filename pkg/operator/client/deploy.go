@@ -6,10 +6,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
-	"strings"
 	"sync"
 
 	"github.com/mholt/archiver/v3"
@@ -17,7 +15,6 @@ import (
 	"github.com/replicatedhq/kots/pkg/appstate"
 	"github.com/replicatedhq/kots/pkg/archives"
 	"github.com/replicatedhq/kots/pkg/helm"
-	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/operator/applier"
 	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
@@ -33,9 +30,11 @@ import (
 var imagePullSecretsMtx sync.Mutex
 
 type commandResult struct {
-	hasErr      bool
-	multiStdout [][]byte
-	multiStderr [][]byte
+	hasErr       bool
+	multiStdout  [][]byte
+	multiStderr  [][]byte
+	resources    []operatortypes.ResourceResult
+	helmReleases []operatortypes.HelmReleaseResult
 }
 
 type deployResult struct {
@@ -46,7 +45,7 @@ type deployResult struct {
 func (c *Client) ensureNamespacePresent(name string) error {
 	logger.Infof("ensuring namespace %s", name)
 
-	clientset, err := k8sutil.GetClientset()
+	clientset, err := c.getClientset()
 	if err != nil {
 		return errors.Wrap(err, "failed to get clientset")
 	}
@@ -78,7 +77,7 @@ func (c *Client) ensureImagePullSecretsPresent(namespace string, imagePullSecret
 	imagePullSecretsMtx.Lock()
 	defer imagePullSecretsMtx.Unlock()
 
-	clientset, err := k8sutil.GetClientset()
+	clientset, err := c.getClientset()
 	if err != nil {
 		return errors.Wrap(err, "failed to get clientset")
 	}
@@ -123,7 +122,7 @@ func (c *Client) ensureEmbeddedClusterCAPresent(namespace string) error {
 
 	logger.Debugf("ensuring embedded cluster ca present in namespace %s", namespace)
 
-	clientset, err := k8sutil.GetClientset()
+	clientset, err := c.getClientset()
 	if err != nil {
 		return errors.Wrap(err, "failed to get clientset")
 	}
@@ -181,6 +180,11 @@ func (c *Client) ensureResourcesPresent(deployArgs operatortypes.DeployAppArgs)
 		return nil, errors.Wrap(err, "failed to get applier")
 	}
 
+	config, err := c.getClusterConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
 	decoded, err := base64.StdEncoding.DecodeString(deployArgs.Manifests)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to decode manifests")
@@ -213,7 +217,10 @@ func (c *Client) ensureResourcesPresent(deployArgs operatortypes.DeployAppArgs)
 					logger.Infof("dry run applying unidentified resource. unable to parse error: %s", resource.DecodeErrMsg)
 				}
 
-				dryrunStdout, dryrunStderr, dryRunErr := kubernetesApplier.ApplyCreateOrPatch(namespace, deployArgs.AppSlug, []byte(resource.Manifest), true, deployArgs.Wait, deployArgs.AnnotateSlug)
+				dryRunResults, dryRunErr := kubernetesApplier.ApplyResources(namespace, deployArgs.AppSlug, []byte(resource.Manifest), true, deployArgs.AnnotateSlug)
+				deployRes.dryRunResult.resources = append(deployRes.dryRunResult.resources, dryRunResults...)
+
+				dryrunStdout, dryrunStderr := applier.FormatResults(dryRunResults)
 				if len(dryrunStdout) > 0 {
 					deployRes.dryRunResult.multiStdout = append(deployRes.dryRunResult.multiStdout, dryrunStdout)
 				}
@@ -226,6 +233,10 @@ func (c *Client) ensureResourcesPresent(deployArgs operatortypes.DeployAppArgs)
 					logger.Infof("stderr (dryrun) = %s", dryrunStderr)
 					logger.Infof("error: %s", dryRunErr.Error())
 
+					if len(dryrunStderr) == 0 {
+						// the document could not be decoded or mapped, so there is no per-resource result to report
+						deployRes.dryRunResult.multiStderr = append(deployRes.dryRunResult.multiStderr, []byte(dryRunErr.Error()))
+					}
 					deployRes.dryRunResult.hasErr = true
 					return &deployRes, nil
 				}
@@ -258,8 +269,10 @@ func (c *Client) ensureResourcesPresent(deployArgs operatortypes.DeployAppArgs)
 				logger.Infof("applying unidentified resource. unable to parse error: %s", resource.DecodeErrMsg)
 			}
 
-			applyStdout, applyStderr, applyErr := kubernetesApplier.ApplyCreateOrPatch(namespace, deployArgs.AppSlug, []byte(resource.Manifest), false, deployArgs.Wait, deployArgs.AnnotateSlug)
+			applyResults, applyErr := kubernetesApplier.ApplyResources(namespace, deployArgs.AppSlug, []byte(resource.Manifest), false, deployArgs.AnnotateSlug)
+			deployRes.applyResult.resources = append(deployRes.applyResult.resources, applyResults...)
 
+			applyStdout, applyStderr := applier.FormatResults(applyResults)
 			if len(applyStdout) > 0 {
 				deployRes.applyResult.multiStdout = append(deployRes.applyResult.multiStdout, applyStdout)
 			}
@@ -272,6 +285,10 @@ func (c *Client) ensureResourcesPresent(deployArgs operatortypes.DeployAppArgs)
 				logger.Infof("stderr (apply) = %s", applyStderr)
 				logger.Infof("error: %s", applyErr.Error())
 
+				if len(applyStderr) == 0 {
+					// the document could not be decoded or mapped, so there is no per-resource result to report
+					deployRes.applyResult.multiStderr = append(deployRes.applyResult.multiStderr, []byte(applyErr.Error()))
+				}
 				deployRes.applyResult.hasErr = true
 				return &deployRes, nil
 			}
@@ -284,7 +301,7 @@ func (c *Client) ensureResourcesPresent(deployArgs operatortypes.DeployAppArgs)
 
 			if resource.ShouldWaitForReady() {
 				logger.Infof("waiting for resource %s/%s/%s/%s in namespace %s to be ready", group, version, kind, name, namespace)
-				err := appstate.WaitForResourceToBeReady(namespace, name, resource.GVK)
+				err := appstate.WaitForResourceToBeReady(config, namespace, name, resource.GVK)
 				if err != nil {
 					return nil, errors.Wrapf(err, "failed to wait for resource %s/%s/%s/%s in namespace %s to be ready", group, version, kind, name, namespace)
 				}
@@ -294,7 +311,7 @@ func (c *Client) ensureResourcesPresent(deployArgs operatortypes.DeployAppArgs)
 			if resource.ShouldWaitForProperties() {
 				for _, prop := range resource.GetWaitForProperties() {
 					logger.Infof("waiting for resource %s/%s/%s/%s in namespace %s to have property %s=%s", group, version, kind, name, namespace, prop.Path, prop.Value)
-					err := appstate.WaitForProperty(namespace, name, resource.GVK, prop.Path, prop.Value)
+					err := appstate.WaitForProperty(config, namespace, name, resource.GVK, prop.Path, prop.Value)
 					if err != nil {
 						return nil, errors.Wrapf(err, "failed to wait for resource %s/%s/%s/%s in namespace %s to have property %s=%s", group, version, kind, name, namespace, prop.Path, prop.Value)
 					}
@@ -313,66 +330,48 @@ func (c *Client) installWithHelm(v1Beta1ChartsDir, v1beta2ChartsDir string, kots
 		return nil, errors.Wrap(err, "failed to get sorted charts")
 	}
 
-	var hasErr bool
-	var multiStdout, multiStderr [][]byte
+	result := &commandResult{}
 
 	for _, dir := range orderedDirs {
-		args := []string{"upgrade", "-i", dir.ReleaseName}
+		var chartPath string
+		var valueFiles []string
 		if dir.APIVersion == "kots.io/v1beta1" {
-			installDir := filepath.Join(v1Beta1ChartsDir, dir.Name)
-			args = append(args, installDir)
+			chartPath = filepath.Join(v1Beta1ChartsDir, dir.Name)
 		} else if dir.APIVersion == "kots.io/v1beta2" {
 			installDir := filepath.Join(v1beta2ChartsDir, dir.Name)
-			chartPath := filepath.Join(installDir, fmt.Sprintf("%s-%s.tgz", dir.ChartName, dir.ChartVersion))
-			valuesPath := filepath.Join(installDir, "values.yaml")
-			args = append(args, chartPath, "-f", valuesPath)
+			chartPath = filepath.Join(installDir, fmt.Sprintf("%s-%s.tgz", dir.ChartName, dir.ChartVersion))
+			valueFiles = []string{filepath.Join(installDir, "values.yaml")}
 		} else {
 			return nil, errors.Errorf("unknown api version %s", dir.APIVersion)
 		}
-		args = append(args, "--timeout", "3600s")
-
-		if dir.Namespace != "" {
-			args = append(args, "-n", dir.Namespace)
 
+		if dir.Namespace != "" && c.ClusterID == "" {
 			// prior to kots v1.95.0, helm release secrets were created in the kotsadm namespace
 			// Since kots v1.95.0, helm release secrets are created in the same namespace as the helm release
 			// This migration will move the helm release secrets to the helm release namespace
 			kotsadmNamespace := util.AppNamespace()
 			if dir.Namespace != kotsadmNamespace {
-				err := migrateExistingHelmReleaseSecrets(dir.ReleaseName, dir.Namespace, kotsadmNamespace)
+				err := c.migrateExistingHelmReleaseSecrets(dir.ReleaseName, dir.Namespace, kotsadmNamespace)
 				if err != nil {
 					return nil, errors.Wrapf(err, "failed to migrate helm release secrets for %s", dir.ReleaseName)
 				}
 			}
 		}
 
-		if len(dir.UpgradeFlags) > 0 {
-			args = append(args, dir.UpgradeFlags...)
-		}
-
-		logger.Infof("running helm with arguments %v", args)
-		cmd := exec.Command("helm", args...)
-		stdout, stderr, err := applier.Run(cmd)
-		if err != nil {
-			logger.Infof("stdout (helm install) = %s", stdout)
-			logger.Infof("stderr (helm install) = %s", stderr)
-			logger.Infof("error: %s", err.Error())
-			hasErr = true
-		}
+		releaseResult := c.upgradeHelmRelease(dir, chartPath, valueFiles)
+		result.helmReleases = append(result.helmReleases, releaseResult)
 
-		if len(stdout) > 0 {
-			multiStdout = append(multiStdout, []byte(fmt.Sprintf("------- %s -------", dir.Name)), stdout)
-		}
-		if len(stderr) > 0 {
-			multiStderr = append(multiStderr, []byte(fmt.Sprintf("------- %s -------", dir.Name)), stderr)
+		header := []byte(fmt.Sprintf("------- %s -------", dir.Name))
+		if releaseResult.IsError() {
+			logger.Infof("failed to deploy helm chart %s: %s", dir.Name, releaseResult.String())
+			result.hasErr = true
+			result.multiStderr = append(result.multiStderr, header, []byte(releaseResult.String()))
+		} else {
+			logger.Infof("deployed helm chart %s: %s", dir.Name, releaseResult.String())
+			result.multiStdout = append(result.multiStdout, header, []byte(releaseResult.String()))
 		}
 	}
 
-	result := &commandResult{
-		hasErr:      hasErr,
-		multiStderr: multiStderr,
-		multiStdout: multiStdout,
-	}
 	return result, nil
 }
 
@@ -568,24 +567,11 @@ func (c *Client) uninstallWithHelm(v1Beta1ChartsDir, v1Beta2ChartsDir string, ko
 	}
 
 	for _, dir := range orderedDirs {
-		args := []string{"uninstall", dir.ReleaseName}
-
-		if dir.Namespace != "" {
-			args = append(args, "-n", dir.Namespace)
-		}
-
-		logger.Infof("running helm with arguments %v", args)
-		cmd := exec.Command("helm", args...)
-		stdout, stderr, err := applier.Run(cmd)
-		logger.Infof("stdout (helm uninstall) = %s", stdout)
-		logger.Infof("stderr (helm uninstall) = %s", stderr)
-		if err != nil {
-			if strings.Contains(string(stderr), "not found") {
-				continue
-			}
-			logger.Errorf("error: %s", err.Error())
-			return errors.Wrapf(err, "failed to uninstall release %s for chart %s: %s", dir.ReleaseName, dir.ChartName, stderr)
+		releaseResult := c.uninstallHelmRelease(dir)
+		if releaseResult.IsError() {
+			return errors.Errorf("failed to uninstall release %s for chart %s: %s", dir.ReleaseName, dir.ChartName, releaseResult.Error)
 		}
+		logger.Infof("uninstalled helm chart %s: %s", dir.Name, releaseResult.String())
 	}
 
 	return nil
@@ -714,8 +700,8 @@ func findMatchingHelmCharts(chartsDir string, kotsCharts []helmchart.HelmChartIn
 	return matching, nil
 }
 
-func migrateExistingHelmReleaseSecrets(relaseName string, releaseNamespace string, kotsadmNamespace string) error {
-	clientset, err := k8sutil.GetClientset()
+func (c *Client) migrateExistingHelmReleaseSecrets(relaseName string, releaseNamespace string, kotsadmNamespace string) error {
+	clientset, err := c.getClientset()
 	if err != nil {
 		return errors.Wrap(err, "failed to get k8s client set")
 	}
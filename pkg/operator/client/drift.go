@@ -0,0 +1,83 @@
+package client
+
+import (
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/logger"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
+	"github.com/replicatedhq/kots/pkg/util"
+)
+
+// DetectDrift compares the manifests of the deployed version with the live objects in the cluster
+// and returns the objects that drifted. If remediation is enabled, drifted objects are re-applied.
+func (c *Client) DetectDrift(args operatortypes.DetectDriftArgs) ([]operatortypes.DriftedResource, error) {
+	kubernetesApplier, err := c.getApplier()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get applier")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(args.Manifests)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode manifests")
+	}
+
+	drifted := []operatortypes.DriftedResource{}
+	for _, resource := range decodeManifests(util.ConvertToSingleDocs(decoded)) {
+		if resource.DecodeErrMsg != "" {
+			// these failed to deploy too
+			continue
+		}
+		if isHookJob(resource) {
+			// hook jobs are expected to be deleted after they complete
+			continue
+		}
+
+		namespace := resource.GetNamespace()
+		if namespace == "" {
+			namespace = c.TargetNamespace
+		}
+
+		results, err := kubernetesApplier.DetectDrift(namespace, args.AppSlug, []byte(resource.Manifest), args.AnnotateSlug)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to detect drift of %s/%s", resource.GetKind(), resource.GetName())
+		}
+		if len(results) == 0 {
+			continue
+		}
+
+		if args.Remediate && canRemediate(results) {
+			logger.Infof("re-applying drifted resource %s/%s/%s/%s in namespace %s", resource.GetGroup(), resource.GetVersion(), resource.GetKind(), resource.GetName(), namespace)
+			_, applyErr := kubernetesApplier.ApplyResources(namespace, args.AppSlug, []byte(resource.Manifest), false, args.AnnotateSlug)
+			for i := range results {
+				if applyErr != nil {
+					results[i].Error = applyErr.Error()
+				} else {
+					results[i].Remediated = true
+				}
+			}
+		}
+
+		drifted = append(drifted, results...)
+	}
+
+	return drifted, nil
+}
+
+func isHookJob(resource operatortypes.Resource) bool {
+	if resource.GetKind() != "Job" || resource.Unstructured == nil {
+		return false
+	}
+	_, ok := resource.Unstructured.GetAnnotations()["kots.io/hook-delete-policy"]
+	return ok
+}
+
+// canRemediate returns false if the drift could not be determined, since re-applying would not fix it
+func canRemediate(results []operatortypes.DriftedResource) bool {
+	for _, result := range results {
+		if result.Reason == operatortypes.DriftReasonError {
+			return false
+		}
+	}
+	return true
+}
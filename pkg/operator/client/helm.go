@@ -0,0 +1,381 @@
+package client
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+const defaultHelmTimeout = time.Hour
+
+// helmUpgradeOptions are the `helm upgrade` flags that can be set in the HelmUpgradeFlags of a HelmChart
+type helmUpgradeOptions struct {
+	Atomic                   bool
+	CleanupOnFail            bool
+	CreateNamespace          bool
+	DisableHooks             bool
+	Force                    bool
+	ResetValues              bool
+	ReuseValues              bool
+	ResetThenReuseValues     bool
+	Recreate                 bool
+	SkipCRDs                 bool
+	SkipSchemaValidation     bool
+	DisableOpenAPIValidation bool
+	TakeOwnership            bool
+	EnableDNS                bool
+	SubNotes                 bool
+	HideNotes                bool
+	Wait                     bool
+	WaitForJobs              bool
+	Timeout                  time.Duration
+	MaxHistory               int
+	Description              string
+	Labels                   map[string]string
+	PostRenderer             string
+	PostRendererArgs         []string
+	Values                   values.Options
+}
+
+// parseHelmUpgradeFlags converts helm cli flags into upgrade options. Both "--flag value" and "--flag=value" are supported.
+// Flags that are not supported are logged and ignored, as they were accepted by the helm cli.
+func parseHelmUpgradeFlags(flags []string) (*helmUpgradeOptions, error) {
+	opts := &helmUpgradeOptions{
+		Timeout: defaultHelmTimeout,
+	}
+
+	boolFlags := map[string]*bool{
+		"--atomic":                     &opts.Atomic,
+		"--cleanup-on-fail":            &opts.CleanupOnFail,
+		"--create-namespace":           &opts.CreateNamespace,
+		"--no-hooks":                   &opts.DisableHooks,
+		"--force":                      &opts.Force,
+		"--reset-values":               &opts.ResetValues,
+		"--reuse-values":               &opts.ReuseValues,
+		"--reset-then-reuse-values":    &opts.ResetThenReuseValues,
+		"--recreate-pods":              &opts.Recreate,
+		"--skip-crds":                  &opts.SkipCRDs,
+		"--skip-schema-validation":     &opts.SkipSchemaValidation,
+		"--disable-openapi-validation": &opts.DisableOpenAPIValidation,
+		"--take-ownership":             &opts.TakeOwnership,
+		"--enable-dns":                 &opts.EnableDNS,
+		"--render-subchart-notes":      &opts.SubNotes,
+		"--hide-notes":                 &opts.HideNotes,
+		"--wait":                       &opts.Wait,
+		"--wait-for-jobs":              &opts.WaitForJobs,
+	}
+
+	// flags that have no effect since every release is installed or upgraded from a packaged chart
+	// that includes its dependencies
+	ignoredFlags := map[string]bool{
+		"--install":           true,
+		"-i":                  true,
+		"--debug":             true,
+		"--dependency-update": true,
+		"--devel":             true,
+	}
+
+	for i := 0; i < len(flags); i++ {
+		name, value, hasValue := strings.Cut(flags[i], "=")
+
+		if ignoredFlags[name] {
+			continue
+		}
+
+		if ptr, ok := boolFlags[name]; ok {
+			if !hasValue {
+				*ptr = true
+				continue
+			}
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to parse value of %s", name)
+			}
+			*ptr = b
+			continue
+		}
+
+		if !isHelmUpgradeValueFlag(name) {
+			// the value of an unsupported flag is skipped as well, unless it looks like another flag
+			if !hasValue && i+1 < len(flags) && !strings.HasPrefix(flags[i+1], "-") {
+				i++
+			}
+			logger.Warnf("ignoring unsupported helm upgrade flag %s", name)
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(flags) {
+				return nil, errors.Errorf("flag %s requires a value", name)
+			}
+			i++
+			value = flags[i]
+		}
+
+		switch name {
+		case "-f", "--values":
+			opts.Values.ValueFiles = append(opts.Values.ValueFiles, value)
+		case "--set":
+			opts.Values.Values = append(opts.Values.Values, value)
+		case "--set-string":
+			opts.Values.StringValues = append(opts.Values.StringValues, value)
+		case "--set-file":
+			opts.Values.FileValues = append(opts.Values.FileValues, value)
+		case "--set-json":
+			opts.Values.JSONValues = append(opts.Values.JSONValues, value)
+		case "--set-literal":
+			opts.Values.LiteralValues = append(opts.Values.LiteralValues, value)
+		case "--timeout":
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse timeout")
+			}
+			opts.Timeout = timeout
+		case "--history-max":
+			maxHistory, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse history max")
+			}
+			opts.MaxHistory = maxHistory
+		case "--description":
+			opts.Description = value
+		case "-l", "--labels":
+			labels, err := parseHelmLabels(value)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse labels")
+			}
+			if opts.Labels == nil {
+				opts.Labels = map[string]string{}
+			}
+			for k, v := range labels {
+				opts.Labels[k] = v
+			}
+		case "--post-renderer":
+			opts.PostRenderer = value
+		case "--post-renderer-args":
+			opts.PostRendererArgs = append(opts.PostRendererArgs, value)
+		}
+	}
+
+	return opts, nil
+}
+
+func isHelmUpgradeValueFlag(name string) bool {
+	switch name {
+	case "-f", "--values", "--set", "--set-string", "--set-file", "--set-json", "--set-literal",
+		"--timeout", "--history-max", "--description", "-l", "--labels", "--post-renderer", "--post-renderer-args":
+		return true
+	}
+	return false
+}
+
+// parseHelmLabels parses labels in the "key1=value1,key2=value2" format of the helm cli
+func parseHelmLabels(value string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || k == "" {
+			return nil, errors.Errorf("invalid label %q", pair)
+		}
+		labels[k] = v
+	}
+	return labels, nil
+}
+
+func (c *Client) getHelmActionConfig(namespace string) (*cli.EnvSettings, *action.Configuration, error) {
+	settings := cli.New()
+	settings.SetNamespace(namespace)
+
+	restClientGetter := settings.RESTClientGetter()
+	if c.RESTConfig != nil {
+		restClientGetter = k8sutil.NewRESTClientGetter(c.RESTConfig, namespace)
+	}
+
+	cfg := &action.Configuration{}
+	if err := cfg.Init(restClientGetter, namespace, os.Getenv("HELM_DRIVER"), logger.Debugf); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to init helm action config")
+	}
+
+	return settings, cfg, nil
+}
+
+// upgradeHelmRelease installs the chart if the release does not exist, otherwise it upgrades it
+func (c *Client) upgradeHelmRelease(dir orderedDir, chartPath string, valueFiles []string) operatortypes.HelmReleaseResult {
+	result := operatortypes.HelmReleaseResult{
+		ReleaseName:  dir.ReleaseName,
+		Namespace:    dir.Namespace,
+		ChartName:    dir.ChartName,
+		ChartVersion: dir.ChartVersion,
+	}
+
+	rel, err := c.runHelmUpgrade(dir, chartPath, valueFiles)
+	setHelmReleaseStatus(&result, rel)
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func (c *Client) runHelmUpgrade(dir orderedDir, chartPath string, valueFiles []string) (*release.Release, error) {
+	opts, err := parseHelmUpgradeFlags(dir.UpgradeFlags)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse helm upgrade flags")
+	}
+	// values from the upgrade flags take precedence over the chart's values
+	opts.Values.ValueFiles = append(valueFiles, opts.Values.ValueFiles...)
+
+	settings, cfg, err := c.getHelmActionConfig(dir.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get helm action config")
+	}
+
+	chart, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load chart")
+	}
+	if req := chart.Metadata.Dependencies; req != nil {
+		if err := action.CheckDependencies(chart, req); err != nil {
+			return nil, errors.Wrap(err, "failed dependency check")
+		}
+	}
+
+	vals, err := opts.Values.MergeValues(getter.All(settings))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to merge values")
+	}
+
+	var postRenderer postrender.PostRenderer
+	if opts.PostRenderer != "" {
+		postRenderer, err = postrender.NewExec(opts.PostRenderer, opts.PostRendererArgs...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create post renderer")
+		}
+	}
+
+	history := action.NewHistory(cfg)
+	history.Max = 1
+	versions, err := history.Run(dir.ReleaseName)
+	isUninstalled := len(versions) > 0 && versions[len(versions)-1].Info.Status == release.StatusUninstalled
+	if err == driver.ErrReleaseNotFound || isUninstalled {
+		logger.Infof("installing helm release %s in namespace %s", dir.ReleaseName, dir.Namespace)
+
+		install := action.NewInstall(cfg)
+		install.ReleaseName = dir.ReleaseName
+		install.Namespace = dir.Namespace
+		install.Replace = isUninstalled
+		install.Atomic = opts.Atomic
+		install.CreateNamespace = opts.CreateNamespace
+		install.DisableHooks = opts.DisableHooks
+		install.Force = opts.Force
+		install.SkipCRDs = opts.SkipCRDs
+		install.SkipSchemaValidation = opts.SkipSchemaValidation
+		install.DisableOpenAPIValidation = opts.DisableOpenAPIValidation
+		install.TakeOwnership = opts.TakeOwnership
+		install.EnableDNS = opts.EnableDNS
+		install.SubNotes = opts.SubNotes
+		install.HideNotes = opts.HideNotes
+		install.Wait = opts.Wait
+		install.WaitForJobs = opts.WaitForJobs
+		install.Timeout = opts.Timeout
+		install.Description = opts.Description
+		install.Labels = opts.Labels
+		install.PostRenderer = postRenderer
+
+		rel, err := install.Run(chart, vals)
+		if err != nil {
+			return rel, errors.Wrap(err, "failed to install release")
+		}
+		return rel, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get release history")
+	}
+
+	logger.Infof("upgrading helm release %s in namespace %s", dir.ReleaseName, dir.Namespace)
+
+	upgrade := action.NewUpgrade(cfg)
+	upgrade.Install = true
+	upgrade.Namespace = dir.Namespace
+	upgrade.Atomic = opts.Atomic
+	upgrade.CleanupOnFail = opts.CleanupOnFail
+	upgrade.DisableHooks = opts.DisableHooks
+	upgrade.Force = opts.Force
+	upgrade.ResetValues = opts.ResetValues
+	upgrade.ReuseValues = opts.ReuseValues
+	upgrade.ResetThenReuseValues = opts.ResetThenReuseValues
+	upgrade.Recreate = opts.Recreate
+	upgrade.SkipCRDs = opts.SkipCRDs
+	upgrade.SkipSchemaValidation = opts.SkipSchemaValidation
+	upgrade.DisableOpenAPIValidation = opts.DisableOpenAPIValidation
+	upgrade.TakeOwnership = opts.TakeOwnership
+	upgrade.EnableDNS = opts.EnableDNS
+	upgrade.SubNotes = opts.SubNotes
+	upgrade.HideNotes = opts.HideNotes
+	upgrade.Wait = opts.Wait
+	upgrade.WaitForJobs = opts.WaitForJobs
+	upgrade.Timeout = opts.Timeout
+	upgrade.MaxHistory = opts.MaxHistory
+	upgrade.Description = opts.Description
+	upgrade.Labels = opts.Labels
+	upgrade.PostRenderer = postRenderer
+
+	rel, err := upgrade.Run(dir.ReleaseName, chart, vals)
+	if err != nil {
+		return rel, errors.Wrap(err, "failed to upgrade release")
+	}
+	return rel, nil
+}
+
+// uninstallHelmRelease uninstalls a release. Releases that do not exist are not an error.
+func (c *Client) uninstallHelmRelease(dir orderedDir) operatortypes.HelmReleaseResult {
+	result := operatortypes.HelmReleaseResult{
+		ReleaseName:  dir.ReleaseName,
+		Namespace:    dir.Namespace,
+		ChartName:    dir.ChartName,
+		ChartVersion: dir.ChartVersion,
+	}
+
+	_, cfg, err := c.getHelmActionConfig(dir.Namespace)
+	if err != nil {
+		result.Error = errors.Wrap(err, "failed to get helm action config").Error()
+		return result
+	}
+
+	uninstall := action.NewUninstall(cfg)
+	uninstall.IgnoreNotFound = true
+
+	resp, err := uninstall.Run(dir.ReleaseName)
+	if resp != nil {
+		setHelmReleaseStatus(&result, resp.Release)
+	}
+	if err != nil {
+		result.Error = errors.Wrap(err, "failed to uninstall release").Error()
+	}
+
+	return result
+}
+
+func setHelmReleaseStatus(result *operatortypes.HelmReleaseResult, rel *release.Release) {
+	if rel == nil {
+		return
+	}
+	result.Revision = rel.Version
+	if rel.Info != nil {
+		result.Status = rel.Info.Status.String()
+		result.Description = rel.Info.Description
+	}
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/cli/values"
+)
+
+func Test_parseHelmUpgradeFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   []string
+		want    *helmUpgradeOptions
+		wantErr bool
+	}{
+		{
+			name:  "no flags",
+			flags: nil,
+			want: &helmUpgradeOptions{
+				Timeout: defaultHelmTimeout,
+			},
+		},
+		{
+			name:  "atomic upgrade with values",
+			flags: []string{"--atomic", "--cleanup-on-fail", "--timeout", "10m", "--set", "replicas=2", "-f", "extra.yaml", "--set-string=tag=1.0"},
+			want: &helmUpgradeOptions{
+				Atomic:        true,
+				CleanupOnFail: true,
+				Timeout:       10 * time.Minute,
+				Values: values.Options{
+					ValueFiles:   []string{"extra.yaml"},
+					Values:       []string{"replicas=2"},
+					StringValues: []string{"tag=1.0"},
+				},
+			},
+		},
+		{
+			name:  "explicit bool values and ignored flags",
+			flags: []string{"--install", "--wait=true", "--force=false", "--history-max=5", "--description", "deployed by kots"},
+			want: &helmUpgradeOptions{
+				Wait:        true,
+				Timeout:     defaultHelmTimeout,
+				MaxHistory:  5,
+				Description: "deployed by kots",
+			},
+		},
+		{
+			name:    "missing value",
+			flags:   []string{"--timeout"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid timeout",
+			flags:   []string{"--timeout=forever"},
+			wantErr: true,
+		},
+		{
+			name:  "flags of newer helm versions",
+			flags: []string{"--skip-schema-validation", "--take-ownership", "--render-subchart-notes", "--labels", "team=web,tier=frontend", "-l=env=prod", "--post-renderer", "./kustomize.sh", "--dependency-update"},
+			want: &helmUpgradeOptions{
+				SkipSchemaValidation: true,
+				TakeOwnership:        true,
+				SubNotes:             true,
+				Timeout:              defaultHelmTimeout,
+				Labels:               map[string]string{"team": "web", "tier": "frontend", "env": "prod"},
+				PostRenderer:         "./kustomize.sh",
+			},
+		},
+		{
+			name:  "unknown flags are ignored",
+			flags: []string{"--kube-context", "other", "--some-new-flag", "--wait", "--another=value"},
+			want: &helmUpgradeOptions{
+				Wait:    true,
+				Timeout: defaultHelmTimeout,
+			},
+		},
+		{
+			name:    "invalid labels",
+			flags:   []string{"--labels", "team"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHelmUpgradeFlags(tt.flags)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
-	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/logger"
 	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,7 +17,7 @@ import (
 func (c *Client) runHooksInformer(namespace string) error {
 	logger.Infof("running hooks informer for namespace %s", namespace)
 
-	clientset, err := k8sutil.GetClientset()
+	clientset, err := c.getClientset()
 	if err != nil {
 		return errors.Wrap(err, "failed to get clientset")
 	}
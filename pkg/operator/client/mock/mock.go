@@ -85,6 +85,35 @@ func (mr *MockClientInterfaceMockRecorder) DeployApp(deployArgs interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployApp", reflect.TypeOf((*MockClientInterface)(nil).DeployApp), deployArgs)
 }
 
+// DeployCanary mocks base method.
+func (m *MockClientInterface) DeployCanary(deployArgs types.DeployAppArgs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeployCanary", deployArgs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeployCanary indicates an expected call of DeployCanary.
+func (mr *MockClientInterfaceMockRecorder) DeployCanary(deployArgs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeployCanary", reflect.TypeOf((*MockClientInterface)(nil).DeployCanary), deployArgs)
+}
+
+// DetectDrift mocks base method.
+func (m *MockClientInterface) DetectDrift(args types.DetectDriftArgs) ([]types.DriftedResource, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetectDrift", args)
+	ret0, _ := ret[0].([]types.DriftedResource)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DetectDrift indicates an expected call of DetectDrift.
+func (mr *MockClientInterfaceMockRecorder) DetectDrift(args interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetectDrift", reflect.TypeOf((*MockClientInterface)(nil).DetectDrift), args)
+}
+
 // Init mocks base method.
 func (m *MockClientInterface) Init() error {
 	m.ctrl.T.Helper()
@@ -4,14 +4,13 @@ import (
 	"log"
 
 	"github.com/pkg/errors"
-	"github.com/replicatedhq/kots/pkg/k8sutil"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/tools/cache"
 )
 
 func (c *Client) runNamespacesInformer() error {
-	clientset, err := k8sutil.GetClientset()
+	clientset, err := c.getClientset()
 	if err != nil {
 		return errors.Wrap(err, "failed to get clientset")
 	}
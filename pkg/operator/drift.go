@@ -0,0 +1,135 @@
+package operator
+
+import (
+	"encoding/base64"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	"github.com/replicatedhq/kots/pkg/apparchive"
+	"github.com/replicatedhq/kots/pkg/binaries"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/midstream"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
+	storetypes "github.com/replicatedhq/kots/pkg/store/types"
+)
+
+// driftCheckIntervalSeconds is how often the deployed version of each app is compared with the cluster
+const driftCheckIntervalSeconds = 300
+
+// startDriftLoop starts checking for drift after the first interval, so that deployments resumed on start don't race with it
+func (o *Operator) startDriftLoop() {
+	go func() {
+		time.Sleep(time.Second * driftCheckIntervalSeconds)
+		startLoop(o.driftLoop, driftCheckIntervalSeconds)
+	}()
+}
+
+func (o *Operator) driftLoop() {
+	apps, err := o.store.ListAppsForDownstream(o.clusterID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list installed apps for downstream"))
+		return
+	}
+
+	for _, a := range apps {
+		if err := o.checkDriftForApp(a); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to check drift for app %s", a.ID))
+			continue
+		}
+	}
+}
+
+func (o *Operator) checkDriftForApp(a *apptypes.App) error {
+	if a.RestoreInProgressName != "" {
+		return nil
+	}
+
+	deployedVersion, err := o.store.GetCurrentDownstreamVersion(a.ID, o.clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get current downstream version")
+	} else if deployedVersion == nil || deployedVersion.Status != storetypes.VersionDeployed {
+		return nil
+	}
+
+	// don't compare with the cluster while a deploy is changing it
+	deployMtx := o.getDeployMtx(a.ID)
+	if !deployMtx.TryLock() {
+		return nil
+	}
+	defer deployMtx.Unlock()
+
+	sequence := deployedVersion.ParentSequence
+
+	autoRemediate, err := o.store.GetAppDriftAutoRemediate(a.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get drift auto remediate setting")
+	}
+
+	drifted, driftErr := o.detectDrift(a, sequence, autoRemediate)
+	if driftErr != nil {
+		logger.Error(errors.Wrapf(driftErr, "failed to detect drift for app %s", a.Slug))
+		drifted = []operatortypes.DriftedResource{}
+	}
+
+	for _, r := range drifted {
+		if r.Remediated {
+			logger.Infof("re-applied drifted resource of app %s: %s", a.Slug, r.String())
+		} else {
+			logger.Infof("detected drifted resource of app %s: %s", a.Slug, r.String())
+		}
+	}
+
+	errMsg := ""
+	if driftErr != nil {
+		errMsg = driftErr.Error()
+	}
+	if err := o.store.SetAppDriftStatus(a.ID, sequence, drifted, time.Now(), errMsg); err != nil {
+		return errors.Wrap(err, "failed to set drift status")
+	}
+
+	return nil
+}
+
+// detectDrift renders the manifests of a deployed version the same way they are deployed and compares them with the cluster
+func (o *Operator) detectDrift(a *apptypes.App, sequence int64, remediate bool) ([]operatortypes.DriftedResource, error) {
+	downstream, err := o.store.GetDownstream(o.clusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get downstream")
+	}
+
+	deployedVersionArchive, err := os.MkdirTemp("", "kotsadm")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create temp dir")
+	}
+	defer os.RemoveAll(deployedVersionArchive)
+
+	if err := o.store.GetAppVersionArchive(a.ID, sequence, deployedVersionArchive); err != nil {
+		return nil, errors.Wrap(err, "failed to get app version archive")
+	}
+
+	additionalLabels := map[string]string{
+		"kots.io/app-slug": a.Slug,
+	}
+	if err := midstream.EnsureDisasterRecoveryLabelTransformer(deployedVersionArchive, additionalLabels); err != nil {
+		return nil, errors.Wrap(err, "failed to ensure disaster recovery label transformer")
+	}
+
+	renderedManifests, _, err := apparchive.GetRenderedApp(deployedVersionArchive, downstream.Name, binaries.GetKustomizeBinPath())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get rendered app")
+	}
+
+	drifted, err := o.client.DetectDrift(operatortypes.DetectDriftArgs{
+		AppSlug:      a.Slug,
+		Manifests:    base64.StdEncoding.EncodeToString(renderedManifests),
+		AnnotateSlug: os.Getenv("ANNOTATE_SLUG") != "",
+		Remediate:    remediate,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to detect drift")
+	}
+
+	return drifted, nil
+}
@@ -20,6 +20,8 @@ import (
 	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
 	"github.com/replicatedhq/kots/pkg/binaries"
 	"github.com/replicatedhq/kots/pkg/embeddedcluster"
+	"github.com/replicatedhq/kots/pkg/eventstream"
+	eventstreamtypes "github.com/replicatedhq/kots/pkg/eventstream/types"
 	"github.com/replicatedhq/kots/pkg/filestore"
 	identitydeploy "github.com/replicatedhq/kots/pkg/identity/deploy"
 	identitytypes "github.com/replicatedhq/kots/pkg/identity/types"
@@ -28,7 +30,10 @@ import (
 	snapshot "github.com/replicatedhq/kots/pkg/kotsadmsnapshot"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/metrics"
 	"github.com/replicatedhq/kots/pkg/midstream"
+	"github.com/replicatedhq/kots/pkg/notifications"
+	notificationstypes "github.com/replicatedhq/kots/pkg/notifications/types"
 	"github.com/replicatedhq/kots/pkg/operator/client"
 	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
 	registrytypes "github.com/replicatedhq/kots/pkg/registry/types"
@@ -61,13 +66,19 @@ var (
 	operator *Operator
 )
 
+// cloneRestoreIntervalSeconds is how often the resources of completed clone restores are relabeled
+const cloneRestoreIntervalSeconds = 10
+
 type Operator struct {
 	client       client.ClientInterface
 	store        store.Store
 	clusterToken string
 	clusterID    string
 	deployMtxs   map[string]*sync.Mutex // key is app id
+	deployMtxsMu sync.Mutex
 	k8sClientset kubernetes.Interface
+	// remote is true for operators of clusters other than the one kotsadm runs in
+	remote bool
 }
 
 func Init(client client.ClientInterface, store store.Store, clusterToken string, k8sClientset kubernetes.Interface) *Operator {
@@ -81,6 +92,20 @@ func Init(client client.ClientInterface, store store.Store, clusterToken string,
 	return operator
 }
 
+// getDeployMtx returns the mutex that serializes changes to the resources of an app
+func (o *Operator) getDeployMtx(appID string) *sync.Mutex {
+	o.deployMtxsMu.Lock()
+	defer o.deployMtxsMu.Unlock()
+
+	if o.deployMtxs == nil {
+		o.deployMtxs = map[string]*sync.Mutex{}
+	}
+	if _, ok := o.deployMtxs[appID]; !ok {
+		o.deployMtxs[appID] = &sync.Mutex{}
+	}
+	return o.deployMtxs[appID]
+}
+
 func MustGetOperator() *Operator {
 	if operator != nil {
 		return operator
@@ -105,6 +130,9 @@ func (o *Operator) Start() error {
 	go o.resumeDeployments()
 	o.watchDeployments()
 	startLoop(o.restoreLoop, 2)
+	startLoop(o.cloneRestoreLoop, cloneRestoreIntervalSeconds)
+	o.startDriftLoop()
+	startLoop(o.remoteClustersLoop, remoteClustersIntervalSeconds)
 
 	return nil
 }
@@ -165,13 +193,17 @@ func (o *Operator) resumeDeployment(a *apptypes.App) (bool, error) {
 }
 
 func (o *Operator) DeployApp(appID string, sequence int64) (deployed bool, deployError error) {
-	if _, ok := o.deployMtxs[appID]; !ok {
-		o.deployMtxs[appID] = &sync.Mutex{}
-	}
-	o.deployMtxs[appID].Lock()
-	defer o.deployMtxs[appID].Unlock()
+	return o.deployApp(appID, sequence, false)
+}
+
+// deployApp deploys a version of an app. Rollbacks are never deployed progressively
+// so that a failed rollback can't trigger another rollback.
+func (o *Operator) deployApp(appID string, sequence int64, isRollback bool) (deployed bool, deployError error) {
+	deployMtx := o.getDeployMtx(appID)
+	deployMtx.Lock()
+	defer deployMtx.Unlock()
 
-	if err := o.store.SetDownstreamVersionStatus(appID, sequence, storetypes.VersionDeploying, ""); err != nil {
+	if err := o.setDownstreamVersionStatus(appID, sequence, storetypes.VersionDeploying, ""); err != nil {
 		return false, errors.Wrap(err, "failed to update downstream status")
 	}
 
@@ -191,22 +223,33 @@ func (o *Operator) DeployApp(appID string, sequence int64) (deployed bool, deplo
 		}()
 	}
 
+	startedAt := time.Now()
+	defer func() {
+		status := metrics.DeployStatusDeployed
+		if deployError != nil || !deployed {
+			status = metrics.DeployStatusFailed
+		}
+		metrics.ObserveDeploy(appID, status, time.Since(startedAt))
+
+		notifyDeployResult(appID, sequence, deployed, deployError)
+	}()
+
 	defer func() {
 		if deployError != nil {
-			err := o.store.SetDownstreamVersionStatus(appID, sequence, storetypes.VersionFailed, deployError.Error())
+			err := o.setDownstreamVersionStatus(appID, sequence, storetypes.VersionFailed, deployError.Error())
 			if err != nil {
 				logger.Error(errors.Wrap(err, "failed to update downstream status"))
 			}
 			return
 		}
 		if !deployed {
-			err := o.store.SetDownstreamVersionStatus(appID, sequence, storetypes.VersionFailed, "")
+			err := o.setDownstreamVersionStatus(appID, sequence, storetypes.VersionFailed, "")
 			if err != nil {
 				logger.Error(errors.Wrap(err, "failed to update downstream status"))
 			}
 			return
 		}
-		err := o.store.SetDownstreamVersionStatus(appID, sequence, storetypes.VersionDeployed, "")
+		err := o.setDownstreamVersionStatus(appID, sequence, storetypes.VersionDeployed, "")
 		if err != nil {
 			logger.Error(errors.Wrap(err, "failed to update downstream status"))
 		}
@@ -255,8 +298,10 @@ func (o *Operator) DeployApp(appID string, sequence int64) (deployed bool, deplo
 		return false, errors.Wrap(err, "failed to get registry settings for app")
 	}
 
-	if err := o.ensureKotsadmApplicationMetadataConfigMap(app, sequence, util.PodNamespace, kotsKinds, registrySettings); err != nil {
-		return false, errors.Wrap(err, "failed to ensure kotsadm application metadata configmap")
+	if !o.remote {
+		if err := o.ensureKotsadmApplicationMetadataConfigMap(app, sequence, util.PodNamespace, kotsKinds, registrySettings); err != nil {
+			return false, errors.Wrap(err, "failed to ensure kotsadm application metadata configmap")
+		}
 	}
 
 	builder, err := render.NewBuilder(kotsKinds, registrySettings, app.Slug, sequence, app.IsAirgap, util.PodNamespace)
@@ -344,6 +389,7 @@ func (o *Operator) DeployApp(appID string, sequence int64) (deployed bool, deplo
 	base64EncodedPreviousManifests := ""
 	previousV1beta1ChartsArchive := []byte{}
 	previousV1beta2ChartsArchive := []byte{}
+	rollbackSequence := int64(-1)
 	previouslyDeployedSequence, err := o.store.GetPreviouslyDeployedSequence(app.ID, o.clusterID)
 	if err != nil {
 		return false, errors.Wrap(err, "failed to get previously deployed sequence")
@@ -355,6 +401,8 @@ func (o *Operator) DeployApp(appID string, sequence int64) (deployed bool, deplo
 		}
 
 		if previouslyDeployedParentSequence != -1 {
+			rollbackSequence = previouslyDeployedParentSequence
+
 			previouslyDeployedVersionArchive, err := os.MkdirTemp("", "kotsadm")
 			if err != nil {
 				return false, errors.Wrap(err, "failed to create temp dir")
@@ -415,6 +463,19 @@ func (o *Operator) DeployApp(appID string, sequence int64) (deployed bool, deplo
 		KotsKinds:                    kotsKinds,
 		PreviousKotsKinds:            previousKotsKinds,
 	}
+
+	var rollout *rolloutConfig
+	// progressive rollouts are only supported in the cluster kotsadm runs in
+	if !isRollback && rollbackSequence != -1 && !o.remote {
+		rollout, err = getRolloutConfig(kotsKinds.KotsApplication.Annotations, renderStatusInformers(app, kotsKinds, builder), util.AppNamespace())
+		if err != nil {
+			return false, errors.Wrap(err, "failed to get rollout config")
+		}
+	}
+	if rollout != nil {
+		return o.deployProgressively(deployArgs, rollout, rollbackSequence)
+	}
+
 	deployed, err = o.client.DeployApp(deployArgs)
 	if err != nil {
 		return false, errors.Wrap(err, "failed to deploy app")
@@ -424,27 +485,7 @@ func (o *Operator) DeployApp(appID string, sequence int64) (deployed bool, deplo
 }
 
 func (o *Operator) applyStatusInformers(a *apptypes.App, sequence int64, kotsKinds *kotsutil.KotsKinds, builder *template.Builder) error {
-	renderedInformers := []appstatetypes.StatusInformerString{}
-
-	// deploy status informers
-	if len(kotsKinds.KotsApplication.Spec.StatusInformers) > 0 {
-		// render status informers
-		for _, informer := range kotsKinds.KotsApplication.Spec.StatusInformers {
-			renderedInformer, err := builder.String(informer)
-			if err != nil {
-				logger.Error(errors.Wrap(err, "failed to render status informer"))
-				continue
-			}
-			if renderedInformer == "" {
-				continue
-			}
-			renderedInformers = append(renderedInformers, appstatetypes.StatusInformerString(renderedInformer))
-		}
-	}
-
-	if identitydeploy.IsEnabled(kotsKinds.Identity, kotsKinds.IdentityConfig) {
-		renderedInformers = append(renderedInformers, appstatetypes.StatusInformerString(fmt.Sprintf("deployment/%s", identitytypes.DeploymentName(a.Slug))))
-	}
+	renderedInformers := renderStatusInformers(a, kotsKinds, builder)
 
 	if len(renderedInformers) > 0 {
 		informersArgs := operatortypes.AppInformersArgs{
@@ -464,7 +505,7 @@ func (o *Operator) applyStatusInformers(a *apptypes.App, sequence int64, kotsKin
 			},
 		}
 
-		err := o.store.SetAppStatus(a.ID, defaultReadyState, time.Now(), sequence)
+		err := o.setAppStatus(a.ID, defaultReadyState, time.Now(), sequence)
 		if err != nil {
 			return errors.Wrap(err, "failed to set app status")
 		}
@@ -480,6 +521,32 @@ func (o *Operator) applyStatusInformers(a *apptypes.App, sequence int64, kotsKin
 	return nil
 }
 
+func renderStatusInformers(a *apptypes.App, kotsKinds *kotsutil.KotsKinds, builder *template.Builder) []appstatetypes.StatusInformerString {
+	renderedInformers := []appstatetypes.StatusInformerString{}
+
+	// deploy status informers
+	if len(kotsKinds.KotsApplication.Spec.StatusInformers) > 0 {
+		// render status informers
+		for _, informer := range kotsKinds.KotsApplication.Spec.StatusInformers {
+			renderedInformer, err := builder.String(informer)
+			if err != nil {
+				logger.Error(errors.Wrap(err, "failed to render status informer"))
+				continue
+			}
+			if renderedInformer == "" {
+				continue
+			}
+			renderedInformers = append(renderedInformers, appstatetypes.StatusInformerString(renderedInformer))
+		}
+	}
+
+	if identitydeploy.IsEnabled(kotsKinds.Identity, kotsKinds.IdentityConfig) {
+		renderedInformers = append(renderedInformers, appstatetypes.StatusInformerString(fmt.Sprintf("deployment/%s", identitytypes.DeploymentName(a.Slug))))
+	}
+
+	return renderedInformers
+}
+
 func (o *Operator) resumeInformers() {
 	apps, err := o.store.ListAppsForDownstream(o.clusterID)
 	if err != nil {
@@ -560,6 +627,13 @@ func (o *Operator) restoreLoop() {
 	}
 }
 
+// cloneRestoreLoop labels the resources of apps that were restored next to the original app with the app slug of their clone
+func (o *Operator) cloneRestoreLoop() {
+	if err := snapshot.RelabelCompletedCloneRestores(context.Background(), util.PodNamespace); err != nil {
+		logger.Error(errors.Wrap(err, "failed to relabel completed clone restores"))
+	}
+}
+
 func (o *Operator) processRestoreForApp(a *apptypes.App) error {
 	if a.RestoreInProgressName == "" {
 		return nil
@@ -712,11 +786,9 @@ func (o *Operator) checkRestoreComplete(a *apptypes.App, restore *velerov1.Resto
 }
 
 func (o *Operator) UndeployApp(a *apptypes.App, d *downstreamtypes.Downstream, isRestore bool) error {
-	if _, ok := o.deployMtxs[a.ID]; !ok {
-		o.deployMtxs[a.ID] = &sync.Mutex{}
-	}
-	o.deployMtxs[a.ID].Lock()
-	defer o.deployMtxs[a.ID].Unlock()
+	deployMtx := o.getDeployMtx(a.ID)
+	deployMtx.Lock()
+	defer deployMtx.Unlock()
 
 	deployedVersion, err := o.store.GetCurrentDownstreamVersion(a.ID, d.ClusterID)
 	if err != nil {
@@ -1123,3 +1195,33 @@ func (o *Operator) notifyClusterUpgradeFailed(ctx context.Context, kbClient kbcl
 	}
 	return nil
 }
+
+func notifyDeployResult(appID string, sequence int64, deployed bool, deployError error) {
+	event := notificationstypes.Event{
+		Type:     notificationstypes.EventDeploySucceeded,
+		AppID:    appID,
+		Sequence: &sequence,
+	}
+	if deployError != nil || !deployed {
+		event.Type = notificationstypes.EventDeployFailed
+		if deployError != nil {
+			event.Data = map[string]interface{}{
+				"error": deployError.Error(),
+			}
+		}
+	}
+	notifications.Notify(event)
+
+	result := eventstreamtypes.DeployResult{
+		Deployed: deployed && deployError == nil,
+	}
+	if deployError != nil {
+		result.Error = deployError.Error()
+	}
+	eventstream.Publish(eventstreamtypes.Event{
+		Type:     eventstreamtypes.EventDeployResult,
+		AppID:    appID,
+		Sequence: &sequence,
+		Data:     result,
+	})
+}
@@ -67,6 +67,7 @@ spec:
 				mockClient.EXPECT().Init().Return(nil)
 
 				mockStore.EXPECT().GetClusterIDFromDeployToken(clusterToken).Return("", nil)
+				mockStore.EXPECT().ListClusters().AnyTimes().Return(nil, nil)
 
 				apps := []*apptypes.App{
 					{
@@ -160,6 +161,7 @@ spec:
 				mockClient.EXPECT().Init().Return(nil)
 
 				mockStore.EXPECT().GetClusterIDFromDeployToken(clusterToken).Return("", nil)
+				mockStore.EXPECT().ListClusters().AnyTimes().Return(nil, nil)
 
 				apps := []*apptypes.App{
 					{
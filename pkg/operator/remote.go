@@ -0,0 +1,179 @@
+package operator
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/cluster"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/operator/client"
+	"github.com/replicatedhq/kots/pkg/store"
+	storetypes "github.com/replicatedhq/kots/pkg/store/types"
+)
+
+// remoteClustersIntervalSeconds is how often operators are started and stopped for registered remote clusters
+const remoteClustersIntervalSeconds = 30
+
+var (
+	remoteOperators    = map[string]*Operator{} // key is cluster id
+	remoteOperatorsMtx sync.Mutex
+)
+
+// InitRemote creates an operator for a remote cluster. The client must be configured for that cluster.
+func InitRemote(client client.ClientInterface, store store.Store, clusterID string) *Operator {
+	return &Operator{
+		client:     client,
+		store:      store,
+		clusterID:  clusterID,
+		remote:     true,
+		deployMtxs: map[string]*sync.Mutex{},
+	}
+}
+
+// GetOperatorForCluster returns the operator that deploys to a cluster
+func GetOperatorForCluster(clusterID string) (*Operator, error) {
+	local := MustGetOperator()
+	if clusterID == "" || clusterID == local.clusterID {
+		return local, nil
+	}
+
+	remoteOperatorsMtx.Lock()
+	defer remoteOperatorsMtx.Unlock()
+
+	o, ok := remoteOperators[clusterID]
+	if !ok {
+		return nil, errors.Errorf("cluster %s is not connected", clusterID)
+	}
+
+	return o, nil
+}
+
+func (o *Operator) startRemote() error {
+	logger.Debugf("starting the operator for cluster %s", o.clusterID)
+
+	if err := o.client.Init(); err != nil {
+		return errors.Wrap(err, "failed to initialize the operator client")
+	}
+
+	go o.resumeInformers()
+	go o.resumeDeployments()
+
+	return nil
+}
+
+// remoteClustersLoop runs on the operator of the local cluster and keeps an operator running for every remote cluster
+// that can be reached
+func (o *Operator) remoteClustersLoop() {
+	clusters, err := o.store.ListClusters()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list clusters"))
+		return
+	}
+
+	reachable := map[string]bool{}
+	for _, c := range clusters {
+		if !cluster.IsRemote(c) || !cluster.IsReachable(c) {
+			continue
+		}
+		reachable[c.ClusterID] = true
+
+		remoteOperatorsMtx.Lock()
+		_, ok := remoteOperators[c.ClusterID]
+		remoteOperatorsMtx.Unlock()
+		if ok {
+			continue
+		}
+
+		config, err := cluster.GetRESTConfigForCluster(c)
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to get config for cluster %s", c.ClusterID))
+			continue
+		}
+
+		remoteClient := &client.Client{
+			TargetNamespace:       c.TargetNamespace,
+			ClusterID:             c.ClusterID,
+			RESTConfig:            config,
+			ExistingHookInformers: map[string]bool{},
+			HookStopChans:         []chan struct{}{},
+		}
+		remote := InitRemote(remoteClient, o.store, c.ClusterID)
+		if err := remote.startRemote(); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to start operator for cluster %s", c.ClusterID))
+			remoteClient.Shutdown()
+			continue
+		}
+
+		remoteOperatorsMtx.Lock()
+		remoteOperators[c.ClusterID] = remote
+		remoteOperatorsMtx.Unlock()
+
+		logger.Infof("started operator for cluster %s", c.Name)
+	}
+
+	// stop operators for clusters that were removed or whose agent disconnected,
+	// they are started again once the cluster can be reached
+	remoteOperatorsMtx.Lock()
+	defer remoteOperatorsMtx.Unlock()
+	for clusterID, remote := range remoteOperators {
+		if reachable[clusterID] {
+			continue
+		}
+		remote.Shutdown()
+		delete(remoteOperators, clusterID)
+		logger.Infof("stopped operator for cluster %s", clusterID)
+	}
+}
+
+func (o *Operator) setDownstreamVersionStatus(appID string, sequence int64, status storetypes.DownstreamVersionStatus, statusInfo string) error {
+	if o.remote {
+		return o.store.SetDownstreamVersionStatusForCluster(appID, o.clusterID, sequence, status, statusInfo)
+	}
+	return o.store.SetDownstreamVersionStatus(appID, sequence, status, statusInfo)
+}
+
+func (o *Operator) setAppStatus(appID string, resourceStates appstatetypes.ResourceStates, updatedAt time.Time, sequence int64) error {
+	if o.remote {
+		return o.store.SetDownstreamAppStatus(appID, o.clusterID, resourceStates, updatedAt, sequence)
+	}
+	return o.store.SetAppStatus(appID, resourceStates, updatedAt, sequence)
+}
+
+// DeployToCluster marks a version as the current version of the app in a cluster and deploys it in the background
+func DeployToCluster(appID string, clusterID string, sequence int64) error {
+	c, err := store.GetStore().GetCluster(clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster")
+	}
+	if c == nil {
+		return errors.Errorf("cluster %s not found", clusterID)
+	}
+
+	op, err := GetOperatorForCluster(clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get operator for cluster")
+	}
+
+	if err := store.GetStore().DeleteDownstreamDeployStatus(appID, clusterID, sequence); err != nil {
+		return errors.Wrap(err, "failed to delete downstream deploy status")
+	}
+
+	if cluster.IsRemote(c) {
+		err = store.GetStore().MarkAsCurrentDownstreamVersionForCluster(appID, clusterID, sequence)
+	} else {
+		err = store.GetStore().MarkAsCurrentDownstreamVersion(appID, sequence)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to mark as current downstream version")
+	}
+
+	go func() {
+		if _, err := op.DeployApp(appID, sequence); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to deploy version %d of app %s to cluster %s", sequence, appID, c.Name))
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,316 @@
+package operator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/appstate"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/logger"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/util"
+	yaml "github.com/replicatedhq/yaml/v3"
+)
+
+// Progressive rollouts are enabled with annotations on the kots Application:
+//
+//	kots.io/rollout-strategy: progressive
+//	kots.io/rollout-canary-informers: deployment/web   # defaults to the first status informer
+//	kots.io/rollout-timeout: 10m
+//
+// The canary workloads are deployed first, together with all non-workload resources.
+// Once they're ready, the full version is deployed. If either step does not become ready
+// within the timeout, the previously deployed version is redeployed.
+const (
+	RolloutStrategyAnnotation        = "kots.io/rollout-strategy"
+	RolloutCanaryInformersAnnotation = "kots.io/rollout-canary-informers"
+	RolloutTimeoutAnnotation         = "kots.io/rollout-timeout"
+
+	RolloutStrategyProgressive = "progressive"
+
+	DefaultRolloutTimeout = 10 * time.Minute
+)
+
+var rolloutPollInterval = 5 * time.Second
+
+// workloadKinds are the status informer kinds that are held back until the canary is ready
+var workloadKinds = map[string]bool{
+	appstate.DeploymentResourceKind:  true,
+	appstate.StatefulSetResourceKind: true,
+	appstate.DaemonSetResourceKind:   true,
+}
+
+type rolloutConfig struct {
+	CanaryInformers []appstatetypes.StatusInformer
+	AllInformers    []appstatetypes.StatusInformer
+	Timeout         time.Duration
+}
+
+// getRolloutConfig returns nil if the app does not use progressive rollouts
+func getRolloutConfig(annotations map[string]string, informers []appstatetypes.StatusInformerString, targetNamespace string) (*rolloutConfig, error) {
+	if annotations[RolloutStrategyAnnotation] != RolloutStrategyProgressive {
+		return nil, nil
+	}
+
+	allInformers, err := parseStatusInformers(informers, targetNamespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse status informers")
+	}
+	if len(allInformers) == 0 {
+		logger.Infof("progressive rollout requires status informers, deploying all resources at once")
+		return nil, nil
+	}
+
+	config := &rolloutConfig{
+		AllInformers: allInformers,
+		Timeout:      DefaultRolloutTimeout,
+	}
+
+	if val := annotations[RolloutTimeoutAnnotation]; val != "" {
+		timeout, err := time.ParseDuration(val)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s annotation", RolloutTimeoutAnnotation)
+		}
+		config.Timeout = timeout
+	}
+
+	if val := annotations[RolloutCanaryInformersAnnotation]; val != "" {
+		canaryInformerStrings := []appstatetypes.StatusInformerString{}
+		for _, s := range strings.Split(val, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				canaryInformerStrings = append(canaryInformerStrings, appstatetypes.StatusInformerString(s))
+			}
+		}
+		canaryInformers, err := parseStatusInformers(canaryInformerStrings, targetNamespace)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s annotation", RolloutCanaryInformersAnnotation)
+		}
+		for _, canaryInformer := range canaryInformers {
+			if !containsInformer(allInformers, canaryInformer) {
+				return nil, errors.Errorf("canary %s is not a status informer", informerString(canaryInformer))
+			}
+		}
+		config.CanaryInformers = canaryInformers
+	} else {
+		config.CanaryInformers = allInformers[:1]
+	}
+
+	return config, nil
+}
+
+// heldBackInformers returns the workloads that are not deployed until the canary is ready
+func (c *rolloutConfig) heldBackInformers() []appstatetypes.StatusInformer {
+	heldBack := []appstatetypes.StatusInformer{}
+	for _, informer := range c.AllInformers {
+		if workloadKinds[informer.Kind] && !containsInformer(c.CanaryInformers, informer) {
+			heldBack = append(heldBack, informer)
+		}
+	}
+	return heldBack
+}
+
+func (o *Operator) deployProgressively(deployArgs operatortypes.DeployAppArgs, rollout *rolloutConfig, rollbackSequence int64) (bool, error) {
+	rlog := newRolloutLog(o.store, deployArgs.AppID, deployArgs.ClusterID, deployArgs.Sequence)
+	rlog.record("starting progressive rollout with canary %s", informersString(rollout.CanaryInformers))
+
+	manifests, err := base64.StdEncoding.DecodeString(deployArgs.Manifests)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to decode manifests")
+	}
+	canaryManifests := filterHeldBackManifests(manifests, rollout.heldBackInformers(), util.AppNamespace())
+
+	canaryArgs := deployArgs
+	canaryArgs.Manifests = base64.StdEncoding.EncodeToString(canaryManifests)
+	canaryArgs.PreviousManifests = ""
+	if err := o.client.DeployCanary(canaryArgs); err != nil {
+		rlog.fail("failed to deploy canary: %v", err)
+		o.rollback(rlog, deployArgs.AppID, rollbackSequence)
+		return false, errors.Wrap(err, "failed to deploy canary")
+	}
+
+	rlog.record("deployed canary, waiting up to %s for it to become ready", rollout.Timeout)
+	if err := o.waitForInformersReady(deployArgs.AppID, deployArgs.Sequence, rollout.CanaryInformers, rollout.Timeout); err != nil {
+		rlog.fail("canary did not become ready: %v", err)
+		o.rollback(rlog, deployArgs.AppID, rollbackSequence)
+		return false, errors.Wrap(err, "canary did not become ready")
+	}
+
+	rlog.record("canary is ready, deploying all resources")
+	deployed, err := o.client.DeployApp(deployArgs)
+	if err != nil || !deployed {
+		rlog.fail("failed to deploy all resources")
+		o.rollback(rlog, deployArgs.AppID, rollbackSequence)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to deploy app")
+		}
+		return false, nil
+	}
+
+	rlog.record("deployed all resources, waiting up to %s for them to become ready", rollout.Timeout)
+	if err := o.waitForInformersReady(deployArgs.AppID, deployArgs.Sequence, rollout.AllInformers, rollout.Timeout); err != nil {
+		rlog.fail("app did not become ready: %v", err)
+		o.rollback(rlog, deployArgs.AppID, rollbackSequence)
+		return false, errors.Wrap(err, "app did not become ready")
+	}
+
+	rlog.record("rollout completed")
+	return true, nil
+}
+
+// rollback redeploys a previous version once the current deploy has finished and released the app's deploy lock
+func (o *Operator) rollback(rlog *rolloutLog, appID string, sequence int64) {
+	rlog.record("rolling back to sequence %d", sequence)
+
+	if err := o.store.MarkAsCurrentDownstreamVersion(appID, sequence); err != nil {
+		rlog.record("failed to mark sequence %d as current: %v", sequence, err)
+		return
+	}
+
+	go func() {
+		if _, err := o.deployApp(appID, sequence, true); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to roll back app %s to sequence %d", appID, sequence))
+		}
+	}()
+}
+
+// waitForInformersReady waits until the app status of the given sequence reports all informers as ready
+func (o *Operator) waitForInformersReady(appID string, sequence int64, informers []appstatetypes.StatusInformer, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	notReady := informersString(informers)
+	for {
+		// give the controllers a chance to observe the change before checking the status
+		time.Sleep(rolloutPollInterval)
+
+		appStatus, err := o.store.GetAppStatus(appID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get app status")
+		}
+		if appStatus != nil && appStatus.Sequence == sequence {
+			notReady = notReadyInformers(appStatus.ResourceStates, informers)
+			if notReady == "" {
+				return nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return errors.Errorf("timed out waiting for %s", notReady)
+		}
+	}
+}
+
+// notReadyInformers returns a description of the informers that are not ready, or an empty string if all are ready
+func notReadyInformers(resourceStates appstatetypes.ResourceStates, informers []appstatetypes.StatusInformer) string {
+	notReady := []string{}
+	for _, informer := range informers {
+		state := appstatetypes.StateMissing
+		for _, rs := range resourceStates {
+			if rs.Kind == informer.Kind && rs.Name == informer.Name && rs.Namespace == informer.Namespace {
+				state = rs.State
+				break
+			}
+		}
+		if state != appstatetypes.StateReady {
+			notReady = append(notReady, fmt.Sprintf("%s (%s)", informerString(informer), state))
+		}
+	}
+	return strings.Join(notReady, ", ")
+}
+
+// filterHeldBackManifests removes the documents of held back workloads from a multi-doc manifest
+func filterHeldBackManifests(manifests []byte, heldBack []appstatetypes.StatusInformer, targetNamespace string) []byte {
+	docs := [][]byte{}
+	for _, doc := range util.ConvertToSingleDocs(manifests) {
+		o := util.OverlySimpleGVK{}
+		if err := yaml.Unmarshal(doc, &o); err == nil {
+			namespace := o.Metadata.Namespace
+			if namespace == "" {
+				namespace = targetNamespace
+			}
+			resource := appstatetypes.StatusInformer{
+				Kind:      appstate.GetResourceKindCommonName(o.Kind),
+				Name:      o.Metadata.Name,
+				Namespace: namespace,
+			}
+			if containsInformer(heldBack, resource) {
+				continue
+			}
+		}
+		docs = append(docs, doc)
+	}
+	return bytes.Join(docs, []byte("\n---\n"))
+}
+
+func parseStatusInformers(informerStrings []appstatetypes.StatusInformerString, targetNamespace string) ([]appstatetypes.StatusInformer, error) {
+	informers := []appstatetypes.StatusInformer{}
+	for _, s := range informerStrings {
+		informer, err := s.Parse()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse informer %s", s)
+		}
+		informers = append(informers, informer)
+	}
+	return appstate.NormalizeStatusInformers(informers, targetNamespace), nil
+}
+
+func containsInformer(informers []appstatetypes.StatusInformer, informer appstatetypes.StatusInformer) bool {
+	for _, i := range informers {
+		if i == informer {
+			return true
+		}
+	}
+	return false
+}
+
+func informerString(informer appstatetypes.StatusInformer) string {
+	return fmt.Sprintf("%s/%s/%s", informer.Namespace, informer.Kind, informer.Name)
+}
+
+func informersString(informers []appstatetypes.StatusInformer) string {
+	s := []string{}
+	for _, informer := range informers {
+		s = append(s, informerString(informer))
+	}
+	return strings.Join(s, ", ")
+}
+
+// rolloutLog records the steps of a progressive rollout in the downstream output of the version
+type rolloutLog struct {
+	store     store.Store
+	appID     string
+	clusterID string
+	sequence  int64
+	lines     []string
+}
+
+func newRolloutLog(kotsStore store.Store, appID string, clusterID string, sequence int64) *rolloutLog {
+	return &rolloutLog{
+		store:     kotsStore,
+		appID:     appID,
+		clusterID: clusterID,
+		sequence:  sequence,
+	}
+}
+
+func (l *rolloutLog) record(format string, args ...interface{}) {
+	l.write(fmt.Sprintf(format, args...), false)
+}
+
+// fail records a step that failed the rollout. The downstream output of the version is marked as an error,
+// including when the full deploy already reported success.
+func (l *rolloutLog) fail(format string, args ...interface{}) {
+	l.write(fmt.Sprintf(format, args...), true)
+}
+
+func (l *rolloutLog) write(message string, isError bool) {
+	logger.Infof("rollout of app %s sequence %d: %s", l.appID, l.sequence, message)
+
+	l.lines = append(l.lines, fmt.Sprintf("%s %s", time.Now().UTC().Format(time.RFC3339), message))
+	if err := l.store.SetDownstreamRolloutLog(l.appID, l.clusterID, l.sequence, strings.Join(l.lines, "\n"), isError); err != nil {
+		logger.Error(errors.Wrap(err, "failed to set downstream rollout log"))
+	}
+}
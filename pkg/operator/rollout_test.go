@@ -0,0 +1,222 @@
+package operator
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	mock_client "github.com/replicatedhq/kots/pkg/operator/client/mock"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
+	mock_store "github.com/replicatedhq/kots/pkg/store/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getRolloutConfig(t *testing.T) {
+	informers := []appstatetypes.StatusInformerString{
+		"deployment/web",
+		"deploy/worker",
+		"other/statefulset/db",
+		"service/web",
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *rolloutConfig
+		wantErr     bool
+	}{
+		{
+			name:        "not progressive",
+			annotations: map[string]string{},
+			want:        nil,
+		},
+		{
+			name: "defaults",
+			annotations: map[string]string{
+				RolloutStrategyAnnotation: RolloutStrategyProgressive,
+			},
+			want: &rolloutConfig{
+				CanaryInformers: []appstatetypes.StatusInformer{
+					{Kind: "deployment", Name: "web", Namespace: "app-ns"},
+				},
+				Timeout: DefaultRolloutTimeout,
+			},
+		},
+		{
+			name: "custom canary and timeout",
+			annotations: map[string]string{
+				RolloutStrategyAnnotation:        RolloutStrategyProgressive,
+				RolloutCanaryInformersAnnotation: "deployments/worker, other/statefulset/db",
+				RolloutTimeoutAnnotation:         "2m",
+			},
+			want: &rolloutConfig{
+				CanaryInformers: []appstatetypes.StatusInformer{
+					{Kind: "deployment", Name: "worker", Namespace: "app-ns"},
+					{Kind: "statefulset", Name: "db", Namespace: "other"},
+				},
+				Timeout: 2 * time.Minute,
+			},
+		},
+		{
+			name: "canary is not a status informer",
+			annotations: map[string]string{
+				RolloutStrategyAnnotation:        RolloutStrategyProgressive,
+				RolloutCanaryInformersAnnotation: "deployment/api",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid timeout",
+			annotations: map[string]string{
+				RolloutStrategyAnnotation: RolloutStrategyProgressive,
+				RolloutTimeoutAnnotation:  "soon",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getRolloutConfig(tt.annotations, informers, "app-ns")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, tt.want.CanaryInformers, got.CanaryInformers)
+			assert.Equal(t, tt.want.Timeout, got.Timeout)
+			assert.Len(t, got.AllInformers, 4)
+		})
+	}
+}
+
+func Test_filterHeldBackManifests(t *testing.T) {
+	rollout := &rolloutConfig{
+		CanaryInformers: []appstatetypes.StatusInformer{
+			{Kind: "deployment", Name: "web", Namespace: "app-ns"},
+		},
+		AllInformers: []appstatetypes.StatusInformer{
+			{Kind: "deployment", Name: "web", Namespace: "app-ns"},
+			{Kind: "deployment", Name: "worker", Namespace: "app-ns"},
+			{Kind: "service", Name: "worker", Namespace: "app-ns"},
+		},
+	}
+
+	manifests := []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: worker
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  namespace: other`)
+
+	want := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: worker
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+  namespace: other`
+
+	got := filterHeldBackManifests(manifests, rollout.heldBackInformers(), "app-ns")
+	assert.Equal(t, want, string(got))
+}
+
+func Test_notReadyInformers(t *testing.T) {
+	informers := []appstatetypes.StatusInformer{
+		{Kind: "deployment", Name: "web", Namespace: "app-ns"},
+		{Kind: "deployment", Name: "worker", Namespace: "app-ns"},
+	}
+
+	resourceStates := appstatetypes.ResourceStates{
+		{Kind: "deployment", Name: "web", Namespace: "app-ns", State: appstatetypes.StateReady},
+		{Kind: "deployment", Name: "worker", Namespace: "app-ns", State: appstatetypes.StateDegraded},
+	}
+	assert.Equal(t, "app-ns/deployment/worker (degraded)", notReadyInformers(resourceStates, informers))
+
+	resourceStates[1].State = appstatetypes.StateReady
+	assert.Equal(t, "", notReadyInformers(resourceStates, informers))
+
+	assert.Equal(t, "app-ns/deployment/web (missing), app-ns/deployment/worker (missing)", notReadyInformers(nil, informers))
+}
+
+func Test_deployProgressively_notReadyAfterFullDeploy(t *testing.T) {
+	defer func(interval time.Duration) { rolloutPollInterval = interval }(rolloutPollInterval)
+	rolloutPollInterval = time.Millisecond
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mock_store.NewMockStore(ctrl)
+	mockClient := mock_client.NewMockClientInterface(ctrl)
+	o := &Operator{
+		client: mockClient,
+		store:  mockStore,
+	}
+
+	web := appstatetypes.StatusInformer{Kind: "deployment", Name: "web", Namespace: "app-ns"}
+	worker := appstatetypes.StatusInformer{Kind: "deployment", Name: "worker", Namespace: "app-ns"}
+	rollout := &rolloutConfig{
+		CanaryInformers: []appstatetypes.StatusInformer{web},
+		AllInformers:    []appstatetypes.StatusInformer{web, worker},
+		Timeout:         20 * time.Millisecond,
+	}
+	deployArgs := operatortypes.DeployAppArgs{
+		AppID:     "app-id",
+		ClusterID: "cluster-id",
+		Sequence:  2,
+		Manifests: base64.StdEncoding.EncodeToString([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n")),
+	}
+
+	// the canary becomes ready, the worker never does
+	mockStore.EXPECT().GetAppStatus("app-id").Return(&appstatetypes.AppStatus{
+		AppID:    "app-id",
+		Sequence: 2,
+		ResourceStates: appstatetypes.ResourceStates{
+			{Kind: "deployment", Name: "web", Namespace: "app-ns", State: appstatetypes.StateReady},
+			{Kind: "deployment", Name: "worker", Namespace: "app-ns", State: appstatetypes.StateUnavailable},
+		},
+	}, nil).AnyTimes()
+	mockStore.EXPECT().SetDownstreamRolloutLog("app-id", "cluster-id", int64(2), gomock.Any(), false).Return(nil).AnyTimes()
+	mockClient.EXPECT().DeployCanary(gomock.Any()).Return(nil)
+
+	// the output of the full deploy reports success, the failed rollout must mark it as an error afterwards
+	gomock.InOrder(
+		mockClient.EXPECT().DeployApp(deployArgs).Return(true, nil),
+		mockStore.EXPECT().SetDownstreamRolloutLog("app-id", "cluster-id", int64(2), gomock.Any(), true).Return(nil).Times(1),
+		mockStore.EXPECT().MarkAsCurrentDownstreamVersion("app-id", int64(1)).Return(errors.New("not rolled back in this test")),
+	)
+
+	deployed, err := o.deployProgressively(deployArgs, rollout, 1)
+	assert.False(t, deployed)
+	assert.ErrorContains(t, err, "app did not become ready")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
@@ -67,6 +68,157 @@ type AppInformersArgs struct {
 	Informers []appstatetypes.StatusInformerString `json:"informers"`
 }
 
+type ResourceOperation string
+
+const (
+	ResourceOperationCreated    ResourceOperation = "created"
+	ResourceOperationConfigured ResourceOperation = "configured"
+	ResourceOperationUnchanged  ResourceOperation = "unchanged"
+	ResourceOperationDeleted    ResourceOperation = "deleted"
+	ResourceOperationNotFound   ResourceOperation = "not found"
+	ResourceOperationFailed     ResourceOperation = "failed"
+)
+
+// ResourceResult is the outcome of applying or removing a single object
+type ResourceResult struct {
+	Group     string             `json:"group"`
+	Version   string             `json:"version"`
+	Kind      string             `json:"kind"`
+	Namespace string             `json:"namespace,omitempty"`
+	Name      string             `json:"name"`
+	DryRun    bool               `json:"dryRun,omitempty"`
+	Operation ResourceOperation  `json:"operation"`
+	Conflicts []ResourceConflict `json:"conflicts,omitempty"`
+	Error     string             `json:"error,omitempty"`
+}
+
+// ResourceConflict is a field that was owned by another field manager when the object was applied
+type ResourceConflict struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (r ResourceResult) IsError() bool {
+	return r.Error != ""
+}
+
+// String returns the result in the same format kubectl uses, e.g. "deployment.apps/my-app configured"
+func (r ResourceResult) String() string {
+	kind := strings.ToLower(r.Kind)
+	if r.Group != "" {
+		kind = fmt.Sprintf("%s.%s", kind, r.Group)
+	}
+	s := fmt.Sprintf("%s/%s %s", kind, r.Name, r.Operation)
+	if r.DryRun {
+		s = fmt.Sprintf("%s (dry run)", s)
+	}
+	return s
+}
+
+// HelmReleaseResult is the outcome of installing, upgrading or uninstalling a single helm release
+type HelmReleaseResult struct {
+	ReleaseName  string `json:"releaseName"`
+	Namespace    string `json:"namespace,omitempty"`
+	ChartName    string `json:"chartName"`
+	ChartVersion string `json:"chartVersion"`
+	Revision     int    `json:"revision,omitempty"`
+	Status       string `json:"status,omitempty"`
+	Description  string `json:"description,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+func (r HelmReleaseResult) IsError() bool {
+	return r.Error != ""
+}
+
+// String returns a single line summary, e.g. "release my-chart (my-chart-1.0.0) in namespace default: deployed revision 2"
+func (r HelmReleaseResult) String() string {
+	s := fmt.Sprintf("release %s (%s-%s)", r.ReleaseName, r.ChartName, r.ChartVersion)
+	if r.Namespace != "" {
+		s = fmt.Sprintf("%s in namespace %s", s, r.Namespace)
+	}
+	if r.Status != "" {
+		s = fmt.Sprintf("%s: %s", s, r.Status)
+		if r.Revision > 0 {
+			s = fmt.Sprintf("%s revision %d", s, r.Revision)
+		}
+	}
+	if r.Error != "" {
+		s = fmt.Sprintf("%s: %s", s, r.Error)
+	}
+	return s
+}
+
+type DriftReason string
+
+const (
+	DriftReasonModified DriftReason = "modified"
+	DriftReasonMissing  DriftReason = "missing"
+	DriftReasonError    DriftReason = "error"
+)
+
+// DriftedResource is an object that kots applied and that no longer matches the rendered manifests
+type DriftedResource struct {
+	Group     string      `json:"group"`
+	Version   string      `json:"version"`
+	Kind      string      `json:"kind"`
+	Namespace string      `json:"namespace,omitempty"`
+	Name      string      `json:"name"`
+	Reason    DriftReason `json:"reason"`
+	// Fields are the paths of the fields that a deploy would revert
+	Fields []string `json:"fields,omitempty"`
+	// Managers are the field managers that own the drifted fields, e.g. kubectl-edit
+	Managers   []string `json:"managers,omitempty"`
+	Remediated bool     `json:"remediated,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// String returns a single line summary, e.g. "deployment.apps/my-app modified (spec.replicas by kubectl-edit)"
+func (r DriftedResource) String() string {
+	kind := strings.ToLower(r.Kind)
+	if r.Group != "" {
+		kind = fmt.Sprintf("%s.%s", kind, r.Group)
+	}
+	s := fmt.Sprintf("%s/%s %s", kind, r.Name, r.Reason)
+	if len(r.Fields) > 0 {
+		details := strings.Join(r.Fields, ", ")
+		if len(r.Managers) > 0 {
+			details = fmt.Sprintf("%s by %s", details, strings.Join(r.Managers, ", "))
+		}
+		s = fmt.Sprintf("%s (%s)", s, details)
+	}
+	if r.Error != "" {
+		s = fmt.Sprintf("%s: %s", s, r.Error)
+	}
+	return s
+}
+
+type DetectDriftArgs struct {
+	AppSlug      string
+	Manifests    string
+	AnnotateSlug bool
+	// Remediate re-applies the resources that drifted
+	Remediate bool
+}
+
+// AppDriftStatus is the result of the last drift check of the deployed version of an app
+type AppDriftStatus struct {
+	AppID     string            `json:"appId"`
+	Sequence  int64             `json:"sequence"`
+	CheckedAt time.Time         `json:"checkedAt"`
+	Resources []DriftedResource `json:"resources"`
+	Error     string            `json:"error,omitempty"`
+}
+
+func (s AppDriftStatus) IsDrifted() bool {
+	for _, r := range s.Resources {
+		if r.Reason != DriftReasonError && !r.Remediated {
+			return true
+		}
+	}
+	return false
+}
+
 type Phases []Phase
 
 type Phase struct {
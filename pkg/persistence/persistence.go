@@ -7,25 +7,94 @@ import (
 	"github.com/rqlite/gorqlite"
 )
 
-var db *gorqlite.Connection
+const (
+	DriverRqlite   = "rqlite"
+	DriverPostgres = "postgres"
+)
+
+// DB is the subset of the gorqlite connection API that the stores use. Statements are written
+// using "?" placeholders and SQL that is portable between sqlite and postgres.
+type DB interface {
+	QueryOne(sqlStatement string) (QueryResult, error)
+	QueryOneParameterized(statement gorqlite.ParameterizedStatement) (QueryResult, error)
+	WriteOne(sqlStatement string) (gorqlite.WriteResult, error)
+	WriteOneParameterized(statement gorqlite.ParameterizedStatement) (gorqlite.WriteResult, error)
+	WriteParameterized(statements []gorqlite.ParameterizedStatement) ([]gorqlite.WriteResult, error)
+}
+
+type rowScanner interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+}
+
+// QueryResult holds the rows returned by a query. Like gorqlite.QueryResult, Err holds any
+// error reported by the database for the statement.
+type QueryResult struct {
+	Err  error
+	rows rowScanner
+}
+
+func (qr QueryResult) Next() bool {
+	if qr.rows == nil {
+		return false
+	}
+	return qr.rows.Next()
+}
+
+func (qr QueryResult) Scan(dest ...interface{}) error {
+	if qr.rows == nil {
+		return fmt.Errorf("no rows to scan")
+	}
+	return qr.rows.Scan(dest...)
+}
+
+var db DB
 
 func IsInitialized() bool {
 	return db != nil
 }
 
-func SetDB(database *gorqlite.Connection) {
+func SetDB(database DB) {
 	db = database
 }
 
-func MustGetDBSession() *gorqlite.Connection {
+// Driver returns the database driver kotsadm is configured to use. Defaults to rqlite.
+func Driver() string {
+	if os.Getenv("DATABASE_DRIVER") == DriverPostgres {
+		return DriverPostgres
+	}
+	return DriverRqlite
+}
+
+// URI returns the connection string for the configured database driver.
+func URI() string {
+	if Driver() == DriverPostgres {
+		return os.Getenv("DATABASE_URI")
+	}
+	return os.Getenv("RQLITE_URI")
+}
+
+func MustGetDBSession() DB {
 	if db != nil {
 		return db
 	}
-	newDB, err := gorqlite.Open(os.Getenv("RQLITE_URI"))
-	if err != nil {
-		fmt.Printf("error connecting to rqlite: %v\n", err)
-		panic(err)
+
+	switch Driver() {
+	case DriverPostgres:
+		newDB, err := OpenPostgres(URI())
+		if err != nil {
+			fmt.Printf("error connecting to postgres: %v\n", err)
+			panic(err)
+		}
+		db = newDB
+	default:
+		newDB, err := OpenRqlite(URI())
+		if err != nil {
+			fmt.Printf("error connecting to rqlite: %v\n", err)
+			panic(err)
+		}
+		db = newDB
 	}
-	db = &newDB
+
 	return db
 }
@@ -0,0 +1,414 @@
+package persistence
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/rqlite/gorqlite"
+)
+
+type postgresDB struct {
+	db *sql.DB
+}
+
+var _ DB = (*postgresDB)(nil)
+
+// OpenPostgres returns a DB backed by postgres. Queries written for rqlite are rebound to
+// postgres placeholders, and results are scanned with the same conversion rules as gorqlite
+// so that the stores can use either driver unchanged.
+func OpenPostgres(uri string) (DB, error) {
+	db, err := sql.Open("postgres", uri)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open postgres connection")
+	}
+	return NewPostgresDB(db), nil
+}
+
+func NewPostgresDB(db *sql.DB) DB {
+	return &postgresDB{db: db}
+}
+
+func (d *postgresDB) QueryOne(sqlStatement string) (QueryResult, error) {
+	return d.QueryOneParameterized(gorqlite.ParameterizedStatement{Query: sqlStatement})
+}
+
+func (d *postgresDB) QueryOneParameterized(statement gorqlite.ParameterizedStatement) (QueryResult, error) {
+	rows, err := d.db.Query(rebind(statement.Query), convertArgs(statement.Arguments)...)
+	if err != nil {
+		return QueryResult{Err: err}, err
+	}
+	defer rows.Close()
+
+	result, err := readPostgresRows(rows)
+	if err != nil {
+		return QueryResult{Err: err}, err
+	}
+
+	return QueryResult{rows: result}, nil
+}
+
+func (d *postgresDB) WriteOne(sqlStatement string) (gorqlite.WriteResult, error) {
+	return d.WriteOneParameterized(gorqlite.ParameterizedStatement{Query: sqlStatement})
+}
+
+func (d *postgresDB) WriteOneParameterized(statement gorqlite.ParameterizedStatement) (gorqlite.WriteResult, error) {
+	wr := execStatement(d.db, statement)
+	return wr, wr.Err
+}
+
+// WriteParameterized runs all statements in a single transaction and stops at the first statement that fails.
+// Results are returned for the statements that were executed, and the last one holds the error if any of them failed.
+func (d *postgresDB) WriteParameterized(statements []gorqlite.ParameterizedStatement) ([]gorqlite.WriteResult, error) {
+	results := []gorqlite.WriteResult{}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return results, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	for i, statement := range statements {
+		wr := execStatement(tx, statement)
+		results = append(results, wr)
+		if wr.Err != nil {
+			return results, errors.Wrapf(wr.Err, "failed to execute statement %d", i)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return results, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return results, nil
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func execStatement(e execer, statement gorqlite.ParameterizedStatement) gorqlite.WriteResult {
+	res, err := e.Exec(rebind(statement.Query), convertArgs(statement.Arguments)...)
+	if err != nil {
+		return gorqlite.WriteResult{Err: err}
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return gorqlite.WriteResult{Err: errors.Wrap(err, "failed to get rows affected")}
+	}
+
+	return gorqlite.WriteResult{RowsAffected: rowsAffected}
+}
+
+// rebind replaces the "?" placeholders used by rqlite with postgres' positional "$n" ones.
+func rebind(query string) string {
+	var sb strings.Builder
+	n := 0
+	inQuote := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			inQuote = !inQuote
+			sb.WriteRune(r)
+		case r == '?' && !inQuote:
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// convertArgs encodes arguments the way rqlite stores them. Booleans are integer columns in
+// both schemas, and times are sent as RFC3339 strings.
+func convertArgs(args []interface{}) []interface{} {
+	converted := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		switch a := arg.(type) {
+		case bool:
+			if a {
+				converted = append(converted, int64(1))
+			} else {
+				converted = append(converted, int64(0))
+			}
+		case time.Time:
+			converted = append(converted, a.Format(time.RFC3339Nano))
+		default:
+			converted = append(converted, arg)
+		}
+	}
+	return converted
+}
+
+type postgresRows struct {
+	columns   []string
+	values    [][]interface{}
+	rowNumber int
+}
+
+func readPostgresRows(rows *sql.Rows) (*postgresRows, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get columns")
+	}
+
+	result := &postgresRows{
+		columns:   columns,
+		rowNumber: -1,
+	}
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		for i, value := range values {
+			values[i] = normalizeValue(value)
+		}
+		result.values = append(result.values, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to iterate rows")
+	}
+
+	return result, nil
+}
+
+// normalizeValue converts a value returned by lib/pq into one of the types rqlite returns:
+// string, int64, float64 or nil.
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case bool:
+		if v {
+			return int64(1)
+		}
+		return int64(0)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
+
+func (r *postgresRows) Next() bool {
+	if r.rowNumber >= len(r.values)-1 {
+		return false
+	}
+	r.rowNumber++
+	return true
+}
+
+func (r *postgresRows) Scan(dest ...interface{}) error {
+	if r.rowNumber < 0 {
+		return errors.New("Next must be called before Scan")
+	}
+	if len(dest) != len(r.columns) {
+		return fmt.Errorf("expected %d columns but got %d vars", len(r.columns), len(dest))
+	}
+
+	for i, d := range dest {
+		if err := scanValue(r.values[r.rowNumber][i], d); err != nil {
+			return errors.Wrapf(err, "failed to scan column %s", r.columns[i])
+		}
+	}
+
+	return nil
+}
+
+// scanValue follows the conversion rules of gorqlite.QueryResult.Scan. Null values leave
+// non-nullable destinations untouched.
+func scanValue(src interface{}, dest interface{}) error {
+	switch d := dest.(type) {
+	case *string:
+		if src == nil {
+			return nil
+		}
+		s, ok := src.(string)
+		if !ok {
+			return fmt.Errorf("invalid string type %T", src)
+		}
+		*d = s
+	case *int:
+		if src == nil {
+			return nil
+		}
+		i, err := toInt64(src)
+		if err != nil {
+			return err
+		}
+		*d = int(i)
+	case *int64:
+		if src == nil {
+			return nil
+		}
+		i, err := toInt64(src)
+		if err != nil {
+			return err
+		}
+		*d = i
+	case *float64:
+		if src == nil {
+			return nil
+		}
+		f, err := toFloat64(src)
+		if err != nil {
+			return err
+		}
+		*d = f
+	case *bool:
+		if src == nil {
+			return nil
+		}
+		b, err := toBool(src)
+		if err != nil {
+			return err
+		}
+		*d = b
+	case *time.Time:
+		if src == nil {
+			return nil
+		}
+		t, err := toTime(src)
+		if err != nil {
+			return err
+		}
+		*d = t
+	case *gorqlite.NullString:
+		if src == nil {
+			*d = gorqlite.NullString{}
+			return nil
+		}
+		s, ok := src.(string)
+		if !ok {
+			return fmt.Errorf("invalid string type %T", src)
+		}
+		*d = gorqlite.NullString{Valid: true, String: s}
+	case *gorqlite.NullInt64:
+		if src == nil {
+			*d = gorqlite.NullInt64{}
+			return nil
+		}
+		i, err := toInt64(src)
+		if err != nil {
+			return err
+		}
+		*d = gorqlite.NullInt64{Valid: true, Int64: i}
+	case *gorqlite.NullInt32:
+		if src == nil {
+			*d = gorqlite.NullInt32{}
+			return nil
+		}
+		i, err := toInt64(src)
+		if err != nil {
+			return err
+		}
+		*d = gorqlite.NullInt32{Valid: true, Int32: int32(i)}
+	case *gorqlite.NullInt16:
+		if src == nil {
+			*d = gorqlite.NullInt16{}
+			return nil
+		}
+		i, err := toInt64(src)
+		if err != nil {
+			return err
+		}
+		*d = gorqlite.NullInt16{Valid: true, Int16: int16(i)}
+	case *gorqlite.NullFloat64:
+		if src == nil {
+			*d = gorqlite.NullFloat64{}
+			return nil
+		}
+		f, err := toFloat64(src)
+		if err != nil {
+			return err
+		}
+		*d = gorqlite.NullFloat64{Valid: true, Float64: f}
+	case *gorqlite.NullBool:
+		if src == nil {
+			*d = gorqlite.NullBool{}
+			return nil
+		}
+		b, err := toBool(src)
+		if err != nil {
+			return err
+		}
+		*d = gorqlite.NullBool{Valid: true, Bool: b}
+	case *gorqlite.NullTime:
+		if src == nil {
+			*d = gorqlite.NullTime{}
+			return nil
+		}
+		t, err := toTime(src)
+		if err != nil {
+			return err
+		}
+		*d = gorqlite.NullTime{Valid: true, Time: t}
+	default:
+		return fmt.Errorf("unknown destination type %T", dest)
+	}
+
+	return nil
+}
+
+func toInt64(src interface{}) (int64, error) {
+	switch s := src.(type) {
+	case int64:
+		return s, nil
+	case float64:
+		return int64(s), nil
+	case string:
+		return strconv.ParseInt(s, 10, 64)
+	}
+	return 0, fmt.Errorf("invalid int type %T", src)
+}
+
+func toFloat64(src interface{}) (float64, error) {
+	switch s := src.(type) {
+	case int64:
+		return float64(s), nil
+	case float64:
+		return s, nil
+	case string:
+		return strconv.ParseFloat(s, 64)
+	}
+	return 0, fmt.Errorf("invalid float type %T", src)
+}
+
+func toBool(src interface{}) (bool, error) {
+	switch s := src.(type) {
+	case int64:
+		return strconv.ParseBool(strconv.FormatInt(s, 10))
+	case float64:
+		return strconv.ParseBool(strconv.FormatFloat(s, 'g', -1, 64))
+	case string:
+		return strconv.ParseBool(s)
+	}
+	return false, fmt.Errorf("invalid bool type %T", src)
+}
+
+func toTime(src interface{}) (time.Time, error) {
+	switch s := src.(type) {
+	case int64:
+		return time.Unix(s, 0), nil
+	case float64:
+		return time.Unix(int64(s), 0), nil
+	case string:
+		if t, err := time.Parse("2006-01-02 15:04:05", s); err == nil {
+			return t, nil
+		}
+		return time.Parse(time.RFC3339, s)
+	}
+	return time.Time{}, fmt.Errorf("invalid time type %T", src)
+}
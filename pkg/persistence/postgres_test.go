@@ -0,0 +1,105 @@
+package persistence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rqlite/gorqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_rebind(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "no placeholders",
+			query: "select count(1) from app",
+			want:  "select count(1) from app",
+		},
+		{
+			name:  "multiple placeholders",
+			query: "insert into kotsadm_params (key, value) values (?, ?) on conflict (key) do update set value = EXCLUDED.value",
+			want:  "insert into kotsadm_params (key, value) values ($1, $2) on conflict (key) do update set value = EXCLUDED.value",
+		},
+		{
+			name:  "quoted question marks are ignored",
+			query: "select id from app where name = 'what?' and slug = ? and title = 'it''s?' and id = ?",
+			want:  "select id from app where name = 'what?' and slug = $1 and title = 'it''s?' and id = $2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rebind(tt.query))
+		})
+	}
+}
+
+func Test_convertArgs(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	got := convertArgs([]interface{}{true, false, ts, "value", int64(3), nil})
+	assert.Equal(t, []interface{}{int64(1), int64(0), "2024-01-02T03:04:05Z", "value", int64(3), nil}, got)
+}
+
+func Test_postgresRows(t *testing.T) {
+	rows := &postgresRows{
+		columns: []string{"id", "created_at", "is_error", "size", "deleted_at", "label"},
+		values: [][]interface{}{
+			{"app-1", int64(1700000000), int64(1), "12.5", nil, nil},
+		},
+		rowNumber: -1,
+	}
+	qr := QueryResult{rows: rows}
+
+	var id string
+	var createdAt gorqlite.NullTime
+	var isError bool
+	var size gorqlite.NullFloat64
+	var deletedAt gorqlite.NullTime
+	label := "unchanged"
+
+	require.Error(t, qr.Scan(&id, &createdAt, &isError, &size, &deletedAt, &label))
+	require.True(t, qr.Next())
+	require.Error(t, qr.Scan(&id))
+	require.NoError(t, qr.Scan(&id, &createdAt, &isError, &size, &deletedAt, &label))
+
+	assert.Equal(t, "app-1", id)
+	assert.Equal(t, gorqlite.NullTime{Valid: true, Time: time.Unix(1700000000, 0)}, createdAt)
+	assert.True(t, isError)
+	assert.Equal(t, gorqlite.NullFloat64{Valid: true, Float64: 12.5}, size)
+	assert.False(t, deletedAt.Valid)
+	assert.Equal(t, "unchanged", label)
+
+	assert.False(t, qr.Next())
+}
+
+func Test_scanValue(t *testing.T) {
+	var s gorqlite.NullString
+	require.NoError(t, scanValue("value", &s))
+	assert.Equal(t, gorqlite.NullString{Valid: true, String: "value"}, s)
+
+	var b gorqlite.NullBool
+	require.NoError(t, scanValue(int64(0), &b))
+	assert.Equal(t, gorqlite.NullBool{Valid: true, Bool: false}, b)
+
+	var i int64
+	require.NoError(t, scanValue("42", &i))
+	assert.Equal(t, int64(42), i)
+
+	var str string
+	require.Error(t, scanValue(int64(42), &str))
+
+	var unsupported []string
+	require.Error(t, scanValue("value", &unsupported))
+}
+
+func Test_rqliteValueToPostgres(t *testing.T) {
+	assert.Equal(t, int64(1700000000), rqliteValueToPostgres(float64(1700000000), "integer"))
+	assert.Equal(t, 1.5, rqliteValueToPostgres(1.5, "real"))
+	assert.Equal(t, "value", rqliteValueToPostgres("value", "text"))
+	assert.Nil(t, rqliteValueToPostgres(nil, "integer"))
+}
@@ -0,0 +1,45 @@
+package persistence
+
+import (
+	"github.com/rqlite/gorqlite"
+)
+
+type rqliteDB struct {
+	conn *gorqlite.Connection
+}
+
+var _ DB = (*rqliteDB)(nil)
+
+func OpenRqlite(uri string) (DB, error) {
+	conn, err := gorqlite.Open(uri)
+	if err != nil {
+		return nil, err
+	}
+	return NewRqliteDB(&conn), nil
+}
+
+func NewRqliteDB(conn *gorqlite.Connection) DB {
+	return &rqliteDB{conn: conn}
+}
+
+func (d *rqliteDB) QueryOne(sqlStatement string) (QueryResult, error) {
+	qr, err := d.conn.QueryOne(sqlStatement)
+	return QueryResult{Err: qr.Err, rows: &qr}, err
+}
+
+func (d *rqliteDB) QueryOneParameterized(statement gorqlite.ParameterizedStatement) (QueryResult, error) {
+	qr, err := d.conn.QueryOneParameterized(statement)
+	return QueryResult{Err: qr.Err, rows: &qr}, err
+}
+
+func (d *rqliteDB) WriteOne(sqlStatement string) (gorqlite.WriteResult, error) {
+	return d.conn.WriteOne(sqlStatement)
+}
+
+func (d *rqliteDB) WriteOneParameterized(statement gorqlite.ParameterizedStatement) (gorqlite.WriteResult, error) {
+	return d.conn.WriteOneParameterized(statement)
+}
+
+func (d *rqliteDB) WriteParameterized(statements []gorqlite.ParameterizedStatement) ([]gorqlite.WriteResult, error) {
+	return d.conn.WriteParameterized(statements)
+}
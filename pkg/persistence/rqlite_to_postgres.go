@@ -0,0 +1,188 @@
+package persistence
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/rqlite/gorqlite"
+)
+
+const (
+	POSTGRES_MIGRATION_SUCCESS_KEY   = "postgres.migration.success"
+	POSTGRES_MIGRATION_SUCCESS_VALUE = "true"
+
+	rqliteToPostgresBatchSize = 100
+)
+
+// MigrateFromRqliteToPostgres copies every table from rqlite into postgres after bringing the
+// postgres schema up to date. Rows that already exist in postgres are left untouched.
+func MigrateFromRqliteToPostgres(rqliteURI string, postgresURI string, schemaDir string) error {
+	rqliteConn, err := gorqlite.Open(rqliteURI)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to rqlite")
+	}
+
+	pgDB, err := sql.Open("postgres", postgresURI)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to postgres")
+	}
+	defer pgDB.Close()
+
+	log.Println("Updating Postgres schema...")
+
+	if err := UpdateDBSchema(DriverPostgres, postgresURI, schemaDir); err != nil {
+		return errors.Wrap(err, "failed to update postgres schema")
+	}
+
+	alreadyMigrated, err := isAlreadyMigratedToPostgres(NewPostgresDB(pgDB))
+	if err != nil {
+		return errors.Wrap(err, "failed to check if already migrated")
+	}
+	if alreadyMigrated {
+		log.Println("Already migrated from rqlite to Postgres. Skipping migration...")
+		return nil
+	}
+
+	tables, err := listRqliteTables(&rqliteConn)
+	if err != nil {
+		return errors.Wrap(err, "failed to list rqlite tables")
+	}
+
+	tx, err := pgDB.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	for _, table := range tables {
+		log.Printf("Migrating table %s...", table)
+
+		count, err := copyRqliteTable(&rqliteConn, tx, table)
+		if err != nil {
+			return errors.Wrapf(err, "failed to copy table %s", table)
+		}
+
+		log.Printf("Migrated %d rows from table %s", count, table)
+	}
+
+	// record a successful migration
+	query := `INSERT INTO kotsadm_params (key, value) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value`
+	if _, err := tx.Exec(query, POSTGRES_MIGRATION_SUCCESS_KEY, POSTGRES_MIGRATION_SUCCESS_VALUE); err != nil {
+		return errors.Wrap(err, "failed to mark migration as successful")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	log.Println("Migrated from rqlite to Postgres successfully!")
+
+	return nil
+}
+
+func isAlreadyMigratedToPostgres(pgDB DB) (bool, error) {
+	rows, err := pgDB.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     `SELECT value FROM kotsadm_params WHERE key = ?`,
+		Arguments: []interface{}{POSTGRES_MIGRATION_SUCCESS_KEY},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+	if !rows.Next() {
+		return false, nil
+	}
+
+	var value string
+	if err := rows.Scan(&value); err != nil {
+		return false, errors.Wrap(err, "failed to scan")
+	}
+
+	return value == POSTGRES_MIGRATION_SUCCESS_VALUE, nil
+}
+
+func listRqliteTables(rqliteConn *gorqlite.Connection) ([]string, error) {
+	query := `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`
+	rows, err := rqliteConn.QueryOne(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	tables := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		tables = append(tables, name)
+	}
+
+	return tables, nil
+}
+
+// copyRqliteTable copies the rows of a table in batches, since tables such as object_store
+// can hold large values.
+func copyRqliteTable(rqliteConn *gorqlite.Connection, tx *sql.Tx, table string) (int, error) {
+	count := 0
+	for offset := 0; ; offset += rqliteToPostgresBatchSize {
+		rows, err := rqliteConn.QueryOneParameterized(gorqlite.ParameterizedStatement{
+			Query:     fmt.Sprintf(`SELECT * FROM %s ORDER BY rowid LIMIT ? OFFSET ?`, table),
+			Arguments: []interface{}{rqliteToPostgresBatchSize, offset},
+		})
+		if err != nil {
+			return count, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+		}
+		if rows.NumRows() == 0 {
+			return count, nil
+		}
+
+		columns := rows.Columns()
+		types := rows.Types()
+
+		quotedColumns := []string{}
+		placeholders := []string{}
+		for i, column := range columns {
+			quotedColumns = append(quotedColumns, pq.QuoteIdentifier(column))
+			placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+		}
+		query := fmt.Sprintf(
+			`INSERT INTO %s (%s) VALUES (%s) ON CONFLICT DO NOTHING`,
+			pq.QuoteIdentifier(table),
+			strings.Join(quotedColumns, ", "),
+			strings.Join(placeholders, ", "),
+		)
+
+		for rows.Next() {
+			row, err := rows.Map()
+			if err != nil {
+				return count, errors.Wrap(err, "failed to read row")
+			}
+
+			args := []interface{}{}
+			for i, column := range columns {
+				args = append(args, rqliteValueToPostgres(row[column], types[i]))
+			}
+
+			if _, err := tx.Exec(query, args...); err != nil {
+				return count, errors.Wrap(err, "failed to insert row")
+			}
+			count++
+		}
+	}
+}
+
+// rqliteValueToPostgres converts integer columns back from the float64 that JSON decoding
+// produces, so postgres accepts them for bigint columns.
+func rqliteValueToPostgres(value interface{}, columnType string) interface{} {
+	f, ok := value.(float64)
+	if !ok {
+		return value
+	}
+	if strings.Contains(strings.ToLower(columnType), "int") {
+		return int64(f)
+	}
+	return f
+}
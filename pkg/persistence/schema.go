@@ -8,6 +8,23 @@ import (
 	schemaherodb "github.com/schemahero/schemahero/pkg/database"
 )
 
+// UpdateSchema brings the schema of the configured database up to date. The rqlite schema is
+// applied by the schemahero init containers, so this only applies the postgres schema.
+func UpdateSchema() error {
+	if Driver() != DriverPostgres {
+		return nil
+	}
+	return UpdateDBSchema(DriverPostgres, URI(), SchemaDir())
+}
+
+// SchemaDir returns the directory containing the table specs for both drivers.
+func SchemaDir() string {
+	if dir := os.Getenv("DATABASE_SCHEMA_DIR"); dir != "" {
+		return dir
+	}
+	return "/tables"
+}
+
 func UpdateDBSchema(driver string, uri string, schemaDir string) error {
 	statements := []string{}
 
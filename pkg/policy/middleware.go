@@ -1,6 +1,7 @@
 package policy
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -28,9 +29,12 @@ func (e RBACError) Abort(w http.ResponseWriter) error {
 	return err
 }
 
+type RolesGetter func(ctx context.Context) []rbactypes.Role
+
 type Middleware struct {
-	KOTSStore store.Store
-	Roles     []rbactypes.Role
+	KOTSStore   store.Store
+	Roles       []rbactypes.Role
+	RolesGetter RolesGetter
 }
 
 func NewMiddleware(kotsStore store.Store, roles []rbactypes.Role) *Middleware {
@@ -40,6 +44,22 @@ func NewMiddleware(kotsStore store.Store, roles []rbactypes.Role) *Middleware {
 	}
 }
 
+// NewMiddlewareWithRolesGetter returns a middleware that looks up the roles on every request,
+// so that custom roles can change without restarting the api server.
+func NewMiddlewareWithRolesGetter(kotsStore store.Store, rolesGetter RolesGetter) *Middleware {
+	return &Middleware{
+		KOTSStore:   kotsStore,
+		RolesGetter: rolesGetter,
+	}
+}
+
+func (m *Middleware) getRoles(ctx context.Context) []rbactypes.Role {
+	if m.RolesGetter != nil {
+		return m.RolesGetter(ctx)
+	}
+	return m.Roles
+}
+
 func (m *Middleware) EnforceAccess(p *Policy, handler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		sess := session.ContextGetSession(r)
@@ -59,7 +79,7 @@ func (m *Middleware) EnforceAccess(p *Policy, handler http.HandlerFunc) http.Han
 
 			rbacErr := NewRBACError(resource)
 
-			allow, err := rbac.CheckAccess(r.Context(), m.Roles, action, resource, sess.Roles)
+			allow, err := rbac.CheckAccess(r.Context(), m.getRoles(r.Context()), action, resource, sess.Roles)
 			if err != nil {
 				logger.Error(errors.Wrapf(err, "failed to check access to resource %q", resource))
 				w.WriteHeader(http.StatusInternalServerError)
@@ -57,6 +57,25 @@ var (
 	PrometheussettingsWrite = Must(NewPolicy(ActionWrite, "prometheussettings."))
 )
 
+// Audit log
+
+var (
+	AuditRead = Must(NewPolicy(ActionRead, "audit."))
+)
+
+// Notifications
+
+var (
+	NotificationsRead  = Must(NewPolicy(ActionRead, "notifications."))
+	NotificationsWrite = Must(NewPolicy(ActionWrite, "notifications."))
+)
+
+// Encryption key
+
+var (
+	EncryptionKeyWrite = Must(NewPolicy(ActionWrite, "encryptionkey."))
+)
+
 // Password change
 
 var (
@@ -6,15 +6,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	"github.com/replicatedhq/kots/pkg/eventstream"
+	eventstreamtypes "github.com/replicatedhq/kots/pkg/eventstream/types"
+	imagetypes "github.com/replicatedhq/kots/pkg/image/types"
 	"github.com/replicatedhq/kots/pkg/installers"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	kotstypes "github.com/replicatedhq/kots/pkg/kotsadm/types"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/notifications"
+	notificationstypes "github.com/replicatedhq/kots/pkg/notifications/types"
 	"github.com/replicatedhq/kots/pkg/preflight/types"
 	"github.com/replicatedhq/kots/pkg/registry"
 	registrytypes "github.com/replicatedhq/kots/pkg/registry/types"
@@ -109,6 +115,12 @@ func Run(appID string, appSlug string, sequence int64, isAirgap bool, ignoreNonS
 	}
 	kotsKinds.Preflight.Spec.Collectors = collectors
 
+	imageVerificationResults, err := store.GetStore().GetImageVerificationResults(appID, sequence)
+	if err != nil {
+		preflightErr = errors.Wrap(err, "failed to get image verification results")
+		return preflightErr
+	}
+
 	go func() {
 		logger.Info("preflight checks beginning",
 			zap.String("appID", appID),
@@ -118,6 +130,7 @@ func Run(appID string, appSlug string, sequence int64, isAirgap bool, ignoreNonS
 			return setPreflightProgress(appID, sequence, progress)
 		}
 		setResults := func(results *types.PreflightResults) error {
+			addImageVerificationResult(results, imageVerificationResults, kotsKinds)
 			return setPreflightResults(appID, sequence, results)
 		}
 		uploadPreflightResults, err := Execute(kotsKinds.Preflight, ignoreRBAC, setProgress, setResults)
@@ -127,6 +140,16 @@ func Run(appID string, appSlug string, sequence int64, isAirgap bool, ignoreNonS
 		}
 		logger.Info("preflight checks completed")
 
+		notifications.Notify(notificationstypes.Event{
+			Type:     notificationstypes.EventPreflightComplete,
+			AppID:    appID,
+			AppSlug:  appSlug,
+			Sequence: &sequence,
+			Data: map[string]interface{}{
+				"state": GetPreflightState(uploadPreflightResults, ignoreNonStrict),
+			},
+		})
+
 		go func() {
 			err := reporting.GetReporter().SubmitAppInfo(appID) // send app and preflight info when preflights finish
 			if err != nil {
@@ -162,6 +185,97 @@ func Run(appID string, appSlug string, sequence int64, isAirgap bool, ignoreNonS
 	return nil
 }
 
+// Analyze runs the preflight checks of an app without recording the results against a version,
+// and returns an error that lists the failed checks if any of them fail
+func Analyze(a *apptypes.App, kotsKinds *kotsutil.KotsKinds) error {
+	registrySettings, err := store.GetStore().GetRegistryDetailsForApp(a.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get registry settings for app")
+	}
+
+	InjectDefaultPreflights(kotsKinds.Preflight, kotsKinds, registrySettings)
+
+	collectors, err := registry.UpdateCollectorSpecsWithRegistryData(kotsKinds.Preflight.Spec.Collectors, registrySettings, kotsKinds.Installation, kotsKinds.License, &kotsKinds.KotsApplication)
+	if err != nil {
+		return errors.Wrap(err, "failed to rewrite images in preflight")
+	}
+	kotsKinds.Preflight.Spec.Collectors = collectors
+
+	setProgress := func(progress map[string]interface{}) error {
+		return nil
+	}
+	setResults := func(results *types.PreflightResults) error {
+		return nil
+	}
+	results, err := Execute(kotsKinds.Preflight, true, setProgress, setResults)
+	if err != nil {
+		return errors.Wrap(err, "failed to run preflight checks")
+	}
+
+	if GetPreflightState(results, false) != "fail" {
+		return nil
+	}
+
+	failed := []string{}
+	for _, e := range results.Errors {
+		failed = append(failed, e.Error)
+	}
+	for _, result := range results.Results {
+		if result.IsFail {
+			failed = append(failed, result.Title)
+		}
+	}
+	return errors.Errorf("failed checks: %s", strings.Join(failed, ", "))
+}
+
+const imageVerificationCheckTitle = "Image Signatures"
+
+// addImageVerificationResult adds the signature verification results of the images of this version
+// that were copied to the registry to the preflight results
+func addImageVerificationResult(results *types.PreflightResults, imageVerificationResults []imagetypes.ImageVerificationResult, kotsKinds *kotsutil.KotsKinds) {
+	knownImages := map[string]bool{}
+	for _, i := range kotsKinds.Installation.Spec.KnownImages {
+		knownImages[i.Image] = true
+	}
+
+	failed := []string{}
+	verified := 0
+	for _, result := range imageVerificationResults {
+		if !knownImages[result.Image] {
+			continue
+		}
+		if result.Verified {
+			verified++
+		} else {
+			failed = append(failed, result.Image)
+		}
+	}
+	if verified == 0 && len(failed) == 0 {
+		return
+	}
+
+	uploadPreflightResult := &troubleshootpreflight.UploadPreflightResult{
+		Strict: true,
+		Title:  imageVerificationCheckTitle,
+	}
+	if len(failed) > 0 {
+		uploadPreflightResult.IsFail = true
+		uploadPreflightResult.Message = fmt.Sprintf("The signatures of the following images could not be verified: %s", strings.Join(failed, ", "))
+	} else {
+		uploadPreflightResult.IsPass = true
+		uploadPreflightResult.Message = fmt.Sprintf("The signatures of %d images were verified", verified)
+	}
+
+	// results can be set more than once, replace the previous result
+	for i, r := range results.Results {
+		if r.Title == imageVerificationCheckTitle {
+			results.Results[i] = uploadPreflightResult
+			return
+		}
+	}
+	results.Results = append(results.Results, uploadPreflightResult)
+}
+
 func setPreflightProgress(appID string, sequence int64, progress map[string]interface{}) error {
 	b, err := json.Marshal(progress)
 	if err != nil {
@@ -170,6 +284,12 @@ func setPreflightProgress(appID string, sequence int64, progress map[string]inte
 	if err := store.GetStore().SetPreflightProgress(appID, sequence, string(b)); err != nil {
 		return errors.Wrap(err, "failed to set preflight progress")
 	}
+	eventstream.Publish(eventstreamtypes.Event{
+		Type:     eventstreamtypes.EventPreflightProgress,
+		AppID:    appID,
+		Sequence: &sequence,
+		Data:     progress,
+	})
 	return nil
 }
 
@@ -181,6 +301,12 @@ func setPreflightResults(appID string, sequence int64, preflightResults *types.P
 	if err := store.GetStore().SetPreflightResults(appID, sequence, b); err != nil {
 		return errors.Wrap(err, "failed to set preflight results")
 	}
+	eventstream.Publish(eventstreamtypes.Event{
+		Type:     eventstreamtypes.EventPreflightResults,
+		AppID:    appID,
+		Sequence: &sequence,
+		Data:     preflightResults,
+	})
 	return nil
 }
 
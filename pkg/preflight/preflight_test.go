@@ -3,7 +3,10 @@ package preflight
 import (
 	"testing"
 
+	imagetypes "github.com/replicatedhq/kots/pkg/image/types"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/preflight/types"
+	kotsv1beta1 "github.com/replicatedhq/kotskinds/apis/kots/v1beta1"
 	kurlv1beta1 "github.com/replicatedhq/kurlkinds/pkg/apis/cluster/v1beta1"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
 	troubleshootpreflight "github.com/replicatedhq/troubleshoot/pkg/preflight"
@@ -290,3 +293,75 @@ func Test_injectInstallerPreflightIfPresent(t *testing.T) {
 		})
 	}
 }
+
+func Test_addImageVerificationResult(t *testing.T) {
+	kotsKinds := &kotsutil.KotsKinds{
+		Installation: kotsv1beta1.Installation{
+			Spec: kotsv1beta1.InstallationSpec{
+				KnownImages: []kotsv1beta1.InstallationImage{
+					{Image: "registry.example.com/app:1.0.0"},
+					{Image: "registry.example.com/worker:1.0.0"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                     string
+		imageVerificationResults []imagetypes.ImageVerificationResult
+		want                     *troubleshootpreflight.UploadPreflightResult
+	}{
+		{
+			name:                     "no results",
+			imageVerificationResults: []imagetypes.ImageVerificationResult{},
+		},
+		{
+			name: "images of other versions are ignored",
+			imageVerificationResults: []imagetypes.ImageVerificationResult{
+				{Image: "registry.example.com/app:0.9.0", Verified: false},
+			},
+		},
+		{
+			name: "verified",
+			imageVerificationResults: []imagetypes.ImageVerificationResult{
+				{Image: "registry.example.com/app:1.0.0", Verified: true},
+				{Image: "registry.example.com/worker:1.0.0", Verified: true},
+			},
+			want: &troubleshootpreflight.UploadPreflightResult{
+				IsPass:  true,
+				Strict:  true,
+				Title:   imageVerificationCheckTitle,
+				Message: "The signatures of 2 images were verified",
+			},
+		},
+		{
+			name: "failed",
+			imageVerificationResults: []imagetypes.ImageVerificationResult{
+				{Image: "registry.example.com/app:1.0.0", Verified: true},
+				{Image: "registry.example.com/worker:1.0.0", Verified: false},
+			},
+			want: &troubleshootpreflight.UploadPreflightResult{
+				IsFail:  true,
+				Strict:  true,
+				Title:   imageVerificationCheckTitle,
+				Message: "The signatures of the following images could not be verified: registry.example.com/worker:1.0.0",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := &types.PreflightResults{}
+
+			// results can be set more than once, the check is only added once
+			addImageVerificationResult(results, tt.imageVerificationResults, kotsKinds)
+			addImageVerificationResult(results, tt.imageVerificationResults, kotsKinds)
+
+			if tt.want == nil {
+				require.Empty(t, results.Results)
+				return
+			}
+			require.Len(t, results.Results, 1)
+			require.Equal(t, tt.want, results.Results[0])
+		})
+	}
+}
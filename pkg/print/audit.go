@@ -0,0 +1,39 @@
+package print
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	audittypes "github.com/replicatedhq/kots/pkg/audit/types"
+)
+
+func AuditLog(entries []audittypes.AuditLogEntry, format string) {
+	switch format {
+	case "json":
+		printAuditLogJSON(entries)
+	default:
+		printAuditLogTable(entries)
+	}
+}
+
+func printAuditLogJSON(entries []audittypes.AuditLogEntry) {
+	str, _ := json.MarshalIndent(entries, "", "    ")
+	fmt.Println(string(str))
+}
+
+func printAuditLogTable(entries []audittypes.AuditLogEntry) {
+	w := NewTabWriter()
+	defer w.Flush()
+
+	fmtColumns := "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n"
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", "TIME", "ACTION", "APP", "SEQUENCE", "CALLER", "ROLES", "OUTCOME", "STATUS")
+	for _, entry := range entries {
+		sequence := ""
+		if entry.Sequence != nil {
+			sequence = fmt.Sprintf("%d", *entry.Sequence)
+		}
+		fmt.Fprintf(w, fmtColumns, entry.CreatedAt.Format(time.RFC3339), entry.Action, entry.AppSlug, sequence, entry.Caller, strings.Join(entry.Roles, ","), entry.Outcome, entry.Status)
+	}
+}
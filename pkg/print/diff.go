@@ -0,0 +1,41 @@
+package print
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/replicatedhq/kots/pkg/apparchive"
+)
+
+func VersionDiff(resources []apparchive.ResourceDiff, format string, stat bool) {
+	switch {
+	case format == "json":
+		printVersionDiffJSON(resources)
+	case stat:
+		printVersionDiffTable(resources)
+	default:
+		printVersionDiffUnified(resources)
+	}
+}
+
+func printVersionDiffJSON(resources []apparchive.ResourceDiff) {
+	str, _ := json.MarshalIndent(resources, "", "    ")
+	fmt.Println(string(str))
+}
+
+func printVersionDiffTable(resources []apparchive.ResourceDiff) {
+	w := NewTabWriter()
+	defer w.Flush()
+
+	fmtColumns := "%s\t%s\t%s\t%s\t+%d\t-%d\n"
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", "STATUS", "KIND", "NAMESPACE", "NAME", "ADDED", "REMOVED")
+	for _, r := range resources {
+		fmt.Fprintf(w, fmtColumns, r.Status, r.Kind, r.Namespace, r.Name, r.LinesAdded, r.LinesRemoved)
+	}
+}
+
+func printVersionDiffUnified(resources []apparchive.ResourceDiff) {
+	for _, r := range resources {
+		fmt.Print(r.Diff)
+	}
+}
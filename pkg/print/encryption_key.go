@@ -0,0 +1,42 @@
+package print
+
+import (
+	"encoding/json"
+	"fmt"
+
+	keyrotationtypes "github.com/replicatedhq/kots/pkg/keyrotation/types"
+)
+
+func EncryptionKeyRotation(report *keyrotationtypes.Report, format string) {
+	switch format {
+	case "json":
+		printEncryptionKeyRotationJSON(report)
+	default:
+		printEncryptionKeyRotationTable(report)
+	}
+}
+
+func printEncryptionKeyRotationJSON(report *keyrotationtypes.Report) {
+	str, _ := json.MarshalIndent(report, "", "    ")
+	fmt.Println(string(str))
+}
+
+func printEncryptionKeyRotationTable(report *keyrotationtypes.Report) {
+	w := NewTabWriter()
+	defer w.Flush()
+
+	reencryptedColumn := "REENCRYPTED"
+	if report.DryRun {
+		reencryptedColumn = "TO REENCRYPT"
+	}
+
+	fmtColumns := "%s\t%s\t%s\t%s\t%d\t%d\t%s\n"
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", "KIND", "APP", "SEQUENCE", "NAME", "ENCRYPTED", reencryptedColumn, "ERROR")
+	for _, l := range report.Locations {
+		sequence := ""
+		if l.Sequence != nil {
+			sequence = fmt.Sprintf("%d", *l.Sequence)
+		}
+		fmt.Fprintf(w, fmtColumns, l.Kind, l.AppSlug, sequence, l.Name, l.Encrypted, l.Reencrypted, l.Error)
+	}
+}
@@ -0,0 +1,311 @@
+package promotion
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	downstreamtypes "github.com/replicatedhq/kots/pkg/api/downstream/types"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/cluster"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/operator"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
+	"github.com/replicatedhq/kots/pkg/preflight"
+	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
+	"github.com/replicatedhq/kots/pkg/promotion/types"
+	"github.com/replicatedhq/kots/pkg/store"
+	storetypes "github.com/replicatedhq/kots/pkg/store/types"
+	"go.uber.org/zap"
+)
+
+// ErrGatesFailed is returned by Promote when at least one of the gates of the stage did not pass
+var ErrGatesFailed = errors.New("promotion gates did not pass")
+
+type PromoteResult struct {
+	Promotion *types.Promotion
+	Gates     []types.GateResult
+}
+
+// gateInputs is everything the gates of a stage are evaluated against
+type gateInputs struct {
+	sourceIsRemote   bool
+	sourceCurrent    *downstreamtypes.DownstreamVersion
+	sourceAppStatus  *appstatetypes.AppStatus
+	preflightResult  *preflighttypes.PreflightResult
+	driftStatus      *operatortypes.AppDriftStatus
+	hasPreflightSpec bool
+}
+
+// ValidateStage returns an error if the stage can't be added to the stages of the app
+func ValidateStage(stage types.Stage, stages []types.Stage, sourceIsRemote bool) error {
+	if stage.SourceClusterID == "" || stage.TargetClusterID == "" {
+		return errors.New("source and target clusters are required")
+	}
+	if stage.SourceClusterID == stage.TargetClusterID {
+		return errors.New("source and target clusters must be different")
+	}
+	if stage.MinSoakSeconds < 0 {
+		return errors.New("minimum soak time can't be negative")
+	}
+	if stage.RequireNoDrift && sourceIsRemote {
+		return errors.New("drift is not detected in remote clusters, no drift can't be required for a remote source cluster")
+	}
+
+	sourceOf := map[string]string{}
+	for _, s := range stages {
+		sourceOf[s.TargetClusterID] = s.SourceClusterID
+	}
+	sourceOf[stage.TargetClusterID] = stage.SourceClusterID
+
+	// following the sources back from the new stage must not lead to its target again
+	visited := map[string]bool{stage.TargetClusterID: true}
+	for clusterID := stage.SourceClusterID; clusterID != ""; clusterID = sourceOf[clusterID] {
+		if visited[clusterID] {
+			return errors.Errorf("promoting from cluster %s to cluster %s would create a cycle", stage.SourceClusterID, stage.TargetClusterID)
+		}
+		visited[clusterID] = true
+	}
+
+	return nil
+}
+
+// CheckGates evaluates the gates of a stage for promoting a sequence without promoting it
+func CheckGates(stage types.Stage, sequence int64) ([]types.GateResult, error) {
+	inputs, err := loadGateInputs(stage, sequence)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load gate inputs")
+	}
+
+	return evaluateGates(stage, sequence, inputs, time.Now()), nil
+}
+
+// Promote deploys a sequence to the target downstream of a stage if all of the stage's gates pass.
+// If sequence is nil, the sequence that's currently deployed to the source downstream is promoted.
+func Promote(appID string, targetClusterID string, sequence *int64) (*PromoteResult, error) {
+	stage, err := store.GetStore().GetPromotionStage(appID, targetClusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get promotion stage")
+	}
+	if stage == nil {
+		return nil, errors.Errorf("cluster %s is not the target of a promotion stage", targetClusterID)
+	}
+
+	if sequence == nil {
+		current, err := store.GetStore().GetCurrentDownstreamSequence(appID, stage.SourceClusterID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get current sequence of source downstream")
+		}
+		if current == -1 {
+			return nil, errors.New("no version is deployed to the source downstream")
+		}
+		sequence = &current
+	}
+
+	gates, err := CheckGates(*stage, *sequence)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check gates")
+	}
+	result := &PromoteResult{
+		Gates: gates,
+	}
+	if !types.AllPassed(gates) {
+		return result, ErrGatesFailed
+	}
+
+	status, err := store.GetStore().GetStatusForVersion(appID, targetClusterID, *sequence)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get status of version in target downstream")
+	}
+	if status == storetypes.VersionPendingDownload || status == storetypes.VersionPendingConfig {
+		return nil, errors.Errorf("version %d is %s in the target downstream", *sequence, status)
+	}
+
+	logger.Info("promoting app version",
+		zap.String("appId", appID),
+		zap.Int64("sequence", *sequence),
+		zap.String("sourceClusterId", stage.SourceClusterID),
+		zap.String("targetClusterId", targetClusterID))
+
+	if err := operator.DeployToCluster(appID, targetClusterID, *sequence); err != nil {
+		return nil, errors.Wrap(err, "failed to deploy to target downstream")
+	}
+
+	promotion := &types.Promotion{
+		AppID:           appID,
+		Sequence:        *sequence,
+		SourceClusterID: stage.SourceClusterID,
+		TargetClusterID: targetClusterID,
+		Gates:           gates,
+	}
+	if err := store.GetStore().CreatePromotion(promotion); err != nil {
+		return nil, errors.Wrap(err, "failed to record promotion")
+	}
+	result.Promotion = promotion
+
+	return result, nil
+}
+
+func loadGateInputs(stage types.Stage, sequence int64) (*gateInputs, error) {
+	source, err := store.GetStore().GetCluster(stage.SourceClusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get source cluster")
+	}
+	if source == nil {
+		return nil, errors.Errorf("source cluster %s not found", stage.SourceClusterID)
+	}
+
+	inputs := &gateInputs{
+		sourceIsRemote: cluster.IsRemote(source),
+	}
+
+	inputs.sourceCurrent, err = store.GetStore().GetCurrentDownstreamVersion(stage.AppID, stage.SourceClusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get current version of source downstream")
+	}
+
+	if inputs.sourceIsRemote {
+		inputs.sourceAppStatus, err = store.GetStore().GetDownstreamAppStatus(stage.AppID, stage.SourceClusterID)
+	} else {
+		inputs.sourceAppStatus, err = store.GetStore().GetAppStatus(stage.AppID)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get app status of source downstream")
+	}
+
+	if stage.RequirePreflights {
+		inputs.hasPreflightSpec, err = hasPreflightSpec(stage.AppID, sequence)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to check for preflight spec")
+		}
+		inputs.preflightResult, err = store.GetStore().GetPreflightResults(stage.AppID, sequence)
+		if err != nil && !store.GetStore().IsNotFound(err) {
+			return nil, errors.Wrap(err, "failed to get preflight results")
+		}
+	}
+
+	if stage.RequireNoDrift && !inputs.sourceIsRemote {
+		inputs.driftStatus, err = store.GetStore().GetAppDriftStatus(stage.AppID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get drift status")
+		}
+	}
+
+	return inputs, nil
+}
+
+func hasPreflightSpec(appID string, sequence int64) (bool, error) {
+	appVersion, err := store.GetStore().GetAppVersion(appID, sequence)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get app version")
+	}
+	return appVersion.KOTSKinds.HasPreflights(), nil
+}
+
+func evaluateGates(stage types.Stage, sequence int64, inputs *gateInputs, now time.Time) []types.GateResult {
+	gates := []types.GateResult{
+		evaluateSourceDeployedGate(sequence, inputs.sourceCurrent),
+	}
+
+	if stage.MinSoakSeconds > 0 {
+		gates = append(gates, evaluateSoakGate(sequence, time.Duration(stage.MinSoakSeconds)*time.Second, inputs.sourceAppStatus, now))
+	}
+	if stage.RequirePreflights {
+		gates = append(gates, evaluatePreflightGate(inputs.hasPreflightSpec, inputs.preflightResult))
+	}
+	if stage.RequireNoDrift {
+		gates = append(gates, evaluateDriftGate(sequence, inputs.sourceIsRemote, inputs.driftStatus))
+	}
+
+	return gates
+}
+
+func evaluateSourceDeployedGate(sequence int64, current *downstreamtypes.DownstreamVersion) types.GateResult {
+	gate := types.GateResult{Name: types.GateSourceDeployed}
+
+	switch {
+	case current == nil:
+		gate.Message = "no version is deployed to the source downstream"
+	case current.Sequence != sequence:
+		gate.Message = fmt.Sprintf("sequence %d is deployed to the source downstream", current.Sequence)
+	case current.Status != storetypes.VersionDeployed:
+		gate.Message = fmt.Sprintf("sequence %d is %s in the source downstream", sequence, current.Status)
+	default:
+		gate.Passed = true
+	}
+
+	return gate
+}
+
+func evaluateSoakGate(sequence int64, minSoak time.Duration, appStatus *appstatetypes.AppStatus, now time.Time) types.GateResult {
+	gate := types.GateResult{Name: types.GateSoakTime}
+
+	switch {
+	case appStatus == nil || appStatus.Sequence != sequence:
+		gate.Message = "the app status of the source downstream is not for this sequence"
+	case appStatus.State != appstatetypes.StateReady || appStatus.ReadyAt == nil:
+		gate.Message = fmt.Sprintf("the app is %s in the source downstream", appStatus.State)
+	default:
+		soaked := now.Sub(*appStatus.ReadyAt)
+		if soaked < minSoak {
+			gate.Message = fmt.Sprintf("the app has been ready for %s of the required %s", soaked.Truncate(time.Second), minSoak)
+		} else {
+			gate.Passed = true
+			gate.Message = fmt.Sprintf("the app has been ready for %s", soaked.Truncate(time.Second))
+		}
+	}
+
+	return gate
+}
+
+func evaluatePreflightGate(hasPreflightSpec bool, result *preflighttypes.PreflightResult) types.GateResult {
+	gate := types.GateResult{Name: types.GatePreflights}
+
+	if !hasPreflightSpec {
+		gate.Passed = true
+		gate.Message = "the version has no preflight checks"
+		return gate
+	}
+
+	if result == nil || result.Skipped {
+		gate.Message = "preflight checks were skipped"
+		return gate
+	}
+	if result.Result == "" {
+		gate.Message = "preflight checks have not completed"
+		return gate
+	}
+
+	results := preflighttypes.PreflightResults{}
+	if err := json.Unmarshal([]byte(result.Result), &results); err != nil {
+		gate.Message = "failed to parse preflight results"
+		return gate
+	}
+
+	if state := preflight.GetPreflightState(&results, false); state == "fail" {
+		gate.Message = "preflight checks failed"
+		return gate
+	}
+
+	gate.Passed = true
+	return gate
+}
+
+// evaluateDriftGate only passes if the sequence was checked for drift in the source downstream and none was found
+func evaluateDriftGate(sequence int64, sourceIsRemote bool, driftStatus *operatortypes.AppDriftStatus) types.GateResult {
+	gate := types.GateResult{Name: types.GateNoDrift}
+
+	switch {
+	case sourceIsRemote:
+		gate.Message = "drift is not detected in remote clusters"
+	case driftStatus == nil || driftStatus.Sequence != sequence:
+		gate.Message = "the source downstream has not been checked for drift"
+	case driftStatus.IsDrifted():
+		gate.Message = "the source downstream has drifted from the deployed manifests"
+	default:
+		gate.Passed = true
+	}
+
+	return gate
+}
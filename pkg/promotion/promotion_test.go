@@ -0,0 +1,272 @@
+package promotion
+
+import (
+	"testing"
+	"time"
+
+	downstreamtypes "github.com/replicatedhq/kots/pkg/api/downstream/types"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
+	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
+	"github.com/replicatedhq/kots/pkg/promotion/types"
+	storetypes "github.com/replicatedhq/kots/pkg/store/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateStage(t *testing.T) {
+	existing := []types.Stage{
+		{SourceClusterID: "dev", TargetClusterID: "staging"},
+		{SourceClusterID: "staging", TargetClusterID: "production"},
+	}
+
+	tests := []struct {
+		name           string
+		stage          types.Stage
+		sourceIsRemote bool
+		wantErr        bool
+	}{
+		{
+			name:  "extends the pipeline",
+			stage: types.Stage{SourceClusterID: "production", TargetClusterID: "dr"},
+		},
+		{
+			name:  "replaces the source of an existing target",
+			stage: types.Stage{SourceClusterID: "dev", TargetClusterID: "production", MinSoakSeconds: 3600},
+		},
+		{
+			name:    "same source and target",
+			stage:   types.Stage{SourceClusterID: "staging", TargetClusterID: "staging"},
+			wantErr: true,
+		},
+		{
+			name:    "missing source",
+			stage:   types.Stage{TargetClusterID: "staging"},
+			wantErr: true,
+		},
+		{
+			name:    "negative soak time",
+			stage:   types.Stage{SourceClusterID: "production", TargetClusterID: "dr", MinSoakSeconds: -1},
+			wantErr: true,
+		},
+		{
+			name:    "cycle",
+			stage:   types.Stage{SourceClusterID: "production", TargetClusterID: "dev"},
+			wantErr: true,
+		},
+		{
+			name:           "remote source",
+			stage:          types.Stage{SourceClusterID: "production", TargetClusterID: "dr", RequirePreflights: true},
+			sourceIsRemote: true,
+		},
+		{
+			name:           "no drift required for a remote source",
+			stage:          types.Stage{SourceClusterID: "production", TargetClusterID: "dr", RequireNoDrift: true},
+			sourceIsRemote: true,
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStage(tt.stage, existing, tt.sourceIsRemote)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_evaluateSourceDeployedGate(t *testing.T) {
+	tests := []struct {
+		name     string
+		sequence int64
+		current  *downstreamtypes.DownstreamVersion
+		want     bool
+	}{
+		{
+			name:     "nothing deployed",
+			sequence: 3,
+			want:     false,
+		},
+		{
+			name:     "other sequence deployed",
+			sequence: 3,
+			current:  &downstreamtypes.DownstreamVersion{Sequence: 2, Status: storetypes.VersionDeployed},
+			want:     false,
+		},
+		{
+			name:     "deploy failed",
+			sequence: 3,
+			current:  &downstreamtypes.DownstreamVersion{Sequence: 3, Status: storetypes.VersionFailed},
+			want:     false,
+		},
+		{
+			name:     "deployed",
+			sequence: 3,
+			current:  &downstreamtypes.DownstreamVersion{Sequence: 3, Status: storetypes.VersionDeployed},
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateSourceDeployedGate(tt.sequence, tt.current)
+			assert.Equal(t, types.GateSourceDeployed, got.Name)
+			assert.Equal(t, tt.want, got.Passed, got.Message)
+		})
+	}
+}
+
+func Test_evaluateSoakGate(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	readyAt := func(ago time.Duration) *time.Time {
+		t := now.Add(-ago)
+		return &t
+	}
+
+	tests := []struct {
+		name      string
+		appStatus *appstatetypes.AppStatus
+		want      bool
+	}{
+		{
+			name:      "no status",
+			appStatus: nil,
+			want:      false,
+		},
+		{
+			name:      "status of another sequence",
+			appStatus: &appstatetypes.AppStatus{Sequence: 2, State: appstatetypes.StateReady, ReadyAt: readyAt(2 * time.Hour)},
+			want:      false,
+		},
+		{
+			name:      "degraded",
+			appStatus: &appstatetypes.AppStatus{Sequence: 3, State: appstatetypes.StateDegraded},
+			want:      false,
+		},
+		{
+			name:      "not ready for long enough",
+			appStatus: &appstatetypes.AppStatus{Sequence: 3, State: appstatetypes.StateReady, ReadyAt: readyAt(30 * time.Minute)},
+			want:      false,
+		},
+		{
+			name:      "soaked",
+			appStatus: &appstatetypes.AppStatus{Sequence: 3, State: appstatetypes.StateReady, ReadyAt: readyAt(time.Hour)},
+			want:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateSoakGate(3, time.Hour, tt.appStatus, now)
+			assert.Equal(t, types.GateSoakTime, got.Name)
+			assert.Equal(t, tt.want, got.Passed, got.Message)
+		})
+	}
+}
+
+func Test_evaluatePreflightGate(t *testing.T) {
+	tests := []struct {
+		name             string
+		hasPreflightSpec bool
+		result           *preflighttypes.PreflightResult
+		want             bool
+	}{
+		{
+			name:             "no preflights in the version",
+			hasPreflightSpec: false,
+			want:             true,
+		},
+		{
+			name:             "no results",
+			hasPreflightSpec: true,
+			want:             false,
+		},
+		{
+			name:             "skipped",
+			hasPreflightSpec: true,
+			result:           &preflighttypes.PreflightResult{Skipped: true},
+			want:             false,
+		},
+		{
+			name:             "still running",
+			hasPreflightSpec: true,
+			result:           &preflighttypes.PreflightResult{},
+			want:             false,
+		},
+		{
+			name:             "failed",
+			hasPreflightSpec: true,
+			result:           &preflighttypes.PreflightResult{Result: `{"results":[{"isFail":true,"title":"Kubernetes version"}]}`},
+			want:             false,
+		},
+		{
+			name:             "errors",
+			hasPreflightSpec: true,
+			result:           &preflighttypes.PreflightResult{Result: `{"errors":[{"isRbac":true,"error":"forbidden"}]}`},
+			want:             false,
+		},
+		{
+			name:             "warnings",
+			hasPreflightSpec: true,
+			result:           &preflighttypes.PreflightResult{Result: `{"results":[{"isWarn":true,"title":"Memory"},{"isPass":true,"title":"Kubernetes version"}]}`},
+			want:             true,
+		},
+		{
+			name:             "passed",
+			hasPreflightSpec: true,
+			result:           &preflighttypes.PreflightResult{Result: `{"results":[{"isPass":true,"title":"Kubernetes version"}]}`},
+			want:             true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluatePreflightGate(tt.hasPreflightSpec, tt.result)
+			assert.Equal(t, types.GatePreflights, got.Name)
+			assert.Equal(t, tt.want, got.Passed, got.Message)
+		})
+	}
+}
+
+func Test_evaluateDriftGate(t *testing.T) {
+	drifted := []operatortypes.DriftedResource{{Kind: "Deployment", Name: "api", Reason: operatortypes.DriftReasonModified}}
+
+	tests := []struct {
+		name           string
+		sourceIsRemote bool
+		driftStatus    *operatortypes.AppDriftStatus
+		want           bool
+	}{
+		{
+			name:           "remote source",
+			sourceIsRemote: true,
+			driftStatus:    &operatortypes.AppDriftStatus{Sequence: 3},
+			want:           false,
+		},
+		{
+			name: "not checked",
+			want: false,
+		},
+		{
+			name:        "checked for another sequence",
+			driftStatus: &operatortypes.AppDriftStatus{Sequence: 2},
+			want:        false,
+		},
+		{
+			name:        "no drift",
+			driftStatus: &operatortypes.AppDriftStatus{Sequence: 3},
+			want:        true,
+		},
+		{
+			name:        "drifted",
+			driftStatus: &operatortypes.AppDriftStatus{Sequence: 3, Resources: drifted},
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateDriftGate(3, tt.sourceIsRemote, tt.driftStatus)
+			assert.Equal(t, types.GateNoDrift, got.Name)
+			assert.Equal(t, tt.want, got.Passed, got.Message)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package types
+
+import "time"
+
+const (
+	GateSourceDeployed = "source-deployed"
+	GateSoakTime       = "soak-time"
+	GatePreflights     = "preflights"
+	GateNoDrift        = "no-drift"
+)
+
+// Stage promotes versions of an app from the downstream of one cluster to the downstream of another.
+// A downstream is the target of at most one stage, so stages chain into a pipeline (e.g. staging -> production).
+type Stage struct {
+	AppID           string `json:"appId"`
+	SourceClusterID string `json:"sourceClusterId"`
+	TargetClusterID string `json:"targetClusterId"`
+	// MinSoakSeconds is how long the version must have been continuously ready in the source downstream
+	MinSoakSeconds    int64 `json:"minSoakSeconds"`
+	RequirePreflights bool  `json:"requirePreflights"`
+	// RequireNoDrift requires that the source downstream was checked for drift and has none. Drift is only
+	// detected in the cluster kotsadm runs in, so this can't be required for remote source clusters.
+	RequireNoDrift bool `json:"requireNoDrift"`
+}
+
+type GateResult struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// Promotion is a version that was promoted from one downstream to another
+type Promotion struct {
+	ID              string       `json:"id"`
+	AppID           string       `json:"appId"`
+	Sequence        int64        `json:"sequence"`
+	SourceClusterID string       `json:"sourceClusterId"`
+	TargetClusterID string       `json:"targetClusterId"`
+	PromotedAt      time.Time    `json:"promotedAt"`
+	Gates           []GateResult `json:"gates"`
+}
+
+// AllPassed returns true if none of the gates blocked the promotion
+func AllPassed(gates []GateResult) bool {
+	for _, g := range gates {
+		if !g.Passed {
+			return false
+		}
+	}
+	return true
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/downstream"
 	"github.com/replicatedhq/kots/pkg/image"
 	imagetypes "github.com/replicatedhq/kots/pkg/image/types"
+	"github.com/replicatedhq/kots/pkg/imageverification"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/kotsadmconfig"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
@@ -352,17 +353,24 @@ func Pull(upstreamURI string, pullOptions PullOptions) (string, error) {
 		return "", errors.Wrap(err, "failed to check if version needs configuration")
 	}
 
+	verificationPolicy, err := imageverification.GetPolicyForApp(pullOptions.AppSlug)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get image verification policy")
+	}
+
 	processImageOptions := imagetypes.ProcessImageOptions{
-		AppSlug:          pullOptions.AppSlug,
-		Namespace:        pullOptions.Namespace,
-		RewriteImages:    pullOptions.RewriteImages,
-		RegistrySettings: pullOptions.RewriteImageOptions,
-		CopyImages:       !pullOptions.RewriteImageOptions.IsReadOnly,
-		RootDir:          pullOptions.RootDir,
-		IsAirgap:         pullOptions.IsAirgap,
-		AirgapBundle:     pullOptions.AirgapBundle,
-		CreateAppDir:     pullOptions.CreateAppDir,
-		ReportWriter:     pullOptions.ReportWriter,
+		AppSlug:             pullOptions.AppSlug,
+		Namespace:           pullOptions.Namespace,
+		RewriteImages:       pullOptions.RewriteImages,
+		RegistrySettings:    pullOptions.RewriteImageOptions,
+		CopyImages:          !pullOptions.RewriteImageOptions.IsReadOnly,
+		RootDir:             pullOptions.RootDir,
+		IsAirgap:            pullOptions.IsAirgap,
+		AirgapBundle:        pullOptions.AirgapBundle,
+		CreateAppDir:        pullOptions.CreateAppDir,
+		ReportWriter:        pullOptions.ReportWriter,
+		VerificationPolicy:  verificationPolicy,
+		VerificationResults: &imagetypes.ImageVerificationResults{},
 	}
 
 	if needsConfig {
@@ -375,6 +383,9 @@ func Pull(upstreamURI string, pullOptions PullOptions) (string, error) {
 				return "", errors.Wrap(err, "failed to copy airgap images")
 			}
 		}
+		if err := imageverification.SaveResults(pullOptions.AppID, pullOptions.AppSlug, pullOptions.AppSequence, processImageOptions.VerificationResults, pullOptions.IsAirgap); err != nil {
+			return "", errors.Wrap(err, "failed to save image verification results")
+		}
 		return "", ErrConfigNeeded
 	}
 
@@ -632,6 +643,10 @@ func Pull(upstreamURI string, pullOptions PullOptions) (string, error) {
 		return "", errors.Wrap(err, "failed to write the rendered kots kinds")
 	}
 
+	if err := imageverification.SaveResults(pullOptions.AppID, pullOptions.AppSlug, pullOptions.AppSequence, processImageOptions.VerificationResults, pullOptions.IsAirgap); err != nil {
+		return "", errors.Wrap(err, "failed to save image verification results")
+	}
+
 	return filepath.Join(pullOptions.RootDir, u.Name), nil
 }
 
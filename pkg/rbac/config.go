@@ -0,0 +1,197 @@
+package rbac
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	ghodssyaml "github.com/ghodss/yaml"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/rbac/types"
+	"github.com/replicatedhq/kots/pkg/util"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+/*
+Custom roles are defined in the kotsadm-rbac config map in the kotsadm namespace, e.g.
+
+	roles:
+	- id: deployer
+	  name: Deployer
+	  allow:
+	  - action: read
+	    resource: "**"
+	  - action: write
+	    resource: "app.*.downstream.*"
+	  deny:
+	  - action: write
+	    resource: "**.snapshotsettings.*"
+	  - action: write
+	    resource: "**.gitops.*"
+	users:
+	- id: oncall@example.com
+	  roleIds: [deployer]
+
+Roles are assigned to identity service groups in the identity config, or to individual
+users by their email address. Roles are only assigned to users whose identity provider
+has verified their email address, since anyone can claim any address otherwise.
+*/
+
+var (
+	RolesConfigMapName = "kotsadm-rbac"
+	RolesConfigMapKey  = "roles.yaml"
+
+	rolesConfigCacheTTL = 30 * time.Second
+)
+
+var rolesConfigCache struct {
+	mu       sync.Mutex
+	config   *types.RolesConfig
+	loadedAt time.Time
+}
+
+// GetRoles returns the default roles followed by any custom roles. If the custom roles cannot
+// be loaded, only the default roles are returned so that access falls back to deny.
+func GetRoles(ctx context.Context) []types.Role {
+	config, err := getCachedRolesConfig(ctx)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get custom roles"))
+		return DefaultRoles()
+	}
+	return append(DefaultRoles(), config.Roles...)
+}
+
+// GetUserRoleIDs returns the role ids assigned directly to a user by their email address.
+// No roles are returned if the email address has not been verified.
+func GetUserRoleIDs(ctx context.Context, email string, emailVerified bool) []string {
+	config, err := getCachedRolesConfig(ctx)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get custom roles"))
+		return nil
+	}
+
+	return getUserRoleIDs(config, email, emailVerified)
+}
+
+func getUserRoleIDs(config *types.RolesConfig, email string, emailVerified bool) []string {
+	roleIDs := []string{}
+	if !emailVerified {
+		return roleIDs
+	}
+	for _, user := range config.Users {
+		if user.ID == email {
+			roleIDs = append(roleIDs, user.RoleIDs...)
+		}
+	}
+	return roleIDs
+}
+
+func getCachedRolesConfig(ctx context.Context) (*types.RolesConfig, error) {
+	rolesConfigCache.mu.Lock()
+	defer rolesConfigCache.mu.Unlock()
+
+	if rolesConfigCache.config != nil && time.Since(rolesConfigCache.loadedAt) < rolesConfigCacheTTL {
+		return rolesConfigCache.config, nil
+	}
+
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get clientset")
+	}
+
+	config, err := GetRolesConfig(ctx, clientset, util.PodNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	rolesConfigCache.config = config
+	rolesConfigCache.loadedAt = time.Now()
+
+	return config, nil
+}
+
+func GetRolesConfig(ctx context.Context, clientset kubernetes.Interface, namespace string) (*types.RolesConfig, error) {
+	configMap, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, RolesConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if kuberneteserrors.IsNotFound(err) {
+			return &types.RolesConfig{}, nil
+		}
+		return nil, errors.Wrap(err, "failed to get config map")
+	}
+
+	config, err := ParseRolesConfig(ctx, []byte(configMap.Data[RolesConfigMapKey]))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s in config map %s", RolesConfigMapKey, RolesConfigMapName)
+	}
+
+	return config, nil
+}
+
+func ParseRolesConfig(ctx context.Context, data []byte) (*types.RolesConfig, error) {
+	config := types.RolesConfig{}
+	if err := ghodssyaml.Unmarshal(data, &config); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	roleIDs := map[string]bool{}
+	for _, role := range DefaultRoles() {
+		roleIDs[role.ID] = true
+	}
+
+	for _, role := range config.Roles {
+		if role.ID == "" {
+			return nil, errors.New("role id is required")
+		}
+		if roleIDs[role.ID] {
+			return nil, errors.Errorf("role %s is already defined", role.ID)
+		}
+		roleIDs[role.ID] = true
+
+		if err := validateRole(ctx, role); err != nil {
+			return nil, errors.Wrapf(err, "invalid role %s", role.ID)
+		}
+	}
+
+	for _, user := range config.Users {
+		if user.ID == "" {
+			return nil, errors.New("user id is required")
+		}
+		for _, roleID := range user.RoleIDs {
+			if !roleIDs[roleID] {
+				return nil, errors.Errorf("user %s references unknown role %s", user.ID, roleID)
+			}
+		}
+	}
+
+	return &config, nil
+}
+
+func validateRole(ctx context.Context, role types.Role) error {
+	policies := append([]types.Policy{}, role.Allow...)
+	policies = append(policies, role.Deny...)
+
+	for _, p := range policies {
+		if p.Action == "" || p.Resource == "" {
+			return errors.New("policy action and resource are required")
+		}
+
+		// evaluate the policy once so that invalid glob patterns are caught here rather than on every request
+		i := map[string]interface{}{
+			"action":            "read",
+			"resource":          "validate",
+			"roles":             []string{role.ID},
+			"allowRolePolicies": map[string][]types.Policy{role.ID: {p}},
+			"denyRolePolicies":  map[string][]types.Policy{},
+		}
+		if _, err := regoEval(ctx, i, rego.StrictBuiltinErrors(true)); err != nil {
+			return errors.Wrapf(err, "invalid policy %s %s", p.Action, p.Resource)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	"github.com/replicatedhq/kots/pkg/rbac/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const deployerRolesConfig = `
+roles:
+- id: deployer
+  name: Deployer
+  allow:
+  - action: read
+    resource: "**"
+  - action: write
+    resource: "app.*.downstream.*"
+  deny:
+  - action: write
+    resource: "**.snapshotsettings.*"
+  - action: write
+    resource: "**.gitops.*"
+users:
+- id: oncall@example.com
+  roleIds: [deployer, support]
+`
+
+func TestParseRolesConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *types.RolesConfig
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			data: "",
+			want: &types.RolesConfig{},
+		},
+		{
+			name: "deployer",
+			data: deployerRolesConfig,
+			want: &types.RolesConfig{
+				Roles: []types.Role{
+					{
+						ID:   "deployer",
+						Name: "Deployer",
+						Allow: []types.Policy{
+							{Action: "read", Resource: "**"},
+							{Action: "write", Resource: "app.*.downstream.*"},
+						},
+						Deny: []types.Policy{
+							{Action: "write", Resource: "**.snapshotsettings.*"},
+							{Action: "write", Resource: "**.gitops.*"},
+						},
+					},
+				},
+				Users: []types.UserRoles{
+					{ID: "oncall@example.com", RoleIDs: []string{"deployer", "support"}},
+				},
+			},
+		},
+		{
+			name: "missing role id",
+			data: `
+roles:
+- name: No ID
+  allow:
+  - action: read
+    resource: "**"
+`,
+			wantErr: true,
+		},
+		{
+			name: "redefines a default role",
+			data: `
+roles:
+- id: cluster-admin
+  allow:
+  - action: read
+    resource: "**"
+`,
+			wantErr: true,
+		},
+		{
+			name: "missing resource",
+			data: `
+roles:
+- id: reader
+  allow:
+  - action: read
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid glob",
+			data: `
+roles:
+- id: reader
+  allow:
+  - action: read
+    resource: "app.[my-app"
+`,
+			wantErr: true,
+		},
+		{
+			name: "user references unknown role",
+			data: `
+users:
+- id: oncall@example.com
+  roleIds: [deployer]
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRolesConfig(context.Background(), []byte(tt.data))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetRolesConfig(t *testing.T) {
+	ctx := context.Background()
+
+	clientset := fake.NewSimpleClientset()
+	config, err := GetRolesConfig(ctx, clientset, "default")
+	require.NoError(t, err)
+	assert.Equal(t, &types.RolesConfig{}, config)
+
+	clientset = fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RolesConfigMapName,
+			Namespace: "default",
+		},
+		Data: map[string]string{
+			RolesConfigMapKey: deployerRolesConfig,
+		},
+	})
+	config, err = GetRolesConfig(ctx, clientset, "default")
+	require.NoError(t, err)
+	require.Len(t, config.Roles, 1)
+	assert.Equal(t, "deployer", config.Roles[0].ID)
+}
+
+func Test_getUserRoleIDs(t *testing.T) {
+	config, err := ParseRolesConfig(context.Background(), []byte(deployerRolesConfig))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		email         string
+		emailVerified bool
+		want          []string
+	}{
+		{
+			name:          "verified email",
+			email:         "oncall@example.com",
+			emailVerified: true,
+			want:          []string{"deployer", "support"},
+		},
+		{
+			name:  "unverified email",
+			email: "oncall@example.com",
+			want:  []string{},
+		},
+		{
+			name:          "no roles assigned",
+			email:         "other@example.com",
+			emailVerified: true,
+			want:          []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getUserRoleIDs(config, tt.email, tt.emailVerified)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheckAccess_customRole(t *testing.T) {
+	ctx := context.Background()
+
+	config, err := ParseRolesConfig(ctx, []byte(deployerRolesConfig))
+	require.NoError(t, err)
+	roles := append(DefaultRoles(), config.Roles...)
+
+	tests := []struct {
+		action   string
+		resource string
+		want     bool
+	}{
+		{action: "read", resource: "app.my-app", want: true},
+		{action: "write", resource: "app.my-app.downstream.", want: true},
+		{action: "write", resource: "app.my-app.snapshotsettings.", want: false},
+		{action: "write", resource: "app.my-app.gitops.", want: false},
+		{action: "write", resource: "gitops.", want: false},
+		{action: "write", resource: "app.my-app.license.", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action+" "+tt.resource, func(t *testing.T) {
+			got, err := CheckAccess(ctx, roles, tt.action, tt.resource, []string{"deployer"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
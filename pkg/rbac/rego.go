@@ -97,12 +97,13 @@ func roleToDenyRolePolicies(role types.Role) map[string][]types.Policy {
 	}
 }
 
-func regoEval(ctx context.Context, input map[string]interface{}) (bool, error) {
-	query := rego.New(
+func regoEval(ctx context.Context, input map[string]interface{}, opts ...func(r *rego.Rego)) (bool, error) {
+	options := []func(r *rego.Rego){
 		rego.Query("data.rbac.allow"),
 		rego.Compiler(compiler),
 		rego.Input(input),
-	)
+	}
+	query := rego.New(append(options, opts...)...)
 	results, err := query.Eval(ctx)
 	if err != nil {
 		return false, errors.Wrap(err, "failed to evaluate query")
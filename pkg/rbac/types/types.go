@@ -14,3 +14,16 @@ type Policy struct {
 	Action      string `json:"action" yaml:"action"`
 	Resource    string `json:"resource" yaml:"resource"`
 }
+
+// RolesConfig holds the custom roles and per-user role assignments defined by the operator.
+type RolesConfig struct {
+	Roles []Role      `json:"roles,omitempty" yaml:"roles,omitempty"`
+	Users []UserRoles `json:"users,omitempty" yaml:"users,omitempty"`
+}
+
+// UserRoles assigns roles to a single user, in addition to the roles granted by the user's
+// identity service groups. ID is matched against the email claim of the id token.
+type UserRoles struct {
+	ID      string   `json:"id" yaml:"id"`
+	RoleIDs []string `json:"roleIds" yaml:"roleIds"`
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/crypto"
 	"github.com/replicatedhq/kots/pkg/downstream"
 	imagetypes "github.com/replicatedhq/kots/pkg/image/types"
+	"github.com/replicatedhq/kots/pkg/imageverification"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
@@ -252,17 +253,24 @@ func Rewrite(rewriteOptions RewriteOptions) error {
 		Log:                log,
 	}
 
+	verificationPolicy, err := imageverification.GetPolicyForApp(rewriteOptions.AppSlug)
+	if err != nil {
+		return errors.Wrap(err, "failed to get image verification policy")
+	}
+
 	processImageOptions := imagetypes.ProcessImageOptions{
-		AppSlug:          rewriteOptions.AppSlug,
-		Namespace:        rewriteOptions.K8sNamespace,
-		RewriteImages:    rewriteOptions.RegistrySettings.Hostname != "",
-		RegistrySettings: rewriteOptions.RegistrySettings,
-		CopyImages:       rewriteOptions.CopyImages,
-		RootDir:          rewriteOptions.RootDir,
-		IsAirgap:         rewriteOptions.IsAirgap,
-		AirgapBundle:     "",
-		CreateAppDir:     false,
-		ReportWriter:     rewriteOptions.ReportWriter,
+		AppSlug:             rewriteOptions.AppSlug,
+		Namespace:           rewriteOptions.K8sNamespace,
+		RewriteImages:       rewriteOptions.RegistrySettings.Hostname != "",
+		RegistrySettings:    rewriteOptions.RegistrySettings,
+		CopyImages:          rewriteOptions.CopyImages,
+		RootDir:             rewriteOptions.RootDir,
+		IsAirgap:            rewriteOptions.IsAirgap,
+		AirgapBundle:        "",
+		CreateAppDir:        false,
+		ReportWriter:        rewriteOptions.ReportWriter,
+		VerificationPolicy:  verificationPolicy,
+		VerificationResults: &imagetypes.ImageVerificationResults{},
 	}
 
 	writeMidstreamOptions := commonWriteMidstreamOptions
@@ -362,6 +370,10 @@ func Rewrite(rewriteOptions RewriteOptions) error {
 		return errors.Wrap(err, "failed to write the rendered kots kinds")
 	}
 
+	if err := imageverification.SaveResults(rewriteOptions.AppID, rewriteOptions.AppSlug, rewriteOptions.AppSequence, processImageOptions.VerificationResults, false); err != nil {
+		return errors.Wrap(err, "failed to save image verification results")
+	}
+
 	log.FinishSpinner()
 
 	return nil
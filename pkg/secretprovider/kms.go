@@ -0,0 +1,164 @@
+package secretprovider
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/pkg/errors"
+)
+
+const kmsTypeAWS = "aws"
+
+// KMSClient generates data keys and unwraps them
+type KMSClient interface {
+	GenerateDataKey(ctx context.Context, keyID string) (plaintextKey []byte, wrappedKey []byte, err error)
+	Decrypt(ctx context.Context, wrappedKey []byte) ([]byte, error)
+}
+
+// KMSProvider encrypts every value with its own data key, and stores the data key wrapped by the KMS next to the value.
+// The kotsadm database only holds wrapped keys, so values can't be decrypted without access to the KMS.
+type KMSProvider struct {
+	// KeyID is the KMS key that new data keys are generated with. Values can be decrypted without it.
+	KeyID string
+	// Client defaults to AWS KMS with the default credential chain
+	Client KMSClient
+
+	clientMtx sync.Mutex
+}
+
+type kmsEnvelope struct {
+	WrappedKey []byte `json:"k"`
+	Nonce      []byte `json:"n"`
+	Ciphertext []byte `json:"c"`
+}
+
+// Encrypt returns a kms reference that holds the encrypted value
+func (p *KMSProvider) Encrypt(ctx context.Context, plaintext string) (string, error) {
+	if p.KeyID == "" {
+		return "", errors.New("kms key id is not set")
+	}
+
+	client, err := p.getClient()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get kms client")
+	}
+
+	dataKey, wrappedKey, err := client.GenerateDataKey(ctx, p.KeyID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate data key")
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create cipher")
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.Wrap(err, "failed to read nonce")
+	}
+
+	envelope, err := json.Marshal(kmsEnvelope{
+		WrappedKey: wrappedKey,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, []byte(plaintext), nil),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal envelope")
+	}
+
+	return SchemeKMS + "://" + kmsTypeAWS + "#" + base64.RawURLEncoding.EncodeToString(envelope), nil
+}
+
+func (p *KMSProvider) Resolve(ctx context.Context, ref Reference) (string, error) {
+	if ref.Path != kmsTypeAWS {
+		return "", errors.Errorf("unsupported kms %q", ref.Path)
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(ref.Key)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode envelope")
+	}
+	envelope := kmsEnvelope{}
+	if err := json.Unmarshal(b, &envelope); err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal envelope")
+	}
+
+	client, err := p.getClient()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get kms client")
+	}
+
+	dataKey, err := client.Decrypt(ctx, envelope.WrappedKey)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to unwrap data key")
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create cipher")
+	}
+
+	plaintext, err := gcm.Open(nil, envelope.Nonce, envelope.Ciphertext, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decrypt")
+	}
+
+	return string(plaintext), nil
+}
+
+func (p *KMSProvider) getClient() (KMSClient, error) {
+	p.clientMtx.Lock()
+	defer p.clientMtx.Unlock()
+
+	if p.Client == nil {
+		sess, err := session.NewSession()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create aws session")
+		}
+		p.Client = &awsKMSClient{client: kms.New(sess)}
+	}
+
+	return p.Client, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create aes cipher")
+	}
+	return cipher.NewGCM(block)
+}
+
+type awsKMSClient struct {
+	client *kms.KMS
+}
+
+func (c *awsKMSClient) GenerateDataKey(ctx context.Context, keyID string) ([]byte, []byte, error) {
+	out, err := c.client.GenerateDataKeyWithContext(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(keyID),
+		KeySpec: aws.String(kms.DataKeySpecAes256),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return out.Plaintext, out.CiphertextBlob, nil
+}
+
+func (c *awsKMSClient) Decrypt(ctx context.Context, wrappedKey []byte) ([]byte, error) {
+	out, err := c.client.DecryptWithContext(ctx, &kms.DecryptInput{
+		CiphertextBlob: wrappedKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
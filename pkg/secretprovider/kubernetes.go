@@ -0,0 +1,70 @@
+package secretprovider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// KubernetesProvider reads keys of Kubernetes secrets. Secrets without a namespace are read from kotsadm's namespace.
+// Only secrets in kotsadm's namespace, the app namespace and AllowedNamespaces can be read, so that config values
+// can't be used to copy secrets from other namespaces, e.g. kube-system, into the rendered manifests.
+type KubernetesProvider struct {
+	// Clientset defaults to the clientset of the cluster kotsadm runs in
+	Clientset kubernetes.Interface
+	// AllowedNamespaces are the namespaces that secrets can be read from in addition to kotsadm's and the app's namespace
+	AllowedNamespaces []string
+}
+
+func (p *KubernetesProvider) Resolve(ctx context.Context, ref Reference) (string, error) {
+	namespace, name := util.PodNamespace, ref.Path
+	if parts := strings.Split(ref.Path, "/"); len(parts) == 2 {
+		namespace, name = parts[0], parts[1]
+	} else if len(parts) > 2 {
+		return "", errors.Errorf("kubernetes secret reference %q must be a name or a namespace and a name", ref.Path)
+	}
+	if !p.isAllowedNamespace(namespace) {
+		return "", errors.Errorf("secrets in namespace %q can't be referenced", namespace)
+	}
+
+	clientset := p.Clientset
+	if clientset == nil {
+		var err error
+		clientset, err = k8sutil.GetClientset()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get clientset")
+		}
+	}
+
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get secret %s/%s", namespace, name)
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", errors.Errorf("key %q not found in secret %s/%s", ref.Key, namespace, name)
+	}
+
+	return string(value), nil
+}
+
+func (p *KubernetesProvider) isAllowedNamespace(namespace string) bool {
+	if namespace == "" {
+		return false
+	}
+	if namespace == util.PodNamespace || namespace == util.AppNamespace() {
+		return true
+	}
+	for _, allowed := range p.AllowedNamespaces {
+		if namespace == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package secretprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/crypto"
+)
+
+const (
+	// SchemeVault references a key of a HashiCorp Vault KV v2 secret: vault://<mount>/<path>#<key>
+	SchemeVault = "vault"
+	// SchemeKubernetesSecret references a key of a Kubernetes secret: k8s-secret://[<namespace>/]<name>#<key>
+	// The namespace must be kotsadm's namespace, the app namespace or listed in KOTSADM_SECRET_REFERENCE_NAMESPACES.
+	SchemeKubernetesSecret = "k8s-secret"
+	// SchemeKMS holds a value encrypted with a data key that's wrapped by a KMS: kms://aws#<envelope>
+	SchemeKMS = "kms"
+)
+
+// Reference points to a secret value that's stored outside of kotsadm, e.g. vault://secret/myapp#password
+type Reference struct {
+	Scheme string
+	// Path is everything between the scheme and the key, without leading or trailing slashes
+	Path string
+	Key  string
+	// Query holds provider specific options, e.g. the version of a vault secret
+	Query url.Values
+}
+
+// Provider resolves the references of one scheme
+type Provider interface {
+	Resolve(ctx context.Context, ref Reference) (string, error)
+}
+
+var (
+	providers       = map[string]Provider{}
+	providersMtx    sync.Mutex
+	initProvidersFn sync.Once
+)
+
+// RegisterProvider sets the provider that resolves the references of a scheme, replacing the default provider
+func RegisterProvider(scheme string, provider Provider) {
+	initProviders()
+
+	providersMtx.Lock()
+	defer providersMtx.Unlock()
+	providers[scheme] = provider
+}
+
+func getProvider(scheme string) Provider {
+	initProviders()
+
+	providersMtx.Lock()
+	defer providersMtx.Unlock()
+	return providers[scheme]
+}
+
+// initProviders registers the default providers. Vault is only available if VAULT_ADDR is set.
+func initProviders() {
+	initProvidersFn.Do(func() {
+		providersMtx.Lock()
+		defer providersMtx.Unlock()
+
+		providers[SchemeKubernetesSecret] = &KubernetesProvider{
+			AllowedNamespaces: splitList(os.Getenv("KOTSADM_SECRET_REFERENCE_NAMESPACES")),
+		}
+		providers[SchemeKMS] = &KMSProvider{
+			KeyID: os.Getenv("KOTSADM_KMS_KEY_ID"),
+		}
+		if address := os.Getenv("VAULT_ADDR"); address != "" {
+			providers[SchemeVault] = &VaultProvider{
+				Address:            address,
+				Token:              os.Getenv("VAULT_TOKEN"),
+				Namespace:          os.Getenv("VAULT_NAMESPACE"),
+				KubernetesRole:     os.Getenv("VAULT_K8S_ROLE"),
+				KubernetesAuthPath: os.Getenv("VAULT_K8S_AUTH_PATH"),
+			}
+		}
+	})
+}
+
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// IsReference returns true if the value is a secret reference rather than a secret
+func IsReference(value string) bool {
+	for _, scheme := range []string{SchemeVault, SchemeKubernetesSecret, SchemeKMS} {
+		if strings.HasPrefix(value, scheme+"://") {
+			return true
+		}
+	}
+	return false
+}
+
+func ParseReference(value string) (*Reference, error) {
+	if !IsReference(value) {
+		return nil, errors.New("not a secret reference")
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse url")
+	}
+
+	ref := &Reference{
+		Scheme: u.Scheme,
+		Path:   strings.Trim(u.Host+u.Path, "/"),
+		Key:    u.Fragment,
+		Query:  u.Query(),
+	}
+	if ref.Path == "" {
+		return nil, errors.New("path is required")
+	}
+	if ref.Key == "" {
+		return nil, errors.New("key is required")
+	}
+
+	return ref, nil
+}
+
+// Resolve returns the secret that a reference points to
+func Resolve(ctx context.Context, value string) (string, error) {
+	ref, err := ParseReference(value)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse reference")
+	}
+
+	provider := getProvider(ref.Scheme)
+	if provider == nil {
+		return "", errors.Errorf("no secret provider is configured for %s references", ref.Scheme)
+	}
+
+	secret, err := provider.Resolve(ctx, *ref)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve %s reference", ref.Scheme)
+	}
+
+	return secret, nil
+}
+
+// EncryptConfigValue returns what's stored in the app archive for the value of a password config item.
+// References are stored as they are. Other values are encrypted with a KMS data key if KOTSADM_KMS_KEY_ID
+// is set, and with the kotsadm encryption key otherwise.
+func EncryptConfigValue(ctx context.Context, plaintext string) (string, error) {
+	if IsReference(plaintext) {
+		return plaintext, nil
+	}
+
+	if kmsProvider, ok := getProvider(SchemeKMS).(*KMSProvider); ok && kmsProvider.KeyID != "" {
+		ref, err := kmsProvider.Encrypt(ctx, plaintext)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to encrypt with kms")
+		}
+		return ref, nil
+	}
+
+	return base64.StdEncoding.EncodeToString(crypto.Encrypt([]byte(plaintext))), nil
+}
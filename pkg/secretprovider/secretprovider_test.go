@@ -0,0 +1,247 @@
+package secretprovider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    *Reference
+		wantErr bool
+	}{
+		{
+			name:  "vault",
+			value: "vault://secret/myapp/db#password",
+			want:  &Reference{Scheme: SchemeVault, Path: "secret/myapp/db", Key: "password", Query: url.Values{}},
+		},
+		{
+			name:  "vault with version",
+			value: "vault://secret/myapp/db?version=2#password",
+			want:  &Reference{Scheme: SchemeVault, Path: "secret/myapp/db", Key: "password", Query: url.Values{"version": []string{"2"}}},
+		},
+		{
+			name:  "kubernetes secret in another namespace",
+			value: "k8s-secret://myapp/db-credentials#password",
+			want:  &Reference{Scheme: SchemeKubernetesSecret, Path: "myapp/db-credentials", Key: "password", Query: url.Values{}},
+		},
+		{
+			name:    "missing key",
+			value:   "vault://secret/myapp/db",
+			wantErr: true,
+		},
+		{
+			name:    "missing path",
+			value:   "k8s-secret://#password",
+			wantErr: true,
+		},
+		{
+			name:    "not a reference",
+			value:   "hunter2",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseReference(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// newVaultDevServer is a stand-in for a vault server in dev mode with a KV v2 engine mounted at "secret"
+func newVaultDevServer(t *testing.T, rootToken string, secrets map[string]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/auth/kubernetes/login" {
+			login := map[string]string{}
+			if err := json.NewDecoder(r.Body).Decode(&login); err != nil || login["role"] != "kotsadm" || login["jwt"] != "sa-token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"auth": map[string]interface{}{"client_token": rootToken},
+			})
+			return
+		}
+
+		if r.Header.Get("X-Vault-Token") != rootToken {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		secretPath := strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")
+		if version := r.URL.Query().Get("version"); version != "" {
+			secretPath += "@" + version
+		}
+		data, ok := secrets[secretPath]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"data": data},
+		})
+	}))
+}
+
+func TestVaultProvider_Resolve(t *testing.T) {
+	server := newVaultDevServer(t, "root", map[string]map[string]interface{}{
+		"myapp/db":   {"password": "s3cret", "port": 5432},
+		"myapp/db@1": {"password": "old-s3cret"},
+	})
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("sa-token\n"), 0600))
+
+	tests := []struct {
+		name     string
+		provider *VaultProvider
+		value    string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "token",
+			provider: &VaultProvider{Address: server.URL, Token: "root"},
+			value:    "vault://secret/myapp/db#password",
+			want:     "s3cret",
+		},
+		{
+			name:     "version",
+			provider: &VaultProvider{Address: server.URL, Token: "root"},
+			value:    "vault://secret/myapp/db?version=1#password",
+			want:     "old-s3cret",
+		},
+		{
+			name:     "non string value",
+			provider: &VaultProvider{Address: server.URL, Token: "root"},
+			value:    "vault://secret/myapp/db#port",
+			want:     "5432",
+		},
+		{
+			name:     "kubernetes auth",
+			provider: &VaultProvider{Address: server.URL, KubernetesRole: "kotsadm", ServiceAccountTokenPath: tokenFile},
+			value:    "vault://secret/myapp/db#password",
+			want:     "s3cret",
+		},
+		{
+			name:     "missing key",
+			provider: &VaultProvider{Address: server.URL, Token: "root"},
+			value:    "vault://secret/myapp/db#username",
+			wantErr:  true,
+		},
+		{
+			name:     "missing secret",
+			provider: &VaultProvider{Address: server.URL, Token: "root"},
+			value:    "vault://secret/otherapp#password",
+			wantErr:  true,
+		},
+		{
+			name:     "bad token",
+			provider: &VaultProvider{Address: server.URL, Token: "not-root"},
+			value:    "vault://secret/myapp/db#password",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := ParseReference(tt.value)
+			require.NoError(t, err)
+
+			got, err := tt.provider.Resolve(context.Background(), *ref)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestKubernetesProvider_Resolve(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-credentials", Namespace: "myapp"},
+		Data:       map[string][]byte{"password": []byte("s3cret")},
+	})
+	provider := &KubernetesProvider{Clientset: clientset, AllowedNamespaces: []string{"myapp"}}
+
+	ref, err := ParseReference("k8s-secret://myapp/db-credentials#password")
+	require.NoError(t, err)
+	got, err := provider.Resolve(context.Background(), *ref)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", got)
+
+	ref, err = ParseReference("k8s-secret://myapp/db-credentials#username")
+	require.NoError(t, err)
+	_, err = provider.Resolve(context.Background(), *ref)
+	assert.Error(t, err)
+
+	// namespaces that are not allowed are rejected even if the secret exists
+	provider = &KubernetesProvider{Clientset: clientset}
+	ref, err = ParseReference("k8s-secret://myapp/db-credentials#password")
+	require.NoError(t, err)
+	_, err = provider.Resolve(context.Background(), *ref)
+	assert.ErrorContains(t, err, `secrets in namespace "myapp" can't be referenced`)
+}
+
+// fakeKMSClient wraps data keys by reversing them
+type fakeKMSClient struct{}
+
+func (c *fakeKMSClient) GenerateDataKey(ctx context.Context, keyID string) ([]byte, []byte, error) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	return key, reverse(key), nil
+}
+
+func (c *fakeKMSClient) Decrypt(ctx context.Context, wrappedKey []byte) ([]byte, error) {
+	return reverse(wrappedKey), nil
+}
+
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+func TestKMSProvider_RoundTrip(t *testing.T) {
+	provider := &KMSProvider{KeyID: "alias/kotsadm", Client: &fakeKMSClient{}}
+
+	value, err := provider.Encrypt(context.Background(), "s3cret")
+	require.NoError(t, err)
+	assert.True(t, IsReference(value))
+	assert.NotContains(t, value, "s3cret")
+
+	ref, err := ParseReference(value)
+	require.NoError(t, err)
+	got, err := provider.Resolve(context.Background(), *ref)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", got)
+
+	// every value has its own nonce
+	other, err := provider.Encrypt(context.Background(), "s3cret")
+	require.NoError(t, err)
+	assert.NotEqual(t, value, other)
+}
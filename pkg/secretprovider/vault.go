@@ -0,0 +1,201 @@
+package secretprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultVaultKubernetesAuthPath = "kubernetes"
+	serviceAccountTokenPath        = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// VaultProvider reads secrets from the KV v2 secrets engine of HashiCorp Vault.
+// It authenticates with Token if it's set, and logs in with the kubernetes auth method and KubernetesRole otherwise.
+type VaultProvider struct {
+	Address   string
+	Token     string
+	Namespace string
+	// KubernetesRole is the vault role that kotsadm's service account logs in as
+	KubernetesRole string
+	// KubernetesAuthPath is where the kubernetes auth method is mounted, defaults to "kubernetes"
+	KubernetesAuthPath string
+	// ServiceAccountTokenPath defaults to the token of the pod's service account
+	ServiceAccountTokenPath string
+	HTTPClient              *http.Client
+
+	loginToken string
+	loginMtx   sync.Mutex
+}
+
+type vaultKVv2Response struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+type vaultLoginResponse struct {
+	Auth struct {
+		ClientToken string `json:"client_token"`
+	} `json:"auth"`
+}
+
+func (p *VaultProvider) Resolve(ctx context.Context, ref Reference) (string, error) {
+	mount, secretPath, ok := strings.Cut(ref.Path, "/")
+	if !ok || secretPath == "" {
+		return "", errors.Errorf("vault reference %q must include the mount and the path of the secret", ref.Path)
+	}
+
+	secretURL := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimSuffix(p.Address, "/"), mount, secretPath)
+	if version := ref.Query.Get("version"); version != "" {
+		secretURL += "?version=" + url.QueryEscape(version)
+	}
+
+	body, status, err := p.readSecret(ctx, secretURL, false)
+	if err != nil {
+		return "", err
+	}
+	if status == http.StatusForbidden && p.Token == "" {
+		// the login token may have expired
+		body, status, err = p.readSecret(ctx, secretURL, true)
+		if err != nil {
+			return "", err
+		}
+	}
+	if status != http.StatusOK {
+		return "", errors.Errorf("unexpected status code %d reading %s/%s", status, mount, secretPath)
+	}
+
+	response := vaultKVv2Response{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal secret")
+	}
+
+	value, ok := response.Data.Data[ref.Key]
+	if !ok {
+		return "", errors.Errorf("key %q not found in secret %s/%s", ref.Key, mount, secretPath)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal value")
+	}
+	return string(b), nil
+}
+
+func (p *VaultProvider) readSecret(ctx context.Context, secretURL string, forceLogin bool) ([]byte, int, error) {
+	token, err := p.getToken(ctx, forceLogin)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to get vault token")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, secretURL, nil)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if p.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.Namespace)
+	}
+
+	resp, err := p.httpClient().Do(req)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to read secret")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to read response body")
+	}
+
+	return body, resp.StatusCode, nil
+}
+
+func (p *VaultProvider) getToken(ctx context.Context, forceLogin bool) (string, error) {
+	if p.Token != "" {
+		return p.Token, nil
+	}
+	if p.KubernetesRole == "" {
+		return "", errors.New("either a vault token or a kubernetes role is required")
+	}
+
+	p.loginMtx.Lock()
+	defer p.loginMtx.Unlock()
+
+	if p.loginToken != "" && !forceLogin {
+		return p.loginToken, nil
+	}
+
+	tokenPath := p.ServiceAccountTokenPath
+	if tokenPath == "" {
+		tokenPath = serviceAccountTokenPath
+	}
+	jwt, err := os.ReadFile(tokenPath)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read service account token")
+	}
+
+	authPath := p.KubernetesAuthPath
+	if authPath == "" {
+		authPath = defaultVaultKubernetesAuthPath
+	}
+
+	reqBody, err := json.Marshal(map[string]string{
+		"role": p.KubernetesRole,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal login request")
+	}
+
+	loginURL := fmt.Sprintf("%s/v1/auth/%s/login", strings.TrimSuffix(p.Address, "/"), strings.Trim(authPath, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, loginURL, bytes.NewReader(reqBody))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create login request")
+	}
+	if p.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.Namespace)
+	}
+
+	resp, err := p.httpClient().Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to login")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("unexpected status code %d logging in as role %s", resp.StatusCode, p.KubernetesRole)
+	}
+
+	response := vaultLoginResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", errors.Wrap(err, "failed to decode login response")
+	}
+	if response.Auth.ClientToken == "" {
+		return "", errors.New("login response did not include a token")
+	}
+
+	p.loginToken = response.Auth.ClientToken
+	return p.loginToken, nil
+}
+
+func (p *VaultProvider) httpClient() *http.Client {
+	if p.HTTPClient != nil {
+		return p.HTTPClient
+	}
+	return http.DefaultClient
+}
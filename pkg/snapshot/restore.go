@@ -31,6 +31,11 @@ type RestoreInstanceBackupOptions struct {
 	WaitForApps         bool
 	VeleroNamespace     string
 	Silent              bool
+	// AppSlug, NamespaceMapping and CloneAppSlug restore only one app into other namespaces, next to the running app.
+	// The admin console and the running app are left as they are.
+	AppSlug          string
+	NamespaceMapping map[string]string
+	CloneAppSlug     string
 }
 
 type ListInstanceRestoresOptions struct {
@@ -101,6 +106,10 @@ func RestoreInstanceBackup(ctx context.Context, options RestoreInstanceBackupOpt
 		log.Silence()
 	}
 
+	if len(options.NamespaceMapping) > 0 {
+		return restoreApplicationClone(ctx, options, veleroNamespace, kotsadmNamespace, log)
+	}
+
 	if !options.ExcludeAdminConsole {
 		log.ActionWithSpinner("Deleting Admin Console")
 
@@ -251,6 +260,56 @@ func RestoreInstanceBackup(ctx context.Context, options RestoreInstanceBackupOpt
 	return nil
 }
 
+// restoreApplicationClone asks kotsadm to restore an app from the backup into other namespaces under a new app slug
+func restoreApplicationClone(ctx context.Context, options RestoreInstanceBackupOptions, veleroNamespace string, kotsadmNamespace string, log *logger.CLILogger) error {
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get k8s clientset")
+	}
+
+	log.ActionWithSpinner("Restoring Application %s as %s", options.AppSlug, options.CloneAppSlug)
+
+	// the admin console is not restored, so it must already be running
+	timeout, err := time.ParseDuration("10m")
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to parse timeout value")
+	}
+	kotsadmPodName, err := k8sutil.WaitForKotsadm(clientset, kotsadmNamespace, timeout)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to wait for kotsadm")
+	}
+
+	restoreName, err := initiateKotsadmApplicationCloneRestore(options, kotsadmNamespace, kotsadmPodName, log)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to restore application")
+	}
+
+	if !options.WaitForApps {
+		log.FinishSpinner()
+		log.ActionWithoutSpinner("Application restore initiated successfully but is still in progress.")
+		return nil
+	}
+
+	restore, err := waitForVeleroRestoreCompleted(ctx, veleroNamespace, restoreName)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		if restore != nil {
+			errMsg := fmt.Sprintf("Application restore failed with %d errors and %d warnings.", restore.Status.Errors, restore.Status.Warnings)
+			log.ActionWithoutSpinner(errMsg)
+			return errors.Wrap(err, errMsg)
+		}
+		return errors.Wrap(err, "failed to wait for velero restore completed")
+	}
+
+	log.FinishSpinner()
+	log.ActionWithoutSpinner("Application restored successfully as %s.", options.CloneAppSlug)
+
+	return nil
+}
+
 func ListInstanceRestores(ctx context.Context, options ListInstanceRestoresOptions) ([]velerov1.Restore, error) {
 	clientset, err := k8sutil.GetClientset()
 	if err != nil {
@@ -390,6 +449,90 @@ func initiateKotsadmApplicationsRestore(backupName string, kotsadmNamespace stri
 	return nil
 }
 
+// initiateKotsadmApplicationCloneRestore creates the clone restore through kotsadm and returns the name of the velero restore
+func initiateKotsadmApplicationCloneRestore(options RestoreInstanceBackupOptions, kotsadmNamespace string, kotsadmPodName string, log *logger.CLILogger) (string, error) {
+	getPodName := func() (string, error) {
+		return kotsadmPodName, nil
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	localPort, errChan, err := k8sutil.PortForward(0, 3000, kotsadmNamespace, getPodName, false, stopCh, log)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to start port forwarding")
+	}
+
+	go func() {
+		select {
+		case err := <-errChan:
+			if err != nil {
+				log.Error(err)
+			}
+		case <-stopCh:
+		}
+	}()
+
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get k8s clientset")
+	}
+
+	authSlug, err := auth.GetOrCreateAuthSlug(clientset, kotsadmNamespace)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get kotsadm auth slug")
+	}
+
+	url := fmt.Sprintf("http://localhost:%d/api/v1/app/%s/snapshot/restore/%s", localPort, options.AppSlug, options.BackupName)
+
+	requestPayload := map[string]interface{}{
+		"clone": snapshottypes.CloneRestoreOptions{
+			NamespaceMapping: options.NamespaceMapping,
+			AppSlug:          options.CloneAppSlug,
+		},
+	}
+	requestBody, err := json.Marshal(requestPayload)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal request json")
+	}
+
+	newRequest, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create request")
+	}
+	newRequest.Header.Add("Authorization", authSlug)
+
+	resp, err := http.DefaultClient.Do(newRequest)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to post to kotsadm")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read server response")
+	}
+
+	type CreateApplicationRestoreResponse struct {
+		Success     bool   `json:"success"`
+		RestoreName string `json:"restoreName,omitempty"`
+		Error       string `json:"error,omitempty"`
+	}
+	var createRestoreResponse CreateApplicationRestoreResponse
+	if err := json.Unmarshal(respBody, &createRestoreResponse); err != nil {
+		return "", errors.Wrapf(err, "failed to unmarshal response with status %s", resp.Status)
+	}
+
+	if resp.StatusCode != http.StatusOK || !createRestoreResponse.Success {
+		if createRestoreResponse.Error != "" {
+			return "", errors.New(createRestoreResponse.Error)
+		}
+		return "", errors.Errorf("unexpected status code from %s: %s", url, resp.Status)
+	}
+
+	return createRestoreResponse.RestoreName, nil
+}
+
 func waitForKotsadmApplicationsRestore(backupName string, kotsadmNamespace string, kotsadmPodName string, log *logger.CLILogger) error {
 	getPodName := func() (string, error) {
 		return kotsadmPodName, nil
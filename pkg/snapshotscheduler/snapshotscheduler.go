@@ -8,11 +8,14 @@ import (
 	"github.com/pkg/errors"
 	downstreamtypes "github.com/replicatedhq/kots/pkg/api/downstream/types"
 	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	"github.com/replicatedhq/kots/pkg/cluster"
 	snapshot "github.com/replicatedhq/kots/pkg/kotsadmsnapshot"
 	snapshottypes "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/preflight"
 	"github.com/replicatedhq/kots/pkg/store"
 	"github.com/replicatedhq/kots/pkg/util"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
 
 	cron "github.com/robfig/cron/v3"
@@ -24,6 +27,14 @@ func Start() error {
 	startLoop(appScheduleLoop, 60)
 	startLoop(instanceScheduleLoop, 60)
 
+	go func() {
+		// no verification runs yet, so any that is recorded as running was interrupted by a restart
+		if err := snapshot.CleanupInterruptedBackupVerifications(context.Background(), util.PodNamespace); err != nil {
+			logger.Error(errors.Wrap(err, "failed to clean up interrupted backup verifications"))
+		}
+		startLoop(verificationScheduleLoop, 60)
+	}()
+
 	return nil
 }
 
@@ -61,6 +72,10 @@ func instanceScheduleLoop() {
 	}
 
 	for _, c := range clusters {
+		if cluster.IsRemote(c) {
+			// instance snapshots are taken with the velero installation of the cluster kotsadm runs in
+			continue
+		}
 		if err := handleCluster(c); err != nil {
 			logger.Error(errors.Wrapf(err, "failed to handle scheduled instance snapshots for cluster %s", c.ClusterID))
 		}
@@ -233,6 +248,141 @@ func handleCluster(c *downstreamtypes.Downstream) error {
 	return nil
 }
 
+/* Scheduled Instance Snapshot Verifications */
+func verificationScheduleLoop() {
+	clusters, err := store.GetStore().ListClusters()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list clusters for scheduled snapshot verifications"))
+		return
+	}
+
+	for _, c := range clusters {
+		if cluster.IsRemote(c) {
+			// instance snapshots are only taken of the cluster kotsadm runs in
+			continue
+		}
+		if err := handleClusterVerification(c); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to handle scheduled snapshot verification for cluster %s", c.ClusterID))
+		}
+	}
+}
+
+func handleClusterVerification(c *downstreamtypes.Downstream) error {
+	policy, err := store.GetStore().GetInstanceSnapshotVerificationPolicy(c.ClusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get snapshot verification policy")
+	}
+	if policy.Schedule == "" {
+		return nil
+	}
+
+	backups, err := snapshot.ListInstanceBackups(context.Background(), util.PodNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list instance backups")
+	}
+
+	backup, err := nextBackupToVerify(backups, policy.Schedule, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "failed to get next backup to verify")
+	}
+	if backup == nil {
+		logger.Debugf("Not yet time to verify a snapshot of cluster %s", c.ClusterID)
+		return nil
+	}
+
+	logger.Infof("Verifying instance backup %s from scheduled snapshot verification", backup.Name)
+
+	verification, err := VerifyInstanceBackup(context.Background(), backup.Name, *policy)
+	if err != nil {
+		if errors.Cause(err) == snapshot.ErrBackupVerificationInProgress {
+			logger.Infof("Postponing scheduled verification of backup %s because a verification is in progress", backup.Name)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to verify backup %s", backup.Name)
+	}
+	logger.Infof("Verification of instance backup %s %s", backup.Name, verification.Status)
+
+	return nil
+}
+
+// nextBackupToVerify returns the latest completed backup that was never verified if the schedule is due, or nil if there is nothing to verify yet.
+// The schedule is due after the last verification started, or after the backup completed if none ever did.
+func nextBackupToVerify(backups []*snapshottypes.Backup, cronExpression string, now time.Time) (*snapshottypes.Backup, error) {
+	cronSchedule, err := cron.ParseStandard(cronExpression)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse cron expression")
+	}
+
+	var lastVerification time.Time
+	var latest *snapshottypes.Backup
+	for _, backup := range backups {
+		if backup.Verification != nil {
+			if backup.Verification.StartedAt != nil && backup.Verification.StartedAt.After(lastVerification) {
+				lastVerification = *backup.Verification.StartedAt
+			}
+			continue
+		}
+		if backup.Status != string(velerov1.BackupPhaseCompleted) || backup.FinishedAt == nil {
+			continue
+		}
+		if latest == nil || backup.FinishedAt.After(*latest.FinishedAt) {
+			latest = backup
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+
+	after := lastVerification
+	if after.IsZero() {
+		after = *latest.FinishedAt
+	}
+	if cronSchedule.Next(after).After(now) {
+		return nil, nil
+	}
+
+	return latest, nil
+}
+
+// ValidateVerificationPolicy returns an error if the schedule or the timeout of the policy can't be parsed
+func ValidateVerificationPolicy(policy snapshottypes.BackupVerificationPolicy) error {
+	if policy.Schedule != "" {
+		if _, err := cron.ParseStandard(policy.Schedule); err != nil {
+			return errors.Wrap(err, "invalid schedule")
+		}
+	}
+
+	if policy.Timeout != "" {
+		timeout, err := time.ParseDuration(policy.Timeout)
+		if err != nil {
+			return errors.Wrap(err, "invalid timeout")
+		}
+		if timeout <= 0 {
+			return errors.New("timeout must be positive")
+		}
+	}
+
+	return nil
+}
+
+// VerifyInstanceBackup restores the apps of an instance backup into scratch namespaces with the options of a verification policy and records the result on the backup
+func VerifyInstanceBackup(ctx context.Context, backupName string, policy snapshottypes.BackupVerificationPolicy) (*snapshottypes.BackupVerification, error) {
+	if err := ValidateVerificationPolicy(policy); err != nil {
+		return nil, errors.Wrap(err, "invalid verification policy")
+	}
+
+	opts := snapshot.VerifyBackupOptions{}
+	if policy.Timeout != "" {
+		// the timeout was validated above
+		opts.Timeout, _ = time.ParseDuration(policy.Timeout)
+	}
+	if policy.RunAnalyzers {
+		opts.Analyze = preflight.Analyze
+	}
+
+	return snapshot.VerifyInstanceBackup(ctx, util.PodNamespace, backupName, opts)
+}
+
 func nextScheduledApplicationSnapshot(appID string, cronExpression string) (*snapshottypes.ScheduledSnapshot, error) {
 	cronSchedule, err := cron.ParseStandard(cronExpression)
 	if err != nil {
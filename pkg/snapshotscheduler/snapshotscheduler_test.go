@@ -0,0 +1,121 @@
+package snapshotscheduler
+
+import (
+	"testing"
+	"time"
+
+	snapshottypes "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_nextBackupToVerify(t *testing.T) {
+	// daily at midnight
+	schedule := "0 0 * * *"
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)
+	at := func(hours int) *time.Time {
+		t := day.Add(time.Duration(hours) * time.Hour)
+		return &t
+	}
+
+	completed := func(name string, finishedAt *time.Time) *snapshottypes.Backup {
+		return &snapshottypes.Backup{Name: name, Status: "Completed", FinishedAt: finishedAt}
+	}
+	verified := func(name string, finishedAt *time.Time, startedAt *time.Time) *snapshottypes.Backup {
+		b := completed(name, finishedAt)
+		b.Verification = &snapshottypes.BackupVerification{Status: snapshottypes.BackupVerificationPassed, StartedAt: startedAt}
+		return b
+	}
+
+	tests := []struct {
+		name    string
+		backups []*snapshottypes.Backup
+		now     time.Time
+		want    string
+	}{
+		{
+			name: "no backups",
+			now:  *at(48),
+		},
+		{
+			name:    "never verified and due",
+			backups: []*snapshottypes.Backup{completed("a", at(1)), completed("b", at(2))},
+			now:     *at(25),
+			want:    "b",
+		},
+		{
+			name:    "never verified and not due",
+			backups: []*snapshottypes.Backup{completed("a", at(1))},
+			now:     *at(23),
+		},
+		{
+			name:    "verified since the last run",
+			backups: []*snapshottypes.Backup{verified("a", at(1), at(24)), completed("b", at(30))},
+			now:     *at(40),
+		},
+		{
+			name:    "due after the last verification",
+			backups: []*snapshottypes.Backup{verified("a", at(1), at(24)), completed("b", at(30))},
+			now:     *at(48),
+			want:    "b",
+		},
+		{
+			name: "only unfinished and failed backups",
+			backups: []*snapshottypes.Backup{
+				verified("a", at(1), at(24)),
+				{Name: "b", Status: "InProgress"},
+				{Name: "c", Status: "PartiallyFailed", FinishedAt: at(30)},
+			},
+			now: *at(72),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nextBackupToVerify(tt.backups, schedule, tt.now)
+			require.NoError(t, err)
+			if tt.want == "" {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, tt.want, got.Name)
+		})
+	}
+}
+
+func TestValidateVerificationPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  snapshottypes.BackupVerificationPolicy
+		wantErr bool
+	}{
+		{
+			name:   "disabled",
+			policy: snapshottypes.BackupVerificationPolicy{},
+		},
+		{
+			name:   "valid",
+			policy: snapshottypes.BackupVerificationPolicy{Schedule: "0 0 * * 0", RunAnalyzers: true, Timeout: "45m"},
+		},
+		{
+			name:    "invalid schedule",
+			policy:  snapshottypes.BackupVerificationPolicy{Schedule: "weekly"},
+			wantErr: true,
+		},
+		{
+			name:    "negative timeout",
+			policy:  snapshottypes.BackupVerificationPolicy{Timeout: "-1m"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateVerificationPolicy(tt.policy)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
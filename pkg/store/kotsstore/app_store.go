@@ -371,7 +371,8 @@ func (s *KOTSStore) CreateApp(name string, selectedChannelID string, upstreamURI
 
 func (s *KOTSStore) ListDownstreamsForApp(appID string) ([]downstreamtypes.Downstream, error) {
 	db := persistence.MustGetDBSession()
-	query := `select c.id from app_downstream d inner join cluster c on d.cluster_id = c.id where app_id = ?`
+	query := `select c.id from app_downstream d inner join cluster c on d.cluster_id = c.id where app_id = ?
+	order by case when c.connection_type = 'local' or c.connection_type is null then 0 else 1 end, c.created_at`
 	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID},
@@ -427,7 +428,7 @@ func (s *KOTSStore) ListAppsForDownstream(clusterID string) ([]*apptypes.App, er
 
 func (s *KOTSStore) GetDownstream(clusterID string) (*downstreamtypes.Downstream, error) {
 	db := persistence.MustGetDBSession()
-	query := `select c.id, c.slug, d.downstream_name, d.current_sequence from app_downstream d inner join cluster c on d.cluster_id = c.id where c.id = ?`
+	query := `select c.id, c.slug, d.downstream_name, d.current_sequence, c.connection_type, c.target_namespace from app_downstream d inner join cluster c on d.cluster_id = c.id where c.id = ?`
 	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{clusterID},
@@ -443,12 +444,19 @@ func (s *KOTSStore) GetDownstream(clusterID string) (*downstreamtypes.Downstream
 		CurrentSequence: -1,
 	}
 	var sequence gorqlite.NullInt64
-	if err := rows.Scan(&downstream.ClusterID, &downstream.ClusterSlug, &downstream.Name, &sequence); err != nil {
+	var connectionType gorqlite.NullString
+	var targetNamespace gorqlite.NullString
+	if err := rows.Scan(&downstream.ClusterID, &downstream.ClusterSlug, &downstream.Name, &sequence, &connectionType, &targetNamespace); err != nil {
 		return nil, errors.Wrap(err, "failed to scan downstream")
 	}
 	if sequence.Valid {
 		downstream.CurrentSequence = sequence.Int64
 	}
+	downstream.ConnectionType = connectionType.String
+	if downstream.ConnectionType == "" {
+		downstream.ConnectionType = "local"
+	}
+	downstream.TargetNamespace = targetNamespace.String
 
 	return &downstream, nil
 }
@@ -554,6 +562,36 @@ func (s *KOTSStore) RemoveApp(appID string) error {
 		Arguments: []interface{}{appID},
 	})
 
+	statements = append(statements, gorqlite.ParameterizedStatement{
+		Query:     "delete from app_downstream_status where app_id = ?",
+		Arguments: []interface{}{appID},
+	})
+
+	statements = append(statements, gorqlite.ParameterizedStatement{
+		Query:     "delete from app_status_history where app_id = ?",
+		Arguments: []interface{}{appID},
+	})
+
+	statements = append(statements, gorqlite.ParameterizedStatement{
+		Query:     "delete from app_drift_status where app_id = ?",
+		Arguments: []interface{}{appID},
+	})
+
+	statements = append(statements, gorqlite.ParameterizedStatement{
+		Query:     "delete from app_image_verification where app_id = ?",
+		Arguments: []interface{}{appID},
+	})
+
+	statements = append(statements, gorqlite.ParameterizedStatement{
+		Query:     "delete from app_promotion_stage where app_id = ?",
+		Arguments: []interface{}{appID},
+	})
+
+	statements = append(statements, gorqlite.ParameterizedStatement{
+		Query:     "delete from app_promotion where app_id = ?",
+		Arguments: []interface{}{appID},
+	})
+
 	statements = append(statements, gorqlite.ParameterizedStatement{
 		Query:     "delete from app_downstream_output where app_id = ?",
 		Arguments: []interface{}{appID},
@@ -3,17 +3,19 @@ package kotsstore
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
 	"github.com/replicatedhq/kots/pkg/persistence"
 	"github.com/rqlite/gorqlite"
+	"github.com/segmentio/ksuid"
 )
 
 func (s *KOTSStore) GetAppStatus(appID string) (*appstatetypes.AppStatus, error) {
 	db := persistence.MustGetDBSession()
-	query := `select resource_states, updated_at, sequence from app_status where app_id = ?`
+	query := `select resource_states, updated_at, sequence, ready_at from app_status where app_id = ?`
 	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID},
@@ -35,8 +37,9 @@ func (s *KOTSStore) GetAppStatus(appID string) (*appstatetypes.AppStatus, error)
 	var updatedAt gorqlite.NullTime
 	var resourceStatesStr gorqlite.NullString
 	var sequence gorqlite.NullInt64
+	var readyAt gorqlite.NullTime
 
-	if err := rows.Scan(&resourceStatesStr, &updatedAt, &sequence); err != nil {
+	if err := rows.Scan(&resourceStatesStr, &updatedAt, &sequence, &readyAt); err != nil {
 		return nil, errors.Wrap(err, "failed to scan")
 	}
 
@@ -48,6 +51,9 @@ func (s *KOTSStore) GetAppStatus(appID string) (*appstatetypes.AppStatus, error)
 	if updatedAt.Valid {
 		appStatus.UpdatedAt = updatedAt.Time
 	}
+	if readyAt.Valid {
+		appStatus.ReadyAt = &readyAt.Time
+	}
 
 	if resourceStatesStr.Valid {
 		var resourceStates appstatetypes.ResourceStates
@@ -69,20 +75,256 @@ func (s *KOTSStore) SetAppStatus(appID string, resourceStates appstatetypes.Reso
 	}
 
 	db := persistence.MustGetDBSession()
+	// ready_at is kept from the previous status while the same sequence stays ready
 	query := `
-	insert into app_status (app_id, resource_states, updated_at, sequence)
-	values (?, ?, ?, ?)
+	insert into app_status (app_id, resource_states, updated_at, sequence, ready_at)
+	values (?, ?, ?, ?, ?)
 	on conflict (app_id) do update set
 	  resource_states = EXCLUDED.resource_states,
 	  updated_at = EXCLUDED.updated_at,
-	  sequence = EXCLUDED.sequence`
-	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+	  sequence = EXCLUDED.sequence,
+	  ready_at = case
+	    when EXCLUDED.ready_at is not null and app_status.ready_at is not null and app_status.sequence = EXCLUDED.sequence then app_status.ready_at
+	    else EXCLUDED.ready_at
+	  end`
+	statements := []gorqlite.ParameterizedStatement{
+		{
+			Query:     query,
+			Arguments: []interface{}{appID, string(marshalledResourceStates), updatedAt.Unix(), sequence, readyAtArg(resourceStates, updatedAt)},
+		},
+	}
+
+	previous, err := s.GetAppStatus(appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get previous app status")
+	}
+	next := appstatetypes.AppStatus{AppID: appID, ResourceStates: resourceStates, UpdatedAt: updatedAt, Sequence: sequence}
+	statements = append(statements, statusTransitionStatements("", appstatetypes.GetStatusTransitions(*previous, next))...)
+
+	if wrs, err := db.WriteParameterized(statements); err != nil {
+		wrErrs := []error{}
+		for _, wr := range wrs {
+			wrErrs = append(wrErrs, wr.Err)
+		}
+		return fmt.Errorf("failed to write: %v: %v", err, wrErrs)
+	}
+
+	return nil
+}
+
+// GetDownstreamAppStatus returns the status of an app in a remote cluster
+func (s *KOTSStore) GetDownstreamAppStatus(appID string, clusterID string) (*appstatetypes.AppStatus, error) {
+	db := persistence.MustGetDBSession()
+	query := `select resource_states, updated_at, sequence, ready_at from app_downstream_status where app_id = ? and cluster_id = ?`
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
 		Query:     query,
-		Arguments: []interface{}{appID, string(marshalledResourceStates), updatedAt.Unix(), sequence},
+		Arguments: []interface{}{appID, clusterID},
 	})
 	if err != nil {
-		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	if !rows.Next() {
+		return &appstatetypes.AppStatus{
+			AppID:          appID,
+			UpdatedAt:      time.Time{},
+			ResourceStates: appstatetypes.ResourceStates{},
+			State:          appstatetypes.StateMissing,
+			Sequence:       0,
+		}, nil
+	}
+
+	var updatedAt gorqlite.NullTime
+	var resourceStatesStr gorqlite.NullString
+	var sequence gorqlite.NullInt64
+	var readyAt gorqlite.NullTime
+
+	if err := rows.Scan(&resourceStatesStr, &updatedAt, &sequence, &readyAt); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+
+	appStatus := appstatetypes.AppStatus{
+		AppID:    appID,
+		Sequence: sequence.Int64,
+	}
+
+	if updatedAt.Valid {
+		appStatus.UpdatedAt = updatedAt.Time
+	}
+	if readyAt.Valid {
+		appStatus.ReadyAt = &readyAt.Time
+	}
+
+	if resourceStatesStr.Valid {
+		var resourceStates appstatetypes.ResourceStates
+		if err := json.Unmarshal([]byte(resourceStatesStr.String), &resourceStates); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal resource states")
+		}
+		appStatus.ResourceStates = resourceStates
+	}
+
+	appStatus.State = appstatetypes.GetState(appStatus.ResourceStates)
+
+	return &appStatus, nil
+}
+
+// SetDownstreamAppStatus sets the status of an app in a remote cluster
+func (s *KOTSStore) SetDownstreamAppStatus(appID string, clusterID string, resourceStates appstatetypes.ResourceStates, updatedAt time.Time, sequence int64) error {
+	marshalledResourceStates, err := json.Marshal(resourceStates)
+	if err != nil {
+		return errors.Wrap(err, "failed to json marshal resource states")
+	}
+
+	db := persistence.MustGetDBSession()
+	query := `
+	insert into app_downstream_status (app_id, cluster_id, resource_states, updated_at, sequence, ready_at)
+	values (?, ?, ?, ?, ?, ?)
+	on conflict (app_id, cluster_id) do update set
+	  resource_states = EXCLUDED.resource_states,
+	  updated_at = EXCLUDED.updated_at,
+	  sequence = EXCLUDED.sequence,
+	  ready_at = case
+	    when EXCLUDED.ready_at is not null and app_downstream_status.ready_at is not null and app_downstream_status.sequence = EXCLUDED.sequence then app_downstream_status.ready_at
+	    else EXCLUDED.ready_at
+	  end`
+	statements := []gorqlite.ParameterizedStatement{
+		{
+			Query:     query,
+			Arguments: []interface{}{appID, clusterID, string(marshalledResourceStates), updatedAt.Unix(), sequence, readyAtArg(resourceStates, updatedAt)},
+		},
+	}
+
+	previous, err := s.GetDownstreamAppStatus(appID, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get previous downstream app status")
+	}
+	next := appstatetypes.AppStatus{AppID: appID, ResourceStates: resourceStates, UpdatedAt: updatedAt, Sequence: sequence}
+	statements = append(statements, statusTransitionStatements(clusterID, appstatetypes.GetStatusTransitions(*previous, next))...)
+
+	if wrs, err := db.WriteParameterized(statements); err != nil {
+		wrErrs := []error{}
+		for _, wr := range wrs {
+			wrErrs = append(wrErrs, wr.Err)
+		}
+		return fmt.Errorf("failed to write: %v: %v", err, wrErrs)
 	}
 
 	return nil
 }
+
+// readyAtArg returns the time the app became ready if the resource states are ready, and nil otherwise
+func readyAtArg(resourceStates appstatetypes.ResourceStates, updatedAt time.Time) interface{} {
+	if appstatetypes.GetState(resourceStates) != appstatetypes.StateReady {
+		return nil
+	}
+	return updatedAt.Unix()
+}
+
+// statusTransitionStatements returns the statements that record the transitions in the status history
+func statusTransitionStatements(clusterID string, transitions []appstatetypes.StatusTransition) []gorqlite.ParameterizedStatement {
+	statements := []gorqlite.ParameterizedStatement{}
+	for _, t := range transitions {
+		statements = append(statements, gorqlite.ParameterizedStatement{
+			Query: `insert into app_status_history (id, app_id, cluster_id, sequence, resource_kind, resource_namespace, resource_name, previous_state, state, created_at) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			Arguments: []interface{}{
+				ksuid.New().String(),
+				t.AppID,
+				clusterID,
+				t.Sequence,
+				t.Kind,
+				t.Namespace,
+				t.Name,
+				string(t.PreviousState),
+				string(t.State),
+				t.CreatedAt.Unix(),
+			},
+		})
+	}
+	return statements
+}
+
+// ListAppStatusTransitions returns the status transitions of an app in a cluster, oldest first.
+// The cluster id is empty for the cluster kotsadm runs in.
+func (s *KOTSStore) ListAppStatusTransitions(opts appstatetypes.ListStatusTransitionsOptions) ([]appstatetypes.StatusTransition, error) {
+	db := persistence.MustGetDBSession()
+
+	conditions := []string{"app_id = ?", "cluster_id = ?"}
+	args := []interface{}{opts.AppID, opts.ClusterID}
+	if !opts.IncludeResources {
+		conditions = append(conditions, "resource_kind = ''")
+	}
+	if !opts.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, opts.From.Unix())
+	}
+	if !opts.To.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, opts.To.Unix())
+	}
+
+	query := `select id, app_id, cluster_id, sequence, resource_kind, resource_namespace, resource_name, previous_state, state, created_at from app_status_history where ` + strings.Join(conditions, " and ") + ` order by created_at asc, id asc`
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: args,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	transitions := []appstatetypes.StatusTransition{}
+	for rows.Next() {
+		t, err := scanStatusTransition(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		transitions = append(transitions, *t)
+	}
+
+	return transitions, nil
+}
+
+// GetLastAppStatusTransitionBefore returns the last transition of the state of an app before a time, or nil if there is none.
+// It is the state the app was in at that time.
+func (s *KOTSStore) GetLastAppStatusTransitionBefore(appID string, clusterID string, before time.Time) (*appstatetypes.StatusTransition, error) {
+	db := persistence.MustGetDBSession()
+	query := `select id, app_id, cluster_id, sequence, resource_kind, resource_namespace, resource_name, previous_state, state, created_at from app_status_history
+	where app_id = ? and cluster_id = ? and resource_kind = '' and created_at < ?
+	order by created_at desc, id desc limit 1`
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{appID, clusterID, before.Unix()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	if !rows.Next() {
+		return nil, nil
+	}
+
+	t, err := scanStatusTransition(rows)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+
+	return t, nil
+}
+
+func scanStatusTransition(rows persistence.QueryResult) (*appstatetypes.StatusTransition, error) {
+	var sequence gorqlite.NullInt64
+	var previousState gorqlite.NullString
+	var state string
+	var createdAt int64
+
+	t := appstatetypes.StatusTransition{}
+	if err := rows.Scan(&t.ID, &t.AppID, &t.ClusterID, &sequence, &t.Kind, &t.Namespace, &t.Name, &previousState, &state, &createdAt); err != nil {
+		return nil, err
+	}
+
+	t.Sequence = sequence.Int64
+	t.PreviousState = appstatetypes.State(previousState.String)
+	t.State = appstatetypes.State(state)
+	t.CreatedAt = time.Unix(createdAt, 0)
+
+	return &t, nil
+}
@@ -0,0 +1,151 @@
+package kotsstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	audittypes "github.com/replicatedhq/kots/pkg/audit/types"
+	"github.com/replicatedhq/kots/pkg/persistence"
+	"github.com/rqlite/gorqlite"
+	"github.com/segmentio/ksuid"
+)
+
+// CreateAuditLogEntry inserts an entry into the audit log. Entries are never updated or deleted.
+func (s *KOTSStore) CreateAuditLogEntry(entry audittypes.AuditLogEntry) error {
+	db := persistence.MustGetDBSession()
+
+	if entry.ID == "" {
+		entry.ID = ksuid.New().String()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	marshalledRoles, err := json.Marshal(entry.Roles)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal roles")
+	}
+
+	var sequence interface{}
+	if entry.Sequence != nil {
+		sequence = *entry.Sequence
+	}
+
+	query := `insert into audit_log (id, created_at, caller, session_id, roles, app_slug, sequence, action, method, path, status, outcome) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query: query,
+		Arguments: []interface{}{
+			entry.ID,
+			entry.CreatedAt.Unix(),
+			string(entry.Caller),
+			entry.SessionID,
+			string(marshalledRoles),
+			entry.AppSlug,
+			sequence,
+			entry.Action,
+			entry.Method,
+			entry.Path,
+			entry.Status,
+			string(entry.Outcome),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+// ListAuditLogEntries returns a page of audit log entries, newest first, along with the total number of matching entries.
+func (s *KOTSStore) ListAuditLogEntries(opts audittypes.ListAuditLogOptions) (*audittypes.AuditLog, error) {
+	db := persistence.MustGetDBSession()
+
+	conditions := []string{}
+	args := []interface{}{}
+	if opts.AppSlug != "" {
+		conditions = append(conditions, "app_slug = ?")
+		args = append(args, opts.AppSlug)
+	}
+	if opts.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, opts.Action)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " where " + strings.Join(conditions, " and ")
+	}
+
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     `select count(1) from audit_log` + where,
+		Arguments: args,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	auditLog := &audittypes.AuditLog{
+		Entries: []audittypes.AuditLogEntry{},
+	}
+	if rows.Next() {
+		if err := rows.Scan(&auditLog.TotalCount); err != nil {
+			return nil, errors.Wrap(err, "failed to scan count")
+		}
+	}
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	currentPage := opts.CurrentPage
+	if currentPage < 0 {
+		currentPage = 0
+	}
+
+	query := `select id, created_at, caller, session_id, roles, app_slug, sequence, action, method, path, status, outcome from audit_log` + where + ` order by created_at desc, id desc limit ? offset ?`
+	rows, err = db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: append(args, pageSize, currentPage*pageSize),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	for rows.Next() {
+		entry := audittypes.AuditLogEntry{}
+
+		var createdAt int64
+		var caller gorqlite.NullString
+		var sessionID gorqlite.NullString
+		var roles gorqlite.NullString
+		var appSlug gorqlite.NullString
+		var sequence gorqlite.NullInt64
+		var outcome string
+		if err := rows.Scan(&entry.ID, &createdAt, &caller, &sessionID, &roles, &appSlug, &sequence, &entry.Action, &entry.Method, &entry.Path, &entry.Status, &outcome); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+
+		entry.CreatedAt = time.Unix(createdAt, 0)
+		entry.Caller = audittypes.Caller(caller.String)
+		entry.SessionID = sessionID.String
+		entry.AppSlug = appSlug.String
+		entry.Outcome = audittypes.Outcome(outcome)
+
+		if sequence.Valid {
+			entry.Sequence = &sequence.Int64
+		}
+
+		if roles.String != "" {
+			if err := json.Unmarshal([]byte(roles.String), &entry.Roles); err != nil {
+				return nil, errors.Wrap(err, "failed to unmarshal roles")
+			}
+		}
+
+		auditLog.Entries = append(auditLog.Entries, entry)
+	}
+
+	return auditLog, nil
+}
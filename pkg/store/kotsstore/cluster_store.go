@@ -10,6 +10,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/persistence"
 	"github.com/replicatedhq/kots/pkg/rand"
+	"github.com/replicatedhq/kots/pkg/store/types"
 	"github.com/rqlite/gorqlite"
 	"go.uber.org/zap"
 )
@@ -17,7 +18,9 @@ import (
 func (s *KOTSStore) ListClusters() ([]*downstreamtypes.Downstream, error) {
 	db := persistence.MustGetDBSession()
 
-	query := `select id, slug, title, snapshot_schedule, snapshot_ttl from cluster` // TODO the current sequence
+	// the local cluster is listed first, callers that only handle the cluster kotsadm runs in rely on it
+	query := `select id, slug, title, snapshot_schedule, snapshot_ttl, connection_type, target_namespace from cluster
+	order by case when connection_type = 'local' or connection_type is null then 0 else 1 end, created_at` // TODO the current sequence
 	rows, err := db.QueryOne(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
@@ -25,22 +28,60 @@ func (s *KOTSStore) ListClusters() ([]*downstreamtypes.Downstream, error) {
 
 	clusters := []*downstreamtypes.Downstream{}
 	for rows.Next() {
-		cluster := downstreamtypes.Downstream{}
-
-		var snapshotSchedule gorqlite.NullString
-		var snapshotTTL gorqlite.NullString
-
-		if err := rows.Scan(&cluster.ClusterID, &cluster.ClusterSlug, &cluster.Name, &snapshotSchedule, &snapshotTTL); err != nil {
+		cluster, err := clusterFromRow(rows)
+		if err != nil {
 			return nil, errors.Wrap(err, "failed to scan row")
 		}
+		clusters = append(clusters, cluster)
+	}
+
+	return clusters, nil
+}
 
-		cluster.SnapshotSchedule = snapshotSchedule.String
-		cluster.SnapshotTTL = snapshotTTL.String
+// GetCluster returns the cluster with the given id, or nil if it does not exist
+func (s *KOTSStore) GetCluster(clusterID string) (*downstreamtypes.Downstream, error) {
+	db := persistence.MustGetDBSession()
+	query := `select id, slug, title, snapshot_schedule, snapshot_ttl, connection_type, target_namespace from cluster where id = ?`
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{clusterID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+	if !rows.Next() {
+		return nil, nil
+	}
 
-		clusters = append(clusters, &cluster)
+	cluster, err := clusterFromRow(rows)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to scan row")
 	}
 
-	return clusters, nil
+	return cluster, nil
+}
+
+func clusterFromRow(row persistence.QueryResult) (*downstreamtypes.Downstream, error) {
+	cluster := downstreamtypes.Downstream{}
+
+	var snapshotSchedule gorqlite.NullString
+	var snapshotTTL gorqlite.NullString
+	var connectionType gorqlite.NullString
+	var targetNamespace gorqlite.NullString
+
+	if err := row.Scan(&cluster.ClusterID, &cluster.ClusterSlug, &cluster.Name, &snapshotSchedule, &snapshotTTL, &connectionType, &targetNamespace); err != nil {
+		return nil, err
+	}
+
+	cluster.SnapshotSchedule = snapshotSchedule.String
+	cluster.SnapshotTTL = snapshotTTL.String
+	cluster.ConnectionType = connectionType.String
+	if cluster.ConnectionType == "" {
+		cluster.ConnectionType = "local"
+	}
+	cluster.TargetNamespace = targetNamespace.String
+
+	return &cluster, nil
 }
 
 func (s *KOTSStore) GetClusterIDFromSlug(slug string) (string, error) {
@@ -187,3 +228,104 @@ func (s *KOTSStore) SetInstanceSnapshotSchedule(clusterID string, snapshotSchedu
 
 	return nil
 }
+
+// SetClusterConnection sets how kotsadm connects to a cluster and the namespace apps are deployed to in it
+func (s *KOTSStore) SetClusterConnection(clusterID string, connectionType string, targetNamespace string) error {
+	db := persistence.MustGetDBSession()
+	query := `update cluster set connection_type = ?, target_namespace = ? where id = ?`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{connectionType, targetNamespace, clusterID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+// AddAppsToDownstream adds all installed apps to the cluster as downstreams, along with their existing versions.
+// The versions are added as pending so that they can be deployed to the cluster independently of the other downstreams.
+func (s *KOTSStore) AddAppsToDownstream(clusterID string) error {
+	cluster, err := s.GetCluster(clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster")
+	}
+	if cluster == nil {
+		return ErrNotFound
+	}
+
+	apps, err := s.ListInstalledApps()
+	if err != nil {
+		return errors.Wrap(err, "failed to list installed apps")
+	}
+
+	db := persistence.MustGetDBSession()
+
+	for _, a := range apps {
+		statements := []gorqlite.ParameterizedStatement{
+			{
+				Query:     `insert into app_downstream (app_id, cluster_id, downstream_name) values (?, ?, ?) ON CONFLICT DO NOTHING`,
+				Arguments: []interface{}{a.ID, clusterID, cluster.Name},
+			},
+			{
+				Query: `insert into app_downstream_version (app_id, cluster_id, sequence, parent_sequence, created_at, version_label, status, source, diff_summary, diff_summary_error, git_deployable, preflight_skipped)
+				select v.app_id, ?, v.sequence, v.parent_sequence, v.created_at, v.version_label, ?, v.source, v.diff_summary, v.diff_summary_error, v.git_deployable, v.preflight_skipped
+				from app_downstream_version v
+				inner join cluster c on v.cluster_id = c.id
+				where v.app_id = ? and (c.connection_type = 'local' or c.connection_type is null) and v.status != ?
+				ON CONFLICT DO NOTHING`,
+				Arguments: []interface{}{clusterID, types.VersionPending, a.ID, types.VersionPendingDownload},
+			},
+		}
+
+		if wrs, err := db.WriteParameterized(statements); err != nil {
+			wrErrs := []error{}
+			for _, wr := range wrs {
+				wrErrs = append(wrErrs, wr.Err)
+			}
+			return fmt.Errorf("failed to write: %v: %v", err, wrErrs)
+		}
+	}
+
+	return nil
+}
+
+// RemoveCluster deletes a cluster along with its downstreams. It does not remove anything from the cluster itself.
+func (s *KOTSStore) RemoveCluster(clusterID string) error {
+	db := persistence.MustGetDBSession()
+
+	tables := []string{
+		"app_downstream_status",
+		"app_downstream_output",
+		"app_downstream_version",
+		"app_downstream",
+		"user_cluster",
+	}
+
+	statements := []gorqlite.ParameterizedStatement{}
+	for _, table := range tables {
+		statements = append(statements, gorqlite.ParameterizedStatement{
+			Query:     fmt.Sprintf(`delete from %s where cluster_id = ?`, table),
+			Arguments: []interface{}{clusterID},
+		})
+	}
+	statements = append(statements, gorqlite.ParameterizedStatement{
+		Query:     `delete from app_promotion_stage where source_cluster_id = ? or target_cluster_id = ?`,
+		Arguments: []interface{}{clusterID, clusterID},
+	})
+	statements = append(statements, gorqlite.ParameterizedStatement{
+		Query:     `delete from cluster where id = ?`,
+		Arguments: []interface{}{clusterID},
+	})
+
+	if wrs, err := db.WriteParameterized(statements); err != nil {
+		wrErrs := []error{}
+		for _, wr := range wrs {
+			wrErrs = append(wrErrs, wr.Err)
+		}
+		return fmt.Errorf("failed to write: %v: %v", err, wrErrs)
+	}
+
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/cursor"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
 	"github.com/replicatedhq/kots/pkg/persistence"
 	"github.com/replicatedhq/kots/pkg/store"
 	"github.com/replicatedhq/kots/pkg/store/types"
@@ -134,17 +135,21 @@ func (s *KOTSStore) GetPreviouslyDeployedSequence(appID string, clusterID string
 	return -1, nil
 }
 
+// localClusterFilter limits downstream queries that are not given a cluster to the cluster kotsadm runs in.
+// Remote clusters have their own versions that are deployed and tracked separately.
+const localClusterFilter = `cluster_id in (select id from cluster where connection_type = 'local' or connection_type is null)`
+
 func (s *KOTSStore) MarkAsCurrentDownstreamVersion(appID string, sequence int64) error {
 	db := persistence.MustGetDBSession()
 	statements := []gorqlite.ParameterizedStatement{}
 
 	statements = append(statements, gorqlite.ParameterizedStatement{
-		Query:     `update app_downstream set current_sequence = ? where app_id = ?`,
+		Query:     `update app_downstream set current_sequence = ? where app_id = ? and ` + localClusterFilter,
 		Arguments: []interface{}{sequence, appID},
 	})
 
 	statements = append(statements, gorqlite.ParameterizedStatement{
-		Query:     `update app_downstream_version set applied_at = ? where sequence = ? and app_id = ?`,
+		Query:     `update app_downstream_version set applied_at = ? where sequence = ? and app_id = ? and ` + localClusterFilter,
 		Arguments: []interface{}{time.Now().Unix(), sequence, appID},
 	})
 
@@ -159,10 +164,36 @@ func (s *KOTSStore) MarkAsCurrentDownstreamVersion(appID string, sequence int64)
 	return nil
 }
 
+// MarkAsCurrentDownstreamVersionForCluster is the same as MarkAsCurrentDownstreamVersion, but for a single cluster
+func (s *KOTSStore) MarkAsCurrentDownstreamVersionForCluster(appID string, clusterID string, sequence int64) error {
+	db := persistence.MustGetDBSession()
+	statements := []gorqlite.ParameterizedStatement{}
+
+	statements = append(statements, gorqlite.ParameterizedStatement{
+		Query:     `update app_downstream set current_sequence = ? where app_id = ? and cluster_id = ?`,
+		Arguments: []interface{}{sequence, appID, clusterID},
+	})
+
+	statements = append(statements, gorqlite.ParameterizedStatement{
+		Query:     `update app_downstream_version set applied_at = ? where sequence = ? and app_id = ? and cluster_id = ?`,
+		Arguments: []interface{}{time.Now().Unix(), sequence, appID, clusterID},
+	})
+
+	if wrs, err := db.WriteParameterized(statements); err != nil {
+		wrErrs := []error{}
+		for _, wr := range wrs {
+			wrErrs = append(wrErrs, wr.Err)
+		}
+		return fmt.Errorf("failed to write: %v: %v", err, wrErrs)
+	}
+
+	return nil
+}
+
 // SetDownstreamVersionStatus updates the status and status info for the downstream version with the given sequence and app id
 func (s *KOTSStore) SetDownstreamVersionStatus(appID string, sequence int64, status types.DownstreamVersionStatus, statusInfo string) error {
 	db := persistence.MustGetDBSession()
-	query := `update app_downstream_version set status = ?, status_info = ? where app_id = ? and sequence = ?`
+	query := `update app_downstream_version set status = ?, status_info = ? where app_id = ? and sequence = ? and ` + localClusterFilter
 	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{status, statusInfo, appID, sequence},
@@ -174,10 +205,25 @@ func (s *KOTSStore) SetDownstreamVersionStatus(appID string, sequence int64, sta
 	return nil
 }
 
+// SetDownstreamVersionStatusForCluster is the same as SetDownstreamVersionStatus, but for a single cluster
+func (s *KOTSStore) SetDownstreamVersionStatusForCluster(appID string, clusterID string, sequence int64, status types.DownstreamVersionStatus, statusInfo string) error {
+	db := persistence.MustGetDBSession()
+	query := `update app_downstream_version set status = ?, status_info = ? where app_id = ? and cluster_id = ? and sequence = ?`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{status, statusInfo, appID, clusterID, sequence},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
 // GetDownstreamVersionStatus gets the status for the downstream version with the given sequence and app id
 func (s *KOTSStore) GetDownstreamVersionStatus(appID string, sequence int64) (types.DownstreamVersionStatus, error) {
 	db := persistence.MustGetDBSession()
-	query := `select status from app_downstream_version where app_id = ? and sequence = ?`
+	query := `select status from app_downstream_version where app_id = ? and sequence = ? and ` + localClusterFilter
 	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
 		Query:     query,
 		Arguments: []interface{}{appID, sequence},
@@ -265,6 +311,7 @@ func (s *KOTSStore) GetCurrentDownstreamVersion(appID string, clusterID string)
 	adv.source,
 	adv.preflight_skipped,
 	adv.git_commit_url,
+	adv.git_pull_request_url,
 	adv.git_deployable,
 	ado.is_error,
 	av.upstream_released_at,
@@ -360,6 +407,7 @@ func (s *KOTSStore) GetDownstreamVersions(appID string, clusterID string, downlo
 	adv.source,
 	adv.preflight_skipped,
 	adv.git_commit_url,
+	adv.git_pull_request_url,
 	adv.git_deployable,
 	ado.is_error,
 	av.upstream_released_at,
@@ -518,6 +566,9 @@ func (s *KOTSStore) GetDownstreamVersionHistory(appID string, clusterID string,
 	if err := s.AddDownstreamVersionsDetails(appID, clusterID, desiredVersions, true); err != nil {
 		return nil, errors.Wrap(err, "failed to add details for desired versions")
 	}
+	if err := s.addDownstreamVersionsPromotions(appID, clusterID, desiredVersions); err != nil {
+		return nil, errors.Wrap(err, "failed to add promotions for desired versions")
+	}
 	history.VersionHistory = desiredVersions
 
 	return history, nil
@@ -765,7 +816,7 @@ func (s *KOTSStore) getLatestDeployableDownstreamVersion(appID string, clusterID
 	return
 }
 
-func (s *KOTSStore) downstreamVersionFromRow(appID string, row gorqlite.QueryResult) (*downstreamtypes.DownstreamVersion, error) {
+func (s *KOTSStore) downstreamVersionFromRow(appID string, row persistence.QueryResult) (*downstreamtypes.DownstreamVersion, error) {
 	v := &downstreamtypes.DownstreamVersion{}
 
 	var createdOn gorqlite.NullTime
@@ -778,6 +829,7 @@ func (s *KOTSStore) downstreamVersionFromRow(appID string, row gorqlite.QueryRes
 	var source gorqlite.NullString
 	var preflightSkipped gorqlite.NullBool
 	var commitURL gorqlite.NullString
+	var pullRequestURL gorqlite.NullString
 	var gitDeployable gorqlite.NullBool
 	var hasError gorqlite.NullBool
 	var upstreamReleasedAt gorqlite.NullTime
@@ -791,6 +843,7 @@ func (s *KOTSStore) downstreamVersionFromRow(appID string, row gorqlite.QueryRes
 		&source,
 		&preflightSkipped,
 		&commitURL,
+		&pullRequestURL,
 		&gitDeployable,
 		&hasError,
 		&upstreamReleasedAt,
@@ -828,6 +881,7 @@ func (s *KOTSStore) downstreamVersionFromRow(appID string, row gorqlite.QueryRes
 	v.Source = source.String
 	v.PreflightSkipped = preflightSkipped.Bool
 	v.CommitURL = commitURL.String
+	v.PullRequestURL = pullRequestURL.String
 	v.GitDeployable = gitDeployable.Bool
 
 	if upstreamReleasedAt.Valid {
@@ -1098,7 +1152,10 @@ func (s *KOTSStore) GetDownstreamOutput(appID string, clusterID string, sequence
 	ado.apply_stdout,
 	ado.apply_stderr,
 	ado.helm_stdout,
-	ado.helm_stderr
+	ado.helm_stderr,
+	ado.resource_results,
+	ado.helm_release_results,
+	ado.rollout_log
 FROM
 	app_downstream_version adv
 LEFT JOIN
@@ -1129,8 +1186,11 @@ WHERE
 	var applyStderr gorqlite.NullString
 	var helmStdout gorqlite.NullString
 	var helmStderr gorqlite.NullString
+	var resourceResultsStr gorqlite.NullString
+	var helmReleaseResultsStr gorqlite.NullString
+	var rolloutLog gorqlite.NullString
 
-	if err := rows.Scan(&status, &statusInfo, &dryrunStdout, &dryrunStderr, &applyStdout, &applyStderr, &helmStdout, &helmStderr); err != nil {
+	if err := rows.Scan(&status, &statusInfo, &dryrunStdout, &dryrunStderr, &applyStdout, &applyStderr, &helmStdout, &helmStderr, &resourceResultsStr, &helmReleaseResultsStr, &rolloutLog); err != nil {
 		return nil, errors.Wrap(err, "failed to select downstream")
 	}
 
@@ -1175,14 +1235,31 @@ WHERE
 		helmStderrDecoded = []byte("")
 	}
 
+	resourceResults := []operatortypes.ResourceResult{}
+	if resourceResultsStr.String != "" {
+		if err := json.Unmarshal([]byte(resourceResultsStr.String), &resourceResults); err != nil {
+			logger.Error(errors.Wrap(err, "failed to unmarshal resource results"))
+		}
+	}
+
+	helmReleaseResults := []operatortypes.HelmReleaseResult{}
+	if helmReleaseResultsStr.String != "" {
+		if err := json.Unmarshal([]byte(helmReleaseResultsStr.String), &helmReleaseResults); err != nil {
+			logger.Error(errors.Wrap(err, "failed to unmarshal helm release results"))
+		}
+	}
+
 	output := &downstreamtypes.DownstreamOutput{
-		DryrunStdout: string(dryrunStdoutDecoded),
-		DryrunStderr: string(dryrunStderrDecoded),
-		ApplyStdout:  string(applyStdoutDecoded),
-		ApplyStderr:  string(applyStderrDecoded),
-		HelmStdout:   string(helmStdoutDecoded),
-		HelmStderr:   string(helmStderrDecoded),
-		RenderError:  string(renderError),
+		DryrunStdout:       string(dryrunStdoutDecoded),
+		DryrunStderr:       string(dryrunStderrDecoded),
+		ApplyStdout:        string(applyStdoutDecoded),
+		ApplyStderr:        string(applyStderrDecoded),
+		HelmStdout:         string(helmStdoutDecoded),
+		HelmStderr:         string(helmStderrDecoded),
+		RenderError:        string(renderError),
+		ResourceResults:    resourceResults,
+		HelmReleaseResults: helmReleaseResults,
+		RolloutLog:         rolloutLog.String,
 	}
 
 	return output, nil
@@ -1217,14 +1294,46 @@ func (s *KOTSStore) IsDownstreamDeploySuccessful(appID string, clusterID string,
 func (s *KOTSStore) UpdateDownstreamDeployStatus(appID string, clusterID string, sequence int64, isError bool, output downstreamtypes.DownstreamOutput) error {
 	db := persistence.MustGetDBSession()
 
-	query := `insert into app_downstream_output (app_id, cluster_id, downstream_sequence, is_error, dryrun_stdout, dryrun_stderr, apply_stdout, apply_stderr, helm_stdout, helm_stderr)
-	values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) on conflict (app_id, cluster_id, downstream_sequence) do update set is_error = EXCLUDED.is_error,
+	resourceResults, err := json.Marshal(output.ResourceResults)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal resource results")
+	}
+
+	helmReleaseResults, err := json.Marshal(output.HelmReleaseResults)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal helm release results")
+	}
+
+	query := `insert into app_downstream_output (app_id, cluster_id, downstream_sequence, is_error, dryrun_stdout, dryrun_stderr, apply_stdout, apply_stderr, helm_stdout, helm_stderr, resource_results, helm_release_results)
+	values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) on conflict (app_id, cluster_id, downstream_sequence) do update set is_error = EXCLUDED.is_error,
 	dryrun_stdout = EXCLUDED.dryrun_stdout, dryrun_stderr = EXCLUDED.dryrun_stderr, apply_stdout = EXCLUDED.apply_stdout, apply_stderr = EXCLUDED.apply_stderr,
-	helm_stdout = EXCLUDED.helm_stdout, helm_stderr = EXCLUDED.helm_stderr`
+	helm_stdout = EXCLUDED.helm_stdout, helm_stderr = EXCLUDED.helm_stderr, resource_results = EXCLUDED.resource_results, helm_release_results = EXCLUDED.helm_release_results`
+
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{appID, clusterID, sequence, isError, output.DryrunStdout, output.DryrunStderr, output.ApplyStdout, output.ApplyStderr, output.HelmStdout, output.HelmStderr, string(resourceResults), string(helmReleaseResults)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+// SetDownstreamRolloutLog stores the progress of a progressive rollout in the downstream output.
+// The deploy is marked as an error until the deploy status is updated so that the output is not mistaken for a successful deploy.
+// If isError is set, the deploy is marked as an error even if the deploy status was already updated, e.g. when the
+// rollout fails after all resources were deployed.
+func (s *KOTSStore) SetDownstreamRolloutLog(appID string, clusterID string, sequence int64, rolloutLog string, isError bool) error {
+	db := persistence.MustGetDBSession()
+
+	query := `insert into app_downstream_output (app_id, cluster_id, downstream_sequence, is_error, rollout_log)
+	values (?, ?, ?, ?, ?) on conflict (app_id, cluster_id, downstream_sequence) do update set rollout_log = EXCLUDED.rollout_log,
+	is_error = (app_downstream_output.is_error or ?)`
 
 	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
 		Query:     query,
-		Arguments: []interface{}{appID, clusterID, sequence, isError, output.DryrunStdout, output.DryrunStderr, output.ApplyStdout, output.ApplyStderr, output.HelmStdout, output.HelmStderr},
+		Arguments: []interface{}{appID, clusterID, sequence, true, rolloutLog, isError},
 	})
 	if err != nil {
 		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
@@ -0,0 +1,119 @@
+package kotsstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
+	"github.com/replicatedhq/kots/pkg/persistence"
+	"github.com/rqlite/gorqlite"
+)
+
+// GetAppDriftStatus returns the result of the last drift check, or nil if the app was never checked
+func (s *KOTSStore) GetAppDriftStatus(appID string) (*operatortypes.AppDriftStatus, error) {
+	db := persistence.MustGetDBSession()
+	query := `select sequence, checked_at, resources, error from app_drift_status where app_id = ?`
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{appID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	if !rows.Next() {
+		return nil, nil
+	}
+
+	var sequence gorqlite.NullInt64
+	var checkedAt gorqlite.NullTime
+	var resourcesStr gorqlite.NullString
+	var errorStr gorqlite.NullString
+
+	if err := rows.Scan(&sequence, &checkedAt, &resourcesStr, &errorStr); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+
+	driftStatus := operatortypes.AppDriftStatus{
+		AppID:     appID,
+		Sequence:  sequence.Int64,
+		Resources: []operatortypes.DriftedResource{},
+		Error:     errorStr.String,
+	}
+
+	if checkedAt.Valid {
+		driftStatus.CheckedAt = checkedAt.Time
+	}
+
+	if resourcesStr.String != "" {
+		if err := json.Unmarshal([]byte(resourcesStr.String), &driftStatus.Resources); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal drifted resources")
+		}
+	}
+
+	return &driftStatus, nil
+}
+
+func (s *KOTSStore) SetAppDriftStatus(appID string, sequence int64, resources []operatortypes.DriftedResource, checkedAt time.Time, driftErr string) error {
+	marshalledResources, err := json.Marshal(resources)
+	if err != nil {
+		return errors.Wrap(err, "failed to json marshal drifted resources")
+	}
+
+	db := persistence.MustGetDBSession()
+	query := `
+	insert into app_drift_status (app_id, sequence, checked_at, resources, error)
+	values (?, ?, ?, ?, ?)
+	on conflict (app_id) do update set
+	  sequence = EXCLUDED.sequence,
+	  checked_at = EXCLUDED.checked_at,
+	  resources = EXCLUDED.resources,
+	  error = EXCLUDED.error`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{appID, sequence, checkedAt.Unix(), string(marshalledResources), driftErr},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+func (s *KOTSStore) GetAppDriftAutoRemediate(appID string) (bool, error) {
+	db := persistence.MustGetDBSession()
+	query := `select drift_auto_remediate from app where id = ?`
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{appID},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+	if !rows.Next() {
+		return false, ErrNotFound
+	}
+
+	var autoRemediate gorqlite.NullBool
+	if err := rows.Scan(&autoRemediate); err != nil {
+		return false, errors.Wrap(err, "failed to scan")
+	}
+
+	return autoRemediate.Bool, nil
+}
+
+func (s *KOTSStore) SetAppDriftAutoRemediate(appID string, autoRemediate bool) error {
+	db := persistence.MustGetDBSession()
+	query := `update app set drift_auto_remediate = ? where id = ?`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{autoRemediate, appID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
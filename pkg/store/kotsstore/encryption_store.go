@@ -0,0 +1,68 @@
+package kotsstore
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	keyrotationtypes "github.com/replicatedhq/kots/pkg/keyrotation/types"
+	"github.com/replicatedhq/kots/pkg/persistence"
+	"github.com/rqlite/gorqlite"
+)
+
+// encryptedColumns are the columns that hold values encrypted with the kotsadm encryption key
+var encryptedColumns = []struct {
+	table    string
+	idColumn string
+	column   string
+}{
+	{table: "app", idColumn: "id", column: "registry_password_enc"},
+	{table: "webhook_target", idColumn: "id", column: "secret_enc"},
+}
+
+func (s *KOTSStore) ListEncryptedValues() ([]keyrotationtypes.EncryptedValue, error) {
+	db := persistence.MustGetDBSession()
+
+	values := []keyrotationtypes.EncryptedValue{}
+	for _, c := range encryptedColumns {
+		query := fmt.Sprintf(`select %s, %s from %s where %s is not null and %s != ''`, c.idColumn, c.column, c.table, c.column, c.column)
+		rows, err := db.QueryOne(query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s: %v: %v", c.table, err, rows.Err)
+		}
+
+		for rows.Next() {
+			value := keyrotationtypes.EncryptedValue{
+				Table:  c.table,
+				Column: c.column,
+			}
+			if err := rows.Scan(&value.ID, &value.Value); err != nil {
+				return nil, errors.Wrap(err, "failed to scan")
+			}
+			values = append(values, value)
+		}
+	}
+
+	return values, nil
+}
+
+func (s *KOTSStore) UpdateEncryptedValue(value keyrotationtypes.EncryptedValue) error {
+	for _, c := range encryptedColumns {
+		if c.table != value.Table || c.column != value.Column {
+			continue
+		}
+
+		db := persistence.MustGetDBSession()
+		query := fmt.Sprintf(`update %s set %s = ? where %s = ?`, c.table, c.column, c.idColumn)
+		wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+			Query:     query,
+			Arguments: []interface{}{value.Value, value.ID},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+		}
+
+		return nil
+	}
+
+	return errors.Errorf("%s.%s is not an encrypted column", value.Table, value.Column)
+}
@@ -0,0 +1,68 @@
+package kotsstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	imagetypes "github.com/replicatedhq/kots/pkg/image/types"
+	"github.com/replicatedhq/kots/pkg/persistence"
+	"github.com/rqlite/gorqlite"
+)
+
+// GetImageVerificationResults returns the signature verification results of the images that were copied for the app version
+func (s *KOTSStore) GetImageVerificationResults(appID string, sequence int64) ([]imagetypes.ImageVerificationResult, error) {
+	db := persistence.MustGetDBSession()
+	query := `select results from app_image_verification where app_id = ? and sequence = ?`
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{appID, sequence},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	results := []imagetypes.ImageVerificationResult{}
+	if !rows.Next() {
+		return results, nil
+	}
+
+	var resultsStr gorqlite.NullString
+	if err := rows.Scan(&resultsStr); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+
+	if resultsStr.String != "" {
+		if err := json.Unmarshal([]byte(resultsStr.String), &results); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal results")
+		}
+	}
+
+	return results, nil
+}
+
+// SetImageVerificationResults replaces the signature verification results of the images of the app version
+func (s *KOTSStore) SetImageVerificationResults(appID string, sequence int64, results []imagetypes.ImageVerificationResult) error {
+	marshalledResults, err := json.Marshal(results)
+	if err != nil {
+		return errors.Wrap(err, "failed to json marshal results")
+	}
+
+	db := persistence.MustGetDBSession()
+	query := `
+	insert into app_image_verification (app_id, sequence, results, updated_at)
+	values (?, ?, ?, ?)
+	on conflict (app_id, sequence) do update set
+	  results = EXCLUDED.results,
+	  updated_at = EXCLUDED.updated_at`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{appID, sequence, string(marshalledResults), time.Now().Unix()},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package kotsstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	maintenancewindowtypes "github.com/replicatedhq/kots/pkg/maintenancewindow/types"
+	"github.com/replicatedhq/kots/pkg/persistence"
+	"github.com/rqlite/gorqlite"
+)
+
+func (s *KOTSStore) GetAppMaintenanceWindows(appID string) ([]maintenancewindowtypes.MaintenanceWindow, error) {
+	db := persistence.MustGetDBSession()
+	query := `select maintenance_windows from app where id = ?`
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{appID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+	if !rows.Next() {
+		return nil, ErrNotFound
+	}
+
+	var windowsStr gorqlite.NullString
+	if err := rows.Scan(&windowsStr); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+
+	windows := []maintenancewindowtypes.MaintenanceWindow{}
+	if windowsStr.String != "" {
+		if err := json.Unmarshal([]byte(windowsStr.String), &windows); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal maintenance windows")
+		}
+	}
+
+	return windows, nil
+}
+
+func (s *KOTSStore) SetAppMaintenanceWindows(appID string, windows []maintenancewindowtypes.MaintenanceWindow) error {
+	db := persistence.MustGetDBSession()
+
+	windowsStr := ""
+	if len(windows) > 0 {
+		b, err := json.Marshal(windows)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal maintenance windows")
+		}
+		windowsStr = string(b)
+	}
+
+	query := `update app set maintenance_windows = ? where id = ?`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{windowsStr, appID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+// SetQueuedDeploy queues a deploy, replacing any deploy that is already queued for the app and downstream
+func (s *KOTSStore) SetQueuedDeploy(queuedDeploy maintenancewindowtypes.QueuedDeploy) error {
+	db := persistence.MustGetDBSession()
+
+	query := `insert into app_deploy_queue (app_id, cluster_id, sequence, source, queued_at) values (?, ?, ?, ?, ?)
+	on conflict (app_id, cluster_id) do update set sequence = EXCLUDED.sequence, source = EXCLUDED.source, queued_at = EXCLUDED.queued_at`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{queuedDeploy.AppID, queuedDeploy.ClusterID, queuedDeploy.Sequence, queuedDeploy.Source, queuedDeploy.QueuedAt.Unix()},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+// GetQueuedDeploy returns nil if no deploy is queued for the app and downstream
+func (s *KOTSStore) GetQueuedDeploy(appID string, clusterID string) (*maintenancewindowtypes.QueuedDeploy, error) {
+	db := persistence.MustGetDBSession()
+	query := `select app_id, cluster_id, sequence, source, queued_at from app_deploy_queue where app_id = ? and cluster_id = ?`
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{appID, clusterID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+	if !rows.Next() {
+		return nil, nil
+	}
+
+	return queuedDeployFromRow(rows)
+}
+
+func (s *KOTSStore) ListQueuedDeploys() ([]maintenancewindowtypes.QueuedDeploy, error) {
+	db := persistence.MustGetDBSession()
+	query := `select app_id, cluster_id, sequence, source, queued_at from app_deploy_queue order by queued_at asc`
+	rows, err := db.QueryOne(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	queuedDeploys := []maintenancewindowtypes.QueuedDeploy{}
+	for rows.Next() {
+		queuedDeploy, err := queuedDeployFromRow(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get queued deploy from row")
+		}
+		queuedDeploys = append(queuedDeploys, *queuedDeploy)
+	}
+
+	return queuedDeploys, nil
+}
+
+func (s *KOTSStore) DeleteQueuedDeploy(appID string, clusterID string) error {
+	db := persistence.MustGetDBSession()
+	query := `delete from app_deploy_queue where app_id = ? and cluster_id = ?`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{appID, clusterID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+func queuedDeployFromRow(row persistence.QueryResult) (*maintenancewindowtypes.QueuedDeploy, error) {
+	queuedDeploy := maintenancewindowtypes.QueuedDeploy{}
+
+	var source gorqlite.NullString
+	var queuedAt int64
+	if err := row.Scan(&queuedDeploy.AppID, &queuedDeploy.ClusterID, &queuedDeploy.Sequence, &source, &queuedAt); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+
+	queuedDeploy.Source = source.String
+	queuedDeploy.QueuedAt = time.Unix(queuedAt, 0)
+
+	return &queuedDeploy, nil
+}
@@ -0,0 +1,283 @@
+package kotsstore
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/crypto"
+	notificationstypes "github.com/replicatedhq/kots/pkg/notifications/types"
+	"github.com/replicatedhq/kots/pkg/persistence"
+	"github.com/rqlite/gorqlite"
+	"github.com/segmentio/ksuid"
+)
+
+func (s *KOTSStore) ListWebhookTargets() ([]notificationstypes.WebhookTarget, error) {
+	db := persistence.MustGetDBSession()
+	query := `select id, name, url, secret_enc, events, enabled, created_at, updated_at from webhook_target order by created_at asc`
+	rows, err := db.QueryOne(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	targets := []notificationstypes.WebhookTarget{}
+	for rows.Next() {
+		target, err := webhookTargetFromRow(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get webhook target from row")
+		}
+		targets = append(targets, *target)
+	}
+
+	return targets, nil
+}
+
+func (s *KOTSStore) GetWebhookTarget(id string) (*notificationstypes.WebhookTarget, error) {
+	db := persistence.MustGetDBSession()
+	query := `select id, name, url, secret_enc, events, enabled, created_at, updated_at from webhook_target where id = ?`
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{id},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	if !rows.Next() {
+		return nil, ErrNotFound
+	}
+
+	target, err := webhookTargetFromRow(rows)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get webhook target from row")
+	}
+
+	return target, nil
+}
+
+func (s *KOTSStore) CreateWebhookTarget(target notificationstypes.WebhookTarget) (*notificationstypes.WebhookTarget, error) {
+	db := persistence.MustGetDBSession()
+
+	target.ID = ksuid.New().String()
+	target.CreatedAt = time.Now()
+	target.UpdatedAt = target.CreatedAt
+
+	events, err := json.Marshal(target.Events)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal events")
+	}
+
+	query := `insert into webhook_target (id, name, url, secret_enc, events, enabled, created_at, updated_at) values (?, ?, ?, ?, ?, ?, ?, ?)`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query: query,
+		Arguments: []interface{}{
+			target.ID,
+			target.Name,
+			target.URL,
+			encryptWebhookSecret(target.Secret),
+			string(events),
+			target.Enabled,
+			target.CreatedAt.Unix(),
+			target.UpdatedAt.Unix(),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return &target, nil
+}
+
+// UpdateWebhookTarget updates a webhook target. The secret is left unchanged if it's empty.
+func (s *KOTSStore) UpdateWebhookTarget(target notificationstypes.WebhookTarget) error {
+	db := persistence.MustGetDBSession()
+
+	events, err := json.Marshal(target.Events)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal events")
+	}
+
+	statement := gorqlite.ParameterizedStatement{
+		Query:     `update webhook_target set name = ?, url = ?, events = ?, enabled = ?, updated_at = ? where id = ?`,
+		Arguments: []interface{}{target.Name, target.URL, string(events), target.Enabled, time.Now().Unix(), target.ID},
+	}
+	if target.Secret != "" {
+		statement = gorqlite.ParameterizedStatement{
+			Query:     `update webhook_target set name = ?, url = ?, secret_enc = ?, events = ?, enabled = ?, updated_at = ? where id = ?`,
+			Arguments: []interface{}{target.Name, target.URL, encryptWebhookSecret(target.Secret), string(events), target.Enabled, time.Now().Unix(), target.ID},
+		}
+	}
+
+	wr, err := db.WriteOneParameterized(statement)
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+	if wr.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *KOTSStore) DeleteWebhookTarget(id string) error {
+	db := persistence.MustGetDBSession()
+
+	statements := []gorqlite.ParameterizedStatement{
+		{
+			Query:     `delete from webhook_delivery where target_id = ?`,
+			Arguments: []interface{}{id},
+		},
+		{
+			Query:     `delete from webhook_target where id = ?`,
+			Arguments: []interface{}{id},
+		},
+	}
+
+	if wrs, err := db.WriteParameterized(statements); err != nil {
+		wrErrs := []error{}
+		for _, wr := range wrs {
+			wrErrs = append(wrErrs, wr.Err)
+		}
+		return fmt.Errorf("failed to write: %v: %v", err, wrErrs)
+	}
+
+	return nil
+}
+
+func (s *KOTSStore) CreateWebhookDelivery(delivery notificationstypes.WebhookDelivery) error {
+	db := persistence.MustGetDBSession()
+
+	if delivery.ID == "" {
+		delivery.ID = ksuid.New().String()
+	}
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now()
+	}
+
+	query := `insert into webhook_delivery (id, target_id, event_id, event_type, attempt, status_code, error, success, payload, created_at) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query: query,
+		Arguments: []interface{}{
+			delivery.ID,
+			delivery.TargetID,
+			delivery.EventID,
+			string(delivery.EventType),
+			delivery.Attempt,
+			delivery.StatusCode,
+			delivery.Error,
+			delivery.Success,
+			delivery.Payload,
+			delivery.CreatedAt.Unix(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries returns a page of delivery attempts for a webhook target, newest first.
+func (s *KOTSStore) ListWebhookDeliveries(targetID string, currentPage int, pageSize int) (*notificationstypes.WebhookDeliveries, error) {
+	db := persistence.MustGetDBSession()
+
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     `select count(1) from webhook_delivery where target_id = ?`,
+		Arguments: []interface{}{targetID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	deliveries := &notificationstypes.WebhookDeliveries{
+		Deliveries: []notificationstypes.WebhookDelivery{},
+	}
+	if rows.Next() {
+		if err := rows.Scan(&deliveries.TotalCount); err != nil {
+			return nil, errors.Wrap(err, "failed to scan count")
+		}
+	}
+
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	if currentPage < 0 {
+		currentPage = 0
+	}
+
+	query := `select id, target_id, event_id, event_type, attempt, status_code, error, success, payload, created_at from webhook_delivery where target_id = ? order by created_at desc, id desc limit ? offset ?`
+	rows, err = db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{targetID, pageSize, currentPage * pageSize},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	for rows.Next() {
+		delivery := notificationstypes.WebhookDelivery{}
+
+		var eventType string
+		var statusCode gorqlite.NullInt64
+		var deliveryError gorqlite.NullString
+		var payload gorqlite.NullString
+		var createdAt int64
+		if err := rows.Scan(&delivery.ID, &delivery.TargetID, &delivery.EventID, &eventType, &delivery.Attempt, &statusCode, &deliveryError, &delivery.Success, &payload, &createdAt); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+
+		delivery.EventType = notificationstypes.EventType(eventType)
+		delivery.StatusCode = int(statusCode.Int64)
+		delivery.Error = deliveryError.String
+		delivery.Payload = payload.String
+		delivery.CreatedAt = time.Unix(createdAt, 0)
+
+		deliveries.Deliveries = append(deliveries.Deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+func webhookTargetFromRow(row persistence.QueryResult) (*notificationstypes.WebhookTarget, error) {
+	target := notificationstypes.WebhookTarget{}
+
+	var secretEnc gorqlite.NullString
+	var events gorqlite.NullString
+	var createdAt int64
+	var updatedAt int64
+	if err := row.Scan(&target.ID, &target.Name, &target.URL, &secretEnc, &events, &target.Enabled, &createdAt, &updatedAt); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+
+	target.CreatedAt = time.Unix(createdAt, 0)
+	target.UpdatedAt = time.Unix(updatedAt, 0)
+
+	if events.String != "" {
+		if err := json.Unmarshal([]byte(events.String), &target.Events); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal events")
+		}
+	}
+
+	if secretEnc.String != "" {
+		decoded, err := base64.StdEncoding.DecodeString(secretEnc.String)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode secret")
+		}
+		decrypted, err := crypto.Decrypt(decoded)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decrypt secret")
+		}
+		target.Secret = string(decrypted)
+	}
+
+	return &target, nil
+}
+
+func encryptWebhookSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(crypto.Encrypt([]byte(secret)))
+}
@@ -139,7 +139,7 @@ func (s *KOTSStore) SetIgnorePreflightPermissionErrors(appID string, sequence in
 	return nil
 }
 
-func (s *KOTSStore) preflightResultFromRow(row gorqlite.QueryResult) (*preflighttypes.PreflightResult, error) {
+func (s *KOTSStore) preflightResultFromRow(row persistence.QueryResult) (*preflighttypes.PreflightResult, error) {
 	r := &preflighttypes.PreflightResult{}
 
 	var preflightResult gorqlite.NullString
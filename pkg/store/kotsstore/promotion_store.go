@@ -0,0 +1,200 @@
+package kotsstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	downstreamtypes "github.com/replicatedhq/kots/pkg/api/downstream/types"
+	"github.com/replicatedhq/kots/pkg/persistence"
+	promotiontypes "github.com/replicatedhq/kots/pkg/promotion/types"
+	"github.com/rqlite/gorqlite"
+	"github.com/segmentio/ksuid"
+)
+
+func (s *KOTSStore) ListPromotionStages(appID string) ([]promotiontypes.Stage, error) {
+	db := persistence.MustGetDBSession()
+	query := `select source_cluster_id, target_cluster_id, min_soak_seconds, require_preflights, require_no_drift from app_promotion_stage where app_id = ? order by source_cluster_id, target_cluster_id`
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{appID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	stages := []promotiontypes.Stage{}
+	for rows.Next() {
+		stage := promotiontypes.Stage{
+			AppID: appID,
+		}
+		var minSoakSeconds gorqlite.NullInt64
+		var requirePreflights gorqlite.NullBool
+		var requireNoDrift gorqlite.NullBool
+		if err := rows.Scan(&stage.SourceClusterID, &stage.TargetClusterID, &minSoakSeconds, &requirePreflights, &requireNoDrift); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		stage.MinSoakSeconds = minSoakSeconds.Int64
+		stage.RequirePreflights = requirePreflights.Bool
+		stage.RequireNoDrift = requireNoDrift.Bool
+		stages = append(stages, stage)
+	}
+
+	return stages, nil
+}
+
+func (s *KOTSStore) GetPromotionStage(appID string, targetClusterID string) (*promotiontypes.Stage, error) {
+	stages, err := s.ListPromotionStages(appID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list promotion stages")
+	}
+
+	for _, stage := range stages {
+		if stage.TargetClusterID == targetClusterID {
+			return &stage, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *KOTSStore) SetPromotionStage(stage promotiontypes.Stage) error {
+	db := persistence.MustGetDBSession()
+	query := `
+	insert into app_promotion_stage (app_id, target_cluster_id, source_cluster_id, min_soak_seconds, require_preflights, require_no_drift)
+	values (?, ?, ?, ?, ?, ?)
+	on conflict (app_id, target_cluster_id) do update set
+	  source_cluster_id = EXCLUDED.source_cluster_id,
+	  min_soak_seconds = EXCLUDED.min_soak_seconds,
+	  require_preflights = EXCLUDED.require_preflights,
+	  require_no_drift = EXCLUDED.require_no_drift`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{stage.AppID, stage.TargetClusterID, stage.SourceClusterID, stage.MinSoakSeconds, stage.RequirePreflights, stage.RequireNoDrift},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+func (s *KOTSStore) DeletePromotionStage(appID string, targetClusterID string) error {
+	db := persistence.MustGetDBSession()
+	query := `delete from app_promotion_stage where app_id = ? and target_cluster_id = ?`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{appID, targetClusterID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+func (s *KOTSStore) CreatePromotion(promotion *promotiontypes.Promotion) error {
+	if promotion.ID == "" {
+		promotion.ID = ksuid.New().String()
+	}
+	if promotion.PromotedAt.IsZero() {
+		promotion.PromotedAt = time.Now()
+	}
+
+	gates, err := json.Marshal(promotion.Gates)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal gates")
+	}
+
+	db := persistence.MustGetDBSession()
+	query := `insert into app_promotion (id, app_id, sequence, source_cluster_id, target_cluster_id, promoted_at, gates) values (?, ?, ?, ?, ?, ?, ?)`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{promotion.ID, promotion.AppID, promotion.Sequence, promotion.SourceClusterID, promotion.TargetClusterID, promotion.PromotedAt.Unix(), string(gates)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
+// ListPromotions returns the promotions of an app into or out of a downstream, newest first.
+// If sequences is not empty, only promotions of those sequences are returned.
+func (s *KOTSStore) ListPromotions(appID string, clusterID string, sequences []int64) ([]promotiontypes.Promotion, error) {
+	query := `select id, sequence, source_cluster_id, target_cluster_id, promoted_at, gates from app_promotion where app_id = ? and (source_cluster_id = ? or target_cluster_id = ?)`
+	if len(sequences) > 0 {
+		sequencesToQuery := []string{}
+		for _, sequence := range sequences {
+			sequencesToQuery = append(sequencesToQuery, fmt.Sprintf("%d", sequence))
+		}
+		query += fmt.Sprintf(` and sequence in (%s)`, strings.Join(sequencesToQuery, ","))
+	}
+	query += ` order by promoted_at desc`
+
+	db := persistence.MustGetDBSession()
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{appID, clusterID, clusterID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+
+	promotions := []promotiontypes.Promotion{}
+	for rows.Next() {
+		promotion := promotiontypes.Promotion{
+			AppID: appID,
+		}
+		var promotedAt gorqlite.NullTime
+		var gates gorqlite.NullString
+		if err := rows.Scan(&promotion.ID, &promotion.Sequence, &promotion.SourceClusterID, &promotion.TargetClusterID, &promotedAt, &gates); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		if promotedAt.Valid {
+			promotion.PromotedAt = promotedAt.Time
+		}
+		if gates.String != "" {
+			if err := json.Unmarshal([]byte(gates.String), &promotion.Gates); err != nil {
+				return nil, errors.Wrap(err, "failed to unmarshal gates")
+			}
+		}
+		promotions = append(promotions, promotion)
+	}
+
+	return promotions, nil
+}
+
+// addDownstreamVersionsPromotions adds the promotions of each version into or out of the downstream
+func (s *KOTSStore) addDownstreamVersionsPromotions(appID string, clusterID string, versions []*downstreamtypes.DownstreamVersion) error {
+	sequences := []int64{}
+	for _, v := range versions {
+		if v == nil {
+			continue
+		}
+		sequences = append(sequences, v.Sequence)
+	}
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	promotions, err := s.ListPromotions(appID, clusterID, sequences)
+	if err != nil {
+		return errors.Wrap(err, "failed to list promotions")
+	}
+
+	for _, v := range versions {
+		if v == nil {
+			continue
+		}
+		for _, p := range promotions {
+			if p.Sequence == v.Sequence {
+				v.Promotions = append(v.Promotions, p)
+			}
+		}
+	}
+
+	return nil
+}
@@ -332,3 +332,28 @@ func (s *KOTSStore) DeleteExpiredSessions() error {
 
 	return nil
 }
+
+// CountActiveSessions returns the number of sessions that have not expired
+func (s *KOTSStore) CountActiveSessions() (int64, error) {
+	sessionLock.Lock()
+	defer sessionLock.Unlock()
+
+	secret, err := s.getSessionSecret()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get session secret")
+	}
+
+	var count int64
+	for _, data := range secret.Data {
+		session := sessiontypes.Session{}
+		if err := json.Unmarshal(data, &session); err != nil {
+			logger.Error(errors.Wrap(err, "failed to unmarshal session while counting active sessions"))
+			continue
+		}
+		if time.Now().Before(session.ExpiresAt) {
+			count++
+		}
+	}
+
+	return count, nil
+}
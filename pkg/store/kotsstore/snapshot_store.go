@@ -1,6 +1,7 @@
 package kotsstore
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -183,3 +184,55 @@ func (s *KOTSStore) CreateScheduledInstanceSnapshot(id string, clusterID string,
 
 	return nil
 }
+
+func (s *KOTSStore) GetInstanceSnapshotVerificationPolicy(clusterID string) (*snapshottypes.BackupVerificationPolicy, error) {
+	db := persistence.MustGetDBSession()
+	query := `select snapshot_verification_policy from cluster where id = ?`
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{clusterID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+	if !rows.Next() {
+		return nil, ErrNotFound
+	}
+
+	var policyStr gorqlite.NullString
+	if err := rows.Scan(&policyStr); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+
+	policy := snapshottypes.BackupVerificationPolicy{}
+	if policyStr.String != "" {
+		if err := json.Unmarshal([]byte(policyStr.String), &policy); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal snapshot verification policy")
+		}
+	}
+
+	return &policy, nil
+}
+
+func (s *KOTSStore) SetInstanceSnapshotVerificationPolicy(clusterID string, policy snapshottypes.BackupVerificationPolicy) error {
+	logger.Debug("Setting instance snapshot verification policy",
+		zap.String("clusterID", clusterID))
+
+	db := persistence.MustGetDBSession()
+
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal snapshot verification policy")
+	}
+
+	query := `update cluster set snapshot_verification_policy = ? where id = ?`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{string(b), clusterID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
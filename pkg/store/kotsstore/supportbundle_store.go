@@ -573,6 +573,55 @@ func (s *KOTSStore) SetRedactions(id string, redacts troubleshootredact.Redactio
 	return nil
 }
 
+func (s *KOTSStore) GetAppSupportBundlePolicy(appID string) (*types.SupportBundlePolicy, error) {
+	db := persistence.MustGetDBSession()
+	query := `select support_bundle_policy from app where id = ?`
+	rows, err := db.QueryOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{appID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %v: %v", err, rows.Err)
+	}
+	if !rows.Next() {
+		return nil, ErrNotFound
+	}
+
+	var policyStr gorqlite.NullString
+	if err := rows.Scan(&policyStr); err != nil {
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+
+	policy := types.SupportBundlePolicy{}
+	if policyStr.String != "" {
+		if err := json.Unmarshal([]byte(policyStr.String), &policy); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal support bundle policy")
+		}
+	}
+
+	return &policy, nil
+}
+
+func (s *KOTSStore) SetAppSupportBundlePolicy(appID string, policy types.SupportBundlePolicy) error {
+	db := persistence.MustGetDBSession()
+
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal support bundle policy")
+	}
+
+	query := `update app set support_bundle_policy = ? where id = ?`
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     query,
+		Arguments: []interface{}{string(b), appID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
 func (s *KOTSStore) saveSupportBundleMetafile(id string, filename string, data []byte) error {
 	var gzipped bytes.Buffer
 	gzipWriter := gzip.NewWriter(&gzipped)
@@ -383,7 +383,7 @@ func (s *KOTSStore) CreatePendingDownloadAppVersion(appID string, update upstrea
 	for _, d := range downstreams {
 		downstreamVersionStatements, err := s.upsertAppDownstreamVersionStatements(a.ID, d.ClusterID, newSequence,
 			kotsKinds.Installation.Spec.VersionLabel, types.VersionPendingDownload,
-			"Upstream Update", "", "", "", false, false)
+			"Upstream Update", "", "", "", "", false, false)
 		if err != nil {
 			return 0, errors.Wrap(err, "failed to construct app downstream version statements")
 		}
@@ -427,6 +427,39 @@ func (s *KOTSStore) UpdateAppVersion(appID string, sequence int64, baseSequence
 	return nil
 }
 
+// UpdateAppVersionArchive replaces the archive of an existing version and the kots kinds that are cached from it.
+// Unlike UpdateAppVersion, the downstream status, diff and gitops commit of the version are left as they are.
+func (s *KOTSStore) UpdateAppVersionArchive(appID string, sequence int64, archiveDir string) error {
+	kotsKinds, err := kotsutil.LoadKotsKinds(archiveDir)
+	if err != nil {
+		return errors.Wrap(err, "failed to read kots kinds")
+	}
+
+	configValuesSpec, err := kotsKinds.Marshal("kots.io", "v1beta1", "ConfigValues")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal configvalues spec")
+	}
+	kotsInstallationSpec, err := kotsKinds.Marshal("kots.io", "v1beta1", "Installation")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal kots installation spec")
+	}
+
+	if err := apparchive.CreateAppVersionArchive(archiveDir, fmt.Sprintf("%s/%d.tar.gz", appID, sequence)); err != nil {
+		return errors.Wrap(err, "failed to create app version archive")
+	}
+
+	db := persistence.MustGetDBSession()
+	wr, err := db.WriteOneParameterized(gorqlite.ParameterizedStatement{
+		Query:     `update app_version set config_values = ?, kots_installation_spec = ?, encryption_key = ? where app_id = ? and sequence = ?`,
+		Arguments: []interface{}{configValuesSpec, kotsInstallationSpec, kotsKinds.Installation.Spec.EncryptionKey, appID, sequence},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
+	}
+
+	return nil
+}
+
 func (s *KOTSStore) CreateAppVersion(appID string, baseSequence *int64, filesInDir string, source string, isInstall bool, isAutomated bool, configFile string, skipPreflights bool, renderer rendertypes.Renderer) (int64, error) {
 	db := persistence.MustGetDBSession()
 	appVersionStatements, newSequence, err := s.createAppVersionStatements(appID, baseSequence, filesInDir, source, isInstall, isAutomated, configFile, skipPreflights, renderer)
@@ -548,14 +581,14 @@ func (s *KOTSStore) upsertAppVersionStatements(appID string, sequence int64, bas
 			}
 		}
 
-		commitURL, err := gitops.CreateGitOpsDownstreamCommit(a, d.ClusterID, int(sequence), filesInDir, d.Name)
+		gitOpsCommit, err := gitops.CreateGitOpsDownstreamCommit(a, d.ClusterID, int(sequence), filesInDir, d.Name)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create gitops commit")
 		}
 
 		downstreamVersionStatements, err := s.upsertAppDownstreamVersionStatements(appID, d.ClusterID, sequence,
 			kotsKinds.Installation.Spec.VersionLabel, downstreamStatus,
-			source, diffSummary, diffSummaryError, commitURL, commitURL != "", skipPreflights)
+			source, diffSummary, diffSummaryError, gitOpsCommit.CommitURL, gitOpsCommit.PullRequestURL, gitOpsCommit.CommitURL != "", skipPreflights)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to construct app downstream version statements")
 		}
@@ -702,8 +735,8 @@ func (s *KOTSStore) upsertAppVersionRecordStatements(appID string, sequence int6
 
 	// an old version could be downloaded at a later point, pick higher sequence
 	statements = append(statements, gorqlite.ParameterizedStatement{
-		Query:     "update app set current_sequence = ifnull(max(current_sequence, ?), 0), name = ?, icon_uri = ? where id = ?",
-		Arguments: []interface{}{sequence, appName, appIcon, appID},
+		Query:     "update app set current_sequence = case when current_sequence is null then 0 when current_sequence > ? then current_sequence else ? end, name = ?, icon_uri = ? where id = ?",
+		Arguments: []interface{}{sequence, sequence, appName, appIcon, appID},
 	})
 
 	return statements, nil
@@ -752,11 +785,11 @@ func determineDownstreamVersionStatus(s store.Store, a *apptypes.App, sequence i
 	return types.VersionPending, nil
 }
 
-func (s *KOTSStore) upsertAppDownstreamVersionStatements(appID string, clusterID string, sequence int64, versionLabel string, status types.DownstreamVersionStatus, source string, diffSummary string, diffSummaryError string, commitURL string, gitDeployable bool, preflightsSkipped bool) ([]gorqlite.ParameterizedStatement, error) {
+func (s *KOTSStore) upsertAppDownstreamVersionStatements(appID string, clusterID string, sequence int64, versionLabel string, status types.DownstreamVersionStatus, source string, diffSummary string, diffSummaryError string, commitURL string, pullRequestURL string, gitDeployable bool, preflightsSkipped bool) ([]gorqlite.ParameterizedStatement, error) {
 	statements := []gorqlite.ParameterizedStatement{}
 
-	query := `insert into app_downstream_version (app_id, cluster_id, sequence, parent_sequence, created_at, version_label, status, source, diff_summary, diff_summary_error, git_commit_url, git_deployable, preflight_skipped)
-		values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	query := `insert into app_downstream_version (app_id, cluster_id, sequence, parent_sequence, created_at, version_label, status, source, diff_summary, diff_summary_error, git_commit_url, git_pull_request_url, git_deployable, preflight_skipped)
+		values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(app_id, cluster_id, sequence) DO UPDATE SET
 		created_at = EXCLUDED.created_at,
 		version_label = EXCLUDED.version_label,
@@ -765,6 +798,7 @@ func (s *KOTSStore) upsertAppDownstreamVersionStatements(appID string, clusterID
 		diff_summary = EXCLUDED.diff_summary,
 		diff_summary_error = EXCLUDED.diff_summary_error,
 		git_commit_url = EXCLUDED.git_commit_url,
+		git_pull_request_url = EXCLUDED.git_pull_request_url,
 		git_deployable = EXCLUDED.git_deployable,
 		preflight_skipped= EXCLUDED.preflight_skipped`
 
@@ -782,6 +816,7 @@ func (s *KOTSStore) upsertAppDownstreamVersionStatements(appID string, clusterID
 			diffSummary,
 			diffSummaryError,
 			commitURL,
+			pullRequestURL,
 			gitDeployable,
 			preflightsSkipped,
 		},
@@ -976,7 +1011,7 @@ func (s *KOTSStore) HasStrictPreflights(appID string, sequence int64) (bool, err
 	return s.hasStrictPreflights(preflightSpecStr)
 }
 
-func (s *KOTSStore) appVersionFromRow(row gorqlite.QueryResult) (*versiontypes.AppVersion, error) {
+func (s *KOTSStore) appVersionFromRow(row persistence.QueryResult) (*versiontypes.AppVersion, error) {
 	v := &versiontypes.AppVersion{}
 
 	var status gorqlite.NullString
@@ -17,9 +17,16 @@ import (
 	types2 "github.com/replicatedhq/kots/pkg/api/version/types"
 	types3 "github.com/replicatedhq/kots/pkg/app/types"
 	types4 "github.com/replicatedhq/kots/pkg/appstate/types"
+	types15 "github.com/replicatedhq/kots/pkg/audit/types"
+	types21 "github.com/replicatedhq/kots/pkg/image/types"
+	types20 "github.com/replicatedhq/kots/pkg/keyrotation/types"
 	types5 "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	types17 "github.com/replicatedhq/kots/pkg/maintenancewindow/types"
+	types16 "github.com/replicatedhq/kots/pkg/notifications/types"
 	types6 "github.com/replicatedhq/kots/pkg/online/types"
+	types18 "github.com/replicatedhq/kots/pkg/operator/types"
 	types7 "github.com/replicatedhq/kots/pkg/preflight/types"
+	types19 "github.com/replicatedhq/kots/pkg/promotion/types"
 	types8 "github.com/replicatedhq/kots/pkg/registry/types"
 	types9 "github.com/replicatedhq/kots/pkg/render/types"
 	types10 "github.com/replicatedhq/kots/pkg/session/types"
@@ -68,6 +75,20 @@ func (mr *MockStoreMockRecorder) AddAppToAllDownstreams(appID interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAppToAllDownstreams", reflect.TypeOf((*MockStore)(nil).AddAppToAllDownstreams), appID)
 }
 
+// AddAppsToDownstream mocks base method.
+func (m *MockStore) AddAppsToDownstream(clusterID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAppsToDownstream", clusterID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAppsToDownstream indicates an expected call of AddAppsToDownstream.
+func (mr *MockStoreMockRecorder) AddAppsToDownstream(clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAppsToDownstream", reflect.TypeOf((*MockStore)(nil).AddAppsToDownstream), clusterID)
+}
+
 // AddDownstreamVersionDetails mocks base method.
 func (m *MockStore) AddDownstreamVersionDetails(appID, clusterID string, version *types0.DownstreamVersion, checkIfDeployable bool) error {
 	m.ctrl.T.Helper()
@@ -96,6 +117,21 @@ func (mr *MockStoreMockRecorder) AddDownstreamVersionsDetails(appID, clusterID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDownstreamVersionsDetails", reflect.TypeOf((*MockStore)(nil).AddDownstreamVersionsDetails), appID, clusterID, versions, checkIfDeployable)
 }
 
+// CountActiveSessions mocks base method.
+func (m *MockStore) CountActiveSessions() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountActiveSessions")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountActiveSessions indicates an expected call of CountActiveSessions.
+func (mr *MockStoreMockRecorder) CountActiveSessions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountActiveSessions", reflect.TypeOf((*MockStore)(nil).CountActiveSessions))
+}
+
 // CreateApp mocks base method.
 func (m *MockStore) CreateApp(name, channelID, upstreamURI, licenseData string, isAirgapEnabled, skipImagePush, registryIsReadOnly bool) (*types3.App, error) {
 	m.ctrl.T.Helper()
@@ -126,6 +162,20 @@ func (mr *MockStoreMockRecorder) CreateAppVersion(appID, baseSequence, filesInDi
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAppVersion", reflect.TypeOf((*MockStore)(nil).CreateAppVersion), appID, baseSequence, filesInDir, source, isInstall, isAutomated, configFile, skipPreflights, renderer)
 }
 
+// CreateAuditLogEntry mocks base method.
+func (m *MockStore) CreateAuditLogEntry(entry types15.AuditLogEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditLogEntry", entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditLogEntry indicates an expected call of CreateAuditLogEntry.
+func (mr *MockStoreMockRecorder) CreateAuditLogEntry(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditLogEntry", reflect.TypeOf((*MockStore)(nil).CreateAuditLogEntry), entry)
+}
+
 // CreateInProgressSupportBundle mocks base method.
 func (m *MockStore) CreateInProgressSupportBundle(supportBundle *types12.SupportBundle) error {
 	m.ctrl.T.Helper()
@@ -185,6 +235,20 @@ func (mr *MockStoreMockRecorder) CreatePendingDownloadAppVersion(appID, update,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePendingDownloadAppVersion", reflect.TypeOf((*MockStore)(nil).CreatePendingDownloadAppVersion), appID, update, kotsApplication, license)
 }
 
+// CreatePromotion mocks base method.
+func (m *MockStore) CreatePromotion(promotion *types19.Promotion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromotion", promotion)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePromotion indicates an expected call of CreatePromotion.
+func (mr *MockStoreMockRecorder) CreatePromotion(promotion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromotion", reflect.TypeOf((*MockStore)(nil).CreatePromotion), promotion)
+}
+
 // CreateScheduledInstanceSnapshot mocks base method.
 func (m *MockStore) CreateScheduledInstanceSnapshot(snapshotID, clusterID string, timestamp time.Time) error {
 	m.ctrl.T.Helper()
@@ -243,6 +307,35 @@ func (mr *MockStoreMockRecorder) CreateSupportBundle(bundleID, appID, archivePat
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSupportBundle", reflect.TypeOf((*MockStore)(nil).CreateSupportBundle), bundleID, appID, archivePath, marshalledTree)
 }
 
+// CreateWebhookDelivery mocks base method.
+func (m *MockStore) CreateWebhookDelivery(delivery types16.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDelivery", delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookDelivery indicates an expected call of CreateWebhookDelivery.
+func (mr *MockStoreMockRecorder) CreateWebhookDelivery(delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDelivery", reflect.TypeOf((*MockStore)(nil).CreateWebhookDelivery), delivery)
+}
+
+// CreateWebhookTarget mocks base method.
+func (m *MockStore) CreateWebhookTarget(target types16.WebhookTarget) (*types16.WebhookTarget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookTarget", target)
+	ret0, _ := ret[0].(*types16.WebhookTarget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookTarget indicates an expected call of CreateWebhookTarget.
+func (mr *MockStoreMockRecorder) CreateWebhookTarget(target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookTarget", reflect.TypeOf((*MockStore)(nil).CreateWebhookTarget), target)
+}
+
 // DeleteDownstreamDeployStatus mocks base method.
 func (m *MockStore) DeleteDownstreamDeployStatus(appID, clusterID string, sequence int64) error {
 	m.ctrl.T.Helper()
@@ -299,6 +392,34 @@ func (mr *MockStoreMockRecorder) DeletePendingScheduledSnapshots(appID interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingScheduledSnapshots", reflect.TypeOf((*MockStore)(nil).DeletePendingScheduledSnapshots), appID)
 }
 
+// DeletePromotionStage mocks base method.
+func (m *MockStore) DeletePromotionStage(appID string, targetClusterID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePromotionStage", appID, targetClusterID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePromotionStage indicates an expected call of DeletePromotionStage.
+func (mr *MockStoreMockRecorder) DeletePromotionStage(appID, targetClusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePromotionStage", reflect.TypeOf((*MockStore)(nil).DeletePromotionStage), appID, targetClusterID)
+}
+
+// DeleteQueuedDeploy mocks base method.
+func (m *MockStore) DeleteQueuedDeploy(appID string, clusterID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteQueuedDeploy", appID, clusterID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteQueuedDeploy indicates an expected call of DeleteQueuedDeploy.
+func (mr *MockStoreMockRecorder) DeleteQueuedDeploy(appID, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteQueuedDeploy", reflect.TypeOf((*MockStore)(nil).DeleteQueuedDeploy), appID, clusterID)
+}
+
 // DeleteSession mocks base method.
 func (m *MockStore) DeleteSession(sessionID string) error {
 	m.ctrl.T.Helper()
@@ -327,6 +448,20 @@ func (mr *MockStoreMockRecorder) DeleteSupportBundle(bundleID, appID interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSupportBundle", reflect.TypeOf((*MockStore)(nil).DeleteSupportBundle), bundleID, appID)
 }
 
+// DeleteWebhookTarget mocks base method.
+func (m *MockStore) DeleteWebhookTarget(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookTarget", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookTarget indicates an expected call of DeleteWebhookTarget.
+func (mr *MockStoreMockRecorder) DeleteWebhookTarget(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookTarget", reflect.TypeOf((*MockStore)(nil).DeleteWebhookTarget), id)
+}
+
 // FindDownstreamVersions mocks base method.
 func (m *MockStore) FindDownstreamVersions(appID string, downloadedOnly bool) (*types0.DownstreamVersions, error) {
 	m.ctrl.T.Helper()
@@ -415,6 +550,36 @@ func (mr *MockStoreMockRecorder) GetApp(appID interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApp", reflect.TypeOf((*MockStore)(nil).GetApp), appID)
 }
 
+// GetAppDriftAutoRemediate mocks base method.
+func (m *MockStore) GetAppDriftAutoRemediate(appID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppDriftAutoRemediate", appID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppDriftAutoRemediate indicates an expected call of GetAppDriftAutoRemediate.
+func (mr *MockStoreMockRecorder) GetAppDriftAutoRemediate(appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppDriftAutoRemediate", reflect.TypeOf((*MockStore)(nil).GetAppDriftAutoRemediate), appID)
+}
+
+// GetAppDriftStatus mocks base method.
+func (m *MockStore) GetAppDriftStatus(appID string) (*types18.AppDriftStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppDriftStatus", appID)
+	ret0, _ := ret[0].(*types18.AppDriftStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppDriftStatus indicates an expected call of GetAppDriftStatus.
+func (mr *MockStoreMockRecorder) GetAppDriftStatus(appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppDriftStatus", reflect.TypeOf((*MockStore)(nil).GetAppDriftStatus), appID)
+}
+
 // GetAppFromSlug mocks base method.
 func (m *MockStore) GetAppFromSlug(slug string) (*types3.App, error) {
 	m.ctrl.T.Helper()
@@ -460,6 +625,21 @@ func (mr *MockStoreMockRecorder) GetAppIDsFromRegistry(hostname interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppIDsFromRegistry", reflect.TypeOf((*MockStore)(nil).GetAppIDsFromRegistry), hostname)
 }
 
+// GetAppMaintenanceWindows mocks base method.
+func (m *MockStore) GetAppMaintenanceWindows(appID string) ([]types17.MaintenanceWindow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppMaintenanceWindows", appID)
+	ret0, _ := ret[0].([]types17.MaintenanceWindow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppMaintenanceWindows indicates an expected call of GetAppMaintenanceWindows.
+func (mr *MockStoreMockRecorder) GetAppMaintenanceWindows(appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppMaintenanceWindows", reflect.TypeOf((*MockStore)(nil).GetAppMaintenanceWindows), appID)
+}
+
 // GetAppStatus mocks base method.
 func (m *MockStore) GetAppStatus(appID string) (*types4.AppStatus, error) {
 	m.ctrl.T.Helper()
@@ -475,6 +655,21 @@ func (mr *MockStoreMockRecorder) GetAppStatus(appID interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppStatus", reflect.TypeOf((*MockStore)(nil).GetAppStatus), appID)
 }
 
+// GetAppSupportBundlePolicy mocks base method.
+func (m *MockStore) GetAppSupportBundlePolicy(appID string) (*types12.SupportBundlePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppSupportBundlePolicy", appID)
+	ret0, _ := ret[0].(*types12.SupportBundlePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppSupportBundlePolicy indicates an expected call of GetAppSupportBundlePolicy.
+func (mr *MockStoreMockRecorder) GetAppSupportBundlePolicy(appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppSupportBundlePolicy", reflect.TypeOf((*MockStore)(nil).GetAppSupportBundlePolicy), appID)
+}
+
 // GetAppVersion mocks base method.
 func (m *MockStore) GetAppVersion(appID string, sequence int64) (*types2.AppVersion, error) {
 	m.ctrl.T.Helper()
@@ -535,6 +730,21 @@ func (mr *MockStoreMockRecorder) GetAppVersionBaseSequence(appID, versionLabel i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppVersionBaseSequence", reflect.TypeOf((*MockStore)(nil).GetAppVersionBaseSequence), appID, versionLabel)
 }
 
+// GetCluster mocks base method.
+func (m *MockStore) GetCluster(clusterID string) (*types0.Downstream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCluster", clusterID)
+	ret0, _ := ret[0].(*types0.Downstream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCluster indicates an expected call of GetCluster.
+func (mr *MockStoreMockRecorder) GetCluster(clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCluster", reflect.TypeOf((*MockStore)(nil).GetCluster), clusterID)
+}
+
 // GetClusterIDFromDeployToken mocks base method.
 func (m *MockStore) GetClusterIDFromDeployToken(deployToken string) (string, error) {
 	m.ctrl.T.Helper()
@@ -640,6 +850,21 @@ func (mr *MockStoreMockRecorder) GetDownstream(clusterID interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDownstream", reflect.TypeOf((*MockStore)(nil).GetDownstream), clusterID)
 }
 
+// GetDownstreamAppStatus mocks base method.
+func (m *MockStore) GetDownstreamAppStatus(appID string, clusterID string) (*types4.AppStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDownstreamAppStatus", appID, clusterID)
+	ret0, _ := ret[0].(*types4.AppStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDownstreamAppStatus indicates an expected call of GetDownstreamAppStatus.
+func (mr *MockStoreMockRecorder) GetDownstreamAppStatus(appID, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDownstreamAppStatus", reflect.TypeOf((*MockStore)(nil).GetDownstreamAppStatus), appID, clusterID)
+}
+
 // GetDownstreamOutput mocks base method.
 func (m *MockStore) GetDownstreamOutput(appID, clusterID string, sequence int64) (*types0.DownstreamOutput, error) {
 	m.ctrl.T.Helper()
@@ -775,6 +1000,21 @@ func (mr *MockStoreMockRecorder) GetIgnoreRBACErrors(appID, sequence interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIgnoreRBACErrors", reflect.TypeOf((*MockStore)(nil).GetIgnoreRBACErrors), appID, sequence)
 }
 
+// GetImageVerificationResults mocks base method.
+func (m *MockStore) GetImageVerificationResults(appID string, sequence int64) ([]types21.ImageVerificationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageVerificationResults", appID, sequence)
+	ret0, _ := ret[0].([]types21.ImageVerificationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageVerificationResults indicates an expected call of GetImageVerificationResults.
+func (mr *MockStoreMockRecorder) GetImageVerificationResults(appID, sequence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageVerificationResults", reflect.TypeOf((*MockStore)(nil).GetImageVerificationResults), appID, sequence)
+}
+
 // GetInitialBranding mocks base method.
 func (m *MockStore) GetInitialBranding() ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -790,6 +1030,36 @@ func (mr *MockStoreMockRecorder) GetInitialBranding() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInitialBranding", reflect.TypeOf((*MockStore)(nil).GetInitialBranding))
 }
 
+// GetInstanceSnapshotVerificationPolicy mocks base method.
+func (m *MockStore) GetInstanceSnapshotVerificationPolicy(clusterID string) (*types5.BackupVerificationPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInstanceSnapshotVerificationPolicy", clusterID)
+	ret0, _ := ret[0].(*types5.BackupVerificationPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInstanceSnapshotVerificationPolicy indicates an expected call of GetInstanceSnapshotVerificationPolicy.
+func (mr *MockStoreMockRecorder) GetInstanceSnapshotVerificationPolicy(clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstanceSnapshotVerificationPolicy", reflect.TypeOf((*MockStore)(nil).GetInstanceSnapshotVerificationPolicy), clusterID)
+}
+
+// GetLastAppStatusTransitionBefore mocks base method.
+func (m *MockStore) GetLastAppStatusTransitionBefore(appID string, clusterID string, before time.Time) (*types4.StatusTransition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastAppStatusTransitionBefore", appID, clusterID, before)
+	ret0, _ := ret[0].(*types4.StatusTransition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastAppStatusTransitionBefore indicates an expected call of GetLastAppStatusTransitionBefore.
+func (mr *MockStoreMockRecorder) GetLastAppStatusTransitionBefore(appID, clusterID, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastAppStatusTransitionBefore", reflect.TypeOf((*MockStore)(nil).GetLastAppStatusTransitionBefore), appID, clusterID, before)
+}
+
 // GetLatestAppSequence mocks base method.
 func (m *MockStore) GetLatestAppSequence(appID string, downloadedOnly bool) (int64, error) {
 	m.ctrl.T.Helper()
@@ -1017,6 +1287,36 @@ func (mr *MockStoreMockRecorder) GetPrometheusAddress() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrometheusAddress", reflect.TypeOf((*MockStore)(nil).GetPrometheusAddress))
 }
 
+// GetPromotionStage mocks base method.
+func (m *MockStore) GetPromotionStage(appID string, targetClusterID string) (*types19.Stage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromotionStage", appID, targetClusterID)
+	ret0, _ := ret[0].(*types19.Stage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromotionStage indicates an expected call of GetPromotionStage.
+func (mr *MockStoreMockRecorder) GetPromotionStage(appID, targetClusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromotionStage", reflect.TypeOf((*MockStore)(nil).GetPromotionStage), appID, targetClusterID)
+}
+
+// GetQueuedDeploy mocks base method.
+func (m *MockStore) GetQueuedDeploy(appID string, clusterID string) (*types17.QueuedDeploy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQueuedDeploy", appID, clusterID)
+	ret0, _ := ret[0].(*types17.QueuedDeploy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQueuedDeploy indicates an expected call of GetQueuedDeploy.
+func (mr *MockStoreMockRecorder) GetQueuedDeploy(appID, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQueuedDeploy", reflect.TypeOf((*MockStore)(nil).GetQueuedDeploy), appID, clusterID)
+}
+
 // GetRedactions mocks base method.
 func (m *MockStore) GetRedactions(bundleID string) (redact.RedactionList, error) {
 	m.ctrl.T.Helper()
@@ -1152,6 +1452,21 @@ func (mr *MockStoreMockRecorder) GetTargetKotsVersionForVersion(appID, sequence
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTargetKotsVersionForVersion", reflect.TypeOf((*MockStore)(nil).GetTargetKotsVersionForVersion), appID, sequence)
 }
 
+// GetWebhookTarget mocks base method.
+func (m *MockStore) GetWebhookTarget(id string) (*types16.WebhookTarget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookTarget", id)
+	ret0, _ := ret[0].(*types16.WebhookTarget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookTarget indicates an expected call of GetWebhookTarget.
+func (mr *MockStoreMockRecorder) GetWebhookTarget(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookTarget", reflect.TypeOf((*MockStore)(nil).GetWebhookTarget), id)
+}
+
 // HasStrictPreflights mocks base method.
 func (m *MockStore) HasStrictPreflights(appID string, sequence int64) (bool, error) {
 	m.ctrl.T.Helper()
@@ -1301,6 +1616,21 @@ func (mr *MockStoreMockRecorder) IsSnapshotsSupportedForVersion(a, sequence, ren
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSnapshotsSupportedForVersion", reflect.TypeOf((*MockStore)(nil).IsSnapshotsSupportedForVersion), a, sequence, renderer)
 }
 
+// ListAppStatusTransitions mocks base method.
+func (m *MockStore) ListAppStatusTransitions(opts types4.ListStatusTransitionsOptions) ([]types4.StatusTransition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAppStatusTransitions", opts)
+	ret0, _ := ret[0].([]types4.StatusTransition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAppStatusTransitions indicates an expected call of ListAppStatusTransitions.
+func (mr *MockStoreMockRecorder) ListAppStatusTransitions(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppStatusTransitions", reflect.TypeOf((*MockStore)(nil).ListAppStatusTransitions), opts)
+}
+
 // ListAppsForDownstream mocks base method.
 func (m *MockStore) ListAppsForDownstream(clusterID string) ([]*types3.App, error) {
 	m.ctrl.T.Helper()
@@ -1316,6 +1646,21 @@ func (mr *MockStoreMockRecorder) ListAppsForDownstream(clusterID interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppsForDownstream", reflect.TypeOf((*MockStore)(nil).ListAppsForDownstream), clusterID)
 }
 
+// ListAuditLogEntries mocks base method.
+func (m *MockStore) ListAuditLogEntries(opts types15.ListAuditLogOptions) (*types15.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditLogEntries", opts)
+	ret0, _ := ret[0].(*types15.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditLogEntries indicates an expected call of ListAuditLogEntries.
+func (mr *MockStoreMockRecorder) ListAuditLogEntries(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditLogEntries", reflect.TypeOf((*MockStore)(nil).ListAuditLogEntries), opts)
+}
+
 // ListClusters mocks base method.
 func (m *MockStore) ListClusters() ([]*types0.Downstream, error) {
 	m.ctrl.T.Helper()
@@ -1346,6 +1691,21 @@ func (mr *MockStoreMockRecorder) ListDownstreamsForApp(appID interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDownstreamsForApp", reflect.TypeOf((*MockStore)(nil).ListDownstreamsForApp), appID)
 }
 
+// ListEncryptedValues mocks base method.
+func (m *MockStore) ListEncryptedValues() ([]types20.EncryptedValue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEncryptedValues")
+	ret0, _ := ret[0].([]types20.EncryptedValue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEncryptedValues indicates an expected call of ListEncryptedValues.
+func (mr *MockStoreMockRecorder) ListEncryptedValues() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEncryptedValues", reflect.TypeOf((*MockStore)(nil).ListEncryptedValues))
+}
+
 // ListFailedApps mocks base method.
 func (m *MockStore) ListFailedApps() ([]*types3.App, error) {
 	m.ctrl.T.Helper()
@@ -1421,6 +1781,51 @@ func (mr *MockStoreMockRecorder) ListPendingScheduledSnapshots(appID interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingScheduledSnapshots", reflect.TypeOf((*MockStore)(nil).ListPendingScheduledSnapshots), appID)
 }
 
+// ListPromotionStages mocks base method.
+func (m *MockStore) ListPromotionStages(appID string) ([]types19.Stage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromotionStages", appID)
+	ret0, _ := ret[0].([]types19.Stage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromotionStages indicates an expected call of ListPromotionStages.
+func (mr *MockStoreMockRecorder) ListPromotionStages(appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromotionStages", reflect.TypeOf((*MockStore)(nil).ListPromotionStages), appID)
+}
+
+// ListPromotions mocks base method.
+func (m *MockStore) ListPromotions(appID string, clusterID string, sequences []int64) ([]types19.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromotions", appID, clusterID, sequences)
+	ret0, _ := ret[0].([]types19.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromotions indicates an expected call of ListPromotions.
+func (mr *MockStoreMockRecorder) ListPromotions(appID, clusterID, sequences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromotions", reflect.TypeOf((*MockStore)(nil).ListPromotions), appID, clusterID, sequences)
+}
+
+// ListQueuedDeploys mocks base method.
+func (m *MockStore) ListQueuedDeploys() ([]types17.QueuedDeploy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListQueuedDeploys")
+	ret0, _ := ret[0].([]types17.QueuedDeploy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListQueuedDeploys indicates an expected call of ListQueuedDeploys.
+func (mr *MockStoreMockRecorder) ListQueuedDeploys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListQueuedDeploys", reflect.TypeOf((*MockStore)(nil).ListQueuedDeploys))
+}
+
 // ListSupportBundles mocks base method.
 func (m *MockStore) ListSupportBundles(appID string) ([]*types12.SupportBundle, error) {
 	m.ctrl.T.Helper()
@@ -1436,6 +1841,36 @@ func (mr *MockStoreMockRecorder) ListSupportBundles(appID interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSupportBundles", reflect.TypeOf((*MockStore)(nil).ListSupportBundles), appID)
 }
 
+// ListWebhookDeliveries mocks base method.
+func (m *MockStore) ListWebhookDeliveries(targetID string, currentPage int, pageSize int) (*types16.WebhookDeliveries, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", targetID, currentPage, pageSize)
+	ret0, _ := ret[0].(*types16.WebhookDeliveries)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockStoreMockRecorder) ListWebhookDeliveries(targetID, currentPage, pageSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).ListWebhookDeliveries), targetID, currentPage, pageSize)
+}
+
+// ListWebhookTargets mocks base method.
+func (m *MockStore) ListWebhookTargets() ([]types16.WebhookTarget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookTargets")
+	ret0, _ := ret[0].([]types16.WebhookTarget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookTargets indicates an expected call of ListWebhookTargets.
+func (mr *MockStoreMockRecorder) ListWebhookTargets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookTargets", reflect.TypeOf((*MockStore)(nil).ListWebhookTargets))
+}
+
 // MarkAsCurrentDownstreamVersion mocks base method.
 func (m *MockStore) MarkAsCurrentDownstreamVersion(appID string, sequence int64) error {
 	m.ctrl.T.Helper()
@@ -1450,6 +1885,20 @@ func (mr *MockStoreMockRecorder) MarkAsCurrentDownstreamVersion(appID, sequence
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsCurrentDownstreamVersion", reflect.TypeOf((*MockStore)(nil).MarkAsCurrentDownstreamVersion), appID, sequence)
 }
 
+// MarkAsCurrentDownstreamVersionForCluster mocks base method.
+func (m *MockStore) MarkAsCurrentDownstreamVersionForCluster(appID string, clusterID string, sequence int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAsCurrentDownstreamVersionForCluster", appID, clusterID, sequence)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAsCurrentDownstreamVersionForCluster indicates an expected call of MarkAsCurrentDownstreamVersionForCluster.
+func (mr *MockStoreMockRecorder) MarkAsCurrentDownstreamVersionForCluster(appID, clusterID, sequence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsCurrentDownstreamVersionForCluster", reflect.TypeOf((*MockStore)(nil).MarkAsCurrentDownstreamVersionForCluster), appID, clusterID, sequence)
+}
+
 // RemoveApp mocks base method.
 func (m *MockStore) RemoveApp(appID string) error {
 	m.ctrl.T.Helper()
@@ -1464,6 +1913,20 @@ func (mr *MockStoreMockRecorder) RemoveApp(appID interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveApp", reflect.TypeOf((*MockStore)(nil).RemoveApp), appID)
 }
 
+// RemoveCluster mocks base method.
+func (m *MockStore) RemoveCluster(clusterID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveCluster", clusterID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveCluster indicates an expected call of RemoveCluster.
+func (mr *MockStoreMockRecorder) RemoveCluster(clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCluster", reflect.TypeOf((*MockStore)(nil).RemoveCluster), clusterID)
+}
+
 // ResetAirgapInstallInProgress mocks base method.
 func (m *MockStore) ResetAirgapInstallInProgress(appID string) error {
 	m.ctrl.T.Helper()
@@ -1518,15 +1981,43 @@ func (mr *MockStoreMockRecorder) SetAppChannelChanged(appID, channelChanged inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppChannelChanged", reflect.TypeOf((*MockStore)(nil).SetAppChannelChanged), appID, channelChanged)
 }
 
-// SetAppInstallState mocks base method.
-func (m *MockStore) SetAppInstallState(appID, state string) error {
+// SetAppDriftAutoRemediate mocks base method.
+func (m *MockStore) SetAppDriftAutoRemediate(appID string, autoRemediate bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetAppInstallState", appID, state)
+	ret := m.ctrl.Call(m, "SetAppDriftAutoRemediate", appID, autoRemediate)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetAppInstallState indicates an expected call of SetAppInstallState.
+// SetAppDriftAutoRemediate indicates an expected call of SetAppDriftAutoRemediate.
+func (mr *MockStoreMockRecorder) SetAppDriftAutoRemediate(appID, autoRemediate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppDriftAutoRemediate", reflect.TypeOf((*MockStore)(nil).SetAppDriftAutoRemediate), appID, autoRemediate)
+}
+
+// SetAppDriftStatus mocks base method.
+func (m *MockStore) SetAppDriftStatus(appID string, sequence int64, resources []types18.DriftedResource, checkedAt time.Time, driftErr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAppDriftStatus", appID, sequence, resources, checkedAt, driftErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAppDriftStatus indicates an expected call of SetAppDriftStatus.
+func (mr *MockStoreMockRecorder) SetAppDriftStatus(appID, sequence, resources, checkedAt, driftErr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppDriftStatus", reflect.TypeOf((*MockStore)(nil).SetAppDriftStatus), appID, sequence, resources, checkedAt, driftErr)
+}
+
+// SetAppInstallState mocks base method.
+func (m *MockStore) SetAppInstallState(appID, state string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAppInstallState", appID, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAppInstallState indicates an expected call of SetAppInstallState.
 func (mr *MockStoreMockRecorder) SetAppInstallState(appID, state interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppInstallState", reflect.TypeOf((*MockStore)(nil).SetAppInstallState), appID, state)
@@ -1546,6 +2037,20 @@ func (mr *MockStoreMockRecorder) SetAppIsAirgap(appID, isAirgap interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppIsAirgap", reflect.TypeOf((*MockStore)(nil).SetAppIsAirgap), appID, isAirgap)
 }
 
+// SetAppMaintenanceWindows mocks base method.
+func (m *MockStore) SetAppMaintenanceWindows(appID string, windows []types17.MaintenanceWindow) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAppMaintenanceWindows", appID, windows)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAppMaintenanceWindows indicates an expected call of SetAppMaintenanceWindows.
+func (mr *MockStoreMockRecorder) SetAppMaintenanceWindows(appID, windows interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppMaintenanceWindows", reflect.TypeOf((*MockStore)(nil).SetAppMaintenanceWindows), appID, windows)
+}
+
 // SetAppSelectedChannelID mocks base method.
 func (m *MockStore) SetAppSelectedChannelID(appID, channelID string) error {
 	m.ctrl.T.Helper()
@@ -1574,6 +2079,20 @@ func (mr *MockStoreMockRecorder) SetAppStatus(appID, resourceStates, updatedAt,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppStatus", reflect.TypeOf((*MockStore)(nil).SetAppStatus), appID, resourceStates, updatedAt, sequence)
 }
 
+// SetAppSupportBundlePolicy mocks base method.
+func (m *MockStore) SetAppSupportBundlePolicy(appID string, policy types12.SupportBundlePolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAppSupportBundlePolicy", appID, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAppSupportBundlePolicy indicates an expected call of SetAppSupportBundlePolicy.
+func (mr *MockStoreMockRecorder) SetAppSupportBundlePolicy(appID, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppSupportBundlePolicy", reflect.TypeOf((*MockStore)(nil).SetAppSupportBundlePolicy), appID, policy)
+}
+
 // SetAutoDeploy mocks base method.
 func (m *MockStore) SetAutoDeploy(appID string, autoDeploy types3.AutoDeploy) error {
 	m.ctrl.T.Helper()
@@ -1588,6 +2107,48 @@ func (mr *MockStoreMockRecorder) SetAutoDeploy(appID, autoDeploy interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAutoDeploy", reflect.TypeOf((*MockStore)(nil).SetAutoDeploy), appID, autoDeploy)
 }
 
+// SetClusterConnection mocks base method.
+func (m *MockStore) SetClusterConnection(clusterID string, connectionType string, targetNamespace string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetClusterConnection", clusterID, connectionType, targetNamespace)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetClusterConnection indicates an expected call of SetClusterConnection.
+func (mr *MockStoreMockRecorder) SetClusterConnection(clusterID, connectionType, targetNamespace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetClusterConnection", reflect.TypeOf((*MockStore)(nil).SetClusterConnection), clusterID, connectionType, targetNamespace)
+}
+
+// SetDownstreamAppStatus mocks base method.
+func (m *MockStore) SetDownstreamAppStatus(appID string, clusterID string, resourceStates types4.ResourceStates, updatedAt time.Time, sequence int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDownstreamAppStatus", appID, clusterID, resourceStates, updatedAt, sequence)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDownstreamAppStatus indicates an expected call of SetDownstreamAppStatus.
+func (mr *MockStoreMockRecorder) SetDownstreamAppStatus(appID, clusterID, resourceStates, updatedAt, sequence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDownstreamAppStatus", reflect.TypeOf((*MockStore)(nil).SetDownstreamAppStatus), appID, clusterID, resourceStates, updatedAt, sequence)
+}
+
+// SetDownstreamRolloutLog mocks base method.
+func (m *MockStore) SetDownstreamRolloutLog(appID string, clusterID string, sequence int64, rolloutLog string, isError bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDownstreamRolloutLog", appID, clusterID, sequence, rolloutLog, isError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDownstreamRolloutLog indicates an expected call of SetDownstreamRolloutLog.
+func (mr *MockStoreMockRecorder) SetDownstreamRolloutLog(appID, clusterID, sequence, rolloutLog, isError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDownstreamRolloutLog", reflect.TypeOf((*MockStore)(nil).SetDownstreamRolloutLog), appID, clusterID, sequence, rolloutLog, isError)
+}
+
 // SetDownstreamVersionStatus mocks base method.
 func (m *MockStore) SetDownstreamVersionStatus(appID string, sequence int64, status types11.DownstreamVersionStatus, statusInfo string) error {
 	m.ctrl.T.Helper()
@@ -1602,6 +2163,20 @@ func (mr *MockStoreMockRecorder) SetDownstreamVersionStatus(appID, sequence, sta
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDownstreamVersionStatus", reflect.TypeOf((*MockStore)(nil).SetDownstreamVersionStatus), appID, sequence, status, statusInfo)
 }
 
+// SetDownstreamVersionStatusForCluster mocks base method.
+func (m *MockStore) SetDownstreamVersionStatusForCluster(appID string, clusterID string, sequence int64, status types11.DownstreamVersionStatus, statusInfo string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDownstreamVersionStatusForCluster", appID, clusterID, sequence, status, statusInfo)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDownstreamVersionStatusForCluster indicates an expected call of SetDownstreamVersionStatusForCluster.
+func (mr *MockStoreMockRecorder) SetDownstreamVersionStatusForCluster(appID, clusterID, sequence, status, statusInfo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDownstreamVersionStatusForCluster", reflect.TypeOf((*MockStore)(nil).SetDownstreamVersionStatusForCluster), appID, clusterID, sequence, status, statusInfo)
+}
+
 // SetEmbeddedClusterAuthToken mocks base method.
 func (m *MockStore) SetEmbeddedClusterAuthToken(token string) error {
 	m.ctrl.T.Helper()
@@ -1645,6 +2220,20 @@ func (mr *MockStoreMockRecorder) SetIgnorePreflightPermissionErrors(appID, seque
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetIgnorePreflightPermissionErrors", reflect.TypeOf((*MockStore)(nil).SetIgnorePreflightPermissionErrors), appID, sequence)
 }
 
+// SetImageVerificationResults mocks base method.
+func (m *MockStore) SetImageVerificationResults(appID string, sequence int64, results []types21.ImageVerificationResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetImageVerificationResults", appID, sequence, results)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetImageVerificationResults indicates an expected call of SetImageVerificationResults.
+func (mr *MockStoreMockRecorder) SetImageVerificationResults(appID, sequence, results interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetImageVerificationResults", reflect.TypeOf((*MockStore)(nil).SetImageVerificationResults), appID, sequence, results)
+}
+
 // SetInstanceSnapshotSchedule mocks base method.
 func (m *MockStore) SetInstanceSnapshotSchedule(clusterID, snapshotSchedule string) error {
 	m.ctrl.T.Helper()
@@ -1673,6 +2262,20 @@ func (mr *MockStoreMockRecorder) SetInstanceSnapshotTTL(clusterID, snapshotTTL i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotTTL", reflect.TypeOf((*MockStore)(nil).SetInstanceSnapshotTTL), clusterID, snapshotTTL)
 }
 
+// SetInstanceSnapshotVerificationPolicy mocks base method.
+func (m *MockStore) SetInstanceSnapshotVerificationPolicy(clusterID string, policy types5.BackupVerificationPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotVerificationPolicy", clusterID, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotVerificationPolicy indicates an expected call of SetInstanceSnapshotVerificationPolicy.
+func (mr *MockStoreMockRecorder) SetInstanceSnapshotVerificationPolicy(clusterID, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotVerificationPolicy", reflect.TypeOf((*MockStore)(nil).SetInstanceSnapshotVerificationPolicy), clusterID, policy)
+}
+
 // SetIsKotsadmIDGenerated mocks base method.
 func (m *MockStore) SetIsKotsadmIDGenerated() error {
 	m.ctrl.T.Helper()
@@ -1729,6 +2332,34 @@ func (mr *MockStoreMockRecorder) SetPrometheusAddress(address interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPrometheusAddress", reflect.TypeOf((*MockStore)(nil).SetPrometheusAddress), address)
 }
 
+// SetPromotionStage mocks base method.
+func (m *MockStore) SetPromotionStage(stage types19.Stage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPromotionStage", stage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPromotionStage indicates an expected call of SetPromotionStage.
+func (mr *MockStoreMockRecorder) SetPromotionStage(stage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPromotionStage", reflect.TypeOf((*MockStore)(nil).SetPromotionStage), stage)
+}
+
+// SetQueuedDeploy mocks base method.
+func (m *MockStore) SetQueuedDeploy(queuedDeploy types17.QueuedDeploy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetQueuedDeploy", queuedDeploy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetQueuedDeploy indicates an expected call of SetQueuedDeploy.
+func (mr *MockStoreMockRecorder) SetQueuedDeploy(queuedDeploy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetQueuedDeploy", reflect.TypeOf((*MockStore)(nil).SetQueuedDeploy), queuedDeploy)
+}
+
 // SetRedactions mocks base method.
 func (m *MockStore) SetRedactions(bundleID string, redacts redact.RedactionList) error {
 	m.ctrl.T.Helper()
@@ -1842,6 +2473,20 @@ func (mr *MockStoreMockRecorder) UpdateAppVersion(appID, sequence, baseSequence,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppVersion", reflect.TypeOf((*MockStore)(nil).UpdateAppVersion), appID, sequence, baseSequence, filesInDir, source, skipPreflights, renderer)
 }
 
+// UpdateAppVersionArchive mocks base method.
+func (m *MockStore) UpdateAppVersionArchive(appID string, sequence int64, archiveDir string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAppVersionArchive", appID, sequence, archiveDir)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAppVersionArchive indicates an expected call of UpdateAppVersionArchive.
+func (mr *MockStoreMockRecorder) UpdateAppVersionArchive(appID, sequence, archiveDir interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppVersionArchive", reflect.TypeOf((*MockStore)(nil).UpdateAppVersionArchive), appID, sequence, archiveDir)
+}
+
 // UpdateDownstreamDeployStatus mocks base method.
 func (m *MockStore) UpdateDownstreamDeployStatus(appID, clusterID string, sequence int64, isError bool, output types0.DownstreamOutput) error {
 	m.ctrl.T.Helper()
@@ -1856,6 +2501,20 @@ func (mr *MockStoreMockRecorder) UpdateDownstreamDeployStatus(appID, clusterID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDownstreamDeployStatus", reflect.TypeOf((*MockStore)(nil).UpdateDownstreamDeployStatus), appID, clusterID, sequence, isError, output)
 }
 
+// UpdateEncryptedValue mocks base method.
+func (m *MockStore) UpdateEncryptedValue(value types20.EncryptedValue) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEncryptedValue", value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEncryptedValue indicates an expected call of UpdateEncryptedValue.
+func (mr *MockStoreMockRecorder) UpdateEncryptedValue(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEncryptedValue", reflect.TypeOf((*MockStore)(nil).UpdateEncryptedValue), value)
+}
+
 // UpdateNextAppVersionDiffSummary mocks base method.
 func (m *MockStore) UpdateNextAppVersionDiffSummary(appID string, baseSequence int64) error {
 	m.ctrl.T.Helper()
@@ -1940,6 +2599,20 @@ func (mr *MockStoreMockRecorder) UpdateSupportBundle(bundle interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSupportBundle", reflect.TypeOf((*MockStore)(nil).UpdateSupportBundle), bundle)
 }
 
+// UpdateWebhookTarget mocks base method.
+func (m *MockStore) UpdateWebhookTarget(target types16.WebhookTarget) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookTarget", target)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookTarget indicates an expected call of UpdateWebhookTarget.
+func (mr *MockStoreMockRecorder) UpdateWebhookTarget(target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookTarget", reflect.TypeOf((*MockStore)(nil).UpdateWebhookTarget), target)
+}
+
 // UploadSupportBundle mocks base method.
 func (m *MockStore) UploadSupportBundle(bundleID, archivePath string, marshalledTree []byte) error {
 	m.ctrl.T.Helper()
@@ -2136,6 +2809,21 @@ func (mr *MockSupportBundleStoreMockRecorder) DeleteSupportBundle(bundleID, appI
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSupportBundle", reflect.TypeOf((*MockSupportBundleStore)(nil).DeleteSupportBundle), bundleID, appID)
 }
 
+// GetAppSupportBundlePolicy mocks base method.
+func (m *MockSupportBundleStore) GetAppSupportBundlePolicy(appID string) (*types12.SupportBundlePolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppSupportBundlePolicy", appID)
+	ret0, _ := ret[0].(*types12.SupportBundlePolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppSupportBundlePolicy indicates an expected call of GetAppSupportBundlePolicy.
+func (mr *MockSupportBundleStoreMockRecorder) GetAppSupportBundlePolicy(appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppSupportBundlePolicy", reflect.TypeOf((*MockSupportBundleStore)(nil).GetAppSupportBundlePolicy), appID)
+}
+
 // GetRedactions mocks base method.
 func (m *MockSupportBundleStore) GetRedactions(bundleID string) (redact.RedactionList, error) {
 	m.ctrl.T.Helper()
@@ -2211,6 +2899,20 @@ func (mr *MockSupportBundleStoreMockRecorder) ListSupportBundles(appID interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSupportBundles", reflect.TypeOf((*MockSupportBundleStore)(nil).ListSupportBundles), appID)
 }
 
+// SetAppSupportBundlePolicy mocks base method.
+func (m *MockSupportBundleStore) SetAppSupportBundlePolicy(appID string, policy types12.SupportBundlePolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAppSupportBundlePolicy", appID, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAppSupportBundlePolicy indicates an expected call of SetAppSupportBundlePolicy.
+func (mr *MockSupportBundleStoreMockRecorder) SetAppSupportBundlePolicy(appID, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppSupportBundlePolicy", reflect.TypeOf((*MockSupportBundleStore)(nil).SetAppSupportBundlePolicy), appID, policy)
+}
+
 // SetRedactions mocks base method.
 func (m *MockSupportBundleStore) SetRedactions(bundleID string, redacts redact.RedactionList) error {
 	m.ctrl.T.Helper()
@@ -2532,6 +3234,21 @@ func (m *MockSessionStore) EXPECT() *MockSessionStoreMockRecorder {
 	return m.recorder
 }
 
+// CountActiveSessions mocks base method.
+func (m *MockSessionStore) CountActiveSessions() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountActiveSessions")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountActiveSessions indicates an expected call of CountActiveSessions.
+func (mr *MockSessionStoreMockRecorder) CountActiveSessions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountActiveSessions", reflect.TypeOf((*MockSessionStore)(nil).CountActiveSessions))
+}
+
 // CreateSession mocks base method.
 func (m *MockSessionStore) CreateSession(user *types14.User, issuedAt, expiresAt time.Time, roles []string) (*types10.Session, error) {
 	m.ctrl.T.Helper()
@@ -2642,6 +3359,51 @@ func (mr *MockAppStatusStoreMockRecorder) GetAppStatus(appID interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppStatus", reflect.TypeOf((*MockAppStatusStore)(nil).GetAppStatus), appID)
 }
 
+// GetDownstreamAppStatus mocks base method.
+func (m *MockAppStatusStore) GetDownstreamAppStatus(appID string, clusterID string) (*types4.AppStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDownstreamAppStatus", appID, clusterID)
+	ret0, _ := ret[0].(*types4.AppStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDownstreamAppStatus indicates an expected call of GetDownstreamAppStatus.
+func (mr *MockAppStatusStoreMockRecorder) GetDownstreamAppStatus(appID, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDownstreamAppStatus", reflect.TypeOf((*MockAppStatusStore)(nil).GetDownstreamAppStatus), appID, clusterID)
+}
+
+// GetLastAppStatusTransitionBefore mocks base method.
+func (m *MockAppStatusStore) GetLastAppStatusTransitionBefore(appID string, clusterID string, before time.Time) (*types4.StatusTransition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastAppStatusTransitionBefore", appID, clusterID, before)
+	ret0, _ := ret[0].(*types4.StatusTransition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastAppStatusTransitionBefore indicates an expected call of GetLastAppStatusTransitionBefore.
+func (mr *MockAppStatusStoreMockRecorder) GetLastAppStatusTransitionBefore(appID, clusterID, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastAppStatusTransitionBefore", reflect.TypeOf((*MockAppStatusStore)(nil).GetLastAppStatusTransitionBefore), appID, clusterID, before)
+}
+
+// ListAppStatusTransitions mocks base method.
+func (m *MockAppStatusStore) ListAppStatusTransitions(opts types4.ListStatusTransitionsOptions) ([]types4.StatusTransition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAppStatusTransitions", opts)
+	ret0, _ := ret[0].([]types4.StatusTransition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAppStatusTransitions indicates an expected call of ListAppStatusTransitions.
+func (mr *MockAppStatusStoreMockRecorder) ListAppStatusTransitions(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppStatusTransitions", reflect.TypeOf((*MockAppStatusStore)(nil).ListAppStatusTransitions), opts)
+}
+
 // SetAppStatus mocks base method.
 func (m *MockAppStatusStore) SetAppStatus(appID string, resourceStates types4.ResourceStates, updatedAt time.Time, sequence int64) error {
 	m.ctrl.T.Helper()
@@ -2656,6 +3418,20 @@ func (mr *MockAppStatusStoreMockRecorder) SetAppStatus(appID, resourceStates, up
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppStatus", reflect.TypeOf((*MockAppStatusStore)(nil).SetAppStatus), appID, resourceStates, updatedAt, sequence)
 }
 
+// SetDownstreamAppStatus mocks base method.
+func (m *MockAppStatusStore) SetDownstreamAppStatus(appID string, clusterID string, resourceStates types4.ResourceStates, updatedAt time.Time, sequence int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDownstreamAppStatus", appID, clusterID, resourceStates, updatedAt, sequence)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDownstreamAppStatus indicates an expected call of SetDownstreamAppStatus.
+func (mr *MockAppStatusStoreMockRecorder) SetDownstreamAppStatus(appID, clusterID, resourceStates, updatedAt, sequence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDownstreamAppStatus", reflect.TypeOf((*MockAppStatusStore)(nil).SetDownstreamAppStatus), appID, clusterID, resourceStates, updatedAt, sequence)
+}
+
 // MockAppStore is a mock of AppStore interface.
 type MockAppStore struct {
 	ctrl     *gomock.Controller
@@ -3292,6 +4068,34 @@ func (mr *MockDownstreamStoreMockRecorder) MarkAsCurrentDownstreamVersion(appID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsCurrentDownstreamVersion", reflect.TypeOf((*MockDownstreamStore)(nil).MarkAsCurrentDownstreamVersion), appID, sequence)
 }
 
+// MarkAsCurrentDownstreamVersionForCluster mocks base method.
+func (m *MockDownstreamStore) MarkAsCurrentDownstreamVersionForCluster(appID string, clusterID string, sequence int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAsCurrentDownstreamVersionForCluster", appID, clusterID, sequence)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAsCurrentDownstreamVersionForCluster indicates an expected call of MarkAsCurrentDownstreamVersionForCluster.
+func (mr *MockDownstreamStoreMockRecorder) MarkAsCurrentDownstreamVersionForCluster(appID, clusterID, sequence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsCurrentDownstreamVersionForCluster", reflect.TypeOf((*MockDownstreamStore)(nil).MarkAsCurrentDownstreamVersionForCluster), appID, clusterID, sequence)
+}
+
+// SetDownstreamRolloutLog mocks base method.
+func (m *MockDownstreamStore) SetDownstreamRolloutLog(appID string, clusterID string, sequence int64, rolloutLog string, isError bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDownstreamRolloutLog", appID, clusterID, sequence, rolloutLog, isError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDownstreamRolloutLog indicates an expected call of SetDownstreamRolloutLog.
+func (mr *MockDownstreamStoreMockRecorder) SetDownstreamRolloutLog(appID, clusterID, sequence, rolloutLog, isError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDownstreamRolloutLog", reflect.TypeOf((*MockDownstreamStore)(nil).SetDownstreamRolloutLog), appID, clusterID, sequence, rolloutLog, isError)
+}
+
 // SetDownstreamVersionStatus mocks base method.
 func (m *MockDownstreamStore) SetDownstreamVersionStatus(appID string, sequence int64, status types11.DownstreamVersionStatus, statusInfo string) error {
 	m.ctrl.T.Helper()
@@ -3306,6 +4110,20 @@ func (mr *MockDownstreamStoreMockRecorder) SetDownstreamVersionStatus(appID, seq
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDownstreamVersionStatus", reflect.TypeOf((*MockDownstreamStore)(nil).SetDownstreamVersionStatus), appID, sequence, status, statusInfo)
 }
 
+// SetDownstreamVersionStatusForCluster mocks base method.
+func (m *MockDownstreamStore) SetDownstreamVersionStatusForCluster(appID string, clusterID string, sequence int64, status types11.DownstreamVersionStatus, statusInfo string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDownstreamVersionStatusForCluster", appID, clusterID, sequence, status, statusInfo)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDownstreamVersionStatusForCluster indicates an expected call of SetDownstreamVersionStatusForCluster.
+func (mr *MockDownstreamStoreMockRecorder) SetDownstreamVersionStatusForCluster(appID, clusterID, sequence, status, statusInfo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDownstreamVersionStatusForCluster", reflect.TypeOf((*MockDownstreamStore)(nil).SetDownstreamVersionStatusForCluster), appID, clusterID, sequence, status, statusInfo)
+}
+
 // UpdateDownstreamDeployStatus mocks base method.
 func (m *MockDownstreamStore) UpdateDownstreamDeployStatus(appID, clusterID string, sequence int64, isError bool, output types0.DownstreamOutput) error {
 	m.ctrl.T.Helper()
@@ -3399,6 +4217,21 @@ func (mr *MockSnapshotStoreMockRecorder) DeletePendingScheduledSnapshots(appID i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingScheduledSnapshots", reflect.TypeOf((*MockSnapshotStore)(nil).DeletePendingScheduledSnapshots), appID)
 }
 
+// GetInstanceSnapshotVerificationPolicy mocks base method.
+func (m *MockSnapshotStore) GetInstanceSnapshotVerificationPolicy(clusterID string) (*types5.BackupVerificationPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInstanceSnapshotVerificationPolicy", clusterID)
+	ret0, _ := ret[0].(*types5.BackupVerificationPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInstanceSnapshotVerificationPolicy indicates an expected call of GetInstanceSnapshotVerificationPolicy.
+func (mr *MockSnapshotStoreMockRecorder) GetInstanceSnapshotVerificationPolicy(clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstanceSnapshotVerificationPolicy", reflect.TypeOf((*MockSnapshotStore)(nil).GetInstanceSnapshotVerificationPolicy), clusterID)
+}
+
 // ListPendingScheduledInstanceSnapshots mocks base method.
 func (m *MockSnapshotStore) ListPendingScheduledInstanceSnapshots(clusterID string) ([]types5.ScheduledInstanceSnapshot, error) {
 	m.ctrl.T.Helper()
@@ -3429,6 +4262,20 @@ func (mr *MockSnapshotStoreMockRecorder) ListPendingScheduledSnapshots(appID int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingScheduledSnapshots", reflect.TypeOf((*MockSnapshotStore)(nil).ListPendingScheduledSnapshots), appID)
 }
 
+// SetInstanceSnapshotVerificationPolicy mocks base method.
+func (m *MockSnapshotStore) SetInstanceSnapshotVerificationPolicy(clusterID string, policy types5.BackupVerificationPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotVerificationPolicy", clusterID, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotVerificationPolicy indicates an expected call of SetInstanceSnapshotVerificationPolicy.
+func (mr *MockSnapshotStoreMockRecorder) SetInstanceSnapshotVerificationPolicy(clusterID, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotVerificationPolicy", reflect.TypeOf((*MockSnapshotStore)(nil).SetInstanceSnapshotVerificationPolicy), clusterID, policy)
+}
+
 // UpdateScheduledInstanceSnapshot mocks base method.
 func (m *MockSnapshotStore) UpdateScheduledInstanceSnapshot(snapshotID, backupName string) error {
 	m.ctrl.T.Helper()
@@ -3719,6 +4566,20 @@ func (mr *MockVersionStoreMockRecorder) UpdateAppVersion(appID, sequence, baseSe
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppVersion", reflect.TypeOf((*MockVersionStore)(nil).UpdateAppVersion), appID, sequence, baseSequence, filesInDir, source, skipPreflights, renderer)
 }
 
+// UpdateAppVersionArchive mocks base method.
+func (m *MockVersionStore) UpdateAppVersionArchive(appID string, sequence int64, archiveDir string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAppVersionArchive", appID, sequence, archiveDir)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAppVersionArchive indicates an expected call of UpdateAppVersionArchive.
+func (mr *MockVersionStoreMockRecorder) UpdateAppVersionArchive(appID, sequence, archiveDir interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppVersionArchive", reflect.TypeOf((*MockVersionStore)(nil).UpdateAppVersionArchive), appID, sequence, archiveDir)
+}
+
 // UpdateNextAppVersionDiffSummary mocks base method.
 func (m *MockVersionStore) UpdateNextAppVersionDiffSummary(appID string, baseSequence int64) error {
 	m.ctrl.T.Helper()
@@ -3934,6 +4795,20 @@ func (m *MockClusterStore) EXPECT() *MockClusterStoreMockRecorder {
 	return m.recorder
 }
 
+// AddAppsToDownstream mocks base method.
+func (m *MockClusterStore) AddAppsToDownstream(clusterID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAppsToDownstream", clusterID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAppsToDownstream indicates an expected call of AddAppsToDownstream.
+func (mr *MockClusterStoreMockRecorder) AddAppsToDownstream(clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAppsToDownstream", reflect.TypeOf((*MockClusterStore)(nil).AddAppsToDownstream), clusterID)
+}
+
 // CreateNewCluster mocks base method.
 func (m *MockClusterStore) CreateNewCluster(userID string, isAllUsers bool, title, token string) (string, error) {
 	m.ctrl.T.Helper()
@@ -3949,6 +4824,21 @@ func (mr *MockClusterStoreMockRecorder) CreateNewCluster(userID, isAllUsers, tit
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNewCluster", reflect.TypeOf((*MockClusterStore)(nil).CreateNewCluster), userID, isAllUsers, title, token)
 }
 
+// GetCluster mocks base method.
+func (m *MockClusterStore) GetCluster(clusterID string) (*types0.Downstream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCluster", clusterID)
+	ret0, _ := ret[0].(*types0.Downstream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCluster indicates an expected call of GetCluster.
+func (mr *MockClusterStoreMockRecorder) GetCluster(clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCluster", reflect.TypeOf((*MockClusterStore)(nil).GetCluster), clusterID)
+}
+
 // GetClusterIDFromDeployToken mocks base method.
 func (m *MockClusterStore) GetClusterIDFromDeployToken(deployToken string) (string, error) {
 	m.ctrl.T.Helper()
@@ -3994,24 +4884,52 @@ func (mr *MockClusterStoreMockRecorder) ListClusters() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusters", reflect.TypeOf((*MockClusterStore)(nil).ListClusters))
 }
 
-// SetInstanceSnapshotSchedule mocks base method.
-func (m *MockClusterStore) SetInstanceSnapshotSchedule(clusterID, snapshotSchedule string) error {
+// RemoveCluster mocks base method.
+func (m *MockClusterStore) RemoveCluster(clusterID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetInstanceSnapshotSchedule", clusterID, snapshotSchedule)
+	ret := m.ctrl.Call(m, "RemoveCluster", clusterID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetInstanceSnapshotSchedule indicates an expected call of SetInstanceSnapshotSchedule.
-func (mr *MockClusterStoreMockRecorder) SetInstanceSnapshotSchedule(clusterID, snapshotSchedule interface{}) *gomock.Call {
+// RemoveCluster indicates an expected call of RemoveCluster.
+func (mr *MockClusterStoreMockRecorder) RemoveCluster(clusterID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotSchedule", reflect.TypeOf((*MockClusterStore)(nil).SetInstanceSnapshotSchedule), clusterID, snapshotSchedule)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCluster", reflect.TypeOf((*MockClusterStore)(nil).RemoveCluster), clusterID)
 }
 
-// SetInstanceSnapshotTTL mocks base method.
-func (m *MockClusterStore) SetInstanceSnapshotTTL(clusterID, snapshotTTL string) error {
+// SetClusterConnection mocks base method.
+func (m *MockClusterStore) SetClusterConnection(clusterID string, connectionType string, targetNamespace string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetInstanceSnapshotTTL", clusterID, snapshotTTL)
+	ret := m.ctrl.Call(m, "SetClusterConnection", clusterID, connectionType, targetNamespace)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetClusterConnection indicates an expected call of SetClusterConnection.
+func (mr *MockClusterStoreMockRecorder) SetClusterConnection(clusterID, connectionType, targetNamespace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetClusterConnection", reflect.TypeOf((*MockClusterStore)(nil).SetClusterConnection), clusterID, connectionType, targetNamespace)
+}
+
+// SetInstanceSnapshotSchedule mocks base method.
+func (m *MockClusterStore) SetInstanceSnapshotSchedule(clusterID, snapshotSchedule string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotSchedule", clusterID, snapshotSchedule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotSchedule indicates an expected call of SetInstanceSnapshotSchedule.
+func (mr *MockClusterStoreMockRecorder) SetInstanceSnapshotSchedule(clusterID, snapshotSchedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotSchedule", reflect.TypeOf((*MockClusterStore)(nil).SetInstanceSnapshotSchedule), clusterID, snapshotSchedule)
+}
+
+// SetInstanceSnapshotTTL mocks base method.
+func (m *MockClusterStore) SetInstanceSnapshotTTL(clusterID, snapshotTTL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotTTL", clusterID, snapshotTTL)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
@@ -4299,3 +5217,585 @@ func (mr *MockEmbeddedClusterStoreMockRecorder) SetEmbeddedClusterInstallCommand
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEmbeddedClusterInstallCommandRoles", reflect.TypeOf((*MockEmbeddedClusterStore)(nil).SetEmbeddedClusterInstallCommandRoles), roles)
 }
+
+// MockAuditStore is a mock of AuditStore interface.
+type MockAuditStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditStoreMockRecorder
+}
+
+// MockAuditStoreMockRecorder is the mock recorder for MockAuditStore.
+type MockAuditStoreMockRecorder struct {
+	mock *MockAuditStore
+}
+
+// NewMockAuditStore creates a new mock instance.
+func NewMockAuditStore(ctrl *gomock.Controller) *MockAuditStore {
+	mock := &MockAuditStore{ctrl: ctrl}
+	mock.recorder = &MockAuditStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditStore) EXPECT() *MockAuditStoreMockRecorder {
+	return m.recorder
+}
+
+// CreateAuditLogEntry mocks base method.
+func (m *MockAuditStore) CreateAuditLogEntry(entry types15.AuditLogEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditLogEntry", entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAuditLogEntry indicates an expected call of CreateAuditLogEntry.
+func (mr *MockAuditStoreMockRecorder) CreateAuditLogEntry(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditLogEntry", reflect.TypeOf((*MockAuditStore)(nil).CreateAuditLogEntry), entry)
+}
+
+// ListAuditLogEntries mocks base method.
+func (m *MockAuditStore) ListAuditLogEntries(opts types15.ListAuditLogOptions) (*types15.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditLogEntries", opts)
+	ret0, _ := ret[0].(*types15.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditLogEntries indicates an expected call of ListAuditLogEntries.
+func (mr *MockAuditStoreMockRecorder) ListAuditLogEntries(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditLogEntries", reflect.TypeOf((*MockAuditStore)(nil).ListAuditLogEntries), opts)
+}
+
+// MockNotificationsStore is a mock of NotificationsStore interface.
+type MockNotificationsStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationsStoreMockRecorder
+}
+
+// MockNotificationsStoreMockRecorder is the mock recorder for MockNotificationsStore.
+type MockNotificationsStoreMockRecorder struct {
+	mock *MockNotificationsStore
+}
+
+// NewMockNotificationsStore creates a new mock instance.
+func NewMockNotificationsStore(ctrl *gomock.Controller) *MockNotificationsStore {
+	mock := &MockNotificationsStore{ctrl: ctrl}
+	mock.recorder = &MockNotificationsStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationsStore) EXPECT() *MockNotificationsStoreMockRecorder {
+	return m.recorder
+}
+
+// CreateWebhookDelivery mocks base method.
+func (m *MockNotificationsStore) CreateWebhookDelivery(delivery types16.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDelivery", delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookDelivery indicates an expected call of CreateWebhookDelivery.
+func (mr *MockNotificationsStoreMockRecorder) CreateWebhookDelivery(delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDelivery", reflect.TypeOf((*MockNotificationsStore)(nil).CreateWebhookDelivery), delivery)
+}
+
+// CreateWebhookTarget mocks base method.
+func (m *MockNotificationsStore) CreateWebhookTarget(target types16.WebhookTarget) (*types16.WebhookTarget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookTarget", target)
+	ret0, _ := ret[0].(*types16.WebhookTarget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookTarget indicates an expected call of CreateWebhookTarget.
+func (mr *MockNotificationsStoreMockRecorder) CreateWebhookTarget(target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookTarget", reflect.TypeOf((*MockNotificationsStore)(nil).CreateWebhookTarget), target)
+}
+
+// DeleteWebhookTarget mocks base method.
+func (m *MockNotificationsStore) DeleteWebhookTarget(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookTarget", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookTarget indicates an expected call of DeleteWebhookTarget.
+func (mr *MockNotificationsStoreMockRecorder) DeleteWebhookTarget(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookTarget", reflect.TypeOf((*MockNotificationsStore)(nil).DeleteWebhookTarget), id)
+}
+
+// GetWebhookTarget mocks base method.
+func (m *MockNotificationsStore) GetWebhookTarget(id string) (*types16.WebhookTarget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookTarget", id)
+	ret0, _ := ret[0].(*types16.WebhookTarget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookTarget indicates an expected call of GetWebhookTarget.
+func (mr *MockNotificationsStoreMockRecorder) GetWebhookTarget(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookTarget", reflect.TypeOf((*MockNotificationsStore)(nil).GetWebhookTarget), id)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockNotificationsStore) ListWebhookDeliveries(targetID string, currentPage int, pageSize int) (*types16.WebhookDeliveries, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", targetID, currentPage, pageSize)
+	ret0, _ := ret[0].(*types16.WebhookDeliveries)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockNotificationsStoreMockRecorder) ListWebhookDeliveries(targetID, currentPage, pageSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockNotificationsStore)(nil).ListWebhookDeliveries), targetID, currentPage, pageSize)
+}
+
+// ListWebhookTargets mocks base method.
+func (m *MockNotificationsStore) ListWebhookTargets() ([]types16.WebhookTarget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookTargets")
+	ret0, _ := ret[0].([]types16.WebhookTarget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookTargets indicates an expected call of ListWebhookTargets.
+func (mr *MockNotificationsStoreMockRecorder) ListWebhookTargets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookTargets", reflect.TypeOf((*MockNotificationsStore)(nil).ListWebhookTargets))
+}
+
+// UpdateWebhookTarget mocks base method.
+func (m *MockNotificationsStore) UpdateWebhookTarget(target types16.WebhookTarget) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookTarget", target)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookTarget indicates an expected call of UpdateWebhookTarget.
+func (mr *MockNotificationsStoreMockRecorder) UpdateWebhookTarget(target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookTarget", reflect.TypeOf((*MockNotificationsStore)(nil).UpdateWebhookTarget), target)
+}
+
+// MockMaintenanceWindowStore is a mock of MaintenanceWindowStore interface.
+type MockMaintenanceWindowStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockMaintenanceWindowStoreMockRecorder
+}
+
+// MockMaintenanceWindowStoreMockRecorder is the mock recorder for MockMaintenanceWindowStore.
+type MockMaintenanceWindowStoreMockRecorder struct {
+	mock *MockMaintenanceWindowStore
+}
+
+// NewMockMaintenanceWindowStore creates a new mock instance.
+func NewMockMaintenanceWindowStore(ctrl *gomock.Controller) *MockMaintenanceWindowStore {
+	mock := &MockMaintenanceWindowStore{ctrl: ctrl}
+	mock.recorder = &MockMaintenanceWindowStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMaintenanceWindowStore) EXPECT() *MockMaintenanceWindowStoreMockRecorder {
+	return m.recorder
+}
+
+// DeleteQueuedDeploy mocks base method.
+func (m *MockMaintenanceWindowStore) DeleteQueuedDeploy(appID string, clusterID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteQueuedDeploy", appID, clusterID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteQueuedDeploy indicates an expected call of DeleteQueuedDeploy.
+func (mr *MockMaintenanceWindowStoreMockRecorder) DeleteQueuedDeploy(appID, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteQueuedDeploy", reflect.TypeOf((*MockMaintenanceWindowStore)(nil).DeleteQueuedDeploy), appID, clusterID)
+}
+
+// GetAppMaintenanceWindows mocks base method.
+func (m *MockMaintenanceWindowStore) GetAppMaintenanceWindows(appID string) ([]types17.MaintenanceWindow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppMaintenanceWindows", appID)
+	ret0, _ := ret[0].([]types17.MaintenanceWindow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppMaintenanceWindows indicates an expected call of GetAppMaintenanceWindows.
+func (mr *MockMaintenanceWindowStoreMockRecorder) GetAppMaintenanceWindows(appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppMaintenanceWindows", reflect.TypeOf((*MockMaintenanceWindowStore)(nil).GetAppMaintenanceWindows), appID)
+}
+
+// GetQueuedDeploy mocks base method.
+func (m *MockMaintenanceWindowStore) GetQueuedDeploy(appID string, clusterID string) (*types17.QueuedDeploy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQueuedDeploy", appID, clusterID)
+	ret0, _ := ret[0].(*types17.QueuedDeploy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQueuedDeploy indicates an expected call of GetQueuedDeploy.
+func (mr *MockMaintenanceWindowStoreMockRecorder) GetQueuedDeploy(appID, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQueuedDeploy", reflect.TypeOf((*MockMaintenanceWindowStore)(nil).GetQueuedDeploy), appID, clusterID)
+}
+
+// ListQueuedDeploys mocks base method.
+func (m *MockMaintenanceWindowStore) ListQueuedDeploys() ([]types17.QueuedDeploy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListQueuedDeploys")
+	ret0, _ := ret[0].([]types17.QueuedDeploy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListQueuedDeploys indicates an expected call of ListQueuedDeploys.
+func (mr *MockMaintenanceWindowStoreMockRecorder) ListQueuedDeploys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListQueuedDeploys", reflect.TypeOf((*MockMaintenanceWindowStore)(nil).ListQueuedDeploys))
+}
+
+// SetAppMaintenanceWindows mocks base method.
+func (m *MockMaintenanceWindowStore) SetAppMaintenanceWindows(appID string, windows []types17.MaintenanceWindow) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAppMaintenanceWindows", appID, windows)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAppMaintenanceWindows indicates an expected call of SetAppMaintenanceWindows.
+func (mr *MockMaintenanceWindowStoreMockRecorder) SetAppMaintenanceWindows(appID, windows interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppMaintenanceWindows", reflect.TypeOf((*MockMaintenanceWindowStore)(nil).SetAppMaintenanceWindows), appID, windows)
+}
+
+// SetQueuedDeploy mocks base method.
+func (m *MockMaintenanceWindowStore) SetQueuedDeploy(queuedDeploy types17.QueuedDeploy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetQueuedDeploy", queuedDeploy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetQueuedDeploy indicates an expected call of SetQueuedDeploy.
+func (mr *MockMaintenanceWindowStoreMockRecorder) SetQueuedDeploy(queuedDeploy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetQueuedDeploy", reflect.TypeOf((*MockMaintenanceWindowStore)(nil).SetQueuedDeploy), queuedDeploy)
+}
+
+// MockDriftStore is a mock of DriftStore interface.
+type MockDriftStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockDriftStoreMockRecorder
+}
+
+// MockDriftStoreMockRecorder is the mock recorder for MockDriftStore.
+type MockDriftStoreMockRecorder struct {
+	mock *MockDriftStore
+}
+
+// NewMockDriftStore creates a new mock instance.
+func NewMockDriftStore(ctrl *gomock.Controller) *MockDriftStore {
+	mock := &MockDriftStore{ctrl: ctrl}
+	mock.recorder = &MockDriftStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDriftStore) EXPECT() *MockDriftStoreMockRecorder {
+	return m.recorder
+}
+
+// GetAppDriftAutoRemediate mocks base method.
+func (m *MockDriftStore) GetAppDriftAutoRemediate(appID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppDriftAutoRemediate", appID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppDriftAutoRemediate indicates an expected call of GetAppDriftAutoRemediate.
+func (mr *MockDriftStoreMockRecorder) GetAppDriftAutoRemediate(appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppDriftAutoRemediate", reflect.TypeOf((*MockDriftStore)(nil).GetAppDriftAutoRemediate), appID)
+}
+
+// GetAppDriftStatus mocks base method.
+func (m *MockDriftStore) GetAppDriftStatus(appID string) (*types18.AppDriftStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppDriftStatus", appID)
+	ret0, _ := ret[0].(*types18.AppDriftStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppDriftStatus indicates an expected call of GetAppDriftStatus.
+func (mr *MockDriftStoreMockRecorder) GetAppDriftStatus(appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppDriftStatus", reflect.TypeOf((*MockDriftStore)(nil).GetAppDriftStatus), appID)
+}
+
+// SetAppDriftAutoRemediate mocks base method.
+func (m *MockDriftStore) SetAppDriftAutoRemediate(appID string, autoRemediate bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAppDriftAutoRemediate", appID, autoRemediate)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAppDriftAutoRemediate indicates an expected call of SetAppDriftAutoRemediate.
+func (mr *MockDriftStoreMockRecorder) SetAppDriftAutoRemediate(appID, autoRemediate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppDriftAutoRemediate", reflect.TypeOf((*MockDriftStore)(nil).SetAppDriftAutoRemediate), appID, autoRemediate)
+}
+
+// SetAppDriftStatus mocks base method.
+func (m *MockDriftStore) SetAppDriftStatus(appID string, sequence int64, resources []types18.DriftedResource, checkedAt time.Time, driftErr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAppDriftStatus", appID, sequence, resources, checkedAt, driftErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAppDriftStatus indicates an expected call of SetAppDriftStatus.
+func (mr *MockDriftStoreMockRecorder) SetAppDriftStatus(appID, sequence, resources, checkedAt, driftErr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAppDriftStatus", reflect.TypeOf((*MockDriftStore)(nil).SetAppDriftStatus), appID, sequence, resources, checkedAt, driftErr)
+}
+
+// MockPromotionStore is a mock of PromotionStore interface.
+type MockPromotionStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockPromotionStoreMockRecorder
+}
+
+// MockPromotionStoreMockRecorder is the mock recorder for MockPromotionStore.
+type MockPromotionStoreMockRecorder struct {
+	mock *MockPromotionStore
+}
+
+// NewMockPromotionStore creates a new mock instance.
+func NewMockPromotionStore(ctrl *gomock.Controller) *MockPromotionStore {
+	mock := &MockPromotionStore{ctrl: ctrl}
+	mock.recorder = &MockPromotionStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPromotionStore) EXPECT() *MockPromotionStoreMockRecorder {
+	return m.recorder
+}
+
+// CreatePromotion mocks base method.
+func (m *MockPromotionStore) CreatePromotion(promotion *types19.Promotion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromotion", promotion)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePromotion indicates an expected call of CreatePromotion.
+func (mr *MockPromotionStoreMockRecorder) CreatePromotion(promotion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromotion", reflect.TypeOf((*MockPromotionStore)(nil).CreatePromotion), promotion)
+}
+
+// DeletePromotionStage mocks base method.
+func (m *MockPromotionStore) DeletePromotionStage(appID string, targetClusterID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePromotionStage", appID, targetClusterID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePromotionStage indicates an expected call of DeletePromotionStage.
+func (mr *MockPromotionStoreMockRecorder) DeletePromotionStage(appID, targetClusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePromotionStage", reflect.TypeOf((*MockPromotionStore)(nil).DeletePromotionStage), appID, targetClusterID)
+}
+
+// GetPromotionStage mocks base method.
+func (m *MockPromotionStore) GetPromotionStage(appID string, targetClusterID string) (*types19.Stage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromotionStage", appID, targetClusterID)
+	ret0, _ := ret[0].(*types19.Stage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromotionStage indicates an expected call of GetPromotionStage.
+func (mr *MockPromotionStoreMockRecorder) GetPromotionStage(appID, targetClusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromotionStage", reflect.TypeOf((*MockPromotionStore)(nil).GetPromotionStage), appID, targetClusterID)
+}
+
+// ListPromotionStages mocks base method.
+func (m *MockPromotionStore) ListPromotionStages(appID string) ([]types19.Stage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromotionStages", appID)
+	ret0, _ := ret[0].([]types19.Stage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromotionStages indicates an expected call of ListPromotionStages.
+func (mr *MockPromotionStoreMockRecorder) ListPromotionStages(appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromotionStages", reflect.TypeOf((*MockPromotionStore)(nil).ListPromotionStages), appID)
+}
+
+// ListPromotions mocks base method.
+func (m *MockPromotionStore) ListPromotions(appID string, clusterID string, sequences []int64) ([]types19.Promotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromotions", appID, clusterID, sequences)
+	ret0, _ := ret[0].([]types19.Promotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromotions indicates an expected call of ListPromotions.
+func (mr *MockPromotionStoreMockRecorder) ListPromotions(appID, clusterID, sequences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromotions", reflect.TypeOf((*MockPromotionStore)(nil).ListPromotions), appID, clusterID, sequences)
+}
+
+// SetPromotionStage mocks base method.
+func (m *MockPromotionStore) SetPromotionStage(stage types19.Stage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPromotionStage", stage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPromotionStage indicates an expected call of SetPromotionStage.
+func (mr *MockPromotionStoreMockRecorder) SetPromotionStage(stage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPromotionStage", reflect.TypeOf((*MockPromotionStore)(nil).SetPromotionStage), stage)
+}
+
+// MockEncryptionStore is a mock of EncryptionStore interface.
+type MockEncryptionStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockEncryptionStoreMockRecorder
+}
+
+// MockEncryptionStoreMockRecorder is the mock recorder for MockEncryptionStore.
+type MockEncryptionStoreMockRecorder struct {
+	mock *MockEncryptionStore
+}
+
+// NewMockEncryptionStore creates a new mock instance.
+func NewMockEncryptionStore(ctrl *gomock.Controller) *MockEncryptionStore {
+	mock := &MockEncryptionStore{ctrl: ctrl}
+	mock.recorder = &MockEncryptionStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEncryptionStore) EXPECT() *MockEncryptionStoreMockRecorder {
+	return m.recorder
+}
+
+// ListEncryptedValues mocks base method.
+func (m *MockEncryptionStore) ListEncryptedValues() ([]types20.EncryptedValue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEncryptedValues")
+	ret0, _ := ret[0].([]types20.EncryptedValue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEncryptedValues indicates an expected call of ListEncryptedValues.
+func (mr *MockEncryptionStoreMockRecorder) ListEncryptedValues() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEncryptedValues", reflect.TypeOf((*MockEncryptionStore)(nil).ListEncryptedValues))
+}
+
+// UpdateEncryptedValue mocks base method.
+func (m *MockEncryptionStore) UpdateEncryptedValue(value types20.EncryptedValue) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEncryptedValue", value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEncryptedValue indicates an expected call of UpdateEncryptedValue.
+func (mr *MockEncryptionStoreMockRecorder) UpdateEncryptedValue(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEncryptedValue", reflect.TypeOf((*MockEncryptionStore)(nil).UpdateEncryptedValue), value)
+}
+
+// MockImageVerificationStore is a mock of ImageVerificationStore interface.
+type MockImageVerificationStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockImageVerificationStoreMockRecorder
+}
+
+// MockImageVerificationStoreMockRecorder is the mock recorder for MockImageVerificationStore.
+type MockImageVerificationStoreMockRecorder struct {
+	mock *MockImageVerificationStore
+}
+
+// NewMockImageVerificationStore creates a new mock instance.
+func NewMockImageVerificationStore(ctrl *gomock.Controller) *MockImageVerificationStore {
+	mock := &MockImageVerificationStore{ctrl: ctrl}
+	mock.recorder = &MockImageVerificationStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImageVerificationStore) EXPECT() *MockImageVerificationStoreMockRecorder {
+	return m.recorder
+}
+
+// GetImageVerificationResults mocks base method.
+func (m *MockImageVerificationStore) GetImageVerificationResults(appID string, sequence int64) ([]types21.ImageVerificationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageVerificationResults", appID, sequence)
+	ret0, _ := ret[0].([]types21.ImageVerificationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageVerificationResults indicates an expected call of GetImageVerificationResults.
+func (mr *MockImageVerificationStoreMockRecorder) GetImageVerificationResults(appID, sequence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageVerificationResults", reflect.TypeOf((*MockImageVerificationStore)(nil).GetImageVerificationResults), appID, sequence)
+}
+
+// SetImageVerificationResults mocks base method.
+func (m *MockImageVerificationStore) SetImageVerificationResults(appID string, sequence int64, results []types21.ImageVerificationResult) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetImageVerificationResults", appID, sequence, results)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetImageVerificationResults indicates an expected call of SetImageVerificationResults.
+func (mr *MockImageVerificationStoreMockRecorder) SetImageVerificationResults(appID, sequence, results interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetImageVerificationResults", reflect.TypeOf((*MockImageVerificationStore)(nil).SetImageVerificationResults), appID, sequence, results)
+}
@@ -11,9 +11,16 @@ import (
 	versiontypes "github.com/replicatedhq/kots/pkg/api/version/types"
 	apptypes "github.com/replicatedhq/kots/pkg/app/types"
 	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	audittypes "github.com/replicatedhq/kots/pkg/audit/types"
+	imagetypes "github.com/replicatedhq/kots/pkg/image/types"
+	keyrotationtypes "github.com/replicatedhq/kots/pkg/keyrotation/types"
 	snapshottypes "github.com/replicatedhq/kots/pkg/kotsadmsnapshot/types"
+	maintenancewindowtypes "github.com/replicatedhq/kots/pkg/maintenancewindow/types"
+	notificationstypes "github.com/replicatedhq/kots/pkg/notifications/types"
 	installationtypes "github.com/replicatedhq/kots/pkg/online/types"
+	operatortypes "github.com/replicatedhq/kots/pkg/operator/types"
 	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
+	promotiontypes "github.com/replicatedhq/kots/pkg/promotion/types"
 	registrytypes "github.com/replicatedhq/kots/pkg/registry/types"
 	rendertypes "github.com/replicatedhq/kots/pkg/render/types"
 	sessiontypes "github.com/replicatedhq/kots/pkg/session/types"
@@ -46,6 +53,13 @@ type Store interface {
 	EmbeddedStore
 	BrandingStore
 	EmbeddedClusterStore
+	AuditStore
+	NotificationsStore
+	MaintenanceWindowStore
+	DriftStore
+	PromotionStore
+	EncryptionStore
+	ImageVerificationStore
 
 	Init() error // this may need options
 	WaitForReady(ctx context.Context) error
@@ -75,6 +89,8 @@ type SupportBundleStore interface {
 	CreateInProgressSupportBundle(supportBundle *supportbundletypes.SupportBundle) error
 	UpdateSupportBundle(bundle *supportbundletypes.SupportBundle) error
 	UploadSupportBundle(bundleID string, archivePath string, marshalledTree []byte) error
+	GetAppSupportBundlePolicy(appID string) (*supportbundletypes.SupportBundlePolicy, error)
+	SetAppSupportBundlePolicy(appID string, policy supportbundletypes.SupportBundlePolicy) error
 }
 
 type PreflightStore interface {
@@ -104,11 +120,16 @@ type SessionStore interface {
 	GetSession(sessionID string) (*sessiontypes.Session, error)
 	UpdateSessionExpiresAt(sessionID string, expiresAt time.Time) error
 	DeleteExpiredSessions() error
+	CountActiveSessions() (int64, error)
 }
 
 type AppStatusStore interface {
 	GetAppStatus(appID string) (*appstatetypes.AppStatus, error)
 	SetAppStatus(appID string, resourceStates appstatetypes.ResourceStates, updatedAt time.Time, sequence int64) error
+	GetDownstreamAppStatus(appID string, clusterID string) (*appstatetypes.AppStatus, error)
+	SetDownstreamAppStatus(appID string, clusterID string, resourceStates appstatetypes.ResourceStates, updatedAt time.Time, sequence int64) error
+	ListAppStatusTransitions(opts appstatetypes.ListStatusTransitionsOptions) ([]appstatetypes.StatusTransition, error)
+	GetLastAppStatusTransitionBefore(appID string, clusterID string, before time.Time) (*appstatetypes.StatusTransition, error)
 }
 
 type AppStore interface {
@@ -140,7 +161,9 @@ type DownstreamStore interface {
 	GetParentSequenceForSequence(appID string, clusterID string, sequence int64) (int64, error)
 	GetPreviouslyDeployedSequence(appID string, clusterID string) (int64, error)
 	MarkAsCurrentDownstreamVersion(appID string, sequence int64) error
+	MarkAsCurrentDownstreamVersionForCluster(appID string, clusterID string, sequence int64) error
 	SetDownstreamVersionStatus(appID string, sequence int64, status types.DownstreamVersionStatus, statusInfo string) error
+	SetDownstreamVersionStatusForCluster(appID string, clusterID string, sequence int64, status types.DownstreamVersionStatus, statusInfo string) error
 	GetDownstreamVersionStatus(appID string, sequence int64) (types.DownstreamVersionStatus, error)
 	GetDownstreamVersionSource(appID string, sequence int64) (string, error)
 	GetIgnoreRBACErrors(appID string, sequence int64) (bool, error)
@@ -160,6 +183,7 @@ type DownstreamStore interface {
 	IsDownstreamDeploySuccessful(appID string, clusterID string, sequence int64) (bool, error)
 	UpdateDownstreamDeployStatus(appID string, clusterID string, sequence int64, isError bool, output downstreamtypes.DownstreamOutput) error
 	DeleteDownstreamDeployStatus(appID string, clusterID string, sequence int64) error
+	SetDownstreamRolloutLog(appID string, clusterID string, sequence int64, rolloutLog string, isError bool) error
 }
 
 type SnapshotStore interface {
@@ -172,6 +196,9 @@ type SnapshotStore interface {
 	UpdateScheduledInstanceSnapshot(snapshotID string, backupName string) error
 	DeletePendingScheduledInstanceSnapshots(clusterID string) error
 	CreateScheduledInstanceSnapshot(snapshotID string, clusterID string, timestamp time.Time) error
+
+	GetInstanceSnapshotVerificationPolicy(clusterID string) (*snapshottypes.BackupVerificationPolicy, error)
+	SetInstanceSnapshotVerificationPolicy(clusterID string, policy snapshottypes.BackupVerificationPolicy) error
 }
 
 type VersionStore interface {
@@ -184,6 +211,7 @@ type VersionStore interface {
 	GetAppVersionBaseArchive(appID string, versionLabel string) (string, int64, error)
 	CreatePendingDownloadAppVersion(appID string, update upstreamtypes.Update, kotsApplication *kotsv1beta1.Application, license *kotsv1beta1.License) (int64, error)
 	UpdateAppVersion(appID string, sequence int64, baseSequence *int64, filesInDir string, source string, skipPreflights bool, renderer rendertypes.Renderer) error
+	UpdateAppVersionArchive(appID string, sequence int64, archiveDir string) error
 	CreateAppVersion(appID string, baseSequence *int64, filesInDir string, source string, isInstall bool, isAutomated bool, configFile string, skipPreflights bool, renderer rendertypes.Renderer) (int64, error)
 	GetAppVersion(appID string, sequence int64) (*versiontypes.AppVersion, error)
 	GetLatestAppSequence(appID string, downloadedOnly bool) (int64, error)
@@ -213,11 +241,15 @@ type UserStore interface {
 
 type ClusterStore interface {
 	ListClusters() ([]*downstreamtypes.Downstream, error)
+	GetCluster(clusterID string) (*downstreamtypes.Downstream, error)
 	GetClusterIDFromSlug(slug string) (clusterID string, err error)
 	GetClusterIDFromDeployToken(deployToken string) (clusterID string, err error)
 	CreateNewCluster(userID string, isAllUsers bool, title string, token string) (clusterID string, err error)
 	SetInstanceSnapshotTTL(clusterID string, snapshotTTL string) error
 	SetInstanceSnapshotSchedule(clusterID string, snapshotSchedule string) error
+	SetClusterConnection(clusterID string, connectionType string, targetNamespace string) error
+	AddAppsToDownstream(clusterID string) error
+	RemoveCluster(clusterID string) error
 }
 
 type InstallationStore interface {
@@ -245,3 +277,54 @@ type EmbeddedClusterStore interface {
 	SetEmbeddedClusterInstallCommandRoles(roles []string) (string, error)
 	GetEmbeddedClusterInstallCommandRoles(token string) ([]string, error)
 }
+
+type AuditStore interface {
+	CreateAuditLogEntry(entry audittypes.AuditLogEntry) error
+	ListAuditLogEntries(opts audittypes.ListAuditLogOptions) (*audittypes.AuditLog, error)
+}
+
+type NotificationsStore interface {
+	ListWebhookTargets() ([]notificationstypes.WebhookTarget, error)
+	GetWebhookTarget(id string) (*notificationstypes.WebhookTarget, error)
+	CreateWebhookTarget(target notificationstypes.WebhookTarget) (*notificationstypes.WebhookTarget, error)
+	UpdateWebhookTarget(target notificationstypes.WebhookTarget) error
+	DeleteWebhookTarget(id string) error
+	CreateWebhookDelivery(delivery notificationstypes.WebhookDelivery) error
+	ListWebhookDeliveries(targetID string, currentPage int, pageSize int) (*notificationstypes.WebhookDeliveries, error)
+}
+
+type MaintenanceWindowStore interface {
+	GetAppMaintenanceWindows(appID string) ([]maintenancewindowtypes.MaintenanceWindow, error)
+	SetAppMaintenanceWindows(appID string, windows []maintenancewindowtypes.MaintenanceWindow) error
+	SetQueuedDeploy(queuedDeploy maintenancewindowtypes.QueuedDeploy) error
+	GetQueuedDeploy(appID string, clusterID string) (*maintenancewindowtypes.QueuedDeploy, error)
+	ListQueuedDeploys() ([]maintenancewindowtypes.QueuedDeploy, error)
+	DeleteQueuedDeploy(appID string, clusterID string) error
+}
+
+type DriftStore interface {
+	GetAppDriftStatus(appID string) (*operatortypes.AppDriftStatus, error)
+	SetAppDriftStatus(appID string, sequence int64, resources []operatortypes.DriftedResource, checkedAt time.Time, driftErr string) error
+	GetAppDriftAutoRemediate(appID string) (bool, error)
+	SetAppDriftAutoRemediate(appID string, autoRemediate bool) error
+}
+
+type PromotionStore interface {
+	ListPromotionStages(appID string) ([]promotiontypes.Stage, error)
+	// GetPromotionStage returns nil if the downstream is not the target of a stage
+	GetPromotionStage(appID string, targetClusterID string) (*promotiontypes.Stage, error)
+	SetPromotionStage(stage promotiontypes.Stage) error
+	DeletePromotionStage(appID string, targetClusterID string) error
+	CreatePromotion(promotion *promotiontypes.Promotion) error
+	ListPromotions(appID string, clusterID string, sequences []int64) ([]promotiontypes.Promotion, error)
+}
+
+type EncryptionStore interface {
+	ListEncryptedValues() ([]keyrotationtypes.EncryptedValue, error)
+	UpdateEncryptedValue(value keyrotationtypes.EncryptedValue) error
+}
+
+type ImageVerificationStore interface {
+	GetImageVerificationResults(appID string, sequence int64) ([]imagetypes.ImageVerificationResult, error)
+	SetImageVerificationResults(appID string, sequence int64, results []imagetypes.ImageVerificationResult) error
+}
@@ -8,12 +8,14 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/cluster"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/store"
 	"github.com/replicatedhq/kots/pkg/supportbundle/types"
 	"github.com/replicatedhq/troubleshoot/pkg/redact"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/supportbundle"
+	"k8s.io/client-go/rest"
 )
 
 type supportBundleProgressUpdate struct {
@@ -146,7 +148,8 @@ func executeUpdateRoutine(bundle *types.SupportBundle) chan interface{} {
 
 // executeSupportBundleCollectRoutine creates a goroutine to collect the support bundle, upload, analyze and
 // send redactors. The function takes a channel for progress updates and closes it when collectors are complete.
-func executeSupportBundleCollectRoutine(bundle *types.SupportBundle, progressChan chan interface{}) {
+// The spec and the redactors are always read from the cluster kotsadm runs in, the collectors run in the given cluster.
+func executeSupportBundleCollectRoutine(bundle *types.SupportBundle, clusterID string, progressChan chan interface{}) {
 
 	collectorCB := func(c chan interface{}, msg string) {
 		c <- supportBundleProgressUpdate{
@@ -156,7 +159,7 @@ func executeSupportBundleCollectRoutine(bundle *types.SupportBundle, progressCha
 		}
 	}
 
-	k8sconfig, err := k8sutil.GetClusterConfig()
+	k8sconfig, err := getClusterConfig(clusterID)
 	if err != nil {
 		err = errors.Wrap(err, fmt.Sprintf("Could not get k8s rest config for support bundle ID: %s", bundle.ID))
 		logger.Error(err)
@@ -288,3 +291,10 @@ func bundleErrorUpdate(bundle *types.SupportBundle) {
 		return
 	}
 }
+
+func getClusterConfig(clusterID string) (*rest.Config, error) {
+	if clusterID == "" {
+		return k8sutil.GetClusterConfig()
+	}
+	return cluster.GetRESTConfig(clusterID)
+}
@@ -37,7 +37,7 @@ const (
 // Collect will queue collection of a new support bundle.
 // It returns the ID of the support bundle so that the status can be queried by the
 // front end.
-func Collect(app *apptypes.App, clusterID string) (string, error) {
+func Collect(app *apptypes.App, clusterID string, trigger types.SupportBundleTrigger) (string, error) {
 	sequence := int64(0)
 
 	currentVersion, err := store.GetStore().GetCurrentDownstreamVersion(app.ID, clusterID)
@@ -58,6 +58,7 @@ func Collect(app *apptypes.App, clusterID string) (string, error) {
 
 	supportBundle.ID = strings.ToLower(ksuid.New().String())
 	supportBundle.Slug = supportBundle.ID
+	supportBundle.Trigger = trigger
 
 	err = store.GetStore().CreateInProgressSupportBundle(supportBundle)
 	if err != nil {
@@ -65,7 +66,7 @@ func Collect(app *apptypes.App, clusterID string) (string, error) {
 	}
 
 	progressChan := executeUpdateRoutine(supportBundle)
-	executeSupportBundleCollectRoutine(supportBundle, progressChan)
+	executeSupportBundleCollectRoutine(supportBundle, clusterID, progressChan)
 
 	return supportBundle.ID, nil
 }
@@ -3,6 +3,7 @@ package types
 import (
 	"time"
 
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
 	troubleshootv1beta2 "github.com/replicatedhq/troubleshoot/pkg/apis/troubleshoot/v1beta2"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -29,6 +30,7 @@ type SupportBundle struct {
 	Progress   SupportBundleProgress `json:"progress"`
 	URI        string                `json:"uri"`
 	RedactURIs []string              `json:"redactURIs"`
+	Trigger    SupportBundleTrigger  `json:"trigger,omitempty"`
 
 	BundleSpec          *troubleshootv1beta2.SupportBundle `json:"-"`
 	AdditionalRedactors *troubleshootv1beta2.Redactor      `json:"-"`
@@ -50,6 +52,27 @@ const (
 	BUNDLE_RUNNING  SupportBundleStatus = "running"
 )
 
+// SupportBundleTrigger is what started the collection of a support bundle
+type SupportBundleTrigger string
+
+const (
+	TriggerManual    SupportBundleTrigger = "manual"
+	TriggerScheduled SupportBundleTrigger = "scheduled"
+	TriggerAppState  SupportBundleTrigger = "app-state"
+)
+
+// SupportBundlePolicy configures the automatic collection and retention of the support bundles of an app
+type SupportBundlePolicy struct {
+	// Schedule is a cron spec to collect bundles on, empty disables scheduled collection
+	Schedule string `json:"schedule"`
+	// CollectOnStates collects a bundle when the app transitions into one of these states
+	CollectOnStates []appstatetypes.State `json:"collectOnStates"`
+	// RetainCount is the number of bundles to keep, 0 keeps all bundles
+	RetainCount int `json:"retainCount"`
+	// RetainAge is how long bundles are kept, e.g. 720h. Empty keeps bundles regardless of age
+	RetainAge string `json:"retainAge"`
+}
+
 type SupportBundleAnalysis struct {
 	Insights  []SupportBundleInsight `json:"insights"`
 	CreatedAt time.Time              `json:"createdAt"`
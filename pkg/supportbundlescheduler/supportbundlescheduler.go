@@ -0,0 +1,305 @@
+package supportbundlescheduler
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	downstreamtypes "github.com/replicatedhq/kots/pkg/api/downstream/types"
+	apptypes "github.com/replicatedhq/kots/pkg/app/types"
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/cluster"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/store"
+	"github.com/replicatedhq/kots/pkg/supportbundle"
+	"github.com/replicatedhq/kots/pkg/supportbundle/types"
+	cron "github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// jobs maps app ids to their cron jobs
+var jobs = make(map[string]*cron.Cron)
+var mtx sync.Mutex
+
+// collectMtx makes sure that two triggers that fire at the same time don't both start a bundle for the same app
+var collectMtx sync.Mutex
+
+// Start configures scheduled collection for every installed app, and starts pruning bundles that are past their retention
+func Start() error {
+	logger.Debug("starting support bundle scheduler")
+
+	appsList, err := store.GetStore().ListInstalledApps()
+	if err != nil {
+		return errors.Wrap(err, "failed to list installed apps")
+	}
+
+	for _, a := range appsList {
+		policy, err := store.GetStore().GetAppSupportBundlePolicy(a.ID)
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to get support bundle policy for app %s", a.Slug))
+			continue
+		}
+		if err := Configure(a, *policy); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to configure support bundle schedule for app %s", a.Slug))
+		}
+	}
+
+	go func() {
+		for {
+			retentionLoop()
+			time.Sleep(10 * time.Minute)
+		}
+	}()
+
+	return nil
+}
+
+// Validate returns an error if the schedule or the retention of the policy can't be parsed
+func Validate(policy types.SupportBundlePolicy) error {
+	if policy.Schedule != "" {
+		if _, err := cron.ParseStandard(policy.Schedule); err != nil {
+			return errors.Wrap(err, "invalid schedule")
+		}
+	}
+
+	for _, state := range policy.CollectOnStates {
+		switch state {
+		case appstatetypes.StateDegraded, appstatetypes.StateUnavailable, appstatetypes.StateMissing:
+		default:
+			return errors.Errorf("cannot collect on state %q", state)
+		}
+	}
+
+	if policy.RetainCount < 0 {
+		return errors.New("retain count cannot be negative")
+	}
+
+	if policy.RetainAge != "" {
+		retainAge, err := time.ParseDuration(policy.RetainAge)
+		if err != nil {
+			return errors.Wrap(err, "invalid retain age")
+		}
+		if retainAge <= 0 {
+			return errors.New("retain age must be positive")
+		}
+	}
+
+	return nil
+}
+
+// Configure starts, updates or stops the scheduled collection of support bundles for an app
+func Configure(a *apptypes.App, policy types.SupportBundlePolicy) error {
+	logger.Debug("configure support bundle schedule for app",
+		zap.String("slug", a.Slug))
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	job, ok := jobs[a.ID]
+	if policy.Schedule == "" {
+		if ok {
+			job.Stop()
+			delete(jobs, a.ID)
+		}
+		return nil
+	}
+
+	if ok {
+		// job already exists, remove entries
+		for _, entry := range job.Entries() {
+			job.Remove(entry.ID)
+		}
+	} else {
+		job = cron.New(cron.WithChain(
+			cron.Recover(cron.DefaultLogger),
+		))
+	}
+
+	jobAppID := a.ID
+	jobAppSlug := a.Slug
+
+	_, err := job.AddFunc(policy.Schedule, func() {
+		logger.Debug("collecting scheduled support bundle for app", zap.String("slug", jobAppSlug))
+
+		if err := collect(jobAppID, "", types.TriggerScheduled); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to collect scheduled support bundle for app %s", jobAppSlug))
+		}
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to add func")
+	}
+
+	job.Start()
+	jobs[a.ID] = job
+
+	return nil
+}
+
+// HandleAppStateChange collects a support bundle if the policy of the app asks for one when it transitions into the new state.
+// An empty cluster id is the cluster kotsadm runs in.
+func HandleAppStateChange(appID string, clusterID string, previousState appstatetypes.State, newState appstatetypes.State) error {
+	if previousState == newState {
+		return nil
+	}
+
+	policy, err := store.GetStore().GetAppSupportBundlePolicy(appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get support bundle policy")
+	}
+	if !shouldCollectOnState(*policy, newState) {
+		return nil
+	}
+
+	logger.Info("collecting support bundle after app state change",
+		zap.String("appID", appID),
+		zap.String("previousState", string(previousState)),
+		zap.String("state", string(newState)))
+
+	if err := collect(appID, clusterID, types.TriggerAppState); err != nil {
+		return errors.Wrap(err, "failed to collect support bundle")
+	}
+
+	return nil
+}
+
+func shouldCollectOnState(policy types.SupportBundlePolicy, state appstatetypes.State) bool {
+	for _, s := range policy.CollectOnStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// collect starts collecting a support bundle and prunes the bundles the new one replaces.
+// Nothing is collected while another bundle is running for the app, so that a flapping app doesn't collect a bundle for every transition.
+func collect(appID string, clusterID string, trigger types.SupportBundleTrigger) error {
+	collectMtx.Lock()
+	defer collectMtx.Unlock()
+
+	a, err := store.GetStore().GetApp(appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get app")
+	}
+
+	bundles, err := store.GetStore().ListSupportBundles(a.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list support bundles")
+	}
+	for _, b := range bundles {
+		if b.Status == types.BUNDLE_RUNNING {
+			logger.Infof("not collecting a support bundle for app %s because bundle %s is still running", a.Slug, b.ID)
+			return nil
+		}
+	}
+
+	if clusterID == "" {
+		downstreams, err := store.GetStore().ListDownstreamsForApp(a.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to list downstreams for app")
+		}
+		clusterID = localClusterID(downstreams)
+		if clusterID == "" {
+			return errors.New("no downstream for the cluster kotsadm runs in")
+		}
+	}
+
+	bundleID, err := supportbundle.Collect(a, clusterID, trigger)
+	if err != nil {
+		return errors.Wrap(err, "failed to collect support bundle")
+	}
+	logger.Infof("started %s support bundle %s for app %s", trigger, bundleID, a.Slug)
+
+	if err := prune(a.ID); err != nil {
+		return errors.Wrap(err, "failed to prune support bundles")
+	}
+
+	return nil
+}
+
+func localClusterID(downstreams []downstreamtypes.Downstream) string {
+	for _, d := range downstreams {
+		if !cluster.IsRemote(&d) {
+			return d.ClusterID
+		}
+	}
+	return ""
+}
+
+func retentionLoop() {
+	appsList, err := store.GetStore().ListInstalledApps()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list installed apps for support bundle retention"))
+		return
+	}
+
+	for _, a := range appsList {
+		if err := prune(a.ID); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to prune support bundles for app %s", a.Slug))
+		}
+	}
+}
+
+// prune deletes the bundles of an app that are past the retention of its policy
+func prune(appID string) error {
+	policy, err := store.GetStore().GetAppSupportBundlePolicy(appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get support bundle policy")
+	}
+	if policy.RetainCount == 0 && policy.RetainAge == "" {
+		return nil
+	}
+
+	bundles, err := store.GetStore().ListSupportBundles(appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list support bundles")
+	}
+
+	toPrune, err := bundlesToPrune(bundles, *policy, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "failed to get bundles to prune")
+	}
+
+	for _, b := range toPrune {
+		if err := store.GetStore().DeleteSupportBundle(b.ID, appID); err != nil {
+			return errors.Wrapf(err, "failed to delete support bundle %s", b.ID)
+		}
+		logger.Infof("deleted support bundle %s of app %s because it is past its retention", b.ID, appID)
+	}
+
+	return nil
+}
+
+// bundlesToPrune returns the bundles that are older than the retain age, or beyond the retain count counting from the newest bundle.
+// Running bundles count towards the retain count but are never pruned.
+func bundlesToPrune(bundles []*types.SupportBundle, policy types.SupportBundlePolicy, now time.Time) ([]*types.SupportBundle, error) {
+	var retainAge time.Duration
+	if policy.RetainAge != "" {
+		d, err := time.ParseDuration(policy.RetainAge)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse retain age")
+		}
+		retainAge = d
+	}
+
+	sorted := make([]*types.SupportBundle, len(bundles))
+	copy(sorted, bundles)
+	sort.Sort(sort.Reverse(types.ByCreated(sorted)))
+
+	toPrune := []*types.SupportBundle{}
+	for i, b := range sorted {
+		if b.Status == types.BUNDLE_RUNNING {
+			continue
+		}
+		if policy.RetainCount > 0 && i >= policy.RetainCount {
+			toPrune = append(toPrune, b)
+			continue
+		}
+		if retainAge > 0 && b.CreatedAt.Before(now.Add(-retainAge)) {
+			toPrune = append(toPrune, b)
+		}
+	}
+
+	return toPrune, nil
+}
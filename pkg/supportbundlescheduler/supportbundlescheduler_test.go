@@ -0,0 +1,146 @@
+package supportbundlescheduler
+
+import (
+	"testing"
+	"time"
+
+	appstatetypes "github.com/replicatedhq/kots/pkg/appstate/types"
+	"github.com/replicatedhq/kots/pkg/supportbundle/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  types.SupportBundlePolicy
+		wantErr bool
+	}{
+		{
+			name:   "empty policy",
+			policy: types.SupportBundlePolicy{},
+		},
+		{
+			name: "valid policy",
+			policy: types.SupportBundlePolicy{
+				Schedule:        "0 */6 * * *",
+				CollectOnStates: []appstatetypes.State{appstatetypes.StateDegraded, appstatetypes.StateUnavailable},
+				RetainCount:     10,
+				RetainAge:       "168h",
+			},
+		},
+		{
+			name: "invalid schedule",
+			policy: types.SupportBundlePolicy{
+				Schedule: "every hour",
+			},
+			wantErr: true,
+		},
+		{
+			name: "ready state",
+			policy: types.SupportBundlePolicy{
+				CollectOnStates: []appstatetypes.State{appstatetypes.StateReady},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative retain count",
+			policy: types.SupportBundlePolicy{
+				RetainCount: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid retain age",
+			policy: types.SupportBundlePolicy{
+				RetainAge: "7d",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.policy)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_bundlesToPrune(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	bundle := func(id string, age time.Duration, status types.SupportBundleStatus) *types.SupportBundle {
+		return &types.SupportBundle{
+			ID:        id,
+			Status:    status,
+			CreatedAt: now.Add(-age),
+		}
+	}
+
+	// not sorted, to make sure the newest bundles are kept regardless of the order they are listed in
+	bundles := []*types.SupportBundle{
+		bundle("3d", 3*24*time.Hour, types.BUNDLE_UPLOADED),
+		bundle("running", 0, types.BUNDLE_RUNNING),
+		bundle("1h", time.Hour, types.BUNDLE_UPLOADED),
+		bundle("10d", 10*24*time.Hour, types.BUNDLE_FAILED),
+		bundle("1d", 24*time.Hour, types.BUNDLE_UPLOADED),
+	}
+
+	tests := []struct {
+		name   string
+		policy types.SupportBundlePolicy
+		want   []string
+	}{
+		{
+			name:   "no retention",
+			policy: types.SupportBundlePolicy{},
+			want:   []string{},
+		},
+		{
+			name: "retain count includes running bundles",
+			policy: types.SupportBundlePolicy{
+				RetainCount: 3,
+			},
+			want: []string{"3d", "10d"},
+		},
+		{
+			name: "retain age",
+			policy: types.SupportBundlePolicy{
+				RetainAge: "48h",
+			},
+			want: []string{"3d", "10d"},
+		},
+		{
+			name: "retain count and age",
+			policy: types.SupportBundlePolicy{
+				RetainCount: 4,
+				RetainAge:   "120h",
+			},
+			want: []string{"10d"},
+		},
+		{
+			name: "running bundles are never pruned",
+			policy: types.SupportBundlePolicy{
+				RetainCount: 1,
+				RetainAge:   "1m",
+			},
+			want: []string{"1h", "1d", "3d", "10d"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := bundlesToPrune(bundles, tt.policy, now)
+			require.NoError(t, err)
+
+			gotIDs := []string{}
+			for _, b := range got {
+				gotIDs = append(gotIDs, b.ID)
+			}
+			assert.Equal(t, tt.want, gotIDs)
+		})
+	}
+}
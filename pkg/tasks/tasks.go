@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/eventstream"
+	eventstreamtypes "github.com/replicatedhq/kots/pkg/eventstream/types"
 	"github.com/replicatedhq/kots/pkg/logger"
 	"github.com/replicatedhq/kots/pkg/persistence"
 	"github.com/replicatedhq/kots/pkg/util"
@@ -84,6 +86,8 @@ ON CONFLICT(id) DO UPDATE SET
 		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
 	}
 
+	publishTaskStatus(id, message, status)
+
 	return nil
 }
 
@@ -114,9 +118,22 @@ func ClearTaskStatus(id string) error {
 		return fmt.Errorf("failed to write: %v: %v", err, wr.Err)
 	}
 
+	publishTaskStatus(id, "", "")
+
 	return nil
 }
 
+func publishTaskStatus(id string, message string, status string) {
+	eventstream.Publish(eventstreamtypes.Event{
+		Type: eventstreamtypes.EventTaskStatus,
+		Data: eventstreamtypes.TaskStatus{
+			ID:      id,
+			Status:  status,
+			Message: message,
+		},
+	})
+}
+
 func GetTaskStatus(id string) (string, string, error) {
 	db := persistence.MustGetDBSession()
 
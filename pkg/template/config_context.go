@@ -1,6 +1,7 @@
 package template
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"regexp"
@@ -13,6 +14,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/image"
 	"github.com/replicatedhq/kots/pkg/imageutil"
 	registrytypes "github.com/replicatedhq/kots/pkg/registry/types"
+	"github.com/replicatedhq/kots/pkg/secretprovider"
 	kotsv1beta1 "github.com/replicatedhq/kotskinds/apis/kots/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -100,7 +102,14 @@ func (b *Builder) newConfigContext(configGroups []kotsv1beta1.ConfigGroup, exist
 			// decrypt password if it exists
 			if configItem.Type == "password" && configCtx.DecryptValues {
 				existingVal, ok := existingValues[configItem.Name]
-				if ok && existingVal.HasValue() {
+				if ok && existingVal.HasValue() && secretprovider.IsReference(existingVal.ValueStr()) {
+					val, err := secretprovider.Resolve(context.TODO(), existingVal.ValueStr())
+					if err != nil {
+						return nil, errors.Wrapf(err, "failed to resolve secret for config item %s", configItem.Name)
+					}
+					existingVal.Value = val
+					existingValues[configItem.Name] = existingVal
+				} else if ok && existingVal.HasValue() {
 					val, err := decrypt(existingVal.ValueStr())
 					if err == nil {
 						existingVal.Value = val
@@ -15,6 +15,9 @@ import (
 	upstream "github.com/replicatedhq/kots/pkg/kotsadmupstream"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
 	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/metrics"
+	"github.com/replicatedhq/kots/pkg/notifications"
+	notificationstypes "github.com/replicatedhq/kots/pkg/notifications/types"
 	"github.com/replicatedhq/kots/pkg/preflight"
 	preflighttypes "github.com/replicatedhq/kots/pkg/preflight/types"
 	kotspull "github.com/replicatedhq/kots/pkg/pull"
@@ -202,7 +205,9 @@ func CheckForUpdates(opts types.CheckForUpdatesOpts) (ucr *types.UpdateCheckResp
 
 	tasks.StartTaskMonitor("update-download", finishedChan)
 
+	startedAt := time.Now()
 	ucr, finalError = checkForKotsAppUpdates(opts, finishedChan)
+	metrics.ObserveUpdateCheck(opts.AppID, updateCheckOutcome(ucr, finalError), time.Since(startedAt))
 	if finalError != nil {
 		finalError = errors.Wrap(finalError, "failed to get kots app updates")
 		return
@@ -211,49 +216,81 @@ func CheckForUpdates(opts types.CheckForUpdatesOpts) (ucr *types.UpdateCheckResp
 	return
 }
 
-func checkForKotsAppUpdates(opts types.CheckForUpdatesOpts, finishedChan chan<- error) (*types.UpdateCheckResponse, error) {
-	a, err := store.GetApp(opts.AppID)
+func updateCheckOutcome(ucr *types.UpdateCheckResponse, err error) string {
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get app")
+		return metrics.UpdateCheckOutcomeError
 	}
-
-	// sync license, this method is only called when online
-	latestLicense, _, err := license.Sync(a, "", false)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to sync license")
+	if ucr == nil || ucr.AvailableUpdates == 0 {
+		return metrics.UpdateCheckOutcomeNoUpdates
 	}
+	return metrics.UpdateCheckOutcomeUpdatesAvailable
+}
 
-	// reload app because license sync could have created a new release
-	a, err = store.GetApp(opts.AppID)
+func checkForKotsAppUpdates(opts types.CheckForUpdatesOpts, finishedChan chan<- error) (*types.UpdateCheckResponse, error) {
+	a, err := store.GetApp(opts.AppID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get app")
 	}
 
-	licenseChan, err := kotsutil.FindChannelInLicense(a.SelectedChannelID, latestLicense)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to find channel in license after sync")
-	}
+	var updates *upstreamtypes.UpdateCheckResult
+	isSemverRequired := false
+	if util.IsUnlicensedUpstreamURI(a.UpstreamURI) {
+		// helm charts and git repositories don't have a license or channels
+		updateCursor, err := store.GetCurrentUpdateCursor(a.ID, "")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get current update cursor")
+		}
 
-	updateCursor, err := store.GetCurrentUpdateCursor(a.ID, licenseChan.ChannelID)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get current update cursor")
-	}
+		getUpdatesOptions := kotspull.GetUpdatesOptions{
+			LastUpdateCheckAt: a.LastUpdateCheckAt,
+			CurrentCursor:     updateCursor,
+			Silent:            false,
+		}
 
-	getUpdatesOptions := kotspull.GetUpdatesOptions{
-		License:            latestLicense,
-		LastUpdateCheckAt:  a.LastUpdateCheckAt,
-		CurrentCursor:      updateCursor,
-		CurrentChannelID:   licenseChan.ChannelID,
-		CurrentChannelName: licenseChan.ChannelName,
-		ChannelChanged:     a.ChannelChanged,
-		Silent:             false,
-		ReportingInfo:      reporting.GetReportingInfo(a.ID),
-	}
+		updates, err = kotspull.GetUpdates(a.UpstreamURI, getUpdatesOptions)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get updates")
+		}
+	} else {
+		// sync license, this method is only called when online
+		latestLicense, _, err := license.Sync(a, "", false)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to sync license")
+		}
 
-	// get updates
-	updates, err := kotspull.GetUpdates(fmt.Sprintf("replicated://%s", latestLicense.Spec.AppSlug), getUpdatesOptions)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get updates")
+		// reload app because license sync could have created a new release
+		a, err = store.GetApp(opts.AppID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get app")
+		}
+
+		licenseChan, err := kotsutil.FindChannelInLicense(a.SelectedChannelID, latestLicense)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to find channel in license after sync")
+		}
+		isSemverRequired = licenseChan.IsSemverRequired
+
+		updateCursor, err := store.GetCurrentUpdateCursor(a.ID, licenseChan.ChannelID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get current update cursor")
+		}
+
+		getUpdatesOptions := kotspull.GetUpdatesOptions{
+			License:            latestLicense,
+			LastUpdateCheckAt:  a.LastUpdateCheckAt,
+			CurrentCursor:      updateCursor,
+			CurrentChannelID:   licenseChan.ChannelID,
+			CurrentChannelName: licenseChan.ChannelName,
+			ChannelChanged:     a.ChannelChanged,
+			Silent:             false,
+			ReportingInfo:      reporting.GetReportingInfo(a.ID),
+		}
+
+		// get updates
+		updates, err = kotspull.GetUpdates(fmt.Sprintf("replicated://%s", latestLicense.Spec.AppSlug), getUpdatesOptions)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get updates")
+		}
 	}
 
 	downstreams, err := store.ListDownstreamsForApp(a.ID)
@@ -274,7 +311,7 @@ func checkForKotsAppUpdates(opts types.CheckForUpdatesOpts, finishedChan chan<-
 		return nil, errors.Errorf("no app versions found for app %s in downstream %s", opts.AppID, d.ClusterID)
 	}
 
-	filteredUpdates := removeOldUpdates(updates.Updates, appVersions, licenseChan.IsSemverRequired)
+	filteredUpdates := removeOldUpdates(updates.Updates, appVersions, isSemverRequired)
 
 	var availableReleases []types.UpdateCheckRelease
 	availableSequence := appVersions.AllVersions[0].Sequence + 1
@@ -309,6 +346,15 @@ func checkForKotsAppUpdates(opts types.CheckForUpdatesOpts, finishedChan chan<-
 		return &ucr, nil
 	}
 
+	notifications.Notify(notificationstypes.Event{
+		Type:    notificationstypes.EventVersionAvailable,
+		AppID:   a.ID,
+		AppSlug: a.Slug,
+		Data: map[string]interface{}{
+			"availableReleases": availableReleases,
+		},
+	})
+
 	// this is to avoid a race condition where the UI polls the task status before it is set by the goroutine
 	status := fmt.Sprintf("%d Updates available...", ucr.AvailableUpdates)
 	if err := tasks.SetTaskStatus("update-download", status, "running"); err != nil {
@@ -640,6 +686,14 @@ func deployVersion(opts types.CheckForUpdatesOpts, clusterID string, appVersions
 			}
 		}
 
+		queued, err := maintenancewindow.QueueIfClosed(opts.AppID, clusterID, versionToDeploy.Sequence, deploySource(opts))
+		if err != nil {
+			return errors.Wrap(err, "failed to check maintenance windows")
+		}
+		if queued {
+			return nil
+		}
+
 		if err := version.DeployVersion(opts.AppID, versionToDeploy.Sequence); err != nil {
 			return errors.Wrap(err, "failed to deploy version")
 		}
@@ -656,6 +710,16 @@ func deployVersion(opts types.CheckForUpdatesOpts, clusterID string, appVersions
 	return nil
 }
 
+func deploySource(opts types.CheckForUpdatesOpts) string {
+	if opts.IsCLI {
+		return maintenancewindowtypes.DeploySourceCLI
+	}
+	if opts.IsAutomatic {
+		return maintenancewindowtypes.DeploySourceAutoDeploy
+	}
+	return maintenancewindowtypes.DeploySourceAPI
+}
+
 type sortableUpdate struct {
 	Sequence       int64
 	Semver         *semver.Version
@@ -339,7 +339,14 @@ func (h *Handler) SaveConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	values := kotsKinds.ConfigValues.Spec.Values
-	kotsKinds.ConfigValues.Spec.Values = kotsadmconfig.UpdateAppConfigValues(values, request.ConfigGroups)
+	updatedValues, err := kotsadmconfig.UpdateAppConfigValues(values, request.ConfigGroups)
+	if err != nil {
+		response.Error = "failed to update config values"
+		logger.Error(errors.Wrap(err, response.Error))
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+	kotsKinds.ConfigValues.Spec.Values = updatedValues
 
 	configValuesSpec, err := kotsKinds.Marshal("kots.io", "v1beta1", "ConfigValues")
 	if err != nil {
@@ -0,0 +1,422 @@
+package upstream
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	semver "github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"github.com/replicatedhq/kots/pkg/upstream/types"
+	kotsv1beta1 "github.com/replicatedhq/kotskinds/apis/kots/v1beta1"
+	kotsv1beta2 "github.com/replicatedhq/kotskinds/apis/kots/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	serializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+const (
+	chartUpstreamSchemeOCI  = "oci"
+	chartUpstreamSchemeHelm = "helm"
+)
+
+// the largest minor and patch that fit in a chart version cursor
+const chartCursorComponentMax = 1000000
+
+// chartUpstream is a helm chart that is tracked directly from an OCI registry (oci://registry/path/chart)
+// or a chart repository (helm://host/path/chart). A version can be pinned with the "version" query param
+// or, for OCI registries, with a tag (oci://registry/path/chart:1.0.0).
+// Credentials are read from the "username" and "password" keys of the secret named by the "secret" query param.
+type chartUpstream struct {
+	Scheme            string
+	Host              string
+	Path              string
+	ChartName         string
+	Version           string
+	PlainHTTP         bool
+	CredentialsSecret string
+	Username          string
+	Password          string
+}
+
+// chartVersion is a version of a chart that is published to the upstream
+type chartVersion struct {
+	Version    *semver.Version
+	Tag        string
+	URL        string
+	ReleasedAt *time.Time
+}
+
+func parseChartUpstream(u *url.URL) (*chartUpstream, error) {
+	if u.Scheme != chartUpstreamSchemeOCI && u.Scheme != chartUpstreamSchemeHelm {
+		return nil, errors.Errorf("unsupported chart upstream scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, errors.New("chart upstream is missing a host")
+	}
+
+	chartPath := strings.Trim(u.Path, "/")
+	if chartPath == "" {
+		return nil, errors.New("chart upstream is missing a chart name")
+	}
+	repoPath, chartName := path.Split(chartPath)
+
+	version := ""
+	if u.Scheme == chartUpstreamSchemeOCI {
+		if strings.Contains(chartName, "@") {
+			return nil, errors.New("chart upstreams cannot be pinned to a digest")
+		}
+		if i := strings.LastIndex(chartName, ":"); i != -1 {
+			chartName, version = chartName[:i], chartName[i+1:]
+		}
+	}
+	if chartName == "" {
+		return nil, errors.New("chart upstream is missing a chart name")
+	}
+
+	query := u.Query()
+	if v := query.Get("version"); v != "" {
+		if version != "" && version != v {
+			return nil, errors.Errorf("chart upstream tag %q does not match version %q", version, v)
+		}
+		version = v
+	}
+
+	plainHTTP := isLocalhost(u.Hostname())
+	if v := query.Get("plainHTTP"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse plainHTTP")
+		}
+		plainHTTP = b
+	}
+
+	c := &chartUpstream{
+		Scheme:            u.Scheme,
+		Host:              u.Host,
+		Path:              strings.TrimSuffix(repoPath, "/"),
+		ChartName:         chartName,
+		Version:           version,
+		PlainHTTP:         plainHTTP,
+		CredentialsSecret: query.Get("secret"),
+	}
+
+	// credentials can be included in the uri when it is not persisted, e.g. with kots pull
+	if u.User != nil {
+		if c.CredentialsSecret != "" {
+			return nil, errors.New("credentials cannot be included in the uri when a secret is set")
+		}
+		c.Username = u.User.Username()
+		c.Password, _ = u.User.Password()
+	}
+
+	return c, nil
+}
+
+// loadCredentials reads the credentials for the registry or chart repository from the referenced secret in the kotsadm namespace
+func (c *chartUpstream) loadCredentials() error {
+	if c.CredentialsSecret == "" {
+		return nil
+	}
+
+	username, password, err := getUpstreamCredentials(c.CredentialsSecret)
+	if err != nil {
+		return err
+	}
+	c.Username, c.Password = username, password
+
+	return nil
+}
+
+func (c *chartUpstream) hasCredentials() bool {
+	return c.Username != "" || c.Password != ""
+}
+
+// isLocalhost returns true for registries that are only reachable from this machine, such as the temp registry,
+// which do not serve tls
+func isLocalhost(hostname string) bool {
+	if hostname == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(hostname)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (c *chartUpstream) listVersions() ([]chartVersion, error) {
+	var versions []chartVersion
+	var err error
+	switch c.Scheme {
+	case chartUpstreamSchemeOCI:
+		versions, err = c.listRegistryVersions()
+	case chartUpstreamSchemeHelm:
+		versions, err = c.listRepoVersions()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Version.LessThan(versions[j].Version)
+	})
+
+	return versions, nil
+}
+
+func (c *chartUpstream) pullChart(version chartVersion) ([]byte, error) {
+	switch c.Scheme {
+	case chartUpstreamSchemeOCI:
+		return c.pullRegistryChart(version)
+	case chartUpstreamSchemeHelm:
+		return c.pullRepoChart(version)
+	}
+	return nil, errors.Errorf("unsupported chart upstream scheme %q", c.Scheme)
+}
+
+// trackableVersions returns the versions that are considered for updates.
+// Prereleases and builds are only installed when pinned since they cannot be told apart by cursor.
+func trackableVersions(versions []chartVersion) []chartVersion {
+	trackable := []chartVersion{}
+	for _, v := range versions {
+		if v.Version.Prerelease() != "" || v.Version.Metadata() != "" {
+			continue
+		}
+		if v.Version.Minor() >= chartCursorComponentMax || v.Version.Patch() >= chartCursorComponentMax {
+			logger.Debugf("skipping chart version %s because it cannot be tracked", v.Version.Original())
+			continue
+		}
+		trackable = append(trackable, v)
+	}
+	return trackable
+}
+
+// chartVersionCursor encodes a chart version as an update cursor, so that cursors sort in the same order as versions
+func chartVersionCursor(v *semver.Version) string {
+	return strconv.FormatUint(v.Major()*chartCursorComponentMax*chartCursorComponentMax+v.Minor()*chartCursorComponentMax+v.Patch(), 10)
+}
+
+// pickChartVersion returns the version to download. The version pinned in the upstream uri takes precedence,
+// followed by the version of the current cursor, the requested version label, and finally the latest version.
+func (c *chartUpstream) pickChartVersion(versions []chartVersion, cursor string, versionLabel string) (*chartVersion, error) {
+	if c.Version != "" {
+		v := findChartVersion(versions, c.Version)
+		if v == nil {
+			return nil, errors.Errorf("version %s of chart %s not found", c.Version, c.ChartName)
+		}
+		return v, nil
+	}
+
+	trackable := trackableVersions(versions)
+
+	if cursor != "" {
+		for i := range trackable {
+			if chartVersionCursor(trackable[i].Version) == cursor {
+				return &trackable[i], nil
+			}
+		}
+	}
+
+	if versionLabel != "" {
+		v := findChartVersion(versions, versionLabel)
+		if v == nil {
+			return nil, errors.Errorf("version %s of chart %s not found", versionLabel, c.ChartName)
+		}
+		return v, nil
+	}
+
+	if len(trackable) == 0 {
+		return nil, errors.Errorf("no versions of chart %s found", c.ChartName)
+	}
+	return &trackable[len(trackable)-1], nil
+}
+
+func findChartVersion(versions []chartVersion, version string) *chartVersion {
+	want, err := semver.NewVersion(version)
+	if err != nil {
+		return nil
+	}
+	for i := range versions {
+		if versions[i].Version.Equal(want) {
+			return &versions[i]
+		}
+	}
+	return nil
+}
+
+func downloadChartUpstream(u *url.URL, fetchOptions *types.FetchOptions) (*types.Upstream, error) {
+	c, err := parseChartUpstream(u)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse chart upstream")
+	}
+	if err := c.loadCredentials(); err != nil {
+		return nil, errors.Wrap(err, "failed to load chart upstream credentials")
+	}
+
+	versions, err := c.listVersions()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list chart versions")
+	}
+
+	version, err := c.pickChartVersion(versions, pickCursor(fetchOptions).Cursor, pickVersionLabel(fetchOptions))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pick chart version")
+	}
+
+	archive, err := c.pullChart(*version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to pull chart version %s", version.Version.Original())
+	}
+
+	files, err := c.upstreamFiles(*version, archive)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create upstream files")
+	}
+
+	if fetchOptions.ConfigValues != nil {
+		files = append(files, types.UpstreamFile{
+			Path:    path.Join("userdata", "config.yaml"),
+			Content: mustMarshalConfigValues(fetchOptions.ConfigValues),
+		})
+	}
+
+	return &types.Upstream{
+		URI:          u.String(),
+		Name:         c.ChartName,
+		Type:         c.Scheme,
+		Files:        files,
+		UpdateCursor: chartVersionCursor(version.Version),
+		VersionLabel: version.Version.Original(),
+		ReleasedAt:   version.ReleasedAt,
+	}, nil
+}
+
+func getUpdatesChartUpstream(u *url.URL, fetchOptions *types.FetchOptions) (*types.UpdateCheckResult, error) {
+	c, err := parseChartUpstream(u)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse chart upstream")
+	}
+	if err := c.loadCredentials(); err != nil {
+		return nil, errors.Wrap(err, "failed to load chart upstream credentials")
+	}
+
+	updateCheckTime := time.Now()
+
+	// a pinned version never has updates
+	if c.Version != "" {
+		return &types.UpdateCheckResult{
+			Updates:         []types.Update{},
+			UpdateCheckTime: updateCheckTime,
+		}, nil
+	}
+
+	var currentCursor uint64
+	if fetchOptions.CurrentCursor != "" {
+		currentCursor, err = strconv.ParseUint(fetchOptions.CurrentCursor, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse cursor %q", fetchOptions.CurrentCursor)
+		}
+	}
+
+	versions, err := c.listVersions()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list chart versions")
+	}
+
+	updates := []types.Update{}
+	for _, v := range trackableVersions(versions) {
+		cursor := chartVersionCursor(v.Version)
+		if n, _ := strconv.ParseUint(cursor, 10, 64); n <= currentCursor {
+			continue
+		}
+		updates = append(updates, types.Update{
+			Cursor:       cursor,
+			VersionLabel: v.Version.Original(),
+			ReleasedAt:   v.ReleasedAt,
+		})
+	}
+
+	if fetchOptions.SortOrder == "desc" {
+		for i, j := 0, len(updates)-1; i < j; i, j = i+1, j-1 {
+			updates[i], updates[j] = updates[j], updates[i]
+		}
+	}
+
+	return &types.UpdateCheckResult{
+		Updates:         updates,
+		UpdateCheckTime: updateCheckTime,
+	}, nil
+}
+
+// upstreamFiles returns the chart archive with the kots kinds that are needed to install it
+func (c *chartUpstream) upstreamFiles(version chartVersion, archive []byte) ([]types.UpstreamFile, error) {
+	helmChart := &kotsv1beta2.HelmChart{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "kots.io/v1beta2",
+			Kind:       "HelmChart",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: c.ChartName,
+		},
+		Spec: kotsv1beta2.HelmChartSpec{
+			Chart: kotsv1beta2.ChartIdentifier{
+				Name:         c.ChartName,
+				ChartVersion: version.Version.Original(),
+			},
+			ReleaseName: c.ChartName,
+		},
+	}
+
+	application := &kotsv1beta1.Application{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "kots.io/v1beta1",
+			Kind:       "Application",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: c.ChartName,
+		},
+		Spec: kotsv1beta1.ApplicationSpec{
+			Title: c.ChartName,
+		},
+	}
+
+	helmChartContent, err := marshalKotsKind(helmChart)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal helm chart")
+	}
+	applicationContent, err := marshalKotsKind(application)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal application")
+	}
+
+	return []types.UpstreamFile{
+		{
+			Path:    fmt.Sprintf("%s-%s.tgz", c.ChartName, version.Version.Original()),
+			Content: archive,
+		},
+		{
+			Path:    "helmchart.yaml",
+			Content: helmChartContent,
+		},
+		{
+			Path:    "application.yaml",
+			Content: applicationContent,
+		},
+	}, nil
+}
+
+func marshalKotsKind(obj runtime.Object) ([]byte, error) {
+	s := serializer.NewYAMLSerializer(serializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme)
+
+	var b bytes.Buffer
+	if err := s.Encode(obj, &b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,25 @@
+package upstream
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getUpstreamCredentials returns the "username" and "password" keys of an upstream credentials secret in the kotsadm namespace
+func getUpstreamCredentials(secretName string) (string, string, error) {
+	clientset, err := k8sutil.GetClientset()
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to get clientset")
+	}
+
+	secret, err := clientset.CoreV1().Secrets(util.PodNamespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to get secret %s", secretName)
+	}
+
+	return string(secret.Data["username"]), string(secret.Data["password"]), nil
+}
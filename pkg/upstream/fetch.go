@@ -63,6 +63,12 @@ func downloadUpstream(upstreamURI string, fetchOptions *types.FetchOptions) (*ty
 			fetchOptions.AppSelectedChannelID,
 		)
 	}
+	if util.IsChartUpstreamURI(upstreamURI) {
+		return downloadChartUpstream(u, fetchOptions)
+	}
+	if util.IsGitUpstreamURI(upstreamURI) {
+		return downloadGitUpstream(u, fetchOptions)
+	}
 
 	return nil, errors.Errorf("unknown protocol scheme %q", u.Scheme)
 }
@@ -0,0 +1,518 @@
+package upstream
+
+import (
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	semver "github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/upstream/types"
+	"github.com/replicatedhq/kots/pkg/util"
+)
+
+const (
+	gitTrackCommits = "commits"
+	gitTrackTags    = "tags"
+)
+
+// gitUpstream is an application that is read from a git repository, e.g. git+https://github.com/org/app.git.
+// The "ref" query param checks out a branch, tag or commit, and "path" reads the application from a sub directory.
+// Credentials for http(s) remotes are read from the "username" and "password" keys of the secret named by the "secret" query param.
+// When "ref" is a branch (or is not set), every new commit on the branch is an update. With "track=tags",
+// every new semver tag is an update instead. Tags and commits are pinned and never have updates.
+type gitUpstream struct {
+	CloneURL          string
+	Auth              transport.AuthMethod
+	CredentialsSecret string
+	Ref               string
+	Path              string
+	Track             string
+}
+
+// gitRef is a commit that can be installed from the repository
+type gitRef struct {
+	Name    string
+	Version *semver.Version
+	Hash    plumbing.Hash
+}
+
+func parseGitUpstream(u *url.URL) (*gitUpstream, error) {
+	if !util.IsGitUpstreamURI(u.String()) {
+		return nil, errors.Errorf("unsupported git upstream scheme %q", u.Scheme)
+	}
+
+	query := u.Query()
+
+	g := &gitUpstream{
+		CredentialsSecret: query.Get("secret"),
+		Ref:               query.Get("ref"),
+		Path:              strings.Trim(query.Get("path"), "/"),
+		Track:             query.Get("track"),
+	}
+
+	if g.Track == "" {
+		g.Track = gitTrackCommits
+	}
+	if g.Track != gitTrackCommits && g.Track != gitTrackTags {
+		return nil, errors.Errorf("unsupported track %q, must be %q or %q", g.Track, gitTrackCommits, gitTrackTags)
+	}
+	if g.Track == gitTrackTags && g.Ref != "" {
+		return nil, errors.New("ref cannot be set when tracking tags")
+	}
+	if strings.Contains(g.Path, "..") {
+		return nil, errors.Errorf("invalid path %q", g.Path)
+	}
+
+	cloneURL := *u
+	cloneURL.Scheme = strings.TrimPrefix(u.Scheme, "git+")
+	cloneURL.RawQuery = ""
+	cloneURL.Fragment = ""
+
+	// credentials for http(s) remotes can be included in the uri when it is not persisted, e.g. with kots pull
+	if u.User != nil && strings.HasPrefix(cloneURL.Scheme, "http") {
+		if g.CredentialsSecret != "" {
+			return nil, errors.New("credentials cannot be included in the uri when a secret is set")
+		}
+		password, _ := u.User.Password()
+		g.Auth = &githttp.BasicAuth{
+			Username: u.User.Username(),
+			Password: password,
+		}
+		cloneURL.User = nil
+	}
+
+	g.CloneURL = cloneURL.String()
+
+	return g, nil
+}
+
+// loadCredentials reads the credentials for the remote from the referenced secret in the kotsadm namespace
+func (g *gitUpstream) loadCredentials() error {
+	if g.CredentialsSecret == "" {
+		return nil
+	}
+	if !strings.HasPrefix(g.CloneURL, "http") {
+		return errors.New("credentials secret is only supported for http(s) remotes")
+	}
+
+	username, password, err := getUpstreamCredentials(g.CredentialsSecret)
+	if err != nil {
+		return err
+	}
+
+	g.Auth = &githttp.BasicAuth{
+		Username: username,
+		Password: password,
+	}
+
+	return nil
+}
+
+// listRefs returns the branches and tags in the remote, with annotated tags resolved to their commits
+func (g *gitUpstream) listRefs() ([]*plumbing.Reference, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{g.CloneURL},
+	})
+
+	refs, err := remote.List(&git.ListOptions{
+		Auth:          g.Auth,
+		PeelingOption: git.AppendPeeled,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list refs in %s", g.CloneURL)
+	}
+
+	return peelRefs(refs), nil
+}
+
+// peelRefs replaces annotated tags with the commits they point to
+func peelRefs(refs []*plumbing.Reference) []*plumbing.Reference {
+	peeled := map[plumbing.ReferenceName]plumbing.Hash{}
+	for _, ref := range refs {
+		if name := ref.Name().String(); strings.HasSuffix(name, "^{}") {
+			peeled[plumbing.ReferenceName(strings.TrimSuffix(name, "^{}"))] = ref.Hash()
+		}
+	}
+
+	result := []*plumbing.Reference{}
+	for _, ref := range refs {
+		if strings.HasSuffix(ref.Name().String(), "^{}") {
+			continue
+		}
+		if hash, ok := peeled[ref.Name()]; ok {
+			ref = plumbing.NewHashReference(ref.Name(), hash)
+		}
+		result = append(result, ref)
+	}
+	return result
+}
+
+// branchRef returns the branch that is tracked for new commits, which is the default branch if no ref is set
+func (g *gitUpstream) branchRef(refs []*plumbing.Reference) *plumbing.Reference {
+	if g.Ref != "" {
+		return findRef(refs, plumbing.NewBranchReferenceName(g.Ref))
+	}
+
+	head := findRef(refs, plumbing.HEAD)
+	if head == nil {
+		return nil
+	}
+	if head.Type() == plumbing.SymbolicReference {
+		return findRef(refs, head.Target())
+	}
+	for _, ref := range refs {
+		if ref.Name().IsBranch() && ref.Hash() == head.Hash() {
+			return ref
+		}
+	}
+	return nil
+}
+
+func findRef(refs []*plumbing.Reference, name plumbing.ReferenceName) *plumbing.Reference {
+	for _, ref := range refs {
+		if ref.Name() == name {
+			return ref
+		}
+	}
+	return nil
+}
+
+// semverTags returns the tags that are versions in ascending order. Prereleases are ignored, and
+// when several tags point to the same commit, only the latest of them is kept.
+func semverTags(refs []*plumbing.Reference) []gitRef {
+	latestByHash := map[plumbing.Hash]gitRef{}
+	for _, ref := range refs {
+		if !ref.Name().IsTag() {
+			continue
+		}
+		v, err := semver.NewVersion(ref.Name().Short())
+		if err != nil || v.Prerelease() != "" {
+			continue
+		}
+		if existing, ok := latestByHash[ref.Hash()]; ok && !existing.Version.LessThan(v) {
+			continue
+		}
+		latestByHash[ref.Hash()] = gitRef{
+			Name:    ref.Name().Short(),
+			Version: v,
+			Hash:    ref.Hash(),
+		}
+	}
+
+	tags := []gitRef{}
+	for _, tag := range latestByHash {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Version.LessThan(tags[j].Version)
+	})
+	return tags
+}
+
+// tagsSince returns the version tags that are newer than the tag on the current commit
+func tagsSince(tags []gitRef, currentCursor string) []gitRef {
+	var current *semver.Version
+	for _, tag := range tags {
+		if tag.Hash.String() == currentCursor {
+			current = tag.Version
+		}
+	}
+
+	newer := []gitRef{}
+	for _, tag := range tags {
+		if current == nil || tag.Version.GreaterThan(current) {
+			newer = append(newer, tag)
+		}
+	}
+	return newer
+}
+
+func shortHash(hash plumbing.Hash) string {
+	return hash.String()[:7]
+}
+
+func getUpdatesGitUpstream(u *url.URL, fetchOptions *types.FetchOptions) (*types.UpdateCheckResult, error) {
+	g, err := parseGitUpstream(u)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse git upstream")
+	}
+	if err := g.loadCredentials(); err != nil {
+		return nil, errors.Wrap(err, "failed to load git credentials")
+	}
+
+	updateCheckTime := time.Now()
+	noUpdates := &types.UpdateCheckResult{
+		Updates:         []types.Update{},
+		UpdateCheckTime: updateCheckTime,
+	}
+
+	refs, err := g.listRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	updates := []types.Update{}
+	if g.Track == gitTrackTags {
+		for _, tag := range tagsSince(semverTags(refs), fetchOptions.CurrentCursor) {
+			updates = append(updates, types.Update{
+				Cursor:       tag.Hash.String(),
+				VersionLabel: tag.Name,
+			})
+		}
+	} else {
+		branch := g.branchRef(refs)
+		if branch == nil {
+			// tags and commits are pinned
+			return noUpdates, nil
+		}
+		if branch.Hash().String() == fetchOptions.CurrentCursor {
+			return noUpdates, nil
+		}
+
+		commits, err := g.commitsSince(branch, fetchOptions.CurrentCursor)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list commits")
+		}
+		for _, commit := range commits {
+			releasedAt := commit.Committer.When
+			updates = append(updates, types.Update{
+				Cursor:       commit.Hash.String(),
+				VersionLabel: shortHash(commit.Hash),
+				ReleasedAt:   &releasedAt,
+			})
+		}
+	}
+
+	if fetchOptions.SortOrder == "desc" {
+		for i, j := 0, len(updates)-1; i < j; i, j = i+1, j-1 {
+			updates[i], updates[j] = updates[j], updates[i]
+		}
+	}
+
+	return &types.UpdateCheckResult{
+		Updates:         updates,
+		UpdateCheckTime: updateCheckTime,
+	}, nil
+}
+
+// commitsSince returns the commits on the branch after the current commit, oldest first. Only the head
+// of the branch is returned if there is no current commit or if it is no longer in the history of the branch.
+func (g *gitUpstream) commitsSince(branch *plumbing.Reference, currentCursor string) ([]*object.Commit, error) {
+	repo, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
+		URL:           g.CloneURL,
+		Auth:          g.Auth,
+		ReferenceName: branch.Name(),
+		SingleBranch:  true,
+		Tags:          git.NoTags,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to clone %s", g.CloneURL)
+	}
+
+	head, err := repo.CommitObject(branch.Hash())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get head commit")
+	}
+	if currentCursor == "" {
+		return []*object.Commit{head}, nil
+	}
+
+	// follow the first parent so that merged branches count as a single update
+	commits := []*object.Commit{}
+	for commit := head; ; {
+		if commit.Hash.String() == currentCursor {
+			break
+		}
+		commits = append([]*object.Commit{commit}, commits...)
+		if commit.NumParents() == 0 {
+			return []*object.Commit{head}, nil
+		}
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get parent of %s", commit.Hash)
+		}
+		commit = parent
+	}
+
+	return commits, nil
+}
+
+func downloadGitUpstream(u *url.URL, fetchOptions *types.FetchOptions) (*types.Upstream, error) {
+	g, err := parseGitUpstream(u)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse git upstream")
+	}
+	if err := g.loadCredentials(); err != nil {
+		return nil, errors.Wrap(err, "failed to load git credentials")
+	}
+
+	refs, err := g.listRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	workDir, err := os.MkdirTemp("", "kots-git-upstream")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create temp dir")
+	}
+	defer os.RemoveAll(workDir)
+
+	repo, err := git.PlainClone(workDir, false, &git.CloneOptions{
+		URL:  g.CloneURL,
+		Auth: g.Auth,
+		Tags: git.AllTags,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to clone %s", g.CloneURL)
+	}
+
+	hash, versionLabel, err := g.pickCommit(repo, refs, pickCursor(fetchOptions).Cursor, pickVersionLabel(fetchOptions))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pick commit")
+	}
+
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get commit %s", hash)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get working tree")
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Hash: hash, Force: true}); err != nil {
+		return nil, errors.Wrapf(err, "failed to checkout %s", hash)
+	}
+
+	files, err := readGitFiles(filepath.Join(workDir, g.Path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read files")
+	}
+	if len(files) == 0 {
+		return nil, errors.Errorf("no files found in %s at %s", g.CloneURL, versionLabel)
+	}
+
+	if fetchOptions.ConfigValues != nil {
+		files = append(files, types.UpstreamFile{
+			Path:    filepath.Join("userdata", "config.yaml"),
+			Content: mustMarshalConfigValues(fetchOptions.ConfigValues),
+		})
+	}
+
+	releasedAt := commit.Committer.When
+
+	return &types.Upstream{
+		URI:          u.String(),
+		Name:         util.GitUpstreamName(u.String()),
+		Type:         "git",
+		Files:        files,
+		UpdateCursor: hash.String(),
+		VersionLabel: versionLabel,
+		ReleasedAt:   &releasedAt,
+	}, nil
+}
+
+// pickCommit returns the commit to check out and its version label. The current cursor takes precedence,
+// followed by the requested version label, and finally the latest tag or the head of the branch.
+func (g *gitUpstream) pickCommit(repo *git.Repository, refs []*plumbing.Reference, cursor string, versionLabel string) (plumbing.Hash, string, error) {
+	if g.Track == gitTrackTags {
+		tags := semverTags(refs)
+		for _, tag := range tags {
+			if cursor != "" && tag.Hash.String() == cursor {
+				return tag.Hash, tag.Name, nil
+			}
+		}
+		if versionLabel != "" {
+			for _, tag := range tags {
+				if tag.Name == versionLabel {
+					return tag.Hash, tag.Name, nil
+				}
+			}
+			return plumbing.ZeroHash, "", errors.Errorf("tag %s not found", versionLabel)
+		}
+		if len(tags) == 0 {
+			return plumbing.ZeroHash, "", errors.New("no version tags found")
+		}
+		latest := tags[len(tags)-1]
+		return latest.Hash, latest.Name, nil
+	}
+
+	if g.Ref != "" {
+		if tag := findRef(refs, plumbing.NewTagReferenceName(g.Ref)); tag != nil && (cursor == "" || tag.Hash().String() == cursor) {
+			return tag.Hash(), g.Ref, nil
+		}
+	}
+
+	if cursor != "" {
+		hash := plumbing.NewHash(cursor)
+		return hash, shortHash(hash), nil
+	}
+
+	revision := versionLabel
+	if revision == "" {
+		if branch := g.branchRef(refs); branch != nil {
+			return branch.Hash(), shortHash(branch.Hash()), nil
+		}
+		revision = g.Ref
+	}
+	if revision == "" {
+		return plumbing.ZeroHash, "", errors.New("failed to find default branch")
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return plumbing.ZeroHash, "", errors.Wrapf(err, "failed to resolve %s", revision)
+	}
+	return *hash, shortHash(*hash), nil
+}
+
+// readGitFiles reads the files in a checked out repository, skipping the .git directory
+func readGitFiles(rootDir string) ([]types.UpstreamFile, error) {
+	files := []types.UpstreamFile{}
+	err := filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", path)
+		}
+		relPath, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get relative path for %s", path)
+		}
+
+		files = append(files, types.UpstreamFile{
+			Path:    relPath,
+			Content: content,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,122 @@
+package upstream
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	semver "github.com/Masterminds/semver/v3"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/util"
+	"sigs.k8s.io/yaml"
+)
+
+// chartRepoIndex is the subset of a chart repository index.yaml that is needed to track a chart
+type chartRepoIndex struct {
+	Entries map[string][]chartRepoIndexEntry `json:"entries"`
+}
+
+type chartRepoIndexEntry struct {
+	Version string     `json:"version"`
+	URLs    []string   `json:"urls"`
+	Created *time.Time `json:"created,omitempty"`
+}
+
+// repoURL returns the url of the chart repository, e.g. https://charts.example.com/stable/
+func (c *chartUpstream) repoURL() *url.URL {
+	scheme := "https"
+	if c.PlainHTTP {
+		scheme = "http"
+	}
+	repoPath := "/"
+	if c.Path != "" {
+		repoPath = fmt.Sprintf("/%s/", c.Path)
+	}
+	return &url.URL{
+		Scheme: scheme,
+		Host:   c.Host,
+		Path:   repoPath,
+	}
+}
+
+// listRepoVersions returns the versions of the chart in the repository index. Entries that are not versions are ignored.
+func (c *chartUpstream) listRepoVersions() ([]chartVersion, error) {
+	repoURL := c.repoURL()
+
+	indexURL := repoURL.ResolveReference(&url.URL{Path: "index.yaml"})
+	indexContent, err := c.httpGetChartRepo(indexURL.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get repository index")
+	}
+
+	index := chartRepoIndex{}
+	if err := yaml.Unmarshal(indexContent, &index); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal repository index")
+	}
+
+	entries, ok := index.Entries[c.ChartName]
+	if !ok {
+		return nil, errors.Errorf("chart %s not found in repository %s", c.ChartName, repoURL.String())
+	}
+
+	versions := []chartVersion{}
+	for _, entry := range entries {
+		v, err := semver.NewVersion(entry.Version)
+		if err != nil || len(entry.URLs) == 0 {
+			continue
+		}
+
+		// chart urls can be relative to the repository
+		chartURL, err := url.Parse(entry.URLs[0])
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, chartVersion{
+			Version:    v,
+			URL:        repoURL.ResolveReference(chartURL).String(),
+			ReleasedAt: entry.Created,
+		})
+	}
+
+	return versions, nil
+}
+
+func (c *chartUpstream) pullRepoChart(version chartVersion) ([]byte, error) {
+	chartContent, err := c.httpGetChartRepo(version.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download chart")
+	}
+	return chartContent, nil
+}
+
+// httpGetChartRepo gets a file from the chart repository. Credentials are only sent to the repository host,
+// not to other hosts that charts are served from, like helm does without --pass-credentials.
+func (c *chartUpstream) httpGetChartRepo(requestURL string) ([]byte, error) {
+	req, err := util.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	if c.hasCredentials() && req.URL.Host == c.Host {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code %d from %s", resp.StatusCode, requestURL)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read response body")
+	}
+
+	return body, nil
+}
@@ -0,0 +1,124 @@
+package upstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	semver "github.com/Masterminds/semver/v3"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/buildversion"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+const helmChartContentLayerMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+
+func (c *chartUpstream) registryRepository() (*remote.Repository, error) {
+	reference := c.Host
+	if c.Path != "" {
+		reference = fmt.Sprintf("%s/%s", reference, c.Path)
+	}
+	reference = fmt.Sprintf("%s/%s", reference, c.ChartName)
+
+	repo, err := remote.NewRepository(reference)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repository")
+	}
+	repo.PlainHTTP = c.PlainHTTP
+
+	client := &auth.Client{
+		Client: retry.DefaultClient,
+		Cache:  auth.NewCache(),
+	}
+
+	if c.hasCredentials() {
+		client.Credential = auth.StaticCredential(c.Host, auth.Credential{
+			Username: c.Username,
+			Password: c.Password,
+		})
+	} else {
+		// use the credentials from "docker login", if there are any
+		credStore, err := credentials.NewStoreFromDocker(credentials.StoreOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load docker credentials")
+		}
+		client.Credential = credentials.Credential(credStore)
+	}
+	client.SetUserAgent(buildversion.GetUserAgent())
+	repo.Client = client
+
+	return repo, nil
+}
+
+// listRegistryVersions returns the chart versions from the repository tags. Tags that are not versions are ignored.
+func (c *chartUpstream) listRegistryVersions() ([]chartVersion, error) {
+	repo, err := c.registryRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []chartVersion{}
+	err = repo.Tags(context.TODO(), "", func(tags []string) error {
+		for _, tag := range tags {
+			// helm replaces "+" with "_" since "+" is not allowed in tags
+			v, err := semver.NewVersion(strings.ReplaceAll(tag, "_", "+"))
+			if err != nil {
+				continue
+			}
+			versions = append(versions, chartVersion{
+				Version: v,
+				Tag:     tag,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list tags")
+	}
+
+	return versions, nil
+}
+
+func (c *chartUpstream) pullRegistryChart(version chartVersion) ([]byte, error) {
+	repo, err := c.registryRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.TODO()
+
+	desc, rc, err := repo.FetchReference(ctx, version.Tag)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch manifest")
+	}
+	defer rc.Close()
+
+	manifestContent, err := content.ReadAll(rc, desc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read manifest")
+	}
+
+	manifest := ocispec.Manifest{}
+	if err := json.Unmarshal(manifestContent, &manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal manifest")
+	}
+
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != helmChartContentLayerMediaType {
+			continue
+		}
+		chartContent, err := content.FetchAll(ctx, repo, layer)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch chart layer")
+		}
+		return chartContent, nil
+	}
+
+	return nil, errors.Errorf("%s:%s is not a helm chart", c.ChartName, version.Tag)
+}
@@ -20,6 +20,12 @@ func GetUpdatesUpstream(upstreamURI string, fetchOptions *types.FetchOptions) (*
 	if u.Scheme == "replicated" {
 		return getUpdatesReplicated(fetchOptions)
 	}
+	if util.IsChartUpstreamURI(upstreamURI) {
+		return getUpdatesChartUpstream(u, fetchOptions)
+	}
+	if util.IsGitUpstreamURI(upstreamURI) {
+		return getUpdatesGitUpstream(u, fetchOptions)
+	}
 
 	return nil, errors.Errorf("unknown protocol scheme %q", u.Scheme)
 }
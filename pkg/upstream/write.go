@@ -2,7 +2,7 @@ package upstream
 
 import (
 	"bytes"
-	"encoding/base64"
+	"context"
 	"os"
 	"path"
 
@@ -10,6 +10,7 @@ import (
 	"github.com/replicatedhq/kots/pkg/archives"
 	"github.com/replicatedhq/kots/pkg/crypto"
 	"github.com/replicatedhq/kots/pkg/kotsutil"
+	"github.com/replicatedhq/kots/pkg/secretprovider"
 	"github.com/replicatedhq/kots/pkg/upstream/types"
 	kotsv1beta1 "github.com/replicatedhq/kotskinds/apis/kots/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -185,7 +186,12 @@ func encryptConfigValues(configValues *kotsv1beta1.ConfigValues) ([]byte, error)
 			continue
 		}
 
-		v.Value = base64.StdEncoding.EncodeToString(crypto.Encrypt([]byte(v.ValuePlaintext)))
+		encrypted, err := secretprovider.EncryptConfigValue(context.TODO(), v.ValuePlaintext)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to encrypt value for %s", k)
+		}
+
+		v.Value = encrypted
 		v.ValuePlaintext = ""
 
 		configValues.Spec.Values[k] = v
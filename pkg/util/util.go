@@ -9,7 +9,9 @@ import (
 	rand "math/rand"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/pkg/crypto"
@@ -24,6 +26,90 @@ func IsURL(str string) bool {
 	return true
 }
 
+// IsChartUpstreamURI returns true if the upstream is a helm chart in an OCI registry (oci://) or a chart repository (helm://)
+func IsChartUpstreamURI(upstreamURI string) bool {
+	u, err := url.ParseRequestURI(upstreamURI)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "oci" || u.Scheme == "helm"
+}
+
+// ChartUpstreamName returns the name of the chart in an oci:// or helm:// upstream uri, without the tag
+func ChartUpstreamName(upstreamURI string) string {
+	u, err := url.ParseRequestURI(upstreamURI)
+	if err != nil {
+		return ""
+	}
+	return strings.Split(path.Base(u.Path), ":")[0]
+}
+
+// IsGitUpstreamURI returns true if the upstream is a git repository (git://, git+https://, git+ssh://, ...)
+func IsGitUpstreamURI(upstreamURI string) bool {
+	u, err := url.ParseRequestURI(upstreamURI)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "git" || strings.HasPrefix(u.Scheme, "git+")
+}
+
+// GitUpstreamName returns the name of the repository in a git upstream uri, without the .git suffix
+func GitUpstreamName(upstreamURI string) string {
+	u, err := url.ParseRequestURI(upstreamURI)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(path.Base(u.Path), ".git")
+}
+
+// HasUpstreamCredentials returns true if a git upstream uri includes credentials for an http(s) remote,
+// or a chart upstream uri includes credentials for the registry or chart repository
+func HasUpstreamCredentials(upstreamURI string) bool {
+	u, err := url.ParseRequestURI(upstreamURI)
+	if err != nil || u.User == nil {
+		return false
+	}
+	if IsChartUpstreamURI(upstreamURI) {
+		return true
+	}
+	return IsGitUpstreamURI(upstreamURI) && strings.HasPrefix(strings.TrimPrefix(u.Scheme, "git+"), "http")
+}
+
+// ReplaceUpstreamCredentials removes the credentials from a git or chart upstream uri and references the secret they are stored in instead.
+// The credentials that were removed are returned, or nil if the uri did not include any.
+func ReplaceUpstreamCredentials(upstreamURI string, secretName string) (string, *url.Userinfo, error) {
+	if !HasUpstreamCredentials(upstreamURI) {
+		return upstreamURI, nil, nil
+	}
+
+	u, err := url.ParseRequestURI(upstreamURI)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to parse upstream uri")
+	}
+
+	credentials := u.User
+	u.User = nil
+
+	query := u.Query()
+	query.Set("secret", secretName)
+	u.RawQuery = query.Encode()
+
+	return u.String(), credentials, nil
+}
+
+// IsUnlicensedUpstreamURI returns true if the upstream is installed without a license, such as a helm chart or a git repository
+func IsUnlicensedUpstreamURI(upstreamURI string) bool {
+	return IsChartUpstreamURI(upstreamURI) || IsGitUpstreamURI(upstreamURI)
+}
+
+// UnlicensedUpstreamName returns the name of the app for an upstream that is installed without a license
+func UnlicensedUpstreamName(upstreamURI string) string {
+	if IsGitUpstreamURI(upstreamURI) {
+		return GitUpstreamName(upstreamURI)
+	}
+	return ChartUpstreamName(upstreamURI)
+}
+
 func CommonSlicePrefix(first []string, second []string) []string {
 	common := []string{}
 
@@ -51,6 +51,11 @@ func DeployVersion(appID string, sequence int64) error {
 
 	logger.Info("deploying app version", zap.String("appId", appID), zap.Int64("sequence", sequence))
 
+	// this deploy supersedes any deploy that's waiting for the next maintenance window
+	if err := deleteQueuedDeploys(appID); err != nil {
+		return errors.Wrap(err, "failed to delete queued deploys")
+	}
+
 	if err := store.GetStore().MarkAsCurrentDownstreamVersion(appID, sequence); err != nil {
 		return errors.Wrap(err, "failed to mark as current downstream version")
 	}
@@ -60,6 +65,21 @@ func DeployVersion(appID string, sequence int64) error {
 	return nil
 }
 
+func deleteQueuedDeploys(appID string) error {
+	downstreams, err := store.GetStore().ListDownstreamsForApp(appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list downstreams for app")
+	}
+
+	for _, d := range downstreams {
+		if err := store.GetStore().DeleteQueuedDeploy(appID, d.ClusterID); err != nil {
+			return errors.Wrapf(err, "failed to delete queued deploy for cluster %s", d.ClusterID)
+		}
+	}
+
+	return nil
+}
+
 func GetRealizedLinksFromAppSpec(appID string, sequence int64) ([]types.RealizedLink, error) {
 	db := persistence.MustGetDBSession()
 	query := `select app_spec, kots_app_spec from app_version where app_id = ? and sequence = ?`